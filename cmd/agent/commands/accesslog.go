@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/spf13/cobra"
+)
+
+// BuildAccessLogCmd returns a cobra command with the specification of the access-log command
+func BuildAccessLogCmd(config *agent.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "access-log",
+		Short: "reports the requests handled by the proxies of the disruptions with the access log enabled",
+		Long: "Reports the requests handled by the proxies of the disruptions with the access log enabled," +
+			" one JSON object per line.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			content, err := agent.ReadAccessLog(config.AccessLogFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(content)
+			return err
+		},
+	}
+
+	return cmd
+}
@@ -3,10 +3,34 @@ package commands
 import (
 	"syscall"
 
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/iptables"
+	"github.com/grafana/xk6-disruptor/pkg/nftables"
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 	"github.com/spf13/cobra"
 )
 
+// removeLeftoverRules removes the iptables and nftables rules left behind by an agent that terminated unexpectedly
+func removeLeftoverRules(env runtime.Environment) error {
+	ipt := iptables.New(env.Executor())
+	nft := nftables.New(env.Executor())
+	if !nft.Available() {
+		return ipt.RemoveTagged("nat", "filter")
+	}
+
+	err := nft.RemoveTable()
+	if err != nil {
+		return err
+	}
+
+	// nodes that only support nftables fail when running iptables commands
+	if !ipt.Available() {
+		return nil
+	}
+
+	return ipt.RemoveTagged("nat", "filter")
+}
+
 // BuiltCleanupCmd returns a cobra command with the specification of the kill command
 func BuiltCleanupCmd(env runtime.Environment) *cobra.Command {
 	cmd := &cobra.Command{
@@ -14,14 +38,12 @@ func BuiltCleanupCmd(env runtime.Environment) *cobra.Command {
 		Short: "stops any ongoing fault injection and cleans resources",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			runningProcess := env.Lock().Owner()
-			// no instance is currently running
-			if runningProcess == -1 {
-				return nil
+			// the running instance cleans its resources when terminated
+			if runningProcess != -1 {
+				return agent.SignalProcess(runningProcess, syscall.SIGTERM)
 			}
 
-			return syscall.Kill(runningProcess, syscall.SIGTERM)
-
-			// TODO: cleanup resources (e.g iptables)
+			return removeLeftoverRules(env)
 		},
 	}
 
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/node"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// BuildClockSkewCmd returns a cobra command with the specification of the clock-skew command
+func BuildClockSkewCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var duration time.Duration
+	disruption := node.ClockSkewDisruption{}
+
+	cmd := &cobra.Command{
+		Use:   "clock-skew",
+		Short: "clock skew",
+		Long: "Shifts the host's clock for the duration of the disruption." +
+			" Requires either to be run as root, or the SYS_TIME capability.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+			defer agent.Stop()
+
+			disruptor, err := node.NewClockSkewDisruptor(node.SystemClock(), disruption)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().DurationVarP(&disruption.Offset, "offset", "o", 0,
+		"offset added to the clock. Negative values set the clock back in time")
+
+	return cmd
+}
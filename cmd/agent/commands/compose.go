@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/grafana/xk6-disruptor/pkg/runtime/profiler"
+	"github.com/spf13/cobra"
+)
+
+// composedLock implements a Lock that is always owned by the current process.
+// The composed faults run under the lock acquired by the compose command.
+type composedLock struct{}
+
+func (composedLock) Acquire() (bool, error) {
+	return true, nil
+}
+
+func (composedLock) Release() error {
+	return nil
+}
+
+func (composedLock) Owner() int {
+	return os.Getpid()
+}
+
+// composedSignals implements Signals that are never notified.
+// The signals are handled by the compose command, which cancels the composed faults.
+type composedSignals struct{}
+
+func (composedSignals) Notify(...os.Signal) <-chan os.Signal {
+	return nil
+}
+
+func (composedSignals) Reset(...os.Signal) {}
+
+// composedEnvironment is the environment of a fault applied by the compose command
+type composedEnvironment struct {
+	runtime.Environment
+	profiler profiler.Profiler
+}
+
+func (e *composedEnvironment) Lock() runtime.Lock {
+	return composedLock{}
+}
+
+func (e *composedEnvironment) Signal() runtime.Signals {
+	return composedSignals{}
+}
+
+func (e *composedEnvironment) Profiler() profiler.Profiler {
+	return e.profiler
+}
+
+// runFaultCmd applies the fault defined by the arguments of a fault command within the agent running
+// in the given environment with the given configuration
+func runFaultCmd(ctx context.Context, env runtime.Environment, parent *agent.Config, args []string) error {
+	faultEnv := &composedEnvironment{
+		Environment: env,
+		profiler:    profiler.NewProfiler(),
+	}
+
+	config := &agent.Config{
+		Profiler: &profiler.Config{},
+	}
+
+	rootCmd := buildRootCmd(config)
+	addFaultCmds(rootCmd, faultEnv, config)
+	rootCmd.AddCommand(BuildComposeCmd(faultEnv, config))
+	// the status is reported by the agent that runs the fault command
+	config.StatusFile = ""
+	// the fault uses the defaults of the agent that runs the fault command
+	config.LogLevel = parent.LogLevel
+	config.ProxyPorts = parent.ProxyPorts
+	config.ExcludedPorts = parent.ExcludedPorts
+	config.Interception = parent.Interception
+
+	rootCmd.SetArgs(args)
+
+	return rootCmd.ExecuteContext(ctx)
+}
+
+// composedDisruptor applies multiple faults simultaneously. If any fault fails, the others are cancelled.
+type composedDisruptor struct {
+	env    runtime.Environment
+	config *agent.Config
+	faults [][]string
+}
+
+func (d *composedDisruptor) Apply(ctx context.Context, _ time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errCh := make(chan error, len(d.faults))
+	for _, args := range d.faults {
+		go func(args []string) {
+			errCh <- runFaultCmd(ctx, d.env, d.config, args)
+		}(args)
+	}
+
+	// wait for all faults to complete, reporting the first error
+	var err error
+	for range d.faults {
+		faultErr := <-errCh
+		if faultErr != nil && err == nil {
+			err = faultErr
+			cancel()
+		}
+	}
+
+	return err
+}
+
+// splitFaults splits the arguments of the compose command in the arguments of each fault command
+func splitFaults(args []string) [][]string {
+	faults := [][]string{}
+	for len(args) > 0 {
+		end := slices.Index(args, "--")
+		if end == -1 {
+			end = len(args)
+		}
+
+		if end > 0 {
+			faults = append(faults, args[:end])
+		}
+
+		args = args[min(end+1, len(args)):]
+	}
+
+	return faults
+}
+
+// isFaultCmd returns if the name corresponds to a command that applies a fault
+func isFaultCmd(name string) bool {
+	rootCmd := &cobra.Command{}
+	addFaultCmds(rootCmd, nil, &agent.Config{Profiler: &profiler.Config{}})
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BuildComposeCmd returns a cobra command with the specification of the compose command
+func BuildComposeCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var duration time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "compose -- fault [fault flags] [-- fault [fault flags]...]",
+		Short: "applies multiple faults simultaneously",
+		Long: "Applies simultaneously the faults defined by the fault commands separated by '--'" +
+			" (e.g. compose -d 30s -- http -d 30s -t 80 -- grpc -d 30s -t 9000)." +
+			" If any of the faults fails, the others are stopped.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			faults := splitFaults(args)
+			if len(faults) == 0 {
+				return fmt.Errorf("%w: at least one fault command is required", agent.ErrInvalidFault)
+			}
+
+			for _, fault := range faults {
+				if !isFaultCmd(fault[0]) {
+					return fmt.Errorf("%w: %q is not a fault command", agent.ErrInvalidFault, fault[0])
+				}
+			}
+
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			disruptor := &composedDisruptor{
+				env:    env,
+				config: config,
+				faults: faults,
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+
+	return cmd
+}
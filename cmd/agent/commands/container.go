@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/cgroup"
+	"github.com/grafana/xk6-disruptor/pkg/agent/node"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// BuildContainerCmd returns a cobra command with the specification of the container command
+func BuildContainerCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var duration time.Duration
+	var runtimeEndpoint string
+	disruption := node.ContainerDisruption{}
+
+	cmd := &cobra.Command{
+		Use:   "container",
+		Short: "container disruptor",
+		Long: "Kills, restarts or pauses containers of the host using the CRI socket of the container runtime." +
+			" Requires to run in a privileged container that shares the host's PID namespace. Pausing containers" +
+			" requires systemd in the host, which thaws them if the agent terminates unexpectedly.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var hierarchy *cgroup.Hierarchy
+			if disruption.Action == node.ContainerPause {
+				var err error
+				hierarchy, err = cgroup.Detect(cgroup.DefaultRoot)
+				if err != nil {
+					return fmt.Errorf("detecting cgroup: %w", err)
+				}
+			}
+
+			containerRuntime, err := node.NewCRIRuntime(runtimeEndpoint)
+			if err != nil {
+				return err
+			}
+			defer containerRuntime.Close() //nolint:errcheck // nothing to do if closing fails
+
+			disruptor, err := node.NewContainerDisruptor(env.Executor(), containerRuntime, disruption, hierarchy)
+			if err != nil {
+				return fmt.Errorf("%w: %w", agent.ErrInvalidFault, err)
+			}
+
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+			defer agent.Stop()
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().StringSliceVar(&disruption.IDs, "id", []string{}, "comma-separated list of IDs of the containers")
+	cmd.Flags().StringVarP(&disruption.Action, "action", "a", node.ContainerKill,
+		"action applied to the containers: kill, restart or pause")
+	cmd.Flags().DurationVar(&disruption.GracePeriod, "grace-period", node.DefaultContainerGracePeriod,
+		"time a restarted container has for terminating gracefully")
+	cmd.Flags().StringVar(&runtimeEndpoint, "runtime-endpoint", "",
+		"CRI socket of the container runtime in the host. By default, the socket of containerd, CRI-O or cri-dockerd")
+
+	return cmd
+}
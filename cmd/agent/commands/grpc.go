@@ -3,12 +3,12 @@ package commands
 import (
 	"fmt"
 	"net"
+	"slices"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/agent"
 	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
 	"github.com/grafana/xk6-disruptor/pkg/agent/protocol/grpc"
-	"github.com/grafana/xk6-disruptor/pkg/iptables"
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 
 	"github.com/spf13/cobra"
@@ -23,7 +23,10 @@ func BuildGrpcCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 	var port uint
 	var upstreamHost string
 	var targetPort uint
-	transparent := true
+	var hostNetwork bool
+	var istioSidecar bool
+	restricted := isRestricted(env)
+	transparent := !restricted
 
 	cmd := &cobra.Command{
 		Use:   "grpc",
@@ -33,13 +36,25 @@ func BuildGrpcCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 			" iptable rules.",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if targetPort == 0 {
-				return fmt.Errorf("target port for fault injection is required")
+				return fmt.Errorf("%w: target port for fault injection is required", agent.ErrInvalidFault)
+			}
+
+			if transparent && restricted {
+				return errTransparentRestricted
 			}
 
 			if transparent && (upstreamHost == "localhost" || upstreamHost == "127.0.0.1") {
 				// When running in transparent mode, the Redirector will also redirect traffic directed to 127.0.0.1 to
 				// the proxy. Using 127.0.0.1 as the proxy upstream would cause a redirection loop.
-				return fmt.Errorf("upstream host cannot be localhost when running in transparent mode")
+				return fmt.Errorf(
+					"%w: upstream host cannot be localhost when running in transparent mode",
+					agent.ErrInvalidFault,
+				)
+			}
+
+			ports, err := proxyPorts(cmd, config, port, targetPort)
+			if err != nil {
+				return err
 			}
 
 			agent, err := agent.Start(env, config)
@@ -49,13 +64,14 @@ func BuildGrpcCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 
 			defer agent.Stop()
 
-			listenAddress := net.JoinHostPort("", fmt.Sprint(port))
 			upstreamAddress := net.JoinHostPort(upstreamHost, fmt.Sprint(targetPort))
 
-			listener, err := net.Listen("tcp", listenAddress)
+			// the proxy does not listen on the target port, which is redirected to it
+			listener, proxyPort, err := ports.Listen(append(slices.Clone(config.ExcludedPorts), targetPort))
 			if err != nil {
-				return fmt.Errorf("setting up listener at %q: %w", listenAddress, err)
+				return fmt.Errorf("setting up listener: %w", err)
 			}
+			agent.ReportProxy(proxyPort, upstreamAddress)
 
 			proxy, err := grpc.NewProxy(listener, upstreamAddress, disruption)
 			if err != nil {
@@ -67,10 +83,16 @@ func BuildGrpcCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 			if transparent {
 				tr := &protocol.TrafficRedirectionSpec{
 					DestinationPort: targetPort, // Redirect traffic from the application (target) port...
-					RedirectPort:    port,       // to the proxy port.
+					RedirectPort:    proxyPort,  // to the proxy port.
+					IstioSidecar:    istioSidecar,
+				}
+
+				// in the network namespace of the node, only the traffic directed to the target is redirected
+				if hostNetwork {
+					tr.DestinationAddress = upstreamHost
 				}
 
-				redirector, err = protocol.NewTrafficRedirector(tr, iptables.New(env.Executor()))
+				redirector, err = protocol.NewBackendTrafficRedirector(config.Interception, tr, env.Executor())
 				if err != nil {
 					return err
 				}
@@ -97,10 +119,16 @@ func BuildGrpcCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 	cmd.Flags().Float32VarP(&disruption.ErrorRate, "rate", "r", 0, "error rate")
 	cmd.Flags().StringVarP(&disruption.StatusMessage, "message", "m", "", "error message for injected faults")
 	cmd.Flags().UintVarP(&port, "port", "p", 8000, "port the proxy will listen to")
+	cmd.Flags().BoolVar(&hostNetwork, "host-network", false, "the target shares the network of the node."+
+		" Only traffic directed to the upstream host is redirected")
+	cmd.Flags().BoolVar(&istioSidecar, "istio", false, "the target has an Istio sidecar."+
+		" Traffic forwarded by the sidecar is also redirected")
 	cmd.Flags().UintVarP(&targetPort, "target", "t", 0, "port the proxy will redirect request to")
 	cmd.Flags().StringSliceVarP(&disruption.Excluded, "exclude", "x", []string{}, "comma-separated list of grpc services"+
 		" to be excluded from disruption")
-	cmd.Flags().BoolVar(&transparent, "transparent", true, "run as transparent proxy")
+	cmd.Flags().DurationVar(&disruption.RampDuration, "ramp-duration", 0,
+		"duration of the linear increase of the delay and error rate from zero")
+	cmd.Flags().BoolVar(&transparent, "transparent", !restricted, "run as transparent proxy")
 	cmd.Flags().StringVar(&upstreamHost, "upstream-host", "localhost",
 		"upstream host to redirect traffic to")
 
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// BuildHealthCmd returns a cobra command with the specification of the health command
+func BuildHealthCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "health",
+		Short: "checks the health of the agent and of the disruption it applies",
+		Long: "Checks if the agent is ready and, if a disruption is active, if it is still applied and its proxy " +
+			"accepts connections and reaches the upstream. Reports the result and fails if any problem is found.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			health, err := agent.CheckHealth(env, config)
+			if err != nil {
+				return err
+			}
+
+			err = json.NewEncoder(cmd.OutOrStdout()).Encode(health)
+			if err != nil {
+				return err
+			}
+
+			if !health.Healthy {
+				return fmt.Errorf("agent is not healthy: %s", strings.Join(health.Problems, ", "))
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -3,12 +3,12 @@ package commands
 import (
 	"fmt"
 	"net"
+	"slices"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/agent"
 	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
 	"github.com/grafana/xk6-disruptor/pkg/agent/protocol/http"
-	"github.com/grafana/xk6-disruptor/pkg/iptables"
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 	"github.com/spf13/cobra"
 )
@@ -18,11 +18,16 @@ import (
 //nolint:funlen
 func BuildHTTPCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
 	disruption := http.Disruption{}
+	proxyConfig := http.DefaultProxyConfig()
 	var duration time.Duration
 	var port uint
 	var upstreamHost string
 	var targetPort uint
-	transparent := true
+	var hostNetwork bool
+	var istioSidecar bool
+	var accessLog bool
+	restricted := isRestricted(env)
+	transparent := !restricted
 
 	cmd := &cobra.Command{
 		Use:   "http",
@@ -32,13 +37,35 @@ func BuildHTTPCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 			" iptable rules.",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if targetPort == 0 {
-				return fmt.Errorf("target port for fault injection is required")
+				return fmt.Errorf("%w: target port for fault injection is required", agent.ErrInvalidFault)
+			}
+
+			if transparent && restricted {
+				return errTransparentRestricted
 			}
 
 			if transparent && (upstreamHost == "localhost" || upstreamHost == "127.0.0.1") {
 				// When running in transparent mode, the Redirector will also redirect traffic directed to 127.0.0.1 to
 				// the proxy. Using 127.0.0.1 as the proxy upstream would cause a redirection loop.
-				return fmt.Errorf("upstream host cannot be localhost when running in transparent mode")
+				return fmt.Errorf(
+					"%w: upstream host cannot be localhost when running in transparent mode",
+					agent.ErrInvalidFault,
+				)
+			}
+
+			ports, err := proxyPorts(cmd, config, port, targetPort)
+			if err != nil {
+				return err
+			}
+
+			if accessLog {
+				log, logErr := agent.OpenAccessLog(config.AccessLogFile)
+				if logErr != nil {
+					return logErr
+				}
+				defer log.Close() //nolint:errcheck
+
+				proxyConfig.AccessLog = log
 			}
 
 			agent, err := agent.Start(env, config)
@@ -48,15 +75,16 @@ func BuildHTTPCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 
 			defer agent.Stop()
 
-			listenAddress := net.JoinHostPort("", fmt.Sprint(port))
 			upstreamAddress := "http://" + net.JoinHostPort(upstreamHost, fmt.Sprint(targetPort))
 
-			listener, err := net.Listen("tcp", listenAddress)
+			// the proxy does not listen on the target port, which is redirected to it
+			listener, proxyPort, err := ports.Listen(append(slices.Clone(config.ExcludedPorts), targetPort))
 			if err != nil {
-				return fmt.Errorf("setting up listener at %q: %w", listenAddress, err)
+				return fmt.Errorf("setting up listener: %w", err)
 			}
+			agent.ReportProxy(proxyPort, net.JoinHostPort(upstreamHost, fmt.Sprint(targetPort)))
 
-			proxy, err := http.NewProxy(listener, upstreamAddress, disruption)
+			proxy, err := http.NewProxy(listener, upstreamAddress, disruption, proxyConfig)
 			if err != nil {
 				return err
 			}
@@ -66,10 +94,16 @@ func BuildHTTPCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 			if transparent {
 				tr := &protocol.TrafficRedirectionSpec{
 					DestinationPort: targetPort, // Redirect traffic from the application (target) port...
-					RedirectPort:    port,       // to the proxy port.
+					RedirectPort:    proxyPort,  // to the proxy port.
+					IstioSidecar:    istioSidecar,
+				}
+
+				// in the network namespace of the node, only the traffic directed to the target is redirected
+				if hostNetwork {
+					tr.DestinationAddress = upstreamHost
 				}
 
-				redirector, err = protocol.NewTrafficRedirector(tr, iptables.New(env.Executor()))
+				redirector, err = protocol.NewBackendTrafficRedirector(config.Interception, tr, env.Executor())
 				if err != nil {
 					return err
 				}
@@ -98,11 +132,50 @@ func BuildHTTPCmd(env runtime.Environment, config *agent.Config) *cobra.Command
 	cmd.Flags().StringVarP(&disruption.ErrorBody, "body", "b", "", "body for injected faults")
 	cmd.Flags().StringSliceVarP(&disruption.Excluded, "exclude", "x", []string{}, "comma-separated list of path(s)"+
 		" to be excluded from disruption")
-	cmd.Flags().BoolVar(&transparent, "transparent", true, "run as transparent proxy")
+	cmd.Flags().DurationVar(&disruption.RampDuration, "ramp-duration", 0,
+		"duration of the linear increase of the delay and error rate from zero")
+	cmd.Flags().BoolVar(&transparent, "transparent", !restricted, "run as transparent proxy")
 	cmd.Flags().StringVar(&upstreamHost, "upstream-host", "localhost",
 		"upstream host to redirect traffic to")
 	cmd.Flags().UintVarP(&port, "port", "p", 8000, "port the proxy will listen to")
+	cmd.Flags().BoolVar(&hostNetwork, "host-network", false, "the target shares the network of the node."+
+		" Only traffic directed to the upstream host is redirected")
+	cmd.Flags().BoolVar(&istioSidecar, "istio", false, "the target has an Istio sidecar."+
+		" Traffic forwarded by the sidecar is also redirected")
 	cmd.Flags().UintVarP(&targetPort, "target", "t", 0, "port the proxy will redirect request to")
+	cmd.Flags().BoolVar(&proxyConfig.UpstreamKeepAlive, "upstream-keep-alive", proxyConfig.UpstreamKeepAlive,
+		"reuse the connections to the upstream between requests")
+	cmd.Flags().UintVar(&proxyConfig.UpstreamMaxIdleConns, "upstream-max-idle-conns", proxyConfig.UpstreamMaxIdleConns,
+		"maximum number of idle connections kept open to the upstream")
+	cmd.Flags().UintVar(&proxyConfig.UpstreamMaxConns, "upstream-max-conns", proxyConfig.UpstreamMaxConns,
+		"maximum number of connections to the upstream. 0 means no limit")
+	cmd.Flags().DurationVar(&proxyConfig.UpstreamIdleTimeout, "upstream-idle-timeout", proxyConfig.UpstreamIdleTimeout,
+		"maximum time an idle connection to the upstream is kept open")
+	cmd.Flags().DurationVar(&proxyConfig.UpstreamDialTimeout, "upstream-dial-timeout", 0,
+		"maximum time for establishing a connection to the upstream. 0 uses the default timeout (30s)")
+	cmd.Flags().DurationVar(&proxyConfig.UpstreamResponseTimeout, "upstream-response-timeout", 0,
+		"maximum time waiting for the response of the upstream to a request. 0 means no limit")
+	cmd.Flags().UintVar(&proxyConfig.ReadBufferSize, "read-buffer-size", 0,
+		"size in bytes of the buffers for reading from the connections. 0 uses the default size")
+	cmd.Flags().UintVar(&proxyConfig.WriteBufferSize, "write-buffer-size", 0,
+		"size in bytes of the buffers for writing to the connections. 0 uses the default size")
+	cmd.Flags().UintVar(&proxyConfig.MaxHeaderBytes, "max-header-bytes", 0,
+		"maximum size in bytes of the headers of requests and responses. 0 uses the default limits")
+	cmd.Flags().UintVar(&proxyConfig.MaxConns, "max-conns", 0,
+		"maximum number of concurrent connections accepted by the proxy. 0 means no limit")
+	cmd.Flags().StringVar(&proxyConfig.TLS.Mode, "tls-mode", "", "handling of the traffic of targets that serve TLS:"+
+		" passthrough (only connection delays), terminate or mtls. By default, the traffic is handled as plain http")
+	cmd.Flags().StringVar(&proxyConfig.TLS.CertFile, "tls-cert", "", "certificate presented to the clients")
+	cmd.Flags().StringVar(&proxyConfig.TLS.KeyFile, "tls-key", "", "key of the certificate presented to the clients")
+	cmd.Flags().StringVar(&proxyConfig.TLS.CAFile, "tls-ca", "", "CA for verifying the certificates of the upstream"+
+		" and, in mtls mode, of the clients")
+	cmd.Flags().StringVar(&proxyConfig.TLS.ServerName, "tls-server-name", "",
+		"name for verifying the certificate of the upstream")
+	cmd.Flags().StringVar(&proxyConfig.TLS.ClientCertFile, "tls-client-cert", "",
+		"certificate presented to the upstream in mtls mode. Defaults to the certificate presented to the clients")
+	cmd.Flags().StringVar(&proxyConfig.TLS.ClientKeyFile, "tls-client-key", "",
+		"key of the certificate presented to the upstream in mtls mode")
+	cmd.Flags().BoolVar(&accessLog, "access-log", false, "report the requests handled by the proxy in the access log")
 
 	return cmd
 }
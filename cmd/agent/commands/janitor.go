@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// BuildJanitorCmd returns a cobra command with the specification of the janitor command
+func BuildJanitorCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var interval time.Duration
+	var controlPort uint
+
+	cmd := &cobra.Command{
+		Use:   "janitor",
+		Short: "removes the resources left behind by agents that terminated unexpectedly",
+		Long: "Periodically removes the resources (e.g. iptables rules) left behind by agents that terminated" +
+			" unexpectedly, while no agent is running. Runs until it receives a termination signal.\n" +
+			"Once started, reports the agent is ready for applying disruptions.\n" +
+			"If a control port is given, serves the control API of the agent in the loopback interface.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if interval <= 0 {
+				return fmt.Errorf("interval must be greater than zero")
+			}
+
+			sc := env.Signal().Notify(syscall.SIGTERM, syscall.SIGINT)
+			defer env.Signal().Reset()
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			err := agent.MarkReady(config.ReadyFile)
+			if err != nil {
+				return err
+			}
+			defer os.Remove(config.ReadyFile) //nolint:errcheck // the container terminates with the janitor
+
+			controlErr := make(chan error, 1)
+			if controlPort != 0 {
+				server := &http.Server{
+					Addr:              net.JoinHostPort("127.0.0.1", fmt.Sprint(controlPort)),
+					Handler:           agent.NewControlHandler(env, config, os.Args[0]),
+					ReadHeaderTimeout: 10 * time.Second,
+				}
+				go func() {
+					controlErr <- server.ListenAndServe()
+				}()
+				defer server.Close() //nolint:errcheck // the container terminates with the janitor
+			}
+
+			for {
+				select {
+				case <-sc:
+					return nil
+				case <-cmd.Context().Done():
+					return nil
+				case serveErr := <-controlErr:
+					return fmt.Errorf("serving control API: %w", serveErr)
+				case <-ticker.C:
+					// errors are reported but do not stop the janitor, as the next attempt may succeed
+					if err := cleanupIdle(env); err != nil {
+						fmt.Fprintf(os.Stderr, "removing leftover rules: %v\n", err)
+					}
+				}
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", 10*time.Second, "interval between cleanups")
+	cmd.Flags().UintVar(&controlPort, "control-port", 0, "port of the control API. 0 disables the control API")
+
+	return cmd
+}
+
+// cleanupIdle removes the leftover rules if no agent is running. The agent lock is held during the cleanup, so an
+// agent cannot start applying a fault while its rules are being removed.
+func cleanupIdle(env runtime.Environment) error {
+	acquired, err := env.Lock().Acquire()
+	if err != nil {
+		return fmt.Errorf("could not acquire process lock: %w", err)
+	}
+
+	if !acquired {
+		return nil
+	}
+
+	cleanupErr := removeLeftoverRules(env)
+
+	err = env.Lock().Release()
+	if err != nil {
+		return errors.Join(cleanupErr, fmt.Errorf("releasing process lock: %w", err))
+	}
+
+	return cleanupErr
+}
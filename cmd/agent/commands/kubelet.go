@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/node"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// BuildKubeletRestartCmd returns a cobra command with the specification of the kubelet-restart command
+func BuildKubeletRestartCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var duration time.Duration
+	disruption := node.KubeletRestartDisruption{}
+
+	cmd := &cobra.Command{
+		Use:   "kubelet-restart",
+		Short: "kubelet restart",
+		Long: "Stops the kubelet service in the host for the duration of the disruption." +
+			" Requires to run in a privileged container that shares the host's PID namespace.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+			defer agent.Stop()
+
+			disruptor, err := node.NewKubeletRestartDisruptor(env.Executor(), disruption)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().StringVarP(&disruption.Service, "service", "s", node.DefaultKubeletService,
+		"name of the kubelet's systemd service")
+
+	return cmd
+}
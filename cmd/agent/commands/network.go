@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/node"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// BuildNetworkCmd returns a cobra command with the specification of the network command
+func BuildNetworkCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var duration time.Duration
+	disruption := node.NetworkDisruption{}
+
+	cmd := &cobra.Command{
+		Use:   "network",
+		Short: "host network disruptor",
+		Long: "Delays and drops the traffic leaving the host." +
+			" Requires to run in the host network namespace with the NET_ADMIN capability.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+			defer agent.Stop()
+
+			disruptor, err := node.NewNetworkDisruptor(env.Executor(), disruption)
+			if err != nil {
+				return err
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "duration of the disruptions")
+	cmd.Flags().StringVarP(&disruption.Interface, "interface", "i", "",
+		"network interface to disrupt (defaults to the interface of the default route)")
+	cmd.Flags().DurationVarP(&disruption.Delay, "delay", "a", 0, "delay added to packets")
+	cmd.Flags().DurationVarP(&disruption.Jitter, "jitter", "v", 0, "variation in the delay added to packets")
+	cmd.Flags().Float32VarP(&disruption.Loss, "loss", "l", 0, "fraction of packets to drop")
+	cmd.Flags().StringSliceVarP(&disruption.ExcludeIPs, "exclude-ip", "x", nil,
+		"destination addresses whose traffic is not disrupted")
+	cmd.Flags().UintSliceVar(&disruption.ExcludePorts, "exclude-port", []uint{node.DefaultKubeletPort},
+		"source ports whose traffic is not disrupted")
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/spf13/cobra"
+)
+
+// proxyPorts returns the range of ports the proxy of the command can listen on. If the port of the proxy is set
+// explicitly, or the agent is not configured with a range of proxy ports, the range only contains the port of the
+// proxy. Returns an error if the target port or the port of the proxy are excluded.
+func proxyPorts(cmd *cobra.Command, config *agent.Config, port uint, targetPort uint) (agent.PortRange, error) {
+	if slices.Contains(config.ExcludedPorts, targetPort) {
+		return agent.PortRange{}, fmt.Errorf("%w: target port %d is excluded", agent.ErrInvalidFault, targetPort)
+	}
+
+	if cmd.Flags().Changed("port") || config.ProxyPorts == "" {
+		if slices.Contains(config.ExcludedPorts, port) {
+			return agent.PortRange{}, fmt.Errorf("%w: proxy port %d is excluded", agent.ErrInvalidFault, port)
+		}
+
+		return agent.PortRange{First: port, Last: port}, nil
+	}
+
+	portRange, err := agent.ParsePortRange(config.ProxyPorts)
+	if err != nil {
+		return agent.PortRange{}, fmt.Errorf("%w: %w", agent.ErrInvalidFault, err)
+	}
+
+	return portRange, nil
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/spf13/cobra"
+)
+
+// errNotReady is returned when the agent has not reported it is ready for applying disruptions
+var errNotReady = errors.New("agent is not ready")
+
+// BuildReadyCmd returns a cobra command with the specification of the ready command
+func BuildReadyCmd(config *agent.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ready",
+		Short: "checks if the agent is ready for applying disruptions",
+		Long:  "Checks if the agent is ready for applying disruptions. Fails if the agent has not reported it is ready.",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			ready, err := agent.IsReady(config.ReadyFile)
+			if err != nil {
+				return err
+			}
+
+			if !ready {
+				return errNotReady
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// repeatDisruptor applies a fault periodically until the duration of the disruption elapses
+type repeatDisruptor struct {
+	env    runtime.Environment
+	config *agent.Config
+	fault  []string
+	every  time.Duration
+}
+
+func (d *repeatDisruptor) Apply(ctx context.Context, duration time.Duration) error {
+	// the fault applied when the duration elapses is interrupted
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	ticker := time.NewTicker(d.every)
+	defer ticker.Stop()
+
+	for {
+		err := runFaultCmd(ctx, d.env, d.config, d.fault)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// BuildRepeatCmd returns a cobra command with the specification of the repeat command
+func BuildRepeatCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var duration time.Duration
+	var every time.Duration
+	var fault string
+
+	cmd := &cobra.Command{
+		Use:   "repeat",
+		Short: "applies a fault periodically",
+		Long: "Applies the fault defined by a fault command and its arguments encoded as a JSON array" +
+			" (e.g. --fault '[\"http\", \"-d\", \"60s\", \"-t\", \"80\"]') periodically, until the duration elapses.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if every <= 0 {
+				return fmt.Errorf("%w: the interval between faults must be greater than zero", agent.ErrInvalidFault)
+			}
+
+			args := []string{}
+			if err := json.Unmarshal([]byte(fault), &args); err != nil {
+				return fmt.Errorf("%w %q: %w", agent.ErrInvalidFault, fault, err)
+			}
+
+			if len(args) == 0 || (!isFaultCmd(args[0]) && args[0] != "compose") {
+				return fmt.Errorf("%w: %q is not a fault command", agent.ErrInvalidFault, fault)
+			}
+
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			disruptor := &repeatDisruptor{
+				env:    env,
+				config: config,
+				fault:  args,
+				every:  every,
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "total duration of the disruption")
+	cmd.Flags().DurationVar(&every, "every", 0, "interval between the start of each fault")
+	cmd.Flags().StringVar(&fault, "fault", "", "fault command as a JSON array of arguments")
+
+	return cmd
+}
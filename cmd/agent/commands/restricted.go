@@ -0,0 +1,22 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// restrictedEnvVar is the environment variable that informs the agent it runs without the NET_ADMIN capability
+const restrictedEnvVar = "XK6_DISRUPTOR_AGENT_RESTRICTED"
+
+// errTransparentRestricted is returned when a transparent proxy is requested in restricted mode
+var errTransparentRestricted = fmt.Errorf(
+	"%w: running as transparent proxy requires the NET_ADMIN capability, which the agent does not have in restricted mode",
+	agent.ErrPermissionDenied,
+)
+
+// isRestricted returns if the agent runs in restricted mode. In this mode, the proxies are not transparent by default.
+func isRestricted(env runtime.Environment) bool {
+	return env.Vars()[restrictedEnvVar] == "true"
+}
@@ -2,9 +2,12 @@ package commands
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 	"github.com/grafana/xk6-disruptor/pkg/runtime/profiler"
 
@@ -13,8 +16,20 @@ import (
 
 // RootCommand maintains the state for executing a command on the Agent
 type RootCommand struct {
-	cmd *cobra.Command
-	env runtime.Environment
+	cmd    *cobra.Command
+	env    runtime.Environment
+	config *agent.Config
+}
+
+// unloggedCmd returns if the execution of the command is not reported in the log of the agent's container, as it
+// does not change the state of the agent and is executed frequently
+func unloggedCmd(name string) bool {
+	switch name {
+	case "janitor", "webhook", "ready", "health", "version", "status", "verify", "access-log", "help":
+		return true
+	default:
+		return false
+	}
 }
 
 // NewRootCommand builds the for the agent that parses the configuration arguments
@@ -24,24 +39,85 @@ func NewRootCommand(env runtime.Environment) *RootCommand {
 	}
 
 	rootCmd := buildRootCmd(config)
-	rootCmd.AddCommand(BuildHTTPCmd(env, config))
-	rootCmd.AddCommand(BuildGrpcCmd(env, config))
-	rootCmd.AddCommand(BuildTCPDropCmd(env, config))
-	rootCmd.AddCommand(BuildStressCmd(env, config))
+	addFaultCmds(rootCmd, env, config)
+	rootCmd.AddCommand(BuildComposeCmd(env, config))
+	rootCmd.AddCommand(BuildTimelineCmd(env, config))
+	rootCmd.AddCommand(BuildRepeatCmd(env, config))
 	rootCmd.AddCommand(BuiltCleanupCmd(env))
+	rootCmd.AddCommand(BuildJanitorCmd(env, config))
+	rootCmd.AddCommand(BuildReadyCmd(config))
+	rootCmd.AddCommand(BuildHealthCmd(env, config))
+	rootCmd.AddCommand(BuildVersionCmd())
+	rootCmd.AddCommand(BuildStopCmd(env))
+	rootCmd.AddCommand(BuildShutdownCmd(env, config))
+	rootCmd.AddCommand(BuildStatusCmd(env, config))
+	rootCmd.AddCommand(BuildAccessLogCmd(config))
+	rootCmd.AddCommand(BuildVerifyCmd(env))
+	rootCmd.AddCommand(BuildWebhookCmd(env))
 
 	return &RootCommand{
-		cmd: rootCmd,
-		env: env,
+		cmd:    rootCmd,
+		env:    env,
+		config: config,
 	}
 }
 
-// Execute executes the RootCommand
+// Execute executes the RootCommand, reporting its result in the log of the agent's container
 func (c *RootCommand) Execute(ctx context.Context) error {
 	rootArgs := c.env.Args()[1:]
 	c.cmd.SetArgs(rootArgs)
 
-	return c.cmd.ExecuteContext(ctx)
+	started := time.Now()
+	cmd, err := c.cmd.ExecuteContextC(ctx)
+	if cmd == nil || cmd == c.cmd || !c.logged(cmd, err) {
+		return err
+	}
+
+	result := "finished"
+	if err != nil {
+		result = fmt.Sprintf("failed: %v", err)
+	}
+
+	log := agent.OpenContainerLog(c.config.ReadyFile)
+	defer log.Close() //nolint:errcheck
+
+	fmt.Fprintf(
+		log,
+		"%s %s: %s after %s\n",
+		started.UTC().Format(time.RFC3339),
+		strings.Join(rootArgs, " "),
+		result,
+		time.Since(started).Round(time.Millisecond),
+	)
+
+	return err
+}
+
+// logged returns if the execution of the command is reported in the log of the agent's container, according to the
+// log level
+func (c *RootCommand) logged(cmd *cobra.Command, err error) bool {
+	switch c.config.LogLevel {
+	case agent.LogLevelNone:
+		return false
+	case agent.LogLevelError:
+		return err != nil
+	case agent.LogLevelDebug:
+		return true
+	default:
+		return !unloggedCmd(cmd.Name())
+	}
+}
+
+// addFaultCmds adds the commands that apply faults to the root command
+func addFaultCmds(rootCmd *cobra.Command, env runtime.Environment, config *agent.Config) {
+	rootCmd.AddCommand(BuildHTTPCmd(env, config))
+	rootCmd.AddCommand(BuildGrpcCmd(env, config))
+	rootCmd.AddCommand(BuildTCPDropCmd(env, config))
+	rootCmd.AddCommand(BuildStressCmd(env, config))
+	rootCmd.AddCommand(BuildKubeletRestartCmd(env, config))
+	rootCmd.AddCommand(BuildNetworkCmd(env, config))
+	rootCmd.AddCommand(BuildClockSkewCmd(env, config))
+	rootCmd.AddCommand(BuildContainerCmd(env, config))
 }
 
 func buildRootCmd(c *agent.Config) *cobra.Command {
@@ -68,6 +144,34 @@ func buildRootCmd(c *agent.Config) *cobra.Command {
 		"metrics output file")
 	rootCmd.PersistentFlags().DurationVar(&c.Profiler.Metrics.Rate, "metrics-rate", time.Second,
 		"frequency of metrics sampling")
+	rootCmd.PersistentFlags().StringVar(&c.StatusFile, "status-file", agent.DefaultStatusFile(),
+		"file for reporting the status of the disruption")
+	rootCmd.PersistentFlags().StringVar(&c.ReadyFile, "ready-file", agent.DefaultReadyFile(),
+		"file for reporting the agent is ready for applying disruptions")
+	rootCmd.PersistentFlags().StringVar(&c.LogLevel, "log-level", agent.LogLevelInfo,
+		"commands reported in the log of the agent's container: none, error, info or debug")
+	rootCmd.PersistentFlags().StringVar(&c.ProxyPorts, "proxy-ports", "",
+		"range of ports (e.g. 8000-8099) the proxies listen on if their port is not specified")
+	rootCmd.PersistentFlags().UintSliceVar(&c.ExcludedPorts, "excluded-ports", []uint{},
+		"comma-separated list of ports that cannot be disrupted or used by the proxies")
+	rootCmd.PersistentFlags().StringVar(&c.Interception, "interception", protocol.BackendAuto,
+		"backend that redirects the traffic to the proxies: auto, iptables or nftables")
+	rootCmd.PersistentFlags().StringVar(&c.AccessLogFile, "access-log-file", agent.DefaultAccessLogFile(),
+		"file for reporting the requests handled by the proxies, if the access log is enabled")
+
+	rootCmd.PersistentPreRunE = func(_ *cobra.Command, _ []string) error {
+		err := agent.ValidateLogLevel(c.LogLevel)
+		if err != nil {
+			return err
+		}
+
+		return protocol.ValidateBackend(c.Interception)
+	}
+
+	// errors in the flags of the commands are the parameters of the faults, therefore the fault is not valid
+	rootCmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return fmt.Errorf("%w: %w", agent.ErrInvalidFault, err)
+	})
 
 	return rootCmd
 }
@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"fmt"
+	"syscall"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// shutdownCheckInterval is the interval for checking if the running instance of the agent terminated
+const shutdownCheckInterval = 100 * time.Millisecond
+
+// BuildShutdownCmd returns a cobra command with the specification of the shutdown command
+func BuildShutdownCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "shutdown",
+		Short: "stops any ongoing fault injection, cleans resources and terminates the agent",
+		Long: "Stops any ongoing fault injection, removes the resources left behind by the faults and terminates" +
+			" the janitor, which terminates the agent's container.",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			runningProcess := env.Lock().Owner()
+			// the running instance cleans its resources when terminated
+			if runningProcess != -1 {
+				err := agent.SignalProcess(runningProcess, syscall.SIGTERM)
+				if err != nil {
+					return fmt.Errorf("stopping fault injection: %w", err)
+				}
+			}
+
+			expired := time.After(timeout)
+			for env.Lock().Owner() != -1 {
+				select {
+				case <-expired:
+					return fmt.Errorf("fault injection has not stopped after %s", timeout)
+				case <-time.After(shutdownCheckInterval):
+				}
+			}
+
+			err := removeLeftoverRules(env)
+			if err != nil {
+				return err
+			}
+
+			janitor, err := agent.ReadyOwner(config.ReadyFile)
+			if err != nil {
+				return err
+			}
+
+			// the agent was not started by a janitor
+			if janitor == -1 {
+				return nil
+			}
+
+			return agent.SignalProcess(janitor, syscall.SIGTERM)
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "maximum time to wait for the fault injection to stop")
+
+	return cmd
+}
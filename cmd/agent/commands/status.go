@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// BuildStatusCmd returns a cobra command with the specification of the status command
+func BuildStatusCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "reports the status of the last disruption applied by the agent",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			status, err := agent.ReadStatus(config.StatusFile)
+			if err != nil {
+				return err
+			}
+
+			// the agent was terminated without updating the status
+			if status.State == agent.StateActive && env.Lock().Owner() == -1 {
+				status.State = agent.StateFailed
+				status.Remaining = 0
+				status.Error = "agent terminated unexpectedly"
+			}
+
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(status)
+		},
+	}
+
+	return cmd
+}
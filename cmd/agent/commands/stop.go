@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// BuildStopCmd returns a cobra command with the specification of the stop command
+func BuildStopCmd(env runtime.Environment) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "stop",
+		Short: "stops any ongoing fault injection without reporting it as an error",
+		RunE: func(_ *cobra.Command, _ []string) error {
+			runningProcess := env.Lock().Owner()
+			// no instance is currently running
+			if runningProcess == -1 {
+				return nil
+			}
+
+			return agent.SignalProcess(runningProcess, agent.StopSignal)
+		},
+	}
+
+	return cmd
+}
@@ -5,9 +5,12 @@ import (
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/agent/cgroup"
 	"github.com/grafana/xk6-disruptor/pkg/agent/stressors"
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 	"github.com/spf13/cobra"
+
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // BuildStressCmd returns a cobra command with the specification of the stress command
@@ -15,12 +18,41 @@ func BuildStressCmd(env runtime.Environment, config *agent.Config) *cobra.Comman
 	var duration time.Duration
 	var disruption stressors.ResourceDisruption
 	var opts stressors.ResourceStressOptions
+	var memory string
+	var ioFileSize string
+	var cgroupRoot string
+	var enforceLimits bool
 
 	cmd := &cobra.Command{
 		Use:   "stress",
 		Short: "resource stressor",
-		Long:  "Stress CPU resource",
+		Long: "Stress CPU, Memory and I/O resources. The stress is checked against the limits of the cgroup" +
+			" of the agent and a warning is reported if the limits cannot hold it, unless the limits are enforced.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if memory != "" {
+				quantity, err := resource.ParseQuantity(memory)
+				if err != nil {
+					return fmt.Errorf("%w: invalid memory %q: %w", agent.ErrInvalidFault, memory, err)
+				}
+				disruption.Bytes = uint64(quantity.Value())
+			}
+
+			if disruption.Workers > 0 {
+				quantity, err := resource.ParseQuantity(ioFileSize)
+				if err != nil {
+					return fmt.Errorf("%w: invalid I/O file size %q: %w", agent.ErrInvalidFault, ioFileSize, err)
+				}
+				disruption.FileSize = uint64(quantity.Value())
+			}
+
+			err := checkStressLimits(disruption, cgroupRoot)
+			if err != nil {
+				if enforceLimits {
+					return err
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", err)
+			}
+
 			agent, err := agent.Start(env, config)
 			if err != nil {
 				return fmt.Errorf("initializing agent: %w", err)
@@ -39,6 +71,27 @@ func BuildStressCmd(env runtime.Environment, config *agent.Config) *cobra.Comman
 	cmd.Flags().DurationVarP(&opts.Slice, "slice", "s", 100, "CPU stress cycle in milliseconds (default 100ms)")
 	cmd.Flags().IntVarP(&disruption.Load, "load", "l", 100, "CPU load percentage (default 100%)")
 	cmd.Flags().IntVarP(&disruption.CPUs, "cpus", "c", 1, "number of CPUs to stress (default 1)")
+	cmd.Flags().StringVarP(&memory, "memory", "m", "", "amount of memory to consume (e.g. 512Mi, 1Gi)")
+	cmd.Flags().IntVarP(&disruption.Workers, "io-workers", "i", 0, "number of files written concurrently"+
+		" for stressing the I/O")
+	cmd.Flags().StringVar(&ioFileSize, "io-file-size", "64Mi", "size of the files written for stressing the I/O")
+	cmd.Flags().StringVar(&disruption.Dir, "io-dir", "", "directory where the files for stressing the I/O are"+
+		" written (default temporary directory)")
+	cmd.Flags().StringVar(&cgroupRoot, "cgroup-root", cgroup.DefaultRoot, "path where the cgroup hierarchy"+
+		" (v1 or v2) is mounted")
+	cmd.Flags().BoolVar(&enforceLimits, "enforce-limits", false, "fail if the limits of the cgroup cannot hold"+
+		" the stress instead of reporting a warning")
 
 	return cmd
 }
+
+// checkStressLimits returns an error if the disruption cannot be applied within the limits of the cgroup mounted at
+// the root path
+func checkStressLimits(disruption stressors.ResourceDisruption, cgroupRoot string) error {
+	h, err := cgroup.Detect(cgroupRoot)
+	if err != nil {
+		return fmt.Errorf("detecting cgroup: %w", err)
+	}
+
+	return stressors.CheckLimits(disruption, h)
+}
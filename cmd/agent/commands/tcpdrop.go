@@ -24,7 +24,7 @@ func BuildTCPDropCmd(env runtime.Environment, config *agent.Config) *cobra.Comma
 			" Requires either to be run as root, or the NET_ADMIN capability.",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if filter.Port == 0 {
-				return fmt.Errorf("target port for fault injection is required")
+				return fmt.Errorf("%w: target port for fault injection is required", agent.ErrInvalidFault)
 			}
 
 			agent, err := agent.Start(env, config)
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// timelineDisruptor applies a sequence of faults. If any fault fails, the following faults are not applied.
+type timelineDisruptor struct {
+	env    runtime.Environment
+	config *agent.Config
+	steps  [][]string
+}
+
+func (d *timelineDisruptor) Apply(ctx context.Context, _ time.Duration) error {
+	for i, args := range d.steps {
+		err := runFaultCmd(ctx, d.env, d.config, args)
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// BuildTimelineCmd returns a cobra command with the specification of the timeline command
+func BuildTimelineCmd(env runtime.Environment, config *agent.Config) *cobra.Command {
+	var duration time.Duration
+	var steps []string
+
+	cmd := &cobra.Command{
+		Use:   "timeline",
+		Short: "applies a sequence of faults",
+		Long: "Applies in sequence the faults defined by the steps of the timeline. Each step is a fault command" +
+			" and its arguments encoded as a JSON array (e.g. --step '[\"http\", \"-d\", \"120s\", \"-t\", \"80\"]')." +
+			" The duration of each step is defined by the duration of its fault command.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if len(steps) == 0 {
+				return fmt.Errorf("at least one step is required")
+			}
+
+			timeline := make([][]string, 0, len(steps))
+			for _, step := range steps {
+				args := []string{}
+				if err := json.Unmarshal([]byte(step), &args); err != nil {
+					return fmt.Errorf("invalid step %q: %w", step, err)
+				}
+
+				if len(args) == 0 || (!isFaultCmd(args[0]) && args[0] != "compose") {
+					return fmt.Errorf("step %q is not a fault command", step)
+				}
+
+				timeline = append(timeline, args)
+			}
+
+			agent, err := agent.Start(env, config)
+			if err != nil {
+				return fmt.Errorf("initializing agent: %w", err)
+			}
+
+			defer agent.Stop()
+
+			disruptor := &timelineDisruptor{
+				env:    env,
+				config: config,
+				steps:  timeline,
+			}
+
+			return agent.ApplyDisruption(cmd.Context(), disruptor, duration)
+		},
+	}
+
+	cmd.Flags().DurationVarP(&duration, "duration", "d", 0, "total duration of the timeline")
+	cmd.Flags().StringArrayVar(&steps, "step", []string{}, "fault command of a step as a JSON array of arguments")
+
+	return cmd
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/xk6-disruptor/pkg/iptables"
+	"github.com/grafana/xk6-disruptor/pkg/nftables"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+// verification reports if the faults applied by the agent were removed
+type verification struct {
+	// Active is true if an agent is still applying a disruption
+	Active bool `json:"active"`
+	// Rules are the iptables and nftables rules created by the agent that remain in place
+	Rules []string `json:"rules,omitempty"`
+}
+
+// leftoverRules returns the iptables and nftables rules created by the agent that remain in place
+func leftoverRules(env runtime.Environment) ([]string, error) {
+	ipt := iptables.New(env.Executor())
+	nft := nftables.New(env.Executor())
+	if !nft.Available() {
+		return ipt.ListTagged("nat", "filter")
+	}
+
+	rules, err := nft.ListRules()
+	if err != nil {
+		return nil, err
+	}
+
+	// nodes that only support nftables fail when running iptables commands
+	if !ipt.Available() {
+		return rules, nil
+	}
+
+	tagged, err := ipt.ListTagged("nat", "filter")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(tagged, rules...), nil
+}
+
+// BuildVerifyCmd returns a cobra command with the specification of the verify command
+func BuildVerifyCmd(env runtime.Environment) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "verifies the faults applied by the agent were removed",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			rules, err := leftoverRules(env)
+			if err != nil {
+				return err
+			}
+
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(verification{
+				Active: env.Lock().Owner() != -1,
+				Rules:  rules,
+			})
+		},
+	}
+
+	return cmd
+}
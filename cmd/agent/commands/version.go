@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"encoding/json"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/spf13/cobra"
+)
+
+// BuildVersionCmd returns a cobra command with the specification of the version command
+func BuildVersionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "reports the version of the agent and the commands it supports",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			commands := []string{}
+			for _, command := range cmd.Root().Commands() {
+				commands = append(commands, command.Name())
+			}
+
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(agent.NewInfo(commands))
+		},
+	}
+
+	return cmd
+}
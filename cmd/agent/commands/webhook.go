@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/grafana/xk6-disruptor/pkg/webhook"
+	"github.com/spf13/cobra"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// BuildWebhookCmd returns a cobra command with the specification of the webhook command
+func BuildWebhookCmd(env runtime.Environment) *cobra.Command {
+	config := webhook.Config{}
+	var pullPolicy string
+	var port uint
+	var certFile string
+	var keyFile string
+	var controlAPI bool
+
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "mutating admission webhook that adds the agent as a sidecar",
+		Long: "Runs a mutating admission webhook that adds the agent as a sidecar container to the pods annotated" +
+			" with " + webhook.SidecarAnnotation + "=true. Runs until it receives a termination signal.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if config.Image == "" {
+				return fmt.Errorf("agent image is required")
+			}
+
+			if certFile == "" || keyFile == "" {
+				return fmt.Errorf("TLS certificate and key are required")
+			}
+
+			config.ImagePullPolicy = corev1.PullPolicy(pullPolicy)
+			if controlAPI {
+				config.ControlPort = agent.DefaultControlPort
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle("/mutate", webhook.Handler(config))
+			server := &http.Server{
+				Addr:              net.JoinHostPort("", fmt.Sprint(port)),
+				Handler:           mux,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			sc := env.Signal().Notify(syscall.SIGTERM, syscall.SIGINT)
+			defer env.Signal().Reset()
+
+			serverErr := make(chan error, 1)
+			go func() {
+				serverErr <- server.ListenAndServeTLS(certFile, keyFile)
+			}()
+
+			select {
+			case err := <-serverErr:
+				return err
+			case <-sc:
+			case <-cmd.Context().Done():
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			err := server.Shutdown(ctx)
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&config.Image, "image", "", "image of the agent sidecar")
+	cmd.Flags().StringVar(&pullPolicy, "image-pull-policy", string(corev1.PullIfNotPresent),
+		"pull policy of the agent image")
+	cmd.Flags().UintVarP(&port, "port", "p", 8443, "port the webhook listens to")
+	cmd.Flags().BoolVar(&controlAPI, "control-api", false, "enable the control API of the agent sidecar")
+	cmd.Flags().StringVar(&certFile, "tls-cert", "", "TLS certificate file")
+	cmd.Flags().StringVar(&keyFile, "tls-key", "", "TLS key file")
+
+	return cmd
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/grafana/xk6-disruptor/cmd/agent/commands"
+	"github.com/grafana/xk6-disruptor/pkg/agent"
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 )
 
@@ -17,6 +18,6 @@ func main() {
 
 	if err := rootCmd.Execute(context.Background()); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
-		os.Exit(1)
+		os.Exit(agent.ExitCode(err))
 	}
 }
@@ -4,29 +4,45 @@ package disruptor
 
 import (
 	"fmt"
+	"os"
+	"sync"
 
-	"go.k6.io/k6/js/common"
+	"go.k6.io/k6/event"
 	"go.k6.io/k6/js/modules"
 
 	"github.com/grafana/sobek"
 
 	"github.com/grafana/xk6-disruptor/pkg/api"
+	"github.com/grafana/xk6-disruptor/pkg/disruptors"
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
 )
 
 func init() {
-	modules.Register("k6/x/disruptor", new(RootModule))
+	modules.Register("k6/x/disruptor", New())
 }
 
 // RootModule is the global module object type. It is instantiated once per test
 // run and will be used to create `k6/x/disruptor` module instances for each VU.
-type RootModule struct{}
+type RootModule struct {
+	once sync.Once
+	// instances of the Kubernetes helpers for the clusters used by the test, shared by all the VUs
+	clusters *kubernetes.Clusters
+}
+
+// New returns a RootModule
+func New() *RootModule {
+	return &RootModule{
+		clusters: kubernetes.NewClusters(),
+	}
+}
 
 // ModuleInstance represents an instance of the JS module.
 type ModuleInstance struct {
 	vu modules.VU
-	// instance of a Kubernetes helper
+	// instance of a Kubernetes helper for the default cluster
 	k8s kubernetes.Kubernetes
+	// instances of the Kubernetes helpers for the clusters used by the test
+	clusters *kubernetes.Clusters
 }
 
 // Ensure the interfaces are implemented correctly.
@@ -36,16 +52,67 @@ var (
 )
 
 // NewModuleInstance returns a new instance of the disruptor module for each VU.
-func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
-	k8s, err := kubernetes.New()
+func (r *RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
+	r.once.Do(func() {
+		disruptors.InitRunID(testRun(vu))
+		r.stopOnExit(vu)
+	})
+
+	k8s, err := r.clusters.Get(kubernetes.Config{UserAgent: disruptors.UserAgent()})
 	if err != nil {
-		common.Throw(vu.Runtime(), fmt.Errorf("error creating Kubernetes helper: %w", err))
+		api.Throw(vu.Runtime(), fmt.Errorf("error creating Kubernetes helper: %w", err))
 	}
 
 	return &ModuleInstance{
-		vu:  vu,
-		k8s: k8s,
+		vu:       vu,
+		k8s:      k8s,
+		clusters: r.clusters,
+	}
+}
+
+// testRun returns the test run the VU is executed in. The environment variables passed to k6 with the --env flag take
+// precedence over those of the process.
+func testRun(vu modules.VU) disruptors.TestRun {
+	initEnv := vu.InitEnv()
+	if initEnv == nil || initEnv.TestPreInitState == nil {
+		return disruptors.TestRun{}
+	}
+
+	env := initEnv.RuntimeOptions.Env
+	lookupEnv := initEnv.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	return disruptors.TestRun{
+		LookupEnv: func(key string) (string, bool) {
+			if value, found := env[key]; found {
+				return value, true
+			}
+
+			return lookupEnv(key)
+		},
+	}
+}
+
+// stopOnExit stops watching the clusters used by the test when the k6 process exits
+func (r *RootModule) stopOnExit(vu modules.VU) {
+	events := vu.Events().Global
+	if events == nil {
+		return
 	}
+
+	id, exit := events.Subscribe(event.Exit)
+	go func() {
+		e, ok := <-exit
+		if !ok {
+			return
+		}
+
+		r.clusters.Stop()
+		e.Done()
+		events.Unsubscribe(id)
+	}()
 }
 
 // Exports implements the modules.Instance interface and returns the exports
@@ -53,8 +120,23 @@ func (*RootModule) NewModuleInstance(vu modules.VU) modules.Instance {
 func (m *ModuleInstance) Exports() modules.Exports {
 	return modules.Exports{
 		Named: map[string]interface{}{
-			"PodDisruptor":     m.newPodDisruptor,
-			"ServiceDisruptor": m.newServiceDisruptor,
+			"PodDisruptor":         m.newPodDisruptor,
+			"ServiceDisruptor":     m.newServiceDisruptor,
+			"NodeDisruptor":        m.newNodeDisruptor,
+			"Cluster":              m.newCluster,
+			"parseDefinition":      m.parseDefinition,
+			"cleanup":              m.cleanup,
+			"waitServiceReady":     m.waitServiceReady,
+			"portForward":          m.portForward,
+			"waitDeploymentReady":  m.waitDeploymentReady,
+			"waitStatefulSetReady": m.waitStatefulSetReady,
+			"podLogs":              m.podLogs,
+			"applyManifest":        m.applyManifest,
+			"runJob":               m.runJob,
+			"createNamespace":      m.createNamespace,
+			"deleteNamespace":      m.deleteNamespace,
+			"rbacManifest":         m.rbacManifest,
+			"runID":                disruptors.RunID,
 		},
 	}
 }
@@ -66,7 +148,7 @@ func (m *ModuleInstance) newPodDisruptor(c sobek.ConstructorCall) *sobek.Object
 
 	disruptor, err := api.NewPodDisruptor(ctx, rt, c, m.k8s)
 	if err != nil {
-		common.Throw(rt, fmt.Errorf("error creating PodDisruptor: %w", err))
+		api.Throw(rt, fmt.Errorf("error creating PodDisruptor: %w", err))
 	}
 	return disruptor
 }
@@ -78,8 +160,94 @@ func (m *ModuleInstance) newServiceDisruptor(c sobek.ConstructorCall) *sobek.Obj
 
 	disruptor, err := api.NewServiceDisruptor(ctx, rt, c, m.k8s)
 	if err != nil {
-		common.Throw(rt, fmt.Errorf("error creating ServiceDisruptor: %w", err))
+		api.Throw(rt, fmt.Errorf("error creating ServiceDisruptor: %w", err))
 	}
 
 	return disruptor
 }
+
+// creates an instance of a NodeDisruptor
+func (m *ModuleInstance) newNodeDisruptor(c sobek.ConstructorCall) *sobek.Object {
+	rt := m.vu.Runtime()
+	ctx := m.vu.Context()
+
+	disruptor, err := api.NewNodeDisruptor(ctx, rt, c, m.k8s)
+	if err != nil {
+		api.Throw(rt, fmt.Errorf("error creating NodeDisruptor: %w", err))
+	}
+
+	return disruptor
+}
+
+// creates an instance of a Cluster for creating disruptors in a cluster other than the default
+func (m *ModuleInstance) newCluster(c sobek.ConstructorCall) *sobek.Object {
+	rt := m.vu.Runtime()
+	ctx := m.vu.Context()
+
+	cluster, err := api.NewCluster(ctx, rt, c, m.clusters)
+	if err != nil {
+		api.Throw(rt, fmt.Errorf("error creating Cluster: %w", err))
+	}
+
+	return cluster
+}
+
+// parses a fault or selector definition
+func (m *ModuleInstance) parseDefinition(args ...sobek.Value) sobek.Value {
+	return api.ParseDefinition(m.vu.Runtime(), args...)
+}
+
+// cleans up the agents injected during a test run
+func (m *ModuleInstance) cleanup(args ...sobek.Value) sobek.Value {
+	return api.Cleanup(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// waits for a service to have ready endpoints
+func (m *ModuleInstance) waitServiceReady(args ...sobek.Value) {
+	api.WaitServiceReady(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// forwards a local port to a port of a pod or service
+func (m *ModuleInstance) portForward(args ...sobek.Value) sobek.Value {
+	return api.PortForward(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// waits for the rollout of a deployment to complete
+func (m *ModuleInstance) waitDeploymentReady(args ...sobek.Value) {
+	api.WaitDeploymentReady(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// waits for the rollout of a statefulset to complete
+func (m *ModuleInstance) waitStatefulSetReady(args ...sobek.Value) {
+	api.WaitStatefulSetReady(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// returns the logs of a container of a pod
+func (m *ModuleInstance) podLogs(args ...sobek.Value) sobek.Value {
+	return api.PodLogs(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// applies the objects of a manifest
+func (m *ModuleInstance) applyManifest(args ...sobek.Value) sobek.Value {
+	return api.ApplyManifest(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// runs a job and waits for it to complete
+func (m *ModuleInstance) runJob(args ...sobek.Value) sobek.Value {
+	return api.RunJob(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// creates a namespace with a unique name
+func (m *ModuleInstance) createNamespace(args ...sobek.Value) sobek.Value {
+	return api.CreateNamespace(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// deletes a namespace and waits for it to be removed
+func (m *ModuleInstance) deleteNamespace(args ...sobek.Value) {
+	api.DeleteNamespace(m.vu.Context(), m.vu.Runtime(), m.k8s, args...)
+}
+
+// generates the RBAC resources required by the disruptors and faults used by a test
+func (m *ModuleInstance) rbacManifest(args ...sobek.Value) sobek.Value {
+	return api.RBACManifest(m.vu.Runtime(), args...)
+}
@@ -0,0 +1,130 @@
+//go:build e2e
+// +build e2e
+
+package e2e
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+
+	k8sintstr "k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/grafana/xk6-disruptor/pkg/disruptors"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/e2e/checks"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/e2e/cluster"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/e2e/deploy"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/e2e/fixtures"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/e2e/kubernetes/namespace"
+)
+
+func Test_IstioSidecar(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("istioctl"); err != nil {
+		t.Skip("istioctl is required for installing istio")
+	}
+
+	cluster, err := cluster.BuildE2eCluster(
+		cluster.DefaultE2eClusterConfig(),
+		cluster.WithName("e2e-istio"),
+		cluster.WithKubeconfig(filepath.Join(os.TempDir(), "e2e-istio")),
+		cluster.WithIngressPort(30081),
+		cluster.WithPostInstall(cluster.InstallIstio),
+	)
+	if err != nil {
+		t.Fatalf("failed to create cluster: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cluster.Cleanup()
+	})
+
+	err = cluster.Load(fixtures.BuildHttpbinPod().Spec.Containers[0].Image)
+	if err != nil {
+		t.Fatalf("preloading test pod images: %v", err)
+	}
+
+	k8s, err := kubernetes.NewFromKubeconfig(cluster.Kubeconfig())
+	if err != nil {
+		t.Fatalf("error creating kubernetes client: %v", err)
+	}
+
+	// pods created in the namespace are injected the istio sidecar
+	namespace, err := namespace.CreateTestNamespace(
+		context.TODO(),
+		t,
+		k8s.Client(),
+		namespace.WithLabel("istio-injection", "enabled"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create test namespace: %v", err)
+	}
+
+	service := fixtures.BuildHttpbinService()
+	err = deploy.ExposeApp(
+		k8s,
+		namespace,
+		fixtures.BuildHttpbinPod(),
+		1,
+		service,
+		k8sintstr.FromInt(80),
+		60*time.Second,
+	)
+	if err != nil {
+		t.Fatalf("error deploying application: %v", err)
+	}
+
+	selector := disruptors.PodSelectorSpec{
+		Namespace: namespace,
+		Select: disruptors.PodAttributes{
+			Labels: service.Spec.Selector,
+		},
+	}
+	disruptor, err := disruptors.NewPodDisruptor(context.TODO(), k8s, selector, disruptors.PodDisruptorOptions{})
+	if err != nil {
+		t.Fatalf("error creating selector: %v", err)
+	}
+
+	targets, _ := disruptor.Targets(context.TODO())
+	if len(targets) == 0 {
+		t.Fatalf("No pods matched the selector")
+	}
+
+	// apply disruption in a go-routine as it is a blocking function
+	go func() {
+		fault := disruptors.HTTPFault{
+			Port:      intstr.FromInt32(80),
+			ErrorRate: 1.0,
+			ErrorCode: 500,
+		}
+		options := disruptors.HTTPDisruptionOptions{
+			ProxyPort: 8080,
+		}
+
+		err := disruptor.InjectHTTPFaults(context.TODO(), fault, 10*time.Second, options)
+		if err != nil {
+			t.Logf("failed to setup disruptor: %v", err)
+		}
+	}()
+
+	// the traffic from the ingress is intercepted by the sidecar, which forwards it to the application
+	check := checks.HTTPCheck{
+		Service:      "httpbin",
+		Method:       "GET",
+		Path:         "/status/200",
+		Body:         []byte{},
+		ExpectedCode: 500,
+		Delay:        5 * time.Second,
+	}
+
+	err = check.Verify(k8s, cluster.Ingress(), namespace)
+	if err != nil {
+		t.Errorf("failed to access service: %v", err)
+	}
+}
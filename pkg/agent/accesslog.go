@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultAccessLogFile returns the default path of the file the proxies use for reporting the requests they handle
+func DefaultAccessLogFile() string {
+	return runtimeFile("access")
+}
+
+// OpenAccessLog opens the access log in the given file for appending entries. The entries of the previous
+// disruptions are kept, so the requests of all the disruptions can be retrieved after the experiment.
+// Callers must close the returned writer.
+func OpenAccessLog(path string) (io.WriteCloser, error) {
+	log, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening access log: %w", err)
+	}
+
+	return log, nil
+}
+
+// ReadAccessLog returns the content of the access log in the given file. If the file does not exist, the access log
+// is empty.
+func ReadAccessLog(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []byte{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading access log: %w", err)
+	}
+
+	return content, nil
+}
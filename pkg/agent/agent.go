@@ -16,6 +16,22 @@ import (
 // Config maintains the configuration for the execution of the agent
 type Config struct {
 	Profiler *profiler.Config
+	// StatusFile is the path of the file used for reporting the status of the disruption.
+	// An empty value disables the reporting.
+	StatusFile string
+	// ReadyFile is the path of the file the agent creates when it is ready for applying disruptions
+	ReadyFile string
+	// LogLevel defines the commands whose execution is reported in the log of the agent's container
+	LogLevel string
+	// ProxyPorts is the range of ports (e.g. 8000-8099) the proxies listen on when their port is not specified.
+	// An empty value uses the default port of each proxy.
+	ProxyPorts string
+	// ExcludedPorts are the ports that cannot be disrupted or used by the proxies
+	ExcludedPorts []uint
+	// Interception is the backend that redirects the traffic of the target to the proxies
+	Interception string
+	// AccessLogFile is the path of the file where the proxies report the requests they handle, if enabled
+	AccessLogFile string
 }
 
 // Agent maintains the state required for executing an agent command
@@ -23,6 +39,8 @@ type Agent struct {
 	env           runtime.Environment
 	sc            <-chan os.Signal
 	profileCloser io.Closer
+	statusFile    string
+	proxy         *ProxyStatus
 }
 
 // Disruptor defines the interface for applying disruptions
@@ -30,13 +48,26 @@ type Disruptor interface {
 	Apply(context.Context, time.Duration) error
 }
 
+// MetricsReporter is implemented by the disruptors that report metrics of the disruption
+type MetricsReporter interface {
+	Metrics() map[string]uint
+}
+
+// metricsInterval is the interval between the updates of the metrics in the status of an active disruption
+const metricsInterval = 5 * time.Second
+
 // Start creates and starts a new instance of an agent.
 // Returned agent is guaranteed to be unique in the environment it is running, and will handle signals sent to the
 // process.
 // Callers must Stop the returned agent at the end of its lifecycle.
 func Start(env runtime.Environment, config *Config) (*Agent, error) {
+	if err := checkPlatform(); err != nil {
+		return nil, err
+	}
+
 	a := &Agent{
-		env: env,
+		env:        env,
+		statusFile: config.StatusFile,
 	}
 
 	if err := a.start(config); err != nil {
@@ -48,7 +79,7 @@ func Start(env runtime.Environment, config *Config) (*Agent, error) {
 }
 
 func (a *Agent) start(config *Config) error {
-	a.sc = a.env.Signal().Notify(syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	a.sc = a.env.Signal().Notify(syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, StopSignal)
 
 	acquired, err := a.env.Lock().Acquire()
 	if err != nil {
@@ -56,7 +87,7 @@ func (a *Agent) start(config *Config) error {
 	}
 
 	if !acquired {
-		return fmt.Errorf("another instance of the agent is already running")
+		return ErrFaultActive
 	}
 
 	// start profiler
@@ -68,8 +99,88 @@ func (a *Agent) start(config *Config) error {
 	return nil
 }
 
-// ApplyDisruption applies a disruption to the target
+// ReportProxy reports the port and the upstream address (host:port) of the proxy used by the disruptions applied by
+// the agent. The proxy is included in the status of the disruptions for checking its health.
+func (a *Agent) ReportProxy(port uint, upstream string) {
+	a.proxy = &ProxyStatus{Port: port, Upstream: upstream}
+}
+
+// ApplyDisruption applies a disruption to the target, reporting its status
 func (a *Agent) ApplyDisruption(ctx context.Context, disruptor Disruptor, duration time.Duration) error {
+	status := Status{
+		State:     StateActive,
+		StartedAt: time.Now(),
+		Duration:  duration,
+		Proxy:     a.proxy,
+	}
+
+	// the agent holds the lock, therefore an active disruption was interrupted by the termination of the agent.
+	// If its duration has not elapsed, this disruption re-applies it (e.g. after the restart of the target)
+	previous, err := a.readStatus()
+	if err != nil {
+		return err
+	}
+	if previous.State == StateActive && previous.Remaining > 0 {
+		status.Reapplied = previous.Reapplied + 1
+	}
+
+	if err = a.updateStatus(status); err != nil {
+		return err
+	}
+
+	reporter, reportsMetrics := disruptor.(MetricsReporter)
+	reportMetrics := func() {
+		if reportsMetrics {
+			status.Metrics = reporter.Metrics()
+		}
+	}
+
+	err = a.applyDisruption(ctx, disruptor, duration, func() {
+		reportMetrics()
+		// errors are ignored, as the status is updated again when the disruption ends
+		_ = a.updateStatus(status)
+	})
+
+	reportMetrics()
+	status.State = StateFinished
+	if err != nil {
+		status.State = StateFailed
+		status.Error = err.Error()
+	}
+
+	// the error applying the disruption takes precedence over the error reporting the status
+	if statusErr := a.updateStatus(status); err == nil {
+		err = statusErr
+	}
+
+	return err
+}
+
+// readStatus returns the status of the last disruption, if reporting is enabled
+func (a *Agent) readStatus() (Status, error) {
+	if a.statusFile == "" {
+		return Status{}, nil
+	}
+
+	return ReadStatus(a.statusFile)
+}
+
+// updateStatus reports the status of the disruption, if enabled
+func (a *Agent) updateStatus(status Status) error {
+	if a.statusFile == "" {
+		return nil
+	}
+
+	return WriteStatus(a.statusFile, status)
+}
+
+// applyDisruption applies the disruption until it completes or is stopped, periodically calling the report function
+func (a *Agent) applyDisruption(
+	ctx context.Context,
+	disruptor Disruptor,
+	duration time.Duration,
+	report func(),
+) error {
 	// set context for command
 	ctx, cancel := context.WithCancel(ctx)
 
@@ -86,14 +197,24 @@ func (a *Agent) ApplyDisruption(ctx context.Context, disruptor Disruptor, durati
 
 	defer cancel()
 
+	ticker := time.NewTicker(metricsInterval)
+	defer ticker.Stop()
+
 	// wait for command completion or cancellation
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-cc:
-		return err
-	case s := <-a.sc:
-		return fmt.Errorf("received signal %q", s)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-cc:
+			return err
+		case s := <-a.sc:
+			if s == StopSignal {
+				return nil
+			}
+			return fmt.Errorf("received signal %q", s)
+		case <-ticker.C:
+			report()
+		}
 	}
 }
 
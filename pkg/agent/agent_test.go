@@ -3,6 +3,7 @@ package agent
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"syscall"
 	"testing"
 	"time"
@@ -110,6 +111,18 @@ func Test_Signals(t *testing.T) {
 			signal:    syscall.SIGINT,
 			expectErr: true,
 		},
+		{
+			title: "Command is stopped",
+			args:  []string{},
+			vars:  map[string]string{},
+			delay: 5 * time.Second,
+			err:   nil,
+			config: &Config{
+				Profiler: &profiler.Config{},
+			},
+			signal:    StopSignal,
+			expectErr: false,
+		},
 		{
 			title: "Command is not canceled with interrupt",
 			args:  []string{},
@@ -159,3 +172,157 @@ func Test_Signals(t *testing.T) {
 		})
 	}
 }
+
+func Test_Status(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		delay    time.Duration
+		cancel   bool
+		expected State
+	}{
+		{
+			title:    "disruption finished",
+			delay:    2 * time.Second,
+			cancel:   false,
+			expected: StateFinished,
+		},
+		{
+			title:    "disruption failed",
+			delay:    5 * time.Second,
+			cancel:   true,
+			expected: StateFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			env := runtime.NewFakeRuntime([]string{}, map[string]string{})
+			statusFile := filepath.Join(t.TempDir(), "status")
+
+			status, err := ReadStatus(statusFile)
+			if err != nil {
+				t.Fatalf("reading status: %v", err)
+			}
+			if status.State != StatePending {
+				t.Fatalf("expected state %q got %q", StatePending, status.State)
+			}
+
+			agent, err := Start(env, &Config{Profiler: &profiler.Config{}, StatusFile: statusFile})
+			if err != nil {
+				t.Fatalf("starting agent: %v", err)
+			}
+
+			defer agent.Stop()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			errCh := make(chan error)
+			go func() {
+				errCh <- agent.ApplyDisruption(ctx, &FakeProtocolDisruptor{}, tc.delay)
+			}()
+
+			time.Sleep(1 * time.Second)
+
+			status, err = ReadStatus(statusFile)
+			if err != nil {
+				t.Fatalf("reading status: %v", err)
+			}
+			if status.State != StateActive {
+				t.Fatalf("expected state %q got %q", StateActive, status.State)
+			}
+			if status.Remaining <= 0 || status.Remaining >= tc.delay {
+				t.Fatalf("unexpected remaining duration %s", status.Remaining)
+			}
+
+			if tc.cancel {
+				cancel()
+			}
+			<-errCh
+
+			status, err = ReadStatus(statusFile)
+			if err != nil {
+				t.Fatalf("reading status: %v", err)
+			}
+			if status.State != tc.expected {
+				t.Fatalf("expected state %q got %q", tc.expected, status.State)
+			}
+			if status.Remaining != 0 {
+				t.Fatalf("expected no remaining duration got %s", status.Remaining)
+			}
+		})
+	}
+}
+
+func Test_StatusReapplied(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		previous Status
+		expected int
+	}{
+		{
+			title:    "no previous disruption",
+			previous: Status{State: StatePending},
+			expected: 0,
+		},
+		{
+			title:    "previous disruption finished",
+			previous: Status{State: StateFinished, StartedAt: time.Now(), Duration: time.Minute},
+			expected: 0,
+		},
+		{
+			title:    "previous disruption interrupted",
+			previous: Status{State: StateActive, StartedAt: time.Now(), Duration: time.Minute, Reapplied: 1},
+			expected: 2,
+		},
+		{
+			title:    "previous disruption expired",
+			previous: Status{State: StateActive, StartedAt: time.Now().Add(-time.Hour), Duration: time.Minute},
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			env := runtime.NewFakeRuntime([]string{}, map[string]string{})
+			statusFile := filepath.Join(t.TempDir(), "status")
+
+			err := WriteStatus(statusFile, tc.previous)
+			if err != nil {
+				t.Fatalf("writing status: %v", err)
+			}
+
+			agent, err := Start(env, &Config{Profiler: &profiler.Config{}, StatusFile: statusFile})
+			if err != nil {
+				t.Fatalf("starting agent: %v", err)
+			}
+
+			defer agent.Stop()
+
+			err = agent.ApplyDisruption(context.Background(), &FakeProtocolDisruptor{}, time.Second)
+			if err != nil {
+				t.Fatalf("applying disruption: %v", err)
+			}
+
+			status, err := ReadStatus(statusFile)
+			if err != nil {
+				t.Fatalf("reading status: %v", err)
+			}
+			if status.Reapplied != tc.expected {
+				t.Fatalf("expected reapplied %d got %d", tc.expected, status.Reapplied)
+			}
+		})
+	}
+}
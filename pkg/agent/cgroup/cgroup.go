@@ -0,0 +1,312 @@
+// Package cgroup reads the controllers and limits of cgroups and freezes their processes, supporting both the cgroup
+// v1 and the cgroup v2 (unified) hierarchies.
+package cgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultRoot is the path where the cgroup hierarchy of the container is mounted
+const DefaultRoot = "/sys/fs/cgroup"
+
+// Version is the version of the cgroup hierarchy
+type Version int
+
+const (
+	// V1 is the legacy hierarchy, with a separate tree for each controller
+	V1 Version = 1
+	// V2 is the unified hierarchy, with a single tree for all the controllers
+	V2 Version = 2
+)
+
+func (v Version) String() string {
+	return fmt.Sprintf("v%d", v)
+}
+
+// Resources controlled by the cgroup controllers. The names are the ones of the controllers in cgroup v2.
+const (
+	CPU    = "cpu"
+	Memory = "memory"
+	IO     = "io"
+)
+
+// v1Controller returns the name of the controller of the resource in cgroup v1
+func v1Controller(resource string) string {
+	if resource == IO {
+		return "blkio"
+	}
+
+	return resource
+}
+
+// unlimitedV1 is the lowest value cgroup v1 reports for an unlimited memory. The value is the maximum int64 rounded
+// down to the page size, which depends on the platform.
+const unlimitedV1 = uint64(1) << 62
+
+// ErrControllerUnavailable is returned when a controller required for a resource is not available in the hierarchy
+var ErrControllerUnavailable = errors.New("cgroup controller not available")
+
+// Hierarchy is the cgroup hierarchy mounted at a root path
+type Hierarchy struct {
+	Version Version
+	Root    string
+	// controllers available in the hierarchy, by the name of their resource
+	controllers []string
+}
+
+// Detect returns the cgroup hierarchy mounted at the root path, with the controllers available in it.
+// cgroup v2 lists the controllers enabled for the cgroup in the cgroup.controllers file, while cgroup v1 mounts a
+// directory for each controller.
+func Detect(root string) (*Hierarchy, error) {
+	content, err := os.ReadFile(filepath.Join(root, "cgroup.controllers"))
+	if err == nil {
+		return &Hierarchy{
+			Version:     V2,
+			Root:        root,
+			controllers: strings.Fields(string(content)),
+		}, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("reading cgroup controllers: %w", err)
+	}
+
+	h := &Hierarchy{Version: V1, Root: root}
+	for _, resource := range []string{CPU, Memory, IO} {
+		info, statErr := os.Stat(filepath.Join(root, v1Controller(resource)))
+		if statErr == nil && info.IsDir() {
+			h.controllers = append(h.controllers, resource)
+		}
+	}
+
+	if len(h.controllers) == 0 {
+		return nil, fmt.Errorf("no cgroup hierarchy found at %s", root)
+	}
+
+	return h, nil
+}
+
+// Require returns an ErrControllerUnavailable error if the controller of any of the resources is not available
+func (h *Hierarchy) Require(resources ...string) error {
+	for _, resource := range resources {
+		if slices.Contains(h.controllers, resource) {
+			continue
+		}
+
+		if h.Version == V2 {
+			return fmt.Errorf(
+				"%w: the %s controller is not enabled in the cgroup %s hierarchy at %s."+
+					" It must be enabled in the cgroup.subtree_control file of the parent cgroup",
+				ErrControllerUnavailable, resource, h.Version, h.Root,
+			)
+		}
+
+		return fmt.Errorf(
+			"%w: the %s controller is not mounted in the cgroup %s hierarchy at %s",
+			ErrControllerUnavailable, v1Controller(resource), h.Version, h.Root,
+		)
+	}
+
+	return nil
+}
+
+// CPULimit returns the number of CPUs the cgroup can use, as defined by its CPU quota. Zero means no limit.
+func (h *Hierarchy) CPULimit() (float64, error) {
+	if err := h.Require(CPU); err != nil {
+		return 0, err
+	}
+
+	var quota, period string
+	if h.Version == V2 {
+		// the cpu.max file contains the quota, or "max", and the period
+		content, err := h.read("cpu.max")
+		if err != nil {
+			return 0, err
+		}
+
+		fields := strings.Fields(content)
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("invalid cpu.max %q", content)
+		}
+		quota, period = fields[0], fields[1]
+		if quota == "max" {
+			return 0, nil
+		}
+	} else {
+		var err error
+		quota, err = h.read(filepath.Join("cpu", "cpu.cfs_quota_us"))
+		if err != nil {
+			return 0, err
+		}
+		if quota == "-1" {
+			return 0, nil
+		}
+
+		period, err = h.read(filepath.Join("cpu", "cpu.cfs_period_us"))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	quotaValue, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid CPU quota %q: %w", quota, err)
+	}
+
+	periodValue, err := strconv.ParseFloat(period, 64)
+	if err != nil || periodValue == 0 {
+		return 0, fmt.Errorf("invalid CPU period %q", period)
+	}
+
+	return quotaValue / periodValue, nil
+}
+
+// MemoryLimit returns the maximum memory in bytes the cgroup can use. Zero means no limit.
+func (h *Hierarchy) MemoryLimit() (uint64, error) {
+	if err := h.Require(Memory); err != nil {
+		return 0, err
+	}
+
+	if h.Version == V2 {
+		content, err := h.read("memory.max")
+		if err != nil {
+			return 0, err
+		}
+		if content == "max" {
+			return 0, nil
+		}
+
+		return parseBytes(content)
+	}
+
+	content, err := h.read(filepath.Join("memory", "memory.limit_in_bytes"))
+	if err != nil {
+		return 0, err
+	}
+
+	limit, err := parseBytes(content)
+	if err != nil || limit >= unlimitedV1 {
+		return 0, err
+	}
+
+	return limit, nil
+}
+
+// MemoryUsage returns the memory in bytes currently used by the cgroup
+func (h *Hierarchy) MemoryUsage() (uint64, error) {
+	if err := h.Require(Memory); err != nil {
+		return 0, err
+	}
+
+	file := filepath.Join("memory", "memory.usage_in_bytes")
+	if h.Version == V2 {
+		file = "memory.current"
+	}
+
+	content, err := h.read(file)
+	if err != nil {
+		return 0, err
+	}
+
+	return parseBytes(content)
+}
+
+// IOLimited returns if the cgroup throttles the writes to any device, either in bytes or operations per second
+func (h *Hierarchy) IOLimited() (bool, error) {
+	if err := h.Require(IO); err != nil {
+		return false, err
+	}
+
+	if h.Version == V2 {
+		// each line of io.max has the format "major:minor rbps=max wbps=max riops=max wiops=max"
+		content, err := h.read("io.max")
+		if err != nil {
+			return false, err
+		}
+
+		for _, line := range strings.Split(content, "\n") {
+			for _, field := range strings.Fields(line) {
+				key, value, found := strings.Cut(field, "=")
+				if found && (key == "wbps" || key == "wiops") && value != "max" {
+					return true, nil
+				}
+			}
+		}
+
+		return false, nil
+	}
+
+	// the throttle files of cgroup v1 list the limited devices with the format "major:minor limit"
+	for _, file := range []string{"blkio.throttle.write_bps_device", "blkio.throttle.write_iops_device"} {
+		content, err := h.read(filepath.Join("blkio", file))
+		if err != nil {
+			return false, err
+		}
+
+		if content != "" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ProcessPath returns the path, relative to the root of the hierarchy, of the cgroup of a process given the content
+// of its /proc/<pid>/cgroup file. In cgroup v1, the path is the one in the freezer hierarchy.
+func (h *Hierarchy) ProcessPath(procCgroup string) (string, error) {
+	for _, line := range strings.Split(strings.TrimSpace(procCgroup), "\n") {
+		// each line has the format hierarchy-id:controllers:path
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		if h.Version == V2 && fields[0] == "0" && fields[1] == "" {
+			return fields[2], nil
+		}
+
+		if h.Version == V1 && slices.Contains(strings.Split(fields[1], ","), "freezer") {
+			return fields[2], nil
+		}
+	}
+
+	if h.Version == V1 {
+		return "", fmt.Errorf("%w: the process is not in the freezer hierarchy", ErrControllerUnavailable)
+	}
+
+	return "", fmt.Errorf("the process is not in the cgroup %s hierarchy", h.Version)
+}
+
+// Freezer returns the file that freezes the processes of the cgroup at the given path of the hierarchy, and the
+// values written to it for freezing and thawing them
+func (h *Hierarchy) Freezer(path string) (string, string, string) {
+	if h.Version == V2 {
+		return filepath.Join(h.Root, path, "cgroup.freeze"), "1", "0"
+	}
+
+	return filepath.Join(h.Root, "freezer", path, "freezer.state"), "FROZEN", "THAWED"
+}
+
+// read returns the trimmed content of a file of the hierarchy
+func (h *Hierarchy) read(file string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(h.Root, file))
+	if err != nil {
+		return "", fmt.Errorf("reading cgroup file: %w", err)
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+func parseBytes(value string) (uint64, error) {
+	bytes, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory value %q: %w", value, err)
+	}
+
+	return bytes, nil
+}
@@ -0,0 +1,378 @@
+package cgroup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// buildHierarchy creates the given files, relative to a temporary root, and returns the root
+func buildHierarchy(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for file, content := range files {
+		path := filepath.Join(root, file)
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		if err != nil {
+			t.Fatalf("creating directory: %v", err)
+		}
+
+		err = os.WriteFile(path, []byte(content), 0o600)
+		if err != nil {
+			t.Fatalf("creating file: %v", err)
+		}
+	}
+
+	return root
+}
+
+func Test_Detect(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title           string
+		files           map[string]string
+		expectError     bool
+		expectedVersion Version
+		available       []string
+		unavailable     []string
+	}{
+		{
+			title:           "cgroup v2",
+			files:           map[string]string{"cgroup.controllers": "cpuset cpu io memory pids\n"},
+			expectedVersion: V2,
+			available:       []string{CPU, Memory, IO},
+		},
+		{
+			title:           "cgroup v2 without memory controller",
+			files:           map[string]string{"cgroup.controllers": "cpu pids\n"},
+			expectedVersion: V2,
+			available:       []string{CPU},
+			unavailable:     []string{Memory, IO},
+		},
+		{
+			title: "cgroup v1",
+			files: map[string]string{
+				"cpu/cpu.shares":               "1024",
+				"memory/memory.limit_in_bytes": "9223372036854771712",
+				"blkio/blkio.weight":           "100",
+			},
+			expectedVersion: V1,
+			available:       []string{CPU, Memory, IO},
+		},
+		{
+			title:           "cgroup v1 without memory controller",
+			files:           map[string]string{"cpu/cpu.shares": "1024"},
+			expectedVersion: V1,
+			available:       []string{CPU},
+			unavailable:     []string{Memory, IO},
+		},
+		{
+			title:       "no hierarchy",
+			files:       map[string]string{},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := Detect(buildHierarchy(t, tc.files))
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if h.Version != tc.expectedVersion {
+				t.Fatalf("expected version %s got %s", tc.expectedVersion, h.Version)
+			}
+
+			err = h.Require(tc.available...)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			for _, resource := range tc.unavailable {
+				err = h.Require(resource)
+				if !errors.Is(err, ErrControllerUnavailable) {
+					t.Fatalf("expected controller unavailable error for %s got %v", resource, err)
+				}
+			}
+		})
+	}
+}
+
+func Test_Limits(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title          string
+		files          map[string]string
+		expectError    bool
+		expectedCPUs   float64
+		expectedMemory uint64
+		expectedUsage  uint64
+	}{
+		{
+			title: "cgroup v2 limited",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "150000 100000\n",
+				"memory.max":         "1073741824\n",
+				"memory.current":     "1048576\n",
+			},
+			expectedCPUs:   1.5,
+			expectedMemory: 1073741824,
+			expectedUsage:  1048576,
+		},
+		{
+			title: "cgroup v2 unlimited",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "max 100000\n",
+				"memory.max":         "max\n",
+				"memory.current":     "1048576\n",
+			},
+			expectedUsage: 1048576,
+		},
+		{
+			title: "cgroup v1 limited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "50000\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "536870912\n",
+				"memory/memory.usage_in_bytes": "2097152\n",
+			},
+			expectedCPUs:   0.5,
+			expectedMemory: 536870912,
+			expectedUsage:  2097152,
+		},
+		{
+			title: "cgroup v1 unlimited",
+			files: map[string]string{
+				"cpu/cpu.cfs_quota_us":         "-1\n",
+				"cpu/cpu.cfs_period_us":        "100000\n",
+				"memory/memory.limit_in_bytes": "9223372036854771712\n",
+				"memory/memory.usage_in_bytes": "2097152\n",
+			},
+			expectedUsage: 2097152,
+		},
+		{
+			title: "invalid cpu.max",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+				"cpu.max":            "invalid\n",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := Detect(buildHierarchy(t, tc.files))
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			cpus, err := h.CPULimit()
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if cpus != tc.expectedCPUs {
+				t.Fatalf("expected %f CPUs got %f", tc.expectedCPUs, cpus)
+			}
+
+			memory, err := h.MemoryLimit()
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if memory != tc.expectedMemory {
+				t.Fatalf("expected memory limit %d got %d", tc.expectedMemory, memory)
+			}
+
+			usage, err := h.MemoryUsage()
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if usage != tc.expectedUsage {
+				t.Fatalf("expected memory usage %d got %d", tc.expectedUsage, usage)
+			}
+		})
+	}
+}
+
+func Test_IOLimited(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		files       map[string]string
+		expectError bool
+		expected    bool
+	}{
+		{
+			title: "cgroup v2 limited",
+			files: map[string]string{
+				"cgroup.controllers": "io",
+				"io.max":             "8:0 rbps=max wbps=1048576 riops=max wiops=max\n",
+			},
+			expected: true,
+		},
+		{
+			title: "cgroup v2 read limited",
+			files: map[string]string{
+				"cgroup.controllers": "io",
+				"io.max":             "8:0 rbps=1048576 wbps=max riops=max wiops=max\n",
+			},
+			expected: false,
+		},
+		{
+			title: "cgroup v2 unlimited",
+			files: map[string]string{
+				"cgroup.controllers": "io",
+				"io.max":             "",
+			},
+			expected: false,
+		},
+		{
+			title: "cgroup v1 limited",
+			files: map[string]string{
+				"blkio/blkio.throttle.write_bps_device":  "",
+				"blkio/blkio.throttle.write_iops_device": "8:0 100\n",
+			},
+			expected: true,
+		},
+		{
+			title: "cgroup v1 unlimited",
+			files: map[string]string{
+				"blkio/blkio.throttle.write_bps_device":  "",
+				"blkio/blkio.throttle.write_iops_device": "",
+			},
+			expected: false,
+		},
+		{
+			title: "io controller unavailable",
+			files: map[string]string{
+				"cgroup.controllers": "cpu memory",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			h, err := Detect(buildHierarchy(t, tc.files))
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			limited, err := h.IOLimited()
+			if tc.expectError {
+				if !errors.Is(err, ErrControllerUnavailable) {
+					t.Fatalf("expected controller unavailable error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if limited != tc.expected {
+				t.Fatalf("expected limited %t got %t", tc.expected, limited)
+			}
+		})
+	}
+}
+
+func Test_Freezer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title          string
+		hierarchy      Hierarchy
+		procCgroup     string
+		expectError    bool
+		expectedFile   string
+		expectedFrozen string
+	}{
+		{
+			title:          "cgroup v2",
+			hierarchy:      Hierarchy{Version: V2, Root: DefaultRoot},
+			procCgroup:     "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-abc.scope\n",
+			expectedFile:   "/sys/fs/cgroup/kubepods.slice/kubepods-pod1.slice/cri-containerd-abc.scope/cgroup.freeze",
+			expectedFrozen: "1",
+		},
+		{
+			title:     "cgroup v1",
+			hierarchy: Hierarchy{Version: V1, Root: DefaultRoot},
+			procCgroup: "12:memory:/kubepods/pod1/abc\n" +
+				"8:freezer:/kubepods/pod1/abc\n" +
+				"2:cpu,cpuacct:/kubepods/pod1/abc\n",
+			expectedFile:   "/sys/fs/cgroup/freezer/kubepods/pod1/abc/freezer.state",
+			expectedFrozen: "FROZEN",
+		},
+		{
+			title:       "cgroup v1 without freezer",
+			hierarchy:   Hierarchy{Version: V1, Root: DefaultRoot},
+			procCgroup:  "12:memory:/kubepods/pod1/abc\n",
+			expectError: true,
+		},
+		{
+			title:       "cgroup v2 process in v1 hierarchy",
+			hierarchy:   Hierarchy{Version: V2, Root: DefaultRoot},
+			procCgroup:  "8:freezer:/kubepods/pod1/abc\n",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := tc.hierarchy.ProcessPath(tc.procCgroup)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			file, frozen, _ := tc.hierarchy.Freezer(path)
+			if file != tc.expectedFile {
+				t.Fatalf("expected file %q got %q", tc.expectedFile, file)
+			}
+
+			if frozen != tc.expectedFrozen {
+				t.Fatalf("expected frozen value %q got %q", tc.expectedFrozen, frozen)
+			}
+		})
+	}
+}
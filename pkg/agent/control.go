@@ -0,0 +1,335 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// DefaultControlPort is the port the disruptor expects the control API of the agent to listen on
+const DefaultControlPort = 9099
+
+// maxApplyRequestSize is the maximum size of a request for applying a fault
+const maxApplyRequestSize = 1 << 16
+
+// controlStopPollInterval is the interval for checking if the command of a fault has started when it is stopped
+const controlStopPollInterval = 10 * time.Millisecond
+
+// ApplyRequest is the request for applying a fault using the control API of the agent
+type ApplyRequest struct {
+	// Args are the arguments of the agent command that applies the fault (e.g. ["http", "-d", "30s"]).
+	// Only the commands that disrupt the traffic of the target can be applied, preceded by the flags that
+	// configure the agent. The flags that set the files written by the agent are not accepted.
+	Args []string `json:"args"`
+}
+
+// applyCommand returns if the command can be applied using the control API
+func applyCommand(command string) bool {
+	switch command {
+	case "http", "grpc", "compose", "timeline", "repeat":
+		return true
+	default:
+		return false
+	}
+}
+
+// applyFlag returns if the flag can precede the command of an ApplyRequest and if it takes a value
+func applyFlag(flag string) (bool, bool) {
+	switch flag {
+	case "--metrics":
+		return true, false
+	case "--log-level", "--proxy-ports", "--excluded-ports", "--metrics-rate", "--interception":
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+// fileFlag returns if the argument sets a file written by the agent
+func fileFlag(arg string) bool {
+	flag, _, _ := strings.Cut(arg, "=")
+	switch flag {
+	case "--status-file", "--ready-file", "--access-log-file", "--metrics-file",
+		"--cpu-profile-file", "--mem-profile-file", "--trace-file":
+		return true
+	default:
+		return false
+	}
+}
+
+// validate returns an error if the request does not apply a fault using the accepted commands and flags
+func (r ApplyRequest) validate() error {
+	if len(r.Args) == 0 {
+		return errors.New("the command of the fault is required")
+	}
+
+	for _, arg := range r.Args {
+		if fileFlag(arg) {
+			return fmt.Errorf("flag %q is not accepted", arg)
+		}
+	}
+
+	for i := 0; i < len(r.Args); i++ {
+		arg := r.Args[i]
+		if !strings.HasPrefix(arg, "-") {
+			if !applyCommand(arg) {
+				return fmt.Errorf("command %q cannot be applied", arg)
+			}
+			return nil
+		}
+
+		flag, _, hasValue := strings.Cut(arg, "=")
+		allowed, value := applyFlag(flag)
+		if !allowed {
+			return fmt.Errorf("flag %q is not accepted", arg)
+		}
+
+		// skip the value of the flag
+		if value && !hasValue {
+			i++
+		}
+	}
+
+	return errors.New("the command of the fault is required")
+}
+
+// ControlStatus is the status reported by the control API of the agent
+type ControlStatus struct {
+	Status
+	// Running is true while the command applying the fault requested to the control API runs
+	Running bool `json:"running"`
+	// ExitCode of the last command requested to the control API, once it has completed
+	ExitCode int `json:"exitCode"`
+	// Output of the last command requested to the control API, once it has completed
+	Output string `json:"output,omitempty"`
+}
+
+// ControlError is the error returned by the control API
+type ControlError struct {
+	// Error is the message of the error
+	Error string `json:"error"`
+	// ExitCode reports the reason of the error using the exit codes of the agent
+	ExitCode int `json:"exitCode"`
+}
+
+// controlHandler serves the control API of the agent. The faults are applied by running the agent command in a
+// separate process, as if it were executed in the container of the agent.
+type controlHandler struct {
+	env        runtime.Environment
+	config     *Config
+	executable string
+	mutex      sync.Mutex
+	running    bool
+	exitCode   int
+	output     string
+}
+
+// NewControlHandler returns a handler that serves the control API of the agent:
+//
+//	POST /apply   applies a fault described by an ApplyRequest
+//	POST /stop    stops the fault being applied, if any
+//	GET  /status  returns the ControlStatus of the last fault
+//	GET  /metrics returns the metrics reported by the last fault
+//	GET  /health  returns the Health of the agent. Fails with 503 if it is not healthy
+//
+// The executable is the path of the agent binary used for applying the faults.
+func NewControlHandler(env runtime.Environment, config *Config, executable string) http.Handler {
+	h := &controlHandler{
+		env:        env,
+		config:     config,
+		executable: executable,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /apply", h.apply)
+	mux.HandleFunc("POST /stop", h.stop)
+	mux.HandleFunc("GET /status", h.status)
+	mux.HandleFunc("GET /metrics", h.metrics)
+	mux.HandleFunc("GET /health", h.health)
+
+	return mux
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, code int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, code int, exitCode int, message string) {
+	writeJSON(w, code, ControlError{Error: message, ExitCode: exitCode})
+}
+
+func (h *controlHandler) apply(w http.ResponseWriter, r *http.Request) {
+	request := ApplyRequest{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxApplyRequestSize)).Decode(&request)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ExitInvalidFault, "decoding request: "+err.Error())
+		return
+	}
+
+	err = request.validate()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ExitInvalidFault, err.Error())
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.running || h.env.Lock().Owner() != -1 {
+		writeError(w, http.StatusConflict, ExitFaultActive, ErrFaultActive.Error())
+		return
+	}
+
+	h.running = true
+
+	// the command uses the same files as the agent serving the control API
+	args := append(
+		[]string{"--status-file", h.config.StatusFile, "--ready-file", h.config.ReadyFile},
+		request.Args...,
+	)
+	go h.run(args)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// run runs the agent command and records its result
+func (h *controlHandler) run(args []string) {
+	output, err := h.env.Executor().Exec(h.executable, args...)
+
+	exitCode := 0
+	if err != nil {
+		exitCode = ExitFailed
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = exitErr.ExitCode()
+		}
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.running = false
+	h.exitCode = exitCode
+	h.output = string(output)
+}
+
+// faultProcess returns the process that applies the fault, or -1 if there is none. If the command requested to
+// the control API is running but has not acquired the lock yet, it waits until the command acquires the lock or
+// completes, as the fault would not be stopped otherwise.
+func (h *controlHandler) faultProcess(ctx context.Context) (int, error) {
+	for {
+		owner := h.env.Lock().Owner()
+		if owner != -1 {
+			return owner, nil
+		}
+
+		h.mutex.Lock()
+		running := h.running
+		h.mutex.Unlock()
+
+		if !running {
+			return -1, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return -1, ctx.Err()
+		case <-time.After(controlStopPollInterval):
+		}
+	}
+}
+
+func (h *controlHandler) stop(w http.ResponseWriter, r *http.Request) {
+	runningProcess, err := h.faultProcess(r.Context())
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, ExitFailed, "waiting for the fault to start: "+err.Error())
+		return
+	}
+
+	if runningProcess != -1 {
+		err = SignalProcess(runningProcess, StopSignal)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, ExitCode(err), "stopping fault: "+err.Error())
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// controlStatus returns the status of the last fault
+func (h *controlHandler) controlStatus() (ControlStatus, error) {
+	status, err := ReadStatus(h.config.StatusFile)
+	if err != nil {
+		return ControlStatus{}, err
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	// the agent was terminated without updating the status
+	if !h.running && status.State == StateActive && h.env.Lock().Owner() == -1 {
+		status.State = StateFailed
+		status.Remaining = 0
+		status.Error = "agent terminated unexpectedly"
+	}
+
+	return ControlStatus{
+		Status:   status,
+		Running:  h.running,
+		ExitCode: h.exitCode,
+		Output:   h.output,
+	}, nil
+}
+
+func (h *controlHandler) status(w http.ResponseWriter, _ *http.Request) {
+	status, err := h.controlStatus()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ExitFailed, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+func (h *controlHandler) metrics(w http.ResponseWriter, _ *http.Request) {
+	status, err := ReadStatus(h.config.StatusFile)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ExitFailed, err.Error())
+		return
+	}
+
+	metrics := status.Metrics
+	if metrics == nil {
+		metrics = map[string]uint{}
+	}
+
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+func (h *controlHandler) health(w http.ResponseWriter, _ *http.Request) {
+	health, err := CheckHealth(h.env, h.config)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ExitFailed, err.Error())
+		return
+	}
+
+	code := http.StatusOK
+	if !health.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, health)
+}
@@ -0,0 +1,343 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// waitControlStatus polls the status of the control API until the command is not running
+func waitControlStatus(t *testing.T, handler http.Handler) ControlStatus {
+	t.Helper()
+
+	for range 50 {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("expected status %d returned %d", http.StatusOK, recorder.Code)
+		}
+
+		status := ControlStatus{}
+		err := json.NewDecoder(recorder.Body).Decode(&status)
+		if err != nil {
+			t.Fatalf("failed: %v", err)
+		}
+
+		if !status.Running {
+			return status
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("command did not complete")
+	return ControlStatus{}
+}
+
+func Test_ControlApply(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title            string
+		body             string
+		locked           bool
+		output           []byte
+		err              error
+		expectedCode     int
+		expectedExitCode int
+		expectedCmd      string
+	}{
+		{
+			title:            "fault applied",
+			body:             `{"args":["http","-d","1s"]}`,
+			expectedCode:     http.StatusAccepted,
+			expectedExitCode: 0,
+			expectedCmd:      "xk6-disruptor-agent --status-file STATUS --ready-file READY http -d 1s",
+		},
+		{
+			title:            "fault failed",
+			body:             `{"args":["http","-d","1s"]}`,
+			output:           []byte("proxy ended with error"),
+			err:              errors.New("exit status 1"),
+			expectedCode:     http.StatusAccepted,
+			expectedExitCode: ExitFailed,
+			expectedCmd:      "xk6-disruptor-agent --status-file STATUS --ready-file READY http -d 1s",
+		},
+		{
+			title:            "invalid request",
+			body:             `{"args":`,
+			expectedCode:     http.StatusBadRequest,
+			expectedExitCode: ExitInvalidFault,
+		},
+		{
+			title:            "no command",
+			body:             `{"args":[]}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedExitCode: ExitInvalidFault,
+		},
+		{
+			title:            "agent flags",
+			body:             `{"args":["--log-level","debug","--metrics","http","-d","1s"]}`,
+			expectedCode:     http.StatusAccepted,
+			expectedExitCode: 0,
+			expectedCmd: "xk6-disruptor-agent --status-file STATUS --ready-file READY " +
+				"--log-level debug --metrics http -d 1s",
+		},
+		{
+			title:            "command not accepted",
+			body:             `{"args":["janitor"]}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedExitCode: ExitInvalidFault,
+		},
+		{
+			title:            "flag not accepted",
+			body:             `{"args":["--trace","http","-d","1s"]}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedExitCode: ExitInvalidFault,
+		},
+		{
+			title:            "file flag",
+			body:             `{"args":["http","-d","1s","--status-file=/etc/passwd"]}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedExitCode: ExitInvalidFault,
+		},
+		{
+			title:            "only flags",
+			body:             `{"args":["--log-level","debug"]}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedExitCode: ExitInvalidFault,
+		},
+		{
+			title:            "fault active",
+			body:             `{"args":["http","-d","1s"]}`,
+			locked:           true,
+			expectedCode:     http.StatusConflict,
+			expectedExitCode: ExitFaultActive,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			config := &Config{
+				StatusFile: filepath.Join(dir, "status"),
+				ReadyFile:  filepath.Join(dir, "ready"),
+			}
+
+			env := runtime.NewFakeRuntime(nil, nil)
+			env.FakeExecutor = runtime.NewFakeExecutor(tc.output, tc.err)
+			if tc.locked {
+				_, _ = env.FakeLock.Acquire()
+			}
+
+			handler := NewControlHandler(env, config, "xk6-disruptor-agent")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/apply", strings.NewReader(tc.body)))
+			if recorder.Code != tc.expectedCode {
+				t.Fatalf("expected status %d returned %d", tc.expectedCode, recorder.Code)
+			}
+
+			if tc.expectedCode != http.StatusAccepted {
+				controlErr := ControlError{}
+				err := json.NewDecoder(recorder.Body).Decode(&controlErr)
+				if err != nil {
+					t.Fatalf("failed: %v", err)
+				}
+
+				if controlErr.ExitCode != tc.expectedExitCode {
+					t.Fatalf("expected exit code %d returned %d", tc.expectedExitCode, controlErr.ExitCode)
+				}
+				return
+			}
+
+			status := waitControlStatus(t, handler)
+			if status.ExitCode != tc.expectedExitCode {
+				t.Fatalf("expected exit code %d returned %d", tc.expectedExitCode, status.ExitCode)
+			}
+
+			if status.Output != string(tc.output) {
+				t.Fatalf("expected output %q returned %q", string(tc.output), status.Output)
+			}
+
+			expectedCmd := strings.NewReplacer("STATUS", config.StatusFile, "READY", config.ReadyFile).
+				Replace(tc.expectedCmd)
+			if diff := cmp.Diff(expectedCmd, env.FakeExecutor.Cmd()); diff != "" {
+				t.Fatalf("expected command does not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ControlFaultProcess(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title         string
+		running       bool
+		locked        bool
+		expectError   bool
+		expectProcess bool
+	}{
+		{
+			title:         "no fault",
+			running:       false,
+			locked:        false,
+			expectError:   false,
+			expectProcess: false,
+		},
+		{
+			title:         "fault started",
+			running:       true,
+			locked:        true,
+			expectError:   false,
+			expectProcess: true,
+		},
+		{
+			title:       "fault not started",
+			running:     true,
+			locked:      false,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			env := runtime.NewFakeRuntime(nil, nil)
+			if tc.locked {
+				_, _ = env.FakeLock.Acquire()
+			}
+
+			handler := &controlHandler{env: env, config: &Config{}, running: tc.running}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			process, err := handler.faultProcess(ctx)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if (process != -1) != tc.expectProcess {
+				t.Fatalf("expected process %t returned %d", tc.expectProcess, process)
+			}
+		})
+	}
+}
+
+func Test_ControlMetrics(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{StatusFile: filepath.Join(t.TempDir(), "status")}
+	err := WriteStatus(config.StatusFile, Status{
+		State:   StateFinished,
+		Metrics: map[string]uint{"requests_total": 10, "requests_disrupted": 5},
+	})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	handler := NewControlHandler(runtime.NewFakeRuntime(nil, nil), config, "xk6-disruptor-agent")
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status %d returned %d", http.StatusOK, recorder.Code)
+	}
+
+	metrics := map[string]uint{}
+	err = json.NewDecoder(recorder.Body).Decode(&metrics)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	expected := map[string]uint{"requests_total": 10, "requests_disrupted": 5}
+	if diff := cmp.Diff(expected, metrics); diff != "" {
+		t.Fatalf("expected metrics do not match returned\n%s", diff)
+	}
+}
+
+func Test_ControlHealth(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		ready        bool
+		expectedCode int
+	}{
+		{
+			title:        "healthy",
+			ready:        true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			title:        "not healthy",
+			ready:        false,
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			config := &Config{
+				StatusFile: filepath.Join(dir, "status"),
+				ReadyFile:  filepath.Join(dir, "ready"),
+			}
+
+			if tc.ready {
+				err := MarkReady(config.ReadyFile)
+				if err != nil {
+					t.Fatalf("failed: %v", err)
+				}
+			}
+
+			handler := NewControlHandler(runtime.NewFakeRuntime(nil, nil), config, "xk6-disruptor-agent")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+			if recorder.Code != tc.expectedCode {
+				t.Fatalf("expected status %d returned %d", tc.expectedCode, recorder.Code)
+			}
+
+			health := Health{}
+			err := json.NewDecoder(recorder.Body).Decode(&health)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if health.Ready != tc.ready {
+				t.Fatalf("expected ready to be %t", tc.ready)
+			}
+		})
+	}
+}
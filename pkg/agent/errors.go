@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// Exit codes of the agent. They allow the callers of the agent to identify the reason of a failure.
+const (
+	// ExitFailed is the exit code of a failure without a specific reason
+	ExitFailed = 1
+	// ExitInvalidFault is the exit code when the fault is not valid
+	ExitInvalidFault = 2
+	// ExitPortNotFound is the exit code when the target port of the fault is not found
+	ExitPortNotFound = 3
+	// ExitPermissionDenied is the exit code when the agent lacks the privileges for applying the fault
+	ExitPermissionDenied = 4
+	// ExitFaultActive is the exit code when another fault is being applied in the target
+	ExitFaultActive = 5
+	// ExitUnsupportedPlatform is the exit code when the fault is not supported in the platform of the target
+	ExitUnsupportedPlatform = 6
+)
+
+var (
+	// ErrInvalidFault is returned when the fault is not valid
+	ErrInvalidFault = errors.New("invalid fault")
+	// ErrPortNotFound is returned when the target port of the fault is not found
+	ErrPortNotFound = errors.New("target port not found")
+	// ErrPermissionDenied is returned when the agent lacks the privileges for applying the fault
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrFaultActive is returned when another instance of the agent is applying a fault in the target
+	ErrFaultActive = errors.New("another instance of the agent is already running")
+	// ErrUnsupportedPlatform is returned when a fault is not supported in the platform the agent runs in
+	ErrUnsupportedPlatform = errors.New("unsupported platform")
+)
+
+// ExitCode returns the exit code that reports the reason of the error
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, ErrInvalidFault):
+		return ExitInvalidFault
+	case errors.Is(err, ErrPortNotFound):
+		return ExitPortNotFound
+	case errors.Is(err, ErrFaultActive):
+		return ExitFaultActive
+	case errors.Is(err, ErrUnsupportedPlatform):
+		return ExitUnsupportedPlatform
+	case errors.Is(err, ErrPermissionDenied), errors.Is(err, os.ErrPermission):
+		return ExitPermissionDenied
+	// errors of external tools such as iptables are only reported in their output, with the messages they
+	// report when they lack privileges
+	case strings.Contains(err.Error(), "Permission denied"), strings.Contains(err.Error(), "Operation not permitted"):
+		return ExitPermissionDenied
+	default:
+		return ExitFailed
+	}
+}
@@ -0,0 +1,71 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func Test_ExitCode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		err      error
+		expected int
+	}{
+		{
+			title:    "no error",
+			err:      nil,
+			expected: 0,
+		},
+		{
+			title:    "generic error",
+			err:      errors.New("failed"),
+			expected: ExitFailed,
+		},
+		{
+			title:    "invalid fault",
+			err:      fmt.Errorf("%w: target port for fault injection is required", ErrInvalidFault),
+			expected: ExitInvalidFault,
+		},
+		{
+			title:    "port not found",
+			err:      fmt.Errorf("checking target: %w", ErrPortNotFound),
+			expected: ExitPortNotFound,
+		},
+		{
+			title:    "fault active",
+			err:      fmt.Errorf("initializing agent: %w", ErrFaultActive),
+			expected: ExitFaultActive,
+		},
+		{
+			title:    "unsupported platform",
+			err:      fmt.Errorf("initializing agent: %w", ErrUnsupportedPlatform),
+			expected: ExitUnsupportedPlatform,
+		},
+		{
+			title:    "permission error",
+			err:      fmt.Errorf("opening file: %w", os.ErrPermission),
+			expected: ExitPermissionDenied,
+		},
+		{
+			title:    "permission denied by external tool",
+			err:      errors.New(`exit status 4: "iptables: Permission denied (you must be root)"`),
+			expected: ExitPermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			if code := ExitCode(tc.err); code != tc.expected {
+				t.Fatalf("expected exit code %d returned %d", tc.expected, code)
+			}
+		})
+	}
+}
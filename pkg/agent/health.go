@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// healthDialTimeout is the maximum time for checking if an address accepts connections
+const healthDialTimeout = time.Second
+
+// ProxyStatus describes the proxy used by a disruption
+type ProxyStatus struct {
+	// Port the proxy listens on
+	Port uint `json:"port"`
+	// Upstream is the address (host:port) the proxy forwards the traffic to
+	Upstream string `json:"upstream"`
+}
+
+// ProxyHealth reports the health of the proxy of an active disruption
+type ProxyHealth struct {
+	ProxyStatus
+	// Listening is true if the proxy accepts connections
+	Listening bool `json:"listening"`
+	// UpstreamReachable is true if the upstream accepts connections
+	UpstreamReachable bool `json:"upstreamReachable"`
+}
+
+// Health reports the health of the agent and of the disruption it applies
+type Health struct {
+	// Healthy is true if the check did not find any problem
+	Healthy bool `json:"healthy"`
+	// Ready is true if the agent reported it is ready for applying disruptions
+	Ready bool `json:"ready"`
+	// Status of the last disruption
+	Status Status `json:"status"`
+	// Proxy reports the health of the proxy of the active disruption, if it uses one
+	Proxy *ProxyHealth `json:"proxy,omitempty"`
+	// Problems found by the check
+	Problems []string `json:"problems,omitempty"`
+}
+
+// CheckHealth checks if the agent is ready and, if a disruption is active, if it is still applied and its proxy
+// accepts connections and reaches the upstream
+func CheckHealth(env runtime.Environment, config *Config) (Health, error) {
+	ready, err := IsReady(config.ReadyFile)
+	if err != nil {
+		return Health{}, err
+	}
+
+	status, err := ReadStatus(config.StatusFile)
+	if err != nil {
+		return Health{}, err
+	}
+
+	health := Health{
+		Ready:  ready,
+		Status: status,
+	}
+
+	if !ready {
+		health.Problems = append(health.Problems, "agent has not reported it is ready")
+	}
+
+	if status.State == StateActive && env.Lock().Owner() == -1 {
+		// the agent was terminated without updating the status
+		health.Status.State = StateFailed
+		health.Status.Remaining = 0
+		health.Status.Error = "agent terminated unexpectedly"
+		health.Problems = append(health.Problems, "the active disruption was terminated unexpectedly")
+	}
+
+	if health.Status.State == StateActive && status.Proxy != nil {
+		health.Proxy = &ProxyHealth{
+			ProxyStatus:       *status.Proxy,
+			Listening:         accepts(net.JoinHostPort("127.0.0.1", fmt.Sprint(status.Proxy.Port))),
+			UpstreamReachable: accepts(status.Proxy.Upstream),
+		}
+
+		if !health.Proxy.Listening {
+			health.Problems = append(health.Problems, fmt.Sprintf("proxy is not listening on port %d", status.Proxy.Port))
+		}
+
+		if !health.Proxy.UpstreamReachable {
+			health.Problems = append(health.Problems, fmt.Sprintf("upstream %s is not reachable", status.Proxy.Upstream))
+		}
+	}
+
+	health.Healthy = len(health.Problems) == 0
+
+	return health, nil
+}
+
+// accepts returns if the address accepts connections
+func accepts(address string) bool {
+	conn, err := net.DialTimeout("tcp", address, healthDialTimeout)
+	if err != nil {
+		return false
+	}
+
+	_ = conn.Close()
+
+	return true
+}
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// listenerPort returns a listener on a free local port and the port it listens on
+func listenerPort(t *testing.T) (net.Listener, uint) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	return listener, uint(listener.Addr().(*net.TCPAddr).Port) //nolint:forcetypeassert
+}
+
+func Test_CheckHealth(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title             string
+		ready             bool
+		state             State
+		locked            bool
+		proxyListening    bool
+		upstreamListening bool
+		expectedState     State
+		expectedProblems  int
+	}{
+		{
+			title:            "agent not ready",
+			ready:            false,
+			state:            StatePending,
+			expectedState:    StatePending,
+			expectedProblems: 1,
+		},
+		{
+			title:            "no active disruption",
+			ready:            true,
+			state:            StateFinished,
+			expectedState:    StateFinished,
+			expectedProblems: 0,
+		},
+		{
+			title:             "active disruption",
+			ready:             true,
+			state:             StateActive,
+			locked:            true,
+			proxyListening:    true,
+			upstreamListening: true,
+			expectedState:     StateActive,
+			expectedProblems:  0,
+		},
+		{
+			title:            "agent terminated unexpectedly",
+			ready:            true,
+			state:            StateActive,
+			locked:           false,
+			expectedState:    StateFailed,
+			expectedProblems: 1,
+		},
+		{
+			title:             "proxy not listening",
+			ready:             true,
+			state:             StateActive,
+			locked:            true,
+			proxyListening:    false,
+			upstreamListening: true,
+			expectedState:     StateActive,
+			expectedProblems:  1,
+		},
+		{
+			title:             "upstream not reachable",
+			ready:             true,
+			state:             StateActive,
+			locked:            true,
+			proxyListening:    true,
+			upstreamListening: false,
+			expectedState:     StateActive,
+			expectedProblems:  1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			config := &Config{
+				StatusFile: filepath.Join(dir, "status"),
+				ReadyFile:  filepath.Join(dir, "ready"),
+			}
+
+			if tc.ready {
+				err := MarkReady(config.ReadyFile)
+				if err != nil {
+					t.Fatalf("failed: %v", err)
+				}
+			}
+
+			proxy, proxyPort := listenerPort(t)
+			upstream, _ := listenerPort(t)
+			if !tc.proxyListening {
+				_ = proxy.Close()
+			}
+			if !tc.upstreamListening {
+				_ = upstream.Close()
+			}
+			t.Cleanup(func() {
+				_ = proxy.Close()
+				_ = upstream.Close()
+			})
+
+			err := WriteStatus(config.StatusFile, Status{
+				State: tc.state,
+				Proxy: &ProxyStatus{
+					Port:     proxyPort,
+					Upstream: upstream.Addr().String(),
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			env := runtime.NewFakeRuntime(nil, nil)
+			if tc.locked {
+				_, _ = env.FakeLock.Acquire()
+			}
+
+			health, err := CheckHealth(env, config)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if health.Status.State != tc.expectedState {
+				t.Fatalf("expected state %q returned %q", tc.expectedState, health.Status.State)
+			}
+
+			if len(health.Problems) != tc.expectedProblems {
+				t.Fatalf("expected %d problems returned %v", tc.expectedProblems, health.Problems)
+			}
+
+			if health.Healthy != (tc.expectedProblems == 0) {
+				t.Fatalf("expected healthy to be %t", tc.expectedProblems == 0)
+			}
+
+			if health.Ready != tc.ready {
+				t.Fatalf("expected ready to be %t", tc.ready)
+			}
+
+			if tc.expectedState != StateActive {
+				return
+			}
+
+			expected := &ProxyHealth{
+				ProxyStatus:       ProxyStatus{Port: proxyPort, Upstream: upstream.Addr().String()},
+				Listening:         tc.proxyListening,
+				UpstreamReachable: tc.upstreamListening,
+			}
+			if diff := cmp.Diff(expected, health.Proxy); diff != "" {
+				t.Fatalf("expected proxy health does not match returned\n%s", diff)
+			}
+		})
+	}
+}
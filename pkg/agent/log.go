@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// Levels of the log of the agent's container
+const (
+	// LogLevelNone does not report the execution of commands
+	LogLevelNone = "none"
+	// LogLevelError reports the commands that fail
+	LogLevelError = "error"
+	// LogLevelInfo reports the commands that change the state of the agent, such as the commands that apply faults
+	LogLevelInfo = "info"
+	// LogLevelDebug reports all the commands
+	LogLevelDebug = "debug"
+)
+
+// ValidateLogLevel returns an error if the level of the log is not valid
+func ValidateLogLevel(level string) error {
+	if !slices.Contains([]string{LogLevelNone, LogLevelError, LogLevelInfo, LogLevelDebug}, level) {
+		return fmt.Errorf(
+			"%w: invalid log level %q: must be one of %s, %s, %s or %s",
+			ErrInvalidFault,
+			level,
+			LogLevelNone,
+			LogLevelError,
+			LogLevelInfo,
+			LogLevelDebug,
+		)
+	}
+
+	return nil
+}
+
+// nopCloser is a writer that does not need to be closed
+type nopCloser struct {
+	io.Writer
+}
+
+// Close implements the io.Closer interface
+func (nopCloser) Close() error {
+	return nil
+}
+
+// OpenContainerLog returns a writer to the log of the agent's container. The log of the container collects the
+// output of its main process, which reported the agent is ready in the given file. If the agent is not ready, the
+// output is discarded. Callers must close the returned writer.
+func OpenContainerLog(readyFile string) io.WriteCloser {
+	owner, err := ReadyOwner(readyFile)
+	if err != nil || owner == -1 {
+		return nopCloser{io.Discard}
+	}
+
+	if owner == os.Getpid() {
+		return nopCloser{os.Stdout}
+	}
+
+	log, err := os.OpenFile(fmt.Sprintf("/proc/%d/fd/1", owner), os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return nopCloser{io.Discard}
+	}
+
+	return log
+}
@@ -0,0 +1,61 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Clock gives access to the system's clock
+type Clock interface {
+	// Now returns the current time of the clock
+	Now() time.Time
+	// Set sets the time of the clock
+	Set(t time.Time) error
+}
+
+// ClockSkewDisruption defines a disruption that shifts the host's clock
+type ClockSkewDisruption struct {
+	// Offset added to the clock. Negative values set the clock back in time
+	Offset time.Duration
+}
+
+// ClockSkewDisruptor shifts the host's clock for the duration of the disruption.
+// Notice that a time synchronization service running in the host may revert the skew before the disruption ends.
+type ClockSkewDisruptor struct {
+	clock      Clock
+	disruption ClockSkewDisruption
+}
+
+// NewClockSkewDisruptor returns a new ClockSkewDisruptor
+func NewClockSkewDisruptor(clock Clock, disruption ClockSkewDisruption) (*ClockSkewDisruptor, error) {
+	if disruption.Offset == 0 {
+		return nil, errors.New("offset cannot be zero")
+	}
+
+	return &ClockSkewDisruptor{
+		clock:      clock,
+		disruption: disruption,
+	}, nil
+}
+
+// Apply shifts the clock by the offset and restores it after the given duration
+func (d *ClockSkewDisruptor) Apply(ctx context.Context, duration time.Duration) error {
+	err := d.clock.Set(d.clock.Now().Add(d.disruption.Offset))
+	if err != nil {
+		return fmt.Errorf("setting clock: %w", err)
+	}
+
+	defer func() {
+		// the time elapsed during the disruption is preserved
+		_ = d.clock.Set(d.clock.Now().Add(-d.disruption.Offset))
+	}()
+
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
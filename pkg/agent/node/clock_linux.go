@@ -0,0 +1,26 @@
+//go:build linux
+// +build linux
+
+package node
+
+import (
+	"syscall"
+	"time"
+)
+
+// systemClock is the Clock of the system
+type systemClock struct{}
+
+// SystemClock returns the clock of the system. Setting the clock requires the CAP_SYS_TIME capability.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Set(t time.Time) error {
+	tv := syscall.NsecToTimeval(t.UnixNano())
+	return syscall.Settimeofday(&tv)
+}
@@ -0,0 +1,25 @@
+//go:build !linux
+// +build !linux
+
+package node
+
+import (
+	"errors"
+	"time"
+)
+
+// systemClock is the Clock of the system
+type systemClock struct{}
+
+// SystemClock returns the clock of the system. Setting the clock is only supported in linux.
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Set(time.Time) error {
+	return errors.New("setting the clock is not supported in this platform")
+}
@@ -0,0 +1,96 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that records the times it was set to
+type fakeClock struct {
+	now    time.Time
+	err    error
+	values []time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Set(t time.Time) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.now = t
+	c.values = append(c.values, t)
+	return nil
+}
+
+func Test_ClockSkew(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		title       string
+		offset      time.Duration
+		setError    error
+		expectError bool
+		expected    []time.Time
+	}{
+		{
+			title:    "forward skew",
+			offset:   time.Hour,
+			expected: []time.Time{start.Add(time.Hour), start},
+		},
+		{
+			title:    "backward skew",
+			offset:   -time.Minute,
+			expected: []time.Time{start.Add(-time.Minute), start},
+		},
+		{
+			title:       "error setting clock",
+			offset:      time.Hour,
+			setError:    errors.New("operation not permitted"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			clock := &fakeClock{now: start, err: tc.setError}
+
+			disruptor, err := NewClockSkewDisruptor(clock, ClockSkewDisruption{Offset: tc.offset})
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			err = disruptor.Apply(context.TODO(), 0)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+
+			if len(clock.values) != len(tc.expected) {
+				t.Fatalf("expected %v got %v", tc.expected, clock.values)
+			}
+
+			for i := range tc.expected {
+				if !clock.values[i].Equal(tc.expected[i]) {
+					t.Errorf("expected %v got %v", tc.expected[i], clock.values[i])
+				}
+			}
+		})
+	}
+}
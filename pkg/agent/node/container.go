@@ -0,0 +1,210 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent/cgroup"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+)
+
+// Actions applied to the containers
+const (
+	// ContainerKill kills the container without waiting for it to terminate gracefully
+	ContainerKill = "kill"
+	// ContainerRestart stops the container gracefully
+	ContainerRestart = "restart"
+	// ContainerPause freezes the processes of the container for the duration of the disruption
+	ContainerPause = "pause"
+)
+
+// DefaultContainerGracePeriod is the default time a restarted container has for terminating gracefully
+const DefaultContainerGracePeriod = 10 * time.Second
+
+// ContainerDisruption defines a disruption that acts on containers of the node through the container runtime
+type ContainerDisruption struct {
+	// IDs of the containers in the container runtime
+	IDs []string
+	// Action is the action applied to the containers: ContainerKill, ContainerRestart or ContainerPause
+	Action string
+	// GracePeriod is the time a restarted container has for terminating gracefully
+	GracePeriod time.Duration
+}
+
+// ContainerDisruptor applies actions to containers of the node using the container runtime (containerd or CRI-O).
+// The containers stopped by the runtime are restarted by the kubelet according to the restart policy of their pods.
+// The paused containers are frozen using the cgroup freezer, as the CRI does not support pausing containers.
+type ContainerDisruptor struct {
+	executor   runtime.Executor
+	runtime    ContainerRuntime
+	disruption ContainerDisruption
+	// hierarchy is the cgroup hierarchy of the node, required for pausing containers
+	hierarchy *cgroup.Hierarchy
+	// id identifies the units of the timers scheduled by the disruptor, so they do not collide with those of other
+	// disruptions
+	id string
+}
+
+// NewContainerDisruptor returns a new ContainerDisruptor. The cgroup hierarchy is required for pausing containers.
+func NewContainerDisruptor(
+	executor runtime.Executor,
+	containerRuntime ContainerRuntime,
+	disruption ContainerDisruption,
+	hierarchy *cgroup.Hierarchy,
+) (*ContainerDisruptor, error) {
+	if len(disruption.IDs) == 0 {
+		return nil, errors.New("at least one container must be specified")
+	}
+
+	switch disruption.Action {
+	case ContainerKill, ContainerRestart:
+	case ContainerPause:
+		if hierarchy == nil {
+			return nil, errors.New("pausing containers requires the cgroup hierarchy of the node")
+		}
+	default:
+		return nil, fmt.Errorf(
+			"invalid action %q: must be one of %s, %s or %s",
+			disruption.Action,
+			ContainerKill,
+			ContainerRestart,
+			ContainerPause,
+		)
+	}
+
+	if disruption.GracePeriod < 0 {
+		return nil, errors.New("grace period cannot be negative")
+	}
+
+	return &ContainerDisruptor{
+		executor:   executor,
+		runtime:    containerRuntime,
+		disruption: disruption,
+		hierarchy:  hierarchy,
+		id:         strconv.FormatInt(time.Now().UnixNano(), 36),
+	}, nil
+}
+
+// hostExec executes a command in the mount and cgroup namespaces of the host's init process, where the cgroups of
+// the containers and systemd are accessible
+func (d *ContainerDisruptor) hostExec(args ...string) ([]byte, error) {
+	nsenterArgs := append([]string{"-t", "1", "-m", "-C", "--"}, args...)
+	out, err := d.executor.Exec("nsenter", nsenterArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, string(out))
+	}
+
+	return out, nil
+}
+
+// Apply applies the action to the containers. Killed and restarted containers are stopped immediately, while
+// paused containers are frozen for the given duration.
+func (d *ContainerDisruptor) Apply(ctx context.Context, duration time.Duration) error {
+	if d.disruption.Action == ContainerPause {
+		return d.pause(ctx, duration)
+	}
+
+	timeout := time.Duration(0)
+	if d.disruption.Action == ContainerRestart {
+		timeout = d.disruption.GracePeriod
+	}
+
+	for _, id := range d.disruption.IDs {
+		err := d.runtime.StopContainer(ctx, id, timeout)
+		if err != nil {
+			return fmt.Errorf("stopping container %s: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// thawUnit returns the name of the transient unit that thaws the i-th container
+func (d *ContainerDisruptor) thawUnit(i int) string {
+	return fmt.Sprintf("xk6-disruptor-thaw-%s-%d", d.id, i)
+}
+
+// pause freezes the cgroups of the containers until the duration elapses or the context is done. The containers
+// are thawed even if freezing any of them fails. Before freezing each container, its thaw is scheduled in the host
+// with a transient systemd timer after the duration, so the container is not left frozen if the agent terminates
+// unexpectedly. The timers are cancelled once the containers are thawed.
+func (d *ContainerDisruptor) pause(ctx context.Context, duration time.Duration) error {
+	thaw := map[string]string{}
+	timers := []string{}
+	defer func() {
+		for file, thawed := range thaw {
+			// nothing to do if thawing fails, the timer will retry
+			_ = d.writeHost(file, thawed)
+		}
+
+		if len(timers) > 0 {
+			// nothing to do if cancelling fails, thawing again is harmless
+			_, _ = d.hostExec(append([]string{"systemctl", "stop"}, timers...)...)
+		}
+	}()
+
+	for i, id := range d.disruption.IDs {
+		file, frozen, thawed, err := d.freezer(ctx, id)
+		if err != nil {
+			return fmt.Errorf("pausing container %s: %w", id, err)
+		}
+
+		unit := d.thawUnit(i)
+		_, err = d.hostExec(
+			"systemd-run",
+			"--collect",
+			"--unit="+unit,
+			fmt.Sprintf("--on-active=%s", utils.DurationSeconds(duration)),
+			"sh", "-c", fmt.Sprintf("echo %s > %s", thawed, file),
+		)
+		if err != nil {
+			return fmt.Errorf("scheduling thaw of container %s: %w", id, err)
+		}
+		timers = append(timers, unit+".timer")
+
+		err = d.writeHost(file, frozen)
+		if err != nil {
+			return fmt.Errorf("pausing container %s: %w", id, err)
+		}
+		thaw[file] = thawed
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(duration):
+	}
+
+	return nil
+}
+
+// freezer returns the freezer file of the cgroup of the container and the values for freezing and thawing it
+func (d *ContainerDisruptor) freezer(ctx context.Context, id string) (string, string, string, error) {
+	pid, err := d.runtime.ContainerPID(ctx, id)
+	if err != nil {
+		return "", "", "", fmt.Errorf("inspecting container: %w", err)
+	}
+
+	procCgroup, err := d.hostExec("cat", fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", "", "", fmt.Errorf("reading cgroup of the container: %w", err)
+	}
+
+	path, err := d.hierarchy.ProcessPath(string(procCgroup))
+	if err != nil {
+		return "", "", "", err
+	}
+
+	file, frozen, thawed := d.hierarchy.Freezer(path)
+
+	return file, frozen, thawed, nil
+}
+
+// writeHost writes the value to a file of the host
+func (d *ContainerDisruptor) writeHost(file string, value string) error {
+	_, err := d.hostExec("sh", "-c", fmt.Sprintf("echo %s > %s", value, file))
+	return err
+}
@@ -0,0 +1,165 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/agent/cgroup"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// fakeRuntime is a ContainerRuntime that records the containers stopped
+type fakeRuntime struct {
+	err     error
+	stopped []string
+}
+
+func (r *fakeRuntime) StopContainer(_ context.Context, id string, timeout time.Duration) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	r.stopped = append(r.stopped, fmt.Sprintf("%s %s", id, timeout))
+
+	return nil
+}
+
+func (r *fakeRuntime) ContainerPID(_ context.Context, _ string) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	return 1234, nil
+}
+
+func Test_ContainerDisruptor(t *testing.T) {
+	t.Parallel()
+
+	hierarchy := &cgroup.Hierarchy{Version: cgroup.V2, Root: cgroup.DefaultRoot}
+	freezer := "/sys/fs/cgroup/kubepods.slice/cri-containerd-abc.scope/cgroup.freeze"
+
+	testCases := []struct {
+		title          string
+		disruption     ContainerDisruption
+		hierarchy      *cgroup.Hierarchy
+		execError      error
+		runtimeError   error
+		expectNewError bool
+		expectError    bool
+		expectedCmds   []string
+		expectedStops  []string
+	}{
+		{
+			title:         "kill",
+			disruption:    ContainerDisruption{IDs: []string{"abc", "def"}, Action: ContainerKill},
+			expectedStops: []string{"abc 0s", "def 0s"},
+		},
+		{
+			title: "restart",
+			disruption: ContainerDisruption{
+				IDs:         []string{"abc"},
+				Action:      ContainerRestart,
+				GracePeriod: 5 * time.Second,
+			},
+			expectedStops: []string{"abc 5s"},
+		},
+		{
+			title:      "pause",
+			disruption: ContainerDisruption{IDs: []string{"abc"}, Action: ContainerPause},
+			hierarchy:  hierarchy,
+			expectedCmds: []string{
+				"nsenter -t 1 -m -C -- cat /proc/1234/cgroup",
+				"nsenter -t 1 -m -C -- systemd-run --collect --unit=xk6-disruptor-thaw-test-0 --on-active=0.01s" +
+					" sh -c echo 0 > " + freezer,
+				"nsenter -t 1 -m -C -- sh -c echo 1 > " + freezer,
+				"nsenter -t 1 -m -C -- sh -c echo 0 > " + freezer,
+				"nsenter -t 1 -m -C -- systemctl stop xk6-disruptor-thaw-test-0.timer",
+			},
+		},
+		{
+			title:       "failed scheduling of thaw",
+			disruption:  ContainerDisruption{IDs: []string{"abc"}, Action: ContainerPause},
+			hierarchy:   hierarchy,
+			execError:   errors.New("failed"),
+			expectError: true,
+			expectedCmds: []string{
+				"nsenter -t 1 -m -C -- cat /proc/1234/cgroup",
+				"nsenter -t 1 -m -C -- systemd-run --collect --unit=xk6-disruptor-thaw-test-0 --on-active=0.01s" +
+					" sh -c echo 0 > " + freezer,
+			},
+		},
+		{
+			title:          "pause without cgroup hierarchy",
+			disruption:     ContainerDisruption{IDs: []string{"abc"}, Action: ContainerPause},
+			expectNewError: true,
+		},
+		{
+			title:          "invalid action",
+			disruption:     ContainerDisruption{IDs: []string{"abc"}, Action: "other"},
+			expectNewError: true,
+		},
+		{
+			title:          "no containers",
+			disruption:     ContainerDisruption{Action: ContainerKill},
+			expectNewError: true,
+		},
+		{
+			title:        "failed stop",
+			disruption:   ContainerDisruption{IDs: []string{"abc"}, Action: ContainerKill},
+			runtimeError: errors.New("failed"),
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewCallbackExecutor(func(_ string, args ...string) ([]byte, error) {
+				cmd := strings.Join(args, " ")
+				if strings.Contains(cmd, "/proc/1234/cgroup") {
+					return []byte("0::/kubepods.slice/cri-containerd-abc.scope\n"), nil
+				}
+
+				return nil, tc.execError
+			})
+			containerRuntime := &fakeRuntime{err: tc.runtimeError}
+
+			disruptor, err := NewContainerDisruptor(executor, containerRuntime, tc.disruption, tc.hierarchy)
+			if tc.expectNewError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+			disruptor.id = "test"
+
+			err = disruptor.Apply(context.TODO(), 10*time.Millisecond)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expectedCmds, executor.CmdHistory()); diff != "" {
+				t.Fatalf("expected commands do not match executed:\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.expectedStops, containerRuntime.stopped); diff != "" {
+				t.Fatalf("expected stopped containers do not match:\n%s", diff)
+			}
+		})
+	}
+}
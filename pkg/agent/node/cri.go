@@ -0,0 +1,143 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// ContainerRuntime defines the operations on the containers of the node used by the ContainerDisruptor
+type ContainerRuntime interface {
+	// StopContainer stops the container, giving it the timeout for terminating gracefully
+	StopContainer(ctx context.Context, id string, timeout time.Duration) error
+	// ContainerPID returns the PID of the main process of the container
+	ContainerPID(ctx context.Context, id string) (int, error)
+}
+
+// hostRoot is the root of the filesystem of the host, as seen from a container that shares the host's PID namespace
+const hostRoot = "/proc/1/root"
+
+// defaultRuntimeEndpoints returns the CRI sockets of the supported container runtimes in the host, in the order
+// they are tried
+func defaultRuntimeEndpoints() []string {
+	return []string{
+		"unix:///run/containerd/containerd.sock",
+		"unix:///run/crio/crio.sock",
+		"unix:///var/run/cri-dockerd.sock",
+	}
+}
+
+// CRIRuntime is a ContainerRuntime that uses the CRI API of the container runtime of the host
+type CRIRuntime struct {
+	conn   *grpc.ClientConn
+	client runtimeapi.RuntimeServiceClient
+}
+
+// NewCRIRuntime returns a CRIRuntime connected to the CRI socket of the host (e.g.
+// unix:///run/containerd/containerd.sock). If the endpoint is empty, the first of the default endpoints of containerd,
+// CRI-O and cri-dockerd that exists in the host is used. The socket is accessed through the filesystem of the host's
+// init process, therefore it requires sharing the host's PID namespace.
+func NewCRIRuntime(endpoint string) (*CRIRuntime, error) {
+	path, err := hostSocket(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCRIRuntime(path)
+}
+
+func newCRIRuntime(path string) (*CRIRuntime, error) {
+	conn, err := grpc.NewClient("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connecting to the container runtime: %w", err)
+	}
+
+	return &CRIRuntime{
+		conn:   conn,
+		client: runtimeapi.NewRuntimeServiceClient(conn),
+	}, nil
+}
+
+// hostSocket returns the path of the CRI socket of the endpoint in the filesystem of the host
+func hostSocket(endpoint string) (string, error) {
+	if endpoint != "" {
+		return socketPath(endpoint)
+	}
+
+	for _, endpoint := range defaultRuntimeEndpoints() {
+		path, err := socketPath(endpoint)
+		if err != nil {
+			return "", err
+		}
+
+		if _, err = os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", errors.New("no container runtime socket found in the host")
+}
+
+// socketPath returns the path of the socket of the endpoint in the filesystem of the host
+func socketPath(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid runtime endpoint %q: %w", endpoint, err)
+	}
+
+	if u.Scheme != "unix" || u.Path == "" {
+		return "", fmt.Errorf("invalid runtime endpoint %q: must be a unix socket", endpoint)
+	}
+
+	return filepath.Join(hostRoot, u.Path), nil
+}
+
+// StopContainer stops the container, giving it the timeout for terminating gracefully
+func (r *CRIRuntime) StopContainer(ctx context.Context, id string, timeout time.Duration) error {
+	_, err := r.client.StopContainer(ctx, &runtimeapi.StopContainerRequest{
+		ContainerId: id,
+		Timeout:     int64(timeout.Seconds()),
+	})
+
+	return err
+}
+
+// ContainerPID returns the PID of the main process of the container, as reported in the verbose information of
+// its status
+func (r *CRIRuntime) ContainerPID(ctx context.Context, id string) (int, error) {
+	status, err := r.client.ContainerStatus(ctx, &runtimeapi.ContainerStatusRequest{
+		ContainerId: id,
+		Verbose:     true,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	info := struct {
+		Pid int `json:"pid"`
+	}{}
+	err = json.Unmarshal([]byte(status.GetInfo()["info"]), &info)
+	if err != nil {
+		return 0, fmt.Errorf("decoding status of the container: %w", err)
+	}
+
+	if info.Pid == 0 {
+		return 0, errors.New("container is not running")
+	}
+
+	return info.Pid, nil
+}
+
+// Close closes the connection to the container runtime
+func (r *CRIRuntime) Close() error {
+	return r.conn.Close()
+}
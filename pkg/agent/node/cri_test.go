@@ -0,0 +1,165 @@
+package node
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+)
+
+// fakeRuntimeService is a CRI runtime service that reports the containers as running and records the stopped ones
+type fakeRuntimeService struct {
+	runtimeapi.UnimplementedRuntimeServiceServer
+	info    string
+	stopped chan *runtimeapi.StopContainerRequest
+}
+
+func (s *fakeRuntimeService) ContainerStatus(
+	_ context.Context,
+	_ *runtimeapi.ContainerStatusRequest,
+) (*runtimeapi.ContainerStatusResponse, error) {
+	return &runtimeapi.ContainerStatusResponse{
+		Status: &runtimeapi.ContainerStatus{},
+		Info:   map[string]string{"info": s.info},
+	}, nil
+}
+
+func (s *fakeRuntimeService) StopContainer(
+	_ context.Context,
+	req *runtimeapi.StopContainerRequest,
+) (*runtimeapi.StopContainerResponse, error) {
+	s.stopped <- req
+	return &runtimeapi.StopContainerResponse{}, nil
+}
+
+func Test_CRIRuntime(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		info        string
+		expectedPID int
+		expectError bool
+	}{
+		{
+			title:       "running container",
+			info:        `{"pid": 1234, "sandboxID": "abc"}`,
+			expectedPID: 1234,
+		},
+		{
+			title:       "stopped container",
+			info:        `{"pid": 0}`,
+			expectError: true,
+		},
+		{
+			title:       "invalid info",
+			info:        "",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			socket := filepath.Join(t.TempDir(), "cri.sock")
+			listener, err := net.Listen("unix", socket)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			service := &fakeRuntimeService{info: tc.info, stopped: make(chan *runtimeapi.StopContainerRequest, 1)}
+			server := grpc.NewServer()
+			runtimeapi.RegisterRuntimeServiceServer(server, service)
+			go func() {
+				_ = server.Serve(listener)
+			}()
+			t.Cleanup(server.Stop)
+
+			containerRuntime, err := newCRIRuntime(socket)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			t.Cleanup(func() {
+				_ = containerRuntime.Close()
+			})
+
+			pid, err := containerRuntime.ContainerPID(context.TODO(), "abc")
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if pid != tc.expectedPID {
+				t.Fatalf("expected pid %d returned %d", tc.expectedPID, pid)
+			}
+
+			err = containerRuntime.StopContainer(context.TODO(), "abc", 5*time.Second)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			stopped := <-service.stopped
+			if stopped.ContainerId != "abc" || stopped.Timeout != 5 {
+				t.Fatalf("expected container abc stopped with timeout 5 stopped %s with %d", stopped.ContainerId, stopped.Timeout)
+			}
+		})
+	}
+}
+
+func Test_SocketPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		endpoint     string
+		expectedPath string
+		expectError  bool
+	}{
+		{
+			title:        "unix socket",
+			endpoint:     "unix:///run/containerd/containerd.sock",
+			expectedPath: "/proc/1/root/run/containerd/containerd.sock",
+		},
+		{
+			title:       "tcp endpoint",
+			endpoint:    "tcp://localhost:3735",
+			expectError: true,
+		},
+		{
+			title:       "path without scheme",
+			endpoint:    "/run/crio/crio.sock",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			path, err := socketPath(tc.endpoint)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if path != tc.expectedPath {
+				t.Fatalf("expected path %q returned %q", tc.expectedPath, path)
+			}
+		})
+	}
+}
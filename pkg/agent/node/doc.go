@@ -0,0 +1,3 @@
+// Package node implements disruptors that act on the node where the agent runs.
+// The agent is expected to run in a privileged container that shares the host's process namespace.
+package node
@@ -0,0 +1,119 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+)
+
+// DefaultKubeletService is the name of the systemd service that runs the kubelet
+const DefaultKubeletService = "kubelet"
+
+// kubeletStopDelay is the time the stop of the service is delayed to allow the agent to report the result
+// of the command before the kubelet, which proxies the connection to the agent, is stopped.
+const kubeletStopDelay = time.Second
+
+// KubeletRestartDisruption defines a disruption that stops the kubelet for a period of time
+type KubeletRestartDisruption struct {
+	// Service is the name of the systemd service that runs the kubelet
+	Service string
+}
+
+// KubeletRestartDisruptor stops and restarts the kubelet in the host
+type KubeletRestartDisruptor struct {
+	executor   runtime.Executor
+	disruption KubeletRestartDisruption
+	// id identifies the units of the timers scheduled by the disruptor, so they do not collide with those of other
+	// disruptions
+	id string
+}
+
+// NewKubeletRestartDisruptor returns a new KubeletRestartDisruptor
+func NewKubeletRestartDisruptor(
+	executor runtime.Executor,
+	disruption KubeletRestartDisruption,
+) (*KubeletRestartDisruptor, error) {
+	if disruption.Service == "" {
+		return nil, errors.New("kubelet service name cannot be empty")
+	}
+
+	return &KubeletRestartDisruptor{
+		executor:   executor,
+		disruption: disruption,
+		id:         strconv.FormatInt(time.Now().UnixNano(), 36),
+	}, nil
+}
+
+// hostExec executes a command in the namespaces of the host's init process
+func (d *KubeletRestartDisruptor) hostExec(args ...string) error {
+	nsenterArgs := append([]string{"-t", "1", "-m", "-u", "-i", "-n", "-p", "--"}, args...)
+	out, err := d.executor.Exec("nsenter", nsenterArgs...)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, string(out))
+	}
+
+	return nil
+}
+
+// unit returns the name of the transient unit that runs the action on the kubelet service
+func (d *KubeletRestartDisruptor) unit(action string) string {
+	return fmt.Sprintf("xk6-disruptor-%s-%s-%s", d.disruption.Service, action, d.id)
+}
+
+// schedule uses a transient systemd timer to run an action on the kubelet service after the given delay
+func (d *KubeletRestartDisruptor) schedule(action string, delay time.Duration) error {
+	return d.hostExec(
+		"systemd-run",
+		"--collect",
+		"--unit="+d.unit(action),
+		fmt.Sprintf("--on-active=%s", utils.DurationSeconds(delay)),
+		"systemctl", action, d.disruption.Service,
+	)
+}
+
+// cancel stops the timers that have not been triggered and starts the kubelet service, in case it was stopped
+func (d *KubeletRestartDisruptor) cancel() error {
+	err := d.hostExec("systemctl", "stop", d.unit("stop")+".timer", d.unit("start")+".timer")
+	if err != nil {
+		err = fmt.Errorf("cancelling timers: %w", err)
+	}
+
+	startErr := d.hostExec("systemctl", "start", d.disruption.Service)
+	if startErr != nil {
+		startErr = fmt.Errorf("starting %s: %w", d.disruption.Service, startErr)
+	}
+
+	return errors.Join(err, startErr)
+}
+
+// Apply stops the kubelet service and starts it after the given duration.
+// Both actions are scheduled in the host because stopping the kubelet terminates the connection
+// to the agent, so the agent cannot be relied upon for restoring the service. If the context is done before the
+// duration, the timers are cancelled and the service is started.
+func (d *KubeletRestartDisruptor) Apply(ctx context.Context, duration time.Duration) error {
+	// schedule the start first so the kubelet is not left stopped if scheduling the stop fails
+	err := d.schedule("start", kubeletStopDelay+duration)
+	if err != nil {
+		return fmt.Errorf("scheduling start of %s: %w", d.disruption.Service, err)
+	}
+
+	err = d.schedule("stop", kubeletStopDelay)
+	if err != nil {
+		return errors.Join(
+			fmt.Errorf("scheduling stop of %s: %w", d.disruption.Service, err),
+			d.cancel(),
+		)
+	}
+
+	select {
+	case <-time.After(kubeletStopDelay + duration):
+		return nil
+	case <-ctx.Done():
+		return errors.Join(ctx.Err(), d.cancel())
+	}
+}
@@ -0,0 +1,118 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_KubeletRestart(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		disruption  KubeletRestartDisruption
+		duration    time.Duration
+		timeout     time.Duration
+		execError   error
+		expectError bool
+		expectedCmd []string
+	}{
+		{
+			title:      "restart kubelet",
+			disruption: KubeletRestartDisruption{Service: "kubelet"},
+			duration:   100 * time.Millisecond,
+			expectedCmd: []string{
+				"nsenter -t 1 -m -u -i -n -p -- systemd-run --collect --unit=xk6-disruptor-kubelet-start-test" +
+					" --on-active=1.1s systemctl start kubelet",
+				"nsenter -t 1 -m -u -i -n -p -- systemd-run --collect --unit=xk6-disruptor-kubelet-stop-test" +
+					" --on-active=1s systemctl stop kubelet",
+			},
+		},
+		{
+			title:      "custom service",
+			disruption: KubeletRestartDisruption{Service: "k3s"},
+			duration:   100 * time.Millisecond,
+			expectedCmd: []string{
+				"nsenter -t 1 -m -u -i -n -p -- systemd-run --collect --unit=xk6-disruptor-k3s-start-test" +
+					" --on-active=1.1s systemctl start k3s",
+				"nsenter -t 1 -m -u -i -n -p -- systemd-run --collect --unit=xk6-disruptor-k3s-stop-test" +
+					" --on-active=1s systemctl stop k3s",
+			},
+		},
+		{
+			title:       "cancelled",
+			disruption:  KubeletRestartDisruption{Service: "kubelet"},
+			duration:    10 * time.Second,
+			timeout:     100 * time.Millisecond,
+			expectError: true,
+			expectedCmd: []string{
+				"nsenter -t 1 -m -u -i -n -p -- systemd-run --collect --unit=xk6-disruptor-kubelet-start-test" +
+					" --on-active=11s systemctl start kubelet",
+				"nsenter -t 1 -m -u -i -n -p -- systemd-run --collect --unit=xk6-disruptor-kubelet-stop-test" +
+					" --on-active=1s systemctl stop kubelet",
+				"nsenter -t 1 -m -u -i -n -p -- systemctl stop xk6-disruptor-kubelet-stop-test.timer" +
+					" xk6-disruptor-kubelet-start-test.timer",
+				"nsenter -t 1 -m -u -i -n -p -- systemctl start kubelet",
+			},
+		},
+		{
+			title:       "failed scheduling",
+			disruption:  KubeletRestartDisruption{Service: "kubelet"},
+			duration:    10 * time.Second,
+			execError:   errors.New("failed"),
+			expectError: true,
+			// the stop must not be scheduled if the start failed
+			expectedCmd: []string{
+				"nsenter -t 1 -m -u -i -n -p -- systemd-run --collect --unit=xk6-disruptor-kubelet-start-test" +
+					" --on-active=11s systemctl start kubelet",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, tc.execError)
+
+			disruptor, err := NewKubeletRestartDisruptor(executor, tc.disruption)
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+			disruptor.id = "test"
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			err = disruptor.Apply(ctx, tc.duration)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+
+			history := executor.CmdHistory()
+			if len(history) != len(tc.expectedCmd) {
+				t.Fatalf("expected %d commands got %d", len(tc.expectedCmd), len(history))
+			}
+
+			for i, cmd := range tc.expectedCmd {
+				if history[i] != cmd {
+					t.Errorf("expected command:\n%s\ngot:\n%s", cmd, history[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+)
+
+// DefaultKubeletPort is the port the kubelet listens for requests from the API server
+const DefaultKubeletPort = 10250
+
+// NetworkDisruption defines a disruption that delays and drops the traffic leaving the host
+type NetworkDisruption struct {
+	// Interface is the network interface to disrupt. If empty, the interface of the default route is used
+	Interface string
+	// Delay added to the packets
+	Delay time.Duration
+	// Variation in the delay added to the packets
+	Jitter time.Duration
+	// Fraction (in the range 0.0 to 1.0) of packets to drop
+	Loss float32
+	// ExcludeIPs is a list of destination addresses whose traffic is not disrupted
+	ExcludeIPs []string
+	// ExcludePorts is a list of source ports whose traffic is not disrupted
+	ExcludePorts []uint
+}
+
+// NetworkDisruptor disrupts the traffic leaving the host using a netem queuing discipline
+type NetworkDisruptor struct {
+	executor   runtime.Executor
+	disruption NetworkDisruption
+}
+
+// NewNetworkDisruptor returns a new NetworkDisruptor
+func NewNetworkDisruptor(executor runtime.Executor, disruption NetworkDisruption) (*NetworkDisruptor, error) {
+	if disruption.Delay <= 0 && disruption.Loss <= 0 {
+		return nil, errors.New("either delay or loss must be specified")
+	}
+
+	if disruption.Jitter < 0 || disruption.Jitter > disruption.Delay {
+		return nil, errors.New("jitter must be between 0 and delay")
+	}
+
+	if disruption.Loss < 0 || disruption.Loss > 1.0 {
+		return nil, errors.New("loss must be between 0.0 and 1.0")
+	}
+
+	for _, ip := range disruption.ExcludeIPs {
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("invalid excluded address %q", ip)
+		}
+	}
+
+	return &NetworkDisruptor{
+		executor:   executor,
+		disruption: disruption,
+	}, nil
+}
+
+func (d *NetworkDisruptor) exec(cmd string, args ...string) ([]byte, error) {
+	out, err := d.executor.Exec(cmd, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w: %s", cmd, strings.Join(args, " "), err, string(out))
+	}
+
+	return out, nil
+}
+
+// defaultInterface returns the interface of the default route
+func (d *NetworkDisruptor) defaultInterface() (string, error) {
+	out, err := d.exec("ip", "route", "show", "default")
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(string(out))
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == "dev" {
+			return fields[i+1], nil
+		}
+	}
+
+	return "", errors.New("default route not found")
+}
+
+// netemArgs returns the arguments for the netem queuing discipline
+func (d *NetworkDisruptor) netemArgs() []string {
+	args := []string{}
+	if d.disruption.Delay > 0 {
+		args = append(args, "delay", utils.DurationMillSeconds(d.disruption.Delay))
+		if d.disruption.Jitter > 0 {
+			args = append(args, utils.DurationMillSeconds(d.disruption.Jitter))
+		}
+	}
+
+	if d.disruption.Loss > 0 {
+		args = append(args, "loss", fmt.Sprintf("%g%%", d.disruption.Loss*100))
+	}
+
+	return args
+}
+
+// excludeFilters returns the arguments for the filters that send the excluded traffic to the undisrupted band
+func (d *NetworkDisruptor) excludeFilters(iface string) [][]string {
+	filters := [][]string{}
+	for _, ip := range d.disruption.ExcludeIPs {
+		match := []string{"protocol", "ip", "prio", "1", "u32", "match", "ip", "dst", ip + "/32"}
+		if net.ParseIP(ip).To4() == nil {
+			match = []string{"protocol", "ipv6", "prio", "2", "u32", "match", "ip6", "dst", ip + "/128"}
+		}
+		filters = append(filters, append([]string{"filter", "add", "dev", iface, "parent", "1:0"}, match...))
+	}
+
+	for _, port := range d.disruption.ExcludePorts {
+		for _, proto := range []string{"ip", "ipv6"} {
+			prio, matcher := "1", "ip"
+			if proto == "ipv6" {
+				prio, matcher = "2", "ip6"
+			}
+			filters = append(filters, []string{
+				"filter", "add", "dev", iface, "parent", "1:0",
+				"protocol", proto, "prio", prio, "u32", "match", matcher, "sport", fmt.Sprint(port), "0xffff",
+			})
+		}
+	}
+
+	for i := range filters {
+		filters[i] = append(filters[i], "flowid", "1:1")
+	}
+
+	return filters
+}
+
+// Apply adds the netem queuing discipline to the interface for the given duration.
+// All the traffic is sent to a band that applies the disruption except the excluded traffic, which is sent
+// to an undisrupted band.
+func (d *NetworkDisruptor) Apply(ctx context.Context, duration time.Duration) error {
+	iface := d.disruption.Interface
+	if iface == "" {
+		var err error
+		iface, err = d.defaultInterface()
+		if err != nil {
+			return fmt.Errorf("finding default interface: %w", err)
+		}
+	}
+
+	// send all traffic to band 4 by default
+	priomap := strings.Fields(strings.Repeat("3 ", 16))
+	_, err := d.exec("tc", append([]string{
+		"qdisc", "add", "dev", iface, "root", "handle", "1:", "prio", "bands", "4", "priomap",
+	}, priomap...)...)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_, _ = d.exec("tc", "qdisc", "del", "dev", iface, "root")
+	}()
+
+	_, err = d.exec("tc", append([]string{
+		"qdisc", "add", "dev", iface, "parent", "1:4", "handle", "40:", "netem",
+	}, d.netemArgs()...)...)
+	if err != nil {
+		return err
+	}
+
+	for _, filter := range d.excludeFilters(iface) {
+		_, err = d.exec("tc", filter...)
+		if err != nil {
+			return err
+		}
+	}
+
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
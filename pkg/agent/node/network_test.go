@@ -0,0 +1,110 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_NetworkDisruptor(t *testing.T) {
+	t.Parallel()
+
+	const priomap = "priomap 3 3 3 3 3 3 3 3 3 3 3 3 3 3 3 3"
+
+	testCases := []struct {
+		title       string
+		disruption  NetworkDisruption
+		expectError bool
+		expectedCmd []string
+	}{
+		{
+			title: "delay in default interface",
+			disruption: NetworkDisruption{
+				Delay:  100 * time.Millisecond,
+				Jitter: 10 * time.Millisecond,
+			},
+			expectedCmd: []string{
+				"ip route show default",
+				"tc qdisc add dev eth0 root handle 1: prio bands 4 " + priomap,
+				"tc qdisc add dev eth0 parent 1:4 handle 40: netem delay 100ms 10ms",
+				"tc qdisc del dev eth0 root",
+			},
+		},
+		{
+			title: "loss with exclusions",
+			disruption: NetworkDisruption{
+				Interface:    "ens5",
+				Loss:         0.1,
+				ExcludeIPs:   []string{"10.0.0.1", "fd00::1"},
+				ExcludePorts: []uint{10250},
+			},
+			expectedCmd: []string{
+				"tc qdisc add dev ens5 root handle 1: prio bands 4 " + priomap,
+				"tc qdisc add dev ens5 parent 1:4 handle 40: netem loss 10%",
+				"tc filter add dev ens5 parent 1:0 protocol ip prio 1 u32 match ip dst 10.0.0.1/32 flowid 1:1",
+				"tc filter add dev ens5 parent 1:0 protocol ipv6 prio 2 u32 match ip6 dst fd00::1/128 flowid 1:1",
+				"tc filter add dev ens5 parent 1:0 protocol ip prio 1 u32 match ip sport 10250 0xffff flowid 1:1",
+				"tc filter add dev ens5 parent 1:0 protocol ipv6 prio 2 u32 match ip6 sport 10250 0xffff flowid 1:1",
+				"tc qdisc del dev ens5 root",
+			},
+		},
+		{
+			title:       "no delay nor loss",
+			disruption:  NetworkDisruption{},
+			expectError: true,
+		},
+		{
+			title: "invalid excluded address",
+			disruption: NetworkDisruption{
+				Delay:      100 * time.Millisecond,
+				ExcludeIPs: []string{"not-an-ip"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewCallbackExecutor(func(cmd string, args ...string) ([]byte, error) {
+				if cmd == "ip" {
+					return []byte("default via 10.0.0.1 dev eth0 proto dhcp src 10.0.0.10 metric 100"), nil
+				}
+				return nil, nil
+			})
+
+			disruptor, err := NewNetworkDisruptor(executor, tc.disruption)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed creating disruptor: %v", err)
+			}
+
+			err = disruptor.Apply(context.TODO(), 0)
+			if err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+
+			history := executor.CmdHistory()
+			if len(history) != len(tc.expectedCmd) {
+				t.Fatalf("expected commands:\n%v\ngot:\n%v", tc.expectedCmd, history)
+			}
+
+			for i, cmd := range tc.expectedCmd {
+				if history[i] != cmd {
+					t.Errorf("expected command:\n%s\ngot:\n%s", cmd, history[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package agent
+
+import "syscall"
+
+// StopSignal is the signal that requests the agent to stop applying a disruption before its duration elapses.
+// Contrary to other signals, stopping the disruption is not reported as an error.
+const StopSignal = syscall.SIGUSR1
+
+// SignalProcess sends a signal to a process
+func SignalProcess(pid int, signal syscall.Signal) error {
+	return syscall.Kill(pid, signal)
+}
+
+// checkPlatform returns an error if the agent cannot apply faults in the platform it runs in
+func checkPlatform() error {
+	return nil
+}
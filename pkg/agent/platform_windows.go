@@ -0,0 +1,24 @@
+//go:build windows
+
+package agent
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// StopSignal is the signal that requests the agent to stop applying a disruption before its duration elapses.
+// Windows does not support user-defined signals, therefore this signal is never received.
+const StopSignal = syscall.Signal(0x1e)
+
+// SignalProcess sends a signal to a process. Signals other than the termination of the process are not supported
+// in Windows, therefore the agents cannot be signaled.
+func SignalProcess(_ int, _ syscall.Signal) error {
+	return ErrUnsupportedPlatform
+}
+
+// checkPlatform returns an error if the agent cannot apply faults in the platform it runs in. The faults rely on
+// Linux facilities such as iptables, therefore they cannot be applied in Windows.
+func checkPlatform() error {
+	return fmt.Errorf("%w: the agent cannot apply faults in windows", ErrUnsupportedPlatform)
+}
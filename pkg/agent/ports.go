@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PortRange is a range of ports, including its first and last ports
+type PortRange struct {
+	First uint
+	Last  uint
+}
+
+// ParsePortRange parses a range of ports with the format first-last (e.g. 8000-8099)
+func ParsePortRange(value string) (PortRange, error) {
+	first, last, found := strings.Cut(value, "-")
+	if !found {
+		return PortRange{}, fmt.Errorf("invalid port range %q: expected format first-last", value)
+	}
+
+	firstPort, err := strconv.ParseUint(strings.TrimSpace(first), 10, 16)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", value, err)
+	}
+
+	lastPort, err := strconv.ParseUint(strings.TrimSpace(last), 10, 16)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", value, err)
+	}
+
+	if firstPort == 0 || firstPort > lastPort {
+		return PortRange{}, fmt.Errorf("invalid port range %q: first port must be positive and not after last port", value)
+	}
+
+	return PortRange{First: uint(firstPort), Last: uint(lastPort)}, nil
+}
+
+// ParsePorts parses a comma-separated list of ports (e.g. 22,9090)
+func ParsePorts(value string) ([]uint, error) {
+	ports := []uint{}
+	for _, port := range strings.Split(value, ",") {
+		parsed, err := strconv.ParseUint(strings.TrimSpace(port), 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port %q: %w", port, err)
+		}
+
+		if parsed == 0 {
+			return nil, fmt.Errorf("invalid port %q: must be positive", port)
+		}
+
+		ports = append(ports, uint(parsed))
+	}
+
+	return ports, nil
+}
+
+// Listen listens on the first port of the range that is available and not excluded. Returns the listener and its port.
+func (r PortRange) Listen(excluded []uint) (net.Listener, uint, error) {
+	for port := r.First; port <= r.Last; port++ {
+		if slices.Contains(excluded, port) {
+			continue
+		}
+
+		listener, err := net.Listen("tcp", net.JoinHostPort("", fmt.Sprint(port)))
+		if err == nil {
+			return listener, port, nil
+		}
+	}
+
+	return nil, 0, fmt.Errorf("no port available in the range %d-%d", r.First, r.Last)
+}
@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_ParsePortRange(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		value       string
+		expectError bool
+		expected    PortRange
+	}{
+		{
+			title:       "valid range",
+			value:       "8000-8099",
+			expectError: false,
+			expected:    PortRange{First: 8000, Last: 8099},
+		},
+		{
+			title:       "single port",
+			value:       "8000-8000",
+			expectError: false,
+			expected:    PortRange{First: 8000, Last: 8000},
+		},
+		{
+			title:       "missing last port",
+			value:       "8000",
+			expectError: true,
+		},
+		{
+			title:       "inverted range",
+			value:       "8099-8000",
+			expectError: true,
+		},
+		{
+			title:       "invalid port",
+			value:       "8000-70000",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			portRange, err := ParsePortRange(tc.value)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, portRange); diff != "" {
+				t.Fatalf("expected range does not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ParsePorts(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		value       string
+		expectError bool
+		expected    []uint
+	}{
+		{
+			title:       "single port",
+			value:       "22",
+			expectError: false,
+			expected:    []uint{22},
+		},
+		{
+			title:       "multiple ports",
+			value:       "22, 9090",
+			expectError: false,
+			expected:    []uint{22, 9090},
+		},
+		{
+			title:       "invalid port",
+			value:       "22,http",
+			expectError: true,
+		},
+		{
+			title:       "zero port",
+			value:       "0",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			ports, err := ParsePorts(tc.value)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, ports); diff != "" {
+				t.Fatalf("expected ports do not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_PortRangeListen(t *testing.T) {
+	t.Parallel()
+
+	// use a port that is known to be in use
+	busy, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	defer busy.Close() //nolint:errcheck // the listener is only used for reserving the port
+
+	busyPort := uint(busy.Addr().(*net.TCPAddr).Port) //nolint:forcetypeassert
+
+	_, _, err = PortRange{First: busyPort, Last: busyPort}.Listen(nil)
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+
+	// reserve a port and release it for the test
+	free, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	freePort := uint(free.Addr().(*net.TCPAddr).Port) //nolint:forcetypeassert
+	_ = free.Close()
+
+	_, _, err = PortRange{First: freePort, Last: freePort}.Listen([]uint{freePort})
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+
+	listener, port, err := PortRange{First: freePort, Last: freePort}.Listen(nil)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	_ = listener.Close()
+
+	if port != freePort {
+		t.Fatalf("expected port %d returned %d", freePort, port)
+	}
+}
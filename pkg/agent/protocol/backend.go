@@ -0,0 +1,80 @@
+package protocol
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/iptables"
+	"github.com/grafana/xk6-disruptor/pkg/nftables"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// Backends that intercept the traffic directed to the target and redirect it to the proxy
+const (
+	// BackendAuto selects the most efficient backend supported by the target, falling back to iptables
+	BackendAuto = "auto"
+	// BackendIptables redirects the traffic using iptables REDIRECT rules
+	BackendIptables = "iptables"
+	// BackendNftables redirects the traffic using nftables redirect rules, for nodes that do not support iptables
+	BackendNftables = "nftables"
+)
+
+// ValidateBackend returns an error if the backend is not valid
+func ValidateBackend(backend string) error {
+	if !slices.Contains([]string{BackendAuto, BackendIptables, BackendNftables}, backend) {
+		return fmt.Errorf(
+			"%w: invalid interception backend %q: must be one of %s, %s or %s",
+			agent.ErrInvalidFault,
+			backend,
+			BackendAuto,
+			BackendIptables,
+			BackendNftables,
+		)
+	}
+
+	return nil
+}
+
+// NewBackendTrafficRedirector returns a TrafficRedirector that uses the given backend. BackendAuto selects the backend
+// supported by the target.
+func NewBackendTrafficRedirector(
+	backend string,
+	tr *TrafficRedirectionSpec,
+	executor runtime.Executor,
+) (TrafficRedirector, error) {
+	if err := ValidateBackend(backend); err != nil {
+		return nil, err
+	}
+
+	if backend == BackendAuto {
+		backend = DetectBackend(executor)
+	}
+
+	switch backend {
+	case BackendNftables:
+		redirector, err := NewNftablesTrafficRedirector(tr, nftables.New(executor))
+		if err != nil {
+			return nil, err
+		}
+
+		return redirector, nil
+	default:
+		redirector, err := NewTrafficRedirector(tr, iptables.New(executor))
+		if err != nil {
+			return nil, err
+		}
+
+		return redirector, nil
+	}
+}
+
+// DetectBackend returns the backend supported by the target. Nodes that only support nftables fail when running
+// iptables (legacy) commands.
+func DetectBackend(executor runtime.Executor) string {
+	if !iptables.New(executor).Available() && nftables.New(executor).Available() {
+		return BackendNftables
+	}
+
+	return BackendIptables
+}
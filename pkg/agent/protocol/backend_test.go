@@ -0,0 +1,90 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_NewBackendTrafficRedirector(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		backend      string
+		iptablesErr  error
+		expectedErr  error
+		expectedType string
+	}{
+		{
+			title:        "auto",
+			backend:      BackendAuto,
+			expectedErr:  nil,
+			expectedType: "*protocol.Redirector",
+		},
+		{
+			title:        "auto in nftables-only node",
+			backend:      BackendAuto,
+			iptablesErr:  errors.New("iptables v1.8.9 (legacy): can't initialize iptables table `nat'"),
+			expectedErr:  nil,
+			expectedType: "*protocol.NftablesRedirector",
+		},
+		{
+			title:        "iptables",
+			backend:      BackendIptables,
+			expectedErr:  nil,
+			expectedType: "*protocol.Redirector",
+		},
+		{
+			title:        "nftables",
+			backend:      BackendNftables,
+			expectedErr:  nil,
+			expectedType: "*protocol.NftablesRedirector",
+		},
+		{
+			title:       "invalid backend",
+			backend:     "ipvs",
+			expectedErr: agent.ErrInvalidFault,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			tr := &TrafficRedirectionSpec{
+				DestinationPort: 80,
+				RedirectPort:    8080,
+			}
+
+			executor := runtime.NewCallbackExecutor(func(cmd string, _ ...string) ([]byte, error) {
+				if cmd == "iptables" {
+					return nil, tc.iptablesErr
+				}
+				return nil, nil
+			})
+
+			redirector, err := NewBackendTrafficRedirector(tc.backend, tr, executor)
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v returned %v", tc.expectedErr, err)
+			}
+
+			if tc.expectedErr != nil {
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if redirectorType := fmt.Sprintf("%T", redirector); redirectorType != tc.expectedType {
+				t.Fatalf("expected redirector %s returned %s", tc.expectedType, redirectorType)
+			}
+		})
+	}
+}
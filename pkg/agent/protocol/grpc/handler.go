@@ -30,6 +30,7 @@ func NewHandler(disruption Disruption, forwardConn *grpc.ClientConn, metrics *pr
 		disruption:  disruption,
 		forwardConn: forwardConn,
 		metrics:     metrics,
+		ramp:        protocol.NewRamp(disruption.RampDuration),
 	}
 
 	// return the handler function
@@ -40,6 +41,7 @@ type handler struct {
 	disruption  Disruption
 	forwardConn *grpc.ClientConn
 	metrics     *protocol.MetricMap
+	ramp        protocol.Ramp
 }
 
 // contains verifies if a list of strings contains the given string
@@ -69,18 +71,19 @@ func (h *handler) streamHandler(_ interface{}, serverStream grpc.ServerStream) e
 		return h.transparentForward(serverStream)
 	}
 
-	if rand.Float32() < h.disruption.ErrorRate {
+	severity := h.ramp.Severity(time.Now())
+
+	if rand.Float32() < h.disruption.ErrorRate*float32(severity) {
 		h.metrics.Inc(protocol.MetricRequestsDisrupted)
 		return h.injectError(serverStream)
 	}
 
 	// add delay
-	if h.disruption.AverageDelay > 0 {
+	if averageDelay := protocol.ScaleDuration(h.disruption.AverageDelay, severity); averageDelay > 0 {
 		h.metrics.Inc(protocol.MetricRequestsDisrupted)
 
-		delay := int64(h.disruption.AverageDelay)
-		if h.disruption.DelayVariation > 0 {
-			variation := int64(h.disruption.DelayVariation)
+		delay := int64(averageDelay)
+		if variation := int64(protocol.ScaleDuration(h.disruption.DelayVariation, severity)); variation > 0 {
 			delay = delay + variation - 2*rand.Int63n(variation)
 		}
 		time.Sleep(time.Duration(delay))
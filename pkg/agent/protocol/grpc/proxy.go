@@ -27,6 +27,8 @@ type Disruption struct {
 	StatusMessage string
 	// List of grpc services to be excluded from disruptions
 	Excluded []string
+	// Duration of the linear increase of the delay and error rate from zero to their configured values
+	RampDuration time.Duration
 }
 
 // Proxy defines the parameters used by the proxy for processing grpc requests and its execution state
@@ -55,6 +57,10 @@ func NewProxy(listener net.Listener, upstreamAddress string, d Disruption) (prot
 		return nil, fmt.Errorf("status code cannot be 0 (OK)")
 	}
 
+	if d.RampDuration < 0 {
+		return nil, fmt.Errorf("ramp duration cannot be negative")
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	conn, err := grpc.DialContext(
 		ctx,
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Faults applied to the requests reported in the access log
+const (
+	// AccessLogFaultNone reports the request was forwarded without any fault, for example during the ramp
+	AccessLogFaultNone = "none"
+	// AccessLogFaultExcluded reports the request was excluded from the disruption
+	AccessLogFaultExcluded = "excluded"
+	// AccessLogFaultDelay reports the response of the upstream was delayed
+	AccessLogFaultDelay = "delay"
+	// AccessLogFaultError reports an error was returned instead of forwarding the request
+	AccessLogFaultError = "error"
+)
+
+// AccessLogEntry describes a request handled by the proxy and the fault applied to it
+type AccessLogEntry struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	// Fault applied to the request: AccessLogFaultNone, AccessLogFaultExcluded, AccessLogFaultDelay or
+	// AccessLogFaultError
+	Fault string `json:"fault"`
+	// Delay injected in the response
+	Delay time.Duration `json:"delay,omitempty"`
+	// Status returned to the client
+	Status int `json:"status"`
+	// UpstreamStatus is the status returned by the upstream. Zero if the request was not forwarded or it failed.
+	UpstreamStatus int `json:"upstreamStatus,omitempty"`
+	// UpstreamError is the reason of the failure forwarding the request to the upstream, if any
+	UpstreamError string `json:"upstreamError,omitempty"`
+}
+
+// accessLog writes the entries as JSON lines. Each entry is written in a single write, therefore multiple proxies
+// can share a file opened for appending.
+type accessLog struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+func (l *accessLog) log(entry AccessLogEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	// nothing to do if writing fails, the access log must not affect the requests
+	_, _ = l.writer.Write(append(line, '\n'))
+}
+
+// statusRecorder records the status written to the response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+)
+
+func Test_AccessLog(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name            string
+		disruption      Disruption
+		upstreamDown    bool
+		endpoints       []string
+		expectedEntries []AccessLogEntry
+	}{
+		{
+			name:       "delays",
+			disruption: Disruption{AverageDelay: 10 * time.Millisecond, Excluded: []string{"/excluded"}},
+			endpoints:  []string{"/included", "/excluded"},
+			expectedEntries: []AccessLogEntry{
+				{
+					Method:         http.MethodGet,
+					Path:           "/included",
+					Fault:          AccessLogFaultDelay,
+					Delay:          10 * time.Millisecond,
+					Status:         http.StatusTeapot,
+					UpstreamStatus: http.StatusTeapot,
+				},
+				{
+					Method:         http.MethodGet,
+					Path:           "/excluded",
+					Fault:          AccessLogFaultExcluded,
+					Status:         http.StatusTeapot,
+					UpstreamStatus: http.StatusTeapot,
+				},
+			},
+		},
+		{
+			name:       "errors",
+			disruption: Disruption{ErrorRate: 1.0, ErrorCode: http.StatusServiceUnavailable},
+			endpoints:  []string{"/included"},
+			expectedEntries: []AccessLogEntry{
+				{
+					Method: http.MethodGet,
+					Path:   "/included",
+					Fault:  AccessLogFaultError,
+					Status: http.StatusServiceUnavailable,
+				},
+			},
+		},
+		{
+			name:         "upstream failure",
+			disruption:   Disruption{},
+			upstreamDown: true,
+			endpoints:    []string{"/included"},
+			expectedEntries: []AccessLogEntry{
+				{
+					Method:        http.MethodGet,
+					Path:          "/included",
+					Fault:         AccessLogFaultNone,
+					Status:        http.StatusBadGateway,
+					UpstreamError: UpstreamErrorUnavailable,
+				},
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			upstreamServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(http.StatusTeapot)
+			}))
+			if tc.upstreamDown {
+				upstreamServer.Close()
+			} else {
+				t.Cleanup(upstreamServer.Close)
+			}
+
+			upstreamURL, err := url.Parse(upstreamServer.URL)
+			if err != nil {
+				t.Fatalf("error parsing httptest url")
+			}
+
+			output := &bytes.Buffer{}
+			handler := &httpHandler{
+				upstreamURL: *upstreamURL,
+				disruption:  tc.disruption,
+				metrics:     protocol.NewMetricMap(supportedMetrics()...),
+				accessLog:   &accessLog{writer: output},
+			}
+
+			proxyServer := httptest.NewServer(handler)
+			t.Cleanup(proxyServer.Close)
+
+			for _, endpoint := range tc.endpoints {
+				resp, getErr := http.Get(proxyServer.URL + endpoint)
+				if getErr != nil {
+					t.Fatalf("requesting %s: %v", endpoint, getErr)
+				}
+				_ = resp.Body.Close()
+			}
+
+			entries := []AccessLogEntry{}
+			for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+				entry := AccessLogEntry{}
+				err = json.Unmarshal([]byte(line), &entry)
+				if err != nil {
+					t.Fatalf("decoding entry %q: %v", line, err)
+				}
+
+				if entry.Time.IsZero() {
+					t.Fatalf("entry %q does not have a time", line)
+				}
+				entry.Time = time.Time{}
+
+				entries = append(entries, entry)
+			}
+
+			if diff := cmp.Diff(tc.expectedEntries, entries); diff != "" {
+				t.Fatalf("expected entries do not match output:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+package http
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+)
+
+// bodyFraming defines how the end of the body of an HTTP/1.x message is determined
+type bodyFraming int
+
+const (
+	// bodyNone is used by the messages without body
+	bodyNone bodyFraming = iota
+	// bodyLength is used by the messages with a Content-Length
+	bodyLength
+	// bodyChunked is used by the messages with a chunked Transfer-Encoding
+	bodyChunked
+	// bodyUntilClose is used by the responses whose body ends when the connection is closed
+	bodyUntilClose
+	// bodyOpaque is used when the data that follows the message is not HTTP/1.x (e.g. upgraded connections)
+	bodyOpaque
+)
+
+// scanState is the part of a message a messageScanner expects next
+type scanState int
+
+const (
+	scanHead scanState = iota
+	scanBody
+	scanChunkSize
+	scanChunkData
+	scanTrailers
+	scanUntilClose
+	scanOpaque
+)
+
+// headEnd is the empty line that ends the head (start line and headers) of a message
+const headEnd = "\r\n\r\n"
+
+// messageScanner follows the framing of the HTTP/1.x messages written to it, without modifying them, for knowing
+// when each message starts and ends. Data it cannot follow is considered opaque and is not scanned.
+type messageScanner struct {
+	state scanState
+	// buffer accumulates the head of the message or the line of the size of a chunk
+	buffer []byte
+	// remaining is the length of the body or of the chunk (including its ending line break) not scanned yet
+	remaining int64
+	// maxHeadBytes is the maximum size of the head of a message. Larger heads make the data opaque.
+	maxHeadBytes int
+	// head is called with the head of each message and returns the framing of its body
+	head func(head []byte) (bodyFraming, int64)
+	// complete is called when a message ends
+	complete func()
+}
+
+// idle returns if the scanner is between messages
+func (s *messageScanner) idle() bool {
+	return s.state == scanHead && len(s.buffer) == 0
+}
+
+// scan follows the framing of the data
+func (s *messageScanner) scan(data []byte) {
+	for len(data) > 0 {
+		switch s.state {
+		case scanHead, scanTrailers:
+			data = s.scanHead(data)
+		case scanBody, scanChunkData:
+			skipped := min(int64(len(data)), s.remaining)
+			s.remaining -= skipped
+			data = data[skipped:]
+			if s.remaining > 0 {
+				continue
+			}
+
+			if s.state == scanChunkData {
+				s.state = scanChunkSize
+			} else {
+				s.end()
+			}
+		case scanChunkSize:
+			data = s.scanChunkSize(data)
+		default:
+			// the rest of the data of the connection is not scanned
+			return
+		}
+	}
+}
+
+// scanHead accumulates the head of a message, or the trailers of a chunked body, until the empty line that ends it.
+// Returns the data that follows it.
+func (s *messageScanner) scanHead(data []byte) []byte {
+	// line breaks preceding a message are ignored
+	if s.state == scanHead && len(s.buffer) == 0 {
+		data = bytes.TrimLeft(data, "\r\n")
+		if len(data) == 0 {
+			return nil
+		}
+	}
+
+	start := max(0, len(s.buffer)-len(headEnd)+1)
+	s.buffer = append(s.buffer, data...)
+
+	i := bytes.Index(s.buffer[start:], []byte(headEnd))
+	if i < 0 {
+		if len(s.buffer) > s.maxHeadBytes {
+			s.opaque()
+		}
+		return nil
+	}
+
+	end := start + i + len(headEnd)
+	rest := data[len(data)-(len(s.buffer)-end):]
+
+	if s.state == scanTrailers {
+		s.end()
+		return rest
+	}
+
+	framing, length := s.head(s.buffer[:end])
+	s.buffer = s.buffer[:0]
+
+	switch framing {
+	case bodyLength:
+		s.state, s.remaining = scanBody, length
+		if length == 0 {
+			s.end()
+		}
+	case bodyChunked:
+		s.state = scanChunkSize
+	case bodyUntilClose:
+		s.state = scanUntilClose
+	case bodyOpaque:
+		s.opaque()
+	default:
+		s.end()
+	}
+
+	return rest
+}
+
+// scanChunkSize accumulates the line with the size of a chunk. Returns the data that follows it.
+func (s *messageScanner) scanChunkSize(data []byte) []byte {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		s.buffer = append(s.buffer, data...)
+		if len(s.buffer) > s.maxHeadBytes {
+			s.opaque()
+		}
+		return nil
+	}
+
+	s.buffer = append(s.buffer, data[:i]...)
+	line, _, _ := strings.Cut(string(s.buffer), ";")
+	s.buffer = s.buffer[:0]
+
+	size, err := strconv.ParseInt(strings.TrimSpace(line), 16, 64)
+	if err != nil || size < 0 {
+		s.opaque()
+		return nil
+	}
+
+	if size == 0 {
+		// the trailers, if any, are followed by an empty line. The line break of the chunk size line is kept for
+		// finding the end of the trailers as the end of a head.
+		s.state = scanTrailers
+		s.buffer = append(s.buffer, '\r', '\n')
+		return data[i+1:]
+	}
+
+	// the data of the chunk is followed by a line break
+	s.state, s.remaining = scanChunkData, size+2
+
+	return data[i+1:]
+}
+
+// end ends the current message
+func (s *messageScanner) end() {
+	s.state = scanHead
+	s.buffer = s.buffer[:0]
+	s.remaining = 0
+	if s.complete != nil {
+		s.complete()
+	}
+}
+
+// opaque stops scanning the data
+func (s *messageScanner) opaque() {
+	s.state = scanOpaque
+	s.buffer = nil
+}
+
+// httpFraming follows the requests and responses exchanged in a connection tunnelled by the proxy, for counting the
+// requests and knowing if the connection is idle, without processing the messages
+type httpFraming struct {
+	mutex     sync.Mutex
+	requests  messageScanner
+	responses messageScanner
+	metrics   *protocol.MetricMap
+	// methods of the requests waiting for a response
+	methods []string
+	// final is true if the response being scanned is not an informational (1xx) response
+	final bool
+}
+
+// newHTTPFraming returns an httpFraming that reports the requests in the metrics
+func newHTTPFraming(metrics *protocol.MetricMap, maxHeadBytes int) *httpFraming {
+	f := &httpFraming{metrics: metrics}
+	f.requests = messageScanner{maxHeadBytes: maxHeadBytes, head: f.requestHead}
+	f.responses = messageScanner{maxHeadBytes: maxHeadBytes, head: f.responseHead, complete: f.responseComplete}
+
+	return f
+}
+
+// scanRequests follows the data sent by the client
+func (f *httpFraming) scanRequests(data []byte) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.requests.scan(data)
+}
+
+// scanResponses follows the data sent by the upstream
+func (f *httpFraming) scanResponses(data []byte) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.responses.scan(data)
+}
+
+// idle returns if the connection has no request in progress. Connections whose data is opaque are never idle.
+func (f *httpFraming) idle() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.requests.idle() && f.responses.idle() && len(f.methods) == 0
+}
+
+// opaque stops following the messages of the connection
+func (f *httpFraming) opaque() {
+	f.requests.opaque()
+	f.responses.opaque()
+}
+
+func (f *httpFraming) requestHead(head []byte) (bodyFraming, int64) {
+	startLine, headers := parseHead(head)
+
+	// the request line has the format "method target protocol"
+	fields := strings.Fields(startLine)
+	if len(fields) != 3 || !strings.HasPrefix(fields[2], "HTTP/1.") {
+		f.opaque()
+		return bodyOpaque, 0
+	}
+
+	f.metrics.Inc(protocol.MetricRequests)
+	f.methods = append(f.methods, fields[0])
+
+	framing, length := messageFraming(headers)
+	if framing == bodyUntilClose {
+		// requests without Content-Length nor Transfer-Encoding have no body
+		framing = bodyNone
+	}
+
+	return framing, length
+}
+
+func (f *httpFraming) responseHead(head []byte) (bodyFraming, int64) {
+	startLine, headers := parseHead(head)
+
+	// the status line has the format "protocol status reason"
+	fields := strings.Fields(startLine)
+	if len(fields) < 2 || !strings.HasPrefix(fields[0], "HTTP/1.") {
+		f.opaque()
+		return bodyOpaque, 0
+	}
+
+	status, err := strconv.Atoi(fields[1])
+	if err != nil || status == 101 {
+		// the connection is switched to another protocol
+		f.opaque()
+		return bodyOpaque, 0
+	}
+
+	f.final = status >= 200
+	if !f.final {
+		return bodyNone, 0
+	}
+
+	method := ""
+	if len(f.methods) > 0 {
+		method = f.methods[0]
+	}
+
+	switch {
+	case method == "CONNECT" && status < 300:
+		// the connection is tunnelled to another destination
+		f.opaque()
+		return bodyOpaque, 0
+	case method == "HEAD" || status == 204 || status == 304:
+		return bodyNone, 0
+	default:
+		return messageFraming(headers)
+	}
+}
+
+func (f *httpFraming) responseComplete() {
+	if f.final && len(f.methods) > 0 {
+		f.methods = f.methods[1:]
+	}
+	f.final = false
+}
+
+// parseHead returns the start line and the header lines of the head of a message
+func parseHead(head []byte) (string, []string) {
+	lines := strings.Split(strings.TrimSpace(string(head)), "\r\n")
+
+	return lines[0], lines[1:]
+}
+
+// messageFraming returns the framing of the body of a message given its header lines. Messages without a
+// Content-Length nor a chunked Transfer-Encoding use bodyUntilClose.
+func messageFraming(headers []string) (bodyFraming, int64) {
+	framing, length := bodyUntilClose, int64(0)
+	for _, header := range headers {
+		name, value, found := strings.Cut(header, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.EqualFold(strings.TrimSpace(name), "Transfer-Encoding"):
+			if strings.Contains(strings.ToLower(value), "chunked") {
+				// the chunked encoding takes precedence over the Content-Length
+				return bodyChunked, 0
+			}
+		case strings.EqualFold(strings.TrimSpace(name), "Content-Length"):
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || parsed < 0 {
+				return bodyOpaque, 0
+			}
+			framing, length = bodyLength, parsed
+		}
+	}
+
+	return framing, length
+}
+
+// scanWriter is an io.Writer that passes the data written to a scan function
+type scanWriter func(data []byte)
+
+func (w scanWriter) Write(data []byte) (int, error) {
+	w(data)
+
+	return len(data), nil
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+)
+
+func Test_HTTPFraming(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name             string
+		requests         []string
+		responses        []string
+		expectedRequests uint
+		expectedIdle     bool
+	}{
+		{
+			name:             "request without body",
+			requests:         []string{"GET / HTTP/1.1\r\nHost: test\r\n\r\n"},
+			responses:        []string{"HTTP/1.1 200 OK\r\nContent-Length: 4\r\n\r\nbody"},
+			expectedRequests: 1,
+			expectedIdle:     true,
+		},
+		{
+			name:             "request waiting for response",
+			requests:         []string{"GET / HTTP/1.1\r\nHost: test\r\n\r\n"},
+			responses:        []string{},
+			expectedRequests: 1,
+			expectedIdle:     false,
+		},
+		{
+			name:             "partial response body",
+			requests:         []string{"GET / HTTP/1.1\r\nHost: test\r\n\r\n"},
+			responses:        []string{"HTTP/1.1 200 OK\r\nContent-Length: 8\r\n\r\nbody"},
+			expectedRequests: 1,
+			expectedIdle:     false,
+		},
+		{
+			name: "pipelined requests with body",
+			requests: []string{
+				"POST / HTTP/1.1\r\nContent-Length: 4\r\n\r\nbodyGET / HTTP/1.1\r\n\r\n",
+			},
+			responses: []string{
+				"HTTP/1.1 204 No Content\r\n\r\n",
+				"HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n",
+			},
+			expectedRequests: 2,
+			expectedIdle:     true,
+		},
+		{
+			name:     "chunked bodies split in writes",
+			requests: []string{"POST / HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n4\r\nbo", "dy\r\n0\r\n\r\n"},
+			responses: []string{
+				"HTTP/1.1 100 Continue\r\n\r\nHTTP/1.1 200 OK\r\nTransfer-Encoding: chunked\r\n\r\n",
+				"4;ext=1\r\nbody\r\n0\r\nTrailer: value\r\n\r\n",
+			},
+			expectedRequests: 1,
+			expectedIdle:     true,
+		},
+		{
+			name:             "response to HEAD request",
+			requests:         []string{"HEAD / HTTP/1.1\r\n\r\n"},
+			responses:        []string{"HTTP/1.1 200 OK\r\nContent-Length: 4\r\n\r\n"},
+			expectedRequests: 1,
+			expectedIdle:     true,
+		},
+		{
+			name:             "response until close",
+			requests:         []string{"GET / HTTP/1.1\r\n\r\n"},
+			responses:        []string{"HTTP/1.1 200 OK\r\n\r\nbody"},
+			expectedRequests: 1,
+			expectedIdle:     false,
+		},
+		{
+			name:             "upgraded connection",
+			requests:         []string{"GET / HTTP/1.1\r\nUpgrade: websocket\r\n\r\n"},
+			responses:        []string{"HTTP/1.1 101 Switching Protocols\r\n\r\nHTTP/1.1 200 OK\r\n\r\n"},
+			expectedRequests: 1,
+			expectedIdle:     false,
+		},
+		{
+			name:             "not HTTP/1",
+			requests:         []string{"PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"},
+			responses:        []string{},
+			expectedRequests: 0,
+			expectedIdle:     false,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			metrics := protocol.NewMetricMap(protocol.MetricRequests)
+			framing := newHTTPFraming(metrics, 1024)
+
+			for _, data := range tc.requests {
+				framing.scanRequests([]byte(data))
+			}
+			for _, data := range tc.responses {
+				framing.scanResponses([]byte(data))
+			}
+
+			if requests := metrics.Map()[protocol.MetricRequests]; requests != tc.expectedRequests {
+				t.Fatalf("expected %d requests counted %d", tc.expectedRequests, requests)
+			}
+
+			if idle := framing.idle(); idle != tc.expectedIdle {
+				t.Fatalf("expected idle %t returned %t", tc.expectedIdle, idle)
+			}
+		})
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+	"golang.org/x/net/netutil"
 )
 
 // Disruption specifies disruptions in http requests
@@ -30,32 +31,197 @@ type Disruption struct {
 	ErrorBody string
 	// List of url paths to be excluded from disruptions
 	Excluded []string
+	// Duration of the linear increase of the delay and error rate from zero to their configured values
+	RampDuration time.Duration
 }
 
+// Defaults of the connections of the proxy to the upstream
+const (
+	// DefaultUpstreamMaxIdleConns is the default maximum number of idle connections kept open to the upstream
+	DefaultUpstreamMaxIdleConns = 100
+	// DefaultUpstreamIdleTimeout is the default maximum time an idle connection to the upstream is kept open
+	DefaultUpstreamIdleTimeout = 90 * time.Second
+)
+
+// ProxyConfig defines the connections of the proxy
+type ProxyConfig struct {
+	// UpstreamKeepAlive reuses the connections to the upstream between requests, as clients using keep-alive do.
+	// Otherwise, a new connection to the upstream is opened for each request.
+	UpstreamKeepAlive bool
+	// UpstreamMaxIdleConns is the maximum number of idle connections kept open to the upstream for reusing them
+	UpstreamMaxIdleConns uint
+	// UpstreamMaxConns limits the number of connections to the upstream. Requests wait for a connection if the limit
+	// is reached. Zero means no limit.
+	UpstreamMaxConns uint
+	// UpstreamIdleTimeout is the maximum time an idle connection to the upstream is kept open
+	UpstreamIdleTimeout time.Duration
+	// UpstreamDialTimeout is the maximum time for establishing a connection to the upstream. Zero uses the default
+	// timeout of 30s.
+	UpstreamDialTimeout time.Duration
+	// UpstreamResponseTimeout is the maximum time waiting for the headers of the response of the upstream after
+	// forwarding a request. Zero means no limit.
+	UpstreamResponseTimeout time.Duration
+	// ReadBufferSize is the size of the buffers for reading from the connections of the clients (socket buffer) and
+	// of the upstream. Zero uses the default sizes.
+	ReadBufferSize uint
+	// WriteBufferSize is the size of the buffers for writing to the connections of the clients (socket buffer) and
+	// of the upstream. Zero uses the default sizes.
+	WriteBufferSize uint
+	// MaxHeaderBytes is the maximum size of the headers of the requests and of the responses of the upstream.
+	// Zero uses the default limits, 1MB for requests and 10MB for responses.
+	MaxHeaderBytes uint
+	// MaxConns limits the number of concurrent connections accepted by the proxy. Clients wait for a connection to
+	// be closed if the limit is reached. Zero means no limit.
+	MaxConns uint
+	// TLS defines how the traffic of targets that serve TLS is handled
+	TLS TLSConfig
+	// AccessLog receives an AccessLogEntry, encoded as a JSON line, for each request handled by the proxy.
+	// The access log is disabled if not set. The connections forwarded without processing the requests, when the
+	// disruption does not affect them or the TLS traffic is not terminated, are not logged.
+	AccessLog io.Writer
+}
+
+// DefaultProxyConfig returns the default configuration of the connections of the proxy
+func DefaultProxyConfig() ProxyConfig {
+	return ProxyConfig{
+		UpstreamKeepAlive:    true,
+		UpstreamMaxIdleConns: DefaultUpstreamMaxIdleConns,
+		UpstreamIdleTimeout:  DefaultUpstreamIdleTimeout,
+	}
+}
+
+// transport returns the transport used for the connections to the upstream
+func (c ProxyConfig) transport() *http.Transport {
+	//nolint:forcetypeassert // the default transport is always an http.Transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = !c.UpstreamKeepAlive
+	// all the requests are forwarded to the same upstream
+	transport.MaxIdleConns = int(c.UpstreamMaxIdleConns)
+	transport.MaxIdleConnsPerHost = int(c.UpstreamMaxIdleConns)
+	transport.MaxConnsPerHost = int(c.UpstreamMaxConns)
+	transport.IdleConnTimeout = c.UpstreamIdleTimeout
+	transport.ReadBufferSize = int(c.ReadBufferSize)
+	transport.WriteBufferSize = int(c.WriteBufferSize)
+	transport.MaxResponseHeaderBytes = int64(c.MaxHeaderBytes)
+	transport.ResponseHeaderTimeout = c.UpstreamResponseTimeout
+	if c.UpstreamDialTimeout > 0 {
+		transport.DialContext = (&net.Dialer{
+			Timeout:   c.UpstreamDialTimeout,
+			KeepAlive: defaultDialKeepAlive,
+		}).DialContext
+	}
+
+	return transport
+}
+
+// defaultDialKeepAlive is the interval between keep-alive probes of the connections to the upstream, as used by
+// http.DefaultTransport
+const defaultDialKeepAlive = 30 * time.Second
+
+// listener returns a listener that applies the buffer sizes and the limit of connections to the connections
+// accepted by the given listener
+func (c ProxyConfig) listener(l net.Listener) net.Listener {
+	if c.ReadBufferSize > 0 || c.WriteBufferSize > 0 {
+		l = &bufferedListener{Listener: l, readBufferSize: int(c.ReadBufferSize), writeBufferSize: int(c.WriteBufferSize)}
+	}
+
+	if c.MaxConns > 0 {
+		l = netutil.LimitListener(l, int(c.MaxConns))
+	}
+
+	return l
+}
+
+// bufferedListener sets the size of the socket buffers of the accepted TCP connections
+type bufferedListener struct {
+	net.Listener
+	readBufferSize  int
+	writeBufferSize int
+}
+
+func (l *bufferedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return conn, nil
+	}
+
+	if l.readBufferSize > 0 {
+		_ = tcpConn.SetReadBuffer(l.readBufferSize)
+	}
+
+	if l.writeBufferSize > 0 {
+		_ = tcpConn.SetWriteBuffer(l.writeBufferSize)
+	}
+
+	return tcpConn, nil
+}
+
+// passthrough returns if the disruption does not affect any request
+func (d Disruption) passthrough() bool {
+	return d.AverageDelay == 0 && d.DelayVariation == 0 && d.ErrorRate == 0
+}
+
+// hostPort returns the address (host:port) of the URL, using the default port of its scheme if it has no port
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// delay returns a random delay for the given severity of the disruption
+func (d Disruption) delay(severity float64) time.Duration {
+	delay := protocol.ScaleDuration(d.AverageDelay, severity)
+	if variation := int64(protocol.ScaleDuration(d.DelayVariation, severity)); variation > 0 {
+		delay += time.Duration(variation - 2*rand.Int63n(variation))
+	}
+
+	return delay
+}
+
+// UpstreamErrorHeader is the header of the responses returned by the proxy when a request cannot be forwarded to
+// the upstream, to distinguish them from the errors injected by the disruption. Its value is the reason:
+// UpstreamErrorUnavailable or UpstreamErrorTimeout.
+const UpstreamErrorHeader = "X-Xk6-Disruptor-Upstream-Error"
+
+// Reasons of the failures forwarding requests to the upstream
+const (
+	// UpstreamErrorUnavailable reports the upstream could not be reached or failed to respond
+	UpstreamErrorUnavailable = "unavailable"
+	// UpstreamErrorTimeout reports the upstream timed out
+	UpstreamErrorTimeout = "timeout"
+)
+
 // Proxy defines the parameters used by the proxy for processing http requests and its execution state
 type proxy struct {
 	listener   net.Listener
 	disruption Disruption
 	srv        *http.Server
+	transport  *http.Transport
 	metrics    *protocol.MetricMap
+	// tunnel forwards the connections without processing the requests if the disruption does not affect them
+	tunnel *tunnel
 }
 
 // NewProxy return a new Proxy for HTTP requests
-func NewProxy(listener net.Listener, upstreamAddress string, d Disruption) (protocol.Proxy, error) {
+func NewProxy(listener net.Listener, upstreamAddress string, d Disruption, c ProxyConfig) (protocol.Proxy, error) {
 	if upstreamAddress == "" {
 		return nil, fmt.Errorf("proxy's forwarding address must be provided")
 	}
 
-	if d.DelayVariation > d.AverageDelay {
-		return nil, fmt.Errorf("variation must be less that average delay")
-	}
-
-	if d.ErrorRate < 0.0 || d.ErrorRate > 1.0 {
-		return nil, fmt.Errorf("error rate must be in the range [0.0, 1.0]")
-	}
-
-	if d.ErrorRate > 0.0 && d.ErrorCode == 0 {
-		return nil, fmt.Errorf("error code must be a valid http error code")
+	if err := validate(d, c); err != nil {
+		return nil, err
 	}
 
 	upstreamURL, err := url.Parse(upstreamAddress)
@@ -63,29 +229,133 @@ func NewProxy(listener net.Listener, upstreamAddress string, d Disruption) (prot
 		return nil, err
 	}
 
+	// The connections are tunnelled if the requests are not affected by the disruption and they can be forwarded
+	// as they are received, which requires the upstream to accept the same protocol as the clients.
+	tunnelled := d.passthrough() && !c.TLS.terminates() && upstreamURL.Scheme == "http"
+	if tunnelled || c.TLS.Mode == TLSModePassthrough {
+		return newTunnelProxy(c.listener(listener), upstreamURL, d, c), nil
+	}
+
 	metrics := protocol.NewMetricMap(supportedMetrics()...)
+	transport := c.transport()
+	listener = c.listener(listener)
+
+	srv := &http.Server{
+		MaxHeaderBytes: int(c.MaxHeaderBytes),
+	}
+
+	if c.TLS.terminates() {
+		srv.TLSConfig, err = c.TLS.serverConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		transport.TLSClientConfig, err = c.TLS.clientConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		upstreamURL.Scheme = "https"
+	}
 
 	handler := &httpHandler{
 		upstreamURL: *upstreamURL,
 		disruption:  d,
 		metrics:     metrics,
+		ramp:        protocol.NewRamp(d.RampDuration),
+		transport:   transport,
 	}
 
+	if c.AccessLog != nil {
+		handler.accessLog = &accessLog{writer: c.AccessLog}
+	}
+	srv.Handler = handler
+
 	return &proxy{
 		listener:   listener,
 		disruption: d,
+		transport:  transport,
 		metrics:    metrics,
-		srv: &http.Server{
-			Handler: handler,
-		},
+		srv:        srv,
 	}, nil
 }
 
+// validate returns an error if the disruption or the configuration of the proxy are not valid
+func validate(d Disruption, c ProxyConfig) error {
+	if d.DelayVariation > d.AverageDelay {
+		return fmt.Errorf("variation must be less that average delay")
+	}
+
+	if d.ErrorRate < 0.0 || d.ErrorRate > 1.0 {
+		return fmt.Errorf("error rate must be in the range [0.0, 1.0]")
+	}
+
+	if d.ErrorRate > 0.0 && d.ErrorCode == 0 {
+		return fmt.Errorf("error code must be a valid http error code")
+	}
+
+	if d.RampDuration < 0 {
+		return fmt.Errorf("ramp duration cannot be negative")
+	}
+
+	if c.UpstreamIdleTimeout < 0 || c.UpstreamDialTimeout < 0 || c.UpstreamResponseTimeout < 0 {
+		return fmt.Errorf("upstream timeouts cannot be negative")
+	}
+
+	if err := c.TLS.validate(); err != nil {
+		return err
+	}
+
+	if c.TLS.Mode == TLSModePassthrough && d.ErrorRate > 0 {
+		return fmt.Errorf("errors cannot be injected in TLS traffic that is not terminated by the proxy")
+	}
+
+	return nil
+}
+
+// newTunnelProxy returns a proxy that forwards the connections to the upstream without processing the requests.
+// It reports the MetricConnections and MetricUpstreamFailures metrics, and the MetricRequests metric if the traffic is
+// not TLS, as the requests are counted by following the messages in the connections. The connections are delayed if
+// the disruption has a delay, as the requests cannot be delayed.
+func newTunnelProxy(listener net.Listener, upstreamURL *url.URL, d Disruption, c ProxyConfig) *proxy {
+	metrics := []string{protocol.MetricConnections, protocol.MetricUpstreamFailures}
+	maxHeadBytes := 0
+	if c.TLS.Mode != TLSModePassthrough {
+		metrics = append(metrics, protocol.MetricRequests)
+		maxHeadBytes = int(c.MaxHeaderBytes)
+		if maxHeadBytes == 0 {
+			maxHeadBytes = http.DefaultMaxHeaderBytes
+		}
+	}
+
+	tunnel := newTunnel(listener, hostPort(upstreamURL), protocol.NewMetricMap(metrics...))
+	tunnel.dialTimeout = c.UpstreamDialTimeout
+	tunnel.maxHeadBytes = maxHeadBytes
+	if !d.passthrough() {
+		ramp := protocol.NewRamp(d.RampDuration)
+		tunnel.delay = func() time.Duration {
+			return d.delay(ramp.Severity(time.Now()))
+		}
+	}
+
+	return &proxy{
+		listener:   listener,
+		disruption: d,
+		metrics:    tunnel.metrics,
+		tunnel:     tunnel,
+	}
+}
+
 // httpHandler implements a http.Handler for disrupting request to a upstream server
 type httpHandler struct {
 	upstreamURL url.URL
 	disruption  Disruption
 	metrics     *protocol.MetricMap
+	ramp        protocol.Ramp
+	// transport used for forwarding the requests to the upstream. Uses http.DefaultTransport if not set.
+	transport http.RoundTripper
+	// accessLog reports the requests handled. Disabled if not set.
+	accessLog *accessLog
 }
 
 // isExcluded checks whether a request should be proxied through without any kind of modification whatsoever.
@@ -101,8 +371,13 @@ func (h *httpHandler) isExcluded(r *http.Request) bool {
 
 // forward forwards a request to the upstream URL.
 // Request is performed immediately, but response won't be sent before the duration specified in delay.
-func (h *httpHandler) forward(rw http.ResponseWriter, req *http.Request, delay time.Duration) {
-	timer := time.After(delay)
+// The requests that are not affected by the disruption are forwarded without delay, which avoids arming a timer.
+// Returns the status of the response of the upstream, or zero if forwarding the request failed.
+func (h *httpHandler) forward(rw http.ResponseWriter, req *http.Request, delay time.Duration) int {
+	var timer <-chan time.Time
+	if delay > 0 {
+		timer = time.After(delay)
+	}
 
 	upstreamReq := req.Clone(context.Background())
 	upstreamReq.Host = h.upstreamURL.Host
@@ -110,14 +385,27 @@ func (h *httpHandler) forward(rw http.ResponseWriter, req *http.Request, delay t
 	upstreamReq.URL.Scheme = h.upstreamURL.Scheme
 	upstreamReq.RequestURI = "" // It is an error to set this field in an HTTP client request.
 
-	response, err := http.DefaultClient.Do(upstreamReq)
-	<-timer
+	transport := h.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	// The request is sent by the transport instead of a client, as the response must be returned as it is. Clients
+	// follow redirects and handle cookies.
+	response, err := transport.RoundTrip(upstreamReq)
+	if timer != nil {
+		<-timer
+	}
 	if err != nil {
-		rw.WriteHeader(http.StatusBadGateway)
-		_, _ = fmt.Fprint(rw, err)
-		return
+		h.upstreamFailure(rw, err)
+		return 0
 	}
 
+	return writeResponse(rw, response)
+}
+
+// writeResponse mirrors the response of the upstream. Returns its status.
+func writeResponse(rw http.ResponseWriter, response *http.Response) int {
 	defer func() {
 		// Fully consume and then close upstream response body.
 		_, _ = io.Copy(io.Discard, response.Body)
@@ -136,6 +424,24 @@ func (h *httpHandler) forward(rw http.ResponseWriter, req *http.Request, delay t
 
 	// ignore errors writing body, nothing to do.
 	_, _ = io.Copy(rw, response.Body)
+
+	return response.StatusCode
+}
+
+// upstreamFailure reports a failure forwarding a request to the upstream, which is not caused by the disruption.
+// The response is identified by the UpstreamErrorHeader.
+func (h *httpHandler) upstreamFailure(rw http.ResponseWriter, err error) {
+	h.metrics.Inc(protocol.MetricUpstreamFailures)
+
+	status, reason := http.StatusBadGateway, UpstreamErrorUnavailable
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		status, reason = http.StatusGatewayTimeout, UpstreamErrorTimeout
+	}
+
+	rw.Header().Set(UpstreamErrorHeader, reason)
+	rw.WriteHeader(status)
+	_, _ = fmt.Fprintf(rw, "upstream error: %v", err)
 }
 
 // injectError waits sleeps the duration specified in delay and then writes the configured error downstream.
@@ -149,32 +455,69 @@ func (h *httpHandler) injectError(rw http.ResponseWriter, delay time.Duration) {
 func (h *httpHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	h.metrics.Inc(protocol.MetricRequests)
 
-	if h.isExcluded(req) {
-		h.metrics.Inc(protocol.MetricRequestsExcluded)
+	if h.accessLog == nil {
 		//nolint:contextcheck // Unclear which context the linter requires us to propagate here.
-		h.forward(rw, req, 0)
+		h.disrupt(rw, req)
 		return
 	}
 
-	delay := h.disruption.AverageDelay
-	if h.disruption.DelayVariation > 0 {
-		variation := int64(h.disruption.DelayVariation)
-		delay += time.Duration(variation - 2*rand.Int63n(variation))
+	entry := AccessLogEntry{
+		Time:   time.Now(),
+		Method: req.Method,
+		Path:   req.URL.Path,
+	}
+
+	recorder := &statusRecorder{ResponseWriter: rw}
+	//nolint:contextcheck // Unclear which context the linter requires us to propagate here.
+	entry.Fault, entry.Delay, entry.UpstreamStatus = h.disrupt(recorder, req)
+	entry.Status = recorder.status
+	entry.UpstreamError = recorder.Header().Get(UpstreamErrorHeader)
+
+	h.accessLog.log(entry)
+}
+
+// disrupt applies the disruption to the request. Returns the fault applied, the delay injected and the status of the
+// response of the upstream, if the request was forwarded.
+func (h *httpHandler) disrupt(rw http.ResponseWriter, req *http.Request) (string, time.Duration, int) {
+	if h.isExcluded(req) {
+		h.metrics.Inc(protocol.MetricRequestsExcluded)
+		//nolint:contextcheck // Unclear which context the linter requires us to propagate here.
+		return AccessLogFaultExcluded, 0, h.forward(rw, req, 0)
 	}
 
-	if h.disruption.ErrorRate > 0 && rand.Float32() <= h.disruption.ErrorRate {
+	severity := h.ramp.Severity(time.Now())
+	delay := h.disruption.delay(severity)
+
+	errorRate := h.disruption.ErrorRate * float32(severity)
+	if errorRate > 0 && rand.Float32() <= errorRate {
 		h.metrics.Inc(protocol.MetricRequestsDisrupted)
 		h.injectError(rw, delay)
-		return
+		return AccessLogFaultError, delay, 0
+	}
+
+	if delay <= 0 {
+		// the request is not affected by the disruption
+		//nolint:contextcheck // Unclear which context the linter requires us to propagate here.
+		return AccessLogFaultNone, 0, h.forward(rw, req, 0)
 	}
 
 	//nolint:contextcheck // Unclear which context the linter requires us to propagate here.
-	h.forward(rw, req, delay)
+	return AccessLogFaultDelay, delay, h.forward(rw, req, delay)
 }
 
 // Start starts the execution of the proxy
 func (p *proxy) Start() error {
-	err := p.srv.Serve(p.listener)
+	if p.tunnel != nil {
+		return p.tunnel.serve()
+	}
+
+	var err error
+	if p.srv.TLSConfig != nil {
+		// the certificates are defined in the TLS config
+		err = p.srv.ServeTLS(p.listener, "", "")
+	} else {
+		err = p.srv.Serve(p.listener)
+	}
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
@@ -183,6 +526,12 @@ func (p *proxy) Start() error {
 
 // Stop stops the execution of the proxy
 func (p *proxy) Stop() error {
+	if p.tunnel != nil {
+		return p.tunnel.shutdown()
+	}
+
+	defer p.transport.CloseIdleConnections()
+
 	return p.srv.Shutdown(context.Background())
 }
 
@@ -193,6 +542,12 @@ func (p *proxy) Metrics() map[string]uint {
 
 // Force stops the proxy without waiting for connections to drain
 func (p *proxy) Force() error {
+	if p.tunnel != nil {
+		return p.tunnel.close()
+	}
+
+	defer p.transport.CloseIdleConnections()
+
 	return p.srv.Close()
 }
 
@@ -204,5 +559,6 @@ func supportedMetrics() []string {
 		protocol.MetricRequests,
 		protocol.MetricRequestsExcluded,
 		protocol.MetricRequestsDisrupted,
+		protocol.MetricUpstreamFailures,
 	}
 }
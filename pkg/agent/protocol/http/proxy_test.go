@@ -7,7 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
@@ -123,6 +126,7 @@ func Test_Validations(t *testing.T) {
 				listener,
 				tc.upstream,
 				tc.disruption,
+				DefaultProxyConfig(),
 			)
 			if !tc.expectError && err != nil {
 				t.Errorf("failed: %v", err)
@@ -364,6 +368,7 @@ func Test_Metrics(t *testing.T) {
 				protocol.MetricRequests:          0,
 				protocol.MetricRequestsExcluded:  0,
 				protocol.MetricRequestsDisrupted: 0,
+				protocol.MetricUpstreamFailures:  0,
 			},
 		},
 		{
@@ -378,6 +383,7 @@ func Test_Metrics(t *testing.T) {
 				protocol.MetricRequests:          2,
 				protocol.MetricRequestsExcluded:  1,
 				protocol.MetricRequestsDisrupted: 1,
+				protocol.MetricUpstreamFailures:  0,
 			},
 		},
 	} {
@@ -417,3 +423,249 @@ func Test_Metrics(t *testing.T) {
 		})
 	}
 }
+
+func Test_UpstreamConnections(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name                string
+		keepAlive           bool
+		requests            int
+		expectedConnections int
+	}{
+		{
+			name:                "keep alive",
+			keepAlive:           true,
+			requests:            5,
+			expectedConnections: 1,
+		},
+		{
+			name:                "without keep alive",
+			keepAlive:           false,
+			requests:            5,
+			expectedConnections: 5,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var mutex sync.Mutex
+			connections := 0
+
+			upstreamServer := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			}))
+			upstreamServer.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					mutex.Lock()
+					connections++
+					mutex.Unlock()
+				}
+			}
+			upstreamServer.Start()
+			t.Cleanup(upstreamServer.Close)
+
+			upstreamURL, err := url.Parse(upstreamServer.URL)
+			if err != nil {
+				t.Fatalf("error parsing httptest url")
+			}
+
+			config := DefaultProxyConfig()
+			config.UpstreamKeepAlive = tc.keepAlive
+
+			handler := &httpHandler{
+				upstreamURL: *upstreamURL,
+				metrics:     protocol.NewMetricMap(supportedMetrics()...),
+				transport:   config.transport(),
+			}
+
+			proxyServer := httptest.NewServer(handler)
+			t.Cleanup(proxyServer.Close)
+
+			for range tc.requests {
+				resp, getErr := http.Get(proxyServer.URL)
+				if getErr != nil {
+					t.Fatalf("failed: %v", getErr)
+				}
+				_ = resp.Body.Close()
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if connections != tc.expectedConnections {
+				t.Fatalf("expected %d upstream connections opened %d", tc.expectedConnections, connections)
+			}
+		})
+	}
+}
+
+func Test_ProxyLimits(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name           string
+		maxHeaderBytes uint
+		headerSize     int
+		expectedStatus int
+	}{
+		{
+			name:           "header within limit",
+			maxHeaderBytes: 64 << 10,
+			headerSize:     32 << 10,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "header exceeds limit",
+			maxHeaderBytes: 1 << 10,
+			headerSize:     32 << 10,
+			expectedStatus: http.StatusRequestHeaderFieldsTooLarge,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			upstreamServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(upstreamServer.Close)
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("error starting test proxy listener: %v", err)
+			}
+
+			config := DefaultProxyConfig()
+			config.MaxHeaderBytes = tc.maxHeaderBytes
+			config.ReadBufferSize = 64 << 10
+			config.WriteBufferSize = 64 << 10
+			config.MaxConns = 10
+
+			proxy, err := NewProxy(listener, upstreamServer.URL, Disruption{AverageDelay: time.Millisecond}, config)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			go func() {
+				_ = proxy.Start()
+			}()
+			t.Cleanup(func() {
+				_ = proxy.Force()
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "http://"+listener.Addr().String(), nil)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			req.Header.Set("X-Large", strings.Repeat("x", tc.headerSize))
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tc.expectedStatus {
+				t.Fatalf("expected status %d returned %d", tc.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func Test_UpstreamFailures(t *testing.T) {
+	t.Parallel()
+
+	slowServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(slowServer.Close)
+
+	// an address where no server is listening
+	closedListener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	if listenErr != nil {
+		t.Fatalf("error starting listener: %v", listenErr)
+	}
+	closedAddress := "http://" + closedListener.Addr().String()
+	_ = closedListener.Close()
+
+	for _, tc := range []struct {
+		name             string
+		upstream         string
+		disruption       Disruption
+		expectedStatus   int
+		expectedReason   string
+		expectedFailures uint
+	}{
+		{
+			name:             "upstream unavailable",
+			upstream:         closedAddress,
+			disruption:       Disruption{AverageDelay: time.Millisecond},
+			expectedStatus:   http.StatusBadGateway,
+			expectedReason:   UpstreamErrorUnavailable,
+			expectedFailures: 1,
+		},
+		{
+			name:             "upstream timeout",
+			upstream:         slowServer.URL,
+			disruption:       Disruption{AverageDelay: time.Millisecond},
+			expectedStatus:   http.StatusGatewayTimeout,
+			expectedReason:   UpstreamErrorTimeout,
+			expectedFailures: 1,
+		},
+		{
+			name:             "injected error",
+			upstream:         closedAddress,
+			disruption:       Disruption{ErrorRate: 1.0, ErrorCode: http.StatusBadGateway},
+			expectedStatus:   http.StatusBadGateway,
+			expectedReason:   "",
+			expectedFailures: 0,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("error starting test proxy listener: %v", err)
+			}
+
+			config := DefaultProxyConfig()
+			config.UpstreamDialTimeout = time.Second
+			config.UpstreamResponseTimeout = 100 * time.Millisecond
+
+			proxy, err := NewProxy(listener, tc.upstream, tc.disruption, config)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			go func() {
+				_ = proxy.Start()
+			}()
+			t.Cleanup(func() {
+				_ = proxy.Force()
+			})
+
+			resp, err := http.Get("http://" + listener.Addr().String())
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tc.expectedStatus {
+				t.Fatalf("expected status %d returned %d", tc.expectedStatus, resp.StatusCode)
+			}
+
+			if reason := resp.Header.Get(UpstreamErrorHeader); reason != tc.expectedReason {
+				t.Fatalf("expected upstream error %q returned %q", tc.expectedReason, reason)
+			}
+
+			if failures := proxy.Metrics()[protocol.MetricUpstreamFailures]; failures != tc.expectedFailures {
+				t.Fatalf("expected %d upstream failures returned %d", tc.expectedFailures, failures)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Modes for handling the traffic of targets that serve TLS
+const (
+	// TLSModeNone handles the traffic as plain HTTP
+	TLSModeNone = ""
+	// TLSModePassthrough forwards the TLS connections without decrypting them. Only delays in the establishment of
+	// the connections can be injected, as the requests cannot be read.
+	TLSModePassthrough = "passthrough"
+	// TLSModeTerminate decrypts the TLS connections using the provided certificate and forwards the requests to the
+	// upstream over TLS
+	TLSModeTerminate = "terminate"
+	// TLSModeMTLS decrypts the TLS connections, verifying the certificates of the clients with the provided CA, and
+	// forwards the requests to the upstream over TLS presenting the provided client certificate
+	TLSModeMTLS = "mtls"
+)
+
+// TLSConfig defines how the proxy handles the traffic of targets that serve TLS. The files are read when the proxy
+// is created.
+type TLSConfig struct {
+	// Mode is the TLS mode: TLSModeNone, TLSModePassthrough, TLSModeTerminate or TLSModeMTLS
+	Mode string
+	// CertFile is the file with the certificate presented to the clients, in PEM format
+	CertFile string
+	// KeyFile is the file with the key of the certificate presented to the clients, in PEM format
+	KeyFile string
+	// CAFile is the file with the certificates of the CA used for verifying the certificates of the upstream and, in
+	// TLSModeMTLS, of the clients. If not specified in TLSModeTerminate, the certificate of the upstream is not
+	// verified, as the upstream is the target of the disruption.
+	CAFile string
+	// ServerName is the name used for verifying the certificate of the upstream, as it is reached by its address.
+	// Defaults to the host of the upstream address.
+	ServerName string
+	// ClientCertFile is the file with the certificate presented to the upstream in TLSModeMTLS. Defaults to CertFile.
+	ClientCertFile string
+	// ClientKeyFile is the file with the key of the certificate presented to the upstream in TLSModeMTLS.
+	// Defaults to KeyFile.
+	ClientKeyFile string
+}
+
+// validate checks the mode and the files it requires
+func (c TLSConfig) validate() error {
+	if !slices.Contains([]string{TLSModeNone, TLSModePassthrough, TLSModeTerminate, TLSModeMTLS}, c.Mode) {
+		return fmt.Errorf(
+			"invalid TLS mode %q: must be one of %s, %s or %s",
+			c.Mode,
+			TLSModePassthrough,
+			TLSModeTerminate,
+			TLSModeMTLS,
+		)
+	}
+
+	if !c.terminates() {
+		return nil
+	}
+
+	if c.CertFile == "" || c.KeyFile == "" {
+		return fmt.Errorf("TLS mode %s requires a certificate and its key", c.Mode)
+	}
+
+	if c.Mode == TLSModeMTLS && c.CAFile == "" {
+		return fmt.Errorf("TLS mode %s requires a CA for verifying the certificates", c.Mode)
+	}
+
+	if (c.ClientCertFile == "") != (c.ClientKeyFile == "") {
+		return fmt.Errorf("the client certificate and its key must be specified together")
+	}
+
+	return nil
+}
+
+// terminates returns if the proxy decrypts the TLS connections
+func (c TLSConfig) terminates() bool {
+	return c.Mode == TLSModeTerminate || c.Mode == TLSModeMTLS
+}
+
+// serverConfig returns the configuration of the TLS connections of the clients
+func (c TLSConfig) serverConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.Mode == TLSModeMTLS {
+		config.ClientCAs, err = c.caPool()
+		if err != nil {
+			return nil, err
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// clientConfig returns the configuration of the TLS connections to the upstream
+func (c TLSConfig) clientConfig() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if c.CAFile == "" {
+		// the upstream is the target of the disruption, reached by its address
+		config.InsecureSkipVerify = true //nolint:gosec
+	} else {
+		pool, err := c.caPool()
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+		config.ServerName = c.ServerName
+	}
+
+	if c.Mode == TLSModeMTLS {
+		certFile, keyFile := c.ClientCertFile, c.ClientKeyFile
+		if certFile == "" {
+			certFile, keyFile = c.CertFile, c.KeyFile
+		}
+
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// caPool returns the pool with the certificates of the CA file
+func (c TLSConfig) caPool() (*x509.CertPool, error) {
+	pem, err := os.ReadFile(c.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading CA: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("loading CA: no certificates found in %s", c.CAFile)
+	}
+
+	return pool, nil
+}
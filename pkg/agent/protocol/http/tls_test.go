@@ -0,0 +1,257 @@
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCerts are the files of a CA and of a certificate signed by it for 127.0.0.1, valid for servers and clients
+type testCerts struct {
+	ca   string
+	cert string
+	key  string
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+
+	err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func generateCerts(t *testing.T) testCerts {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating CA key: %v", err)
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("generating CA: %v", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "test-app"},
+		DNSNames:     []string{"test-app"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caTemplate, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("generating certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("encoding key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certs := testCerts{
+		ca:   filepath.Join(dir, "ca.crt"),
+		cert: filepath.Join(dir, "tls.crt"),
+		key:  filepath.Join(dir, "tls.key"),
+	}
+	writePEM(t, certs.ca, "CERTIFICATE", caDER)
+	writePEM(t, certs.cert, "CERTIFICATE", der)
+	writePEM(t, certs.key, "EC PRIVATE KEY", keyDER)
+
+	return certs
+}
+
+func Test_TLSModes(t *testing.T) {
+	t.Parallel()
+
+	certs := generateCerts(t)
+
+	cert, err := tls.LoadX509KeyPair(certs.cert, certs.key)
+	if err != nil {
+		t.Fatalf("loading certificate: %v", err)
+	}
+
+	pool, err := TLSConfig{CAFile: certs.ca}.caPool()
+	if err != nil {
+		t.Fatalf("loading CA: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name              string
+		tls               TLSConfig
+		disruption        Disruption
+		upstreamMTLS      bool
+		clientCert        bool
+		expectProxyError  bool
+		expectClientError bool
+		expectedStatus    int
+	}{
+		{
+			name:           "passthrough",
+			tls:            TLSConfig{Mode: TLSModePassthrough},
+			disruption:     Disruption{AverageDelay: time.Millisecond},
+			expectedStatus: http.StatusTeapot,
+		},
+		{
+			name:             "passthrough with errors",
+			tls:              TLSConfig{Mode: TLSModePassthrough},
+			disruption:       Disruption{ErrorRate: 1.0, ErrorCode: http.StatusServiceUnavailable},
+			expectProxyError: true,
+		},
+		{
+			name:           "terminate",
+			tls:            TLSConfig{Mode: TLSModeTerminate, CertFile: certs.cert, KeyFile: certs.key},
+			disruption:     Disruption{AverageDelay: time.Millisecond},
+			expectedStatus: http.StatusTeapot,
+		},
+		{
+			name:           "terminate injecting errors",
+			tls:            TLSConfig{Mode: TLSModeTerminate, CertFile: certs.cert, KeyFile: certs.key},
+			disruption:     Disruption{ErrorRate: 1.0, ErrorCode: http.StatusServiceUnavailable},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "terminate verifying upstream",
+			tls: TLSConfig{
+				Mode:       TLSModeTerminate,
+				CertFile:   certs.cert,
+				KeyFile:    certs.key,
+				CAFile:     certs.ca,
+				ServerName: "test-app",
+			},
+			disruption:     Disruption{AverageDelay: time.Millisecond},
+			expectedStatus: http.StatusTeapot,
+		},
+		{
+			name:             "terminate without certificate",
+			tls:              TLSConfig{Mode: TLSModeTerminate},
+			expectProxyError: true,
+		},
+		{
+			name:           "mtls",
+			tls:            TLSConfig{Mode: TLSModeMTLS, CertFile: certs.cert, KeyFile: certs.key, CAFile: certs.ca},
+			disruption:     Disruption{AverageDelay: time.Millisecond},
+			upstreamMTLS:   true,
+			clientCert:     true,
+			expectedStatus: http.StatusTeapot,
+		},
+		{
+			name:              "mtls without client certificate",
+			tls:               TLSConfig{Mode: TLSModeMTLS, CertFile: certs.cert, KeyFile: certs.key, CAFile: certs.ca},
+			disruption:        Disruption{AverageDelay: time.Millisecond},
+			upstreamMTLS:      true,
+			expectClientError: true,
+		},
+		{
+			name:             "mtls without CA",
+			tls:              TLSConfig{Mode: TLSModeMTLS, CertFile: certs.cert, KeyFile: certs.key},
+			expectProxyError: true,
+		},
+		{
+			name:             "invalid mode",
+			tls:              TLSConfig{Mode: "other"},
+			expectProxyError: true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			upstreamServer := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+				rw.WriteHeader(http.StatusTeapot)
+			}))
+			upstreamServer.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+			if tc.upstreamMTLS {
+				upstreamServer.TLS.ClientCAs = pool
+				upstreamServer.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			upstreamServer.StartTLS()
+			t.Cleanup(upstreamServer.Close)
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("error starting test proxy listener: %v", err)
+			}
+			t.Cleanup(func() {
+				_ = listener.Close()
+			})
+
+			config := DefaultProxyConfig()
+			config.TLS = tc.tls
+
+			proxy, err := NewProxy(listener, upstreamServer.URL, tc.disruption, config)
+			if tc.expectProxyError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			go func() {
+				_ = proxy.Start()
+			}()
+			t.Cleanup(func() {
+				_ = proxy.Force()
+			})
+
+			clientConfig := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+			if tc.clientCert {
+				clientConfig.Certificates = []tls.Certificate{cert}
+			}
+			client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+
+			resp, err := client.Get("https://" + listener.Addr().String())
+			if tc.expectClientError {
+				if err == nil {
+					_ = resp.Body.Close()
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tc.expectedStatus {
+				t.Fatalf("expected status %d returned %d", tc.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+)
+
+// tunnel forwards the connections accepted by the listener to the upstream without parsing the requests.
+// It is used when the disruption does not affect any request, as copying the data between the connections avoids the
+// overhead of parsing and serializing the requests. It is also used for TLS traffic that is not terminated by the
+// proxy, delaying the connections instead of the requests.
+type tunnel struct {
+	listener net.Listener
+	upstream string
+	metrics  *protocol.MetricMap
+	mutex    sync.Mutex
+	conns    map[*tunnelConn]struct{}
+	closed   bool
+	// active tracks the connections being forwarded, for waiting for them when the tunnel is shut down
+	active sync.WaitGroup
+	// delay returns the delay applied before forwarding each connection. No delay is applied if not set.
+	delay func() time.Duration
+	// dialTimeout is the maximum time for establishing the connections to the upstream. Zero means no limit.
+	dialTimeout time.Duration
+	// maxHeadBytes enables following the HTTP/1.x messages forwarded in the connections, for counting the requests
+	// and closing the idle connections when the tunnel is shut down, and limits the size of their heads. Zero
+	// disables it, and the data is copied without being inspected.
+	maxHeadBytes int
+}
+
+// tunnelConn is a connection forwarded by the tunnel
+type tunnelConn struct {
+	client   net.Conn
+	upstream net.Conn
+	// framing follows the messages of the connection. Not set if the messages are not followed.
+	framing *httpFraming
+}
+
+// idle returns if the connection is known to have no request in progress
+func (c *tunnelConn) idle() bool {
+	return c.framing != nil && c.framing.idle()
+}
+
+func (c *tunnelConn) close() {
+	_ = c.client.Close()
+	_ = c.upstream.Close()
+}
+
+// tunnelDrainTimeout is the maximum time the tunnel waits for the forwarded connections to end when it is shut down.
+// Connections with requests in progress, or whose messages are not followed, would otherwise prevent the tunnel from
+// stopping if their clients keep them open.
+const tunnelDrainTimeout = 10 * time.Second
+
+// tunnelIdlePollInterval is the interval for closing the connections that become idle while the tunnel is shut down
+const tunnelIdlePollInterval = 50 * time.Millisecond
+
+// newTunnel returns a tunnel that forwards the connections to the upstream address (host:port)
+func newTunnel(listener net.Listener, upstream string, metrics *protocol.MetricMap) *tunnel {
+	return &tunnel{
+		listener: listener,
+		upstream: upstream,
+		metrics:  metrics,
+		conns:    map[*tunnelConn]struct{}{},
+	}
+}
+
+// serve accepts connections until the tunnel is closed
+func (t *tunnel) serve() error {
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			if t.isClosed() {
+				return nil
+			}
+			return err
+		}
+
+		t.metrics.Inc(protocol.MetricConnections)
+
+		go t.forward(conn)
+	}
+}
+
+// forward copies the data between the connection and a new connection to the upstream until both are closed
+func (t *tunnel) forward(conn net.Conn) {
+	defer conn.Close() //nolint:errcheck // nothing to do if closing fails
+
+	if t.delay != nil {
+		time.Sleep(t.delay())
+	}
+
+	upstream, err := net.DialTimeout("tcp", t.upstream, t.dialTimeout)
+	if err != nil {
+		t.metrics.Inc(protocol.MetricUpstreamFailures)
+		return
+	}
+	defer upstream.Close() //nolint:errcheck // nothing to do if closing fails
+
+	// the requests are followed before being forwarded and the responses after, so the connection is not
+	// considered idle while either is in transit
+	tc := &tunnelConn{client: conn, upstream: upstream}
+	var requests io.Reader = conn
+	var responses io.Writer = conn
+	if t.maxHeadBytes > 0 {
+		tc.framing = newHTTPFraming(t.metrics, t.maxHeadBytes)
+		requests = io.TeeReader(conn, scanWriter(tc.framing.scanRequests))
+		responses = io.MultiWriter(conn, scanWriter(tc.framing.scanResponses))
+	}
+
+	if !t.track(tc) {
+		return
+	}
+	defer t.untrack(tc)
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(upstream, requests)
+		closeWrite(upstream)
+		close(done)
+	}()
+
+	_, _ = io.Copy(responses, upstream)
+	closeWrite(conn)
+
+	<-done
+}
+
+// closeWrite signals the end of the data sent to the connection, if supported, keeping it open for reading
+func closeWrite(conn net.Conn) {
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		_ = tcpConn.CloseWrite()
+	}
+}
+
+// track registers the connection for closing it when the tunnel is closed. Returns false if the tunnel is closed.
+func (t *tunnel) track(conn *tunnelConn) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.closed {
+		return false
+	}
+
+	t.conns[conn] = struct{}{}
+	t.active.Add(1)
+
+	return true
+}
+
+func (t *tunnel) untrack(conn *tunnelConn) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.conns, conn)
+	t.active.Done()
+}
+
+func (t *tunnel) isClosed() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.closed
+}
+
+// shutdown stops accepting connections, closes the idle connections and waits for the other forwarded connections
+// to end, closing them as they become idle, up to tunnelDrainTimeout. The connections that do not end in time are
+// closed.
+func (t *tunnel) shutdown() error {
+	err := t.stopAccepting()
+
+	drained := make(chan struct{})
+	go func() {
+		t.active.Wait()
+		close(drained)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), tunnelDrainTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(tunnelIdlePollInterval)
+	defer ticker.Stop()
+
+	for {
+		t.closeIdleConns()
+
+		select {
+		case <-drained:
+			return err
+		case <-ctx.Done():
+			t.closeConns()
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+// close stops accepting connections and closes the forwarded connections without waiting for them to end
+func (t *tunnel) close() error {
+	err := t.stopAccepting()
+	t.closeConns()
+
+	return err
+}
+
+// stopAccepting closes the listener. The connections accepted afterwards are not forwarded.
+func (t *tunnel) stopAccepting() error {
+	t.mutex.Lock()
+	t.closed = true
+	t.mutex.Unlock()
+
+	err := t.listener.Close()
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+
+	return err
+}
+
+func (t *tunnel) closeConns() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for conn := range t.conns {
+		conn.close()
+	}
+}
+
+// closeIdleConns closes the connections that have no request in progress
+func (t *tunnel) closeIdleConns() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for conn := range t.conns {
+		if conn.idle() {
+			conn.close()
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/agent/protocol"
+)
+
+func Test_PassthroughProxy(t *testing.T) {
+	t.Parallel()
+
+	upstreamServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+		_, _ = rw.Write([]byte("upstream"))
+	}))
+	t.Cleanup(upstreamServer.Close)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting test proxy listener: %v", err)
+	}
+
+	// the disruption does not affect any request
+	proxy, err := NewProxy(listener, upstreamServer.URL, Disruption{Excluded: []string{"/health"}}, DefaultProxyConfig())
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	go func() {
+		_ = proxy.Start()
+	}()
+	t.Cleanup(func() {
+		_ = proxy.Stop()
+	})
+
+	client := &http.Client{Transport: &http.Transport{}}
+	for range 3 {
+		resp, getErr := client.Get("http://" + listener.Addr().String())
+		if getErr != nil {
+			t.Fatalf("failed: %v", getErr)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusTeapot || string(body) != "upstream" {
+			t.Fatalf("expected upstream response returned %d %q", resp.StatusCode, string(body))
+		}
+	}
+
+	// requests are forwarded in the same connection, which is not processed by the proxy
+	expected := map[string]uint{
+		protocol.MetricConnections:      1,
+		protocol.MetricRequests:         3,
+		protocol.MetricUpstreamFailures: 0,
+	}
+	if diff := cmp.Diff(expected, proxy.Metrics()); diff != "" {
+		t.Fatalf("expected metrics do not match returned:\n%s", diff)
+	}
+}
+
+func Test_PassthroughProxyStopWaitsForConnections(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan struct{})
+	upstreamServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		close(received)
+		time.Sleep(200 * time.Millisecond)
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	t.Cleanup(upstreamServer.Close)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting test proxy listener: %v", err)
+	}
+
+	proxy, err := NewProxy(listener, upstreamServer.URL, Disruption{}, DefaultProxyConfig())
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	go func() {
+		_ = proxy.Start()
+	}()
+
+	responses := make(chan int, 1)
+	go func() {
+		client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+		resp, getErr := client.Get("http://" + listener.Addr().String())
+		if getErr != nil {
+			responses <- 0
+			return
+		}
+		_ = resp.Body.Close()
+		responses <- resp.StatusCode
+	}()
+
+	<-received
+	if err = proxy.Stop(); err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	// the connection of the request in progress is not closed when the proxy stops
+	select {
+	case status := <-responses:
+		if status != http.StatusTeapot {
+			t.Fatalf("expected upstream response returned %d", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("request did not complete")
+	}
+}
+
+func Test_PassthroughProxyStopClosesIdleConnections(t *testing.T) {
+	t.Parallel()
+
+	upstreamServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	t.Cleanup(upstreamServer.Close)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error starting test proxy listener: %v", err)
+	}
+
+	proxy, err := NewProxy(listener, upstreamServer.URL, Disruption{}, DefaultProxyConfig())
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	go func() {
+		_ = proxy.Start()
+	}()
+
+	// the client keeps the connection open after the request
+	client := &http.Client{Transport: &http.Transport{}}
+	resp, err := client.Get("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- proxy.Stop()
+	}()
+
+	select {
+	case err = <-stopped:
+		if err != nil {
+			t.Fatalf("failed: %v", err)
+		}
+	case <-time.After(tunnelDrainTimeout / 2):
+		t.Fatalf("idle connection was not closed")
+	}
+}
@@ -0,0 +1,161 @@
+package protocol
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/xk6-disruptor/pkg/nftables"
+)
+
+// NftablesRedirector is an implementation of TrafficRedirector that uses nftables rules, for nodes that do not
+// support iptables. It applies the same rules as Redirector, in chains of the agent's table that are specific to
+// the destination port, so multiple redirections can be applied simultaneously.
+type NftablesRedirector struct {
+	*TrafficRedirectionSpec
+	nftables nftables.Nftables
+}
+
+// NewNftablesTrafficRedirector creates instances of an nftables traffic redirector
+func NewNftablesTrafficRedirector(
+	tr *TrafficRedirectionSpec,
+	nftables nftables.Nftables,
+) (*NftablesRedirector, error) {
+	err := tr.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &NftablesRedirector{
+		TrafficRedirectionSpec: tr,
+		nftables:               nftables,
+	}, nil
+}
+
+// chains returns the chains that hold the rules of the redirection.
+// The nat chain of the output hook is evaluated before the chains of other components, such as the sidecar of a
+// service mesh, while the one of the prerouting hook is evaluated after them. This matches the order of the rules
+// that Redirector inserts and appends.
+func (tr *NftablesRedirector) chains() []nftables.Chain {
+	return []nftables.Chain{
+		{
+			Name:     fmt.Sprintf("output-%d", tr.DestinationPort),
+			Type:     "nat",
+			Hook:     "output",
+			Priority: "dstnat - 1",
+		},
+		{
+			Name:     fmt.Sprintf("prerouting-%d", tr.DestinationPort),
+			Type:     "nat",
+			Hook:     "prerouting",
+			Priority: "dstnat + 1",
+		},
+		{
+			Name:     fmt.Sprintf("input-%d", tr.DestinationPort),
+			Type:     "filter",
+			Hook:     "input",
+			Priority: "filter",
+		},
+	}
+}
+
+// rules returns the nftables rules that cause traffic to be forwarded according to the spec.
+// See Redirector.rules for the purpose of each rule.
+func (tr *NftablesRedirector) rules() []nftables.Rule {
+	chains := tr.chains()
+	output, prerouting, input := chains[0].Name, chains[1].Name, chains[2].Name
+
+	redirect := fmt.Sprintf("tcp dport %d redirect to :%d", tr.DestinationPort, tr.RedirectPort)
+	reset := fmt.Sprintf("tcp dport %d ct state established reject with tcp reset", tr.DestinationPort)
+
+	rules := []nftables.Rule{
+		{Chain: output, Args: "ip saddr 127.0.0.0/8 ip daddr 127.0.0.1 " + redirect},
+		{Chain: prerouting, Args: "iifname != lo " + tr.destination() + redirect},
+		{Chain: input, Args: "iifname lo ip saddr 127.0.0.0/8 ip daddr 127.0.0.1 " + reset},
+		{Chain: input, Args: "iifname != lo " + tr.destination() + reset},
+	}
+
+	// traffic forwarded by the Istio sidecar to the application. See Redirector.istioRules
+	if tr.IstioSidecar {
+		rules = append(
+			rules,
+			nftables.Rule{Chain: output, Args: fmt.Sprintf("oifname lo ip saddr %s %s", istioInboundSource, redirect)},
+			nftables.Rule{Chain: input, Args: fmt.Sprintf("iifname lo ip saddr %s %s", istioInboundSource, reset)},
+		)
+	}
+
+	return rules
+}
+
+// destination returns the nftables statement that restricts a rule to the DestinationAddress, if any
+func (tr *NftablesRedirector) destination() string {
+	if tr.DestinationAddress == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("ip daddr %s ", tr.DestinationAddress)
+}
+
+// resetProxyChain returns the chain that rejects the traffic to the proxy.
+// It is set up after injection finishes to kill any leftover connection to the proxy.
+func (tr *NftablesRedirector) resetProxyChain() (nftables.Chain, nftables.Rule) {
+	chain := nftables.Chain{
+		Name:     fmt.Sprintf("proxy-%d", tr.RedirectPort),
+		Type:     "filter",
+		Hook:     "input",
+		Priority: "filter",
+	}
+
+	rule := nftables.Rule{
+		Chain: chain.Name,
+		Args:  fmt.Sprintf("tcp dport %d reject with tcp reset", tr.RedirectPort),
+	}
+
+	return chain, rule
+}
+
+// Start applies the TrafficRedirect
+func (tr *NftablesRedirector) Start() error {
+	// Remove reset chain for the proxy in case it exists from a previous run.
+	resetChain, _ := tr.resetProxyChain()
+	_ = tr.nftables.RemoveChain(resetChain.Name)
+
+	for _, chain := range tr.chains() {
+		err := tr.nftables.AddChain(chain)
+		if err != nil {
+			return fmt.Errorf("adding chains: %w", err)
+		}
+	}
+
+	for _, rule := range tr.rules() {
+		err := tr.nftables.Add(rule)
+		if err != nil {
+			return fmt.Errorf("adding rules: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the TrafficRedirect.
+// Stop will continue attempting to remove all the chains it created even if removing one fails.
+func (tr *NftablesRedirector) Stop() error {
+	var errs []error
+
+	for _, chain := range tr.chains() {
+		err := tr.nftables.RemoveChain(chain.Name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	resetChain, resetRule := tr.resetProxyChain()
+	err := tr.nftables.AddChain(resetChain)
+	if err == nil {
+		err = tr.nftables.Add(resetRule)
+	}
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
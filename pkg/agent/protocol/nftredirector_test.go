@@ -0,0 +1,144 @@
+package protocol
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/nftables"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+//nolint:lll
+func Test_NftablesCommands(t *testing.T) {
+	t.Parallel()
+
+	TestCases := []struct {
+		title        string
+		redirect     TrafficRedirectionSpec
+		expectedCmds []string
+		expectError  bool
+		fakeError    error
+		testFunction func(TrafficRedirector) error
+	}{
+		{
+			title: "Start valid redirect",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort: 80,
+				RedirectPort:    8080,
+			},
+			testFunction: func(tr TrafficRedirector) error {
+				return tr.Start()
+			},
+			expectedCmds: []string{
+				"nft flush chain ip xk6-disruptor proxy-8080",
+				"nft delete chain ip xk6-disruptor proxy-8080",
+				"nft add table ip xk6-disruptor",
+				"nft add chain ip xk6-disruptor output-80 { type nat hook output priority dstnat - 1 ; }",
+				"nft add table ip xk6-disruptor",
+				"nft add chain ip xk6-disruptor prerouting-80 { type nat hook prerouting priority dstnat + 1 ; }",
+				"nft add table ip xk6-disruptor",
+				"nft add chain ip xk6-disruptor input-80 { type filter hook input priority filter ; }",
+				"nft add rule ip xk6-disruptor output-80 ip saddr 127.0.0.0/8 ip daddr 127.0.0.1 tcp dport 80 redirect to :8080",
+				"nft add rule ip xk6-disruptor prerouting-80 iifname != lo tcp dport 80 redirect to :8080",
+				"nft add rule ip xk6-disruptor input-80 iifname lo ip saddr 127.0.0.0/8 ip daddr 127.0.0.1 tcp dport 80 ct state established reject with tcp reset",
+				"nft add rule ip xk6-disruptor input-80 iifname != lo tcp dport 80 ct state established reject with tcp reset",
+			},
+			expectError: false,
+		},
+		{
+			title: "Start redirect to destination address with Istio sidecar",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort:    80,
+				RedirectPort:       8080,
+				DestinationAddress: "192.0.2.6",
+				IstioSidecar:       true,
+			},
+			testFunction: func(tr TrafficRedirector) error {
+				return tr.Start()
+			},
+			expectedCmds: []string{
+				"nft flush chain ip xk6-disruptor proxy-8080",
+				"nft delete chain ip xk6-disruptor proxy-8080",
+				"nft add table ip xk6-disruptor",
+				"nft add chain ip xk6-disruptor output-80 { type nat hook output priority dstnat - 1 ; }",
+				"nft add table ip xk6-disruptor",
+				"nft add chain ip xk6-disruptor prerouting-80 { type nat hook prerouting priority dstnat + 1 ; }",
+				"nft add table ip xk6-disruptor",
+				"nft add chain ip xk6-disruptor input-80 { type filter hook input priority filter ; }",
+				"nft add rule ip xk6-disruptor output-80 ip saddr 127.0.0.0/8 ip daddr 127.0.0.1 tcp dport 80 redirect to :8080",
+				"nft add rule ip xk6-disruptor prerouting-80 iifname != lo ip daddr 192.0.2.6 tcp dport 80 redirect to :8080",
+				"nft add rule ip xk6-disruptor input-80 iifname lo ip saddr 127.0.0.0/8 ip daddr 127.0.0.1 tcp dport 80 ct state established reject with tcp reset",
+				"nft add rule ip xk6-disruptor input-80 iifname != lo ip daddr 192.0.2.6 tcp dport 80 ct state established reject with tcp reset",
+				"nft add rule ip xk6-disruptor output-80 oifname lo ip saddr 127.0.0.6/32 tcp dport 80 redirect to :8080",
+				"nft add rule ip xk6-disruptor input-80 iifname lo ip saddr 127.0.0.6/32 tcp dport 80 ct state established reject with tcp reset",
+			},
+			expectError: false,
+		},
+		{
+			title: "Stop active redirect",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort: 80,
+				RedirectPort:    8080,
+			},
+			testFunction: func(tr TrafficRedirector) error {
+				return tr.Stop()
+			},
+			expectedCmds: []string{
+				"nft flush chain ip xk6-disruptor output-80",
+				"nft delete chain ip xk6-disruptor output-80",
+				"nft flush chain ip xk6-disruptor prerouting-80",
+				"nft delete chain ip xk6-disruptor prerouting-80",
+				"nft flush chain ip xk6-disruptor input-80",
+				"nft delete chain ip xk6-disruptor input-80",
+				"nft add table ip xk6-disruptor",
+				"nft add chain ip xk6-disruptor proxy-8080 { type filter hook input priority filter ; }",
+				"nft add rule ip xk6-disruptor proxy-8080 tcp dport 8080 reject with tcp reset",
+			},
+			expectError: false,
+		},
+		{
+			title: "Error invoking nft command in Start",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort: 80,
+				RedirectPort:    8080,
+			},
+			testFunction: func(tr TrafficRedirector) error {
+				return tr.Start()
+			},
+			expectError: true,
+			fakeError:   fmt.Errorf("process exited with return code 1"),
+		},
+	}
+
+	for _, tc := range TestCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, tc.fakeError)
+			redirector, err := NewNftablesTrafficRedirector(&tc.redirect, nftables.New(executor))
+			if err != nil {
+				t.Fatalf("failed creating traffic redirector with error %v", err)
+			}
+
+			err = tc.testFunction(redirector)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed with error: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expectedCmds, executor.CmdHistory()); diff != "" {
+				t.Fatalf("Actual commands differ from expected:\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,6 +1,6 @@
 // Package protocol implements the agent that injects disruptors in protocols.
 // The protocol disruptors run as a proxy. The agent redirects the traffic
-// to the proxy using iptables.
+// to the proxy using iptables or nftables.
 package protocol
 
 import (
@@ -43,6 +43,13 @@ const (
 	MetricRequestsExcluded = "requests_excluded"
 	// MetricRequestsDisrupted is the total number requests that the proxy altered in any way.
 	MetricRequestsDisrupted = "requests_disrupted"
+	// MetricConnections is the total number of connections received by the proxy. Reported by proxies that forward
+	// the connections without processing the requests, which cannot count the requests of TLS traffic.
+	MetricConnections = "connections_total"
+	// MetricUpstreamFailures is the total number of requests or connections the proxy failed to forward to the
+	// upstream, either because it could not be reached or because it timed out. These failures are not injected by
+	// the disruption.
+	MetricUpstreamFailures = "upstream_failures"
 )
 
 // disruptor is an instance of a Disruptor that applies a disruption
@@ -103,7 +110,12 @@ func (d *disruptor) Apply(ctx context.Context, duration time.Duration) error {
 				return fmt.Errorf(" proxy ended with error: %w", err)
 			}
 		case <-time.After(duration):
-			requests, hasMetric := d.proxy.Metrics()[MetricRequests]
+			metrics := d.proxy.Metrics()
+			requests, hasMetric := metrics[MetricRequests]
+			if !hasMetric {
+				// proxies that cannot count the requests only report the connections that carry them
+				requests, hasMetric = metrics[MetricConnections]
+			}
 			if hasMetric && requests == 0 {
 				return ErrNoRequests
 			}
@@ -130,3 +142,8 @@ func (n *noop) Start() error {
 func (n *noop) Stop() error {
 	return nil
 }
+
+// Metrics returns the metrics of the proxy
+func (d *disruptor) Metrics() map[string]uint {
+	return d.proxy.Metrics()
+}
@@ -0,0 +1,36 @@
+package protocol
+
+import "time"
+
+// Ramp linearly increases the severity of a disruption from zero to its full value over a period of time
+type Ramp struct {
+	duration time.Duration
+	start    time.Time
+}
+
+// NewRamp returns a Ramp that starts at the current time and lasts the given duration.
+// A zero duration applies the full severity from the start.
+func NewRamp(duration time.Duration) Ramp {
+	return Ramp{
+		duration: duration,
+		start:    time.Now(),
+	}
+}
+
+// Severity returns the fraction (in the range 0.0 to 1.0) of the full severity of the disruption at the given time
+func (r Ramp) Severity(now time.Time) float64 {
+	elapsed := now.Sub(r.start)
+	switch {
+	case r.duration <= 0 || elapsed >= r.duration:
+		return 1.0
+	case elapsed <= 0:
+		return 0.0
+	default:
+		return float64(elapsed) / float64(r.duration)
+	}
+}
+
+// ScaleDuration returns the fraction of the duration for the given severity
+func ScaleDuration(d time.Duration, severity float64) time.Duration {
+	return time.Duration(float64(d) * severity)
+}
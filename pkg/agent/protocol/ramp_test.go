@@ -0,0 +1,58 @@
+package protocol
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_RampSeverity(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+
+	testCases := []struct {
+		title    string
+		duration time.Duration
+		elapsed  time.Duration
+		expected float64
+	}{
+		{
+			title:    "no ramp",
+			duration: 0,
+			elapsed:  0,
+			expected: 1.0,
+		},
+		{
+			title:    "ramp start",
+			duration: 10 * time.Second,
+			elapsed:  0,
+			expected: 0.0,
+		},
+		{
+			title:    "ramp middle",
+			duration: 10 * time.Second,
+			elapsed:  5 * time.Second,
+			expected: 0.5,
+		},
+		{
+			title:    "ramp completed",
+			duration: 10 * time.Second,
+			elapsed:  20 * time.Second,
+			expected: 1.0,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			ramp := Ramp{duration: tc.duration, start: start}
+			severity := ramp.Severity(start.Add(tc.elapsed))
+			if severity != tc.expected {
+				t.Fatalf("expected severity %f got %f", tc.expected, severity)
+			}
+		})
+	}
+}
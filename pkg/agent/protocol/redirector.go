@@ -2,8 +2,10 @@ package protocol
 
 import (
 	"fmt"
+	"net"
 
 	"github.com/grafana/xk6-disruptor/pkg/iptables"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
 )
 
 // TrafficRedirectionSpec specifies the redirection of traffic to a destination
@@ -13,31 +15,64 @@ type TrafficRedirectionSpec struct {
 	// RedirectPort is the port where the traffic should be redirected to.
 	// Typically, this would be where a transparent proxy is listening.
 	RedirectPort uint
+	// DestinationAddress restricts the redirection of external traffic to the traffic directed to this address.
+	// Required when the agent shares the network namespace of the node (hostNetwork), as otherwise the traffic
+	// forwarded by the node to other pods listening on the DestinationPort would also be redirected.
+	DestinationAddress string
+	// IstioSidecar indicates the target has an Istio sidecar. The traffic to the target is intercepted by the
+	// sidecar, which then forwards it to the application.
+	IstioSidecar bool
 }
 
+// istioInboundSource is the address the Istio sidecar uses as source when forwarding inbound traffic to the
+// application
+const istioInboundSource = "127.0.0.6/32"
+
 // Redirector is an implementation of TrafficRedirector that uses iptables rules.
 type Redirector struct {
 	*TrafficRedirectionSpec
 	iptables iptables.Iptables
 }
 
-// NewTrafficRedirector creates instances of an iptables traffic redirector
-func NewTrafficRedirector(
-	tr *TrafficRedirectionSpec,
-	iptables iptables.Iptables,
-) (*Redirector, error) {
+// validate returns an error if the spec is not valid
+func (tr *TrafficRedirectionSpec) validate() error {
 	if tr.DestinationPort == 0 || tr.RedirectPort == 0 {
-		return nil, fmt.Errorf("DestinationPort and RedirectPort must be specified")
+		return fmt.Errorf("DestinationPort and RedirectPort must be specified")
 	}
 
 	if tr.DestinationPort == tr.RedirectPort {
-		return nil, fmt.Errorf(
+		return fmt.Errorf(
 			"DestinationPort (%d) and RedirectPort (%d) must be different",
 			tr.DestinationPort,
 			tr.RedirectPort,
 		)
 	}
 
+	if tr.IstioSidecar {
+		for _, port := range []uint{tr.DestinationPort, tr.RedirectPort} {
+			if utils.IsIstioPort(port) {
+				return fmt.Errorf("port %d is used by the Istio sidecar and cannot be redirected", port)
+			}
+		}
+	}
+
+	if tr.DestinationAddress != "" && net.ParseIP(tr.DestinationAddress).To4() == nil {
+		return fmt.Errorf("DestinationAddress %q must be an IPv4 address", tr.DestinationAddress)
+	}
+
+	return nil
+}
+
+// NewTrafficRedirector creates instances of an iptables traffic redirector
+func NewTrafficRedirector(
+	tr *TrafficRedirectionSpec,
+	iptables iptables.Iptables,
+) (*Redirector, error) {
+	err := tr.validate()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Redirector{
 		TrafficRedirectionSpec: tr,
 		iptables:               iptables,
@@ -65,6 +100,12 @@ func NewTrafficRedirector(
 // +-----------+---------------+------------------------+
 // | lo        | ! 127.0.0.0/8 | Proxy traffic          |
 // +-----------+---------------+------------------------+
+//
+// If the spec has a DestinationAddress, the rules for outside traffic only match traffic directed to this address.
+//
+// If the target has an Istio sidecar, outside traffic is redirected by the sidecar to itself before it reaches the
+// rules above, and is then forwarded by the sidecar to the application. See istioRules for the rules that handle this
+// traffic.
 func (tr *Redirector) rules() []iptables.Rule {
 	// redirectLocalRule is a netfilter rule that intercepts locally-originated traffic, such as that coming from sidecars
 	// or `kubectl port-forward, directed to the application and redirects it to the proxy.
@@ -88,6 +129,7 @@ func (tr *Redirector) rules() []iptables.Rule {
 		Table: "nat",
 		Chain: "PREROUTING", // For remote traffic
 		Args: "! -i lo " + // Not coming form loopback. Technically not needed, but doesn't hurt and helps readability.
+			tr.destination() + // Directed to the target address, if restricted
 			fmt.Sprintf("-p tcp --dport %d ", tr.DestinationPort) + // Sent to the upstream application's port
 			fmt.Sprintf("-j REDIRECT --to-port %d", tr.RedirectPort), // Forward it to the proxy address
 	}
@@ -116,17 +158,68 @@ func (tr *Redirector) rules() []iptables.Rule {
 		Chain: "INPUT", // For traffic traversing the INPUT chain
 		Args: "! -i lo " + // Not coming form loopback. This is technically not needed as loopback traffic does not
 			// traverse INPUT, but helps with explicitness.
+			tr.destination() + // Directed to the target address, if restricted
 			fmt.Sprintf("-p tcp --dport %d ", tr.DestinationPort) + // Directed to the upstream application's port
 			"-m state --state ESTABLISHED " + // That are already ESTABLISHED, i.e. not before they are redirected
 			"-j REJECT --reject-with tcp-reset", // Reject it
 	}
 
-	return []iptables.Rule{
+	rules := []iptables.Rule{
 		redirectLocalRule,
 		redirectExternalRule,
 		resetLocalRule,
 		resetExternalRule,
 	}
+
+	if tr.IstioSidecar {
+		rules = append(rules, tr.istioRules()...)
+	}
+
+	return rules
+}
+
+// istioRules returns the iptables rules that redirect the traffic forwarded by the Istio sidecar to the application.
+// The sidecar forwards the traffic to the pod IP using 127.0.0.6 as source, which distinguishes it from the traffic of
+// the proxy, that uses the pod IP as source.
+func (tr *Redirector) istioRules() []iptables.Rule {
+	// redirectSidecarRule intercepts the traffic forwarded by the sidecar and redirects it to the proxy.
+	// It is inserted at the beginning of the OUTPUT chain because Istio's rules in this chain return the traffic
+	// through the loopback interface before it reaches the rules appended at the end of the chain.
+	redirectSidecarRule := iptables.Rule{
+		Table: "nat",
+		Chain: "OUTPUT",
+		Args: "-o lo " + // Traffic forwarded by the sidecar to the application flows through the loopback interface
+			fmt.Sprintf("-s %s ", istioInboundSource) + // Coming from the sidecar
+			fmt.Sprintf("-p tcp --dport %d ", tr.DestinationPort) + // Sent to the upstream application's port
+			fmt.Sprintf("-j REDIRECT --to-port %d", tr.RedirectPort), // Forward it to the proxy address
+		Insert: true,
+	}
+
+	// resetSidecarRule resets the connections established by the sidecar to the application before the redirection,
+	// as the sidecar keeps them open for reusing them.
+	resetSidecarRule := iptables.Rule{
+		Table: "filter",
+		Chain: "INPUT",
+		Args: "-i lo " + // On the loopback interface
+			fmt.Sprintf("-s %s ", istioInboundSource) + // Coming from the sidecar
+			fmt.Sprintf("-p tcp --dport %d ", tr.DestinationPort) + // Directed to the upstream application's port
+			"-m state --state ESTABLISHED " + // That are already ESTABLISHED, i.e. not before they are redirected
+			"-j REJECT --reject-with tcp-reset", // Reject it
+	}
+
+	return []iptables.Rule{
+		redirectSidecarRule,
+		resetSidecarRule,
+	}
+}
+
+// destination returns the iptables arguments that restrict a rule to the DestinationAddress, if any
+func (tr *Redirector) destination() string {
+	if tr.DestinationAddress == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("-d %s/32 ", tr.DestinationAddress)
 }
 
 // proxyResetRule returns a netfilter rule that rejects traffic to the proxy.
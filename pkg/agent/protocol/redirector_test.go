@@ -38,6 +38,51 @@ func Test_validateTrafficRedirect(t *testing.T) {
 			redirect:    TrafficRedirectionSpec{},
 			expectError: true,
 		},
+		{
+			title: "Valid destination address",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort:    80,
+				RedirectPort:       8080,
+				DestinationAddress: "192.0.2.6",
+			},
+			expectError: false,
+		},
+		{
+			title: "Istio sidecar",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort: 80,
+				RedirectPort:    8080,
+				IstioSidecar:    true,
+			},
+			expectError: false,
+		},
+		{
+			title: "Istio inbound port",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort: 15006,
+				RedirectPort:    8080,
+				IstioSidecar:    true,
+			},
+			expectError: true,
+		},
+		{
+			title: "Proxy port used by Istio",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort: 80,
+				RedirectPort:    15001,
+				IstioSidecar:    true,
+			},
+			expectError: true,
+		},
+		{
+			title: "Invalid destination address",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort:    80,
+				RedirectPort:       8080,
+				DestinationAddress: "node.local",
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range TestCases {
@@ -85,11 +130,57 @@ func Test_Commands(t *testing.T) {
 			},
 			//nolint:lll
 			expectedCmds: []string{
-				"iptables -t filter -D INPUT -p tcp --dport 8080 -j REJECT --reject-with tcp-reset",
-				"iptables -t nat -A OUTPUT -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -j REDIRECT --to-port 8080",
-				"iptables -t nat -A PREROUTING ! -i lo -p tcp --dport 80 -j REDIRECT --to-port 8080",
-				"iptables -t filter -A INPUT -i lo -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
-				"iptables -t filter -A INPUT ! -i lo -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+				"iptables -t filter -D INPUT -m comment --comment xk6-disruptor -p tcp --dport 8080 -j REJECT --reject-with tcp-reset",
+				"iptables -t nat -A OUTPUT -m comment --comment xk6-disruptor -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -j REDIRECT --to-port 8080",
+				"iptables -t nat -A PREROUTING -m comment --comment xk6-disruptor ! -i lo -p tcp --dport 80 -j REDIRECT --to-port 8080",
+				"iptables -t filter -A INPUT -m comment --comment xk6-disruptor -i lo -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+				"iptables -t filter -A INPUT -m comment --comment xk6-disruptor ! -i lo -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+			},
+			expectError: false,
+			fakeError:   nil,
+			fakeOutput:  []byte{},
+		},
+		{
+			title: "Start redirect restricted to destination address",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort:    80,
+				RedirectPort:       8080,
+				DestinationAddress: "192.0.2.6",
+			},
+			testFunction: func(tr TrafficRedirector) error {
+				return tr.Start()
+			},
+			//nolint:lll
+			expectedCmds: []string{
+				"iptables -t filter -D INPUT -m comment --comment xk6-disruptor -p tcp --dport 8080 -j REJECT --reject-with tcp-reset",
+				"iptables -t nat -A OUTPUT -m comment --comment xk6-disruptor -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -j REDIRECT --to-port 8080",
+				"iptables -t nat -A PREROUTING -m comment --comment xk6-disruptor ! -i lo -d 192.0.2.6/32 -p tcp --dport 80 -j REDIRECT --to-port 8080",
+				"iptables -t filter -A INPUT -m comment --comment xk6-disruptor -i lo -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+				"iptables -t filter -A INPUT -m comment --comment xk6-disruptor ! -i lo -d 192.0.2.6/32 -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+			},
+			expectError: false,
+			fakeError:   nil,
+			fakeOutput:  []byte{},
+		},
+		{
+			title: "Start redirect with Istio sidecar",
+			redirect: TrafficRedirectionSpec{
+				DestinationPort: 80,
+				RedirectPort:    8080,
+				IstioSidecar:    true,
+			},
+			testFunction: func(tr TrafficRedirector) error {
+				return tr.Start()
+			},
+			//nolint:lll
+			expectedCmds: []string{
+				"iptables -t filter -D INPUT -m comment --comment xk6-disruptor -p tcp --dport 8080 -j REJECT --reject-with tcp-reset",
+				"iptables -t nat -A OUTPUT -m comment --comment xk6-disruptor -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -j REDIRECT --to-port 8080",
+				"iptables -t nat -A PREROUTING -m comment --comment xk6-disruptor ! -i lo -p tcp --dport 80 -j REDIRECT --to-port 8080",
+				"iptables -t filter -A INPUT -m comment --comment xk6-disruptor -i lo -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+				"iptables -t filter -A INPUT -m comment --comment xk6-disruptor ! -i lo -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+				"iptables -t nat -I OUTPUT -m comment --comment xk6-disruptor -o lo -s 127.0.0.6/32 -p tcp --dport 80 -j REDIRECT --to-port 8080",
+				"iptables -t filter -A INPUT -m comment --comment xk6-disruptor -i lo -s 127.0.0.6/32 -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
 			},
 			expectError: false,
 			fakeError:   nil,
@@ -106,11 +197,11 @@ func Test_Commands(t *testing.T) {
 			},
 			//nolint:lll
 			expectedCmds: []string{
-				"iptables -t nat -D OUTPUT -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -j REDIRECT --to-port 8080",
-				"iptables -t nat -D PREROUTING ! -i lo -p tcp --dport 80 -j REDIRECT --to-port 8080",
-				"iptables -t filter -D INPUT -i lo -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
-				"iptables -t filter -D INPUT ! -i lo -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
-				"iptables -t filter -A INPUT -p tcp --dport 8080 -j REJECT --reject-with tcp-reset",
+				"iptables -t nat -D OUTPUT -m comment --comment xk6-disruptor -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -j REDIRECT --to-port 8080",
+				"iptables -t nat -D PREROUTING -m comment --comment xk6-disruptor ! -i lo -p tcp --dport 80 -j REDIRECT --to-port 8080",
+				"iptables -t filter -D INPUT -m comment --comment xk6-disruptor -i lo -s 127.0.0.0/8 -d 127.0.0.1/32 -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+				"iptables -t filter -D INPUT -m comment --comment xk6-disruptor ! -i lo -p tcp --dport 80 -m state --state ESTABLISHED -j REJECT --reject-with tcp-reset",
+				"iptables -t filter -A INPUT -m comment --comment xk6-disruptor -p tcp --dport 8080 -j REJECT --reject-with tcp-reset",
 			},
 			expectError: false,
 			fakeError:   nil,
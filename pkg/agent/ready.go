@@ -0,0 +1,58 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultReadyFile returns the default path of the file the agent uses for reporting it is ready for
+// applying disruptions
+func DefaultReadyFile() string {
+	return runtimeFile("ready")
+}
+
+// MarkReady reports the agent is ready for applying disruptions by creating the given file. The file contains the
+// PID of the process that reports the agent is ready.
+func MarkReady(path string) error {
+	err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0o600)
+	if err != nil {
+		return fmt.Errorf("writing ready file: %w", err)
+	}
+
+	return nil
+}
+
+// IsReady returns if the agent reported it is ready for applying disruptions in the given file
+func IsReady(path string) (bool, error) {
+	_, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("checking ready file: %w", err)
+	}
+
+	return true, nil
+}
+
+// ReadyOwner returns the PID of the process that reported the agent is ready in the given file, or -1 if the agent
+// is not ready
+func ReadyOwner(path string) (int, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return -1, nil
+	}
+	if err != nil {
+		return -1, fmt.Errorf("reading ready file: %w", err)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	if err != nil {
+		return -1, fmt.Errorf("invalid PID in ready file: %w", err)
+	}
+
+	return pid, nil
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// State defines the state of the disruption applied by the agent
+type State string
+
+const (
+	// StatePending indicates the agent has not applied any disruption yet
+	StatePending State = "pending"
+	// StateActive indicates the disruption is being applied
+	StateActive State = "active"
+	// StateFinished indicates the disruption completed or was stopped
+	StateFinished State = "finished"
+	// StateFailed indicates the disruption terminated with an error
+	StateFailed State = "failed"
+)
+
+// Status describes the state of the last disruption applied by the agent
+type Status struct {
+	State     State         `json:"state"`
+	StartedAt time.Time     `json:"startedAt,omitempty"`
+	Duration  time.Duration `json:"duration,omitempty"`
+	Remaining time.Duration `json:"remaining,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Reapplied int           `json:"reapplied,omitempty"`
+	// Metrics reported by the disruption, if it supports them
+	Metrics map[string]uint `json:"metrics,omitempty"`
+	// Proxy used by the disruption, if any
+	Proxy *ProxyStatus `json:"proxy,omitempty"`
+}
+
+// DefaultStatusFile returns the default path of the file the agent uses for reporting its status
+func DefaultStatusFile() string {
+	return runtimeFile("status")
+}
+
+// runtimeFile returns the path of a file with the given extension in the runtime directory of the agent
+func runtimeFile(extension string) string {
+	name := filepath.Base(os.Args[0])
+
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = os.TempDir()
+	}
+
+	return filepath.Join(runtimeDir, name+"."+extension)
+}
+
+// WriteStatus stores the status in the given file
+func WriteStatus(path string, status Status) error {
+	content, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("encoding status: %w", err)
+	}
+
+	// write to a temporary file and rename it to prevent readers from getting a partial status
+	tempFile := fmt.Sprintf("%s.%d", path, os.Getpid())
+	err = os.WriteFile(tempFile, content, 0o600)
+	if err != nil {
+		return fmt.Errorf("writing status: %w", err)
+	}
+
+	return os.Rename(tempFile, path)
+}
+
+// ReadStatus returns the status stored in the given file. If the file does not exist, the status is pending.
+// The remaining duration of an active disruption is calculated at the moment of reading the status.
+func ReadStatus(path string) (Status, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Status{State: StatePending}, nil
+	}
+	if err != nil {
+		return Status{}, fmt.Errorf("reading status: %w", err)
+	}
+
+	status := Status{}
+	err = json.Unmarshal(content, &status)
+	if err != nil {
+		return Status{}, fmt.Errorf("decoding status: %w", err)
+	}
+
+	if status.State == StateActive {
+		status.Remaining = max(time.Until(status.StartedAt.Add(status.Duration)).Round(time.Second), 0)
+	}
+
+	return status, nil
+}
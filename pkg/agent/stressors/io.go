@@ -0,0 +1,77 @@
+package stressors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ioBlock is the size of each write performed by the IOStressor
+const ioBlock = 1024 * 1024
+
+// IODisruption defines a disruption that stresses the I/O of the disk by writing files
+type IODisruption struct {
+	// Workers is the number of files written concurrently
+	Workers int
+	// FileSize is the size in bytes of each file. The files are overwritten until the disruption ends
+	FileSize uint64
+	// Dir is the directory where the files are written. Defaults to the temporary directory
+	Dir string
+}
+
+// IOStressor defines a stressor for I/O
+type IOStressor struct {
+	FileSize uint64
+	Dir      string
+}
+
+// Apply writes a file, flushing every block to the disk, until the context is done. The file is removed when the
+// context is done.
+func (s *IOStressor) Apply(ctx context.Context) error {
+	file, err := os.CreateTemp(s.Dir, "xk6-disruptor-io-*")
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}()
+
+	block := make([]byte, ioBlock)
+	for i := range block {
+		block[i] = byte(i)
+	}
+
+	written := uint64(0)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		size := min(uint64(len(block)), s.FileSize-written)
+		_, err = file.Write(block[:size])
+		if err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+
+		// flush the block to ensure it is written to the disk and not only to the page cache
+		err = file.Sync()
+		if err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+
+		written += size
+		if written < s.FileSize {
+			continue
+		}
+
+		_, err = file.Seek(0, io.SeekStart)
+		if err != nil {
+			return fmt.Errorf("writing file: %w", err)
+		}
+		written = 0
+	}
+}
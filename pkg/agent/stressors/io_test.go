@@ -0,0 +1,53 @@
+package stressors
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func Test_IOStressor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		fileSize uint64
+	}{
+		{
+			title:    "less than one block",
+			fileSize: 1024,
+		},
+		{
+			title:    "multiple blocks",
+			fileSize: ioBlock + 1024,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			dir := t.TempDir()
+			s := IOStressor{FileSize: tc.fileSize, Dir: dir}
+			err := s.Apply(ctx)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			files, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if len(files) != 0 {
+				t.Fatalf("expected the files to be removed, found %d", len(files))
+			}
+		})
+	}
+}
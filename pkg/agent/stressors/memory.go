@@ -0,0 +1,55 @@
+package stressors
+
+import (
+	"context"
+	"os"
+	"runtime"
+)
+
+// memoryChunk is the size of each allocation performed by the MemoryStressor
+const memoryChunk = 64 * 1024 * 1024
+
+// MemoryDisruption defines a disruption that consumes memory
+type MemoryDisruption struct {
+	// Bytes of memory to consume
+	Bytes uint64
+}
+
+// MemoryStressor defines a stressor for Memory
+type MemoryStressor struct {
+	Bytes uint64
+}
+
+// Apply allocates the memory and keeps it in use until the context is done
+func (s *MemoryStressor) Apply(ctx context.Context) error {
+	pageSize := os.Getpagesize()
+
+	chunks := [][]byte{}
+	for allocated := uint64(0); allocated < s.Bytes; {
+		size := s.Bytes - allocated
+		if size > memoryChunk {
+			size = memoryChunk
+		}
+
+		chunk := make([]byte, size)
+		// write to every page to ensure the memory is actually committed by the OS
+		for i := 0; i < len(chunk); i += pageSize {
+			chunk[i] = 1
+		}
+
+		chunks = append(chunks, chunk)
+		allocated += size
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+	}
+
+	<-ctx.Done()
+
+	runtime.KeepAlive(chunks)
+
+	return nil
+}
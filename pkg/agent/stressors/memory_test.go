@@ -0,0 +1,42 @@
+package stressors
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func Test_MemoryStressor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title string
+		bytes uint64
+	}{
+		{
+			title: "less than one chunk",
+			bytes: 1024 * 1024,
+		},
+		{
+			title: "multiple chunks",
+			bytes: memoryChunk + 1024*1024,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			s := MemoryStressor{Bytes: tc.bytes}
+			err := s.Apply(ctx)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
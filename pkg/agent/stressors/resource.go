@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"runtime"
 	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent/cgroup"
 )
 
 // DefaultSlice default CPU stress slice
@@ -76,9 +78,11 @@ func (s *CPUStressor) Apply(ctx context.Context) error {
 	}
 }
 
-// ResourceDisruption defines a disruption that stress the CPU and Memory of a target
+// ResourceDisruption defines a disruption that stress the CPU, Memory and I/O of a target
 type ResourceDisruption struct {
 	CPUDisruption
+	MemoryDisruption
+	IODisruption
 }
 
 // ResourceStressOptions defines options that control the resource stressing
@@ -101,22 +105,84 @@ func NewResourceStressor(disruption ResourceDisruption, options ResourceStressOp
 		options.Slice = DefaultSlice
 	}
 
+	if disruption.Workers > 0 && disruption.FileSize == 0 {
+		return nil, fmt.Errorf("the size of the files written by the I/O stressors is required")
+	}
+
 	return &ResourceStressor{
 		Options:    options,
 		Disruption: disruption,
 	}, nil
 }
 
+// CheckLimits returns an error if the disruption cannot be applied within the limits of the cgroup: the load of the
+// stressed CPUs would be throttled by the CPU quota, the memory would exceed the memory limit, causing the
+// stressor to be killed, or the writes of the I/O stressors would be throttled. Checking the limits requires the
+// controllers of the stressed resources to be available in the hierarchy.
+func CheckLimits(disruption ResourceDisruption, h *cgroup.Hierarchy) error {
+	if disruption.CPUs > 0 {
+		cpus, err := h.CPULimit()
+		if err != nil {
+			return fmt.Errorf("checking CPU limit: %w", err)
+		}
+
+		load := float64(disruption.CPUs) * float64(disruption.Load) / 100
+		if cpus > 0 && load > cpus {
+			return fmt.Errorf(
+				"the load of %d CPUs at %d%% exceeds the CPU limit of the cgroup (%.2f CPUs)",
+				disruption.CPUs,
+				disruption.Load,
+				cpus,
+			)
+		}
+	}
+
+	if disruption.Bytes > 0 {
+		limit, err := h.MemoryLimit()
+		if err != nil {
+			return fmt.Errorf("checking memory limit: %w", err)
+		}
+
+		usage, err := h.MemoryUsage()
+		if err != nil {
+			return fmt.Errorf("checking memory usage: %w", err)
+		}
+
+		if limit > 0 && usage+disruption.Bytes > limit {
+			return fmt.Errorf(
+				"consuming %d bytes of memory exceeds the memory limit of the cgroup (%d bytes, %d in use)",
+				disruption.Bytes,
+				limit,
+				usage,
+			)
+		}
+	}
+
+	if disruption.Workers > 0 {
+		limited, err := h.IOLimited()
+		if err != nil {
+			return fmt.Errorf("checking I/O limit: %w", err)
+		}
+
+		if limited {
+			return fmt.Errorf("the writes of the I/O stressors are throttled by the I/O limit of the cgroup")
+		}
+	}
+
+	return nil
+}
+
 // Apply applies the resource stress disruption for a given duration
 func (r *ResourceStressor) Apply(ctx context.Context, duration time.Duration) error {
-	if r.Disruption.CPUs == 0 {
-		return fmt.Errorf("at least one CPU must be stressed")
+	if r.Disruption.CPUs == 0 && r.Disruption.Bytes == 0 && r.Disruption.Workers == 0 {
+		return fmt.Errorf("at least one CPU, some memory or the I/O must be stressed")
 	}
 
 	stressorsCtx, done := context.WithTimeout(ctx, duration)
 	defer done()
 
-	doneCh := make(chan error, r.Disruption.CPUs)
+	pending := r.Disruption.CPUs
+	doneCh := make(chan error, r.Disruption.CPUs+r.Disruption.Workers+1)
 	// create a CPUStressor for each CPU
 	for i := 0; i < r.Disruption.CPUs; i++ {
 		go func() {
@@ -128,8 +194,29 @@ func (r *ResourceStressor) Apply(ctx context.Context, duration time.Duration) er
 		}()
 	}
 
+	if r.Disruption.Bytes > 0 {
+		pending++
+		go func() {
+			s := MemoryStressor{
+				Bytes: r.Disruption.Bytes,
+			}
+			doneCh <- s.Apply(stressorsCtx)
+		}()
+	}
+
+	// create an IOStressor for each worker
+	for i := 0; i < r.Disruption.Workers; i++ {
+		pending++
+		go func() {
+			s := IOStressor{
+				FileSize: r.Disruption.FileSize,
+				Dir:      r.Disruption.Dir,
+			}
+			doneCh <- s.Apply(stressorsCtx)
+		}()
+	}
+
 	// wait for all stressors to finish or context to be done
-	pending := r.Disruption.CPUs
 	for pending > 0 {
 		select {
 		case <-ctx.Done():
@@ -0,0 +1,119 @@
+package stressors
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent/cgroup"
+)
+
+func Test_CheckLimits(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		controllers string
+		disruption  ResourceDisruption
+		expectError bool
+		unavailable bool
+	}{
+		{
+			title:       "within limits",
+			controllers: "cpu memory",
+			disruption: ResourceDisruption{
+				CPUDisruption:    CPUDisruption{CPUs: 1, Load: 100},
+				MemoryDisruption: MemoryDisruption{Bytes: 512 * 1024 * 1024},
+			},
+		},
+		{
+			title:       "CPU load exceeds limit",
+			controllers: "cpu memory",
+			disruption:  ResourceDisruption{CPUDisruption: CPUDisruption{CPUs: 2, Load: 100}},
+			expectError: true,
+		},
+		{
+			title:       "CPU load within limit",
+			controllers: "cpu memory",
+			disruption:  ResourceDisruption{CPUDisruption: CPUDisruption{CPUs: 2, Load: 50}},
+		},
+		{
+			title:       "memory exceeds limit",
+			controllers: "cpu memory",
+			disruption:  ResourceDisruption{MemoryDisruption: MemoryDisruption{Bytes: 1024 * 1024 * 1024}},
+			expectError: true,
+		},
+		{
+			title:       "memory controller unavailable",
+			controllers: "cpu",
+			disruption:  ResourceDisruption{MemoryDisruption: MemoryDisruption{Bytes: 1024}},
+			expectError: true,
+			unavailable: true,
+		},
+		{
+			title:       "CPU controller unavailable",
+			controllers: "memory",
+			disruption:  ResourceDisruption{CPUDisruption: CPUDisruption{CPUs: 1, Load: 100}},
+			expectError: true,
+			unavailable: true,
+		},
+		{
+			title:       "I/O throttled",
+			controllers: "cpu memory io",
+			disruption:  ResourceDisruption{IODisruption: IODisruption{Workers: 1, FileSize: 1024}},
+			expectError: true,
+		},
+		{
+			title:       "I/O controller unavailable",
+			controllers: "cpu memory",
+			disruption:  ResourceDisruption{IODisruption: IODisruption{Workers: 1, FileSize: 1024}},
+			expectError: true,
+			unavailable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			// cgroup v2 hierarchy limited to 1.5 CPUs and 1Gi of memory, of which 128Mi are in use, that throttles
+			// the writes to a device
+			root := t.TempDir()
+			for file, content := range map[string]string{
+				"cgroup.controllers": tc.controllers,
+				"cpu.max":            "150000 100000",
+				"memory.max":         "1073741824",
+				"memory.current":     "134217728",
+				"io.max":             "8:0 rbps=max wbps=1048576 riops=max wiops=max",
+			} {
+				err := os.WriteFile(filepath.Join(root, file), []byte(content), 0o600)
+				if err != nil {
+					t.Fatalf("creating file: %v", err)
+				}
+			}
+
+			h, err := cgroup.Detect(root)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			err = CheckLimits(tc.disruption, h)
+			if !tc.expectError {
+				if err != nil {
+					t.Fatalf("failed: %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if tc.unavailable != errors.Is(err, cgroup.ErrControllerUnavailable) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
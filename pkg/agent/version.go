@@ -0,0 +1,23 @@
+package agent
+
+import "github.com/grafana/xk6-disruptor/pkg/internal/version"
+
+// ProtocolVersion is the version of the commands and flags of the agent. It must be increased when they change in a
+// way that previous versions of the extension cannot use.
+const ProtocolVersion = 1
+
+// Info describes the version of the agent and the commands it supports
+type Info struct {
+	Version  string   `json:"version"`
+	Protocol int      `json:"protocol"`
+	Commands []string `json:"commands"`
+}
+
+// NewInfo returns the version information of the agent that supports the given commands
+func NewInfo(commands []string) Info {
+	return Info{
+		Version:  version.DisruptorVersion(),
+		Protocol: ProtocolVersion,
+		Commands: commands,
+	}
+}
@@ -7,6 +7,7 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/grafana/sobek"
 	"github.com/grafana/xk6-disruptor/pkg/disruptors"
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
 	"go.k6.io/k6/js/common"
 )
 
@@ -57,15 +59,148 @@ func (p *jsDisruptor) Targets() sobek.Value {
 	return p.rt.ToValue(targets)
 }
 
+// Stop is a proxy method. Delegates to the Disruptor method
+func (p *jsDisruptor) Stop() {
+	err := p.Disruptor.Stop(p.ctx)
+	if err != nil {
+		Throw(p.rt, fmt.Errorf("error stopping faults: %w", err))
+	}
+}
+
+// Logs is a proxy method. Delegates to the Disruptor method and converts the logs of each target
+func (p *jsDisruptor) Logs() sobek.Value {
+	logs, err := p.Disruptor.Logs(p.ctx)
+	if err != nil {
+		common.Throw(p.rt, fmt.Errorf("error getting agent logs: %w", err))
+	}
+
+	targets := make([]map[string]interface{}, 0, len(logs))
+	for _, l := range logs {
+		targets = append(targets, map[string]interface{}{
+			"target": l.Target,
+			"logs":   l.Logs,
+		})
+	}
+
+	return p.rt.ToValue(targets)
+}
+
+// Shutdown is a proxy method. Delegates to the Disruptor method
+func (p *jsDisruptor) Shutdown() {
+	err := p.Disruptor.Shutdown(p.ctx)
+	if err != nil {
+		common.Throw(p.rt, fmt.Errorf("error shutting down agents: %w", err))
+	}
+}
+
+// Status is a proxy method. Delegates to the Disruptor method and converts the status of each target
+func (p *jsDisruptor) Status() sobek.Value {
+	status, err := p.Disruptor.Status(p.ctx)
+	if err != nil {
+		common.Throw(p.rt, fmt.Errorf("error getting faults status: %w", err))
+	}
+
+	targets := make([]map[string]interface{}, 0, len(status))
+	for _, s := range status {
+		targets = append(targets, map[string]interface{}{
+			"target":    s.Target,
+			"state":     string(s.State),
+			"remaining": s.Remaining.String(),
+			"error":     s.Error,
+			"reapplied": s.Reapplied,
+			"replaces":  s.Replaces,
+		})
+	}
+
+	return p.rt.ToValue(targets)
+}
+
 // jsProtocolFaultInjector implements the JS interface for jsProtocolFaultInjector
 type jsProtocolFaultInjector struct {
 	ctx context.Context // this context controls the object's lifecycle
 	rt  *sobek.Runtime
 	disruptors.ProtocolFaultInjector
+	// disruptor is used for stopping the faults when the test is aborted
+	disruptor disruptors.Disruptor
+}
+
+// stopOnAbort stops the faults in the targets if the test was aborted while injecting them
+func (p *jsProtocolFaultInjector) stopOnAbort() {
+	err := disruptors.StopOnAbort(p.ctx, p.disruptor, disruptors.DefaultAbortTimeout)
+	if err != nil {
+		Throw(p.rt, fmt.Errorf("error stopping aborted faults: %w", err))
+	}
+}
+
+// result returns the targets where the fault failed if it was partially applied and partial application is
+// allowed. Otherwise, throws the error.
+func (p *jsProtocolFaultInjector) result(err error, message string) sobek.Value {
+	failed := []map[string]interface{}{}
+	if err == nil {
+		return p.rt.ToValue(failed)
+	}
+
+	targetsErr := &disruptors.TargetsError{}
+	if !errors.As(err, &targetsErr) || !targetsErr.Accepted {
+		Throw(p.rt, fmt.Errorf("%s: %w", message, err))
+	}
+
+	for _, target := range targetsErr.Failed {
+		failed = append(failed, map[string]interface{}{
+			"target":   target.Target,
+			"error":    target.Err.Error(),
+			"reason":   errorReason(target.Err),
+			"timedOut": errors.Is(target.Err, disruptors.ErrExecTimeout),
+		})
+	}
+
+	return p.rt.ToValue(failed)
+}
+
+// Throw throws the error as an exception, reporting the reason of the failure, if known, in its reason property
+// (see errorReason), so scripts can handle the failure depending on its cause
+func Throw(rt *sobek.Runtime, err error) {
+	exception := rt.NewGoError(err)
+	if reason := errorReason(err); reason != "" {
+		_ = exception.Set("reason", reason)
+	}
+
+	panic(exception)
+}
+
+// errorReason returns the reason of a failure. The reasons of the failure of a fault are invalidFault, portNotFound,
+// permissionDenied, faultActive, unsupportedOS or hostNetwork. The reasons of the failure of a request to the
+// Kubernetes API are targetNotFound, permissionDenied, ephemeralContainersDisabled or versionUnsupported. Returns an
+// empty string if the reason is not known.
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, helpers.ErrTargetNotFound):
+		return "targetNotFound"
+	case errors.Is(err, helpers.ErrPermissionDenied):
+		return "permissionDenied"
+	case errors.Is(err, helpers.ErrEphemeralContainersDisabled):
+		return "ephemeralContainersDisabled"
+	case errors.Is(err, helpers.ErrVersionUnsupported):
+		return "versionUnsupported"
+	case errors.Is(err, disruptors.ErrInvalidFault):
+		return "invalidFault"
+	case errors.Is(err, disruptors.ErrPortNotFound):
+		return "portNotFound"
+	case errors.Is(err, disruptors.ErrPermissionDenied):
+		return "permissionDenied"
+	case errors.Is(err, disruptors.ErrFaultActive):
+		return "faultActive"
+	case errors.Is(err, disruptors.ErrUnsupportedOS):
+		return "unsupportedOS"
+	case errors.Is(err, disruptors.ErrHostNetwork):
+		return "hostNetwork"
+	default:
+		return ""
+	}
 }
 
 // injectHTTPFaults is a proxy method. Validates parameters and delegates to the Protocol Disruptor method
-func (p *jsProtocolFaultInjector) InjectHTTPFaults(args ...sobek.Value) {
+func (p *jsProtocolFaultInjector) InjectHTTPFaults(args ...sobek.Value) sobek.Value {
 	if len(args) < 2 {
 		common.Throw(p.rt, fmt.Errorf("HTTPFault and duration are required"))
 	}
@@ -91,13 +226,13 @@ func (p *jsProtocolFaultInjector) InjectHTTPFaults(args ...sobek.Value) {
 	}
 
 	err = p.ProtocolFaultInjector.InjectHTTPFaults(p.ctx, fault, duration, opts)
-	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("error injecting fault: %w", err))
-	}
+	p.stopOnAbort()
+
+	return p.result(err, "error injecting fault")
 }
 
 // InjectGrpcFaults is a proxy method. Validates parameters and delegates to the PodDisruptor method
-func (p *jsProtocolFaultInjector) InjectGrpcFaults(args ...sobek.Value) {
+func (p *jsProtocolFaultInjector) InjectGrpcFaults(args ...sobek.Value) sobek.Value {
 	if len(args) < 2 {
 		common.Throw(p.rt, fmt.Errorf("GrpcFault and duration are required"))
 	}
@@ -123,9 +258,107 @@ func (p *jsProtocolFaultInjector) InjectGrpcFaults(args ...sobek.Value) {
 	}
 
 	err = p.ProtocolFaultInjector.InjectGrpcFaults(p.ctx, fault, duration, opts)
+	p.stopOnAbort()
+
+	return p.result(err, "error injecting fault")
+}
+
+// ComposeFaults is a proxy method. Validates parameters and delegates to the Protocol Disruptor method
+func (p *jsProtocolFaultInjector) ComposeFaults(args ...sobek.Value) sobek.Value {
+	if len(args) < 2 {
+		common.Throw(p.rt, fmt.Errorf("ComposedFaults and duration are required"))
+	}
+
+	faults := disruptors.ComposedFaults{}
+	err := convertValue(p.rt, args[0], &faults)
+	if err != nil {
+		common.Throw(p.rt, fmt.Errorf("invalid faults argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		common.Throw(p.rt, fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	err = p.ProtocolFaultInjector.ComposeFaults(p.ctx, faults, duration)
+	p.stopOnAbort()
+
+	return p.result(err, "error injecting faults")
+}
+
+// InjectTimeline is a proxy method. Validates parameters and delegates to the Protocol Disruptor method
+func (p *jsProtocolFaultInjector) InjectTimeline(args ...sobek.Value) sobek.Value {
+	if len(args) < 1 {
+		common.Throw(p.rt, fmt.Errorf("FaultTimeline is required"))
+	}
+
+	timeline := disruptors.FaultTimeline{}
+	err := convertValue(p.rt, args[0], &timeline)
 	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("error injecting fault: %w", err))
+		common.Throw(p.rt, fmt.Errorf("invalid timeline argument: %w", err))
 	}
+
+	err = p.ProtocolFaultInjector.InjectTimeline(p.ctx, timeline)
+	p.stopOnAbort()
+
+	return p.result(err, "error injecting faults")
+}
+
+// InjectChaos is a proxy method. Validates parameters and delegates to the Protocol Disruptor method
+func (p *jsProtocolFaultInjector) InjectChaos(args ...sobek.Value) sobek.Value {
+	if len(args) < 2 {
+		common.Throw(p.rt, fmt.Errorf("ChaosSpec and duration are required"))
+	}
+
+	spec := disruptors.ChaosSpec{}
+	err := convertValue(p.rt, args[0], &spec)
+	if err != nil {
+		common.Throw(p.rt, fmt.Errorf("invalid spec argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(p.rt, args[1], &duration)
+	if err != nil {
+		common.Throw(p.rt, fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	err = p.ProtocolFaultInjector.InjectChaos(p.ctx, spec, duration)
+	p.stopOnAbort()
+
+	return p.result(err, "error injecting faults")
+}
+
+// AccessLog is a proxy method. Delegates to the Protocol Disruptor method and converts the access log of each target
+func (p *jsProtocolFaultInjector) AccessLog() sobek.Value {
+	accessLogs, err := p.ProtocolFaultInjector.AccessLog(p.ctx)
+	if err != nil {
+		common.Throw(p.rt, fmt.Errorf("error getting access log: %w", err))
+	}
+
+	targets := make([]map[string]interface{}, 0, len(accessLogs))
+	for _, l := range accessLogs {
+		entries := make([]map[string]interface{}, 0, len(l.Entries))
+		for _, e := range l.Entries {
+			entries = append(entries, map[string]interface{}{
+				"time":           e.Time.Format(time.RFC3339Nano),
+				"method":         e.Method,
+				"path":           e.Path,
+				"fault":          e.Fault,
+				"delay":          e.Delay.String(),
+				"status":         e.Status,
+				"upstreamStatus": e.UpstreamStatus,
+				"upstreamError":  e.UpstreamError,
+			})
+		}
+
+		targets = append(targets, map[string]interface{}{
+			"target":  l.Target,
+			"entries": entries,
+		})
+	}
+
+	return p.rt.ToValue(targets)
 }
 
 // jsPodFaultInjector implements methods for injecting faults into Pods
@@ -150,7 +383,279 @@ func (p *jsPodFaultInjector) TerminatePods(args ...sobek.Value) {
 	// TODO: return list of pods terminated
 	_, err = p.PodFaultInjector.TerminatePods(p.ctx, fault)
 	if err != nil {
-		common.Throw(p.rt, fmt.Errorf("error injecting fault: %w", err))
+		Throw(p.rt, fmt.Errorf("error injecting fault: %w", err))
+	}
+}
+
+// InjectContainerFault is a proxy method. Validates parameters and delegates to the Pod Fault Injector method
+func (p *jsPodFaultInjector) InjectContainerFault(args ...sobek.Value) {
+	if len(args) == 0 {
+		common.Throw(p.rt, fmt.Errorf("ContainerFault is required"))
+	}
+
+	fault := disruptors.ContainerFault{}
+	err := convertValue(p.rt, args[0], &fault)
+	if err != nil {
+		common.Throw(p.rt, fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	// the duration is only required for pausing containers
+	var duration time.Duration
+	if len(args) > 1 {
+		err = convertValue(p.rt, args[1], &duration)
+		if err != nil {
+			common.Throw(p.rt, fmt.Errorf("invalid duration argument: %w", err))
+		}
+	}
+
+	err = p.PodFaultInjector.InjectContainerFault(p.ctx, fault, duration)
+	if err != nil {
+		Throw(p.rt, fmt.Errorf("error injecting fault: %w", err))
+	}
+}
+
+// jsRecoveryVerifier implements the JS interface for RecoveryVerifier
+type jsRecoveryVerifier struct {
+	ctx context.Context
+	rt  *sobek.Runtime
+	disruptors.RecoveryVerifier
+}
+
+// VerifyRecovery is a proxy method. Validates parameters and delegates to the RecoveryVerifier method
+func (r *jsRecoveryVerifier) VerifyRecovery(args ...sobek.Value) sobek.Value {
+	timeout := disruptors.DefaultRecoveryTimeout
+	if len(args) > 0 {
+		err := convertValue(r.rt, args[0], &timeout)
+		if err != nil {
+			common.Throw(r.rt, fmt.Errorf("invalid timeout argument: %w", err))
+		}
+	}
+
+	recovery, err := r.RecoveryVerifier.VerifyRecovery(r.ctx, timeout)
+	if err != nil {
+		Throw(r.rt, fmt.Errorf("error verifying recovery: %w", err))
+	}
+
+	targets := make([]map[string]interface{}, 0, len(recovery))
+	for _, target := range recovery {
+		targets = append(targets, map[string]interface{}{
+			"target":       target.Target,
+			"recovered":    target.Recovered(),
+			"faultRemoved": target.FaultRemoved,
+			"ready":        target.Ready,
+			"error":        target.Error,
+		})
+	}
+
+	return r.rt.ToValue(targets)
+}
+
+// jsNodeFaultInjector implements methods for injecting faults into Nodes
+type jsNodeFaultInjector struct {
+	ctx context.Context
+	rt  *sobek.Runtime
+	disruptors.NodeFaultInjector
+	// disruptor is used for stopping the faults when the test is aborted
+	disruptor disruptors.Disruptor
+}
+
+// stopOnAbort stops the faults in the targets if the test was aborted while injecting them
+func (n *jsNodeFaultInjector) stopOnAbort() {
+	err := disruptors.StopOnAbort(n.ctx, n.disruptor, disruptors.DefaultAbortTimeout)
+	if err != nil {
+		Throw(n.rt, fmt.Errorf("error stopping aborted faults: %w", err))
+	}
+}
+
+// CordonNodes is a proxy method. Validates parameters and delegates to the Node Fault Injector method
+func (n *jsNodeFaultInjector) CordonNodes(args ...sobek.Value) {
+	if len(args) < 2 {
+		common.Throw(n.rt, fmt.Errorf("NodeCordonFault and duration are required"))
+	}
+
+	fault := disruptors.NodeCordonFault{}
+	err := convertValue(n.rt, args[0], &fault)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(n.rt, args[1], &duration)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	err = n.NodeFaultInjector.CordonNodes(n.ctx, fault, duration)
+	n.stopOnAbort()
+	if err != nil {
+		Throw(n.rt, fmt.Errorf("error injecting fault: %w", err))
+	}
+}
+
+// RestartKubelet is a proxy method. Validates parameters and delegates to the Node Fault Injector method
+func (n *jsNodeFaultInjector) RestartKubelet(args ...sobek.Value) {
+	if len(args) < 2 {
+		common.Throw(n.rt, fmt.Errorf("KubeletRestartFault and duration are required"))
+	}
+
+	fault := disruptors.KubeletRestartFault{}
+	err := convertValue(n.rt, args[0], &fault)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(n.rt, args[1], &duration)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	err = n.NodeFaultInjector.RestartKubelet(n.ctx, fault, duration)
+	n.stopOnAbort()
+	if err != nil {
+		Throw(n.rt, fmt.Errorf("error injecting fault: %w", err))
+	}
+}
+
+// InjectNetworkFault is a proxy method. Validates parameters and delegates to the Node Fault Injector method
+func (n *jsNodeFaultInjector) InjectNetworkFault(args ...sobek.Value) {
+	if len(args) < 2 {
+		common.Throw(n.rt, fmt.Errorf("NodeNetworkFault and duration are required"))
+	}
+
+	fault := disruptors.NodeNetworkFault{}
+	err := convertValue(n.rt, args[0], &fault)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(n.rt, args[1], &duration)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	err = n.NodeFaultInjector.InjectNetworkFault(n.ctx, fault, duration)
+	n.stopOnAbort()
+	if err != nil {
+		Throw(n.rt, fmt.Errorf("error injecting fault: %w", err))
+	}
+}
+
+// StressNodes is a proxy method. Validates parameters and delegates to the Node Fault Injector method
+func (n *jsNodeFaultInjector) StressNodes(args ...sobek.Value) {
+	if len(args) < 2 {
+		common.Throw(n.rt, fmt.Errorf("NodeStressFault and duration are required"))
+	}
+
+	fault := disruptors.NodeStressFault{}
+	err := convertValue(n.rt, args[0], &fault)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(n.rt, args[1], &duration)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	err = n.NodeFaultInjector.StressNodes(n.ctx, fault, duration)
+	n.stopOnAbort()
+	if err != nil {
+		Throw(n.rt, fmt.Errorf("error injecting fault: %w", err))
+	}
+}
+
+// SkewClock is a proxy method. Validates parameters and delegates to the Node Fault Injector method
+func (n *jsNodeFaultInjector) SkewClock(args ...sobek.Value) {
+	if len(args) < 2 {
+		common.Throw(n.rt, fmt.Errorf("ClockSkewFault and duration are required"))
+	}
+
+	fault := disruptors.ClockSkewFault{}
+	err := convertValue(n.rt, args[0], &fault)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(n.rt, args[1], &duration)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	err = n.NodeFaultInjector.SkewClock(n.ctx, fault, duration)
+	n.stopOnAbort()
+	if err != nil {
+		Throw(n.rt, fmt.Errorf("error injecting fault: %w", err))
+	}
+}
+
+// RebootNodes is a proxy method. Validates parameters and delegates to the Node Fault Injector method
+func (n *jsNodeFaultInjector) RebootNodes(args ...sobek.Value) {
+	fault := disruptors.NodeRebootFault{}
+	// fault argument is optional
+	if len(args) > 0 {
+		err := convertValue(n.rt, args[0], &fault)
+		if err != nil {
+			common.Throw(n.rt, fmt.Errorf("invalid fault argument: %w", err))
+		}
+	}
+
+	err := n.NodeFaultInjector.RebootNodes(n.ctx, fault)
+	if err != nil {
+		Throw(n.rt, fmt.Errorf("error injecting fault: %w", err))
+	}
+}
+
+// TaintNodes is a proxy method. Validates parameters and delegates to the Node Fault Injector method
+func (n *jsNodeFaultInjector) TaintNodes(args ...sobek.Value) {
+	if len(args) < 2 {
+		common.Throw(n.rt, fmt.Errorf("NodeTaintFault and duration are required"))
+	}
+
+	fault := disruptors.NodeTaintFault{}
+	err := convertValue(n.rt, args[0], &fault)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(n.rt, args[1], &duration)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	err = n.NodeFaultInjector.TaintNodes(n.ctx, fault, duration)
+	n.stopOnAbort()
+	if err != nil {
+		Throw(n.rt, fmt.Errorf("error injecting fault: %w", err))
+	}
+}
+
+// InterruptNodes is a proxy method. Validates parameters and delegates to the Node Fault Injector method
+func (n *jsNodeFaultInjector) InterruptNodes(args ...sobek.Value) {
+	if len(args) < 2 {
+		common.Throw(n.rt, fmt.Errorf("SpotInterruptionFault and duration are required"))
+	}
+
+	fault := disruptors.SpotInterruptionFault{}
+	err := convertValue(n.rt, args[0], &fault)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid fault argument: %w", err))
+	}
+
+	var duration time.Duration
+	err = convertValue(n.rt, args[1], &duration)
+	if err != nil {
+		common.Throw(n.rt, fmt.Errorf("invalid duration argument: %w", err))
+	}
+
+	err = n.NodeFaultInjector.InterruptNodes(n.ctx, fault, duration)
+	n.stopOnAbort()
+	if err != nil {
+		Throw(n.rt, fmt.Errorf("error injecting fault: %w", err))
 	}
 }
 
@@ -158,6 +663,7 @@ type jsPodDisruptor struct {
 	jsDisruptor
 	jsProtocolFaultInjector
 	jsPodFaultInjector
+	jsRecoveryVerifier
 }
 
 // buildJsPodDisruptor builds a goja object that implements the PodDisruptor API
@@ -176,12 +682,18 @@ func buildJsPodDisruptor(
 			ctx:                   ctx,
 			rt:                    rt,
 			ProtocolFaultInjector: disruptor,
+			disruptor:             disruptor,
 		},
 		jsPodFaultInjector: jsPodFaultInjector{
 			ctx:              ctx,
 			rt:               rt,
 			PodFaultInjector: disruptor,
 		},
+		jsRecoveryVerifier: jsRecoveryVerifier{
+			ctx:              ctx,
+			rt:               rt,
+			RecoveryVerifier: disruptor,
+		},
 	}
 
 	return buildObject(rt, d)
@@ -191,6 +703,7 @@ type jsServiceDisruptor struct {
 	jsDisruptor
 	jsProtocolFaultInjector
 	jsPodFaultInjector
+	jsRecoveryVerifier
 }
 
 // buildJsServiceDisruptor builds a goja object that implements the ServiceDisruptor API
@@ -209,12 +722,46 @@ func buildJsServiceDisruptor(
 			ctx:                   ctx,
 			rt:                    rt,
 			ProtocolFaultInjector: disruptor,
+			disruptor:             disruptor,
 		},
 		jsPodFaultInjector: jsPodFaultInjector{
 			ctx:              ctx,
 			rt:               rt,
 			PodFaultInjector: disruptor,
 		},
+		jsRecoveryVerifier: jsRecoveryVerifier{
+			ctx:              ctx,
+			rt:               rt,
+			RecoveryVerifier: disruptor,
+		},
+	}
+
+	return buildObject(rt, d)
+}
+
+type jsNodeDisruptor struct {
+	jsDisruptor
+	jsNodeFaultInjector
+}
+
+// buildJsNodeDisruptor builds a goja object that implements the NodeDisruptor API
+func buildJsNodeDisruptor(
+	ctx context.Context,
+	rt *sobek.Runtime,
+	disruptor disruptors.NodeDisruptor,
+) (*sobek.Object, error) {
+	d := &jsNodeDisruptor{
+		jsDisruptor: jsDisruptor{
+			ctx:       ctx,
+			rt:        rt,
+			Disruptor: disruptor,
+		},
+		jsNodeFaultInjector: jsNodeFaultInjector{
+			ctx:               ctx,
+			rt:                rt,
+			NodeFaultInjector: disruptor,
+			disruptor:         disruptor,
+		},
 	}
 
 	return buildObject(rt, d)
@@ -305,3 +852,142 @@ func NewServiceDisruptor(
 
 	return obj, nil
 }
+
+// NewNodeDisruptor creates an instance of a NodeDisruptor
+// The context passed to this constructor is expected to control the lifecycle of the NodeDisruptor
+func NewNodeDisruptor(
+	ctx context.Context,
+	rt *sobek.Runtime,
+	c sobek.ConstructorCall,
+	k8s kubernetes.Kubernetes,
+) (*sobek.Object, error) {
+	if c.Argument(0).Equals(sobek.Null()) {
+		return nil, fmt.Errorf("NodeDisruptor constructor expects a non null NodeSelector argument")
+	}
+
+	selector := disruptors.NodeSelectorSpec{}
+	err := convertValue(rt, c.Argument(0), &selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NodeSelector: %w", err)
+	}
+
+	options := disruptors.NodeDisruptorOptions{}
+	// options argument is optional
+	if len(c.Arguments) > 1 {
+		err = convertValue(rt, c.Argument(1), &options)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NodeDisruptorOptions: %w", err)
+		}
+	}
+
+	disruptor, err := disruptors.NewNodeDisruptor(ctx, k8s, selector, options)
+	if err != nil {
+		return nil, fmt.Errorf("error creating NodeDisruptor: %w", err)
+	}
+
+	obj, err := buildJsNodeDisruptor(ctx, rt, disruptor)
+	if err != nil {
+		return nil, fmt.Errorf("error creating NodeDisruptor: %w", err)
+	}
+
+	return obj, nil
+}
+
+// NewCluster creates an object for creating disruptors and cleaning up agents in the cluster defined by the
+// kubernetes.Config passed to the constructor. Disruptors created from different clusters can be used in the
+// same test. The context passed to this constructor is expected to control the lifecycle of the disruptors.
+func NewCluster(
+	ctx context.Context,
+	rt *sobek.Runtime,
+	c sobek.ConstructorCall,
+	clusters *kubernetes.Clusters,
+) (*sobek.Object, error) {
+	if c.Argument(0).Equals(sobek.Null()) || c.Argument(0).Equals(sobek.Undefined()) {
+		return nil, fmt.Errorf("Cluster constructor expects a non null cluster config argument")
+	}
+
+	config := kubernetes.Config{}
+	err := convertValue(rt, c.Argument(0), &config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cluster config: %w", err)
+	}
+
+	if config.UserAgent == "" {
+		config.UserAgent = disruptors.UserAgent()
+	}
+
+	k8s, err := clusters.Get(config)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Kubernetes helper: %w", err)
+	}
+
+	constructors := map[string]func(context.Context, *sobek.Runtime, sobek.ConstructorCall, kubernetes.Kubernetes) (
+		*sobek.Object,
+		error,
+	){
+		"PodDisruptor":     NewPodDisruptor,
+		"ServiceDisruptor": NewServiceDisruptor,
+		"NodeDisruptor":    NewNodeDisruptor,
+	}
+
+	obj := rt.NewObject()
+	for name, constructor := range constructors {
+		name, constructor := name, constructor
+		err = obj.Set(name, func(c sobek.ConstructorCall) *sobek.Object {
+			disruptor, cErr := constructor(ctx, rt, c, k8s)
+			if cErr != nil {
+				Throw(rt, fmt.Errorf("error creating %s: %w", name, cErr))
+			}
+			return disruptor
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for name, helper := range clusterHelpers(ctx, rt, k8s) {
+		err = obj.Set(name, helper)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return obj, nil
+}
+
+// Cleanup stops the faults and removes the resources left behind by the agents injected during the test run whose ID
+// is received as argument (by default, the current test run). Returns the pods cleaned up.
+func Cleanup(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes, args ...sobek.Value) sobek.Value {
+	runID := disruptors.RunID()
+	if len(args) > 0 {
+		runID = args[0].String()
+	}
+
+	cleaned, err := disruptors.Cleanup(ctx, k8s, runID)
+	if err != nil {
+		Throw(rt, fmt.Errorf("error cleaning up run %q: %w", runID, err))
+	}
+
+	return rt.ToValue(cleaned)
+}
+
+// RBACManifest returns the YAML manifest of the roles and bindings that grant the minimal permissions required by the
+// disruptors and faults described by the spec received as argument
+func RBACManifest(rt *sobek.Runtime, args ...sobek.Value) sobek.Value {
+	if len(args) == 0 {
+		common.Throw(rt, fmt.Errorf("RBAC spec is required"))
+	}
+
+	spec := disruptors.RBACSpec{}
+	err := convertValue(rt, args[0], &spec)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid RBAC spec: %w", err))
+	}
+
+	manifest, err := disruptors.RBACManifest(spec)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("error generating RBAC manifest: %w", err))
+	}
+
+	return rt.ToValue(string(manifest))
+}
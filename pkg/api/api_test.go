@@ -102,6 +102,15 @@ func testSetup() (*testEnv, error) {
 		return nil, fmt.Errorf("creating namespace: %w", err)
 	}
 
+	node := builders.NewNodeBuilder("some-node").
+		WithLabel("zone", "a").
+		Build()
+
+	_, err = k8s.Client().CoreV1().Nodes().Create(context.TODO(), &node, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("creating node: %w", err)
+	}
+
 	return &testEnv{
 		rt:     rt,
 		client: client,
@@ -150,6 +159,130 @@ func Test_PodDisruptorConstructor(t *testing.T) {
 			`,
 			expectError: false,
 		},
+		{
+			description: "valid constructor with percentage",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const opts = {
+				percentage: 30
+			}
+			new PodDisruptor(selector, opts)
+			`,
+			expectError: false,
+		},
+		{
+			description: "valid constructor with seed",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const opts = {
+				percentage: 30,
+				seed: 1234
+			}
+			new PodDisruptor(selector, opts)
+			`,
+			expectError: false,
+		},
+		{
+			description: "valid constructor with dynamic targets",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const opts = {
+				dynamicTargets: true
+			}
+			new PodDisruptor(selector, opts)
+			`,
+			expectError: false,
+		},
+		{
+			description: "invalid constructor with dynamic targets and percentage",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const opts = {
+				dynamicTargets: true,
+				percentage: 50
+			}
+			new PodDisruptor(selector, opts)
+			`,
+			expectError: true,
+		},
+		{
+			description: "invalid constructor with percentage out of range",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				}
+			}
+			const opts = {
+				percentage: 130
+			}
+			new PodDisruptor(selector, opts)
+			`,
+			expectError: true,
+		},
+		{
+			description: "valid constructor with max targets",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				},
+				maxTargets: 2
+			}
+			new PodDisruptor(selector)
+			`,
+			expectError: false,
+		},
+		{
+			description: "invalid constructor with negative max targets",
+			script: `
+			const selector = {
+				namespace: "namespace",
+				select: {
+					labels: {
+						app: "app"
+					}
+				},
+				maxTargets: -1
+			}
+			new PodDisruptor(selector)
+			`,
+			expectError: true,
+		},
 		{
 			description: "invalid constructor without selector",
 			script: `
@@ -254,6 +387,132 @@ func Test_JsPodDisruptor(t *testing.T) {
 			`,
 			expectError: false,
 		},
+		{
+			description: "stop faults",
+			script: `
+			d.stop()
+			`,
+			expectError: false,
+		},
+		{
+			description: "shutdown agents",
+			script: `
+			d.shutdown()
+			`,
+			expectError: false,
+		},
+		{
+			description: "get agent logs",
+			script: `
+			d.logs()
+			`,
+			expectError: false,
+		},
+		{
+			description: "verify recovery",
+			script: `
+			d.verifyRecovery("1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "verify recovery with invalid timeout",
+			script: `
+			d.verifyRecovery("1")
+			`,
+			expectError: true,
+		},
+		{
+			description: "compose faults",
+			script: `
+			const faults = {
+				http: [
+					{ fault: { averageDelay: "100ms", port: 80 }, options: { proxyPort: 8001 } },
+				],
+			}
+
+			d.composeFaults(faults, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "compose faults targeting the same port",
+			script: `
+			const faults = {
+				http: [
+					{ fault: { averageDelay: "100ms", port: 80 } },
+				],
+				grpc: [
+					{ fault: { errorRate: 0.1, statusCode: 14, port: 80 }, options: { proxyPort: 8001 } },
+				],
+			}
+
+			d.composeFaults(faults, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "compose faults with malformed fault (misspelled field)",
+			script: `
+			const faults = {
+				http: [
+					{ fault: { averageDelay: "100ms", port: 80 }, option: { proxyPort: 8001 } },
+				],
+			}
+
+			d.composeFaults(faults, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject timeline",
+			script: `
+			const timeline = [
+				{ faults: { http: [{ fault: { averageDelay: "100ms", port: 80 } }] }, duration: "2s" },
+				{ faults: { http: [{ fault: { errorRate: 1.0, errorCode: 503, port: 80 } }] }, duration: "1s" },
+			]
+
+			d.injectTimeline(timeline)
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject timeline with step without duration",
+			script: `
+			const timeline = [
+				{ faults: { http: [{ fault: { averageDelay: "100ms", port: 80 } }] } },
+			]
+
+			d.injectTimeline(timeline)
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject chaos",
+			script: `
+			const spec = {
+				faults: {
+					http: [
+						{ fault: { averageDelay: "100ms", port: 80 } },
+						{ fault: { errorRate: 0.1, errorCode: 503, port: 80 } },
+					],
+				},
+				maxFaultDuration: "1s",
+				maxInterval: "100ms",
+				maxPercentage: 50,
+			}
+
+			d.injectChaos(spec, "2s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject chaos without faults",
+			script: `
+			d.injectChaos({ maxFaultDuration: "1s" }, "2s")
+			`,
+			expectError: true,
+		},
 		{
 			description: "inject HTTP Fault with full arguments",
 			script: `
@@ -326,6 +585,40 @@ func Test_JsPodDisruptor(t *testing.T) {
 			`,
 			expectError: true,
 		},
+		{
+			description: "inject HTTP Fault with start time in the past",
+			script: `
+			const fault = {
+				errorRate: 1.0,
+				errorCode: 500,
+				port: 80
+			}
+
+			const faultOpts = {
+				startAt: "2020-01-01T00:00:00Z",
+			}
+
+			d.injectHTTPFaults(fault, "1s", faultOpts)
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject HTTP Fault with negative start delay",
+			script: `
+			const fault = {
+				errorRate: 1.0,
+				errorCode: 500,
+				port: 80
+			}
+
+			const faultOpts = {
+				startAfter: "-1s",
+			}
+
+			d.injectHTTPFaults(fault, "1s", faultOpts)
+			`,
+			expectError: true,
+		},
 		{
 			description: "inject HTTP Fault with malformed fault (misspelled field)",
 			script: `
@@ -623,3 +916,273 @@ func Test_ServiceDisruptorConstructor(t *testing.T) {
 		})
 	}
 }
+
+const setupNodeDisruptor = `
+const selector = {
+	select: {
+		labels: {
+			zone: "a"
+		}
+	}
+}
+
+// skip waiting for the agent pods to start
+const options = {
+	injectTimeout: "-1s"
+}
+
+const d = new NodeDisruptor(selector, options)
+`
+
+func Test_JsNodeDisruptor(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		script      string
+		expectError bool
+	}{
+		{
+			description: "get targets",
+			script: `
+			d.targets()
+			`,
+			expectError: false,
+		},
+		{
+			description: "stop faults",
+			script: `
+			d.stop()
+			`,
+			expectError: false,
+		},
+		{
+			description: "shutdown agents",
+			script: `
+			d.shutdown()
+			`,
+			expectError: false,
+		},
+		{
+			description: "get agent logs",
+			script: `
+			d.logs()
+			`,
+			expectError: false,
+		},
+		{
+			description: "faults status",
+			script: `
+			const status = d.status()
+			if (status.length == 0 || status[0].state != "pending") {
+				throw new Error("unexpected status " + JSON.stringify(status))
+			}
+			`,
+			expectError: false,
+		},
+		{
+			description: "cordon nodes",
+			script: `
+			const fault = {
+				drain: true,
+				timeout: "5s",
+				gracePeriod: "1s"
+			}
+
+			d.cordonNodes(fault, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "cordon nodes without duration",
+			script: `
+			d.cordonNodes({})
+			`,
+			expectError: true,
+		},
+		{
+			description: "cordon nodes with malformed fault (misspelled field)",
+			script: `
+			const fault = {
+				drained: true
+			}
+
+			d.cordonNodes(fault, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "inject network fault",
+			script: `
+			const fault = {
+				delay: "100ms",
+				jitter: "10ms",
+				lossRate: 0.1
+			}
+
+			d.injectNetworkFault(fault, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "inject network fault with malformed fault (misspelled field)",
+			script: `
+			const fault = {
+				latency: "100ms"
+			}
+
+			d.injectNetworkFault(fault, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "stress nodes",
+			script: `
+			const fault = {
+				cores: 2,
+				load: 80,
+				memory: "1Gi"
+			}
+
+			d.stressNodes(fault, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "stress nodes with invalid memory",
+			script: `
+			const fault = {
+				memory: "lots"
+			}
+
+			d.stressNodes(fault, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "skew clock",
+			script: `
+			d.skewClock({ offset: "-5m" }, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "skew clock without offset",
+			script: `
+			d.skewClock({}, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "reboot nodes without providerID",
+			script: `
+			d.rebootNodes()
+			`,
+			expectError: true,
+		},
+		{
+			description: "reboot nodes with malformed fault (misspelled field)",
+			script: `
+			d.rebootNodes({ terminated: true })
+			`,
+			expectError: true,
+		},
+		{
+			description: "taint nodes",
+			script: `
+			const fault = {
+				key: "example.com/fault",
+				value: "true",
+				effect: "NoExecute"
+			}
+
+			d.taintNodes(fault, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "taint nodes with invalid effect",
+			script: `
+			d.taintNodes({ effect: "NoRun" }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "interrupt nodes",
+			script: `
+			const fault = {
+				provider: "gcp",
+				noticePeriod: "1s",
+				drain: true
+			}
+
+			d.interruptNodes(fault, "1s")
+			`,
+			expectError: false,
+		},
+		{
+			description: "interrupt nodes with unsupported provider",
+			script: `
+			d.interruptNodes({ provider: "other" }, "1s")
+			`,
+			expectError: true,
+		},
+		{
+			description: "restart kubelet without duration",
+			script: `
+			d.restartKubelet({})
+			`,
+			expectError: true,
+		},
+		{
+			description: "restart kubelet with malformed fault (misspelled field)",
+			script: `
+			const fault = {
+				services: "kubelet"
+			}
+
+			d.restartKubelet(fault, "1s")
+			`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := testSetup()
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			err = env.registerConstructor("NodeDisruptor", func(e *testEnv, c sobek.ConstructorCall) (*sobek.Object, error) {
+				return NewNodeDisruptor(context.TODO(), e.rt, c, e.k8s)
+			})
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(setupNodeDisruptor)
+			if err != nil {
+				t.Errorf("error in test setup %v", err)
+				return
+			}
+
+			_, err = env.rt.RunString(tc.script)
+
+			if !tc.expectError && err != nil {
+				t.Errorf("failed %v", err)
+				return
+			}
+
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
@@ -130,12 +130,12 @@ func convertStruct(value interface{}, target interface{}) error {
 	}
 
 	for field, fieldValue := range fieldMap {
-		sf := targetValue.FieldByName(toGoCase(field))
-		if !sf.IsValid() {
+		sf, found := fieldByJSName(targetValue.Type(), field)
+		if !found {
 			return fmt.Errorf("unknown field %s in struct %s", field, targetValue.Type().Name())
 		}
 
-		err := Convert(fieldValue, sf.Addr().Interface())
+		err := Convert(fieldValue, targetValue.FieldByIndex(sf.Index).Addr().Interface())
 		if err != nil {
 			return fmt.Errorf("error converting field %s of struct %s: %w", field, targetValue.Type().Name(), err)
 		}
@@ -25,6 +25,10 @@ func Test_Conversions(t *testing.T) {
 		Map         map[string]string
 		Array       []string
 	}
+	type TaggedFields struct {
+		HTTPPort int64  `js:"httpPort"`
+		QOSClass string `js:"qosClass"`
+	}
 
 	testCases := []struct {
 		description string
@@ -212,6 +216,19 @@ func Test_Conversions(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			description: "Tagged struct field conversion",
+			value: map[string]interface{}{
+				"httpPort": int64(80),
+				"qosClass": "Guaranteed",
+			},
+			target: &TaggedFields{},
+			expected: TaggedFields{
+				HTTPPort: 80,
+				QOSClass: "Guaranteed",
+			},
+			expectError: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+
+	"github.com/grafana/sobek"
+	"github.com/grafana/xk6-disruptor/pkg/disruptors"
+	"go.k6.io/k6/js/common"
+	"sigs.k8s.io/yaml"
+)
+
+// definitionKinds returns the types a definition can be loaded into, indexed by the name of their kind
+func definitionKinds() map[string]reflect.Type {
+	kinds := []interface{}{
+		disruptors.PodSelectorSpec{},
+		disruptors.PodDisruptorOptions{},
+		disruptors.ServiceDisruptorOptions{},
+		disruptors.NodeSelectorSpec{},
+		disruptors.NodeDisruptorOptions{},
+		disruptors.HTTPFault{},
+		disruptors.HTTPDisruptionOptions{},
+		disruptors.GrpcFault{},
+		disruptors.GrpcDisruptionOptions{},
+		disruptors.ComposedFaults{},
+		disruptors.FaultTimeline{},
+		disruptors.ChaosSpec{},
+		disruptors.PodTerminationFault{},
+		disruptors.NodeCordonFault{},
+		disruptors.NodeTaintFault{},
+		disruptors.NodeRebootFault{},
+		disruptors.NodeStressFault{},
+		disruptors.NodeNetworkFault{},
+		disruptors.ClockSkewFault{},
+		disruptors.KubeletRestartFault{},
+		disruptors.SpotInterruptionFault{},
+	}
+
+	types := map[string]reflect.Type{}
+	for _, kind := range kinds {
+		t := reflect.TypeOf(kind)
+		types[t.Name()] = t
+	}
+
+	return types
+}
+
+// normalizeNumbers replaces the integral numbers decoded as float64 by int64 values, as they are received
+// from the JS interface
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt64 {
+			return int64(v)
+		}
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// parseDocument parses a YAML or JSON document into a generic value that can be converted into the disruptors'
+// types. The attributes of the document use the same names than the JS API.
+func parseDocument(document []byte) (interface{}, error) {
+	// JSON is a subset of YAML
+	content, err := yaml.YAMLToJSON(document)
+	if err != nil {
+		return nil, fmt.Errorf("parsing definition: %w", err)
+	}
+
+	var value interface{}
+	err = json.Unmarshal(content, &value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing definition: %w", err)
+	}
+
+	return normalizeNumbers(value), nil
+}
+
+// LoadDefinition parses a YAML or JSON document and converts it into the target, which must be a pointer
+// to one of the disruptors' types (e.g. *disruptors.HTTPFault)
+func LoadDefinition(document []byte, target interface{}) error {
+	value, err := parseDocument(document)
+	if err != nil {
+		return err
+	}
+
+	return Convert(value, target)
+}
+
+// LoadDefinitionFile loads the definition in a YAML or JSON file into the target
+func LoadDefinitionFile(path string, target interface{}) error {
+	document, err := os.ReadFile(path) //nolint:gosec // the path is provided by the user
+	if err != nil {
+		return fmt.Errorf("reading definition: %w", err)
+	}
+
+	return LoadDefinition(document, target)
+}
+
+// ParseDefinition parses the YAML or JSON document received as first argument and returns its content as
+// an object. If a kind (e.g. "HTTPFault") is received as second argument, the content is validated against it.
+func ParseDefinition(rt *sobek.Runtime, args ...sobek.Value) sobek.Value {
+	if len(args) == 0 {
+		common.Throw(rt, fmt.Errorf("definition is required"))
+	}
+
+	value, err := parseDocument([]byte(args[0].String()))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	if len(args) > 1 {
+		kind := args[1].String()
+		t, found := definitionKinds()[kind]
+		if !found {
+			common.Throw(rt, fmt.Errorf("unknown definition kind %q", kind))
+		}
+
+		err = Convert(value, reflect.New(t).Interface())
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid %s definition: %w", kind, err))
+		}
+	}
+
+	return rt.ToValue(value)
+}
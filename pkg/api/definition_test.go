@@ -0,0 +1,151 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/disruptors"
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+)
+
+func Test_LoadDefinition(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		description string
+		document    string
+		target      interface{}
+		expected    interface{}
+		expectError bool
+	}{
+		{
+			description: "YAML fault",
+			document: `
+port: 80
+averageDelay: 100ms
+errorRate: 0.1
+errorCode: 503
+`,
+			target: &disruptors.HTTPFault{},
+			expected: disruptors.HTTPFault{
+				Port:         intstr.FromInt32(80),
+				AverageDelay: 100 * time.Millisecond,
+				ErrorRate:    0.1,
+				ErrorCode:    503,
+			},
+			expectError: false,
+		},
+		{
+			description: "JSON selector",
+			document:    `{"namespace": "my-app", "select": {"labels": {"app": "my-app"}}, "maxTargets": 2}`,
+			target:      &disruptors.PodSelectorSpec{},
+			expected: disruptors.PodSelectorSpec{
+				Namespace: "my-app",
+				Select: disruptors.PodAttributes{
+					Labels: map[string]string{"app": "my-app"},
+				},
+				MaxTargets: 2,
+			},
+			expectError: false,
+		},
+		{
+			description: "YAML timeline",
+			document: `
+- faults:
+    http:
+      - fault:
+          port: http
+          averageDelay: 1s
+  duration: 2m
+- faults:
+    http:
+      - fault:
+          port: http
+          errorRate: 1
+          errorCode: 500
+  duration: 1m
+`,
+			target: &disruptors.FaultTimeline{},
+			expected: disruptors.FaultTimeline{
+				{
+					Faults: disruptors.ComposedFaults{
+						HTTP: []disruptors.HTTPFaultSpec{
+							{Fault: disruptors.HTTPFault{Port: intstr.FromString("http"), AverageDelay: time.Second}},
+						},
+					},
+					Duration: 2 * time.Minute,
+				},
+				{
+					Faults: disruptors.ComposedFaults{
+						HTTP: []disruptors.HTTPFaultSpec{
+							{Fault: disruptors.HTTPFault{Port: intstr.FromString("http"), ErrorRate: 1, ErrorCode: 500}},
+						},
+					},
+					Duration: time.Minute,
+				},
+			},
+			expectError: false,
+		},
+		{
+			description: "unknown field",
+			document:    `{"averageDelay": "100ms", "errorCod": 500}`,
+			target:      &disruptors.HTTPFault{},
+			expectError: true,
+		},
+		{
+			description: "malformed document",
+			document:    "averageDelay: [100ms",
+			target:      &disruptors.HTTPFault{},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			err := LoadDefinition([]byte(tc.document), tc.target)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			target := reflect.ValueOf(tc.target).Elem().Interface()
+			if !reflect.DeepEqual(tc.expected, target) {
+				t.Fatalf("expected %+v got %+v", tc.expected, target)
+			}
+		})
+	}
+}
+
+func Test_LoadDefinitionFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "fault.yaml")
+	err := os.WriteFile(path, []byte("errorRate: 0.5\nstatusCode: 14\n"), 0o600)
+	if err != nil {
+		t.Fatalf("creating definition file: %v", err)
+	}
+
+	fault := disruptors.GrpcFault{}
+	err = LoadDefinitionFile(path, &fault)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	expected := disruptors.GrpcFault{ErrorRate: 0.5, StatusCode: 14}
+	if !reflect.DeepEqual(expected, fault) {
+		t.Fatalf("expected %+v got %+v", expected, fault)
+	}
+}
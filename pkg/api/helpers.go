@@ -0,0 +1,402 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/grafana/sobek"
+	"github.com/grafana/xk6-disruptor/pkg/disruptors"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"go.k6.io/k6/js/common"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultWaitTimeout is the default time the helpers wait for a resource to be ready
+const defaultWaitTimeout = 60 * time.Second
+
+// clusterHelpers returns the helper functions of a cluster, by the name they are exposed to scripts
+func clusterHelpers(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes) map[string]interface{} {
+	return map[string]interface{}{
+		"cleanup": func(args ...sobek.Value) sobek.Value {
+			return Cleanup(ctx, rt, k8s, args...)
+		},
+		"waitServiceReady": func(args ...sobek.Value) {
+			WaitServiceReady(ctx, rt, k8s, args...)
+		},
+		"portForward": func(args ...sobek.Value) sobek.Value {
+			return PortForward(ctx, rt, k8s, args...)
+		},
+		"waitDeploymentReady": func(args ...sobek.Value) {
+			WaitDeploymentReady(ctx, rt, k8s, args...)
+		},
+		"waitStatefulSetReady": func(args ...sobek.Value) {
+			WaitStatefulSetReady(ctx, rt, k8s, args...)
+		},
+		"podLogs": func(args ...sobek.Value) sobek.Value {
+			return PodLogs(ctx, rt, k8s, args...)
+		},
+		"applyManifest": func(args ...sobek.Value) sobek.Value {
+			return ApplyManifest(ctx, rt, k8s, args...)
+		},
+		"runJob": func(args ...sobek.Value) sobek.Value {
+			return RunJob(ctx, rt, k8s, args...)
+		},
+		"createNamespace": func(args ...sobek.Value) sobek.Value {
+			return CreateNamespace(ctx, rt, k8s, args...)
+		},
+		"deleteNamespace": func(args ...sobek.Value) {
+			DeleteNamespace(ctx, rt, k8s, args...)
+		},
+	}
+}
+
+// convertResourceArgs converts the name and namespace of a resource and the optional timeout from the arguments
+func convertResourceArgs(rt *sobek.Runtime, kind string, args ...sobek.Value) (string, string, time.Duration, error) {
+	if len(args) < 2 {
+		return "", "", 0, fmt.Errorf("%s name and namespace are required", kind)
+	}
+
+	var name string
+	err := convertValue(rt, args[0], &name)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid %s name: %w", kind, err)
+	}
+
+	var namespace string
+	err = convertValue(rt, args[1], &namespace)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("invalid namespace: %w", err)
+	}
+
+	timeout := defaultWaitTimeout
+	if len(args) > 2 {
+		err = convertValue(rt, args[2], &timeout)
+		if err != nil {
+			return "", "", 0, fmt.Errorf("invalid timeout: %w", err)
+		}
+	}
+
+	return name, namespace, timeout, nil
+}
+
+// WaitServiceReady waits for the service to have ready endpoints. Receives as arguments the name and namespace of the
+// service and, optionally, the timeout (by default, 60s).
+func WaitServiceReady(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes, args ...sobek.Value) {
+	service, namespace, timeout, err := convertResourceArgs(rt, "service", args...)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	err = k8s.ServiceHelper(namespace).WaitServiceReady(ctx, service, timeout)
+	if err != nil {
+		Throw(rt, fmt.Errorf("error waiting for service %q to be ready: %w", service, err))
+	}
+}
+
+// PortForward forwards a local port to a port of a pod or service and returns the local address. Receives as
+// arguments the target, in the form pod/name or service/name (by default, a pod), its namespace and the port. For
+// services, the port is the port exposed by the service. The forwarding is stopped when the context is done.
+func PortForward(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes, args ...sobek.Value) sobek.Value {
+	if len(args) < 3 {
+		common.Throw(rt, fmt.Errorf("target, namespace and port are required"))
+	}
+
+	var target string
+	err := convertValue(rt, args[0], &target)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid target: %w", err))
+	}
+
+	var namespace string
+	err = convertValue(rt, args[1], &namespace)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid namespace: %w", err))
+	}
+
+	var port int64
+	err = convertValue(rt, args[2], &port)
+	if err != nil || port < 0 {
+		common.Throw(rt, fmt.Errorf("invalid port: %v", args[2]))
+	}
+
+	kind, name, found := strings.Cut(target, "/")
+	if !found {
+		kind, name = "pod", target
+	}
+
+	var localPort uint
+	switch kind {
+	case "pod", "pods", "po":
+		localPort, _, err = k8s.PodHelper(namespace).PortForward(ctx, name, uint(port))
+	case "service", "services", "svc":
+		localPort, _, err = k8s.ServiceHelper(namespace).PortForward(ctx, name, uint(port))
+	default:
+		common.Throw(rt, fmt.Errorf("invalid target %q. Expected pod/name or service/name", target))
+	}
+	if err != nil {
+		Throw(rt, fmt.Errorf("error forwarding port %d of %s: %w", port, target, err))
+	}
+
+	return rt.ToValue(fmt.Sprintf("127.0.0.1:%d", localPort))
+}
+
+// WaitDeploymentReady waits for the rollout of the deployment to complete. Receives as arguments the name and
+// namespace of the deployment and, optionally, the timeout (by default, 60s).
+func WaitDeploymentReady(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes, args ...sobek.Value) {
+	deployment, namespace, timeout, err := convertResourceArgs(rt, "deployment", args...)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	err = k8s.WorkloadHelper(namespace).WaitDeploymentReady(ctx, deployment, timeout)
+	if err != nil {
+		Throw(rt, fmt.Errorf("error waiting for deployment %q to be ready: %w", deployment, err))
+	}
+}
+
+// WaitStatefulSetReady waits for the rollout of the statefulset to complete. Receives as arguments the name and
+// namespace of the statefulset and, optionally, the timeout (by default, 60s).
+func WaitStatefulSetReady(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes, args ...sobek.Value) {
+	sts, namespace, timeout, err := convertResourceArgs(rt, "statefulset", args...)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+
+	err = k8s.WorkloadHelper(namespace).WaitStatefulSetReady(ctx, sts, timeout)
+	if err != nil {
+		Throw(rt, fmt.Errorf("error waiting for statefulset %q to be ready: %w", sts, err))
+	}
+}
+
+// podLogsOptions defines the logs returned by PodLogs
+type podLogsOptions struct {
+	Container string
+	Since     time.Duration
+	Tail      int64
+}
+
+// PodLogs returns the logs of a container of a pod. Receives as arguments the name and namespace of the pod and,
+// optionally, an object with the container, the time since when the logs are returned (e.g. "30s") and the number
+// of lines from the end of the logs (tail).
+func PodLogs(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes, args ...sobek.Value) sobek.Value {
+	if len(args) < 2 {
+		common.Throw(rt, fmt.Errorf("pod name and namespace are required"))
+	}
+
+	var pod string
+	err := convertValue(rt, args[0], &pod)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid pod name: %w", err))
+	}
+
+	var namespace string
+	err = convertValue(rt, args[1], &namespace)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid namespace: %w", err))
+	}
+
+	options := podLogsOptions{}
+	if len(args) > 2 {
+		err = convertValue(rt, args[2], &options)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid logs options: %w", err))
+		}
+	}
+
+	stream, err := k8s.PodHelper(namespace).StreamLogs(
+		ctx,
+		pod,
+		helpers.LogOptions{Container: options.Container, Since: options.Since, Tail: options.Tail},
+	)
+	if err != nil {
+		Throw(rt, err)
+	}
+	defer stream.Close() //nolint:errcheck
+
+	logs, err := io.ReadAll(stream)
+	if err != nil {
+		Throw(rt, fmt.Errorf("reading logs of pod %q: %w", pod, err))
+	}
+
+	return rt.ToValue(string(logs))
+}
+
+// applyManifestOptions defines the options of ApplyManifest
+type applyManifestOptions struct {
+	Namespace    string
+	FieldManager string `js:"fieldManager"`
+	Timeout      time.Duration
+}
+
+// ApplyManifest applies the objects of a YAML manifest with one or more documents using server-side apply. Receives
+// as arguments the manifest (e.g. read from a file with open) and, optionally, an object with the namespace of the
+// objects that do not define one, the field manager and the timeout for the deployments, statefulsets and pods to
+// be ready. Returns the kind, namespace and name of the objects applied.
+func ApplyManifest(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes, args ...sobek.Value) sobek.Value {
+	if len(args) == 0 {
+		common.Throw(rt, fmt.Errorf("manifest is required"))
+	}
+
+	var manifest string
+	err := convertValue(rt, args[0], &manifest)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid manifest: %w", err))
+	}
+
+	options := applyManifestOptions{}
+	if len(args) > 1 {
+		err = convertValue(rt, args[1], &options)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid apply options: %w", err))
+		}
+	}
+
+	applied, err := k8s.ManifestHelper().Apply(
+		ctx,
+		[]byte(manifest),
+		helpers.ApplyOptions{
+			Namespace:    options.Namespace,
+			FieldManager: options.FieldManager,
+			Timeout:      options.Timeout,
+		},
+	)
+	if err != nil {
+		Throw(rt, fmt.Errorf("error applying manifest: %w", err))
+	}
+
+	objs := make([]map[string]interface{}, 0, len(applied))
+	for _, obj := range applied {
+		objs = append(objs, map[string]interface{}{
+			"kind":      obj.GetKind(),
+			"namespace": obj.GetNamespace(),
+			"name":      obj.GetName(),
+		})
+	}
+
+	return rt.ToValue(objs)
+}
+
+// runJobOptions defines the options of RunJob
+type runJobOptions struct {
+	Namespace string
+	Timeout   time.Duration
+}
+
+// RunJob creates a job and waits for it to complete or fail. Receives as arguments the job, as an object in the
+// format of the Kubernetes API, and, optionally, an object with the namespace (if not defined by the job) and the
+// timeout (by default, 60s). Returns if the job succeeded and the name, exit code and logs of its last pod.
+func RunJob(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes, args ...sobek.Value) sobek.Value {
+	if len(args) == 0 {
+		common.Throw(rt, fmt.Errorf("job is required"))
+	}
+
+	// the job is converted from its JSON encoding, as defined by the Kubernetes API
+	encoded, err := json.Marshal(args[0].Export())
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid job: %w", err))
+	}
+
+	job := batchv1.Job{}
+	err = json.Unmarshal(encoded, &job)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid job: %w", err))
+	}
+
+	options := runJobOptions{Timeout: defaultWaitTimeout}
+	if len(args) > 1 {
+		err = convertValue(rt, args[1], &options)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid job options: %w", err))
+		}
+	}
+
+	namespace := job.Namespace
+	if namespace == "" {
+		namespace = options.Namespace
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	result, err := k8s.WorkloadHelper(namespace).RunJob(ctx, job, options.Timeout)
+	if err != nil {
+		Throw(rt, fmt.Errorf("error running job %q: %w", job.Name, err))
+	}
+
+	return rt.ToValue(map[string]interface{}{
+		"succeeded": result.Succeeded,
+		"pod":       result.Pod,
+		"exitCode":  result.ExitCode,
+		"logs":      result.Logs,
+	})
+}
+
+// createNamespaceOptions defines the options of CreateNamespace
+type createNamespaceOptions struct {
+	Prefix string
+	Labels map[string]string
+}
+
+// CreateNamespace creates a namespace with a unique name for running an isolated experiment. Receives as argument,
+// optionally, an object with the prefix of the name (by default, xk6-disruptor) and the labels of the namespace.
+// The namespace is labeled with the ID of the test run. Returns the name of the namespace.
+func CreateNamespace(
+	ctx context.Context,
+	rt *sobek.Runtime,
+	k8s kubernetes.Kubernetes,
+	args ...sobek.Value,
+) sobek.Value {
+	options := createNamespaceOptions{}
+	if len(args) > 0 {
+		err := convertValue(rt, args[0], &options)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid namespace options: %w", err))
+		}
+	}
+
+	labels := map[string]string{disruptors.RunIDLabel: disruptors.RunID()}
+	for label, value := range options.Labels {
+		labels[label] = value
+	}
+
+	namespace, err := k8s.NamespaceHelper().Create(ctx, options.Prefix, labels)
+	if err != nil {
+		Throw(rt, err)
+	}
+
+	return rt.ToValue(namespace)
+}
+
+// DeleteNamespace deletes a namespace and waits for it to be removed. Receives as arguments the name of the namespace
+// and, optionally, the timeout (by default, 60s).
+func DeleteNamespace(ctx context.Context, rt *sobek.Runtime, k8s kubernetes.Kubernetes, args ...sobek.Value) {
+	if len(args) == 0 {
+		common.Throw(rt, fmt.Errorf("namespace is required"))
+	}
+
+	var namespace string
+	err := convertValue(rt, args[0], &namespace)
+	if err != nil {
+		common.Throw(rt, fmt.Errorf("invalid namespace: %w", err))
+	}
+
+	timeout := defaultWaitTimeout
+	if len(args) > 1 {
+		err = convertValue(rt, args[1], &timeout)
+		if err != nil {
+			common.Throw(rt, fmt.Errorf("invalid timeout: %w", err))
+		}
+	}
+
+	err = k8s.NamespaceHelper().Delete(ctx, namespace, timeout)
+	if err != nil {
+		Throw(rt, err)
+	}
+}
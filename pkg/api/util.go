@@ -1,6 +1,9 @@
 package api
 
-import "strings"
+import (
+	"reflect"
+	"strings"
+)
 
 // toGoCase transforms an identifier to its camel case
 // maps 'fieldName' and 'field_name' to 'FieldName'
@@ -22,3 +25,16 @@ func toCamelCase(name string) string {
 	first := strings.ToLower(string(runes[0]))
 	return first + string(runes[1:])
 }
+
+// fieldByJSName returns the field of a struct type that corresponds to a JS identifier.
+// Fields with a 'js' tag are matched by their tag. Other fields are matched by the identifier in Go case.
+func fieldByJSName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Tag.Get("js") == name {
+			return sf, true
+		}
+	}
+
+	return t.FieldByName(toGoCase(name))
+}
@@ -35,11 +35,8 @@ func IsCompatible(actual interface{}, expected interface{}) error {
 // ValidateStruct validates that the value of a generic map[string]interface{} can
 // be assigned to a expected Struct using the compatibility rules defined in IsCompatible.
 // Note that the field names are expected to match except for the case of the initial letter
-// e.g  'fieldName' will match 'FieldName' in the struct, but 'field_name' will not.
-//
-// TODO: use the tags in the struct to find out any field name mapping. This will require
-// iterating from the struct to the actual value. This will not detect spurious fields in the
-// actual value
+// e.g  'fieldName' will match 'FieldName' in the struct, but 'field_name' will not, unless the
+// field has a 'js' tag with the name of the field in the actual value (e.g. `js:"field_name"`).
 func ValidateStruct(actual interface{}, expected interface{}) error {
 	actualValue, ok := actual.(map[string]interface{})
 	if !ok {
@@ -52,7 +49,7 @@ func ValidateStruct(actual interface{}, expected interface{}) error {
 	expectedValue := reflect.ValueOf(expected)
 
 	for field, value := range actualValue {
-		sf, found := expectedType.FieldByName(toGoCase(field))
+		sf, found := fieldByJSName(expectedType, field)
 		if !found {
 			return fmt.Errorf("unknown field %s in struct %s", field, expectedType.Name())
 		}
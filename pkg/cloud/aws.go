@@ -0,0 +1,77 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// awsProvider executes operations on EC2 instances using the aws CLI
+type awsProvider struct {
+	executor runtime.Executor
+}
+
+// instance returns the region and the id of the instance from a providerID of the form
+// aws:///<availability zone>/<instance id>
+func (p *awsProvider) instance(node corev1.Node) (string, string, error) {
+	scheme, path, err := providerID(node)
+	if err != nil {
+		return "", "", err
+	}
+
+	if scheme != "aws" || len(path) != 2 || path[0] == "" {
+		return "", "", fmt.Errorf("node %q is not an EC2 instance: %q", node.Name, node.Spec.ProviderID)
+	}
+
+	region := node.Labels[corev1.LabelTopologyRegion]
+	if region == "" {
+		region = node.Labels[corev1.LabelFailureDomainBetaRegion]
+	}
+	if region == "" {
+		region, err = awsRegion(path[0])
+		if err != nil {
+			return "", "", fmt.Errorf("node %q: %w", node.Name, err)
+		}
+	}
+
+	return region, path[1], nil
+}
+
+// awsRegion returns the region of an availability zone, which may be a Local Zone or a Wavelength Zone. The name of
+// the region is the name of the zone up to its number: us-east-1a, us-west-2-lax-1a and us-east-1-wl1-bos-wlz-1 are
+// zones of the regions us-east-1, us-west-2 and us-east-1 respectively.
+func awsRegion(zone string) (string, error) {
+	parts := strings.Split(zone, "-")
+	for i, part := range parts {
+		if i < 2 || part == "" || !unicode.IsDigit(rune(part[0])) {
+			continue
+		}
+
+		number := strings.TrimRightFunc(part, unicode.IsLetter)
+		return strings.Join(append(parts[:i:i], number), "-"), nil
+	}
+
+	return "", fmt.Errorf("invalid availability zone %q", zone)
+}
+
+func (p *awsProvider) run(ctx context.Context, node corev1.Node, operation string) error {
+	region, id, err := p.instance(node)
+	if err != nil {
+		return err
+	}
+
+	return exec(ctx, p.executor, "aws", "ec2", operation, "--region", region, "--instance-ids", id)
+}
+
+func (p *awsProvider) Reboot(ctx context.Context, node corev1.Node) error {
+	return p.run(ctx, node, "reboot-instances")
+}
+
+func (p *awsProvider) Terminate(ctx context.Context, node corev1.Node) error {
+	return p.run(ctx, node, "terminate-instances")
+}
@@ -0,0 +1,86 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// azureProvider executes operations on virtual machines using the az CLI
+type azureProvider struct {
+	executor runtime.Executor
+}
+
+// azureInstance identifies a virtual machine. If it is part of a scale set, the instance is identified by
+// its resource group, the scale set name and the instance id. Otherwise, by its resource id.
+type azureInstance struct {
+	id            string
+	resourceGroup string
+	scaleSet      string
+	instanceID    string
+}
+
+// instance parses a providerID of the form
+// azure:///subscriptions/<subscription>/resourceGroups/<group>/providers/Microsoft.Compute/virtualMachines/<name>
+// or, for instances of a scale set,
+// azure:///subscriptions/<subscription>/resourceGroups/<group>/providers/Microsoft.Compute/virtualMachineScaleSets/<name>/virtualMachines/<id>
+//
+//nolint:lll
+func (p *azureProvider) instance(node corev1.Node) (azureInstance, error) {
+	scheme, path, err := providerID(node)
+	if err != nil {
+		return azureInstance{}, err
+	}
+
+	if scheme != "azure" || len(path) < 8 || path[0] != "subscriptions" || path[2] != "resourceGroups" {
+		return azureInstance{}, fmt.Errorf("node %q is not an Azure virtual machine: %q", node.Name, node.Spec.ProviderID)
+	}
+
+	if path[6] == "virtualMachineScaleSets" && len(path) == 10 {
+		return azureInstance{
+			resourceGroup: path[3],
+			scaleSet:      path[7],
+			instanceID:    path[9],
+		}, nil
+	}
+
+	return azureInstance{id: "/" + strings.Join(path, "/")}, nil
+}
+
+func (p *azureProvider) Reboot(ctx context.Context, node corev1.Node) error {
+	instance, err := p.instance(node)
+	if err != nil {
+		return err
+	}
+
+	if instance.scaleSet != "" {
+		return exec(ctx, p.executor, "az", "vmss", "restart",
+			"--resource-group", instance.resourceGroup,
+			"--name", instance.scaleSet,
+			"--instance-ids", instance.instanceID,
+		)
+	}
+
+	return exec(ctx, p.executor, "az", "vm", "restart", "--ids", instance.id)
+}
+
+func (p *azureProvider) Terminate(ctx context.Context, node corev1.Node) error {
+	instance, err := p.instance(node)
+	if err != nil {
+		return err
+	}
+
+	if instance.scaleSet != "" {
+		return exec(ctx, p.executor, "az", "vmss", "delete-instances",
+			"--resource-group", instance.resourceGroup,
+			"--name", instance.scaleSet,
+			"--instance-ids", instance.instanceID,
+		)
+	}
+
+	return exec(ctx, p.executor, "az", "vm", "delete", "--ids", instance.id, "--yes")
+}
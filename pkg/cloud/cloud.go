@@ -0,0 +1,108 @@
+// Package cloud implements the operations on the instances that back the nodes of a cluster using the
+// command line tools of the cloud providers. The tools must be installed and configured with the credentials
+// for accessing the provider's API.
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Provider defines the operations on the instance that backs a node
+type Provider interface {
+	// Reboot restarts the instance of the node
+	Reboot(ctx context.Context, node corev1.Node) error
+	// Terminate terminates the instance of the node
+	Terminate(ctx context.Context, node corev1.Node) error
+}
+
+// Supported providers
+const (
+	AWS   = "aws"
+	GCP   = "gcp"
+	Azure = "azure"
+)
+
+// New returns the Provider with the given name. If name is empty, the provider is detected from the
+// providerID of the nodes when an operation is executed.
+func New(name string, executor runtime.Executor) (Provider, error) {
+	switch name {
+	case "":
+		return &autoProvider{executor: executor}, nil
+	case AWS:
+		return &awsProvider{executor: executor}, nil
+	case GCP:
+		return &gcpProvider{executor: executor}, nil
+	case Azure:
+		return &azureProvider{executor: executor}, nil
+	default:
+		return nil, fmt.Errorf("unsupported cloud provider %q", name)
+	}
+}
+
+// providerID splits the providerID of a node in the provider's scheme and the instance path
+func providerID(node corev1.Node) (string, []string, error) {
+	scheme, path, found := strings.Cut(node.Spec.ProviderID, "://")
+	if !found {
+		return "", nil, fmt.Errorf("node %q has an invalid providerID %q", node.Name, node.Spec.ProviderID)
+	}
+
+	return scheme, strings.Split(strings.TrimPrefix(path, "/"), "/"), nil
+}
+
+// exec executes a provider's command, returning its output in case of error. The command is killed if the context
+// is done before it completes.
+func exec(ctx context.Context, executor runtime.Executor, cmd string, args ...string) error {
+	out, err := runtime.ExecContext(ctx, executor, cmd, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %s", cmd, err, string(out))
+	}
+
+	return nil
+}
+
+// autoProvider selects the provider from the providerID of each node
+type autoProvider struct {
+	executor runtime.Executor
+}
+
+func (p *autoProvider) provider(node corev1.Node) (Provider, error) {
+	scheme, _, err := providerID(node)
+	if err != nil {
+		return nil, err
+	}
+
+	switch scheme {
+	case "aws":
+		return &awsProvider{executor: p.executor}, nil
+	case "gce":
+		return &gcpProvider{executor: p.executor}, nil
+	case "azure":
+		return &azureProvider{executor: p.executor}, nil
+	default:
+		return nil, fmt.Errorf("unsupported cloud provider %q for node %q", scheme, node.Name)
+	}
+}
+
+func (p *autoProvider) Reboot(ctx context.Context, node corev1.Node) error {
+	provider, err := p.provider(node)
+	if err != nil {
+		return err
+	}
+
+	return provider.Reboot(ctx, node)
+}
+
+func (p *autoProvider) Terminate(ctx context.Context, node corev1.Node) error {
+	provider, err := p.provider(node)
+	if err != nil {
+		return err
+	}
+
+	return provider.Terminate(ctx, node)
+}
@@ -0,0 +1,156 @@
+package cloud
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_Provider(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		provider    string
+		providerID  string
+		labels      map[string]string
+		terminate   bool
+		expectError bool
+		expectedCmd string
+	}{
+		{
+			title:       "reboot EC2 instance",
+			provider:    AWS,
+			providerID:  "aws:///us-east-1a/i-0123456789",
+			expectedCmd: "aws ec2 reboot-instances --region us-east-1 --instance-ids i-0123456789",
+		},
+		{
+			title:       "terminate EC2 instance",
+			provider:    AWS,
+			providerID:  "aws:///us-east-1a/i-0123456789",
+			terminate:   true,
+			expectedCmd: "aws ec2 terminate-instances --region us-east-1 --instance-ids i-0123456789",
+		},
+		{
+			title:       "reboot EC2 instance in a Local Zone",
+			provider:    AWS,
+			providerID:  "aws:///us-west-2-lax-1a/i-0123456789",
+			expectedCmd: "aws ec2 reboot-instances --region us-west-2 --instance-ids i-0123456789",
+		},
+		{
+			title:       "reboot EC2 instance in a Wavelength Zone",
+			provider:    AWS,
+			providerID:  "aws:///us-east-1-wl1-bos-wlz-1/i-0123456789",
+			expectedCmd: "aws ec2 reboot-instances --region us-east-1 --instance-ids i-0123456789",
+		},
+		{
+			title:       "reboot EC2 instance in GovCloud",
+			provider:    AWS,
+			providerID:  "aws:///us-gov-west-1a/i-0123456789",
+			expectedCmd: "aws ec2 reboot-instances --region us-gov-west-1 --instance-ids i-0123456789",
+		},
+		{
+			title:       "region of EC2 instance from node label",
+			provider:    AWS,
+			providerID:  "aws:///use1-az1/i-0123456789",
+			labels:      map[string]string{corev1.LabelTopologyRegion: "us-east-1"},
+			expectedCmd: "aws ec2 reboot-instances --region us-east-1 --instance-ids i-0123456789",
+		},
+		{
+			title:       "invalid availability zone",
+			provider:    AWS,
+			providerID:  "aws:///zone/i-0123456789",
+			expectError: true,
+		},
+		{
+			title:       "reboot Compute Engine instance",
+			provider:    GCP,
+			providerID:  "gce://my-project/europe-west1-b/node-1",
+			expectedCmd: "gcloud compute instances reset node-1 --project my-project --zone europe-west1-b",
+		},
+		{
+			title:       "terminate Compute Engine instance",
+			provider:    GCP,
+			providerID:  "gce://my-project/europe-west1-b/node-1",
+			terminate:   true,
+			expectedCmd: "gcloud compute instances delete node-1 --project my-project --zone europe-west1-b --quiet",
+		},
+		{
+			title:      "reboot Azure virtual machine",
+			provider:   Azure,
+			providerID: "azure:///subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1",
+			expectedCmd: "az vm restart --ids " +
+				"/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/vm-1",
+		},
+		{
+			title:    "terminate Azure scale set instance",
+			provider: Azure,
+			providerID: "azure:///subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute" +
+				"/virtualMachineScaleSets/pool/virtualMachines/3",
+			terminate:   true,
+			expectedCmd: "az vmss delete-instances --resource-group rg --name pool --instance-ids 3",
+		},
+		{
+			title:       "detect provider",
+			provider:    "",
+			providerID:  "gce://my-project/europe-west1-b/node-1",
+			expectedCmd: "gcloud compute instances reset node-1 --project my-project --zone europe-west1-b",
+		},
+		{
+			title:       "provider does not match node",
+			provider:    AWS,
+			providerID:  "gce://my-project/europe-west1-b/node-1",
+			expectError: true,
+		},
+		{
+			title:       "node without providerID",
+			provider:    "",
+			providerID:  "",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			executor := runtime.NewFakeExecutor(nil, nil)
+			provider, err := New(tc.provider, executor)
+			if err != nil {
+				t.Fatalf("failed creating provider: %v", err)
+			}
+
+			node := corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-1", Labels: tc.labels},
+				Spec:       corev1.NodeSpec{ProviderID: tc.providerID},
+			}
+
+			if tc.terminate {
+				err = provider.Terminate(context.TODO(), node)
+			} else {
+				err = provider.Reboot(context.TODO(), node)
+			}
+
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+
+			if executor.Cmd() != tc.expectedCmd {
+				t.Errorf("expected command:\n%s\ngot:\n%s", tc.expectedCmd, executor.Cmd())
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package cloud
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// gcpProvider executes operations on Compute Engine instances using the gcloud CLI
+type gcpProvider struct {
+	executor runtime.Executor
+}
+
+// instance returns the project, zone and name of the instance from a providerID of the form
+// gce://<project>/<zone>/<instance name>
+func (p *gcpProvider) instance(node corev1.Node) (string, string, string, error) {
+	scheme, path, err := providerID(node)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	if scheme != "gce" || len(path) != 3 {
+		return "", "", "", fmt.Errorf("node %q is not a Compute Engine instance: %q", node.Name, node.Spec.ProviderID)
+	}
+
+	return path[0], path[1], path[2], nil
+}
+
+func (p *gcpProvider) run(ctx context.Context, node corev1.Node, operation string, args ...string) error {
+	project, zone, name, err := p.instance(node)
+	if err != nil {
+		return err
+	}
+
+	cmd := []string{"compute", "instances", operation, name, "--project", project, "--zone", zone}
+	return exec(ctx, p.executor, "gcloud", append(cmd, args...)...)
+}
+
+func (p *gcpProvider) Reboot(ctx context.Context, node corev1.Node) error {
+	return p.run(ctx, node, "reset")
+}
+
+func (p *gcpProvider) Terminate(ctx context.Context, node corev1.Node) error {
+	return p.run(ctx, node, "delete", "--quiet")
+}
@@ -0,0 +1,71 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultAbortTimeout is the default time to wait for the faults to be stopped when their injection is aborted
+const DefaultAbortTimeout = 30 * time.Second
+
+// abortCheckInterval is the interval for checking if the faults were stopped
+const abortCheckInterval = time.Second
+
+// StopOnAbort stops the faults applied by the disruptor if the given context was cancelled while injecting them
+// (e.g. the test was aborted) and waits until no target reports an active fault. Otherwise, the agents would keep
+// applying the faults until their duration elapses.
+func StopOnAbort(ctx context.Context, disruptor Disruptor, timeout time.Duration) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+
+	// we use a fresh context because the given context is cancelled
+	stopCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	//nolint:contextcheck
+	err := disruptor.Stop(stopCtx)
+	if err != nil {
+		return fmt.Errorf("stopping faults: %w", err)
+	}
+
+	ticker := time.NewTicker(abortCheckInterval)
+	defer ticker.Stop()
+
+	var active []string
+	for {
+		//nolint:contextcheck
+		active, err = activeTargets(stopCtx, disruptor)
+		if err != nil {
+			return fmt.Errorf("checking faults are stopped: %w", err)
+		}
+
+		if len(active) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-stopCtx.Done():
+			return fmt.Errorf("faults still active in targets %v", active)
+		}
+	}
+}
+
+// activeTargets returns the targets with an active fault
+func activeTargets(ctx context.Context, disruptor Disruptor) ([]string, error) {
+	status, err := disruptor.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	active := []string{}
+	for _, target := range status {
+		if target.State == FaultActive {
+			active = append(active, target.Target)
+		}
+	}
+
+	return active, nil
+}
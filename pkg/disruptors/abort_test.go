@@ -0,0 +1,116 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeDisruptor is a Disruptor whose faults stop after a number of status checks
+type fakeDisruptor struct {
+	stopErr    error
+	stopped    bool
+	activeFor  int
+	statusCall int
+}
+
+func (d *fakeDisruptor) Targets(_ context.Context) ([]string, error) {
+	return []string{"target"}, nil
+}
+
+func (d *fakeDisruptor) Stop(_ context.Context) error {
+	d.stopped = true
+	return d.stopErr
+}
+
+func (d *fakeDisruptor) Shutdown(_ context.Context) error {
+	return nil
+}
+
+func (d *fakeDisruptor) Logs(_ context.Context) ([]TargetLogs, error) {
+	return nil, nil
+}
+
+func (d *fakeDisruptor) Status(_ context.Context) ([]TargetStatus, error) {
+	d.statusCall++
+	if d.statusCall <= d.activeFor {
+		return []TargetStatus{{Target: "target", State: FaultActive}}, nil
+	}
+
+	return []TargetStatus{{Target: "target", State: FaultFinished}}, nil
+}
+
+func Test_StopOnAbort(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		aborted     bool
+		disruptor   *fakeDisruptor
+		timeout     time.Duration
+		expectStop  bool
+		expectError bool
+	}{
+		{
+			title:       "not aborted",
+			aborted:     false,
+			disruptor:   &fakeDisruptor{},
+			timeout:     time.Second,
+			expectStop:  false,
+			expectError: false,
+		},
+		{
+			title:       "aborted",
+			aborted:     true,
+			disruptor:   &fakeDisruptor{activeFor: 1},
+			timeout:     5 * time.Second,
+			expectStop:  true,
+			expectError: false,
+		},
+		{
+			title:       "failed stop",
+			aborted:     true,
+			disruptor:   &fakeDisruptor{stopErr: errors.New("failed")},
+			timeout:     time.Second,
+			expectStop:  true,
+			expectError: true,
+		},
+		{
+			title:       "faults not stopped",
+			aborted:     true,
+			disruptor:   &fakeDisruptor{activeFor: 100},
+			timeout:     2 * time.Second,
+			expectStop:  true,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if tc.aborted {
+				cancel()
+			}
+
+			err := StopOnAbort(ctx, tc.disruptor, tc.timeout)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.disruptor.stopped != tc.expectStop {
+				t.Fatalf("expected stopped %t got %t", tc.expectStop, tc.disruptor.stopped)
+			}
+		})
+	}
+}
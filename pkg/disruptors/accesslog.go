@@ -0,0 +1,67 @@
+package disruptors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AccessLogEntry describes a request handled by the agent's proxy in a target with the access log enabled
+type AccessLogEntry struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	// Fault applied to the request: none, excluded, delay or error
+	Fault string `json:"fault"`
+	// Delay injected in the response
+	Delay time.Duration `json:"delay"`
+	// Status returned to the client
+	Status int `json:"status"`
+	// UpstreamStatus is the status returned by the target. Zero if the request was not forwarded or it failed.
+	UpstreamStatus int `json:"upstreamStatus"`
+	// UpstreamError is the reason of the failure forwarding the request to the target, if any
+	UpstreamError string `json:"upstreamError"`
+}
+
+// TargetAccessLog contains the requests handled by the disruptor agent in a target
+type TargetAccessLog struct {
+	// Target is the name of the target
+	Target string
+	// Entries of the access log. Empty if the agent is not running in the target.
+	Entries []AccessLogEntry
+}
+
+// podAgentAccessLog returns the access log of the agent injected in the pod
+func podAgentAccessLog(ctx context.Context, helper helpers.PodHelper, pod corev1.Pod) (TargetAccessLog, error) {
+	accessLog := TargetAccessLog{Target: pod.Name, Entries: []AccessLogEntry{}}
+	if !hasAgent(pod) {
+		return accessLog, nil
+	}
+
+	stdout, stderr, err := helper.Exec(ctx, pod.Name, "xk6-agent", buildAccessLogCmd(), []byte{})
+	if err != nil {
+		return TargetAccessLog{}, fmt.Errorf("getting access log in %q: %w \n%s", pod.Name, err, string(stderr))
+	}
+
+	for _, line := range bytes.Split(stdout, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		entry := AccessLogEntry{}
+		err = json.Unmarshal(line, &entry)
+		if err != nil {
+			return TargetAccessLog{}, fmt.Errorf("decoding access log in %q: %w", pod.Name, err)
+		}
+
+		accessLog.Entries = append(accessLog.Entries, entry)
+	}
+
+	return accessLog, nil
+}
@@ -0,0 +1,113 @@
+package disruptors
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_PodAgentAccessLog(t *testing.T) {
+	t.Parallel()
+
+	agent := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name: "xk6-agent",
+		},
+	}
+
+	requestTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		title       string
+		stdout      []byte
+		expectError bool
+		expected    TargetAccessLog
+	}{
+		{
+			title:    "agent not injected",
+			expected: TargetAccessLog{Target: "pod1", Entries: []AccessLogEntry{}},
+		},
+		{
+			title:    "empty access log",
+			stdout:   []byte{},
+			expected: TargetAccessLog{Target: "pod1", Entries: []AccessLogEntry{}},
+		},
+		{
+			title: "requests",
+			stdout: []byte(
+				`{"time":"2024-01-01T00:00:00Z","method":"GET","path":"/a","fault":"delay","delay":100000000,` +
+					`"status":200,"upstreamStatus":200}` + "\n" +
+					`{"time":"2024-01-01T00:00:00Z","method":"POST","path":"/b","fault":"error","status":500}` + "\n",
+			),
+			expected: TargetAccessLog{
+				Target: "pod1",
+				Entries: []AccessLogEntry{
+					{
+						Time:           requestTime,
+						Method:         http.MethodGet,
+						Path:           "/a",
+						Fault:          "delay",
+						Delay:          100 * time.Millisecond,
+						Status:         http.StatusOK,
+						UpstreamStatus: http.StatusOK,
+					},
+					{
+						Time:   requestTime,
+						Method: http.MethodPost,
+						Path:   "/b",
+						Fault:  "error",
+						Status: http.StatusInternalServerError,
+					},
+				},
+			},
+		},
+		{
+			title:       "invalid access log",
+			stdout:      []byte("not an entry\n"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+			if tc.stdout != nil {
+				pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, agent)
+			}
+
+			client := fake.NewSimpleClientset(&pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			executor.SetResult(tc.stdout, nil, nil)
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+
+			accessLog, err := podAgentAccessLog(context.TODO(), helper, pod)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expected, accessLog); diff != "" {
+				t.Errorf("expected access log does not match returned:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/agent"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// AgentConfigEnvVar is the environment variable that defines the ConfigMap with the cluster-wide defaults of the
+// agents, as namespace/name. Defaults to DefaultAgentConfig.
+const AgentConfigEnvVar = "XK6_DISRUPTOR_AGENT_CONFIG"
+
+// DefaultAgentConfig is the ConfigMap with the cluster-wide defaults of the agents if AgentConfigEnvVar is not defined
+const DefaultAgentConfig = "xk6-disruptor/xk6-disruptor-agent"
+
+// keys of the ConfigMap with the defaults of the agents
+const (
+	agentConfigLogLevel      = "logLevel"
+	agentConfigProxyPorts    = "proxyPorts"
+	agentConfigExcludedPorts = "excludedPorts"
+	agentConfigMetrics       = "metrics"
+	agentConfigMetricsRate   = "metricsRate"
+	agentConfigInterception  = "interception"
+)
+
+// AgentConfig defines the cluster-wide defaults of the agents. Operators set them in a ConfigMap, which the
+// disruptors pass to the agents they inject, instead of every test re-specifying them.
+type AgentConfig struct {
+	// LogLevel defines the commands reported in the log of the agent's container: none, error, info or debug
+	LogLevel string
+	// ProxyPorts is the range of ports (e.g. 8000-8099) the proxies of the agent listen on when the proxyPort
+	// option of the fault is not specified
+	ProxyPorts string
+	// ExcludedPorts are the ports that cannot be disrupted or used by the proxies of the agent
+	ExcludedPorts []uint
+	// Metrics enables the collection of runtime metrics by the agent
+	Metrics bool
+	// MetricsRate is the frequency of the sampling of the runtime metrics
+	MetricsRate time.Duration
+	// Interception is the backend the agent uses for redirecting the traffic to its proxies: auto, iptables or
+	// nftables
+	Interception string
+}
+
+// parseAgentConfig parses the data of the ConfigMap with the defaults of the agents
+func parseAgentConfig(data map[string]string) (AgentConfig, error) {
+	config := AgentConfig{}
+
+	var err error
+	for key, value := range data {
+		value = strings.TrimSpace(value)
+		switch key {
+		case agentConfigLogLevel:
+			switch value {
+			case agent.LogLevelNone, agent.LogLevelError, agent.LogLevelInfo, agent.LogLevelDebug:
+			default:
+				err = fmt.Errorf(
+					"must be one of %s, %s, %s or %s",
+					agent.LogLevelNone,
+					agent.LogLevelError,
+					agent.LogLevelInfo,
+					agent.LogLevelDebug,
+				)
+			}
+			config.LogLevel = value
+		case agentConfigInterception:
+			// the backends the agents use for redirecting the traffic to their proxies, as defined in the agent
+			switch value {
+			case "auto", "iptables", "nftables":
+			default:
+				err = fmt.Errorf("must be one of auto, iptables or nftables")
+			}
+			config.Interception = value
+		case agentConfigProxyPorts:
+			_, err = agent.ParsePortRange(value)
+			config.ProxyPorts = value
+		case agentConfigExcludedPorts:
+			config.ExcludedPorts, err = agent.ParsePorts(value)
+		case agentConfigMetrics:
+			config.Metrics, err = strconv.ParseBool(value)
+		case agentConfigMetricsRate:
+			config.MetricsRate, err = time.ParseDuration(value)
+			if err == nil && config.MetricsRate <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		default:
+			return AgentConfig{}, fmt.Errorf("unknown key %q", key)
+		}
+
+		if err != nil {
+			return AgentConfig{}, fmt.Errorf("invalid %s %q: %w", key, value, err)
+		}
+	}
+
+	return config, nil
+}
+
+// args returns the arguments of the agent that apply the defaults
+func (c AgentConfig) args() []string {
+	args := []string{}
+	if c.LogLevel != "" {
+		args = append(args, "--log-level", c.LogLevel)
+	}
+
+	if c.ProxyPorts != "" {
+		args = append(args, "--proxy-ports", c.ProxyPorts)
+	}
+
+	if len(c.ExcludedPorts) > 0 {
+		ports := make([]string, 0, len(c.ExcludedPorts))
+		for _, port := range c.ExcludedPorts {
+			ports = append(ports, fmt.Sprint(port))
+		}
+		args = append(args, "--excluded-ports", strings.Join(ports, ","))
+	}
+
+	if c.Metrics {
+		args = append(args, "--metrics")
+	}
+
+	if c.MetricsRate > 0 {
+		args = append(args, "--metrics-rate", c.MetricsRate.String())
+	}
+
+	if c.Interception != "" {
+		args = append(args, "--interception", c.Interception)
+	}
+
+	return args
+}
+
+// agentConfigReference returns the namespace and name of the ConfigMap with the defaults of the agents
+func agentConfigReference() (string, string, error) {
+	reference := utils.GetStringEnvVar(AgentConfigEnvVar, DefaultAgentConfig)
+
+	namespace, name, found := strings.Cut(reference, "/")
+	if !found || namespace == "" || name == "" {
+		return "", "", fmt.Errorf("invalid %s %q: expected format namespace/name", AgentConfigEnvVar, reference)
+	}
+
+	return namespace, name, nil
+}
+
+// loadAgentConfig returns the defaults of the agents defined in the ConfigMap referenced by AgentConfigEnvVar.
+// Returns empty defaults if the ConfigMap does not exist or the user is not allowed to read it, as the ConfigMap is
+// optional and most users are not granted access to the namespace of the disruptor.
+func loadAgentConfig(ctx context.Context, client kubernetes.Interface) (AgentConfig, error) {
+	namespace, name, err := agentConfigReference()
+	if err != nil {
+		return AgentConfig{}, err
+	}
+
+	configMap, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return AgentConfig{}, nil
+	}
+	if err != nil {
+		return AgentConfig{}, fmt.Errorf("getting agent configuration %s/%s: %w", namespace, name, err)
+	}
+
+	config, err := parseAgentConfig(configMap.Data)
+	if err != nil {
+		return AgentConfig{}, fmt.Errorf("agent configuration %s/%s: %w", namespace, name, err)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,238 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_ParseAgentConfig(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		data         map[string]string
+		expectError  bool
+		expected     AgentConfig
+		expectedArgs []string
+	}{
+		{
+			title:        "empty",
+			data:         map[string]string{},
+			expectError:  false,
+			expected:     AgentConfig{},
+			expectedArgs: []string{},
+		},
+		{
+			title: "all settings",
+			data: map[string]string{
+				"logLevel":      "debug",
+				"proxyPorts":    "8000-8099",
+				"excludedPorts": "22, 9090",
+				"metrics":       "true",
+				"metricsRate":   "5s",
+				"interception":  "iptables",
+			},
+			expectError: false,
+			expected: AgentConfig{
+				LogLevel:      "debug",
+				ProxyPorts:    "8000-8099",
+				ExcludedPorts: []uint{22, 9090},
+				Metrics:       true,
+				MetricsRate:   5 * time.Second,
+				Interception:  "iptables",
+			},
+			expectedArgs: []string{
+				"--log-level", "debug",
+				"--proxy-ports", "8000-8099",
+				"--excluded-ports", "22,9090",
+				"--metrics",
+				"--metrics-rate", "5s",
+				"--interception", "iptables",
+			},
+		},
+		{
+			title:       "invalid log level",
+			data:        map[string]string{"logLevel": "verbose"},
+			expectError: true,
+		},
+		{
+			title:       "invalid proxy ports",
+			data:        map[string]string{"proxyPorts": "8099-8000"},
+			expectError: true,
+		},
+		{
+			title:       "invalid excluded port",
+			data:        map[string]string{"excludedPorts": "22,http"},
+			expectError: true,
+		},
+		{
+			title:       "invalid metrics rate",
+			data:        map[string]string{"metricsRate": "0s"},
+			expectError: true,
+		},
+		{
+			title:       "invalid interception backend",
+			data:        map[string]string{"interception": "ipvs"},
+			expectError: true,
+		},
+		{
+			title:       "unknown key",
+			data:        map[string]string{"loglevel": "debug"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := parseAgentConfig(tc.data)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expected, config); diff != "" {
+				t.Fatalf("expected config does not match returned\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.expectedArgs, config.args()); diff != "" {
+				t.Fatalf("expected args do not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+//nolint:paralleltest // uses t.Setenv
+func Test_LoadAgentConfig(t *testing.T) {
+	testCases := []struct {
+		title       string
+		env         string
+		objects     []runtime.Object
+		forbidden   bool
+		expectError bool
+		expected    AgentConfig
+	}{
+		{
+			title:       "no config",
+			objects:     []runtime.Object{},
+			expectError: false,
+			expected:    AgentConfig{},
+		},
+		{
+			title: "default config",
+			objects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "xk6-disruptor", Name: "xk6-disruptor-agent"},
+					Data:       map[string]string{"logLevel": "error"},
+				},
+			},
+			expectError: false,
+			expected:    AgentConfig{LogLevel: "error"},
+		},
+		{
+			title: "config from environment",
+			env:   "testns/agent",
+			objects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "testns", Name: "agent"},
+					Data:       map[string]string{"metrics": "true"},
+				},
+			},
+			expectError: false,
+			expected:    AgentConfig{Metrics: true},
+		},
+		{
+			title:       "config cannot be read",
+			objects:     []runtime.Object{},
+			forbidden:   true,
+			expected:    AgentConfig{},
+			expectError: false,
+		},
+		{
+			title:       "invalid reference",
+			env:         "agent",
+			objects:     []runtime.Object{},
+			expectError: true,
+		},
+		{
+			title: "invalid config",
+			objects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "xk6-disruptor", Name: "xk6-disruptor-agent"},
+					Data:       map[string]string{"metrics": "sometimes"},
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			t.Setenv(AgentConfigEnvVar, tc.env)
+
+			client := fake.NewSimpleClientset(tc.objects...)
+			if tc.forbidden {
+				client.PrependReactor("get", "configmaps", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewForbidden(corev1.Resource("configmaps"), "xk6-disruptor-agent", nil)
+				})
+			}
+
+			config, err := loadAgentConfig(context.TODO(), client)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, config); diff != "" {
+				t.Fatalf("expected config does not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_WithAgentArgs(t *testing.T) {
+	t.Parallel()
+
+	commands := VisitCommands{
+		Exec:     []string{"xk6-disruptor-agent", "http", "-d", "60s"},
+		Cleanup:  []string{"xk6-disruptor-agent", "cleanup"},
+		Duration: time.Minute,
+	}
+
+	expected := VisitCommands{
+		Exec:     []string{"xk6-disruptor-agent", "--log-level", "debug", "http", "-d", "60s"},
+		Cleanup:  []string{"xk6-disruptor-agent", "--log-level", "debug", "cleanup"},
+		Duration: time.Minute,
+	}
+
+	if diff := cmp.Diff(expected, commands.withAgentArgs([]string{"--log-level", "debug"})); diff != "" {
+		t.Fatalf("expected commands do not match returned\n%s", diff)
+	}
+
+	if diff := cmp.Diff(commands, commands.withAgentArgs(nil)); diff != "" {
+		t.Fatalf("expected commands do not match returned\n%s", diff)
+	}
+}
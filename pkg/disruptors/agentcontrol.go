@@ -0,0 +1,220 @@
+package disruptors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+)
+
+const (
+	// AgentControlExec applies the faults by executing the agent commands in the agent container. The execution
+	// lasts for the entire duration of the fault.
+	AgentControlExec = "exec"
+	// AgentControlAPI applies the faults using the control API served by the agent, which is reached by forwarding
+	// its port. The requests to the API are short-lived, therefore the faults are not affected by the interruption
+	// of a connection.
+	AgentControlAPI = "api"
+)
+
+// agentControlPort is the port of the control API of the agent. It must match the default control port of the agent.
+const agentControlPort = 9099
+
+// agentControlPollInterval is the interval between the requests of the status of a fault to the control API
+const agentControlPollInterval = time.Second
+
+// agentControlMaxFailures is the number of consecutive failed requests of the status of a fault that are tolerated
+const agentControlMaxFailures = 5
+
+// agentApplyRequest is the request for applying a fault to the control API. It mirrors agent.ApplyRequest.
+type agentApplyRequest struct {
+	Args []string `json:"args"`
+}
+
+// agentControlStatus is the status reported by the control API. It mirrors agent.ControlStatus.
+type agentControlStatus struct {
+	State    string `json:"state"`
+	Error    string `json:"error"`
+	Running  bool   `json:"running"`
+	ExitCode int    `json:"exitCode"`
+	Output   string `json:"output"`
+}
+
+// agentControlError is the error returned by the control API. It mirrors agent.ControlError.
+type agentControlError struct {
+	Error    string `json:"error"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// validateAgentControl returns an error if the agent control mode is not valid. An empty value is the default mode.
+func validateAgentControl(mode string) error {
+	switch mode {
+	case "", AgentControlExec, AgentControlAPI:
+		return nil
+	default:
+		return fmt.Errorf("invalid agentControl %q: must be %s or %s", mode, AgentControlExec, AgentControlAPI)
+	}
+}
+
+// agentControlCommand returns the command of the agent container with the control API enabled
+func agentControlCommand() []string {
+	return append(agentCommand(), "--control-port", fmt.Sprint(agentControlPort))
+}
+
+// agentControlStopTimeout is the maximum time for stopping a fault with the control API when the fault is cancelled
+const agentControlStopTimeout = 10 * time.Second
+
+// agentControl sends requests to the control API of the agent in a pod. The port of the API is forwarded by the
+// first request and reused by the following ones. If a request fails, the port is forwarded again by the next one,
+// as the forwarding may have been interrupted.
+type agentControl struct {
+	helper    helpers.PodHelper
+	pod       string
+	localPort uint
+	// stop stops the forwarding of the port. It is nil if the port is not forwarded.
+	stop func()
+}
+
+// forward returns the local port forwarded to the port of the API, forwarding it if it is not forwarded.
+// The forwarding is not bound to the context of the request, as it is reused by the following requests.
+func (c *agentControl) forward(ctx context.Context) (uint, error) {
+	if c.stop != nil {
+		return c.localPort, nil
+	}
+
+	localPort, stop, err := c.helper.PortForward(context.WithoutCancel(ctx), c.pod, agentControlPort)
+	if err != nil {
+		return 0, err
+	}
+
+	c.localPort = localPort
+	c.stop = stop
+
+	return localPort, nil
+}
+
+// close stops the forwarding of the port of the API
+func (c *agentControl) close() {
+	if c.stop != nil {
+		c.stop()
+		c.stop = nil
+	}
+}
+
+// request sends a request to the control API of the agent. If response is not nil, the body of the response is
+// decoded into it.
+func (c *agentControl) request(
+	ctx context.Context,
+	method string,
+	path string,
+	body interface{},
+	response interface{},
+) error {
+	var content io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encoding request: %w", err)
+		}
+		content = bytes.NewReader(encoded)
+	}
+
+	localPort, err := c.forward(ctx)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d%s", localPort, path)
+	request, err := http.NewRequestWithContext(ctx, method, url, content)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(request)
+	if err != nil {
+		c.close()
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // the body is only read
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		controlErr := agentControlError{}
+		_ = json.NewDecoder(resp.Body).Decode(&controlErr)
+
+		return &AgentError{
+			Reason:   agentErrorReason(controlErr.ExitCode, controlErr.Error),
+			ExitCode: controlErr.ExitCode,
+			Message:  controlErr.Error,
+			Err:      fmt.Errorf("control API returned %s", resp.Status),
+		}
+	}
+
+	if response == nil {
+		return nil
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// applyWithControlAPI applies a fault using the control API of the agent in the pod and waits for its completion.
+// If the context is done before the fault completes, the fault is stopped.
+func applyWithControlAPI(ctx context.Context, helper helpers.PodHelper, pod string, command []string) error {
+	control := &agentControl{helper: helper, pod: pod}
+	defer control.close()
+
+	// the first element of the command is the agent binary
+	err := control.request(ctx, http.MethodPost, "/apply", agentApplyRequest{Args: command[1:]}, nil)
+	if err != nil {
+		return fmt.Errorf("requesting the fault to the control API of the agent: %w", err)
+	}
+
+	ticker := time.NewTicker(agentControlPollInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			// the context of the fault is done, therefore the fault is stopped with a new one
+			stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), agentControlStopTimeout)
+			_ = control.request(stopCtx, http.MethodPost, "/stop", nil, nil)
+			cancel()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		status := agentControlStatus{}
+		err = control.request(ctx, http.MethodGet, "/status", nil, &status)
+		if err != nil {
+			// the request is retried, as the status can be requested again while the agent applies the fault
+			failures++
+			if failures >= agentControlMaxFailures {
+				return fmt.Errorf("getting the status of the fault from the control API of the agent: %w", err)
+			}
+			continue
+		}
+
+		failures = 0
+		if status.Running {
+			continue
+		}
+
+		if status.ExitCode != 0 {
+			message := strings.TrimSpace(status.Output)
+			return &AgentError{
+				Reason:   agentErrorReason(status.ExitCode, message),
+				ExitCode: status.ExitCode,
+				Message:  message,
+				Err:      fmt.Errorf("command terminated with exit code %d", status.ExitCode),
+			}
+		}
+
+		return nil
+	}
+}
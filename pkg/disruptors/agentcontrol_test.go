@@ -0,0 +1,132 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+)
+
+// fakeControlAPI returns a handler that emulates the control API of the agent, responding to the requests with the
+// given status code and body
+func fakeControlAPI(applyCode int, applyBody string, statusBody string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /apply", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(applyCode)
+		_, _ = w.Write([]byte(applyBody))
+	})
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(statusBody))
+	})
+	mux.HandleFunc("POST /stop", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
+
+func Test_PodAgentVisitorControlAPI(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title        string
+		noAPI        bool
+		applyCode    int
+		applyBody    string
+		statusBody   string
+		expectError  bool
+		expectReason error
+	}{
+		{
+			title:       "fault applied",
+			applyCode:   http.StatusAccepted,
+			statusBody:  `{"state":"finished","running":false,"exitCode":0}`,
+			expectError: false,
+		},
+		{
+			title:        "fault failed",
+			applyCode:    http.StatusAccepted,
+			statusBody:   `{"state":"failed","running":false,"exitCode":2,"output":"invalid fault: target port"}`,
+			expectError:  true,
+			expectReason: ErrInvalidFault,
+		},
+		{
+			title:        "fault active",
+			applyCode:    http.StatusConflict,
+			applyBody:    `{"error":"another instance of the agent is already running","exitCode":5}`,
+			expectError:  true,
+			expectReason: ErrFaultActive,
+		},
+		{
+			title:       "control API not available",
+			noAPI:       true,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(fakeControlAPI(tc.applyCode, tc.applyBody, tc.statusBody))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			port, err := strconv.ParseUint(serverURL.Port(), 10, 32)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+			client := fake.NewSimpleClientset(&pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			if !tc.noAPI {
+				executor.SetLocalPort(uint(port))
+			}
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+			visitor := NewPodAgentVisitor(
+				helper,
+				PodAgentVisitorOptions{Timeout: -1, ControlAPI: true},
+				fakeCommand{exec: []string{"xk6-disruptor-agent", "http"}},
+			)
+
+			err = visitor.Visit(context.TODO(), pod)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectReason != nil && !errors.Is(err, tc.expectReason) {
+				t.Fatalf("expected error reason %v returned %v", tc.expectReason, err)
+			}
+
+			// the port of the control API is forwarded once for all the requests
+			if !tc.noAPI && len(executor.GetForwards()) != 1 {
+				t.Fatalf("expected 1 port forward got %d", len(executor.GetForwards()))
+			}
+
+			// the fault is applied using the control API instead of executing the agent command
+			for _, command := range executor.GetHistory() {
+				if command.Command[0] == "xk6-disruptor-agent" && command.Command[1] == "http" {
+					t.Fatalf("agent command should not be executed")
+				}
+			}
+		})
+	}
+}
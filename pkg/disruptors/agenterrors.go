@@ -0,0 +1,108 @@
+package disruptors
+
+import (
+	"errors"
+	"strings"
+
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// exit codes of the agent commands that report the reason of a failure. They must match the exit codes defined
+// in the agent.
+const (
+	agentExitInvalidFault     = 2
+	agentExitPortNotFound     = 3
+	agentExitPermissionDenied = 4
+	agentExitFaultActive      = 5
+	agentExitUnsupportedOS    = 6
+)
+
+var (
+	// ErrInvalidFault is returned when the agent rejects the fault because it is not valid
+	ErrInvalidFault = errors.New("invalid fault")
+	// ErrPortNotFound is returned when the target port of the fault is not found in the target
+	ErrPortNotFound = errors.New("target port not found")
+	// ErrPermissionDenied is returned when the agent lacks the privileges for applying the fault
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrFaultActive is returned when another fault is being applied in the target
+	ErrFaultActive = errors.New("another fault is active in the target")
+	// ErrUnsupportedOS is returned when the agent does not support the operating system of the target
+	ErrUnsupportedOS = errors.New("unsupported operating system")
+	// ErrHostNetwork is returned when the fault cannot be safely injected because the target uses the network of the
+	// node and the injection in such targets was not explicitly allowed
+	ErrHostNetwork = errors.New("target uses hostNetwork")
+)
+
+// AgentError is the error of a command executed by the agent. Its reason, if known, can be checked with errors.Is
+// against ErrInvalidFault, ErrPortNotFound, ErrPermissionDenied, ErrFaultActive and ErrUnsupportedOS.
+type AgentError struct {
+	// Reason of the failure, or nil if it is not known
+	Reason error
+	// ExitCode of the agent command. It is zero if the command did not complete (e.g. the connection failed).
+	ExitCode int
+	// Message is the error reported by the agent
+	Message string
+	// Err is the error of the execution of the command
+	Err error
+}
+
+// Error implements the error interface
+func (e *AgentError) Error() string {
+	if e.Message == "" {
+		return e.Err.Error()
+	}
+
+	return e.Err.Error() + " \n" + e.Message
+}
+
+// Unwrap returns the reason of the failure and the error of the execution of the command
+func (e *AgentError) Unwrap() []error {
+	if e.Reason == nil {
+		return []error{e.Err}
+	}
+
+	return []error{e.Reason, e.Err}
+}
+
+// newAgentError returns the error of an agent command from its exit code and the error it reported in stderr
+func newAgentError(err error, stderr []byte) *AgentError {
+	agentErr := &AgentError{
+		Message: strings.TrimSpace(string(stderr)),
+		Err:     err,
+	}
+
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) {
+		agentErr.ExitCode = exitErr.ExitStatus()
+	}
+
+	agentErr.Reason = agentErrorReason(agentErr.ExitCode, agentErr.Message)
+
+	return agentErr
+}
+
+// agentErrorReason returns the reason of the failure of an agent command. Agents that do not report the reason in
+// their exit code are identified by their message.
+func agentErrorReason(exitCode int, message string) error {
+	switch exitCode {
+	case agentExitInvalidFault:
+		return ErrInvalidFault
+	case agentExitPortNotFound:
+		return ErrPortNotFound
+	case agentExitPermissionDenied:
+		return ErrPermissionDenied
+	case agentExitFaultActive:
+		return ErrFaultActive
+	case agentExitUnsupportedOS:
+		return ErrUnsupportedOS
+	}
+
+	switch {
+	case strings.Contains(message, "another instance of the agent is already running"):
+		return ErrFaultActive
+	case strings.Contains(message, "Permission denied"), strings.Contains(message, "Operation not permitted"):
+		return ErrPermissionDenied
+	default:
+		return nil
+	}
+}
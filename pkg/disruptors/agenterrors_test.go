@@ -0,0 +1,80 @@
+package disruptors
+
+import (
+	"errors"
+	"testing"
+
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func Test_AgentError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		err      error
+		stderr   []byte
+		expected error
+	}{
+		{
+			title:    "unknown reason",
+			err:      utilexec.CodeExitError{Err: errFailed, Code: 1},
+			stderr:   []byte("proxy ended with error"),
+			expected: nil,
+		},
+		{
+			title:    "invalid fault",
+			err:      utilexec.CodeExitError{Err: errFailed, Code: agentExitInvalidFault},
+			stderr:   []byte("invalid fault: target port for fault injection is required"),
+			expected: ErrInvalidFault,
+		},
+		{
+			title:    "port not found",
+			err:      utilexec.CodeExitError{Err: errFailed, Code: agentExitPortNotFound},
+			expected: ErrPortNotFound,
+		},
+		{
+			title:    "permission denied",
+			err:      utilexec.CodeExitError{Err: errFailed, Code: agentExitPermissionDenied},
+			expected: ErrPermissionDenied,
+		},
+		{
+			title:    "fault active",
+			err:      utilexec.CodeExitError{Err: errFailed, Code: agentExitFaultActive},
+			expected: ErrFaultActive,
+		},
+		{
+			title:    "fault active reported by previous agent versions",
+			err:      utilexec.CodeExitError{Err: errFailed, Code: 1},
+			stderr:   []byte("initializing agent: another instance of the agent is already running"),
+			expected: ErrFaultActive,
+		},
+		{
+			title:    "permission denied reported by previous agent versions",
+			err:      errFailed,
+			stderr:   []byte(`adding rules: exit status 4: "iptables: Permission denied (you must be root)"`),
+			expected: ErrPermissionDenied,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			agentErr := newAgentError(tc.err, tc.stderr)
+			if !errors.Is(agentErr, tc.err) {
+				t.Fatalf("agent error should wrap the execution error")
+			}
+
+			if !errors.Is(agentErr.Reason, tc.expected) {
+				t.Fatalf("expected reason %v returned %v", tc.expected, agentErr.Reason)
+			}
+
+			if tc.expected != nil && !errors.Is(agentErr, tc.expected) {
+				t.Fatalf("agent error should wrap its reason")
+			}
+		})
+	}
+}
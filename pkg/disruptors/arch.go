@@ -0,0 +1,82 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/xk6-disruptor/pkg/internal/version"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// supportedAgentArchitecture returns if the architecture is supported by the agent image of this version of the
+// extension
+func supportedAgentArchitecture(arch string) bool {
+	switch arch {
+	case "amd64", "arm64":
+		return true
+	default:
+		return false
+	}
+}
+
+// validateAgentImages returns an error if the agent images per architecture are not valid
+func validateAgentImages(images map[string]string) error {
+	for arch, image := range images {
+		if arch == "" || image == "" {
+			return fmt.Errorf("agentImages must map architectures (e.g. arm64) to images")
+		}
+	}
+
+	return nil
+}
+
+// agentImage returns the image of the agent for the architecture of the node the pod runs in. The image of the
+// extension is a multi-architecture image, therefore the architecture is only verified to be one of the
+// architectures it supports.
+func (c *PodAgentVisitor) agentImage(ctx context.Context, pod corev1.Pod) (string, error) {
+	defaultImage := c.options.Image == version.AgentImage()
+	if (len(c.options.Images) == 0 && !defaultImage) || pod.Spec.NodeName == "" {
+		return c.options.Image, nil
+	}
+
+	arch, err := c.helper.NodeArchitecture(ctx, pod.Spec.NodeName)
+	if err != nil || arch == "" {
+		// the node may not be accessible (e.g. the user is not allowed to get nodes). The image is used
+		// regardless of the architecture and the injection fails if the image does not support it.
+		return c.options.Image, nil //nolint:nilerr
+	}
+
+	if image, found := c.options.Images[arch]; found {
+		return image, nil
+	}
+
+	if defaultImage && !supportedAgentArchitecture(arch) {
+		return "", fmt.Errorf(
+			"the agent image %q does not support the architecture %q of the node %q."+
+				" Use the agentImages option for setting the image for this architecture",
+			c.options.Image,
+			arch,
+			pod.Spec.NodeName,
+		)
+	}
+
+	return c.options.Image, nil
+}
+
+// architectureError returns an explicit error if the agent failed to start because its image does not support the
+// architecture of the node
+func architectureError(err error, image string, pod corev1.Pod) error {
+	if err == nil || !strings.Contains(err.Error(), "exec format error") {
+		return err
+	}
+
+	return fmt.Errorf(
+		"the agent image %q does not support the architecture of the node %q."+
+			" Use the agentImages option for setting the image for this architecture: %w",
+		image,
+		pod.Spec.NodeName,
+		err,
+	)
+}
@@ -0,0 +1,99 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/internal/version"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_AgentImage(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		arch        string
+		image       string
+		images      map[string]string
+		expectError bool
+		expected    string
+	}{
+		{
+			title:       "default image in supported architecture",
+			arch:        "arm64",
+			image:       version.AgentImage(),
+			expectError: false,
+			expected:    version.AgentImage(),
+		},
+		{
+			title:       "default image in unsupported architecture",
+			arch:        "s390x",
+			image:       version.AgentImage(),
+			expectError: true,
+		},
+		{
+			title:       "image for the architecture",
+			arch:        "s390x",
+			image:       version.AgentImage(),
+			images:      map[string]string{"s390x": "registry.local/xk6-disruptor-agent:s390x"},
+			expectError: false,
+			expected:    "registry.local/xk6-disruptor-agent:s390x",
+		},
+		{
+			title:       "custom image in any architecture",
+			arch:        "s390x",
+			image:       "registry.local/xk6-disruptor-agent:v1",
+			images:      map[string]string{"arm64": "registry.local/xk6-disruptor-agent:arm64"},
+			expectError: false,
+			expected:    "registry.local/xk6-disruptor-agent:v1",
+		},
+		{
+			title:       "node not accessible",
+			arch:        "",
+			image:       version.AgentImage(),
+			expectError: false,
+			expected:    version.AgentImage(),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").WithNodeName("node1").Build()
+			objs := []runtime.Object{&pod}
+			if tc.arch != "" {
+				node := builders.NewNodeBuilder("node1").WithLabel("kubernetes.io/arch", tc.arch).Build()
+				objs = append(objs, &node)
+			}
+
+			client := fake.NewSimpleClientset(objs...)
+			helper := helpers.NewPodHelper(client, helpers.NewFakePodCommandExecutor(), "test-ns")
+			visitor := NewPodAgentVisitor(
+				helper,
+				PodAgentVisitorOptions{Image: tc.image, Images: tc.images},
+				visitCommands(),
+			)
+
+			image, err := visitor.agentImage(context.TODO(), pod)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if image != tc.expected {
+				t.Fatalf("expected image %q returned %q", tc.expected, image)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// minChaosFaultDuration is the minimum duration of a random fault, as the agent accepts durations in seconds
+const minChaosFaultDuration = time.Second
+
+// ChaosSpec defines the faults and bounds for injecting random faults in random subsets of targets
+type ChaosSpec struct {
+	// Faults that can be injected. Each time, one of the faults is selected at random.
+	Faults ComposedFaults
+	// MinFaultDuration is the minimum duration of each fault. Defaults to 1s.
+	MinFaultDuration time.Duration `js:"minFaultDuration"`
+	// MaxFaultDuration is the maximum duration of each fault
+	MaxFaultDuration time.Duration `js:"maxFaultDuration"`
+	// MinInterval is the minimum time between faults
+	MinInterval time.Duration `js:"minInterval"`
+	// MaxInterval is the maximum time between faults
+	MaxInterval time.Duration `js:"maxInterval"`
+	// MaxPercentage of the targets affected by each fault. Each fault affects a random percentage of the
+	// targets up to this value. A zero value allows affecting all targets.
+	MaxPercentage int `js:"maxPercentage"`
+}
+
+func (s ChaosSpec) validate() error {
+	candidates := s.candidates()
+	if len(candidates) == 0 {
+		return fmt.Errorf("at least one fault must be specified")
+	}
+
+	for _, faults := range candidates {
+		if err := faults.validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.MinFaultDuration < 0 || s.MaxFaultDuration < max(s.MinFaultDuration, minChaosFaultDuration) {
+		return fmt.Errorf("maxFaultDuration must be at least 1s and not lower than minFaultDuration")
+	}
+
+	if s.MinInterval < 0 || s.MaxInterval < s.MinInterval {
+		return fmt.Errorf("maxInterval cannot be lower than minInterval")
+	}
+
+	if s.MaxPercentage < 0 || s.MaxPercentage > 100 {
+		return fmt.Errorf("maxPercentage must be between 0 and 100: %d", s.MaxPercentage)
+	}
+
+	return nil
+}
+
+// candidates returns each of the faults that can be selected
+func (s ChaosSpec) candidates() []ComposedFaults {
+	candidates := []ComposedFaults{}
+	for _, spec := range s.Faults.HTTP {
+		candidates = append(candidates, ComposedFaults{HTTP: []HTTPFaultSpec{spec}})
+	}
+	for _, spec := range s.Faults.Grpc {
+		candidates = append(candidates, ComposedFaults{Grpc: []GrpcFaultSpec{spec}})
+	}
+
+	return candidates
+}
+
+// chaosInjectFunc injects the faults in the given percentage of the targets for the given duration
+type chaosInjectFunc func(ctx context.Context, faults ComposedFaults, percentage int, duration time.Duration) error
+
+// runChaos injects faults selected at random from the spec at random times until the duration elapses.
+// Faults are injected one at a time. The random choices are made using the sampler, so they are reproducible
+// if the sampler has a seed.
+func runChaos(
+	ctx context.Context,
+	spec ChaosSpec,
+	duration time.Duration,
+	sampler *targetSampler,
+	inject chaosInjectFunc,
+) error {
+	minFaultDuration := max(spec.MinFaultDuration, minChaosFaultDuration)
+	maxPercentage := spec.MaxPercentage
+	if maxPercentage == 0 {
+		maxPercentage = 100
+	}
+
+	candidates := spec.candidates()
+	deadline := time.Now().Add(duration)
+	for {
+		interval := sampler.duration(spec.MinInterval, spec.MaxInterval)
+		// the fault must end before the deadline
+		available := time.Until(deadline) - interval
+		if available < minFaultDuration {
+			return waitFor(ctx, time.Until(deadline))
+		}
+
+		faultDuration := min(sampler.duration(minFaultDuration, spec.MaxFaultDuration), available)
+		faults := candidates[sampler.intn(len(candidates))]
+		percentage := 1 + sampler.intn(maxPercentage)
+
+		if err := waitFor(ctx, interval); err != nil {
+			return err
+		}
+
+		// the agent accepts durations in seconds
+		err := inject(ctx, faults, percentage, faultDuration.Truncate(time.Second))
+		if err != nil && !errors.Is(err, ErrSelectorNoPods) && !errors.Is(err, ErrServiceNoTargets) {
+			return err
+		}
+	}
+}
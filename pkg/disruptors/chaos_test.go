@@ -0,0 +1,135 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+)
+
+func Test_ChaosSpecValidation(t *testing.T) {
+	t.Parallel()
+
+	faults := ComposedFaults{
+		HTTP: []HTTPFaultSpec{{Fault: HTTPFault{AverageDelay: 100 * time.Millisecond, Port: intstr.FromInt32(80)}}},
+	}
+
+	testCases := []struct {
+		title       string
+		spec        ChaosSpec
+		expectError bool
+	}{
+		{
+			title:       "valid spec",
+			spec:        ChaosSpec{Faults: faults, MaxFaultDuration: time.Minute, MaxInterval: time.Minute},
+			expectError: false,
+		},
+		{
+			title:       "no faults",
+			spec:        ChaosSpec{MaxFaultDuration: time.Minute},
+			expectError: true,
+		},
+		{
+			title:       "no max fault duration",
+			spec:        ChaosSpec{Faults: faults},
+			expectError: true,
+		},
+		{
+			title: "max fault duration lower than min",
+			spec: ChaosSpec{
+				Faults:           faults,
+				MinFaultDuration: time.Minute,
+				MaxFaultDuration: 30 * time.Second,
+			},
+			expectError: true,
+		},
+		{
+			title: "max interval lower than min",
+			spec: ChaosSpec{
+				Faults:           faults,
+				MaxFaultDuration: time.Minute,
+				MinInterval:      time.Minute,
+			},
+			expectError: true,
+		},
+		{
+			title:       "invalid max percentage",
+			spec:        ChaosSpec{Faults: faults, MaxFaultDuration: time.Minute, MaxPercentage: 120},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.spec.validate()
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+		})
+	}
+}
+
+func Test_RunChaos(t *testing.T) {
+	t.Parallel()
+
+	spec := ChaosSpec{
+		Faults: ComposedFaults{
+			HTTP: []HTTPFaultSpec{{Fault: HTTPFault{AverageDelay: 100 * time.Millisecond}}},
+			Grpc: []GrpcFaultSpec{{Fault: GrpcFault{ErrorRate: 0.1}}},
+		},
+		MaxFaultDuration: 2 * time.Second,
+		MaxInterval:      500 * time.Millisecond,
+		MaxPercentage:    50,
+	}
+
+	duration := 4 * time.Second
+	start := time.Now()
+	injected := 0
+	inject := func(_ context.Context, faults ComposedFaults, percentage int, faultDuration time.Duration) error {
+		injected++
+
+		if len(faults.HTTP)+len(faults.Grpc) != 1 {
+			t.Errorf("expected one fault got %d", len(faults.HTTP)+len(faults.Grpc))
+		}
+
+		if percentage < 1 || percentage > spec.MaxPercentage {
+			t.Errorf("percentage %d out of bounds", percentage)
+		}
+
+		if faultDuration < time.Second || faultDuration > spec.MaxFaultDuration {
+			t.Errorf("fault duration %s out of bounds", faultDuration)
+		}
+
+		if time.Since(start)+faultDuration > duration {
+			t.Errorf("fault of %s injected at %s exceeds the duration", faultDuration, time.Since(start))
+		}
+
+		// simulate the fault being applied
+		time.Sleep(faultDuration)
+
+		return nil
+	}
+
+	err := runChaos(context.TODO(), spec, duration, newTargetSampler(1), inject)
+	if err != nil {
+		t.Fatalf("failed unexpectedly: %v", err)
+	}
+
+	if injected == 0 {
+		t.Fatalf("no fault was injected")
+	}
+
+	elapsed := time.Since(start)
+	if elapsed < duration {
+		t.Fatalf("expected to run for %s but completed after %s", duration, elapsed)
+	}
+}
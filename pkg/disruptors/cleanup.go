@@ -0,0 +1,178 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/grafana/xk6-disruptor/pkg/internal/version"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// RunIDEnvVar is the environment variable that defines the ID of the test run. If it is not defined, the ID of the
+// test run in Grafana Cloud k6 is used or, for local test runs, a random ID is generated. The ID is added to the
+// agents injected during the test run for cleaning them up if the test crashes.
+const RunIDEnvVar = "XK6_DISRUPTOR_RUN_ID"
+
+// cloudRunIDEnvVar is the environment variable that defines the ID of the test runs executed in Grafana Cloud k6.
+// All the instances of a distributed test run share it.
+const cloudRunIDEnvVar = "K6_CLOUD_PUSH_REF_ID"
+
+// RunIDLabel is the label added to the agent pods with the ID of the test run that started them
+const RunIDLabel = "xk6-disruptor/run-id"
+
+// runID is the ID of the current test run. It is state of the process, as it is shared by all the disruptors
+// created by the scripts of the test run, and is set once.
+//
+//nolint:gochecknoglobals // the ID cannot be passed to the disruptors created independently by the scripts
+var (
+	runID     string
+	runIDOnce sync.Once
+)
+
+// TestRun describes the k6 test run the disruptor is executed in
+type TestRun struct {
+	// LookupEnv returns the environment variables of the test run, including those passed to k6 with the --env flag
+	LookupEnv func(key string) (string, bool)
+}
+
+// id returns the ID of the test run
+func (r TestRun) id() string {
+	lookupEnv := r.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	if id, found := lookupEnv(RunIDEnvVar); found && id != "" {
+		return id
+	}
+
+	if id, found := lookupEnv(cloudRunIDEnvVar); found && id != "" {
+		return "cloud-" + id
+	}
+
+	return rand.String(10)
+}
+
+// InitRunID sets the ID of the current test run from the given test run. It has no effect if the ID of the test run
+// was already set, as the ID must not change during the test run.
+func InitRunID(run TestRun) {
+	runIDOnce.Do(func() {
+		runID = run.id()
+	})
+}
+
+// RunID returns the ID of the current test run. If InitRunID was not called, the ID is taken from the environment of
+// the process.
+func RunID() string {
+	InitRunID(TestRun{})
+
+	return runID
+}
+
+// UserAgent returns the User-Agent of the requests to the Kubernetes API made in the current test run. It identifies
+// the versions of the disruptor and k6 and the ID of the test run, so the requests can be attributed to the test run
+// in the audit logs of the cluster.
+func UserAgent() string {
+	return version.UserAgent(RunID())
+}
+
+// agentEnv returns the environment of the agent containers
+func agentEnv() []corev1.EnvVar {
+	return []corev1.EnvVar{{Name: RunIDEnvVar, Value: RunID()}}
+}
+
+// agentCommand returns the command of the agent containers. The agent removes the resources left behind by the
+// faults if they terminate unexpectedly.
+func agentCommand() []string {
+	return []string{"xk6-disruptor-agent", "janitor"}
+}
+
+// isAgentOfRun returns if the pod runs an agent injected during the given test run
+func isAgentOfRun(pod corev1.Pod, runID string) bool {
+	if pod.Labels[RunIDLabel] == runID {
+		return true
+	}
+
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.Name != "xk6-agent" {
+			continue
+		}
+
+		for _, env := range container.Env {
+			if env.Name == RunIDEnvVar && env.Value == runID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// cleanupPageSize is the maximum number of pods requested in each page when looking for the agents to clean up
+const cleanupPageSize = 500
+
+// Cleanup stops the faults and removes the resources left behind by the agents injected during the given test run,
+// for example, by a test that crashed. The pods that run the agent in nodes are deleted.
+// Cleanup continues with the remaining pods if cleaning up a pod fails, and returns the errors of all the pods.
+// Returns the pods cleaned up, as namespace/name.
+func Cleanup(ctx context.Context, k8s kubernetes.Kubernetes, runID string) ([]string, error) {
+	if runID == "" {
+		return nil, fmt.Errorf("run ID is required")
+	}
+
+	var errs []error
+	cleaned := []string{}
+	options := metav1.ListOptions{Limit: cleanupPageSize}
+	for {
+		pods, err := k8s.Client().CoreV1().Pods(metav1.NamespaceAll).List(ctx, options)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing pods: %w", err))
+			return cleaned, errors.Join(errs...)
+		}
+
+		for _, pod := range pods.Items {
+			if !isAgentOfRun(pod, runID) {
+				continue
+			}
+
+			if err = cleanupAgent(ctx, k8s, pod, runID); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			cleaned = append(cleaned, podKey(pod))
+		}
+
+		if pods.Continue == "" {
+			return cleaned, errors.Join(errs...)
+		}
+		options.Continue = pods.Continue
+	}
+}
+
+// cleanupAgent stops the faults of the agent running in the pod and deletes the pod if it was created for the agent
+func cleanupAgent(ctx context.Context, k8s kubernetes.Kubernetes, pod corev1.Pod, runID string) error {
+	helper := k8s.PodHelper(pod.Namespace)
+	_, stderr, err := helper.Exec(ctx, pod.Name, "xk6-agent", buildCleanupCmd(), []byte{})
+	if err != nil {
+		return fmt.Errorf("cleaning up agent in pod %q: %w \n%s", pod.Name, err, string(stderr))
+	}
+
+	if pod.Labels[RunIDLabel] != runID {
+		return nil
+	}
+
+	err = k8s.Client().CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+	if err != nil {
+		return fmt.Errorf("deleting agent pod %q: %w", pod.Name, err)
+	}
+
+	return nil
+}
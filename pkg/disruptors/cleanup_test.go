@@ -0,0 +1,156 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_Cleanup(t *testing.T) {
+	t.Parallel()
+
+	agent := func(runID string) corev1.EphemeralContainer {
+		return corev1.EphemeralContainer{
+			EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+				Name: "xk6-agent",
+				Env:  []corev1.EnvVar{{Name: RunIDEnvVar, Value: runID}},
+			},
+		}
+	}
+
+	target := builders.NewPodBuilder("target").WithNamespace("ns1").Build()
+	target.Spec.EphemeralContainers = []corev1.EphemeralContainer{agent("run-1")}
+
+	other := builders.NewPodBuilder("other").WithNamespace("ns2").Build()
+	other.Spec.EphemeralContainers = []corev1.EphemeralContainer{agent("run-2")}
+
+	nodeAgent := builders.NewPodBuilder("xk6-agent-node1").
+		WithNamespace("ns1").
+		WithLabel(RunIDLabel, "run-1").
+		Build()
+
+	noAgent := builders.NewPodBuilder("no-agent").WithNamespace("ns1").Build()
+
+	client := fake.NewSimpleClientset(&target, &other, &nodeAgent, &noAgent)
+	k8s, _ := kubernetes.NewFakeKubernetes(client)
+
+	cleaned, err := Cleanup(context.TODO(), k8s, "run-1")
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	sort.Strings(cleaned)
+	if diff := cmp.Diff([]string{"ns1/target", "ns1/xk6-agent-node1"}, cleaned); diff != "" {
+		t.Fatalf("expected cleaned pods do not match\n%s", diff)
+	}
+
+	history := k8s.GetFakeProcessExecutor().GetHistory()
+	for _, cmd := range history {
+		if diff := cmp.Diff(buildCleanupCmd(), cmd.Command); diff != "" {
+			t.Fatalf("expected cleanup command:\n%s", diff)
+		}
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 cleanup commands got %d", len(history))
+	}
+
+	// the pods that run the agent in nodes are deleted
+	pods, err := client.CoreV1().Pods("ns1").List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("listing pods: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Fatalf("expected 2 pods in namespace got %d", len(pods.Items))
+	}
+}
+
+func Test_CleanupContinuesOnError(t *testing.T) {
+	t.Parallel()
+
+	pods := []runtime.Object{}
+	for _, name := range []string{"pod-1", "pod-2"} {
+		pod := builders.NewPodBuilder(name).WithNamespace("ns1").WithLabel(RunIDLabel, "run-1").Build()
+		pods = append(pods, &pod)
+	}
+
+	client := fake.NewSimpleClientset(pods...)
+	k8s, _ := kubernetes.NewFakeKubernetes(client)
+	k8s.GetFakeProcessExecutor().SetResult(nil, nil, fmt.Errorf("exec failed"))
+
+	cleaned, err := Cleanup(context.TODO(), k8s, "run-1")
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+
+	if len(cleaned) != 0 {
+		t.Fatalf("expected no pods cleaned got %v", cleaned)
+	}
+
+	if history := k8s.GetFakeProcessExecutor().GetHistory(); len(history) != 2 {
+		t.Fatalf("expected 2 cleanup commands got %d", len(history))
+	}
+}
+
+func Test_TestRunID(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		env      map[string]string
+		expected string
+	}{
+		{
+			title:    "run ID defined",
+			env:      map[string]string{RunIDEnvVar: "run-1", cloudRunIDEnvVar: "1234"},
+			expected: "run-1",
+		},
+		{
+			title:    "cloud test run",
+			env:      map[string]string{cloudRunIDEnvVar: "1234"},
+			expected: "cloud-1234",
+		},
+		{
+			title:    "local test run",
+			env:      map[string]string{},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			run := TestRun{
+				LookupEnv: func(key string) (string, bool) {
+					value, found := tc.env[key]
+					return value, found
+				},
+			}
+
+			id := run.id()
+			if tc.expected == "" {
+				// a random ID is generated
+				if len(id) != 10 || id == run.id() {
+					t.Fatalf("expected a random ID got %q", id)
+				}
+				return
+			}
+
+			if id != tc.expected {
+				t.Fatalf("expected %q got %q", tc.expected, id)
+			}
+		})
+	}
+}
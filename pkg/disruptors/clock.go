@@ -0,0 +1,43 @@
+package disruptors
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ClockSkewFault specifies a fault that shifts the clock of a node
+type ClockSkewFault struct {
+	// Offset added to the node's clock. Negative values set the clock back in time
+	Offset time.Duration
+}
+
+func buildClockSkewCmd(fault ClockSkewFault, duration time.Duration) []string {
+	return []string{
+		"xk6-disruptor-agent",
+		"clock-skew",
+		"-d", utils.DurationSeconds(duration),
+		// use the explicit form to prevent negative offsets from being parsed as flags
+		"--offset=" + fault.Offset.String(),
+	}
+}
+
+// NodeClockSkewCommand implements the NodeVisitCommand interface for shifting the clock of a Node
+type NodeClockSkewCommand struct {
+	fault    ClockSkewFault
+	duration time.Duration
+}
+
+// Commands return the command for shifting the clock of a Node
+func (c NodeClockSkewCommand) Commands(_ corev1.Node) (VisitCommands, error) {
+	if c.fault.Offset == 0 {
+		return VisitCommands{}, errors.New("clock offset cannot be zero")
+	}
+
+	return VisitCommands{
+		Exec:    buildClockSkewCmd(c.fault, c.duration),
+		Cleanup: buildCleanupCmd(),
+	}, nil
+}
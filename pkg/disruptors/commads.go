@@ -1,7 +1,9 @@
 package disruptors
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
@@ -55,12 +57,20 @@ func buildGrpcFaultCmd(
 		cmd = append(cmd, "-x", fault.Exclude)
 	}
 
+	if fault.RampDuration > 0 {
+		cmd = append(cmd, "--ramp-duration", utils.DurationSeconds(fault.RampDuration))
+	}
+
 	if options.ProxyPort != 0 {
 		cmd = append(cmd, "-p", fmt.Sprint(options.ProxyPort))
 	}
 
 	cmd = append(cmd, "--upstream-host", targetAddress)
 
+	if options.AllowHostNetwork {
+		cmd = append(cmd, "--host-network")
+	}
+
 	return cmd
 }
 
@@ -108,19 +118,186 @@ func buildHTTPFaultCmd(
 		cmd = append(cmd, "-x", fault.Exclude)
 	}
 
+	if fault.RampDuration > 0 {
+		cmd = append(cmd, "--ramp-duration", utils.DurationSeconds(fault.RampDuration))
+	}
+
 	if options.ProxyPort != 0 {
 		cmd = append(cmd, "-p", fmt.Sprint(options.ProxyPort))
 	}
 
 	cmd = append(cmd, "--upstream-host", targetAddress)
 
+	if options.AllowHostNetwork {
+		cmd = append(cmd, "--host-network")
+	}
+
+	return append(cmd, proxyArgs(options)...)
+}
+
+// proxyArgs returns the arguments that configure the connections of the agent's proxy
+func proxyArgs(options HTTPDisruptionOptions) []string {
+	args := []string{}
+	if options.DisableUpstreamKeepAlive {
+		args = append(args, "--upstream-keep-alive=false")
+	}
+
+	if options.UpstreamMaxIdleConns > 0 {
+		args = append(args, "--upstream-max-idle-conns", fmt.Sprint(options.UpstreamMaxIdleConns))
+	}
+
+	if options.UpstreamMaxConns > 0 {
+		args = append(args, "--upstream-max-conns", fmt.Sprint(options.UpstreamMaxConns))
+	}
+
+	if options.UpstreamIdleTimeout > 0 {
+		args = append(args, "--upstream-idle-timeout", utils.DurationSeconds(options.UpstreamIdleTimeout))
+	}
+
+	if options.UpstreamDialTimeout > 0 {
+		args = append(args, "--upstream-dial-timeout", utils.DurationMillSeconds(options.UpstreamDialTimeout))
+	}
+
+	if options.UpstreamResponseTimeout > 0 {
+		args = append(args, "--upstream-response-timeout", utils.DurationMillSeconds(options.UpstreamResponseTimeout))
+	}
+
+	if options.ReadBufferSize > 0 {
+		args = append(args, "--read-buffer-size", fmt.Sprint(options.ReadBufferSize))
+	}
+
+	if options.WriteBufferSize > 0 {
+		args = append(args, "--write-buffer-size", fmt.Sprint(options.WriteBufferSize))
+	}
+
+	if options.MaxHeaderBytes > 0 {
+		args = append(args, "--max-header-bytes", fmt.Sprint(options.MaxHeaderBytes))
+	}
+
+	if options.MaxConnections > 0 {
+		args = append(args, "--max-conns", fmt.Sprint(options.MaxConnections))
+	}
+
+	if options.AccessLog {
+		args = append(args, "--access-log")
+	}
+
+	return append(args, tlsArgs(options.TLS)...)
+}
+
+// tlsArgs returns the arguments of the http command for handling the traffic of targets that serve TLS
+func tlsArgs(options TLSOptions) []string {
+	if options.Mode == "" {
+		return []string{}
+	}
+
+	args := []string{"--tls-mode", options.Mode}
+	for _, arg := range []struct {
+		flag  string
+		value string
+	}{
+		{"--tls-cert", options.Cert},
+		{"--tls-key", options.Key},
+		{"--tls-ca", options.CA},
+		{"--tls-server-name", options.ServerName},
+		{"--tls-client-cert", options.ClientCert},
+		{"--tls-client-key", options.ClientKey},
+	} {
+		if arg.value != "" {
+			args = append(args, arg.flag, arg.value)
+		}
+	}
+
+	return args
+}
+
+func buildComposeCmd(duration time.Duration, faults [][]string) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"compose",
+		"-d", utils.DurationSeconds(duration),
+	}
+
+	// the arguments of each fault command follow a '--' separator
+	for _, fault := range faults {
+		cmd = append(cmd, "--")
+		cmd = append(cmd, fault[1:]...)
+	}
+
 	return cmd
 }
 
+func buildTimelineCmd(duration time.Duration, steps [][]string) ([]string, error) {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"timeline",
+		"-d", utils.DurationSeconds(duration),
+	}
+
+	// each step is passed as a JSON array with the arguments of its fault command
+	for _, step := range steps {
+		args, err := json.Marshal(step[1:])
+		if err != nil {
+			return nil, err
+		}
+		cmd = append(cmd, "--step", string(args))
+	}
+
+	return cmd, nil
+}
+
+func buildRepeatCmd(duration time.Duration, every time.Duration, fault []string) ([]string, error) {
+	// the fault is passed as a JSON array with the arguments of its fault command
+	args, err := json.Marshal(fault[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{
+		"xk6-disruptor-agent",
+		"repeat",
+		"-d", utils.DurationSeconds(duration),
+		"--every", utils.DurationSeconds(every),
+		"--fault", string(args),
+	}, nil
+}
+
 func buildCleanupCmd() []string {
 	return []string{"xk6-disruptor-agent", "cleanup"}
 }
 
+func buildStopCmd() []string {
+	return []string{"xk6-disruptor-agent", "stop"}
+}
+
+func buildShutdownCmd() []string {
+	return []string{"xk6-disruptor-agent", "shutdown"}
+}
+
+func buildStatusCmd() []string {
+	return []string{"xk6-disruptor-agent", "status"}
+}
+
+func buildAccessLogCmd() []string {
+	return []string{"xk6-disruptor-agent", "access-log"}
+}
+
+func buildVerifyCmd() []string {
+	return []string{"xk6-disruptor-agent", "verify"}
+}
+
+func buildReadyCmd() []string {
+	return []string{"xk6-disruptor-agent", "ready"}
+}
+
+func buildHealthCmd() []string {
+	return []string{"xk6-disruptor-agent", "health"}
+}
+
+func buildVersionCmd() []string {
+	return []string{"xk6-disruptor-agent", "version"}
+}
+
 // PodHTTPFaultCommand implements the PodVisitCommands interface for injecting
 // HttpFaults in a Pod
 type PodHTTPFaultCommand struct {
@@ -131,14 +308,18 @@ type PodHTTPFaultCommand struct {
 
 // Commands return the command for injecting a HttpFault in a Pod
 func (c PodHTTPFaultCommand) Commands(pod corev1.Pod) (VisitCommands, error) {
-	if utils.HasHostNetwork(pod) {
-		return VisitCommands{}, fmt.Errorf("fault cannot be safely injected because pod %q uses hostNetwork", pod.Name)
+	if utils.HasHostNetwork(pod) && !c.options.AllowHostNetwork {
+		return VisitCommands{}, hostNetworkError(pod)
+	}
+
+	if len(c.fault.Ports) > 0 {
+		return c.portsCommands(pod)
 	}
 
 	// find the container port for fault injection
-	port, err := utils.FindPort(c.fault.Port, pod)
+	port, err := findTargetPort(c.fault.Port, pod)
 	if err != nil {
-		return VisitCommands{}, err
+		return VisitCommands{}, fmt.Errorf("%w: %w", ErrPortNotFound, err)
 	}
 	podFault := c.fault
 	podFault.Port = port
@@ -148,9 +329,59 @@ func (c PodHTTPFaultCommand) Commands(pod corev1.Pod) (VisitCommands, error) {
 		return VisitCommands{}, err
 	}
 
+	istio, err := istioArgs(pod, uint(port.Int32()), c.options.ProxyPort)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	return VisitCommands{
+		Exec:     append(buildHTTPFaultCmd(targetAddress, podFault, c.duration, c.options), istio...),
+		Cleanup:  buildCleanupCmd(),
+		Duration: c.duration,
+	}, nil
+}
+
+// portsCommands returns the command for injecting the fault in each of the ports of the fault simultaneously.
+// Each port uses its own proxy, listening in consecutive ports starting from the proxy port in the options.
+func (c PodHTTPFaultCommand) portsCommands(pod corev1.Pod) (VisitCommands, error) {
+	ports, err := findPorts(c.fault.Ports, pod)
+	if err != nil {
+		return VisitCommands{}, fmt.Errorf("%w: %w", ErrPortNotFound, err)
+	}
+
+	proxyPort := c.options.ProxyPort
+	if proxyPort == 0 {
+		proxyPort = defaultProxyPort
+	}
+
+	faults := [][]string{}
+	for _, port := range ports {
+		// the proxies cannot listen in the ports the fault is injected in
+		for slices.Contains(ports, intstr.FromInt32(int32(proxyPort))) {
+			proxyPort++
+		}
+		if proxyPort > maxPort {
+			return VisitCommands{}, fmt.Errorf("not enough proxy ports for %d ports", len(ports))
+		}
+
+		fault := c.fault
+		fault.Port = port
+		fault.Ports = nil
+		options := c.options
+		options.ProxyPort = proxyPort
+		proxyPort++
+
+		commands, cmdErr := PodHTTPFaultCommand{fault: fault, duration: c.duration, options: options}.Commands(pod)
+		if cmdErr != nil {
+			return VisitCommands{}, cmdErr
+		}
+		faults = append(faults, commands.Exec)
+	}
+
 	return VisitCommands{
-		Exec:    buildHTTPFaultCmd(targetAddress, podFault, c.duration, c.options),
-		Cleanup: buildCleanupCmd(),
+		Exec:     buildComposeCmd(c.duration, faults),
+		Cleanup:  buildCleanupCmd(),
+		Duration: c.duration,
 	}, nil
 }
 
@@ -163,14 +394,14 @@ type PodGrpcFaultCommand struct {
 
 // Commands return the command for injecting a GrpcFault in a Pod
 func (c PodGrpcFaultCommand) Commands(pod corev1.Pod) (VisitCommands, error) {
-	if utils.HasHostNetwork(pod) {
-		return VisitCommands{}, fmt.Errorf("fault cannot be safely injected because pod %q uses hostNetwork", pod.Name)
+	if utils.HasHostNetwork(pod) && !c.options.AllowHostNetwork {
+		return VisitCommands{}, hostNetworkError(pod)
 	}
 
 	// find the container port for fault injection
-	port, err := utils.FindPort(c.fault.Port, pod)
+	port, err := findTargetPort(c.fault.Port, pod)
 	if err != nil {
-		return VisitCommands{}, err
+		return VisitCommands{}, fmt.Errorf("%w: %w", ErrPortNotFound, err)
 	}
 	podFault := c.fault
 	podFault.Port = port
@@ -180,8 +411,129 @@ func (c PodGrpcFaultCommand) Commands(pod corev1.Pod) (VisitCommands, error) {
 		return VisitCommands{}, err
 	}
 
+	istio, err := istioArgs(pod, uint(port.Int32()), c.options.ProxyPort)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	return VisitCommands{
+		Exec:     append(buildGrpcFaultCmd(targetAddress, podFault, c.duration, c.options), istio...),
+		Cleanup:  buildCleanupCmd(),
+		Duration: c.duration,
+	}, nil
+}
+
+// PodComposedFaultCommand implements the PodVisitCommands interface for injecting simultaneously
+// multiple protocol faults in a Pod
+type PodComposedFaultCommand struct {
+	faults   ComposedFaults
+	duration time.Duration
+}
+
+// Commands return the command for injecting the composed faults in a Pod
+func (c PodComposedFaultCommand) Commands(pod corev1.Pod) (VisitCommands, error) {
+	faultCmds := []PodVisitCommand{}
+	for _, spec := range c.faults.HTTP {
+		faultCmds = append(faultCmds, PodHTTPFaultCommand{fault: spec.Fault, duration: c.duration, options: spec.Options})
+	}
+	for _, spec := range c.faults.Grpc {
+		faultCmds = append(faultCmds, PodGrpcFaultCommand{fault: spec.Fault, duration: c.duration, options: spec.Options})
+	}
+
+	faults := [][]string{}
+	for _, faultCmd := range faultCmds {
+		commands, err := faultCmd.Commands(pod)
+		if err != nil {
+			return VisitCommands{}, err
+		}
+		faults = append(faults, commands.Exec)
+	}
+
+	return VisitCommands{
+		Exec:     buildComposeCmd(c.duration, faults),
+		Cleanup:  buildCleanupCmd(),
+		Duration: c.duration,
+	}, nil
+}
+
+// PodTimelineCommand implements the PodVisitCommands interface for injecting a sequence of protocol faults
+// in a Pod
+type PodTimelineCommand struct {
+	timeline FaultTimeline
+}
+
+// Commands return the command for injecting the faults of the timeline in a Pod
+func (c PodTimelineCommand) Commands(pod corev1.Pod) (VisitCommands, error) {
+	steps := [][]string{}
+	for _, step := range c.timeline {
+		commands, err := PodComposedFaultCommand{faults: step.Faults, duration: step.Duration}.Commands(pod)
+		if err != nil {
+			return VisitCommands{}, err
+		}
+		steps = append(steps, commands.Exec)
+	}
+
+	cmd, err := buildTimelineCmd(c.timeline.duration(), steps)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	return VisitCommands{
+		Exec:     cmd,
+		Cleanup:  buildCleanupCmd(),
+		Duration: c.timeline.duration(),
+	}, nil
+}
+
+// PodRepeatCommand implements the PodVisitCommands interface for repeating a fault periodically in a Pod
+type PodRepeatCommand struct {
+	command  PodVisitCommand
+	every    time.Duration
+	duration time.Duration
+}
+
+// Commands return the command for repeating the fault in a Pod
+func (c PodRepeatCommand) Commands(pod corev1.Pod) (VisitCommands, error) {
+	commands, err := c.command.Commands(pod)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	cmd, err := buildRepeatCmd(c.duration, c.every, commands.Exec)
+	if err != nil {
+		return VisitCommands{}, err
+	}
+
+	return VisitCommands{
+		Exec:     cmd,
+		Cleanup:  commands.Cleanup,
+		Duration: c.duration,
+	}, nil
+}
+
+func buildKubeletRestartCmd(fault KubeletRestartFault, duration time.Duration) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"kubelet-restart",
+		"-d", utils.DurationSeconds(duration),
+	}
+
+	if fault.Service != "" {
+		cmd = append(cmd, "-s", fault.Service)
+	}
+
+	return cmd
+}
+
+// NodeKubeletRestartCommand implements the NodeVisitCommand interface for restarting the kubelet in a Node
+type NodeKubeletRestartCommand struct {
+	fault    KubeletRestartFault
+	duration time.Duration
+}
+
+// Commands return the command for restarting the kubelet in a Node
+func (c NodeKubeletRestartCommand) Commands(_ corev1.Node) (VisitCommands, error) {
 	return VisitCommands{
-		Exec:    buildGrpcFaultCmd(targetAddress, c.fault, c.duration, c.options),
-		Cleanup: buildCleanupCmd(),
+		Exec: buildKubeletRestartCmd(c.fault, c.duration),
 	}, nil
 }
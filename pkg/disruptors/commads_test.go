@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/xk6-disruptor/pkg/testutils/command"
 	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
 	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
@@ -83,6 +84,20 @@ func Test_PodHTTPFaultCommandGenerator(t *testing.T) {
 			opts:     HTTPDisruptionOptions{},
 			duration: 60 * time.Second,
 		},
+		{
+			title:       "Test ramp duration",
+			target:      buildPodWithPort("my-app-pod", "http", 80),
+			expectedCmd: "xk6-disruptor-agent http -d 60s -t 80 -a 100ms -v 0ms --ramp-duration 30s --upstream-host 192.0.2.6",
+			expectError: false,
+			cmdError:    nil,
+			fault: HTTPFault{
+				AverageDelay: 100 * time.Millisecond,
+				Port:         intstr.FromInt32(80),
+				RampDuration: 30 * time.Second,
+			},
+			opts:     HTTPDisruptionOptions{},
+			duration: 60 * time.Second,
+		},
 		{
 			title:       "Test exclude list",
 			target:      buildPodWithPort("my-app-pod", "http", 80),
@@ -147,6 +162,192 @@ func Test_PodHTTPFaultCommandGenerator(t *testing.T) {
 			opts:     HTTPDisruptionOptions{},
 			duration: 60,
 		},
+		{
+			title: "Pod with hostNetwork allowed",
+			target: builders.NewPodBuilder("hostnet").
+				WithNamespace("test-ns").
+				WithLabel("app", "myapp").
+				WithHostNetwork(true).
+				WithIP("192.0.2.6").
+				WithContainer(
+					builders.NewContainerBuilder("myapp").
+						WithPort("http", 80).
+						Build(),
+				).
+				Build(),
+			expectedCmd: "xk6-disruptor-agent http -d 60s -t 80 --upstream-host 192.0.2.6 --host-network",
+			expectError: false,
+			fault: HTTPFault{
+				Port: intstr.FromInt32(80),
+			},
+			opts:     HTTPDisruptionOptions{AllowHostNetwork: true},
+			duration: 60 * time.Second,
+		},
+		{
+			title: "Upstream connections",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithContainer(builders.NewContainerBuilder("myapp").WithPort("http", 80).Build()).
+				Build(),
+			expectedCmd: "xk6-disruptor-agent http -d 60s -t 80 --upstream-host 192.0.2.6" +
+				" --upstream-keep-alive=false --upstream-max-conns 10 --upstream-idle-timeout 30s" +
+				" --upstream-dial-timeout 2000ms --upstream-response-timeout 500ms",
+			expectError: false,
+			fault: HTTPFault{
+				Port: intstr.FromInt32(80),
+			},
+			opts: HTTPDisruptionOptions{
+				DisableUpstreamKeepAlive: true,
+				UpstreamMaxConns:         10,
+				UpstreamIdleTimeout:      30 * time.Second,
+				UpstreamDialTimeout:      2 * time.Second,
+				UpstreamResponseTimeout:  500 * time.Millisecond,
+			},
+			duration: 60 * time.Second,
+		},
+		{
+			title: "Access log",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithContainer(builders.NewContainerBuilder("myapp").WithPort("http", 80).Build()).
+				Build(),
+			expectedCmd: "xk6-disruptor-agent http -d 60s -t 80 --upstream-host 192.0.2.6 --access-log",
+			expectError: false,
+			fault: HTTPFault{
+				Port: intstr.FromInt32(80),
+			},
+			opts:     HTTPDisruptionOptions{AccessLog: true},
+			duration: 60 * time.Second,
+		},
+		{
+			title: "Proxy limits",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithContainer(builders.NewContainerBuilder("myapp").WithPort("http", 80).Build()).
+				Build(),
+			expectedCmd: "xk6-disruptor-agent http -d 60s -t 80 --upstream-host 192.0.2.6" +
+				" --read-buffer-size 65536 --write-buffer-size 65536 --max-header-bytes 4194304 --max-conns 500",
+			expectError: false,
+			fault: HTTPFault{
+				Port: intstr.FromInt32(80),
+			},
+			opts: HTTPDisruptionOptions{
+				ReadBufferSize:  65536,
+				WriteBufferSize: 65536,
+				MaxHeaderBytes:  4 << 20,
+				MaxConnections:  500,
+			},
+			duration: 60 * time.Second,
+		},
+		{
+			title:  "TLS termination",
+			target: buildPodWithPort("my-app-pod", "https", 443),
+			expectedCmd: "xk6-disruptor-agent http -d 60s -t 443 -e 503 -r 0.1 --upstream-host 192.0.2.6" +
+				" --tls-mode mtls --tls-cert /certs/tls.crt --tls-key /certs/tls.key --tls-ca /certs/ca.crt",
+			expectError: false,
+			fault: HTTPFault{
+				Port:      intstr.FromInt32(443),
+				ErrorRate: 0.1,
+				ErrorCode: 503,
+			},
+			opts: HTTPDisruptionOptions{
+				TLS: TLSOptions{Mode: TLSMutual, Cert: "/certs/tls.crt", Key: "/certs/tls.key", CA: "/certs/ca.crt"},
+			},
+			duration: 60 * time.Second,
+		},
+		{
+			title: "Multiple ports",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithContainer(builders.NewContainerBuilder("myapp").
+					WithPort("http", 80).
+					WithPort("admin", 8001).
+					WithPort("metrics", 9090).
+					Build()).
+				Build(),
+			expectedCmd: "xk6-disruptor-agent compose -d 60s" +
+				" -- http -d 60s -t 80 -a 100ms -v 0ms -p 8000 --upstream-host 192.0.2.6" +
+				" -- http -d 60s -t 8001 -a 100ms -v 0ms -p 8002 --upstream-host 192.0.2.6" +
+				" -- http -d 60s -t 9090 -a 100ms -v 0ms -p 8003 --upstream-host 192.0.2.6",
+			expectError: false,
+			fault: HTTPFault{
+				AverageDelay: 100 * time.Millisecond,
+				Ports:        []intstr.IntOrString{intstr.FromInt32(80), intstr.FromString("8000-8100"), "metrics"},
+			},
+			opts:     HTTPDisruptionOptions{},
+			duration: 60 * time.Second,
+		},
+		{
+			title:       "Discovered port",
+			target:      buildPodWithPort("my-app-pod", "http", 8080),
+			expectedCmd: "xk6-disruptor-agent http -d 60s -t 8080 -a 100ms -v 0ms --upstream-host 192.0.2.6",
+			expectError: false,
+			fault: HTTPFault{
+				AverageDelay: 100 * time.Millisecond,
+			},
+			opts:     HTTPDisruptionOptions{},
+			duration: 60 * time.Second,
+		},
+		{
+			title: "Port cannot be discovered",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithContainer(builders.NewContainerBuilder("myapp").WithPort("http", 8080).WithPort("admin", 8001).Build()).
+				Build(),
+			expectedCmd: "",
+			expectError: true,
+			fault:       HTTPFault{},
+			opts:        HTTPDisruptionOptions{},
+			duration:    60 * time.Second,
+		},
+		{
+			title:       "Port range not exposed",
+			target:      buildPodWithPort("my-app-pod", "http", 80),
+			expectedCmd: "",
+			expectError: true,
+			fault: HTTPFault{
+				Ports: []intstr.IntOrString{intstr.FromString("8000-8100")},
+			},
+			opts:     HTTPDisruptionOptions{},
+			duration: 60 * time.Second,
+		},
+		{
+			title: "Pod with Istio sidecar",
+			target: builders.NewPodBuilder("istio").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithContainer(builders.NewContainerBuilder("myapp").WithPort("http", 80).Build()).
+				WithContainer(builders.NewContainerBuilder("istio-proxy").Build()).
+				Build(),
+			expectedCmd: "xk6-disruptor-agent http -d 60s -t 80 --upstream-host 192.0.2.6 --istio",
+			expectError: false,
+			fault: HTTPFault{
+				Port: intstr.FromInt32(80),
+			},
+			opts:     HTTPDisruptionOptions{},
+			duration: 60 * time.Second,
+		},
+		{
+			title: "Pod with Istio sidecar and port used by the sidecar",
+			target: builders.NewPodBuilder("istio").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithContainer(builders.NewContainerBuilder("myapp").WithPort("http", 80).Build()).
+				WithContainer(builders.NewContainerBuilder("istio-proxy").Build()).
+				Build(),
+			expectedCmd: "",
+			expectError: true,
+			fault: HTTPFault{
+				Port: intstr.FromInt32(80),
+			},
+			opts:     HTTPDisruptionOptions{ProxyPort: 15001},
+			duration: 60 * time.Second,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -233,6 +434,20 @@ func Test_PodGrpcPFaultCommandGenerator(t *testing.T) {
 			expectError: false,
 			cmdError:    nil,
 		},
+		{
+			title:  "Test ramp duration",
+			target: buildPodWithPort("my-app-pod", "grpc", 3000),
+			fault: GrpcFault{
+				AverageDelay: 100 * time.Millisecond,
+				Port:         intstr.FromInt32(3000),
+				RampDuration: 30 * time.Second,
+			},
+			opts:        GrpcDisruptionOptions{},
+			duration:    60 * time.Second,
+			expectedCmd: "xk6-disruptor-agent grpc -d 60s -t 3000 -a 100ms -v 0ms --ramp-duration 30s --upstream-host 192.0.2.6",
+			expectError: false,
+			cmdError:    nil,
+		},
 		{
 			title:  "Test exclude list",
 			target: buildPodWithPort("my-app-pod", "grpc", 3000),
@@ -285,3 +500,160 @@ func Test_PodGrpcPFaultCommandGenerator(t *testing.T) {
 		})
 	}
 }
+
+func Test_PodComposedFaultCommandGenerator(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		target      corev1.Pod
+		faults      ComposedFaults
+		duration    time.Duration
+		expectError bool
+		expectedCmd []string
+	}{
+		{
+			title: "HTTP and grpc faults",
+			target: builders.NewPodBuilder("my-app-pod").
+				WithNamespace("test-ns").
+				WithContainer(
+					builders.NewContainerBuilder("my-app").
+						WithPort("http", 80).
+						WithPort("grpc", 9000).
+						Build(),
+				).
+				WithIP("192.0.2.6").
+				Build(),
+			faults: ComposedFaults{
+				HTTP: []HTTPFaultSpec{
+					{
+						Fault: HTTPFault{AverageDelay: 100 * time.Millisecond, Port: intstr.FromInt32(80)},
+					},
+				},
+				Grpc: []GrpcFaultSpec{
+					{
+						Fault:   GrpcFault{ErrorRate: 0.1, StatusCode: 14, Port: intstr.FromInt32(9000)},
+						Options: GrpcDisruptionOptions{ProxyPort: 8001},
+					},
+				},
+			},
+			duration: 60 * time.Second,
+			expectedCmd: []string{
+				"xk6-disruptor-agent", "compose", "-d", "60s",
+				"--", "http", "-d", "60s", "-t", "80", "-a", "100ms", "-v", "0ms", "--upstream-host", "192.0.2.6",
+				"--", "grpc", "-d", "60s", "-t", "9000", "-t", "9000", "-s", "14", "-r", "0.1", "-p", "8001",
+				"--upstream-host", "192.0.2.6",
+			},
+			expectError: false,
+		},
+		{
+			title:  "Container port not found",
+			target: buildPodWithPort("my-app-pod", "http", 80),
+			faults: ComposedFaults{
+				HTTP: []HTTPFaultSpec{
+					{
+						Fault: HTTPFault{Port: intstr.FromInt32(8080)},
+					},
+				},
+			},
+			duration:    60 * time.Second,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			cmd := PodComposedFaultCommand{
+				faults:   tc.faults,
+				duration: tc.duration,
+			}
+
+			cmds, err := cmd.Commands(tc.target)
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+
+			if !tc.expectError && err != nil {
+				t.Errorf("unexpected error : %v", err)
+				return
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expectedCmd, cmds.Exec); diff != "" {
+				t.Errorf("expected command does not match returned:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_PodTimelineCommandGenerator(t *testing.T) {
+	t.Parallel()
+
+	timeline := FaultTimeline{
+		{
+			Faults: ComposedFaults{
+				HTTP: []HTTPFaultSpec{{Fault: HTTPFault{AverageDelay: 100 * time.Millisecond, Port: intstr.FromInt32(80)}}},
+			},
+			Duration: 2 * time.Minute,
+		},
+		{
+			Faults: ComposedFaults{
+				HTTP: []HTTPFaultSpec{{Fault: HTTPFault{ErrorRate: 1.0, ErrorCode: 503, Port: intstr.FromInt32(80)}}},
+			},
+			Duration: time.Minute,
+		},
+	}
+
+	cmd := PodTimelineCommand{timeline: timeline}
+
+	cmds, err := cmd.Commands(buildPodWithPort("my-app-pod", "http", 80))
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	expected := []string{
+		"xk6-disruptor-agent", "timeline", "-d", "180s",
+		"--step", `["compose","-d","120s","--","http","-d","120s","-t","80","-a","100ms","-v","0ms",` +
+			`"--upstream-host","192.0.2.6"]`,
+		"--step", `["compose","-d","60s","--","http","-d","60s","-t","80","-e","503","-r","1",` +
+			`"--upstream-host","192.0.2.6"]`,
+	}
+
+	if diff := cmp.Diff(expected, cmds.Exec); diff != "" {
+		t.Errorf("expected command does not match returned:\n%s", diff)
+	}
+}
+
+func Test_PodRepeatCommandGenerator(t *testing.T) {
+	t.Parallel()
+
+	cmd := PodRepeatCommand{
+		command: PodHTTPFaultCommand{
+			fault:    HTTPFault{ErrorRate: 1.0, ErrorCode: 503, Port: intstr.FromInt32(80)},
+			duration: time.Minute,
+		},
+		every:    10 * time.Minute,
+		duration: time.Hour,
+	}
+
+	cmds, err := cmd.Commands(buildPodWithPort("my-app-pod", "http", 80))
+	if err != nil {
+		t.Fatalf("unexpected error : %v", err)
+	}
+
+	expected := []string{
+		"xk6-disruptor-agent", "repeat", "-d", "3600s", "--every", "600s",
+		"--fault", `["http","-d","60s","-t","80","-e","503","-r","1","--upstream-host","192.0.2.6"]`,
+	}
+
+	if diff := cmp.Diff(expected, cmds.Exec); diff != "" {
+		t.Errorf("expected command does not match returned:\n%s", diff)
+	}
+}
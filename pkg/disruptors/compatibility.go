@@ -0,0 +1,69 @@
+package disruptors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+)
+
+// agentProtocol is the version of the commands and flags of the agent used by this version of the extension
+const agentProtocol = 1
+
+// agentInfo is the version information reported by the agent's version command
+type agentInfo struct {
+	Version  string   `json:"version"`
+	Protocol int      `json:"protocol"`
+	Commands []string `json:"commands"`
+}
+
+// agentSubcommand returns the subcommand of the agent invoked by the command, skipping the global flags
+func agentSubcommand(command []string) string {
+	for _, arg := range command[min(1, len(command)):] {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+
+	return ""
+}
+
+// checkAgentVersion returns an error if the agent running in the pod cannot execute the command as expected by
+// this version of the extension
+func checkAgentVersion(ctx context.Context, helper helpers.PodHelper, pod string, command []string) error {
+	stdout, stderr, err := helper.Exec(ctx, pod, "xk6-agent", buildVersionCmd(), []byte{})
+	if err != nil && strings.Contains(string(stderr), "unknown command") {
+		return fmt.Errorf(
+			"the agent does not report its version, therefore it is older than this version of the extension." +
+				" Use a matching agent image or restart the pod to remove the agent injected by a previous version",
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("getting agent version: %w \n%s", err, string(stderr))
+	}
+
+	info := agentInfo{}
+	err = json.Unmarshal(stdout, &info)
+	if err != nil {
+		return fmt.Errorf("decoding agent version: %w", err)
+	}
+
+	if info.Protocol != agentProtocol {
+		return fmt.Errorf(
+			"the agent version %q uses protocol %d, which is not compatible with the protocol %d of the extension",
+			info.Version,
+			info.Protocol,
+			agentProtocol,
+		)
+	}
+
+	subcommand := agentSubcommand(command)
+	if subcommand != "" && !slices.Contains(info.Commands, subcommand) {
+		return fmt.Errorf("the agent version %q does not support the %q command", info.Version, subcommand)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_CheckAgentVersion(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		stdout      string
+		stderr      string
+		err         error
+		command     []string
+		expectError bool
+	}{
+		{
+			title:       "compatible agent",
+			stdout:      `{"version":"v1.0.0","protocol":1,"commands":["http","grpc"]}`,
+			command:     []string{"xk6-disruptor-agent", "http", "-d", "60s"},
+			expectError: false,
+		},
+		{
+			title:       "command after global flags",
+			stdout:      `{"version":"v1.0.0","protocol":1,"commands":["http","grpc"]}`,
+			command:     []string{"xk6-disruptor-agent", "--trace", "grpc"},
+			expectError: false,
+		},
+		{
+			title:       "unsupported command",
+			stdout:      `{"version":"v1.0.0","protocol":1,"commands":["http","grpc"]}`,
+			command:     []string{"xk6-disruptor-agent", "clock-skew"},
+			expectError: true,
+		},
+		{
+			title:       "incompatible protocol",
+			stdout:      `{"version":"v2.0.0","protocol":2,"commands":["http","grpc"]}`,
+			command:     []string{"xk6-disruptor-agent", "http"},
+			expectError: true,
+		},
+		{
+			title:       "agent does not report its version",
+			stderr:      `unknown command "version" for "xk6-disruptor-agent"`,
+			err:         errFailed,
+			command:     []string{"xk6-disruptor-agent", "http"},
+			expectError: true,
+		},
+		{
+			title:       "invalid version",
+			stdout:      `v1.0.0`,
+			command:     []string{"xk6-disruptor-agent", "http"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+			client := fake.NewSimpleClientset(&pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+
+			executor.SetResult([]byte(tc.stdout), []byte(tc.stderr), tc.err)
+			err := checkAgentVersion(context.TODO(), helper, pod.Name, tc.command)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
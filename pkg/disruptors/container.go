@@ -0,0 +1,236 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Actions of the container faults
+const (
+	// ContainerKill kills the container. The kubelet restarts it according to the restart policy of the pod.
+	ContainerKill = "kill"
+	// ContainerRestart stops the container gracefully. The kubelet restarts it according to the restart policy of
+	// the pod.
+	ContainerRestart = "restart"
+	// ContainerPause freezes the processes of the container for the duration of the fault
+	ContainerPause = "pause"
+)
+
+// ContainerFault specifies a fault that acts on a container of the target pods through the container runtime of
+// their nodes (containerd or CRI-O). The actions are applied by the agent running in the nodes, therefore they do
+// not depend on the tools available in the image of the container. Pausing containers requires systemd in the nodes.
+type ContainerFault struct {
+	// Count indicates how many pods are affected. Can be a number or a percentage of the targets. Defaults to all.
+	Count intstr.IntOrString
+	// Action applied to the container: kill, restart or pause
+	Action string
+	// Container is the name of the container. Defaults to the first container of the pods.
+	Container string
+	// GracePeriod is the time a restarted container has for terminating gracefully. Defaults to 10s.
+	GracePeriod time.Duration `js:"gracePeriod"`
+	// RuntimeEndpoint is the CRI socket of the container runtime in the nodes
+	// (e.g. "unix:///run/containerd/containerd.sock"). Defaults to the socket of containerd, CRI-O or cri-dockerd.
+	RuntimeEndpoint string `js:"runtimeEndpoint"`
+	// AgentNamespace is the namespace where the agent pods that run in the nodes are created. Defaults to "default".
+	AgentNamespace string `js:"agentNamespace"`
+}
+
+// validate returns an error if the fault is not valid
+func (f ContainerFault) validate(duration time.Duration) error {
+	switch f.Action {
+	case ContainerKill, ContainerRestart:
+	case ContainerPause:
+		if duration <= 0 {
+			return fmt.Errorf("the duration of a %s fault must be positive", f.Action)
+		}
+	default:
+		return fmt.Errorf(
+			"invalid action %q: must be one of %s, %s or %s",
+			f.Action,
+			ContainerKill,
+			ContainerRestart,
+			ContainerPause,
+		)
+	}
+
+	if f.GracePeriod < 0 {
+		return fmt.Errorf("grace period cannot be negative")
+	}
+
+	return nil
+}
+
+// agentNamespace returns the namespace of the node agents
+func (f ContainerFault) agentNamespace() string {
+	if f.AgentNamespace == "" {
+		return metav1.NamespaceDefault
+	}
+
+	return f.AgentNamespace
+}
+
+// containerID returns the ID in the container runtime of the container of the pod affected by the fault
+func containerID(pod corev1.Pod, container string) (string, error) {
+	if container == "" && len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != container {
+			continue
+		}
+
+		if status.State.Running == nil {
+			return "", fmt.Errorf("container %q of pod %q is not running", container, pod.Name)
+		}
+
+		// the ID has the format <runtime>://<id>
+		runtime, id, found := strings.Cut(status.ContainerID, "://")
+		if !found {
+			return "", fmt.Errorf("invalid ID %q of container %q of pod %q", status.ContainerID, container, pod.Name)
+		}
+
+		// the container runtimes that support container faults
+		switch runtime {
+		case "containerd", "cri-o":
+		default:
+			return "", fmt.Errorf(
+				"container runtime %q of pod %q is not supported. Supported runtimes: containerd, cri-o",
+				runtime,
+				pod.Name,
+			)
+		}
+
+		return id, nil
+	}
+
+	return "", fmt.Errorf("pod %q does not have a container %q", pod.Name, container)
+}
+
+// buildContainerCmd returns the agent command that applies the fault to the containers with the given IDs
+func buildContainerCmd(fault ContainerFault, ids []string, duration time.Duration) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"container",
+		"--id", strings.Join(ids, ","),
+		"-a", fault.Action,
+	}
+
+	// killing and restarting containers does not require a duration
+	if duration > 0 {
+		cmd = append(cmd, "-d", utils.DurationSeconds(duration))
+	}
+
+	if fault.GracePeriod > 0 {
+		cmd = append(cmd, "--grace-period", utils.DurationSeconds(fault.GracePeriod))
+	}
+
+	if fault.RuntimeEndpoint != "" {
+		cmd = append(cmd, "--runtime-endpoint", fault.RuntimeEndpoint)
+	}
+
+	return cmd
+}
+
+// NodeContainerFaultCommand implements the NodeVisitCommand interface for applying a container fault to the
+// containers of the target pods that run in a Node
+type NodeContainerFaultCommand struct {
+	fault    ContainerFault
+	duration time.Duration
+	// containers are the IDs of the containers of the targets, by node
+	containers map[string][]string
+}
+
+// Commands return the command for applying the fault to the containers of the targets in the Node
+func (c NodeContainerFaultCommand) Commands(node corev1.Node) (VisitCommands, error) {
+	ids := c.containers[node.Name]
+	if len(ids) == 0 {
+		return VisitCommands{}, fmt.Errorf("node %q does not run any target", node.Name)
+	}
+
+	return VisitCommands{
+		Exec:    buildContainerCmd(c.fault, ids, c.duration),
+		Cleanup: buildCleanupCmd(),
+	}, nil
+}
+
+// containersByNode returns the nodes of the targets and the IDs of the containers affected by the fault in each node
+func containersByNode(targets []corev1.Pod, fault ContainerFault) ([]corev1.Node, map[string][]string, error) {
+	nodes := []corev1.Node{}
+	containers := map[string][]string{}
+	for _, pod := range targets {
+		if pod.Spec.NodeName == "" {
+			return nil, nil, fmt.Errorf("pod %q is not scheduled in a node", pod.Name)
+		}
+
+		id, err := containerID(pod, fault.Container)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, found := containers[pod.Spec.NodeName]; !found {
+			nodes = append(nodes, corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: pod.Spec.NodeName}})
+		}
+		containers[pod.Spec.NodeName] = append(containers[pod.Spec.NodeName], id)
+	}
+
+	return nodes, containers, nil
+}
+
+// injectContainerFault applies the fault to the containers of the targets using an agent in each of their nodes.
+// The agents are created with the given helper.
+func injectContainerFault(
+	ctx context.Context,
+	helper helpers.PodHelper,
+	options NodeAgentVisitorOptions,
+	targets []corev1.Pod,
+	fault ContainerFault,
+	duration time.Duration,
+) error {
+	nodes, containers, err := containersByNode(targets, fault)
+	if err != nil {
+		return err
+	}
+
+	visitor := NewNodeAgentVisitor(
+		helper,
+		options,
+		NodeContainerFaultCommand{fault: fault, duration: duration, containers: containers},
+	)
+
+	return NewNodeController(nodes).Visit(ctx, visitor)
+}
+
+// dryRunContainerFault writes to out the command the agent would run in the node of each target
+func dryRunContainerFault(out io.Writer, targets []corev1.Pod, fault ContainerFault, duration time.Duration) error {
+	for _, pod := range targets {
+		id, err := containerID(pod, fault.Container)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(
+			out,
+			"%s/%s: node %s: %s\n",
+			pod.Namespace,
+			pod.Name,
+			pod.Spec.NodeName,
+			quoteCommand(buildContainerCmd(fault, []string{id}, duration)),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
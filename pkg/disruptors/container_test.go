@@ -0,0 +1,191 @@
+package disruptors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// buildContainerPod returns a pod in the node with a container with the given ID. If running is false the
+// container is waiting.
+func buildContainerPod(name string, node string, id string, running bool) corev1.Pod {
+	pod := builders.NewPodBuilder(name).
+		WithDefaultNamespace().
+		WithNodeName(node).
+		WithContainer(builders.NewContainerBuilder("main").Build()).
+		Build()
+
+	state := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}}
+	if running {
+		state = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	}
+
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{Name: "main", ContainerID: id, State: state},
+	}
+
+	return pod
+}
+
+func Test_ContainersByNode(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title              string
+		targets            []corev1.Pod
+		fault              ContainerFault
+		expectError        bool
+		expectedNodes      []string
+		expectedContainers map[string][]string
+	}{
+		{
+			title: "targets in different nodes",
+			targets: []corev1.Pod{
+				buildContainerPod("pod1", "node1", "containerd://abc", true),
+				buildContainerPod("pod2", "node2", "cri-o://def", true),
+				buildContainerPod("pod3", "node1", "containerd://ghi", true),
+			},
+			fault:         ContainerFault{Action: ContainerKill},
+			expectedNodes: []string{"node1", "node2"},
+			expectedContainers: map[string][]string{
+				"node1": {"abc", "ghi"},
+				"node2": {"def"},
+			},
+		},
+		{
+			title:              "named container",
+			targets:            []corev1.Pod{buildContainerPod("pod1", "node1", "containerd://abc", true)},
+			fault:              ContainerFault{Action: ContainerKill, Container: "main"},
+			expectedNodes:      []string{"node1"},
+			expectedContainers: map[string][]string{"node1": {"abc"}},
+		},
+		{
+			title:       "unknown container",
+			targets:     []corev1.Pod{buildContainerPod("pod1", "node1", "containerd://abc", true)},
+			fault:       ContainerFault{Action: ContainerKill, Container: "sidecar"},
+			expectError: true,
+		},
+		{
+			title:       "container not running",
+			targets:     []corev1.Pod{buildContainerPod("pod1", "node1", "containerd://abc", false)},
+			fault:       ContainerFault{Action: ContainerKill},
+			expectError: true,
+		},
+		{
+			title:       "unsupported runtime",
+			targets:     []corev1.Pod{buildContainerPod("pod1", "node1", "docker://abc", true)},
+			fault:       ContainerFault{Action: ContainerKill},
+			expectError: true,
+		},
+		{
+			title:       "pod not scheduled",
+			targets:     []corev1.Pod{buildContainerPod("pod1", "", "containerd://abc", true)},
+			fault:       ContainerFault{Action: ContainerKill},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			nodes, containers, err := containersByNode(tc.targets, tc.fault)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			nodeNames := []string{}
+			for _, node := range nodes {
+				nodeNames = append(nodeNames, node.Name)
+			}
+
+			if diff := cmp.Diff(tc.expectedNodes, nodeNames); diff != "" {
+				t.Fatalf("expected nodes do not match returned:\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.expectedContainers, containers); diff != "" {
+				t.Fatalf("expected containers do not match returned:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ContainerFaultCommand(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		fault       ContainerFault
+		duration    time.Duration
+		expectError bool
+		expected    []string
+	}{
+		{
+			title:    "kill",
+			fault:    ContainerFault{Action: ContainerKill},
+			expected: []string{"xk6-disruptor-agent", "container", "--id", "abc,def", "-a", "kill"},
+		},
+		{
+			title: "restart with grace period and runtime endpoint",
+			fault: ContainerFault{
+				Action:          ContainerRestart,
+				GracePeriod:     30 * time.Second,
+				RuntimeEndpoint: "unix:///run/containerd/containerd.sock",
+			},
+			expected: []string{
+				"xk6-disruptor-agent", "container", "--id", "abc,def", "-a", "restart",
+				"--grace-period", "30s", "--runtime-endpoint", "unix:///run/containerd/containerd.sock",
+			},
+		},
+		{
+			title:    "pause",
+			fault:    ContainerFault{Action: ContainerPause},
+			duration: time.Minute,
+			expected: []string{"xk6-disruptor-agent", "container", "--id", "abc,def", "-a", "pause", "-d", "60s"},
+		},
+		{
+			title:       "pause without duration",
+			fault:       ContainerFault{Action: ContainerPause},
+			expectError: true,
+		},
+		{
+			title:       "invalid action",
+			fault:       ContainerFault{Action: "stop"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.fault.validate(tc.duration)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			cmd := buildContainerCmd(tc.fault, []string{"abc", "def"}, tc.duration)
+			if diff := cmp.Diff(tc.expected, cmd); diff != "" {
+				t.Fatalf("expected command does not match returned:\n%s", diff)
+			}
+		})
+	}
+}
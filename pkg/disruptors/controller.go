@@ -2,15 +2,21 @@ package disruptors
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"path"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/internal/version"
-
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"golang.org/x/time/rate"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 // PodController uses a PodVisitor to perform a certain action (Visit) on a list of pods.
@@ -18,16 +24,88 @@ import (
 // is responsible for coordinating the action of the PodVisitor on multiple target pods
 type PodController struct {
 	targets []corev1.Pod
+	options PodControllerOptions
+}
+
+// PodControllerOptions defines how the PodController visits the targets
+type PodControllerOptions struct {
+	// Concurrency is the maximum number of targets visited simultaneously. A zero value does not limit the
+	// concurrency.
+	Concurrency int
+	// Rate is the maximum number of visits started per second. A zero value does not limit the rate.
+	Rate float64
+	// ContinueOnError visits all the targets even if the visit of some of them fails. The errors of all the
+	// failed visits are returned once all the visits have finished.
+	ContinueOnError bool
+	// AllowPartial visits all the targets even if the visit of some of them fails, and accepts the visit if it
+	// succeeded in at least one target. The TargetsError returned reports the visit was accepted.
+	AllowPartial bool
+}
+
+// validate returns an error if the options are not valid
+func (o PodControllerOptions) validate() error {
+	if o.Concurrency < 0 {
+		return fmt.Errorf("concurrency cannot be negative: %d", o.Concurrency)
+	}
+
+	if o.Rate < 0 {
+		return fmt.Errorf("injectRate cannot be negative: %f", o.Rate)
+	}
+
+	return nil
 }
 
 // NewPodController creates a new controller for a collection of pods
-func NewPodController(targets []corev1.Pod) *PodController {
+func NewPodController(targets []corev1.Pod, options PodControllerOptions) *PodController {
 	return &PodController{
 		targets: targets,
+		options: options,
 	}
 }
 
-// Visit allows executing a different command on each target returned by a visiting function
+// visitResult is the result of the visit of a target
+type visitResult struct {
+	target string
+	err    error
+}
+
+// start starts the visit of each target, limiting the number of concurrent visits and the rate at which they
+// start. The result of each visit is sent to the done channel.
+func (c *PodController) start(ctx context.Context, visitor PodVisitor, doneCh chan<- visitResult) {
+	var limiter *rate.Limiter
+	if c.options.Rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(c.options.Rate), 1)
+	}
+
+	var slots chan struct{}
+	if c.options.Concurrency > 0 {
+		slots = make(chan struct{}, c.options.Concurrency)
+	}
+
+	for _, pod := range c.targets {
+		if limiter != nil && limiter.Wait(ctx) != nil {
+			return
+		}
+
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		go func(pod corev1.Pod) {
+			doneCh <- visitResult{target: pod.Name, err: visitor.Visit(ctx, pod)}
+			if slots != nil {
+				<-slots
+			}
+		}(pod)
+	}
+}
+
+// Visit allows executing a different command on each target returned by a visiting function.
+// If all the targets are visited regardless of errors, the visits that failed are reported as a *TargetsError.
 func (c *PodController) Visit(ctx context.Context, visitor PodVisitor) error {
 	// if there are no targets, nothing to do
 	if len(c.targets) == 0 {
@@ -39,24 +117,27 @@ func (c *PodController) Visit(ctx context.Context, visitor PodVisitor) error {
 	defer cancelVisit()
 
 	// make space to prevent blocking go routines
-	doneCh := make(chan error, len(c.targets))
+	doneCh := make(chan visitResult, len(c.targets))
 
-	for _, pod := range c.targets {
-		go func(pod corev1.Pod) {
-			doneCh <- visitor.Visit(visitCtx, pod)
-		}(pod)
-	}
+	go c.start(visitCtx, visitor, doneCh)
 
+	visitAll := c.options.ContinueOnError || c.options.AllowPartial
+	result := &TargetsError{}
 	pending := len(c.targets)
 	for {
 		select {
-		case e := <-doneCh:
-			if e != nil {
-				return e
+		case done := <-doneCh:
+			if done.err != nil && !visitAll {
+				return done.err
+			}
+			if done.err != nil {
+				result.Failed = append(result.Failed, TargetError{Target: done.target, Err: done.err})
+			} else {
+				result.Succeeded = append(result.Succeeded, done.target)
 			}
 			pending--
 			if pending == 0 {
-				return nil
+				return c.result(result)
 			}
 		case <-ctx.Done():
 			return ctx.Err()
@@ -64,10 +145,50 @@ func (c *PodController) Visit(ctx context.Context, visitor PodVisitor) error {
 	}
 }
 
+// result returns the error that reports the targets whose visit failed, if any
+func (c *PodController) result(result *TargetsError) error {
+	if len(result.Failed) == 0 {
+		return nil
+	}
+
+	slices.Sort(result.Succeeded)
+	slices.SortFunc(result.Failed, func(a, b TargetError) int {
+		return strings.Compare(a.Target, b.Target)
+	})
+	result.Accepted = c.options.AllowPartial && len(result.Succeeded) > 0
+
+	return result
+}
+
 // VisitCommands contains the commands to be executed when visiting a pod
 type VisitCommands struct {
 	Exec    []string
 	Cleanup []string
+	// Duration is the expected duration of the Exec command. A zero value means the command is not expected to
+	// last a given time.
+	Duration time.Duration
+}
+
+// withAgentArgs returns the commands with the given arguments of the agent added before the agent command
+// (e.g. "xk6-disruptor-agent --log-level debug http ...")
+func (c VisitCommands) withAgentArgs(args []string) VisitCommands {
+	if len(args) == 0 {
+		return c
+	}
+
+	insert := func(cmd []string) []string {
+		if len(cmd) == 0 {
+			return cmd
+		}
+
+		return slices.Concat(cmd[:1], args, cmd[1:])
+	}
+
+	return VisitCommands{
+		Exec:     insert(c.Exec),
+		Cleanup:  insert(c.Cleanup),
+		Duration: c.Duration,
+	}
 }
 
 // PodVisitor is the interface implemented by objects that perform actions on a Pod
@@ -104,6 +225,18 @@ func NewPodAgentVisitor(
 	if options.Timeout < 0 {
 		options.Timeout = 0
 	}
+	if options.Image == "" {
+		options.Image = defaultAgentImage()
+	}
+	if options.ImagePullPolicy == "" {
+		options.ImagePullPolicy = defaultAgentPullPolicy()
+	}
+	if options.ImagePullSecrets == nil {
+		options.ImagePullSecrets = defaultAgentPullSecrets()
+	}
+	if options.SecurityContext == nil {
+		options.SecurityContext = defaultAgentSecurityContext()
+	}
 
 	return &PodAgentVisitor{
 		helper:  helper,
@@ -112,68 +245,377 @@ func NewPodAgentVisitor(
 	}
 }
 
+// checkPullSecrets returns an error if the pod does not reference the secrets required for pulling the agent image
+func (c *PodAgentVisitor) checkPullSecrets(pod corev1.Pod) error {
+	for _, secret := range c.options.ImagePullSecrets {
+		referenced := slices.ContainsFunc(pod.Spec.ImagePullSecrets, func(ref corev1.LocalObjectReference) bool {
+			return ref.Name == secret
+		})
+		if !referenced {
+			return fmt.Errorf(
+				"the agent image requires the imagePullSecret %q, which is not referenced by the pod",
+				secret,
+			)
+		}
+	}
+
+	return nil
+}
+
+// AgentVolumesPath is the directory of the agent's container where the volumes of the pod are mounted, each in a
+// directory with the name of the volume (e.g. /var/run/xk6-disruptor/volumes/tls-certs)
+const AgentVolumesPath = "/var/run/xk6-disruptor/volumes"
+
+// volumeMounts returns the mounts of the volumes of the pod in the agent's container
+func (c *PodAgentVisitor) volumeMounts() []corev1.VolumeMount {
+	mounts := []corev1.VolumeMount{}
+	for _, volume := range c.options.Volumes {
+		mounts = append(mounts, corev1.VolumeMount{
+			Name:      volume,
+			MountPath: path.Join(AgentVolumesPath, volume),
+			ReadOnly:  true,
+		})
+	}
+
+	return mounts
+}
+
+// checkVolumes returns an error if the pod does not have the volumes mounted in the agent, or if the agent was
+// already injected without mounting them, as the mounts of ephemeral containers cannot be changed
+func (c *PodAgentVisitor) checkVolumes(pod corev1.Pod) error {
+	for _, volume := range c.options.Volumes {
+		exists := slices.ContainsFunc(pod.Spec.Volumes, func(v corev1.Volume) bool {
+			return v.Name == volume
+		})
+		if !exists {
+			return fmt.Errorf("the pod does not have the volume %q", volume)
+		}
+	}
+
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.Name != "xk6-agent" {
+			continue
+		}
+
+		for _, volume := range c.options.Volumes {
+			mounted := slices.ContainsFunc(container.VolumeMounts, func(m corev1.VolumeMount) bool {
+				return m.Name == volume
+			})
+			if !mounted {
+				return fmt.Errorf("the agent was injected in the pod without mounting the volume %q", volume)
+			}
+		}
+	}
+
+	return nil
+}
+
 // injectDisruptorAgent injects the Disruptor agent in the target pods
 func (c *PodAgentVisitor) injectDisruptorAgent(ctx context.Context, pod corev1.Pod) error {
-	var (
-		rootUser     = int64(0)
-		rootGroup    = int64(0)
-		runAsNonRoot = false
-	)
+	if c.options.Sidecar {
+		if !hasSidecarAgent(pod) {
+			return fmt.Errorf("the pod does not run the agent as a sidecar")
+		}
+		return nil
+	}
+
+	if !hasAgent(pod) {
+		err := c.checkPullSecrets(pod)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := c.checkVolumes(pod)
+	if err != nil {
+		return err
+	}
+
+	env := agentEnv()
+	if c.options.Restricted {
+		env = append(env, corev1.EnvVar{Name: AgentRestrictedEnvVar, Value: "true"})
+	}
+
+	image, err := c.agentImage(ctx, pod)
+	if err != nil {
+		return err
+	}
+
+	command := agentCommand()
+	if c.options.ControlAPI {
+		command = agentControlCommand()
+	}
 
 	agentContainer := corev1.EphemeralContainer{
 		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
 			Name:            "xk6-agent",
-			Image:           version.AgentImage(),
-			ImagePullPolicy: corev1.PullIfNotPresent,
-			SecurityContext: &corev1.SecurityContext{
-				Capabilities: &corev1.Capabilities{
-					Add: []corev1.Capability{"NET_ADMIN"},
-				},
-				RunAsUser:    &rootUser,
-				RunAsGroup:   &rootGroup,
-				RunAsNonRoot: &runAsNonRoot,
-			},
-			TTY:   true,
-			Stdin: true,
+			Image:           image,
+			ImagePullPolicy: c.options.ImagePullPolicy,
+			Command:         command,
+			Env:             env,
+			SecurityContext: c.options.SecurityContext,
+			VolumeMounts:    c.volumeMounts(),
+			TTY:             true,
+			Stdin:           true,
 		},
 	}
 
-	return c.helper.AttachEphemeralContainer(
+	err = c.helper.AttachEphemeralContainer(
 		ctx,
 		pod.Name,
 		agentContainer,
 		helpers.AttachOptions{
 			Timeout:        c.options.Timeout,
 			IgnoreIfExists: true,
+			Retries:        c.options.Retries,
+			RetryBackoff:   c.options.RetryBackoff,
 		},
 	)
+
+	return architectureError(err, image, pod)
+}
+
+// agentReadyBackoff returns the delays between the checks of the readiness of the agent. The agent is usually ready
+// shortly after its container starts, so it is checked often at first and less frequently the longer it takes.
+func agentReadyBackoff() wait.Backoff {
+	return wait.Backoff{
+		Duration: 50 * time.Millisecond,
+		Factor:   2,
+		Cap:      time.Second,
+		Steps:    math.MaxInt32,
+	}
+}
+
+// agentHealth is the health reported by the agent's health command
+type agentHealth struct {
+	Ready    bool     `json:"ready"`
+	Problems []string `json:"problems"`
+}
+
+// agentReady returns if the agent is ready for applying faults or the reason it is not. Agents that do not report
+// their health are checked with the ready command, and those that do not support reporting their readiness either
+// are considered ready.
+func (c *PodAgentVisitor) agentReady(ctx context.Context, pod corev1.Pod) (bool, error) {
+	stdout, stderr, err := c.helper.Exec(ctx, pod.Name, "xk6-agent", buildHealthCmd(), []byte{})
+	if err != nil && strings.Contains(string(stderr), "unknown command") {
+		_, stderr, err = c.helper.Exec(ctx, pod.Name, "xk6-agent", buildReadyCmd(), []byte{})
+		if err == nil || strings.Contains(string(stderr), "unknown command") {
+			return true, nil
+		}
+
+		return false, fmt.Errorf("%w \n%s", err, string(stderr))
+	}
+
+	if err == nil {
+		return true, nil
+	}
+
+	// the health command fails if any problem is found, even if the agent is ready
+	health := agentHealth{}
+	if json.Unmarshal(stdout, &health) != nil {
+		return false, fmt.Errorf("%w \n%s", err, string(stderr))
+	}
+
+	if !health.Ready {
+		return false, errors.New(strings.Join(health.Problems, ", "))
+	}
+
+	return true, nil
+}
+
+// waitAgentReady waits until the agent reports it is ready for applying faults. The agent container may be running
+// before the agent has completed its initialization.
+func (c *PodAgentVisitor) waitAgentReady(ctx context.Context, pod corev1.Pod) error {
+	if c.options.Timeout == 0 {
+		return nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, c.options.Timeout)
+	defer cancel()
+
+	var notReady error
+	err := agentReadyBackoff().DelayFunc().Until(waitCtx, true, false, func(ctx context.Context) (bool, error) {
+		ready, reason := c.agentReady(ctx, pod)
+		notReady = reason
+		return ready, nil
+	})
+	if err != nil && notReady != nil {
+		return fmt.Errorf("agent is not ready: %w", notReady)
+	}
+
+	return err
 }
 
 // Visit allows executing a different command on each target returned by a visiting function
 func (c *PodAgentVisitor) Visit(ctx context.Context, pod corev1.Pod) error {
+	if isWindowsPod(pod) {
+		return unsupportedOSError("pod", pod.Name)
+	}
+
 	err := c.injectDisruptorAgent(ctx, pod)
 	if err != nil {
 		return fmt.Errorf("injecting agent in the pod %q: %w", pod.Name, err)
 	}
 
+	if !hasAgent(pod) {
+		recordEvent(ctx, c.helper, pod, corev1.EventTypeNormal, EventAgentInjected, "injected the xk6-disruptor agent")
+	}
+
+	err = c.waitAgentReady(ctx, pod)
+	if err != nil {
+		return c.agentError(ctx, pod, fmt.Errorf("waiting for the agent in the pod %q: %w", pod.Name, err))
+	}
+
 	// get the command to execute in the target
 	commands, err := c.command.Commands(pod)
 	if err != nil {
 		return fmt.Errorf("unable to get command for pod %q: %w", pod.Name, err)
 	}
 
-	_, stderr, err := c.helper.Exec(ctx, pod.Name, "xk6-agent", commands.Exec, []byte{})
+	// agents injected from the image of this version of the extension are compatible with it, but the pod
+	// may run an agent injected by a previous version or the image may be of a different version
+	if hasAgent(pod) || c.options.Image != version.AgentImage() {
+		err = c.checkAgentVersion(ctx, pod, commands.Exec)
+		if err != nil {
+			return fmt.Errorf("agent in the pod %q: %w", pod.Name, err)
+		}
+	}
+
+	// the defaults of the agents are added once the compatibility of the command with the agent is checked
+	commands = commands.withAgentArgs(c.options.AgentArgs)
+
+	c.recordFaultStart(ctx, pod, commands)
+	err = c.apply(ctx, pod, commands)
+	c.recordFaultEnd(pod, commands, err)
 
 	if err != nil && commands.Cleanup != nil {
 		// we ignore errors because we are reporting the reason of the exec failure
 		// we use a fresh context because the context used in exec may have been cancelled or expired
 		//nolint:contextcheck
-		_, _, _ = c.helper.Exec(context.TODO(), pod.Name, "xk6-agent", commands.Cleanup, []byte{})
+		_, _, _ = execWithTimeout(context.TODO(), c.helper, pod.Name, commands.Cleanup, 0, c.options.ExecTimeout)
 	}
 
 	// if the context is cancelled, don't report error (we assume the caller is reporting this error)
 	if err != nil && !errors.Is(err, context.Canceled) {
-		return fmt.Errorf("failed command execution for pod %q: %w \n%s", pod.Name, err, string(stderr))
+		return c.agentError(
+			ctx,
+			pod,
+			fmt.Errorf("failed command execution for pod %q: %w", pod.Name, err),
+		)
+	}
+
+	return nil
+}
+
+// apply applies the fault in the pod by executing the agent command or, if enabled, using the control API of the
+// agent. The application is aborted if it lasts more than the duration of the fault plus the exec timeout.
+func (c *PodAgentVisitor) apply(ctx context.Context, pod corev1.Pod, commands VisitCommands) error {
+	if !c.options.ControlAPI {
+		_, stderr, err := execWithTimeout(
+			ctx,
+			c.helper,
+			pod.Name,
+			commands.Exec,
+			commands.Duration,
+			c.options.ExecTimeout,
+		)
+		if err != nil {
+			return newAgentError(err, stderr)
+		}
+
+		return nil
+	}
+
+	if c.options.ExecTimeout == 0 {
+		return applyWithControlAPI(ctx, c.helper, pod.Name, commands.Exec)
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, commands.Duration+c.options.ExecTimeout)
+	defer cancel()
+
+	err := applyWithControlAPI(execCtx, c.helper, pod.Name, commands.Exec)
+	if err != nil {
+		return execError(ctx, execCtx, err)
+	}
+
+	return nil
+}
+
+// recordFaultStart records the start of the fault applied in the pod
+func (c *PodAgentVisitor) recordFaultStart(ctx context.Context, pod corev1.Pod, commands VisitCommands) {
+	message := "fault started: " + faultDescription(commands)
+	recordEvent(ctx, c.helper, pod, corev1.EventTypeNormal, EventFaultStarted, message)
+}
+
+// recordFaultEnd records the end of the fault applied in the pod. A fresh context is used because the context used
+// for applying the fault may have been cancelled.
+func (c *PodAgentVisitor) recordFaultEnd(pod corev1.Pod, commands VisitCommands, err error) {
+	ctx := context.TODO()
+	if err != nil && !errors.Is(err, context.Canceled) {
+		message := fmt.Sprintf("fault failed: %s: %v", faultDescription(commands), err)
+		recordEvent(ctx, c.helper, pod, corev1.EventTypeWarning, EventFaultFailed, message)
+		return
+	}
+
+	message := "fault ended: " + faultDescription(commands)
+	recordEvent(ctx, c.helper, pod, corev1.EventTypeNormal, EventFaultEnded, message)
+}
+
+// checkAgentVersion checks the version of the agent, limiting the time the agent has to report it
+func (c *PodAgentVisitor) checkAgentVersion(ctx context.Context, pod corev1.Pod, command []string) error {
+	if c.options.ExecTimeout == 0 {
+		return checkAgentVersion(ctx, c.helper, pod.Name, command)
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, c.options.ExecTimeout)
+	defer cancel()
+
+	err := checkAgentVersion(execCtx, c.helper, pod.Name, command)
+	if err != nil {
+		return execError(ctx, execCtx, err)
+	}
+
+	return nil
+}
+
+// agentError returns the error of a fault, including the log of the agent if requested
+func (c *PodAgentVisitor) agentError(ctx context.Context, pod corev1.Pod, err error) error {
+	if !c.options.LogsOnError {
+		return err
+	}
+
+	return withAgentLogs(ctx, c.helper, pod.Name, err)
+}
+
+// PodAgentStopVisitor implements PodVisitor, stopping the fault applied by the agent in the Pod, if any
+type PodAgentStopVisitor struct {
+	helper helpers.PodHelper
+}
+
+// hasAgent returns if the agent was injected in the pod or runs as a sidecar
+func hasAgent(pod corev1.Pod) bool {
+	if hasSidecarAgent(pod) {
+		return true
+	}
+
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.Name == "xk6-agent" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Visit stops the fault applied by the agent in the pod
+func (c PodAgentStopVisitor) Visit(ctx context.Context, pod corev1.Pod) error {
+	if !hasAgent(pod) {
+		return nil
+	}
+
+	_, stderr, err := c.helper.Exec(ctx, pod.Name, "xk6-agent", buildStopCmd(), []byte{})
+	if err != nil {
+		return fmt.Errorf("stopping fault in pod %q: %w \n%s", pod.Name, err, string(stderr))
 	}
 
 	return nil
@@ -183,6 +625,41 @@ func (c *PodAgentVisitor) Visit(ctx context.Context, pod corev1.Pod) error {
 type PodAgentVisitorOptions struct {
 	// Defines the timeout for injecting the agent
 	Timeout time.Duration
+	// Image of the agent. Defaults to the image defined in the XK6_DISRUPTOR_AGENT_IMAGE environment variable
+	// or, if not defined, the image that corresponds to this version of the extension.
+	Image string
+	// ImagePullPolicy of the agent image. Defaults to the policy defined in the
+	// XK6_DISRUPTOR_AGENT_IMAGE_PULL_POLICY environment variable or, if not defined, IfNotPresent.
+	ImagePullPolicy corev1.PullPolicy
+	// ImagePullSecrets required for pulling the agent image. Ephemeral containers can only use the
+	// imagePullSecrets of their pod, therefore the agent is not injected in pods that do not reference them.
+	ImagePullSecrets []string
+	// SecurityContext of the agent. Defaults to running as root with the NET_ADMIN capability.
+	SecurityContext *corev1.SecurityContext
+	// Restricted informs the agent it runs without the NET_ADMIN capability
+	Restricted bool
+	// Retries is the number of times the injection of the agent is retried if it fails with a transient error. If
+	// zero, the retries of the PodHelper are used.
+	Retries int
+	// RetryBackoff is the initial delay between retries. The delay doubles after each retry.
+	RetryBackoff time.Duration
+	// LogsOnError adds the last lines of the log of the agent to the error of a failed fault
+	LogsOnError bool
+	// Sidecar uses the agent that runs as a sidecar in the pod instead of injecting it
+	Sidecar bool
+	// ExecTimeout is the maximum time the execution of a command in the agent can last in addition to the duration
+	// of the fault. A zero value does not limit the execution.
+	ExecTimeout time.Duration
+	// ControlAPI applies the faults using the control API of the agent instead of executing the agent commands
+	ControlAPI bool
+	// Images are the images of the agent for the architectures of the nodes (e.g. arm64), which take precedence
+	// over Image
+	Images map[string]string
+	// AgentArgs are the arguments added to the commands executed by the agent, such as the defaults of the
+	// AgentConfig
+	AgentArgs []string
+	// Volumes of the pod mounted in the agent under AgentVolumesPath
+	Volumes []string
 }
 
 // PodVisitCommand is a command that can be run on a given pod.
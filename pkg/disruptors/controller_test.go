@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"github.com/google/go-cmp/cmp"
@@ -102,6 +105,113 @@ func Test_PodAgentVisitor(t *testing.T) {
 			expectError: true,
 			expected:    nil,
 		},
+		{
+			title:     "pod references image pull secret",
+			namespace: "test-ns",
+			pod: builders.NewPodBuilder("pod1").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithImagePullSecret("registry").
+				Build(),
+			visitCmds: visitCommands(),
+			err:       nil,
+			options: PodAgentVisitorOptions{
+				Timeout:          -1,
+				ImagePullSecrets: []string{"registry"},
+			},
+			expectError: false,
+			expected: []helpers.Command{
+				{Pod: "pod1", Container: "xk6-agent", Namespace: "test-ns", Command: []string{"command"}, Stdin: []byte{}},
+			},
+		},
+		{
+			title:     "sidecar agent",
+			namespace: "test-ns",
+			pod: builders.NewPodBuilder("pod1").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithContainer(corev1.Container{Name: "xk6-agent"}).
+				Build(),
+			visitCmds: visitCommands(),
+			err:       nil,
+			stdout:    []byte(`{"version":"v1.0.0","protocol":1,"commands":["http"]}`),
+			options: PodAgentVisitorOptions{
+				Timeout: -1,
+				Sidecar: true,
+			},
+			expectError: false,
+			expected: []helpers.Command{
+				{Pod: "pod1", Container: "xk6-agent", Namespace: "test-ns", Command: buildVersionCmd(), Stdin: []byte{}},
+				{Pod: "pod1", Container: "xk6-agent", Namespace: "test-ns", Command: []string{"command"}, Stdin: []byte{}},
+			},
+		},
+		{
+			title:     "pod does not run sidecar agent",
+			namespace: "test-ns",
+			pod: builders.NewPodBuilder("pod1").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				Build(),
+			visitCmds: visitCommands(),
+			err:       nil,
+			options: PodAgentVisitorOptions{
+				Timeout: -1,
+				Sidecar: true,
+			},
+			expectError: true,
+			expected:    nil,
+		},
+		{
+			title:     "pod has agent volume",
+			namespace: "test-ns",
+			pod: builders.NewPodBuilder("pod1").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				WithSecretVolume("tls-certs", "app-tls").
+				Build(),
+			visitCmds: visitCommands(),
+			err:       nil,
+			options: PodAgentVisitorOptions{
+				Timeout: -1,
+				Volumes: []string{"tls-certs"},
+			},
+			expectError: false,
+			expected: []helpers.Command{
+				{Pod: "pod1", Container: "xk6-agent", Namespace: "test-ns", Command: []string{"command"}, Stdin: []byte{}},
+			},
+		},
+		{
+			title:     "pod does not have agent volume",
+			namespace: "test-ns",
+			pod: builders.NewPodBuilder("pod1").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				Build(),
+			visitCmds: visitCommands(),
+			err:       nil,
+			options: PodAgentVisitorOptions{
+				Timeout: -1,
+				Volumes: []string{"tls-certs"},
+			},
+			expectError: true,
+			expected:    nil,
+		},
+		{
+			title:     "pod does not reference image pull secret",
+			namespace: "test-ns",
+			pod: builders.NewPodBuilder("pod1").
+				WithNamespace("test-ns").
+				WithIP("192.0.2.6").
+				Build(),
+			visitCmds: visitCommands(),
+			err:       nil,
+			options: PodAgentVisitorOptions{
+				Timeout:          -1,
+				ImagePullSecrets: []string{"registry"},
+			},
+			expectError: true,
+			expected:    nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -147,6 +257,64 @@ func Test_PodAgentVisitor(t *testing.T) {
 	}
 }
 
+func Test_PodAgentVisitorEvents(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		err      error
+		expected []string
+	}{
+		{
+			title:    "fault ended",
+			err:      nil,
+			expected: []string{EventAgentInjected, EventFaultStarted, EventFaultEnded},
+		},
+		{
+			title:    "fault failed",
+			err:      errFailed,
+			expected: []string{EventAgentInjected, EventFaultStarted, EventFaultFailed},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+			client := fake.NewSimpleClientset(&pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+			visitor := NewPodAgentVisitor(helper, PodAgentVisitorOptions{Timeout: -1}, visitCommands())
+
+			executor.SetResult(nil, nil, tc.err)
+			_ = visitor.Visit(context.TODO(), pod)
+
+			events, err := client.CoreV1().Events("test-ns").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			reasons := []string{}
+			for _, event := range events.Items {
+				if event.InvolvedObject.Name != pod.Name {
+					t.Fatalf("event %q is not about the target pod", event.Reason)
+				}
+				if event.Labels[RunIDLabel] != RunID() {
+					t.Fatalf("event %q is not labeled with the run ID", event.Reason)
+				}
+				reasons = append(reasons, event.Reason)
+			}
+
+			if diff := cmp.Diff(tc.expected, reasons); diff != "" {
+				t.Fatalf("expected events do not match recorded:\n%s", diff)
+			}
+		})
+	}
+}
+
 var errFailed = errors.New("failed")
 
 func Test_PodController(t *testing.T) {
@@ -240,7 +408,7 @@ func Test_PodController(t *testing.T) {
 		t.Run(tc.title, func(t *testing.T) {
 			t.Parallel()
 
-			controller := NewPodController(tc.targets)
+			controller := NewPodController(tc.targets, PodControllerOptions{})
 
 			ctx, cancel := context.WithTimeout(context.TODO(), 1*time.Second)
 			defer cancel()
@@ -252,3 +420,283 @@ func Test_PodController(t *testing.T) {
 		})
 	}
 }
+
+func Test_PodControllerConcurrency(t *testing.T) {
+	t.Parallel()
+
+	targets := []corev1.Pod{}
+	for i := range 6 {
+		targets = append(targets, builders.NewPodBuilder(fmt.Sprintf("pod%d", i)).WithNamespace("test-ns").Build())
+	}
+
+	testCases := []struct {
+		title    string
+		options  PodControllerOptions
+		expected int
+	}{
+		{
+			title:    "unlimited concurrency",
+			options:  PodControllerOptions{},
+			expected: 6,
+		},
+		{
+			title:    "limited concurrency",
+			options:  PodControllerOptions{Concurrency: 2},
+			expected: 2,
+		},
+		{
+			title:    "limited rate",
+			options:  PodControllerOptions{Rate: 10},
+			expected: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			mtx := sync.Mutex{}
+			active := 0
+			maxActive := 0
+			visitor := PodVisitorFunc(func(_ context.Context, _ corev1.Pod) error {
+				mtx.Lock()
+				active++
+				maxActive = max(maxActive, active)
+				mtx.Unlock()
+
+				time.Sleep(50 * time.Millisecond)
+
+				mtx.Lock()
+				active--
+				mtx.Unlock()
+				return nil
+			})
+
+			err := NewPodController(targets, tc.options).Visit(context.TODO(), visitor)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if maxActive != tc.expected {
+				t.Fatalf("expected %d concurrent visits got %d", tc.expected, maxActive)
+			}
+		})
+	}
+}
+
+func Test_PodControllerContinueOnError(t *testing.T) {
+	t.Parallel()
+
+	targets := []corev1.Pod{}
+	for i := range 4 {
+		targets = append(targets, builders.NewPodBuilder(fmt.Sprintf("pod%d", i)).WithNamespace("test-ns").Build())
+	}
+
+	mtx := sync.Mutex{}
+	visited := 0
+	visitor := PodVisitorFunc(func(_ context.Context, pod corev1.Pod) error {
+		mtx.Lock()
+		visited++
+		mtx.Unlock()
+
+		if pod.Name == "pod1" || pod.Name == "pod3" {
+			return fmt.Errorf("visiting pod %q", pod.Name)
+		}
+		return nil
+	})
+
+	err := NewPodController(targets, PodControllerOptions{ContinueOnError: true}).Visit(context.TODO(), visitor)
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+
+	if visited != len(targets) {
+		t.Fatalf("expected %d visits got %d", len(targets), visited)
+	}
+
+	for _, pod := range []string{"pod1", "pod3"} {
+		if !strings.Contains(err.Error(), pod) {
+			t.Fatalf("expected error of %q in %v", pod, err)
+		}
+	}
+}
+
+func Test_PodAgentVisitorWaitReady(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		err         error
+		stdout      []byte
+		stderr      []byte
+		expectError bool
+		expectedMsg string
+	}{
+		{
+			title:       "agent ready",
+			err:         nil,
+			expectError: false,
+		},
+		{
+			title:       "agent not ready",
+			err:         errFailed,
+			stderr:      []byte("agent is not ready"),
+			expectError: true,
+		},
+		{
+			title:       "agent does not report readiness",
+			err:         errFailed,
+			stderr:      []byte(`unknown command "ready" for "xk6-disruptor-agent"`),
+			expectError: false,
+		},
+		{
+			title:       "agent ready but not healthy",
+			err:         errFailed,
+			stdout:      []byte(`{"healthy":false,"ready":true,"problems":["proxy is not listening on port 8000"]}`),
+			expectError: false,
+		},
+		{
+			title:       "agent not healthy",
+			err:         errFailed,
+			stdout:      []byte(`{"healthy":false,"ready":false,"problems":["agent has not reported it is ready"]}`),
+			expectError: true,
+			expectedMsg: "agent has not reported it is ready",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+			client := fake.NewSimpleClientset(&pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+			visitor := NewPodAgentVisitor(helper, PodAgentVisitorOptions{Timeout: time.Second}, visitCommands())
+
+			executor.SetResult(tc.stdout, tc.stderr, tc.err)
+			err := visitor.waitAgentReady(context.TODO(), pod)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError && !strings.Contains(err.Error(), string(tc.stderr)) {
+				t.Fatalf("returned error message should contain stderr (%q)", string(tc.stderr))
+			}
+
+			if tc.expectError && !strings.Contains(err.Error(), tc.expectedMsg) {
+				t.Fatalf("returned error message should contain %q", tc.expectedMsg)
+			}
+		})
+	}
+}
+
+func Test_PodControllerPartialFailure(t *testing.T) {
+	t.Parallel()
+
+	targets := []corev1.Pod{}
+	for i := range 3 {
+		targets = append(targets, builders.NewPodBuilder(fmt.Sprintf("pod%d", i)).WithNamespace("test-ns").Build())
+	}
+
+	testCases := []struct {
+		title             string
+		options           PodControllerOptions
+		failing           []string
+		expectError       bool
+		expectAccepted    bool
+		expectedFailed    []string
+		expectedSucceeded []string
+	}{
+		{
+			title:       "no failures",
+			options:     PodControllerOptions{AllowPartial: true},
+			failing:     nil,
+			expectError: false,
+		},
+		{
+			title:             "partial failure accepted",
+			options:           PodControllerOptions{AllowPartial: true},
+			failing:           []string{"pod0", "pod2"},
+			expectError:       true,
+			expectAccepted:    true,
+			expectedFailed:    []string{"pod0", "pod2"},
+			expectedSucceeded: []string{"pod1"},
+		},
+		{
+			title:             "partial failure not accepted",
+			options:           PodControllerOptions{ContinueOnError: true},
+			failing:           []string{"pod1"},
+			expectError:       true,
+			expectAccepted:    false,
+			expectedFailed:    []string{"pod1"},
+			expectedSucceeded: []string{"pod0", "pod2"},
+		},
+		{
+			title:             "all targets failed",
+			options:           PodControllerOptions{AllowPartial: true},
+			failing:           []string{"pod0", "pod1", "pod2"},
+			expectError:       true,
+			expectAccepted:    false,
+			expectedFailed:    []string{"pod0", "pod1", "pod2"},
+			expectedSucceeded: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			visitor := PodVisitorFunc(func(_ context.Context, pod corev1.Pod) error {
+				if slices.Contains(tc.failing, pod.Name) {
+					return errFailed
+				}
+				return nil
+			})
+
+			err := NewPodController(targets, tc.options).Visit(context.TODO(), visitor)
+			if !tc.expectError {
+				if err != nil {
+					t.Fatalf("failed: %v", err)
+				}
+				return
+			}
+
+			targetsErr := &TargetsError{}
+			if !errors.As(err, &targetsErr) {
+				t.Fatalf("expected TargetsError got %v", err)
+			}
+
+			if targetsErr.Accepted != tc.expectAccepted {
+				t.Fatalf("expected accepted %t got %t", tc.expectAccepted, targetsErr.Accepted)
+			}
+
+			failed := []string{}
+			for _, target := range targetsErr.Failed {
+				failed = append(failed, target.Target)
+			}
+
+			if diff := cmp.Diff(tc.expectedFailed, failed); diff != "" {
+				t.Fatalf("expected failed targets do not match returned\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.expectedSucceeded, targetsErr.Succeeded); diff != "" {
+				t.Fatalf("expected succeeded targets do not match returned\n%s", diff)
+			}
+
+			if !errors.Is(err, errFailed) {
+				t.Fatalf("expected error to wrap the errors of the targets")
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeCordonFault specifies a fault that marks a set of nodes as unschedulable
+type NodeCordonFault struct {
+	// Drain indicates if the pods running in the node must be evicted after it is cordoned
+	Drain bool `js:"drain"`
+	// Timeout specifies the maximum time to wait for the evicted pods to terminate
+	Timeout time.Duration `js:"timeout"`
+	// GracePeriod overrides the termination grace period of the evicted pods
+	GracePeriod time.Duration `js:"gracePeriod"`
+}
+
+// NodeCordonVisitor defines a Visitor that cordons its target node for the duration of the fault
+type NodeCordonVisitor struct {
+	helper   helpers.NodeHelper
+	fault    NodeCordonFault
+	duration time.Duration
+}
+
+// Visit cordons the target node, optionally drains it, and uncordons it after the fault duration
+func (c NodeCordonVisitor) Visit(ctx context.Context, node corev1.Node) error {
+	if c.fault.Timeout == 0 {
+		c.fault.Timeout = 60 * time.Second
+	}
+
+	expired := time.After(c.duration)
+
+	err := c.helper.Cordon(ctx, node.Name)
+	if err != nil {
+		return err
+	}
+
+	// nodes that were unschedulable before the fault are left as they were
+	if !node.Spec.Unschedulable {
+		defer func() {
+			// we use a fresh context because the context of the visit may have been cancelled
+			//nolint:contextcheck
+			_ = c.helper.Uncordon(context.TODO(), node.Name)
+		}()
+	}
+
+	if c.fault.Drain {
+		err = c.helper.Drain(
+			ctx,
+			node.Name,
+			helpers.DrainOptions{
+				Timeout:     c.fault.Timeout,
+				GracePeriod: c.fault.GracePeriod,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("draining node %q: %w", node.Name, err)
+		}
+	}
+
+	select {
+	case <-expired:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
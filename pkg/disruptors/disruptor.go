@@ -6,4 +6,15 @@ import "context"
 type Disruptor interface {
 	// Targets returns the names of the targets for the disruptor
 	Targets(ctx context.Context) ([]string, error)
+	// Stop stops the faults that are active in the targets of the disruptor before their duration elapses.
+	// Only the faults applied by the disruptor agent can be stopped.
+	Stop(ctx context.Context) error
+	// Status returns the state of the last fault applied by the disruptor agent in each target
+	Status(ctx context.Context) ([]TargetStatus, error)
+	// Shutdown stops the faults and terminates the disruptor agents in the targets, removing the resources left
+	// behind by the faults. Ephemeral containers cannot be restarted, therefore no more faults can be injected in the
+	// pods whose agent was terminated until they are restarted.
+	Shutdown(ctx context.Context) error
+	// Logs returns the logs of the disruptor agent in each target
+	Logs(ctx context.Context) ([]TargetLogs, error)
 }
@@ -0,0 +1,65 @@
+package disruptors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dryRunCommands writes to out the command the agent would run in each target for executing the visit command,
+// without injecting the agent or running anything in the targets
+func dryRunCommands(out io.Writer, targets []corev1.Pod, command PodVisitCommand) error {
+	for _, pod := range targets {
+		commands, err := command.Commands(pod)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(out, "%s/%s: %s\n", pod.Namespace, pod.Name, quoteCommand(commands.Exec))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dryRunTermination writes to out the targets that would be terminated, without terminating them
+func dryRunTermination(out io.Writer, targets []corev1.Pod) error {
+	for _, pod := range targets {
+		_, err := fmt.Fprintf(out, "%s/%s: terminate\n", pod.Namespace, pod.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dryRunChaos writes to out the command the agent would run in each target for each of the faults that can be
+// selected by the chaos spec, with their maximum duration
+func dryRunChaos(out io.Writer, targets []corev1.Pod, spec ChaosSpec) error {
+	for _, faults := range spec.candidates() {
+		err := dryRunCommands(out, targets, PodComposedFaultCommand{faults: faults, duration: spec.MaxFaultDuration})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// quoteCommand returns the command as a string that can be pasted in a shell
+func quoteCommand(command []string) string {
+	args := make([]string, len(command))
+	for i, arg := range command {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`|&;<>()*?[]{}#~!") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		args[i] = arg
+	}
+
+	return strings.Join(args, " ")
+}
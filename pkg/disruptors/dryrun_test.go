@@ -0,0 +1,80 @@
+package disruptors
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_DryRunCommands(t *testing.T) {
+	t.Parallel()
+
+	targets := []corev1.Pod{
+		builders.NewPodBuilder("pod-1").WithNamespace("ns-1").Build(),
+		builders.NewPodBuilder("pod-2").WithNamespace("ns-2").Build(),
+	}
+
+	testCases := []struct {
+		title       string
+		command     PodVisitCommand
+		expectError bool
+		expected    string
+	}{
+		{
+			title: "simple command",
+			command: fakeCommand{
+				exec: []string{"xk6-disruptor-agent", "http", "-d", "60s"},
+			},
+			expectError: false,
+			expected: "ns-1/pod-1: xk6-disruptor-agent http -d 60s\n" +
+				"ns-2/pod-2: xk6-disruptor-agent http -d 60s\n",
+		},
+		{
+			title: "arguments are quoted",
+			command: fakeCommand{
+				exec: []string{"xk6-disruptor-agent", "repeat", "--fault", `["http","-e","it's"]`, "--body", ""},
+			},
+			expectError: false,
+			expected: `ns-1/pod-1: xk6-disruptor-agent repeat --fault '["http","-e","it'\''s"]' --body ''` + "\n" +
+				`ns-2/pod-2: xk6-disruptor-agent repeat --fault '["http","-e","it'\''s"]' --body ''` + "\n",
+		},
+		{
+			title: "error building command",
+			command: fakeCommand{
+				err: fmt.Errorf("fake error"),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			err := dryRunCommands(out, targets, tc.command)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expected, out.String()); diff != "" {
+				t.Fatalf("expected output does not match returned\n%s", diff)
+			}
+		})
+	}
+}
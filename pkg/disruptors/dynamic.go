@@ -0,0 +1,362 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultDynamicTargetsInterval is the default interval for looking for new targets
+const DefaultDynamicTargetsInterval = 5 * time.Second
+
+// DefaultRestartTimeout is the default time to wait for the restart of a target whose visit failed
+const DefaultRestartTimeout = 30 * time.Second
+
+// PodTargetsFunc returns the current targets of a disruptor
+type PodTargetsFunc func(context.Context) ([]corev1.Pod, error)
+
+// PodVisitorBuilder returns the PodVisitor for a visit that lasts the given duration
+type PodVisitorBuilder func(duration time.Duration) PodVisitor
+
+// DynamicPodControllerOptions defines the options for the DynamicPodController
+type DynamicPodControllerOptions struct {
+	// Interval for looking for new targets. A zero value forces default
+	Interval time.Duration
+	// MaxTargets limits the total number of targets visited. A zero value does not limit the targets
+	MaxTargets int
+	// Reapply visits again, for the remaining duration, the targets whose visit fails because their containers
+	// restarted. The visit error is returned if the target does not restart within the RestartTimeout
+	Reapply bool
+	// RestartTimeout is the time to wait for the restart of a target whose visit failed. A zero value forces default
+	RestartTimeout time.Duration
+	// Replace visits, for the remaining duration, the pods that replace the targets deleted during the visit.
+	// A pod replaces a deleted target if both are controlled by the same owner (e.g. a ReplicaSet)
+	Replace bool
+	// OnReplace is called when a pod that replaces a deleted target is visited
+	OnReplace func(replaced corev1.Pod, replacement corev1.Pod)
+	// IgnoreNewTargets does not visit the pods discovered during the visit, except the replacements of targets
+	IgnoreNewTargets bool
+	// Changes notifies changes in the pods, which triggers looking for new targets without waiting for the interval.
+	// If nil, targets are only looked for at every interval.
+	Changes <-chan struct{}
+}
+
+// DynamicPodController visits a list of pods and, while the visit lasts, periodically looks for new
+// targets and visits them for the remaining of the duration of the visit.
+type DynamicPodController struct {
+	targets  []corev1.Pod
+	discover PodTargetsFunc
+	options  DynamicPodControllerOptions
+}
+
+// NewDynamicPodController creates a controller that visits the given targets and the new targets returned
+// by the discover function
+func NewDynamicPodController(
+	targets []corev1.Pod,
+	discover PodTargetsFunc,
+	options DynamicPodControllerOptions,
+) *DynamicPodController {
+	if options.Interval == 0 {
+		options.Interval = DefaultDynamicTargetsInterval
+	}
+
+	if options.RestartTimeout == 0 {
+		options.RestartTimeout = DefaultRestartTimeout
+	}
+
+	return &DynamicPodController{
+		targets:  targets,
+		discover: discover,
+		options:  options,
+	}
+}
+
+// podKey returns a key that identifies a pod
+func podKey(pod corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
+
+// restartCount returns the number of restarts of the containers of a pod
+func restartCount(pod corev1.Pod) int32 {
+	var restarts int32
+	for _, status := range pod.Status.ContainerStatuses {
+		restarts += status.RestartCount
+	}
+
+	return restarts
+}
+
+// failedVisit is a visit that failed and will be repeated if its target restarts or is replaced
+type failedVisit struct {
+	pod      corev1.Pod
+	err      error
+	failedAt time.Time
+}
+
+// dynamicVisit keeps the state of a visit of a DynamicPodController
+type dynamicVisit struct {
+	build PodVisitorBuilder
+	// holdFailures defers the report of failed visits, as their target may restart or be replaced
+	holdFailures bool
+	visited      map[string]corev1.Pod
+	restarts     map[string]int32
+	replaced     map[string]bool
+	failed       map[string]failedVisit
+	errCh        chan error
+	failCh       chan failedVisit
+	wg           sync.WaitGroup
+}
+
+// visit starts visiting the pod for the given duration
+func (v *dynamicVisit) visit(ctx context.Context, pod corev1.Pod, duration time.Duration) {
+	v.visited[podKey(pod)] = pod
+	v.restarts[podKey(pod)] = restartCount(pod)
+	v.wg.Add(1)
+	go func() {
+		defer v.wg.Done()
+		err := v.build(duration).Visit(ctx, pod)
+		if err == nil {
+			return
+		}
+
+		if v.holdFailures {
+			select {
+			case v.failCh <- failedVisit{pod: pod, err: err, failedAt: time.Now()}:
+			case <-ctx.Done():
+			}
+			return
+		}
+
+		// report only the first error
+		select {
+		case v.errCh <- err:
+		default:
+		}
+	}()
+}
+
+// wait waits for all the visits to complete and returns the first error reported
+func (v *dynamicVisit) wait(ctx context.Context) error {
+	doneCh := make(chan struct{})
+	go func() {
+		v.wg.Wait()
+		close(doneCh)
+	}()
+
+	select {
+	case err := <-v.errCh:
+		return err
+	case failed := <-v.failCh:
+		// the remaining duration is too short for re-applying the visit
+		return failed.err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-doneCh:
+		// a visit may have failed just before completing
+		select {
+		case err := <-v.errCh:
+			return err
+		default:
+			return nil
+		}
+	}
+}
+
+// discoverTargets visits the targets not visited yet for the remaining duration
+func (c *DynamicPodController) discoverTargets(ctx context.Context, v *dynamicVisit, remaining time.Duration) error {
+	pods, err := c.discover(ctx)
+	if err != nil && !errors.Is(err, ErrSelectorNoPods) {
+		return err
+	}
+
+	if c.options.Replace {
+		c.replaceDeleted(ctx, v, pods, remaining)
+	}
+
+	err = c.reapplyFailed(ctx, v, pods, remaining)
+	if err != nil {
+		return err
+	}
+
+	if c.options.IgnoreNewTargets {
+		return nil
+	}
+
+	for _, pod := range pods {
+		if c.options.MaxTargets > 0 && len(v.visited) >= c.options.MaxTargets {
+			break
+		}
+
+		// pods that are not running yet are visited when they start running
+		if _, visited := v.visited[podKey(pod)]; visited || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		v.visit(ctx, pod, remaining)
+	}
+
+	return nil
+}
+
+// replaceDeleted visits for the remaining duration the pods that replace the targets that were deleted
+func (c *DynamicPodController) replaceDeleted(
+	ctx context.Context,
+	v *dynamicVisit,
+	pods []corev1.Pod,
+	remaining time.Duration,
+) {
+	current := map[string]bool{}
+	for _, pod := range pods {
+		current[podKey(pod)] = pod.DeletionTimestamp == nil
+	}
+
+	for key, target := range v.visited {
+		owner := metav1.GetControllerOf(&target)
+		if v.replaced[key] || current[key] || owner == nil {
+			continue
+		}
+
+		for _, pod := range pods {
+			if _, visited := v.visited[podKey(pod)]; visited || !current[podKey(pod)] {
+				continue
+			}
+
+			podOwner := metav1.GetControllerOf(&pod)
+			if pod.Status.Phase != corev1.PodRunning || podOwner == nil || podOwner.UID != owner.UID {
+				continue
+			}
+
+			v.replaced[key] = true
+			v.visit(ctx, pod, remaining)
+			if c.options.OnReplace != nil {
+				c.options.OnReplace(target, pod)
+			}
+
+			break
+		}
+	}
+}
+
+// restarted returns the current state of the pod with the given key if it is running and has restarted since it was
+// visited
+func (v *dynamicVisit) restarted(key string, pods []corev1.Pod) (corev1.Pod, bool) {
+	for _, pod := range pods {
+		if podKey(pod) == key {
+			return pod, pod.Status.Phase == corev1.PodRunning && restartCount(pod) > v.restarts[key]
+		}
+	}
+
+	return corev1.Pod{}, false
+}
+
+// pendingFailures returns the error of the failed visits whose targets did not restart and were not replaced when the
+// visit completes
+func (c *DynamicPodController) pendingFailures(ctx context.Context, v *dynamicVisit) error {
+	if len(v.failed) == 0 {
+		return nil
+	}
+
+	pods, err := c.discover(ctx)
+	if err != nil && !errors.Is(err, ErrSelectorNoPods) {
+		return err
+	}
+
+	for key, failed := range v.failed {
+		_, restarted := v.restarted(key, pods)
+		if !(c.options.Reapply && restarted) && !v.replaced[key] {
+			return failed.err
+		}
+	}
+
+	return nil
+}
+
+// reapplyFailed visits again for the remaining duration the targets whose visit failed and have restarted since
+// they were visited. Returns the error of the visits of targets that did not restart within the restart timeout.
+func (c *DynamicPodController) reapplyFailed(
+	ctx context.Context,
+	v *dynamicVisit,
+	pods []corev1.Pod,
+	remaining time.Duration,
+) error {
+	for key, failed := range v.failed {
+		// the visit of a target that was replaced is not repeated
+		if v.replaced[key] {
+			delete(v.failed, key)
+			continue
+		}
+
+		pod, restarted := v.restarted(key, pods)
+		if c.options.Reapply && restarted {
+			delete(v.failed, key)
+			v.visit(ctx, pod, remaining)
+			continue
+		}
+
+		if time.Since(failed.failedAt) > c.options.RestartTimeout {
+			return failed.err
+		}
+	}
+
+	return nil
+}
+
+// Visit visits the targets with the visitor returned by the builder for the given duration. Pods discovered
+// during the visit are visited with a visitor for the remaining duration.
+func (c *DynamicPodController) Visit(ctx context.Context, duration time.Duration, build PodVisitorBuilder) error {
+	// create context for the visit, that can be cancelled in case of error
+	visitCtx, cancelVisit := context.WithCancel(ctx)
+	defer cancelVisit()
+
+	v := &dynamicVisit{
+		build:        build,
+		holdFailures: c.options.Reapply || c.options.Replace,
+		visited:      map[string]corev1.Pod{},
+		restarts:     map[string]int32{},
+		replaced:     map[string]bool{},
+		failed:       map[string]failedVisit{},
+		errCh:        make(chan error, 1),
+		failCh:       make(chan failedVisit),
+	}
+
+	deadline := time.Now().Add(duration)
+	for _, pod := range c.targets {
+		v.visit(visitCtx, pod, duration)
+	}
+
+	ticker := time.NewTicker(c.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		// the agent accepts durations in seconds
+		remaining := time.Until(deadline).Truncate(time.Second)
+		if remaining <= 0 {
+			if err := v.wait(ctx); err != nil {
+				return err
+			}
+
+			return c.pendingFailures(ctx, v)
+		}
+
+		select {
+		case err := <-v.errCh:
+			return err
+		case failed := <-v.failCh:
+			v.failed[podKey(failed.pod)] = failed
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := c.discoverTargets(visitCtx, v, remaining); err != nil {
+				return err
+			}
+		case <-c.options.Changes:
+			if err := c.discoverTargets(visitCtx, v, remaining); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func Test_DynamicPodController(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		targets     []corev1.Pod
+		discovered  []corev1.Pod
+		maxTargets  int
+		failOn      string
+		expectError bool
+		expected    []string
+	}{
+		{
+			title: "new targets are visited",
+			targets: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").WithPhase(corev1.PodRunning).Build(),
+			},
+			discovered: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").WithPhase(corev1.PodRunning).Build(),
+				builders.NewPodBuilder("pod-2").WithPhase(corev1.PodRunning).Build(),
+			},
+			expectError: false,
+			expected:    []string{"pod-1", "pod-2"},
+		},
+		{
+			title: "pending targets are not visited",
+			targets: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").WithPhase(corev1.PodRunning).Build(),
+			},
+			discovered: []corev1.Pod{
+				builders.NewPodBuilder("pod-2").WithPhase(corev1.PodPending).Build(),
+			},
+			expectError: false,
+			expected:    []string{"pod-1"},
+		},
+		{
+			title: "max targets",
+			targets: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").WithPhase(corev1.PodRunning).Build(),
+			},
+			discovered: []corev1.Pod{
+				builders.NewPodBuilder("pod-2").WithPhase(corev1.PodRunning).Build(),
+			},
+			maxTargets:  1,
+			expectError: false,
+			expected:    []string{"pod-1"},
+		},
+		{
+			title: "failed visit of new target",
+			targets: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").WithPhase(corev1.PodRunning).Build(),
+			},
+			discovered: []corev1.Pod{
+				builders.NewPodBuilder("pod-2").WithPhase(corev1.PodRunning).Build(),
+			},
+			failOn:      "pod-2",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			mtx := sync.Mutex{}
+			visited := []string{}
+			durations := map[string]time.Duration{}
+
+			build := func(duration time.Duration) PodVisitor {
+				return PodVisitorFunc(func(_ context.Context, pod corev1.Pod) error {
+					mtx.Lock()
+					defer mtx.Unlock()
+
+					visited = append(visited, pod.Name)
+					durations[pod.Name] = duration
+					if pod.Name == tc.failOn {
+						return errors.New("failed")
+					}
+
+					return nil
+				})
+			}
+
+			discover := func(_ context.Context) ([]corev1.Pod, error) {
+				return tc.discovered, nil
+			}
+
+			controller := NewDynamicPodController(
+				tc.targets,
+				discover,
+				DynamicPodControllerOptions{
+					Interval:   100 * time.Millisecond,
+					MaxTargets: tc.maxTargets,
+				},
+			)
+
+			err := controller.Visit(context.TODO(), 2*time.Second, build)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			sort.Strings(visited)
+			if diff := cmp.Diff(tc.expected, visited); diff != "" {
+				t.Fatalf("expected visited pods do not match\n%s", diff)
+			}
+
+			// targets discovered during the visit are visited for the remaining duration
+			for _, pod := range tc.targets {
+				if durations[pod.Name] != 2*time.Second {
+					t.Fatalf("expected initial target %q visited for 2s got %s", pod.Name, durations[pod.Name])
+				}
+			}
+			for _, pod := range visited[len(tc.targets):] {
+				if durations[pod] >= 2*time.Second {
+					t.Fatalf("expected target %q visited for less than 2s got %s", pod, durations[pod])
+				}
+			}
+		})
+	}
+}
+
+func Test_DynamicPodControllerChanges(t *testing.T) {
+	t.Parallel()
+
+	// the visit is cancelled once the new target is visited, or times out if the target is not discovered
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	build := func(_ time.Duration) PodVisitor {
+		return PodVisitorFunc(func(_ context.Context, pod corev1.Pod) error {
+			if pod.Name == "pod-2" {
+				cancel()
+			}
+			return nil
+		})
+	}
+
+	discover := func(_ context.Context) ([]corev1.Pod, error) {
+		return []corev1.Pod{
+			builders.NewPodBuilder("pod-1").WithPhase(corev1.PodRunning).Build(),
+			builders.NewPodBuilder("pod-2").WithPhase(corev1.PodRunning).Build(),
+		}, nil
+	}
+
+	// the interval is longer than the visit, so the new target is only discovered if the change is notified
+	changes := make(chan struct{}, 1)
+	changes <- struct{}{}
+
+	controller := NewDynamicPodController(
+		[]corev1.Pod{builders.NewPodBuilder("pod-1").WithPhase(corev1.PodRunning).Build()},
+		discover,
+		DynamicPodControllerOptions{
+			Interval: time.Hour,
+			Changes:  changes,
+		},
+	)
+
+	err := controller.Visit(ctx, 10*time.Second, build)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("new target not visited: %v", err)
+	}
+}
+
+func Test_DynamicPodControllerReapply(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		restarts    int32
+		expectError bool
+		expected    int
+	}{
+		{
+			title:       "target restarted",
+			restarts:    1,
+			expectError: false,
+			expected:    2,
+		},
+		{
+			title:       "target not restarted",
+			restarts:    0,
+			expectError: true,
+			expected:    1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			mtx := sync.Mutex{}
+			visits := 0
+
+			// the first visit fails as if the target restarted
+			build := func(_ time.Duration) PodVisitor {
+				return PodVisitorFunc(func(_ context.Context, _ corev1.Pod) error {
+					mtx.Lock()
+					defer mtx.Unlock()
+
+					visits++
+					if visits == 1 {
+						return errors.New("failed")
+					}
+
+					return nil
+				})
+			}
+
+			discover := func(_ context.Context) ([]corev1.Pod, error) {
+				return []corev1.Pod{
+					builders.NewPodBuilder("pod-1").
+						WithPhase(corev1.PodRunning).
+						WithRestarts(tc.restarts, time.Now()).
+						Build(),
+				}, nil
+			}
+
+			targets := []corev1.Pod{
+				builders.NewPodBuilder("pod-1").WithPhase(corev1.PodRunning).WithRestarts(0, time.Time{}).Build(),
+			}
+
+			controller := NewDynamicPodController(
+				targets,
+				discover,
+				DynamicPodControllerOptions{
+					Interval:         100 * time.Millisecond,
+					IgnoreNewTargets: true,
+					Reapply:          true,
+					RestartTimeout:   500 * time.Millisecond,
+				},
+			)
+
+			err := controller.Visit(context.TODO(), 2*time.Second, build)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			if visits != tc.expected {
+				t.Fatalf("expected %d visits got %d", tc.expected, visits)
+			}
+		})
+	}
+}
+
+func Test_DynamicPodControllerReplace(t *testing.T) {
+	t.Parallel()
+
+	owned := func(name string, owner types.UID) corev1.Pod {
+		controller := true
+		pod := builders.NewPodBuilder(name).WithPhase(corev1.PodRunning).Build()
+		pod.OwnerReferences = []metav1.OwnerReference{
+			{Kind: "ReplicaSet", Name: "rs-" + string(owner), UID: owner, Controller: &controller},
+		}
+		return pod
+	}
+
+	mtx := sync.Mutex{}
+	visited := []string{}
+
+	// the visit of pod-1 fails as it is deleted
+	build := func(_ time.Duration) PodVisitor {
+		return PodVisitorFunc(func(_ context.Context, pod corev1.Pod) error {
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			visited = append(visited, pod.Name)
+			if pod.Name == "pod-1" {
+				return errors.New("pod deleted")
+			}
+
+			return nil
+		})
+	}
+
+	// pod-1 is replaced by pod-3. pod-4 has a different owner
+	discover := func(_ context.Context) ([]corev1.Pod, error) {
+		return []corev1.Pod{owned("pod-2", "a"), owned("pod-3", "a"), owned("pod-4", "b")}, nil
+	}
+
+	replacements := map[string]string{}
+	controller := NewDynamicPodController(
+		[]corev1.Pod{owned("pod-1", "a"), owned("pod-2", "a")},
+		discover,
+		DynamicPodControllerOptions{
+			Interval:         100 * time.Millisecond,
+			IgnoreNewTargets: true,
+			Replace:          true,
+			OnReplace: func(replaced corev1.Pod, replacement corev1.Pod) {
+				replacements[replacement.Name] = replaced.Name
+			},
+		},
+	)
+
+	err := controller.Visit(context.TODO(), 2*time.Second, build)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	sort.Strings(visited)
+	if diff := cmp.Diff([]string{"pod-1", "pod-2", "pod-3"}, visited); diff != "" {
+		t.Fatalf("expected visited pods do not match\n%s", diff)
+	}
+
+	if diff := cmp.Diff(map[string]string{"pod-3": "pod-1"}, replacements); diff != "" {
+		t.Fatalf("expected replacements do not match\n%s", diff)
+	}
+}
@@ -0,0 +1,46 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reasons of the events recorded on the target pods, which allow cluster operators to correlate the alerts of
+// their applications with the faults injected by a test
+const (
+	// EventAgentInjected is recorded when the agent is injected in the pod
+	EventAgentInjected = "AgentInjected"
+	// EventFaultStarted is recorded when the agent starts applying a fault in the pod
+	EventFaultStarted = "FaultStarted"
+	// EventFaultEnded is recorded when the agent finishes applying a fault in the pod
+	EventFaultEnded = "FaultEnded"
+	// EventFaultFailed is recorded when the agent fails applying a fault in the pod
+	EventFaultFailed = "FaultFailed"
+)
+
+// recordEvent records an event on the pod labeled with the ID of the test run. Recording events is best effort:
+// errors (for example, not having permission to create events) are ignored.
+func recordEvent(ctx context.Context, helper helpers.PodHelper, pod corev1.Pod, eventType, reason, message string) {
+	runID := RunID()
+	_ = helper.RecordEvent(ctx, pod, helpers.Event{
+		Type:    eventType,
+		Reason:  reason,
+		Message: fmt.Sprintf("%s (k6 run %s)", message, runID),
+		Labels:  map[string]string{RunIDLabel: runID},
+	})
+}
+
+// faultDescription describes the fault applied by the commands, including its parameters
+func faultDescription(commands VisitCommands) string {
+	description := strings.Join(commands.Exec, " ")
+	if commands.Duration > 0 {
+		description = fmt.Sprintf("%s for %s", description, commands.Duration)
+	}
+
+	return description
+}
@@ -0,0 +1,48 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+)
+
+// ErrExecTimeout is returned when the execution of a command in the agent exceeds its timeout
+var ErrExecTimeout = errors.New("agent command timed out")
+
+// execWithTimeout executes a command in the agent of a pod, aborting it if it lasts more than the expected
+// duration plus the timeout. A zero timeout does not limit the execution.
+func execWithTimeout(
+	ctx context.Context,
+	helper helpers.PodHelper,
+	pod string,
+	command []string,
+	duration time.Duration,
+	timeout time.Duration,
+) ([]byte, []byte, error) {
+	if timeout == 0 {
+		return helper.Exec(ctx, pod, "xk6-agent", command, []byte{})
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, duration+timeout)
+	defer cancel()
+
+	stdout, stderr, err := helper.Exec(execCtx, pod, "xk6-agent", command, []byte{})
+	if err != nil {
+		err = execError(ctx, execCtx, err)
+	}
+
+	return stdout, stderr, err
+}
+
+// execError returns ErrExecTimeout if the execution context expired but its parent did not, so the
+// expiration was caused by the timeout of the command
+func execError(ctx context.Context, execCtx context.Context, err error) error {
+	if ctx.Err() == nil && errors.Is(execCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrExecTimeout, err)
+	}
+
+	return err
+}
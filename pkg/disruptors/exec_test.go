@@ -0,0 +1,133 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+)
+
+// blockingExecutor is a PodCommandExecutor that blocks the execution of a command until its context is done
+type blockingExecutor struct {
+	command []string
+}
+
+func (b blockingExecutor) Exec(
+	ctx context.Context,
+	_ string,
+	_ string,
+	_ string,
+	command []string,
+	_ []byte,
+) ([]byte, []byte, error) {
+	if !slices.Equal(command, b.command) {
+		return nil, nil, nil
+	}
+
+	<-ctx.Done()
+	return nil, nil, ctx.Err()
+}
+
+func Test_PodAgentVisitorExecTimeout(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title         string
+		blocked       []string
+		expectError   bool
+		expectTimeout bool
+	}{
+		{
+			title:         "command completes",
+			blocked:       nil,
+			expectError:   false,
+			expectTimeout: false,
+		},
+		{
+			title:         "command times out",
+			blocked:       []string{"command"},
+			expectError:   true,
+			expectTimeout: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+			client := fake.NewSimpleClientset(&pod)
+			executor := blockingExecutor{command: tc.blocked}
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+			visitor := NewPodAgentVisitor(
+				helper,
+				PodAgentVisitorOptions{Timeout: -1, ExecTimeout: 100 * time.Millisecond},
+				visitCommands(),
+			)
+
+			err := visitor.Visit(context.TODO(), pod)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectTimeout != errors.Is(err, ErrExecTimeout) {
+				t.Fatalf("expected timeout: %t, returned error: %v", tc.expectTimeout, err)
+			}
+		})
+	}
+}
+
+func Test_PodAgentVisitorExecCancelled(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+	client := fake.NewSimpleClientset(&pod)
+	executor := blockingExecutor{command: []string{"command"}}
+	helper := helpers.NewPodHelper(client, executor, "test-ns")
+	visitor := NewPodAgentVisitor(
+		helper,
+		PodAgentVisitorOptions{Timeout: -1, ExecTimeout: time.Minute},
+		visitCommands(),
+	)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	// the cancellation of the context is not reported as an error of the target
+	err := visitor.Visit(ctx, pod)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+}
+
+func Test_TargetsErrorTimedOut(t *testing.T) {
+	t.Parallel()
+
+	result := &TargetsError{
+		Succeeded: []string{"pod1"},
+		Failed: []TargetError{
+			{Target: "pod2", Err: errFailed},
+			{Target: "pod3", Err: ErrExecTimeout},
+		},
+	}
+
+	if diff := cmp.Diff([]string{"pod3"}, result.TimedOut()); diff != "" {
+		t.Fatalf("expected timed out targets do not match returned\n%s", diff)
+	}
+}
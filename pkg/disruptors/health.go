@@ -0,0 +1,82 @@
+package disruptors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// HealthCheckNone injects faults in the targets regardless of their health
+	HealthCheckNone = ""
+	// HealthCheckFail fails the injection of faults if any target is unhealthy
+	HealthCheckFail = "fail"
+	// HealthCheckSkip does not inject faults in the unhealthy targets
+	HealthCheckSkip = "skip"
+)
+
+func validateHealthCheck(mode string) error {
+	switch mode {
+	case HealthCheckNone, HealthCheckFail, HealthCheckSkip:
+		return nil
+	default:
+		return fmt.Errorf("healthCheck must be %q or %q: %q", HealthCheckFail, HealthCheckSkip, mode)
+	}
+}
+
+// unhealthyReason returns why a pod is not healthy, or an empty string if it is healthy.
+// A pod is healthy if it is Ready and none of its containers is in CrashLoopBackOff.
+func unhealthyReason(pod corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil && status.State.Waiting.Reason == "CrashLoopBackOff" {
+			return fmt.Sprintf("container %s is in CrashLoopBackOff", status.Name)
+		}
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return ""
+		}
+	}
+
+	return "pod is not Ready"
+}
+
+// checkHealth returns the targets faults can be injected in according to the health check mode.
+// With HealthCheckFail, an error reporting each unhealthy target is returned. With HealthCheckSkip,
+// the unhealthy targets are reported to out and are not returned.
+func checkHealth(out io.Writer, targets []corev1.Pod, mode string) ([]corev1.Pod, error) {
+	if mode == HealthCheckNone {
+		return targets, nil
+	}
+
+	healthy := []corev1.Pod{}
+	unhealthy := []string{}
+	for _, pod := range targets {
+		reason := unhealthyReason(pod)
+		if reason == "" {
+			healthy = append(healthy, pod)
+			continue
+		}
+		unhealthy = append(unhealthy, fmt.Sprintf("%s/%s: %s", pod.Namespace, pod.Name, reason))
+	}
+
+	if len(unhealthy) == 0 {
+		return healthy, nil
+	}
+
+	if mode == HealthCheckFail {
+		return nil, fmt.Errorf("unhealthy targets: %s", strings.Join(unhealthy, ", "))
+	}
+
+	for _, target := range unhealthy {
+		_, err := fmt.Fprintf(out, "skipping unhealthy target %s\n", target)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return healthy, nil
+}
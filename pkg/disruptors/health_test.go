@@ -0,0 +1,93 @@
+package disruptors
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_CheckHealth(t *testing.T) {
+	t.Parallel()
+
+	healthy := builders.NewPodBuilder("healthy").WithNamespace("test-ns").WithReady(true).Build()
+	notReady := builders.NewPodBuilder("not-ready").WithNamespace("test-ns").WithReady(false).Build()
+	crashing := builders.NewPodBuilder("crashing").
+		WithNamespace("test-ns").
+		WithReady(true).
+		WithWaiting("CrashLoopBackOff").
+		Build()
+
+	testCases := []struct {
+		title          string
+		targets        []corev1.Pod
+		mode           string
+		expectError    bool
+		expected       []string
+		expectedOutput string
+	}{
+		{
+			title:       "no health check",
+			targets:     []corev1.Pod{healthy, notReady, crashing},
+			mode:        HealthCheckNone,
+			expectError: false,
+			expected:    []string{"healthy", "not-ready", "crashing"},
+		},
+		{
+			title:       "healthy targets",
+			targets:     []corev1.Pod{healthy},
+			mode:        HealthCheckFail,
+			expectError: false,
+			expected:    []string{"healthy"},
+		},
+		{
+			title:       "fail with unhealthy targets",
+			targets:     []corev1.Pod{healthy, notReady, crashing},
+			mode:        HealthCheckFail,
+			expectError: true,
+		},
+		{
+			title:       "skip unhealthy targets",
+			targets:     []corev1.Pod{healthy, notReady, crashing},
+			mode:        HealthCheckSkip,
+			expectError: false,
+			expected:    []string{"healthy"},
+			expectedOutput: "skipping unhealthy target test-ns/not-ready: pod is not Ready\n" +
+				"skipping unhealthy target test-ns/crashing: container container-0 is in CrashLoopBackOff\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			out := &bytes.Buffer{}
+			targets, err := checkHealth(out, tc.targets, tc.mode)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expected, utils.PodNames(targets)); diff != "" {
+				t.Fatalf("expected targets do not match returned\n%s", diff)
+			}
+
+			if diff := cmp.Diff(tc.expectedOutput, out.String()); diff != "" {
+				t.Fatalf("expected output does not match returned\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package disruptors
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/internal/version"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AgentImageEnvVar is the environment variable that overrides the image of the agent (e.g. for using a
+	// private registry)
+	AgentImageEnvVar = "XK6_DISRUPTOR_AGENT_IMAGE"
+	// AgentImagePullPolicyEnvVar is the environment variable that overrides the pull policy of the agent image
+	AgentImagePullPolicyEnvVar = "XK6_DISRUPTOR_AGENT_IMAGE_PULL_POLICY"
+	// AgentImagePullSecretsEnvVar is the environment variable that defines a comma-separated list of the secrets
+	// used for pulling the agent image
+	AgentImagePullSecretsEnvVar = "XK6_DISRUPTOR_AGENT_IMAGE_PULL_SECRETS"
+)
+
+// defaultAgentImage returns the image defined by the AgentImageEnvVar or, if not defined, the image that
+// corresponds to this version of the extension
+func defaultAgentImage() string {
+	if image := os.Getenv(AgentImageEnvVar); image != "" {
+		return image
+	}
+
+	return version.AgentImage()
+}
+
+// defaultAgentPullPolicy returns the pull policy defined by the AgentImagePullPolicyEnvVar or, if not defined,
+// IfNotPresent
+func defaultAgentPullPolicy() corev1.PullPolicy {
+	if policy := os.Getenv(AgentImagePullPolicyEnvVar); policy != "" {
+		return corev1.PullPolicy(policy)
+	}
+
+	return corev1.PullIfNotPresent
+}
+
+// defaultAgentPullSecrets returns the secrets defined by the AgentImagePullSecretsEnvVar
+func defaultAgentPullSecrets() []string {
+	secrets := []string{}
+	for _, secret := range strings.Split(os.Getenv(AgentImagePullSecretsEnvVar), ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	return secrets
+}
+
+// newAgentOptions returns the options for injecting the agent with the given image, pull policy, pull secrets and
+// security context. The given values take precedence over the defaults defined by the environment.
+func newAgentOptions(
+	timeout time.Duration,
+	image string,
+	pullPolicy string,
+	pullSecrets []string,
+	security AgentSecurityContext,
+) (PodAgentVisitorOptions, error) {
+	securityContext, err := security.securityContext()
+	if err != nil {
+		return PodAgentVisitorOptions{}, err
+	}
+
+	options := PodAgentVisitorOptions{
+		Timeout:          timeout,
+		Image:            image,
+		ImagePullPolicy:  corev1.PullPolicy(pullPolicy),
+		ImagePullSecrets: pullSecrets,
+		SecurityContext:  securityContext,
+		Restricted:       security.Restricted,
+	}
+	if options.Image == "" {
+		options.Image = defaultAgentImage()
+	}
+	if options.ImagePullPolicy == "" {
+		options.ImagePullPolicy = defaultAgentPullPolicy()
+	}
+	if len(options.ImagePullSecrets) == 0 {
+		options.ImagePullSecrets = defaultAgentPullSecrets()
+	}
+
+	switch options.ImagePullPolicy {
+	case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return options, nil
+	default:
+		return PodAgentVisitorOptions{}, fmt.Errorf("invalid agent image pull policy %q", options.ImagePullPolicy)
+	}
+}
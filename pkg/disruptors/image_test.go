@@ -0,0 +1,96 @@
+package disruptors
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/internal/version"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+//nolint:paralleltest // uses t.Setenv
+func Test_NewAgentOptions(t *testing.T) {
+	testCases := []struct {
+		title         string
+		envImage      string
+		envPullPolicy string
+		envSecrets    string
+		image         string
+		pullPolicy    string
+		pullSecrets   []string
+		expectError   bool
+		expected      PodAgentVisitorOptions
+	}{
+		{
+			title:       "defaults",
+			expectError: false,
+			expected: PodAgentVisitorOptions{
+				Image:            version.AgentImage(),
+				ImagePullPolicy:  corev1.PullIfNotPresent,
+				ImagePullSecrets: []string{},
+				SecurityContext:  defaultAgentSecurityContext(),
+			},
+		},
+		{
+			title:         "environment",
+			envImage:      "registry.local/xk6-disruptor-agent:v1",
+			envPullPolicy: "Never",
+			envSecrets:    "registry, mirror",
+			expectError:   false,
+			expected: PodAgentVisitorOptions{
+				Image:            "registry.local/xk6-disruptor-agent:v1",
+				ImagePullPolicy:  corev1.PullNever,
+				ImagePullSecrets: []string{"registry", "mirror"},
+				SecurityContext:  defaultAgentSecurityContext(),
+			},
+		},
+		{
+			title:         "options override environment",
+			envImage:      "registry.local/xk6-disruptor-agent:v1",
+			envPullPolicy: "Never",
+			envSecrets:    "registry",
+			image:         "registry.local/xk6-disruptor-agent:v2",
+			pullPolicy:    "Always",
+			pullSecrets:   []string{"mirror"},
+			expectError:   false,
+			expected: PodAgentVisitorOptions{
+				Image:            "registry.local/xk6-disruptor-agent:v2",
+				ImagePullPolicy:  corev1.PullAlways,
+				ImagePullSecrets: []string{"mirror"},
+				SecurityContext:  defaultAgentSecurityContext(),
+			},
+		},
+		{
+			title:       "invalid pull policy",
+			pullPolicy:  "Sometimes",
+			expectError: true,
+		},
+		{
+			title:         "invalid pull policy in environment",
+			envPullPolicy: "always",
+			expectError:   true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			t.Setenv(AgentImageEnvVar, tc.envImage)
+			t.Setenv(AgentImagePullPolicyEnvVar, tc.envPullPolicy)
+			t.Setenv(AgentImagePullSecretsEnvVar, tc.envSecrets)
+
+			options, err := newAgentOptions(0, tc.image, tc.pullPolicy, tc.pullSecrets, AgentSecurityContext{})
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, options); diff != "" {
+				t.Fatalf("expected options do not match returned\n%s", diff)
+			}
+		})
+	}
+}
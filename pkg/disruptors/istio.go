@@ -0,0 +1,33 @@
+package disruptors
+
+import (
+	"fmt"
+
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// istioArgs returns the arguments of the agent command for redirecting the traffic of a pod with an Istio sidecar,
+// if any. Returns an error if the target port or the port of the proxy are used by the sidecar.
+func istioArgs(pod corev1.Pod, port uint, proxyPort uint) ([]string, error) {
+	if !utils.HasIstioSidecar(pod) {
+		return nil, nil
+	}
+
+	if proxyPort == 0 {
+		proxyPort = defaultProxyPort
+	}
+
+	for _, p := range []uint{port, proxyPort} {
+		if utils.IsIstioPort(p) {
+			return nil, fmt.Errorf(
+				"%w: port %d is used by the Istio sidecar of pod %q",
+				ErrInvalidFault,
+				p,
+				pod.Name,
+			)
+		}
+	}
+
+	return []string{"--istio"}, nil
+}
@@ -0,0 +1,80 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// kubeletStopDelay is the time the agent delays the stop of the kubelet. It must match the delay of the agent.
+const kubeletStopDelay = time.Second
+
+// KubeletRestartFault specifies a fault that stops the kubelet in a set of nodes and restarts it after the
+// fault duration
+type KubeletRestartFault struct {
+	// Service is the name of the systemd service that runs the kubelet. Defaults to "kubelet"
+	Service string
+	// Timeout specifies the maximum time to wait, after the fault duration, for the node to be not ready and then
+	// ready again. The node is reported as not ready once the control plane misses the kubelet's heartbeats for its
+	// node monitor grace period.
+	Timeout time.Duration
+}
+
+// KubeletRestartVisitor defines a Visitor that restarts the kubelet in the target node using the node agent
+// and waits for the node to recover
+type KubeletRestartVisitor struct {
+	helper   helpers.NodeHelper
+	agent    *NodeAgentVisitor
+	timeout  time.Duration
+	duration time.Duration
+}
+
+// Visit restarts the kubelet in the node and waits until the node is not ready and then ready again
+func (c KubeletRestartVisitor) Visit(ctx context.Context, node corev1.Node) error {
+	if c.timeout == 0 {
+		c.timeout = 60 * time.Second
+	}
+
+	if isWindowsNode(node) {
+		return unsupportedOSError("node", node.Name)
+	}
+
+	err := c.agent.injectNodeAgent(ctx, node)
+	if err != nil {
+		return fmt.Errorf("starting agent in node %q: %w", node.Name, err)
+	}
+
+	// The agent schedules the restart in the node and restores the kubelet after the duration, but stopping the
+	// kubelet terminates the connection to the agent. Therefore, only the failures reported by the agent before the
+	// kubelet is stopped are errors. The kubelet is restarted after the duration, counted from the start of the
+	// command.
+	restart := time.Now().Add(kubeletStopDelay + c.duration)
+	failed := make(chan error, 1)
+	go func() {
+		failed <- c.agent.exec(ctx, node)
+	}()
+
+	select {
+	case err = <-failed:
+		if err != nil {
+			return err
+		}
+	case <-time.After(kubeletStopDelay):
+	}
+
+	// the node reports the Ready condition until the control plane detects the kubelet is stopped
+	err = c.helper.WaitNodeNotReady(ctx, node.Name, time.Until(restart)+c.timeout)
+	if err != nil {
+		return fmt.Errorf("node %q did not become not ready after kubelet stop: %w", node.Name, err)
+	}
+
+	err = c.helper.WaitNodeReady(ctx, node.Name, time.Until(restart)+c.timeout)
+	if err != nil {
+		return fmt.Errorf("node %q did not recover after kubelet restart: %w", node.Name, err)
+	}
+
+	return nil
+}
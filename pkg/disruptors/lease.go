@@ -0,0 +1,134 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// leaseMargin is added to the duration of the fault when taking the lease of a target, so the lease does not
+// expire before the fault ends. It also bounds how long a crashed test run keeps its targets locked.
+const leaseMargin = time.Minute
+
+// ErrTargetLocked is returned when a target is under disruption by another test run
+var ErrTargetLocked = errors.New("target already under disruption")
+
+// targetLock prevents disruptors of different test runs from disrupting the same pods simultaneously.
+// A lease is taken in the namespace of each target, holding the ID of the test run.
+type targetLock struct {
+	client kubernetes.Interface
+	holder string
+}
+
+func newTargetLock(client kubernetes.Interface) targetLock {
+	return targetLock{client: client, holder: RunID()}
+}
+
+// leaseName returns the name of the lease that locks the pod
+func leaseName(pod corev1.Pod) string {
+	return "xk6-disruptor-" + pod.Name
+}
+
+// acquire takes the leases of the targets for the duration of the fault and returns a function that releases them.
+// If any target is locked by another test run, the leases already taken are released and ErrTargetLocked is
+// returned.
+func (l targetLock) acquire(ctx context.Context, targets []corev1.Pod, duration time.Duration) (func(), error) {
+	acquired := []corev1.Pod{}
+	release := func() {
+		// the leases must be released even if the context of the fault is cancelled
+		for _, pod := range acquired {
+			l.release(context.Background(), pod)
+		}
+	}
+
+	for _, pod := range targets {
+		err := l.acquireTarget(ctx, pod, duration)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		acquired = append(acquired, pod)
+	}
+
+	return release, nil
+}
+
+func (l targetLock) acquireTarget(ctx context.Context, pod corev1.Pod, duration time.Duration) error {
+	leases := l.client.CoordinationV1().Leases(pod.Namespace)
+
+	now := metav1.NewMicroTime(time.Now())
+	seconds := int32((duration + leaseMargin).Seconds())
+	spec := coordinationv1.LeaseSpec{
+		HolderIdentity:       &l.holder,
+		LeaseDurationSeconds: &seconds,
+		AcquireTime:          &now,
+		RenewTime:            &now,
+	}
+
+	lease, err := leases.Get(ctx, leaseName(pod), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      leaseName(pod),
+				Namespace: pod.Namespace,
+				Labels:    map[string]string{RunIDLabel: l.holder},
+			},
+			Spec: spec,
+		}
+		_, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("%w: %s/%s is being locked by another test run", ErrTargetLocked, pod.Namespace, pod.Name)
+		}
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("getting lease of %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	if holder := lease.Spec.HolderIdentity; holder != nil && *holder != l.holder && !leaseExpired(lease) {
+		return fmt.Errorf("%w: %s/%s by run %s", ErrTargetLocked, pod.Namespace, pod.Name, *holder)
+	}
+
+	lease.Labels = map[string]string{RunIDLabel: l.holder}
+	lease.Spec = spec
+	// the update fails if another test run took the lease since it was read
+	_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return fmt.Errorf("%w: %s/%s is being locked by another test run", ErrTargetLocked, pod.Namespace, pod.Name)
+	}
+
+	return err
+}
+
+// release deletes the lease of the target if it is held by the test run
+func (l targetLock) release(ctx context.Context, pod corev1.Pod) {
+	leases := l.client.CoordinationV1().Leases(pod.Namespace)
+
+	lease, err := leases.Get(ctx, leaseName(pod), metav1.GetOptions{})
+	if err != nil || lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != l.holder {
+		return
+	}
+
+	// an expired lease is taken over by other test runs, so failing to delete it is not an error
+	_ = leases.Delete(ctx, lease.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{ResourceVersion: &lease.ResourceVersion},
+	})
+}
+
+// leaseExpired returns if the lease was not renewed within its duration
+func leaseExpired(lease *coordinationv1.Lease) bool {
+	if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+
+	duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+
+	return time.Now().After(lease.Spec.RenewTime.Add(duration))
+}
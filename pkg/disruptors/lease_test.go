@@ -0,0 +1,127 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func buildLease(pod string, holder string, renewed time.Time) *coordinationv1.Lease {
+	seconds := int32(60)
+	renewTime := metav1.NewMicroTime(renewed)
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "xk6-disruptor-" + pod,
+			Namespace: "test-ns",
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &seconds,
+			RenewTime:            &renewTime,
+		},
+	}
+}
+
+func Test_TargetLock(t *testing.T) {
+	t.Parallel()
+
+	targets := []corev1.Pod{
+		builders.NewPodBuilder("pod-1").WithNamespace("test-ns").Build(),
+		builders.NewPodBuilder("pod-2").WithNamespace("test-ns").Build(),
+	}
+
+	testCases := []struct {
+		title       string
+		leases      []runtime.Object
+		expectError bool
+	}{
+		{
+			title:       "targets not locked",
+			leases:      []runtime.Object{},
+			expectError: false,
+		},
+		{
+			title: "target locked by the same run",
+			leases: []runtime.Object{
+				buildLease("pod-1", "this-run", time.Now()),
+			},
+			expectError: false,
+		},
+		{
+			title: "target locked by another run",
+			leases: []runtime.Object{
+				buildLease("pod-2", "other-run", time.Now()),
+			},
+			expectError: true,
+		},
+		{
+			title: "expired lock of another run",
+			leases: []runtime.Object{
+				buildLease("pod-2", "other-run", time.Now().Add(-time.Hour)),
+			},
+			expectError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(tc.leases...)
+			lock := targetLock{client: client, holder: "this-run"}
+
+			release, err := lock.acquire(context.TODO(), targets, time.Minute)
+			if tc.expectError {
+				if !errors.Is(err, ErrTargetLocked) {
+					t.Fatalf("expected ErrTargetLocked but got %v", err)
+				}
+
+				// the leases taken before the failure must be released
+				_, err = client.CoordinationV1().Leases("test-ns").Get(
+					context.TODO(),
+					"xk6-disruptor-pod-1",
+					metav1.GetOptions{},
+				)
+				if err == nil {
+					t.Fatalf("lease of pod-1 was not released")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			leases, err := client.CoordinationV1().Leases("test-ns").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			for _, lease := range leases.Items {
+				if *lease.Spec.HolderIdentity != "this-run" {
+					t.Fatalf("lease %s is held by %s", lease.Name, *lease.Spec.HolderIdentity)
+				}
+			}
+
+			release()
+
+			leases, err = client.CoordinationV1().Leases("test-ns").List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			if len(leases.Items) != 0 {
+				t.Fatalf("expected leases to be released but %d remain", len(leases.Items))
+			}
+		})
+	}
+}
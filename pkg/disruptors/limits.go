@@ -0,0 +1,169 @@
+package disruptors
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MaxPodsEnvVar is the environment variable that defines the maximum number of pods any disruptor can disrupt
+	MaxPodsEnvVar = "XK6_DISRUPTOR_MAX_PODS"
+	// MaxNamespacesEnvVar is the environment variable that defines the maximum number of namespaces the pods
+	// disrupted by any disruptor can span
+	MaxNamespacesEnvVar = "XK6_DISRUPTOR_MAX_NAMESPACES"
+	// MaxPercentageEnvVar is the environment variable that defines the maximum percentage of the pods of a
+	// workload any disruptor can disrupt
+	MaxPercentageEnvVar = "XK6_DISRUPTOR_MAX_PERCENTAGE"
+)
+
+// SafetyLimits bound the blast radius of the faults injected by a disruptor. A zero value does not limit.
+// The limits of a NodeDisruptor apply to nodes: MaxPods is the maximum number of nodes disrupted and MaxPercentage
+// the maximum percentage of the nodes of the cluster disrupted.
+type SafetyLimits struct {
+	// MaxPods is the maximum number of pods disrupted
+	MaxPods int `js:"maxPods"`
+	// MaxNamespaces is the maximum number of namespaces the disrupted pods span
+	MaxNamespaces int `js:"maxNamespaces"`
+	// MaxPercentage is the maximum percentage of the pods of the workload (the pods matching the selector of a
+	// PodDisruptor or backing the service of a ServiceDisruptor) disrupted
+	MaxPercentage int `js:"maxPercentage"`
+}
+
+// SafetyLimitsFromEnv returns the limits defined by the MaxPodsEnvVar, MaxNamespacesEnvVar and
+// MaxPercentageEnvVar environment variables
+func SafetyLimitsFromEnv() (SafetyLimits, error) {
+	limits := SafetyLimits{}
+	for envVar, limit := range map[string]*int{
+		MaxPodsEnvVar:       &limits.MaxPods,
+		MaxNamespacesEnvVar: &limits.MaxNamespaces,
+		MaxPercentageEnvVar: &limits.MaxPercentage,
+	} {
+		value := os.Getenv(envVar)
+		if value == "" {
+			continue
+		}
+
+		var err error
+		*limit, err = strconv.Atoi(value)
+		if err != nil {
+			return SafetyLimits{}, fmt.Errorf("invalid value of %s %q: %w", envVar, value, err)
+		}
+	}
+
+	return limits, limits.validate()
+}
+
+// newSafetyLimits returns the limits defined by the environment restricted by the given limits.
+// The limits given cannot relax the limits defined by the environment.
+func newSafetyLimits(limits SafetyLimits) (SafetyLimits, error) {
+	err := limits.validate()
+	if err != nil {
+		return SafetyLimits{}, err
+	}
+
+	envLimits, err := SafetyLimitsFromEnv()
+	if err != nil {
+		return SafetyLimits{}, err
+	}
+
+	return SafetyLimits{
+		MaxPods:       stricterLimit(envLimits.MaxPods, limits.MaxPods),
+		MaxNamespaces: stricterLimit(envLimits.MaxNamespaces, limits.MaxNamespaces),
+		MaxPercentage: stricterLimit(envLimits.MaxPercentage, limits.MaxPercentage),
+	}, nil
+}
+
+// stricterLimit returns the lowest of two limits, considering zero as no limit
+func stricterLimit(a int, b int) int {
+	if a == 0 || b == 0 {
+		return max(a, b)
+	}
+
+	return min(a, b)
+}
+
+func (l SafetyLimits) validate() error {
+	if l.MaxPods < 0 {
+		return fmt.Errorf("maxPods cannot be negative: %d", l.MaxPods)
+	}
+
+	if l.MaxNamespaces < 0 {
+		return fmt.Errorf("maxNamespaces cannot be negative: %d", l.MaxNamespaces)
+	}
+
+	return validatePercentage(l.MaxPercentage)
+}
+
+// check returns an error if disrupting the targets exceeds the limits. The workload function returns the pods
+// the percentage of targets is calculated from. It is only called if the percentage is limited.
+func (l SafetyLimits) check(targets []corev1.Pod, workload func() ([]corev1.Pod, error)) error {
+	if l.MaxPods > 0 && len(targets) > l.MaxPods {
+		return fmt.Errorf("disrupting %d pods exceeds the limit of %d pods", len(targets), l.MaxPods)
+	}
+
+	if l.MaxNamespaces > 0 {
+		namespaces := map[string]bool{}
+		for _, pod := range targets {
+			namespaces[pod.Namespace] = true
+		}
+
+		if len(namespaces) > l.MaxNamespaces {
+			return fmt.Errorf(
+				"disrupting pods in %d namespaces exceeds the limit of %d namespaces",
+				len(namespaces),
+				l.MaxNamespaces,
+			)
+		}
+	}
+
+	if l.MaxPercentage == 0 || len(targets) == 0 {
+		return nil
+	}
+
+	pods, err := workload()
+	if err != nil {
+		return err
+	}
+
+	if len(targets)*100 > l.MaxPercentage*len(pods) {
+		return fmt.Errorf(
+			"disrupting %d of %d pods exceeds the limit of %d%% of the pods",
+			len(targets),
+			len(pods),
+			l.MaxPercentage,
+		)
+	}
+
+	return nil
+}
+
+// checkNodes returns an error if disrupting the target nodes exceeds the limits. The cluster function returns the
+// nodes the percentage of targets is calculated from. It is only called if the percentage is limited.
+func (l SafetyLimits) checkNodes(targets []corev1.Node, cluster func() ([]corev1.Node, error)) error {
+	if l.MaxPods > 0 && len(targets) > l.MaxPods {
+		return fmt.Errorf("disrupting %d nodes exceeds the limit of %d nodes", len(targets), l.MaxPods)
+	}
+
+	if l.MaxPercentage == 0 || len(targets) == 0 {
+		return nil
+	}
+
+	nodes, err := cluster()
+	if err != nil {
+		return err
+	}
+
+	if len(targets)*100 > l.MaxPercentage*len(nodes) {
+		return fmt.Errorf(
+			"disrupting %d of %d nodes exceeds the limit of %d%% of the nodes",
+			len(targets),
+			len(nodes),
+			l.MaxPercentage,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,265 @@
+package disruptors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_SafetyLimitsCheck(t *testing.T) {
+	t.Parallel()
+
+	workload := []corev1.Pod{
+		builders.NewPodBuilder("pod-1").WithNamespace("ns-1").Build(),
+		builders.NewPodBuilder("pod-2").WithNamespace("ns-1").Build(),
+		builders.NewPodBuilder("pod-3").WithNamespace("ns-2").Build(),
+		builders.NewPodBuilder("pod-4").WithNamespace("ns-3").Build(),
+	}
+
+	testCases := []struct {
+		title       string
+		limits      SafetyLimits
+		targets     []corev1.Pod
+		workloadErr error
+		expectError bool
+	}{
+		{
+			title:       "no limits",
+			limits:      SafetyLimits{},
+			targets:     workload,
+			expectError: false,
+		},
+		{
+			title:       "within pod limit",
+			limits:      SafetyLimits{MaxPods: 2},
+			targets:     workload[:2],
+			expectError: false,
+		},
+		{
+			title:       "exceeds pod limit",
+			limits:      SafetyLimits{MaxPods: 2},
+			targets:     workload[:3],
+			expectError: true,
+		},
+		{
+			title:       "within namespace limit",
+			limits:      SafetyLimits{MaxNamespaces: 2},
+			targets:     workload[:3],
+			expectError: false,
+		},
+		{
+			title:       "exceeds namespace limit",
+			limits:      SafetyLimits{MaxNamespaces: 2},
+			targets:     workload,
+			expectError: true,
+		},
+		{
+			title:       "within percentage limit",
+			limits:      SafetyLimits{MaxPercentage: 50},
+			targets:     workload[:2],
+			expectError: false,
+		},
+		{
+			title:       "exceeds percentage limit",
+			limits:      SafetyLimits{MaxPercentage: 50},
+			targets:     workload[:3],
+			expectError: true,
+		},
+		{
+			title:       "error getting workload",
+			limits:      SafetyLimits{MaxPercentage: 50},
+			targets:     workload[:1],
+			workloadErr: fmt.Errorf("fake error"),
+			expectError: true,
+		},
+		{
+			title:       "no targets",
+			limits:      SafetyLimits{MaxPercentage: 50},
+			targets:     nil,
+			workloadErr: fmt.Errorf("fake error"),
+			expectError: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.limits.check(tc.targets, func() ([]corev1.Pod, error) {
+				return workload, tc.workloadErr
+			})
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
+
+func Test_SafetyLimitsCheckNodes(t *testing.T) {
+	t.Parallel()
+
+	cluster := []corev1.Node{
+		builders.NewNodeBuilder("node-1").Build(),
+		builders.NewNodeBuilder("node-2").Build(),
+		builders.NewNodeBuilder("node-3").Build(),
+		builders.NewNodeBuilder("node-4").Build(),
+	}
+
+	testCases := []struct {
+		title       string
+		limits      SafetyLimits
+		targets     []corev1.Node
+		clusterErr  error
+		expectError bool
+	}{
+		{
+			title:       "no limits",
+			limits:      SafetyLimits{},
+			targets:     cluster,
+			expectError: false,
+		},
+		{
+			title:       "within node limit",
+			limits:      SafetyLimits{MaxPods: 2},
+			targets:     cluster[:2],
+			expectError: false,
+		},
+		{
+			title:       "exceeds node limit",
+			limits:      SafetyLimits{MaxPods: 2},
+			targets:     cluster[:3],
+			expectError: true,
+		},
+		{
+			title:       "within percentage limit",
+			limits:      SafetyLimits{MaxPercentage: 50},
+			targets:     cluster[:2],
+			expectError: false,
+		},
+		{
+			title:       "exceeds percentage limit",
+			limits:      SafetyLimits{MaxPercentage: 50},
+			targets:     cluster[:3],
+			expectError: true,
+		},
+		{
+			title:       "error listing cluster nodes",
+			limits:      SafetyLimits{MaxPercentage: 50},
+			targets:     cluster[:1],
+			clusterErr:  fmt.Errorf("fake error"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.limits.checkNodes(tc.targets, func() ([]corev1.Node, error) {
+				return cluster, tc.clusterErr
+			})
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
+
+//nolint:paralleltest // uses t.Setenv
+func Test_NewSafetyLimits(t *testing.T) {
+	testCases := []struct {
+		title       string
+		env         map[string]string
+		limits      SafetyLimits
+		expectError bool
+		expected    SafetyLimits
+	}{
+		{
+			title:       "no limits",
+			expectError: false,
+			expected:    SafetyLimits{},
+		},
+		{
+			title:       "limits from environment",
+			env:         map[string]string{MaxPodsEnvVar: "10", MaxPercentageEnvVar: "50"},
+			expectError: false,
+			expected:    SafetyLimits{MaxPods: 10, MaxPercentage: 50},
+		},
+		{
+			title:       "limits from options",
+			limits:      SafetyLimits{MaxPods: 5, MaxNamespaces: 1},
+			expectError: false,
+			expected:    SafetyLimits{MaxPods: 5, MaxNamespaces: 1},
+		},
+		{
+			title:       "options restrict environment",
+			env:         map[string]string{MaxPodsEnvVar: "10", MaxNamespacesEnvVar: "2"},
+			limits:      SafetyLimits{MaxPods: 5, MaxPercentage: 20},
+			expectError: false,
+			expected:    SafetyLimits{MaxPods: 5, MaxNamespaces: 2, MaxPercentage: 20},
+		},
+		{
+			title:       "options cannot relax environment",
+			env:         map[string]string{MaxPodsEnvVar: "10"},
+			limits:      SafetyLimits{MaxPods: 20},
+			expectError: false,
+			expected:    SafetyLimits{MaxPods: 10},
+		},
+		{
+			title:       "invalid environment",
+			env:         map[string]string{MaxPodsEnvVar: "many"},
+			expectError: true,
+		},
+		{
+			title:       "invalid percentage",
+			limits:      SafetyLimits{MaxPercentage: 120},
+			expectError: true,
+		},
+		{
+			title:       "negative limit",
+			limits:      SafetyLimits{MaxNamespaces: -1},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			for _, envVar := range []string{MaxPodsEnvVar, MaxNamespacesEnvVar, MaxPercentageEnvVar} {
+				t.Setenv(envVar, tc.env[envVar])
+			}
+
+			limits, err := newSafetyLimits(tc.limits)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expected, limits); diff != "" {
+				t.Fatalf("expected limits do not match returned\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// agentLogLines is the number of lines of the agent's log added to the error of a failed fault
+const agentLogLines = 20
+
+// TargetLogs contains the logs of the disruptor agent in a target
+type TargetLogs struct {
+	// Target is the name of the target
+	Target string
+	// Logs of the agent. Empty if the agent is not running in the target.
+	Logs string
+}
+
+// podAgentLogs returns the logs of the agent injected in the pod
+func podAgentLogs(ctx context.Context, helper helpers.PodHelper, pod corev1.Pod) (TargetLogs, error) {
+	if !hasAgent(pod) {
+		return TargetLogs{Target: pod.Name}, nil
+	}
+
+	logs, err := helper.Logs(ctx, pod.Name, "xk6-agent", 0)
+	if err != nil {
+		return TargetLogs{}, err
+	}
+
+	return TargetLogs{Target: pod.Name, Logs: string(logs)}, nil
+}
+
+// nodeAgentLogs returns the logs of the agent running in the node
+func nodeAgentLogs(ctx context.Context, helper helpers.PodHelper, node corev1.Node) (TargetLogs, error) {
+	agents, err := helper.List(ctx, helpers.PodFilter{Select: map[string]string{NodeAgentLabel: node.Name}})
+	if err != nil {
+		return TargetLogs{}, fmt.Errorf("listing agent in node %q: %w", node.Name, err)
+	}
+
+	if len(agents) == 0 {
+		return TargetLogs{Target: node.Name}, nil
+	}
+
+	logs, err := helper.Logs(ctx, nodeAgentPodName(node), "xk6-agent", 0)
+	if err != nil {
+		return TargetLogs{}, err
+	}
+
+	return TargetLogs{Target: node.Name, Logs: string(logs)}, nil
+}
+
+// withAgentLogs adds the last lines of the log of the agent injected in the pod to the error
+func withAgentLogs(ctx context.Context, helper helpers.PodHelper, pod string, err error) error {
+	// we use a fresh context because the context of the fault may have been cancelled or expired
+	//nolint:contextcheck
+	logs, logsErr := helper.Logs(context.WithoutCancel(ctx), pod, "xk6-agent", agentLogLines)
+	if logsErr != nil || len(logs) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%w\nagent log:\n%s", err, string(logs))
+}
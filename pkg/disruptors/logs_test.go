@@ -0,0 +1,93 @@
+package disruptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_PodAgentLogs(t *testing.T) {
+	t.Parallel()
+
+	agent := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name: "xk6-agent",
+		},
+	}
+
+	testCases := []struct {
+		title    string
+		injected bool
+		expected TargetLogs
+	}{
+		{
+			title:    "agent not injected",
+			injected: false,
+			expected: TargetLogs{Target: "pod1"},
+		},
+		{
+			title:    "agent injected",
+			injected: true,
+			// the fake client returns the same logs for every container
+			expected: TargetLogs{Target: "pod1", Logs: "fake logs"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+			if tc.injected {
+				pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{agent}
+			}
+
+			client := fake.NewSimpleClientset(&pod)
+			helper := helpers.NewPodHelper(client, helpers.NewFakePodCommandExecutor(), "test-ns")
+
+			logs, err := podAgentLogs(context.TODO(), helper, pod)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, logs); diff != "" {
+				t.Fatalf("expected logs do not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_PodAgentVisitorLogsOnError(t *testing.T) {
+	t.Parallel()
+
+	for _, logsOnError := range []bool{false, true} {
+		pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+		client := fake.NewSimpleClientset(&pod)
+		executor := helpers.NewFakePodCommandExecutor()
+		helper := helpers.NewPodHelper(client, executor, "test-ns")
+		visitor := NewPodAgentVisitor(
+			helper,
+			PodAgentVisitorOptions{Timeout: -1, LogsOnError: logsOnError},
+			visitCommands(),
+		)
+
+		executor.SetResult(nil, []byte("error output"), errFailed)
+		err := visitor.Visit(context.TODO(), pod)
+		if err == nil {
+			t.Fatalf("should had failed")
+		}
+
+		if strings.Contains(err.Error(), "fake logs") != logsOnError {
+			t.Fatalf("agent logs in error %q expected %t", err.Error(), logsOnError)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+package disruptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/cloud"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeDisruptor defines the types of faults that can be injected in a Node
+type NodeDisruptor interface {
+	Disruptor
+	NodeFaultInjector
+}
+
+// NodeFaultInjector defines methods for injecting faults into Nodes
+type NodeFaultInjector interface {
+	// CordonNodes marks the target nodes as unschedulable, and optionally evicts their pods, for the given duration.
+	CordonNodes(ctx context.Context, fault NodeCordonFault, duration time.Duration) error
+	// RestartKubelet stops the kubelet in the target nodes and restarts it after the given duration
+	RestartKubelet(ctx context.Context, fault KubeletRestartFault, duration time.Duration) error
+	// InjectNetworkFault delays and drops the traffic leaving the target nodes for the given duration
+	InjectNetworkFault(ctx context.Context, fault NodeNetworkFault, duration time.Duration) error
+	// StressNodes consumes CPU and memory in the target nodes for the given duration
+	StressNodes(ctx context.Context, fault NodeStressFault, duration time.Duration) error
+	// SkewClock shifts the clock of the target nodes for the given duration
+	SkewClock(ctx context.Context, fault ClockSkewFault, duration time.Duration) error
+	// RebootNodes reboots or terminates the instances of the target nodes using the cloud provider
+	RebootNodes(ctx context.Context, fault NodeRebootFault) error
+	// TaintNodes applies a taint to the target nodes for the given duration
+	TaintNodes(ctx context.Context, fault NodeTaintFault, duration time.Duration) error
+	// InterruptNodes emulates the interruption of spot instances in the target nodes. The nodes are restored
+	// after the given duration since the termination of the instance.
+	InterruptNodes(ctx context.Context, fault SpotInterruptionFault, duration time.Duration) error
+}
+
+// NodeDisruptorOptions defines options that controls the NodeDisruptor's behavior
+type NodeDisruptorOptions struct {
+	// timeout when waiting the agent to be started in the node. A zero value forces default.
+	// A Negative value forces no waiting.
+	InjectTimeout time.Duration `js:"injectTimeout"`
+	// AgentNamespace is the namespace where the agent pods are created. Defaults to "default"
+	AgentNamespace string `js:"agentNamespace"`
+	// CloudProvider is the provider of the instances that back the nodes ("aws", "gcp" or "azure").
+	// If empty, the provider is detected from the providerID of the nodes.
+	CloudProvider string `js:"cloudProvider"`
+	// AgentResources are the compute resources of the agent pods
+	AgentResources AgentResources `js:"agentResources"`
+	// Limits bound the nodes disrupted: MaxPods limits the number of nodes and MaxPercentage the percentage of
+	// the nodes of the cluster. They cannot relax the limits defined by the XK6_DISRUPTOR_MAX_PODS and
+	// XK6_DISRUPTOR_MAX_PERCENTAGE environment variables.
+	Limits SafetyLimits
+}
+
+// NodeSelectorSpec defines the criteria for selecting a node for disruption
+type NodeSelectorSpec struct {
+	// Select Nodes that match these NodeAttributes
+	Select NodeAttributes
+	// Exclude Nodes that match these NodeAttributes
+	Exclude NodeAttributes
+}
+
+// NodeAttributes defines the attributes a Node must match for being selected/excluded
+type NodeAttributes struct {
+	Labels map[string]string
+}
+
+// nodeDisruptor is an instance of a NodeDisruptor that uses a NodeController to interact with target nodes
+type nodeDisruptor struct {
+	helper    helpers.NodeHelper
+	podHelper helpers.PodHelper
+	selector  *NodeSelector
+	options   NodeDisruptorOptions
+	cloud     cloud.Provider
+	limits    SafetyLimits
+	// agentOptions are the options for starting the agent in the targets
+	agentOptions NodeAgentVisitorOptions
+}
+
+// NewNodeDisruptor creates a new instance of a NodeDisruptor that acts on the nodes
+// that match the given NodeSelectorSpec
+func NewNodeDisruptor(
+	ctx context.Context,
+	k8s kubernetes.Kubernetes,
+	spec NodeSelectorSpec,
+	options NodeDisruptorOptions,
+) (NodeDisruptor, error) {
+	if options.AgentNamespace == "" {
+		options.AgentNamespace = metav1.NamespaceDefault
+	}
+
+	helper := k8s.NodeHelper()
+
+	selector, err := NewNodeSelector(spec, helper)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkNodePolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	err = CheckPermissions(ctx, k8s.Client(), nodePermissions(options.AgentNamespace))
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := cloud.New(options.CloudProvider, runtime.DefaultExecutor())
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := options.AgentResources.requirements()
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := newSafetyLimits(options.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	agentConfig, err := loadAgentConfig(ctx, k8s.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	return &nodeDisruptor{
+		helper:    helper,
+		podHelper: k8s.PodHelper(options.AgentNamespace),
+		selector:  selector,
+		options:   options,
+		cloud:     provider,
+		limits:    limits,
+		agentOptions: NodeAgentVisitorOptions{
+			Timeout:   options.InjectTimeout,
+			Resources: resources,
+			AgentArgs: agentConfig.args(),
+		},
+	}, nil
+}
+
+func (d *nodeDisruptor) Targets(ctx context.Context) ([]string, error) {
+	targets, err := d.selector.Targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.NodeNames(targets), nil
+}
+
+// targets returns the target nodes of a fault. It returns an error if disrupting them exceeds the safety limits.
+func (d *nodeDisruptor) targets(ctx context.Context) ([]corev1.Node, error) {
+	targets, err := d.selector.Targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.limits.checkNodes(targets, func() ([]corev1.Node, error) {
+		return d.helper.List(ctx, helpers.NodeFilter{})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// Stop stops the faults applied by the agents running in the target nodes
+func (d *nodeDisruptor) Stop(ctx context.Context) error {
+	targets, err := d.selector.Targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	return controller.Visit(ctx, NodeAgentStopVisitor{helper: d.podHelper})
+}
+
+// Logs returns the logs of the agents running in the target nodes
+func (d *nodeDisruptor) Logs(ctx context.Context) ([]TargetLogs, error) {
+	targets, err := d.selector.Targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]TargetLogs, len(targets))
+	for i, node := range targets {
+		logs[i], err = nodeAgentLogs(ctx, d.podHelper, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return logs, nil
+}
+
+// Shutdown terminates the agents running in the target nodes and deletes their pods
+func (d *nodeDisruptor) Shutdown(ctx context.Context) error {
+	targets, err := d.selector.Targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	return controller.Visit(ctx, NodeAgentShutdownVisitor{helper: d.podHelper})
+}
+
+// Status returns the state of the faults applied by the agents running in the target nodes
+func (d *nodeDisruptor) Status(ctx context.Context) ([]TargetStatus, error) {
+	targets, err := d.selector.Targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]TargetStatus, len(targets))
+	for i, node := range targets {
+		status[i], err = nodeAgentStatus(ctx, d.podHelper, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return status, nil
+}
+
+// CordonNodes marks the target nodes as unschedulable for the duration of the fault
+func (d *nodeDisruptor) CordonNodes(ctx context.Context, fault NodeCordonFault, duration time.Duration) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	visitor := NodeCordonVisitor{helper: d.helper, fault: fault, duration: duration}
+
+	return controller.Visit(ctx, visitor)
+}
+
+// RestartKubelet stops the kubelet in the target nodes for the duration of the fault
+func (d *nodeDisruptor) RestartKubelet(ctx context.Context, fault KubeletRestartFault, duration time.Duration) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	agent := NewNodeAgentVisitor(
+		d.podHelper,
+		d.agentOptions,
+		NodeKubeletRestartCommand{fault: fault, duration: duration},
+	)
+
+	visitor := KubeletRestartVisitor{
+		helper:   d.helper,
+		agent:    agent,
+		timeout:  fault.Timeout,
+		duration: duration,
+	}
+
+	return controller.Visit(ctx, visitor)
+}
+
+// InjectNetworkFault disrupts the traffic leaving the target nodes for the duration of the fault.
+// The traffic to the control-plane is excluded to prevent the nodes from being considered not ready.
+func (d *nodeDisruptor) InjectNetworkFault(
+	ctx context.Context,
+	fault NodeNetworkFault,
+	duration time.Duration,
+) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	exclude, err := d.helper.ControlPlaneAddresses(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	visitor := NewNodeAgentVisitor(
+		d.podHelper,
+		d.agentOptions,
+		NodeNetworkFaultCommand{fault: fault, duration: duration, exclude: exclude},
+	)
+
+	return controller.Visit(ctx, visitor)
+}
+
+// StressNodes consumes CPU and memory in the target nodes for the duration of the fault
+func (d *nodeDisruptor) StressNodes(ctx context.Context, fault NodeStressFault, duration time.Duration) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	visitor := NewNodeAgentVisitor(
+		d.podHelper,
+		d.agentOptions,
+		NodeStressCommand{fault: fault, duration: duration},
+	)
+
+	return controller.Visit(ctx, visitor)
+}
+
+// SkewClock shifts the clock of the target nodes for the duration of the fault
+func (d *nodeDisruptor) SkewClock(ctx context.Context, fault ClockSkewFault, duration time.Duration) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	visitor := NewNodeAgentVisitor(
+		d.podHelper,
+		d.agentOptions,
+		NodeClockSkewCommand{fault: fault, duration: duration},
+	)
+
+	return controller.Visit(ctx, visitor)
+}
+
+// RebootNodes reboots or terminates the instances of the target nodes
+func (d *nodeDisruptor) RebootNodes(ctx context.Context, fault NodeRebootFault) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	visitor := NodeRebootVisitor{provider: d.cloud, fault: fault}
+
+	return controller.Visit(ctx, visitor)
+}
+
+// TaintNodes applies a taint to the target nodes for the duration of the fault
+func (d *nodeDisruptor) TaintNodes(ctx context.Context, fault NodeTaintFault, duration time.Duration) error {
+	taint, err := fault.taint()
+	if err != nil {
+		return err
+	}
+
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	visitor := NodeTaintVisitor{helper: d.helper, taint: taint, duration: duration}
+
+	return controller.Visit(ctx, visitor)
+}
+
+// InterruptNodes emulates the interruption of the spot instances of the target nodes
+func (d *nodeDisruptor) InterruptNodes(
+	ctx context.Context,
+	fault SpotInterruptionFault,
+	duration time.Duration,
+) error {
+	noticePeriod, err := fault.noticePeriod()
+	if err != nil {
+		return err
+	}
+
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	controller := NewNodeController(targets)
+
+	visitor := SpotInterruptionVisitor{
+		helper:       d.helper,
+		noticePeriod: noticePeriod,
+		drain:        fault.Drain,
+		duration:     duration,
+	}
+
+	return controller.Visit(ctx, visitor)
+}
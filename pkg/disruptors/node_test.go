@@ -0,0 +1,169 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_NodeTaintVisitor(t *testing.T) {
+	t.Parallel()
+
+	taint := corev1.Taint{Key: DefaultTaintKey, Effect: corev1.TaintEffectNoSchedule}
+
+	testCases := []struct {
+		title          string
+		existingTaints []corev1.Taint
+		expectedTaints int
+	}{
+		{
+			title:          "taint is removed after the fault",
+			existingTaints: nil,
+			expectedTaints: 0,
+		},
+		{
+			title:          "existing taint is preserved",
+			existingTaints: []corev1.Taint{taint},
+			expectedTaints: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			node := builders.NewNodeBuilder("node-1").Build()
+			node.Spec.Taints = tc.existingTaints
+
+			client := fake.NewSimpleClientset(&node)
+
+			visitor := NodeTaintVisitor{
+				helper: helpers.NewNodeHelper(client),
+				taint:  taint,
+			}
+
+			err := visitor.Visit(context.TODO(), node)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			updated, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed getting node: %v", err)
+			}
+
+			if len(updated.Spec.Taints) != tc.expectedTaints {
+				t.Fatalf("expected %d taints got %v", tc.expectedTaints, updated.Spec.Taints)
+			}
+		})
+	}
+}
+
+func Test_SpotInterruptionVisitor(t *testing.T) {
+	t.Parallel()
+
+	node := builders.NewNodeBuilder("node-1").Build()
+	pod := builders.NewPodBuilder("pod-1").WithNamespace("test-ns").WithNodeName(node.Name).Build()
+
+	client := fake.NewSimpleClientset(&node, &pod)
+
+	visitor := SpotInterruptionVisitor{
+		helper: helpers.NewNodeHelper(client),
+	}
+
+	err := visitor.Visit(context.TODO(), node)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed listing pods: %v", err)
+	}
+
+	if len(pods.Items) != 0 {
+		t.Fatalf("pods should had been terminated: %v", pods.Items)
+	}
+
+	updated, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed getting node: %v", err)
+	}
+
+	if updated.Spec.Unschedulable || len(updated.Spec.Taints) != 0 {
+		t.Fatalf("node should had been restored: %v", updated.Spec)
+	}
+}
+
+func Test_SpotInterruptionVisitorDrain(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		evictionErr error
+		expectError bool
+	}{
+		{
+			title:       "pods evicted",
+			evictionErr: nil,
+			expectError: false,
+		},
+		{
+			title:       "pods not evicted during the notice period",
+			evictionErr: k8serrors.NewTooManyRequests("disruption budget", 1),
+			expectError: false,
+		},
+		{
+			title:       "eviction failed",
+			evictionErr: k8serrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "pod-1", nil),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			node := builders.NewNodeBuilder("node-1").Build()
+			pod := builders.NewPodBuilder("pod-1").WithNamespace("test-ns").WithNodeName(node.Name).Build()
+
+			client := fake.NewSimpleClientset(&node, &pod)
+			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() != "eviction" {
+					return false, nil, nil
+				}
+				return tc.evictionErr != nil, nil, tc.evictionErr
+			})
+
+			visitor := SpotInterruptionVisitor{
+				helper:       helpers.NewNodeHelper(client),
+				noticePeriod: 100 * time.Millisecond,
+				drain:        true,
+			}
+
+			err := visitor.Visit(context.TODO(), node)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeAgentLabel is the label added to the pods that run the disruptor agent in a node
+const NodeAgentLabel = "xk6-disruptor/node-agent"
+
+// NodeVisitCommand is a command that can be run on a given node.
+// Implementations build the VisitCommands according to properties of the node where it is going to run
+type NodeVisitCommand interface {
+	// Commands defines the command to be executed, and optionally a cleanup command
+	Commands(corev1.Node) (VisitCommands, error)
+}
+
+// NodeAgentVisitorOptions defines the options for the NodeAgentVisitor
+type NodeAgentVisitorOptions struct {
+	// Defines the timeout for starting the agent
+	Timeout time.Duration
+	// Resources of the agent pods
+	Resources corev1.ResourceRequirements
+	// AgentArgs are the arguments added to the commands executed by the agent, such as the defaults of the
+	// AgentConfig
+	AgentArgs []string
+}
+
+// NodeAgentVisitor implements NodeVisitor, performing actions in a Node by means of running a NodeVisitCommand
+// in an agent pod scheduled in the node.
+type NodeAgentVisitor struct {
+	helper  helpers.PodHelper
+	options NodeAgentVisitorOptions
+	command NodeVisitCommand
+}
+
+// NewNodeAgentVisitor creates a new node visitor. The agent pods are created using the given PodHelper.
+func NewNodeAgentVisitor(
+	helper helpers.PodHelper,
+	options NodeAgentVisitorOptions,
+	command NodeVisitCommand,
+) *NodeAgentVisitor {
+	// handling timeout < 0 is required only to allow tests to skip waiting for the agent to start
+	if options.Timeout == 0 {
+		options.Timeout = 30 * time.Second
+	}
+	if options.Timeout < 0 {
+		options.Timeout = 0
+	}
+
+	return &NodeAgentVisitor{
+		helper:  helper,
+		options: options,
+		command: command,
+	}
+}
+
+// nodeAgentPodName returns the name of the agent pod for a node
+func nodeAgentPodName(node corev1.Node) string {
+	return "xk6-agent-" + node.Name
+}
+
+// buildNodeAgentPod returns the spec of the pod that runs the agent in a node.
+// The agent runs privileged and shares the host's network and process namespaces.
+func buildNodeAgentPod(node corev1.Node, resources corev1.ResourceRequirements) corev1.Pod {
+	privileged := true
+	imagePullSecrets := []corev1.LocalObjectReference{}
+	for _, secret := range defaultAgentPullSecrets() {
+		imagePullSecrets = append(imagePullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: nodeAgentPodName(node),
+			Labels: map[string]string{
+				NodeAgentLabel: node.Name,
+				RunIDLabel:     RunID(),
+			},
+		},
+		Spec: corev1.PodSpec{
+			ImagePullSecrets: imagePullSecrets,
+			NodeName:         node.Name,
+			HostNetwork:      true,
+			HostPID:          true,
+			RestartPolicy:    corev1.RestartPolicyNever,
+			// the agent must run in the node regardless of its taints
+			Tolerations: []corev1.Toleration{
+				{Operator: corev1.TolerationOpExists},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:            "xk6-agent",
+					Image:           defaultAgentImage(),
+					ImagePullPolicy: defaultAgentPullPolicy(),
+					Command:         agentCommand(),
+					Env:             agentEnv(),
+					Resources:       resources,
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: &privileged,
+					},
+					TTY:   true,
+					Stdin: true,
+				},
+			},
+		},
+	}
+}
+
+// injectNodeAgent starts the agent pod in the target node
+func (c *NodeAgentVisitor) injectNodeAgent(ctx context.Context, node corev1.Node) error {
+	return c.helper.CreatePod(
+		ctx,
+		buildNodeAgentPod(node, c.options.Resources),
+		helpers.CreatePodOptions{
+			Timeout:        c.options.Timeout,
+			IgnoreIfExists: true,
+		},
+	)
+}
+
+// Visit executes the command returned by the NodeVisitCommand in the agent running in the node
+func (c *NodeAgentVisitor) Visit(ctx context.Context, node corev1.Node) error {
+	if isWindowsNode(node) {
+		return unsupportedOSError("node", node.Name)
+	}
+
+	err := c.injectNodeAgent(ctx, node)
+	if err != nil {
+		return fmt.Errorf("starting agent in node %q: %w", node.Name, err)
+	}
+
+	return c.exec(ctx, node)
+}
+
+// exec executes the command returned by the NodeVisitCommand in the agent, which must be already running in the node
+func (c *NodeAgentVisitor) exec(ctx context.Context, node corev1.Node) error {
+	// get the command to execute in the target
+	commands, err := c.command.Commands(node)
+	if err != nil {
+		return fmt.Errorf("unable to get command for node %q: %w", node.Name, err)
+	}
+	commands = commands.withAgentArgs(c.options.AgentArgs)
+
+	agentPod := nodeAgentPodName(node)
+	_, stderr, err := c.helper.Exec(ctx, agentPod, "xk6-agent", commands.Exec, []byte{})
+
+	if err != nil && commands.Cleanup != nil {
+		// we ignore errors because we are reporting the reason of the exec failure
+		// we use a fresh context because the context used in exec may have been cancelled or expired
+		//nolint:contextcheck
+		_, _, _ = c.helper.Exec(context.TODO(), agentPod, "xk6-agent", commands.Cleanup, []byte{})
+	}
+
+	// if the context is cancelled, don't report error (we assume the caller is reporting this error)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("failed command execution for node %q: %w \n%s", node.Name, err, string(stderr))
+	}
+
+	return nil
+}
+
+// NodeAgentStopVisitor implements NodeVisitor, stopping the fault applied by the agent running in the Node, if any
+type NodeAgentStopVisitor struct {
+	helper helpers.PodHelper
+}
+
+// Visit stops the fault applied by the agent in the node
+func (c NodeAgentStopVisitor) Visit(ctx context.Context, node corev1.Node) error {
+	agents, err := c.helper.List(ctx, helpers.PodFilter{Select: map[string]string{NodeAgentLabel: node.Name}})
+	if err != nil {
+		return fmt.Errorf("listing agent in node %q: %w", node.Name, err)
+	}
+
+	if len(agents) == 0 {
+		return nil
+	}
+
+	_, stderr, err := c.helper.Exec(ctx, nodeAgentPodName(node), "xk6-agent", buildStopCmd(), []byte{})
+	if err != nil {
+		return fmt.Errorf("stopping fault in node %q: %w \n%s", node.Name, err, string(stderr))
+	}
+
+	return nil
+}
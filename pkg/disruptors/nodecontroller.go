@@ -0,0 +1,71 @@
+package disruptors
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeController uses a NodeVisitor to perform a certain action (Visit) on a list of nodes.
+// The NodeVisitor is responsible for executing the action in one target node, while the NodeController
+// is responsible for coordinating the action of the NodeVisitor on multiple target nodes
+type NodeController struct {
+	targets []corev1.Node
+}
+
+// NewNodeController creates a new controller for a collection of nodes
+func NewNodeController(targets []corev1.Node) *NodeController {
+	return &NodeController{
+		targets: targets,
+	}
+}
+
+// Visit allows executing a different command on each target node
+func (c *NodeController) Visit(ctx context.Context, visitor NodeVisitor) error {
+	// if there are no targets, nothing to do
+	if len(c.targets) == 0 {
+		return nil
+	}
+
+	// create context for the visit, that can be cancelled in case of error
+	visitCtx, cancelVisit := context.WithCancel(ctx)
+	defer cancelVisit()
+
+	// make space to prevent blocking go routines
+	doneCh := make(chan error, len(c.targets))
+
+	for _, node := range c.targets {
+		go func(node corev1.Node) {
+			doneCh <- visitor.Visit(visitCtx, node)
+		}(node)
+	}
+
+	pending := len(c.targets)
+	for {
+		select {
+		case e := <-doneCh:
+			if e != nil {
+				return e
+			}
+			pending--
+			if pending == 0 {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// NodeVisitor is the interface implemented by objects that perform actions on a Node
+type NodeVisitor interface {
+	Visit(context.Context, corev1.Node) error
+}
+
+// NodeVisitorFunc defines a function that implements the NodeVisitor interface
+type NodeVisitorFunc func(context.Context, corev1.Node) error
+
+// Visit implements NodeVisitor interface's Visit function
+func (f NodeVisitorFunc) Visit(ctx context.Context, node corev1.Node) error {
+	return f(ctx, node)
+}
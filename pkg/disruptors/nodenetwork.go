@@ -0,0 +1,68 @@
+package disruptors
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeNetworkFault specifies a fault that delays and drops the traffic leaving a node.
+// The traffic to the control-plane and the traffic of the kubelet are not affected.
+type NodeNetworkFault struct {
+	// Interface is the network interface to disrupt. Defaults to the interface of the node's default route
+	Interface string
+	// Delay added to the packets
+	Delay time.Duration
+	// Variation in the delay added to the packets
+	Jitter time.Duration
+	// Fraction (in the range 0.0 to 1.0) of packets to drop
+	LossRate float32 `js:"lossRate"`
+}
+
+func buildNodeNetworkFaultCmd(fault NodeNetworkFault, duration time.Duration, exclude []string) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"network",
+		"-d", utils.DurationSeconds(duration),
+	}
+
+	if fault.Interface != "" {
+		cmd = append(cmd, "-i", fault.Interface)
+	}
+
+	if fault.Delay > 0 {
+		cmd = append(cmd, "-a", utils.DurationMillSeconds(fault.Delay))
+		if fault.Jitter > 0 {
+			cmd = append(cmd, "-v", utils.DurationMillSeconds(fault.Jitter))
+		}
+	}
+
+	if fault.LossRate > 0 {
+		cmd = append(cmd, "-l", fmt.Sprint(fault.LossRate))
+	}
+
+	if len(exclude) > 0 {
+		cmd = append(cmd, "-x", strings.Join(exclude, ","))
+	}
+
+	return cmd
+}
+
+// NodeNetworkFaultCommand implements the NodeVisitCommand interface for injecting NodeNetworkFaults in a Node
+type NodeNetworkFaultCommand struct {
+	fault    NodeNetworkFault
+	duration time.Duration
+	// addresses excluded from the disruption
+	exclude []string
+}
+
+// Commands return the command for injecting a NodeNetworkFault in a Node
+func (c NodeNetworkFaultCommand) Commands(_ corev1.Node) (VisitCommands, error) {
+	return VisitCommands{
+		Exec:    buildNodeNetworkFaultCmd(c.fault, c.duration, c.exclude),
+		Cleanup: buildCleanupCmd(),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package disruptors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// NodeStressFault specifies a fault that consumes CPU, memory and I/O in a node.
+// The stress is checked against the limits of the agent pods (AgentResources), either in cgroup v1 or v2 nodes, and
+// the agent reports a warning in its log if they cannot hold it.
+type NodeStressFault struct {
+	// Cores is the number of CPU cores to stress
+	Cores int
+	// Load is the percentage of load in each stressed core. Defaults to 100
+	Load int
+	// Memory is the amount of memory to consume (e.g. "512Mi", "1Gi")
+	Memory string
+	// IOWorkers is the number of files written concurrently for stressing the I/O of the disk of the node
+	IOWorkers int `js:"ioWorkers"`
+	// IOFileSize is the size of the files written by each I/O worker (e.g. "64Mi"). Defaults to "64Mi"
+	IOFileSize string `js:"ioFileSize"`
+}
+
+func buildNodeStressCmd(fault NodeStressFault, duration time.Duration) []string {
+	cmd := []string{
+		"xk6-disruptor-agent",
+		"stress",
+		"-d", utils.DurationSeconds(duration),
+		"-c", fmt.Sprint(fault.Cores),
+	}
+
+	if fault.Load > 0 {
+		cmd = append(cmd, "-l", fmt.Sprint(fault.Load))
+	}
+
+	if fault.Memory != "" {
+		cmd = append(cmd, "-m", fault.Memory)
+	}
+
+	if fault.IOWorkers > 0 {
+		cmd = append(cmd, "-i", fmt.Sprint(fault.IOWorkers))
+	}
+
+	if fault.IOFileSize != "" {
+		cmd = append(cmd, "--io-file-size", fault.IOFileSize)
+	}
+
+	return cmd
+}
+
+// NodeStressCommand implements the NodeVisitCommand interface for stressing the resources of a Node
+type NodeStressCommand struct {
+	fault    NodeStressFault
+	duration time.Duration
+}
+
+// Commands return the command for stressing the resources of a Node
+func (c NodeStressCommand) Commands(_ corev1.Node) (VisitCommands, error) {
+	if c.fault.Cores < 0 {
+		return VisitCommands{}, errors.New("number of cores cannot be negative")
+	}
+
+	if c.fault.Load < 0 || c.fault.Load > 100 {
+		return VisitCommands{}, errors.New("load must be between 0 and 100")
+	}
+
+	if c.fault.Memory != "" {
+		if _, err := resource.ParseQuantity(c.fault.Memory); err != nil {
+			return VisitCommands{}, fmt.Errorf("invalid memory %q: %w", c.fault.Memory, err)
+		}
+	}
+
+	if c.fault.IOWorkers < 0 {
+		return VisitCommands{}, errors.New("number of I/O workers cannot be negative")
+	}
+
+	if c.fault.IOFileSize != "" {
+		if _, err := resource.ParseQuantity(c.fault.IOFileSize); err != nil {
+			return VisitCommands{}, fmt.Errorf("invalid I/O file size %q: %w", c.fault.IOFileSize, err)
+		}
+	}
+
+	if c.fault.Cores == 0 && c.fault.Memory == "" && c.fault.IOWorkers == 0 {
+		return VisitCommands{}, errors.New("either cores, memory or I/O workers must be specified")
+	}
+
+	return VisitCommands{
+		Exec:    buildNodeStressCmd(c.fault, c.duration),
+		Cleanup: buildCleanupCmd(),
+	}, nil
+}
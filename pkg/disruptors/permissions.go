@@ -0,0 +1,129 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Permission defines an action on a resource required by a disruptor
+type Permission struct {
+	Verb        string
+	Group       string
+	Resource    string
+	Subresource string
+	// Name of the resource. An empty name requires the permission on all the resources.
+	Name string
+	// Namespace of the resource. An empty namespace requires the permission in all namespaces.
+	Namespace string
+}
+
+func (p Permission) String() string {
+	resource := p.Resource
+	if p.Group != "" {
+		resource = resource + "." + p.Group
+	}
+	if p.Subresource != "" {
+		resource = resource + "/" + p.Subresource
+	}
+	if p.Name != "" {
+		resource = resource + " " + p.Name
+	}
+
+	if p.Namespace == "" {
+		return fmt.Sprintf("%s %s", p.Verb, resource)
+	}
+
+	return fmt.Sprintf("%s %s in namespace %s", p.Verb, resource, p.Namespace)
+}
+
+// podPermissions returns the permissions required for injecting faults in the pods of the namespace
+func podPermissions(namespace string) []Permission {
+	return []Permission{
+		{Verb: "get", Resource: "pods", Namespace: namespace},
+		{Verb: "list", Resource: "pods", Namespace: namespace},
+		{Verb: "watch", Resource: "pods", Namespace: namespace},
+		{Verb: "patch", Resource: "pods", Subresource: "ephemeralcontainers", Namespace: namespace},
+		{Verb: "create", Resource: "pods", Subresource: "exec", Namespace: namespace},
+		{Verb: "get", Group: "coordination.k8s.io", Resource: "leases", Namespace: namespace},
+		{Verb: "create", Group: "coordination.k8s.io", Resource: "leases", Namespace: namespace},
+		{Verb: "update", Group: "coordination.k8s.io", Resource: "leases", Namespace: namespace},
+		{Verb: "delete", Group: "coordination.k8s.io", Resource: "leases", Namespace: namespace},
+	}
+}
+
+// selectorPermissions returns the permissions required for injecting faults in the pods matched by the selector.
+// Selectors that span multiple namespaces list the pods in all namespaces.
+func selectorPermissions(spec PodSelectorSpec) []Permission {
+	permissions := podPermissions(spec.NamespaceOrDefault())
+	if len(spec.NamespaceLabels) > 0 {
+		permissions = append(permissions, Permission{Verb: "list", Resource: "namespaces"})
+	}
+
+	if len(spec.Nodes.Labels) > 0 {
+		permissions = append(permissions, Permission{Verb: "list", Resource: "nodes"})
+	}
+
+	return permissions
+}
+
+// servicePermissions returns the permissions required for injecting faults in the pods backing a service
+func servicePermissions(service string, namespace string) []Permission {
+	return append(
+		podPermissions(namespace),
+		Permission{Verb: "get", Resource: "services", Name: service, Namespace: namespace},
+		Permission{Verb: "get", Resource: "endpoints", Name: service, Namespace: namespace},
+	)
+}
+
+// nodePermissions returns the permissions required for injecting faults in nodes using agent pods created in the
+// given namespace
+func nodePermissions(agentNamespace string) []Permission {
+	return []Permission{
+		{Verb: "list", Resource: "nodes"},
+		{Verb: "get", Resource: "pods", Namespace: agentNamespace},
+		{Verb: "list", Resource: "pods", Namespace: agentNamespace},
+		{Verb: "watch", Resource: "pods", Namespace: agentNamespace},
+		{Verb: "create", Resource: "pods", Namespace: agentNamespace},
+		{Verb: "create", Resource: "pods", Subresource: "exec", Namespace: agentNamespace},
+	}
+}
+
+// CheckPermissions verifies the caller has the given permissions using SelfSubjectAccessReviews.
+// The error reports all the missing permissions.
+func CheckPermissions(ctx context.Context, client kubernetes.Interface, permissions []Permission) error {
+	missing := []string{}
+	for _, permission := range permissions {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace:   permission.Namespace,
+					Verb:        permission.Verb,
+					Group:       permission.Group,
+					Resource:    permission.Resource,
+					Subresource: permission.Subresource,
+					Name:        permission.Name,
+				},
+			},
+		}
+
+		review, err := client.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("checking permission to %s: %w", permission, err)
+		}
+
+		if !review.Status.Allowed {
+			missing = append(missing, permission.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing permissions: %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_CheckPermissions(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		denied      []string
+		expectError bool
+		expected    string
+	}{
+		{
+			title:       "all permissions allowed",
+			denied:      []string{},
+			expectError: false,
+		},
+		{
+			title:       "missing permissions",
+			denied:      []string{"ephemeralcontainers", "exec"},
+			expectError: true,
+			expected: "missing permissions: patch pods/ephemeralcontainers in namespace test-ns, " +
+				"create pods/exec in namespace test-ns",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset()
+			client.PrependReactor(
+				"create",
+				"selfsubjectaccessreviews",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					review, _ := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+					review.Status.Allowed = true
+					for _, subresource := range tc.denied {
+						if review.Spec.ResourceAttributes.Subresource == subresource {
+							review.Status.Allowed = false
+						}
+					}
+					return true, review, nil
+				},
+			)
+
+			err := CheckPermissions(context.TODO(), client, podPermissions("test-ns"))
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError && err.Error() != tc.expected {
+				t.Fatalf("expected error %q but got %q", tc.expected, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package disruptors
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// isWindowsPod returns if the pod runs Windows containers, as declared by its OS or its node selector
+func isWindowsPod(pod corev1.Pod) bool {
+	if pod.Spec.OS != nil {
+		return pod.Spec.OS.Name == corev1.Windows
+	}
+
+	return pod.Spec.NodeSelector[corev1.LabelOSStable] == string(corev1.Windows)
+}
+
+// isWindowsNode returns if the node runs Windows
+func isWindowsNode(node corev1.Node) bool {
+	if os, found := node.Labels[corev1.LabelOSStable]; found {
+		return os == string(corev1.Windows)
+	}
+
+	return node.Status.NodeInfo.OperatingSystem == string(corev1.Windows)
+}
+
+// unsupportedOSError returns the error of a target whose operating system is not supported by the agent
+func unsupportedOSError(kind string, name string) error {
+	return fmt.Errorf("%s %q runs windows: %w. The agent only supports linux targets", kind, name, ErrUnsupportedOS)
+}
+
+// hostNetworkError returns the error of a target pod that uses the network of the node
+func hostNetworkError(pod corev1.Pod) error {
+	return fmt.Errorf(
+		"fault cannot be safely injected because pod %q uses hostNetwork: %w. Set allowHostNetwork to inject it",
+		pod.Name,
+		ErrHostNetwork,
+	)
+}
@@ -0,0 +1,92 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_WindowsTargets(t *testing.T) {
+	t.Parallel()
+
+	linux := builders.NewPodBuilder("linux").WithNamespace("test-ns").Build()
+	linux.Spec.OS = &corev1.PodOS{Name: corev1.Linux}
+
+	windows := builders.NewPodBuilder("windows").WithNamespace("test-ns").Build()
+	windows.Spec.OS = &corev1.PodOS{Name: corev1.Windows}
+
+	selected := builders.NewPodBuilder("selected").WithNamespace("test-ns").Build()
+	selected.Spec.NodeSelector = map[string]string{corev1.LabelOSStable: "windows"}
+
+	testCases := []struct {
+		title       string
+		pod         corev1.Pod
+		expectError bool
+	}{
+		{
+			title:       "linux pod",
+			pod:         linux,
+			expectError: false,
+		},
+		{
+			title:       "windows pod",
+			pod:         windows,
+			expectError: true,
+		},
+		{
+			title:       "pod selecting windows nodes",
+			pod:         selected,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(&tc.pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+			visitor := NewPodAgentVisitor(helper, PodAgentVisitorOptions{Timeout: -1}, visitCommands())
+
+			err := visitor.Visit(context.TODO(), tc.pod)
+			if tc.expectError && !errors.Is(err, ErrUnsupportedOS) {
+				t.Fatalf("expected unsupported OS error, returned %v", err)
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			// the agent is not injected in the unsupported targets
+			if tc.expectError && len(executor.GetHistory()) > 0 {
+				t.Fatalf("agent should not be executed in the unsupported targets")
+			}
+		})
+	}
+}
+
+func Test_IsWindowsNode(t *testing.T) {
+	t.Parallel()
+
+	labeled := builders.NewNodeBuilder("labeled").WithLabel(corev1.LabelOSStable, "windows").Build()
+	unlabeled := builders.NewNodeBuilder("unlabeled").Build()
+	unlabeled.Status.NodeInfo.OperatingSystem = "windows"
+	linux := builders.NewNodeBuilder("linux").WithLabel(corev1.LabelOSStable, "linux").Build()
+
+	if !isWindowsNode(labeled) || !isWindowsNode(unlabeled) {
+		t.Fatalf("windows nodes should be detected")
+	}
+
+	if isWindowsNode(linux) {
+		t.Fatalf("linux node should not be detected as windows")
+	}
+}
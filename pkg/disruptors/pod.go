@@ -3,15 +3,22 @@ package disruptors
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
 	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
 	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
-// DefaultTargetPort defines the default value for a target HTTP
+// DefaultTargetPort is the port selected for the faults that do not specify a port, if the target exposes more than
+// one port
 var DefaultTargetPort = intstr.FromInt32(80) //nolint:gochecknoglobals
 
 // PodDisruptor defines the types of faults that can be injected in a Pod
@@ -19,6 +26,7 @@ type PodDisruptor interface {
 	Disruptor
 	ProtocolFaultInjector
 	PodFaultInjector
+	RecoveryVerifier
 }
 
 // PodDisruptorOptions defines options that controls the PodDisruptor's behavior
@@ -26,33 +34,268 @@ type PodDisruptorOptions struct {
 	// timeout when waiting agent to be injected in seconds. A zero value forces default.
 	// A Negative value forces no waiting.
 	InjectTimeout time.Duration `js:"injectTimeout"`
+	// Percentage of the pods matching the selector that are disrupted. A zero value disrupts all pods.
+	Percentage int
+	// Seed for the random selection of targets. Using the same seed selects the same targets across runs.
+	// A zero value selects targets differently on each run.
+	Seed int64
+	// DynamicTargets injects the faults in the pods that start matching the selector while the fault is active.
+	// Cannot be combined with Percentage.
+	DynamicTargets bool `js:"dynamicTargets"`
+	// ReapplyOnRestart re-applies the faults in the targets whose containers restart while the fault is active.
+	// The agent reports the number of times the fault was re-applied in the status of the target.
+	ReapplyOnRestart bool `js:"reapplyOnRestart"`
+	// ReinjectReplaced injects the faults in the pods that replace the targets deleted while the fault is active.
+	// The status of the replacement reports the target it replaces.
+	ReinjectReplaced bool `js:"reinjectReplaced"`
+	// ProtectedNamespaces are namespaces the disruptor refuses to target, in addition to kube-system,
+	// kube-node-lease and the namespaces defined in the XK6_DISRUPTOR_PROTECTED_NAMESPACES environment variable.
+	ProtectedNamespaces []string `js:"protectedNamespaces"`
+	// AllowProtectedNamespaces allows targeting protected namespaces
+	AllowProtectedNamespaces bool `js:"allowProtectedNamespaces"`
+	// DryRun resolves the targets and prints the commands the agent would run in each of them when injecting
+	// faults, without injecting anything. Pods are not terminated either.
+	DryRun bool `js:"dryRun"`
+	// HealthCheck verifies the targets are Ready and none of their containers is in CrashLoopBackOff before
+	// injecting faults. With "fail", the injection fails if any target is unhealthy. With "skip", faults are
+	// not injected in the unhealthy targets. By default, the health of the targets is not checked.
+	HealthCheck string `js:"healthCheck"`
+	// Limits bound the pods disrupted. They cannot relax the limits defined by the XK6_DISRUPTOR_MAX_PODS,
+	// XK6_DISRUPTOR_MAX_NAMESPACES and XK6_DISRUPTOR_MAX_PERCENTAGE environment variables.
+	Limits SafetyLimits
+	// AgentImage is the image of the agent injected in the targets. Defaults to the image defined in the
+	// XK6_DISRUPTOR_AGENT_IMAGE environment variable or, if not defined, the image of this version of the extension.
+	AgentImage string `js:"agentImage"`
+	// AgentImagePullPolicy is the pull policy of the agent image: Always, IfNotPresent or Never. Defaults to the
+	// policy defined in the XK6_DISRUPTOR_AGENT_IMAGE_PULL_POLICY environment variable or, if not defined,
+	// IfNotPresent.
+	AgentImagePullPolicy string `js:"agentImagePullPolicy"`
+	// AgentImagePullSecrets are the secrets required for pulling the agent image. Defaults to the secrets defined in
+	// the XK6_DISRUPTOR_AGENT_IMAGE_PULL_SECRETS environment variable. The agent uses the imagePullSecrets of the
+	// targets, therefore the targets must reference these secrets.
+	AgentImagePullSecrets []string `js:"agentImagePullSecrets"`
+	// AgentImages are the images of the agent for the architectures of the nodes of the targets
+	// (e.g. {"arm64": "registry.local/xk6-disruptor-agent:arm64"}). They take precedence over AgentImage.
+	AgentImages map[string]string `js:"agentImages"`
+	// AgentSecurityContext is the security context of the agent injected in the targets
+	AgentSecurityContext AgentSecurityContext `js:"agentSecurityContext"`
+	// AgentVolumes are volumes of the targets mounted, read-only, in the agent under AgentVolumesPath, such as the
+	// secrets with the certificates for the tls option of HTTP faults. Volumes cannot be added to an agent that was
+	// already injected in a target.
+	AgentVolumes []string `js:"agentVolumes"`
+	// Concurrency is the maximum number of targets the agent is injected in and the faults are applied to
+	// simultaneously. A zero value does not limit the concurrency.
+	Concurrency int
+	// InjectRate is the maximum number of targets per second the agent is injected in. A zero value does not
+	// limit the rate.
+	InjectRate float64 `js:"injectRate"`
+	// InjectRetries is the number of times the injection of the agent in a target is retried if it fails with a
+	// transient error. If zero, the retries of the requests to the cluster are used (see kubernetes.Config).
+	InjectRetries int `js:"injectRetries"`
+	// InjectRetryBackoff is the initial delay between the retries of the injection of the agent. The delay doubles
+	// after each retry. Defaults to 1s.
+	InjectRetryBackoff time.Duration `js:"injectRetryBackoff"`
+	// ContinueOnError applies the faults to all the targets even if some of them fail. The errors of all the
+	// failed targets are reported once the faults have finished.
+	ContinueOnError bool `js:"continueOnError"`
+	// AllowPartial applies the faults to all the targets even if some of them fail, and does not report an error if
+	// the faults were applied to at least one target. The targets where the faults failed are returned instead.
+	AllowPartial bool `js:"allowPartial"`
+	// ExecTimeout is the time the agent is given, in addition to the duration of the fault, to complete the
+	// fault in a target. Targets exceeding it are reported as timed out. A zero value does not limit the time.
+	ExecTimeout time.Duration `js:"execTimeout"`
+	// AgentLogsOnError adds the last lines of the log of the agent in a target to the error reported when the fault
+	// fails in the target
+	AgentLogsOnError bool `js:"agentLogsOnError"`
+	// InjectionMode defines how the agent is added to the targets: injected as an ephemeral container (ephemeral)
+	// or added as a sidecar when the targets were created (sidecar). Defaults to ephemeral.
+	InjectionMode string `js:"injectionMode"`
+	// AgentControl defines how the faults are applied by the agent: executing the agent commands for the duration
+	// of the faults (exec) or requesting them to the control API of the agent (api). Defaults to exec.
+	AgentControl string `js:"agentControl"`
 }
 
 // podDisruptor is an instance of a PodDisruptor that uses a PodController to interact with target pods
 type podDisruptor struct {
+	k8s      kubernetes.Kubernetes
 	helper   helpers.PodHelper
 	selector *PodSelector
 	options  PodDisruptorOptions
+	sampler  *targetSampler
+	limits   SafetyLimits
+	lock     targetLock
+	// subset are the targets selected when only a percentage of them is disrupted
+	subset targetSubset
+	// agentOptions are the options for injecting the agent in the targets
+	agentOptions PodAgentVisitorOptions
+	// out is where the commands are printed in dry run
+	out io.Writer
+	// replacements maps the pods injected as replacement of deleted targets to the target they replace
+	replacements map[string]string
 }
 
 // PodSelectorSpec defines the criteria for selecting a pod for disruption
 type PodSelectorSpec struct {
 	Namespace string
+	// Namespaces selects Pods from any of these namespaces. Cannot be combined with Namespace.
+	Namespaces []string
+	// NamespaceLabels selects Pods from the namespaces that match these labels. Cannot be combined with Namespace.
+	NamespaceLabels map[string]string `js:"namespaceLabels"`
 	// Select Pods that match these PodAttributes
 	Select PodAttributes
 	// Select Pods that match these PodAttributes
 	Exclude PodAttributes
+	// Owner selects the Pods controlled by a workload
+	Owner PodOwner
+	// Nodes restricts the selection to the Pods running in these nodes
+	Nodes PodNodes
+	// Phases of the Pods selected (e.g. "Running"). By default, Pods in the Succeeded and Failed phases are
+	// not selected.
+	Phases []string
+	// IncludeTerminating selects Pods that are being deleted. By default, they are not selected.
+	IncludeTerminating bool `js:"includeTerminating"`
+	// ReadyOnly selects only the Pods that are Ready. By default, Pods are selected regardless of their readiness.
+	ReadyOnly bool `js:"readyOnly"`
+	// Status selects Pods by their status conditions, QoS class and restarts
+	Status PodStatusAttributes
+	// MaxTargets limits the number of Pods selected. If more Pods match the selector, a random subset
+	// is selected. A zero value does not limit the number of Pods.
+	MaxTargets int `js:"maxTargets"`
+}
+
+// PodNodes defines the nodes the selected Pods must run in
+type PodNodes struct {
+	// Names of the nodes
+	Names []string
+	// Labels the nodes must match (e.g. "topology.kubernetes.io/zone")
+	Labels map[string]string
+}
+
+// PodStatusAttributes defines the status a Pod must have for being selected
+type PodStatusAttributes struct {
+	// Conditions of the Pod and their status (e.g. "Ready": "False")
+	Conditions map[string]string
+	// QOSClass of the Pod: Guaranteed, Burstable or BestEffort
+	QOSClass string `js:"qosClass"`
+	// MinRestarts selects Pods with a container restarted at least this number of times
+	MinRestarts int `js:"minRestarts"`
+	// RestartedWithin selects Pods with a container restarted within this time
+	RestartedWithin time.Duration `js:"restartedWithin"`
+}
+
+// PodOwner identifies the workload that controls a set of Pods
+type PodOwner struct {
+	// Kind of the workload: Deployment, ReplicaSet, StatefulSet, DaemonSet or Job
+	Kind string
+	// Name of the workload
+	Name string
 }
 
 // PodAttributes defines the attributes a Pod must match for being selected/excluded
 type PodAttributes struct {
 	Labels map[string]string
+	// Fields of the Pod (e.g. "spec.nodeName", "status.phase") and their values
+	Fields map[string]string
+	// Annotations of the Pod and their values
+	Annotations map[string]string
+	// MatchExpressions are set-based requirements on the labels of the Pod
+	MatchExpressions []LabelExpression `js:"matchExpressions"`
+	// LabelPatterns are regular expressions the whole value of the labels of the Pod must match
+	// (e.g. "checkout-.*")
+	LabelPatterns map[string]string `js:"labelPatterns"`
+}
+
+// LabelExpression is a set-based requirement on the value of a label
+type LabelExpression struct {
+	// Key of the label
+	Key string
+	// Operator is one of In, NotIn, Exists and DoesNotExist
+	Operator string
+	// Values of the label for the In and NotIn operators
+	Values []string
+}
+
+// validate returns an error if the options are not valid
+func (o PodDisruptorOptions) validate() error {
+	err := validatePercentage(o.Percentage)
+	if err != nil {
+		return err
+	}
+
+	if o.DynamicTargets && o.Percentage > 0 {
+		return fmt.Errorf("dynamicTargets cannot be combined with percentage")
+	}
+
+	err = validateHealthCheck(o.HealthCheck)
+	if err != nil {
+		return err
+	}
+
+	err = validateInjectionMode(o.InjectionMode)
+	if err != nil {
+		return err
+	}
+
+	err = validateAgentControl(o.AgentControl)
+	if err != nil {
+		return err
+	}
+
+	err = validateAgentImages(o.AgentImages)
+	if err != nil {
+		return err
+	}
+
+	if o.InjectRetries < 0 || o.InjectRetryBackoff < 0 {
+		return fmt.Errorf("injectRetries and injectRetryBackoff cannot be negative")
+	}
+
+	if o.ExecTimeout < 0 {
+		return fmt.Errorf("execTimeout cannot be negative")
+	}
+
+	return o.controllerOptions().validate()
+}
+
+// controllerOptions returns the options for visiting the targets
+func (o PodDisruptorOptions) controllerOptions() PodControllerOptions {
+	return PodControllerOptions{
+		Concurrency:     o.Concurrency,
+		Rate:            o.InjectRate,
+		ContinueOnError: o.ContinueOnError,
+		AllowPartial:    o.AllowPartial,
+	}
+}
+
+// agentOptions returns the options for injecting the agent in the targets
+func (o PodDisruptorOptions) agentOptions() (PodAgentVisitorOptions, error) {
+	agentOptions, err := newAgentOptions(
+		o.InjectTimeout,
+		o.AgentImage,
+		o.AgentImagePullPolicy,
+		o.AgentImagePullSecrets,
+		o.AgentSecurityContext,
+	)
+	if err != nil {
+		return PodAgentVisitorOptions{}, err
+	}
+
+	agentOptions.Retries = o.InjectRetries
+	agentOptions.RetryBackoff = o.InjectRetryBackoff
+	agentOptions.LogsOnError = o.AgentLogsOnError
+	agentOptions.Sidecar = o.InjectionMode == InjectionSidecar
+	agentOptions.ExecTimeout = o.ExecTimeout
+	agentOptions.ControlAPI = o.AgentControl == AgentControlAPI
+	agentOptions.Images = o.AgentImages
+	agentOptions.Volumes = o.AgentVolumes
+
+	return agentOptions, nil
 }
 
 // NewPodDisruptor creates a new instance of a PodDisruptor that acts on the pods
 // that match the given PodSelector
 func NewPodDisruptor(
-	_ context.Context,
+	ctx context.Context,
 	k8s kubernetes.Kubernetes,
 	spec PodSelectorSpec,
 	options PodDisruptorOptions,
@@ -67,22 +310,412 @@ func NewPodDisruptor(
 		return nil, err
 	}
 
+	guard := newNamespaceGuard(options.ProtectedNamespaces, options.AllowProtectedNamespaces)
+	err = guard.check(append([]string{namespace}, spec.Namespaces...)...)
+	if err != nil {
+		return nil, err
+	}
+	// pods in protected namespaces are not targeted by selectors that span multiple namespaces
+	selector.guard = guard
+
+	err = checkPolicy(ctx, k8s.Client(), func() ([]string, error) {
+		return selectorNamespaces(ctx, k8s.Client(), spec)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = CheckPermissions(ctx, k8s.Client(), selectorPermissions(spec))
+	if err != nil {
+		return nil, err
+	}
+
+	err = options.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := newSafetyLimits(options.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	agentOptions, err := options.agentOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	agentConfig, err := loadAgentConfig(ctx, k8s.Client())
+	if err != nil {
+		return nil, err
+	}
+	agentOptions.AgentArgs = agentConfig.args()
+
+	// the selector shares the sampler for the selection of targets to be reproducible
+	sampler := newTargetSampler(options.Seed)
+	selector.sampler = sampler
+
 	return &podDisruptor{
-		helper:   helper,
-		options:  options,
-		selector: selector,
+		k8s:          k8s,
+		helper:       helper,
+		options:      options,
+		selector:     selector,
+		sampler:      sampler,
+		limits:       limits,
+		lock:         newTargetLock(k8s.Client()),
+		agentOptions: agentOptions,
+		out:          os.Stdout,
+		replacements: map[string]string{},
 	}, nil
 }
 
-func (d *podDisruptor) Targets(ctx context.Context) ([]string, error) {
+// targets returns the pods matched by the selector that are disrupted. When only a percentage of them is disrupted,
+// the same pods are returned as long as they match the selector.
+func (d *podDisruptor) targets(ctx context.Context) ([]corev1.Pod, error) {
 	targets, err := d.selector.Targets(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	if d.options.Percentage == 0 || d.options.Percentage == 100 {
+		return targets, nil
+	}
+
+	return d.subset.pick(d.sampler, targets, percentageSize(len(targets), d.options.Percentage)), nil
+}
+
+// namespacedVisitor returns a PodVisitor that visits each target with the visitor returned by the build function
+// for a PodHelper scoped to the namespace of the target. This is required when the selector spans multiple namespaces.
+func (d *podDisruptor) namespacedVisitor(build func(helpers.PodHelper) PodVisitor) PodVisitor {
+	if !d.selector.spec.multiNamespace() {
+		return build(d.helper)
+	}
+
+	return PodVisitorFunc(func(ctx context.Context, pod corev1.Pod) error {
+		return build(d.k8s.PodHelper(pod.Namespace)).Visit(ctx, pod)
+	})
+}
+
+// injectFault executes the command returned by the build function in the agent of the targets for the duration
+// of the fault. With DynamicTargets, the pods that match the selector during the fault are also injected.
+// With ReapplyOnRestart, the targets that restart during the fault are injected again. With ReinjectReplaced,
+// the pods that replace targets deleted during the fault are injected. With dryRun, the commands are printed
+// instead.
+func (d *podDisruptor) injectFault(
+	ctx context.Context,
+	duration time.Duration,
+	build func(time.Duration) PodVisitCommand,
+	dryRun bool,
+) error {
+	visitor := func(duration time.Duration) PodVisitor {
+		command := build(duration)
+		return d.namespacedVisitor(func(helper helpers.PodHelper) PodVisitor {
+			return NewPodAgentVisitor(
+				helper,
+				d.agentOptions,
+				command,
+			)
+		})
+	}
+
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	targets, err = checkHealth(d.out, targets, d.options.HealthCheck)
+	if err != nil {
+		return err
+	}
+
+	err = d.checkLimits(ctx, targets)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		return dryRunCommands(d.out, targets, build(duration))
+	}
+
+	release, err := d.lock.acquire(ctx, targets, duration)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if !d.options.DynamicTargets && !d.options.ReapplyOnRestart && !d.options.ReinjectReplaced {
+		return NewPodController(targets, d.options.controllerOptions()).Visit(ctx, visitor(duration))
+	}
+
+	// stop watching the changes of the pods when the visit ends
+	changesCtx, stopChanges := context.WithCancel(ctx)
+	defer stopChanges()
+
+	controller := NewDynamicPodController(
+		targets,
+		d.selector.Targets,
+		DynamicPodControllerOptions{
+			MaxTargets:       d.selector.spec.MaxTargets,
+			IgnoreNewTargets: !d.options.DynamicTargets,
+			Reapply:          d.options.ReapplyOnRestart,
+			Replace:          d.options.ReinjectReplaced,
+			OnReplace:        d.recordReplacement,
+			Changes:          d.helper.Changes(changesCtx),
+		},
+	)
+
+	return controller.Visit(ctx, duration, visitor)
+}
+
+// checkLimits returns an error if disrupting the targets exceeds the safety limits of the disruptor
+func (d *podDisruptor) checkLimits(ctx context.Context, targets []corev1.Pod) error {
+	return d.limits.check(targets, func() ([]corev1.Pod, error) {
+		return d.selector.Targets(ctx)
+	})
+}
+
+// recordReplacement records the target replaced by a pod
+func (d *podDisruptor) recordReplacement(replaced corev1.Pod, replacement corev1.Pod) {
+	d.replacements[podKey(replacement)] = replaced.Name
+}
+
+func (d *podDisruptor) Targets(ctx context.Context) ([]string, error) {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return utils.PodNames(targets), nil
 }
 
+// Stop stops the faults applied by the agents injected in the pods that match the selector
+func (d *podDisruptor) Stop(ctx context.Context) error {
+	// all the pods matching the selector are visited, as the targets of the faults may have been sampled
+	targets, err := d.selector.AllTargets(ctx)
+	if errors.Is(err, ErrSelectorNoPods) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	visitor := d.namespacedVisitor(func(helper helpers.PodHelper) PodVisitor {
+		return PodAgentStopVisitor{helper: helper}
+	})
+
+	return NewPodController(targets, d.options.controllerOptions()).Visit(ctx, visitor)
+}
+
+// Logs returns the logs of the agents injected in the pods that match the selector
+func (d *podDisruptor) Logs(ctx context.Context) ([]TargetLogs, error) {
+	targets, err := d.selector.AllTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]TargetLogs, len(targets))
+	for i, pod := range targets {
+		helper := d.helper
+		if d.selector.spec.multiNamespace() {
+			helper = d.k8s.PodHelper(pod.Namespace)
+		}
+
+		logs[i], err = podAgentLogs(ctx, helper, pod)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return logs, nil
+}
+
+// AccessLog returns the access log of the agents injected in the pods that match the selector
+func (d *podDisruptor) AccessLog(ctx context.Context) ([]TargetAccessLog, error) {
+	targets, err := d.selector.AllTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accessLogs := make([]TargetAccessLog, len(targets))
+	for i, pod := range targets {
+		helper := d.helper
+		if d.selector.spec.multiNamespace() {
+			helper = d.k8s.PodHelper(pod.Namespace)
+		}
+
+		accessLogs[i], err = podAgentAccessLog(ctx, helper, pod)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return accessLogs, nil
+}
+
+// Shutdown terminates the agents injected in the pods that match the selector
+func (d *podDisruptor) Shutdown(ctx context.Context) error {
+	targets, err := d.selector.AllTargets(ctx)
+	if errors.Is(err, ErrSelectorNoPods) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	visitor := d.namespacedVisitor(func(helper helpers.PodHelper) PodVisitor {
+		return PodAgentShutdownVisitor{helper: helper}
+	})
+
+	return NewPodController(targets, d.options.controllerOptions()).Visit(ctx, visitor)
+}
+
+// Status returns the state of the faults applied by the agents injected in the pods that match the selector
+func (d *podDisruptor) Status(ctx context.Context) ([]TargetStatus, error) {
+	targets, err := d.selector.AllTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]TargetStatus, len(targets))
+	for i, pod := range targets {
+		helper := d.helper
+		if d.selector.spec.multiNamespace() {
+			helper = d.k8s.PodHelper(pod.Namespace)
+		}
+
+		status[i], err = podAgentStatus(ctx, helper, pod)
+		if err != nil {
+			return nil, err
+		}
+		status[i].Replaces = d.replacements[podKey(pod)]
+	}
+
+	return status, nil
+}
+
+// VerifyRecovery verifies the pods that match the selector recovered from the faults
+func (d *podDisruptor) VerifyRecovery(ctx context.Context, timeout time.Duration) ([]TargetRecovery, error) {
+	return verifyRecovery(ctx, timeout, func(ctx context.Context) ([]TargetRecovery, error) {
+		targets, err := d.selector.AllTargets(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		recovery := make([]TargetRecovery, len(targets))
+		for i, pod := range targets {
+			helper := d.helper
+			if d.selector.spec.multiNamespace() {
+				helper = d.k8s.PodHelper(pod.Namespace)
+			}
+
+			recovery[i], err = podRecovery(ctx, helper, pod)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return recovery, nil
+	})
+}
+
+// ComposeFaults injects simultaneously multiple faults in the requests sent to the disruptor's targets
+func (d *podDisruptor) ComposeFaults(ctx context.Context, faults ComposedFaults, duration time.Duration) error {
+	err := faults.validate()
+	if err != nil {
+		return err
+	}
+
+	return d.injectFault(ctx, duration, func(duration time.Duration) PodVisitCommand {
+		return PodComposedFaultCommand{
+			faults:   faults,
+			duration: duration,
+		}
+	}, d.options.DryRun)
+}
+
+// InjectTimeline injects in sequence the faults defined by the timeline in the requests sent to the disruptor's targets
+func (d *podDisruptor) InjectTimeline(ctx context.Context, timeline FaultTimeline) error {
+	err := timeline.validate()
+	if err != nil {
+		return err
+	}
+
+	// targets injected after the start of the timeline skip the steps already elapsed
+	return d.injectFault(ctx, timeline.duration(), func(duration time.Duration) PodVisitCommand {
+		return PodTimelineCommand{
+			timeline: timeline.remaining(duration),
+		}
+	}, d.options.DryRun)
+}
+
+// InjectChaos injects random faults in random subsets of the disruptor's targets
+func (d *podDisruptor) InjectChaos(ctx context.Context, spec ChaosSpec, duration time.Duration) error {
+	err := spec.validate()
+	if err != nil {
+		return err
+	}
+
+	if d.options.DryRun {
+		return d.dryRunChaos(ctx, spec)
+	}
+
+	return runChaos(ctx, spec, duration, d.sampler, d.injectChaosFault)
+}
+
+// dryRunChaos prints the commands for each of the faults of the chaos spec in the targets
+func (d *podDisruptor) dryRunChaos(ctx context.Context, spec ChaosSpec) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	return dryRunChaos(d.out, targets, spec)
+}
+
+// injectChaosFault injects the faults selected at random in a percentage of the targets
+func (d *podDisruptor) injectChaosFault(
+	ctx context.Context,
+	faults ComposedFaults,
+	percentage int,
+	duration time.Duration,
+) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	targets, err = checkHealth(d.out, targets, d.options.HealthCheck)
+	if err != nil {
+		return err
+	}
+
+	targets, err = d.sampler.percentage(targets, percentage)
+	if err != nil {
+		return err
+	}
+
+	err = d.checkLimits(ctx, targets)
+	if err != nil {
+		return err
+	}
+
+	release, err := d.lock.acquire(ctx, targets, duration)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	visitor := d.namespacedVisitor(func(helper helpers.PodHelper) PodVisitor {
+		return NewPodAgentVisitor(
+			helper,
+			d.agentOptions,
+			PodComposedFaultCommand{faults: faults, duration: duration},
+		)
+	})
+
+	return NewPodController(targets, d.options.controllerOptions()).Visit(ctx, visitor)
+}
+
 // InjectHTTPFault injects faults in the http requests sent to the disruptor's targets
 func (d *podDisruptor) InjectHTTPFaults(
 	ctx context.Context,
@@ -90,32 +723,36 @@ func (d *podDisruptor) InjectHTTPFaults(
 	duration time.Duration,
 	options HTTPDisruptionOptions,
 ) error {
-	// Handle default port mapping
-	// TODO: make port mandatory instead of using a default
-	if fault.Port.IsNull() || fault.Port.IsZero() {
-		fault.Port = DefaultTargetPort
-	}
-
-	command := PodHTTPFaultCommand{
-		fault:    fault,
-		duration: duration,
-		options:  options,
+	err := HTTPFaultSpec{Fault: fault, Options: options}.Validate()
+	if err != nil {
+		return err
 	}
 
-	visitor := NewPodAgentVisitor(
-		d.helper,
-		PodAgentVisitorOptions{Timeout: d.options.InjectTimeout},
-		command,
+	total, build, err := repeatFault(
+		func(duration time.Duration) PodVisitCommand {
+			return PodHTTPFaultCommand{
+				fault:    fault,
+				duration: duration,
+				options:  options,
+			}
+		},
+		duration,
+		options.RepeatEvery,
+		options.RepeatFor,
 	)
-
-	targets, err := d.selector.Targets(ctx)
 	if err != nil {
 		return err
 	}
 
-	controller := NewPodController(targets)
+	dryRun := d.options.DryRun || options.DryRun
+	if !dryRun {
+		err = waitFaultStart(ctx, options.StartAfter, options.StartAt)
+		if err != nil {
+			return err
+		}
+	}
 
-	return controller.Visit(ctx, visitor)
+	return d.injectFault(ctx, total, build, dryRun)
 }
 
 // InjectGrpcFaults injects faults in the grpc requests sent to the disruptor's targets
@@ -125,26 +762,74 @@ func (d *podDisruptor) InjectGrpcFaults(
 	duration time.Duration,
 	options GrpcDisruptionOptions,
 ) error {
-	command := PodGrpcFaultCommand{
-		fault:    fault,
-		duration: duration,
-		options:  options,
+	err := GrpcFaultSpec{Fault: fault, Options: options}.Validate()
+	if err != nil {
+		return err
 	}
 
-	visitor := NewPodAgentVisitor(
-		d.helper,
-		PodAgentVisitorOptions{Timeout: d.options.InjectTimeout},
-		command,
+	total, build, err := repeatFault(
+		func(duration time.Duration) PodVisitCommand {
+			return PodGrpcFaultCommand{
+				fault:    fault,
+				duration: duration,
+				options:  options,
+			}
+		},
+		duration,
+		options.RepeatEvery,
+		options.RepeatFor,
 	)
+	if err != nil {
+		return err
+	}
 
-	targets, err := d.selector.Targets(ctx)
+	dryRun := d.options.DryRun || options.DryRun
+	if !dryRun {
+		err = waitFaultStart(ctx, options.StartAfter, options.StartAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.injectFault(ctx, total, build, dryRun)
+}
+
+// InjectContainerFault applies the container fault to a subset of the target pods of the disruptor
+func (d *podDisruptor) InjectContainerFault(
+	ctx context.Context,
+	fault ContainerFault,
+	duration time.Duration,
+) error {
+	err := fault.validate(duration)
 	if err != nil {
 		return err
 	}
 
-	controller := NewPodController(targets)
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
 
-	return controller.Visit(ctx, visitor)
+	targets, err = d.sampler.sample(targets, fault.Count)
+	if err != nil {
+		return err
+	}
+
+	err = d.checkLimits(ctx, targets)
+	if err != nil {
+		return err
+	}
+
+	if d.options.DryRun {
+		return dryRunContainerFault(d.out, targets, fault, duration)
+	}
+
+	options := NodeAgentVisitorOptions{
+		Timeout:   d.options.InjectTimeout,
+		AgentArgs: d.agentOptions.AgentArgs,
+	}
+
+	return injectContainerFault(ctx, d.k8s.PodHelper(fault.agentNamespace()), options, targets, fault, duration)
 }
 
 // TerminatePods terminates a subset of the target pods of the disruptor
@@ -152,19 +837,30 @@ func (d *podDisruptor) TerminatePods(
 	ctx context.Context,
 	fault PodTerminationFault,
 ) ([]string, error) {
-	targets, err := d.selector.Targets(ctx)
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err = d.sampler.sample(targets, fault.Count)
 	if err != nil {
 		return nil, err
 	}
 
-	targets, err = utils.Sample(targets, fault.Count)
+	err = d.checkLimits(ctx, targets)
 	if err != nil {
 		return nil, err
 	}
 
-	controller := NewPodController(targets)
+	if d.options.DryRun {
+		return utils.PodNames(targets), dryRunTermination(d.out, targets)
+	}
+
+	controller := NewPodController(targets, d.options.controllerOptions())
 
-	visitor := PodTerminationVisitor{helper: d.helper, timeout: fault.Timeout}
+	visitor := d.namespacedVisitor(func(helper helpers.PodHelper) PodVisitor {
+		return PodTerminationVisitor{helper: helper, timeout: fault.Timeout}
+	})
 
 	return utils.PodNames(targets), controller.Visit(ctx, visitor)
 }
@@ -0,0 +1,141 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// PolicyEnvVar is the environment variable that defines the path to the file with the TargetPolicy that restricts
+// the namespaces any disruptor can target. The file can be mounted from a ConfigMap. As nodes do not belong to a
+// namespace, node disruptors cannot be created if a policy is defined.
+const PolicyEnvVar = "XK6_DISRUPTOR_POLICY"
+
+// TargetPolicy defines the namespaces disruptors are permitted to target. A namespace is permitted if it is listed
+// in Namespaces or matches the NamespaceLabels.
+type TargetPolicy struct {
+	// Namespaces permitted
+	Namespaces []string `json:"namespaces"`
+	// NamespaceLabels permit the namespaces that match these labels
+	NamespaceLabels map[string]string `json:"namespaceLabels"`
+}
+
+// TargetPolicyFromEnv returns the policy defined in the YAML or JSON file referenced by the PolicyEnvVar
+// environment variable. If the variable is not defined, it returns nil.
+func TargetPolicyFromEnv() (*TargetPolicy, error) {
+	path := os.Getenv(PolicyEnvVar)
+	if path == "" {
+		return nil, nil //nolint:nilnil // no policy defined
+	}
+
+	content, err := os.ReadFile(path) //nolint:gosec // path is defined by the operator
+	if err != nil {
+		return nil, fmt.Errorf("reading policy from %s: %w", PolicyEnvVar, err)
+	}
+
+	policy := &TargetPolicy{}
+	err = yaml.UnmarshalStrict(content, policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy in %s: %w", path, err)
+	}
+
+	if len(policy.Namespaces) == 0 && len(policy.NamespaceLabels) == 0 {
+		return nil, fmt.Errorf("invalid policy in %s: namespaces and namespaceLabels cannot both be empty", path)
+	}
+
+	return policy, nil
+}
+
+// permits returns if the policy permits targeting the namespace
+func (p *TargetPolicy) permits(ctx context.Context, client kubernetes.Interface, namespace string) (bool, error) {
+	if slices.Contains(p.Namespaces, namespace) {
+		return true, nil
+	}
+
+	if len(p.NamespaceLabels) == 0 {
+		return false, nil
+	}
+
+	ns, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("checking policy for namespace %q: %w", namespace, err)
+	}
+
+	return labels.SelectorFromSet(p.NamespaceLabels).Matches(labels.Set(ns.Labels)), nil
+}
+
+// check returns an error if any of the namespaces is not permitted by the policy
+func (p *TargetPolicy) check(ctx context.Context, client kubernetes.Interface, namespaces ...string) error {
+	for _, namespace := range namespaces {
+		permitted, err := p.permits(ctx, client, namespace)
+		if err != nil {
+			return err
+		}
+
+		if !permitted {
+			return fmt.Errorf("namespace %q is not permitted by the policy defined in %s", namespace, PolicyEnvVar)
+		}
+	}
+
+	return nil
+}
+
+// selectorNamespaces returns the namespaces the selector can target. The namespaces that match the NamespaceLabels
+// of the selector are listed at the time of the call.
+func selectorNamespaces(ctx context.Context, client kubernetes.Interface, spec PodSelectorSpec) ([]string, error) {
+	if len(spec.NamespaceLabels) == 0 {
+		if len(spec.Namespaces) > 0 {
+			return spec.Namespaces, nil
+		}
+		return []string{spec.NamespaceOrDefault()}, nil
+	}
+
+	namespaceList, err := client.CoreV1().Namespaces().List(
+		ctx,
+		metav1.ListOptions{LabelSelector: labels.SelectorFromSet(spec.NamespaceLabels).String()},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := []string{}
+	for _, ns := range namespaceList.Items {
+		if len(spec.Namespaces) == 0 || slices.Contains(spec.Namespaces, ns.Name) {
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+
+	return namespaces, nil
+}
+
+// checkPolicy returns an error if the policy defined in the environment does not permit targeting the namespaces
+func checkPolicy(ctx context.Context, client kubernetes.Interface, namespaces func() ([]string, error)) error {
+	policy, err := TargetPolicyFromEnv()
+	if err != nil || policy == nil {
+		return err
+	}
+
+	targeted, err := namespaces()
+	if err != nil {
+		return err
+	}
+
+	return policy.check(ctx, client, targeted...)
+}
+
+// checkNodePolicy returns an error if a policy is defined in the environment, as the policy cannot restrict the nodes
+// targeted by node disruptors
+func checkNodePolicy() error {
+	policy, err := TargetPolicyFromEnv()
+	if err != nil || policy == nil {
+		return err
+	}
+
+	return fmt.Errorf("node disruptors are not permitted by the policy defined in %s", PolicyEnvVar)
+}
@@ -0,0 +1,233 @@
+package disruptors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+//nolint:paralleltest // uses t.Setenv
+func Test_TargetPolicyFromEnv(t *testing.T) {
+	testCases := []struct {
+		title       string
+		content     string
+		expectError bool
+		expected    *TargetPolicy
+	}{
+		{
+			title:       "yaml policy",
+			content:     "namespaces: [app]\nnamespaceLabels:\n  team: a\n",
+			expectError: false,
+			expected:    &TargetPolicy{Namespaces: []string{"app"}, NamespaceLabels: map[string]string{"team": "a"}},
+		},
+		{
+			title:       "json policy",
+			content:     `{"namespaces": ["app"]}`,
+			expectError: false,
+			expected:    &TargetPolicy{Namespaces: []string{"app"}},
+		},
+		{
+			title:       "unknown field",
+			content:     "namespace: app\n",
+			expectError: true,
+		},
+		{
+			title:       "empty policy",
+			content:     "{}",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			err := os.WriteFile(path, []byte(tc.content), 0o600)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			t.Setenv(PolicyEnvVar, path)
+
+			policy, err := TargetPolicyFromEnv()
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, policy); diff != "" {
+				t.Fatalf("expected policy does not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_TargetPolicyCheck(t *testing.T) {
+	t.Parallel()
+
+	policy := &TargetPolicy{
+		Namespaces:      []string{"app"},
+		NamespaceLabels: map[string]string{"team": "a"},
+	}
+
+	testCases := []struct {
+		title       string
+		namespaces  []string
+		expectError bool
+	}{
+		{
+			title:       "listed namespace",
+			namespaces:  []string{"app"},
+			expectError: false,
+		},
+		{
+			title:       "namespace matching labels",
+			namespaces:  []string{"app", "team-a"},
+			expectError: false,
+		},
+		{
+			title:       "namespace not matching labels",
+			namespaces:  []string{"app", "team-b"},
+			expectError: true,
+		},
+		{
+			title:       "namespace does not exist",
+			namespaces:  []string{"other"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(
+				testNamespace("team-a", map[string]string{"team": "a"}),
+				testNamespace("team-b", map[string]string{"team": "b"}),
+			)
+
+			err := policy.check(context.TODO(), client, tc.namespaces...)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
+
+//nolint:paralleltest // uses t.Setenv
+func Test_CheckNodePolicy(t *testing.T) {
+	testCases := []struct {
+		title       string
+		content     string
+		expectError bool
+	}{
+		{
+			title:       "no policy",
+			content:     "",
+			expectError: false,
+		},
+		{
+			title:       "policy defined",
+			content:     "namespaces: [app]\n",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			path := ""
+			if tc.content != "" {
+				path = filepath.Join(t.TempDir(), "policy.yaml")
+				err := os.WriteFile(path, []byte(tc.content), 0o600)
+				if err != nil {
+					t.Fatalf("failed: %v", err)
+				}
+			}
+			t.Setenv(PolicyEnvVar, path)
+
+			err := checkNodePolicy()
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
+
+func Test_SelectorNamespaces(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		spec     PodSelectorSpec
+		expected []string
+	}{
+		{
+			title:    "default namespace",
+			spec:     PodSelectorSpec{},
+			expected: []string{"default"},
+		},
+		{
+			title:    "namespaces",
+			spec:     PodSelectorSpec{Namespaces: []string{"ns1", "ns2"}},
+			expected: []string{"ns1", "ns2"},
+		},
+		{
+			title:    "namespace labels",
+			spec:     PodSelectorSpec{NamespaceLabels: map[string]string{"team": "a"}},
+			expected: []string{"ns1", "ns2"},
+		},
+		{
+			title: "namespaces and namespace labels",
+			spec: PodSelectorSpec{
+				Namespaces:      []string{"ns2", "ns3"},
+				NamespaceLabels: map[string]string{"team": "a"},
+			},
+			expected: []string{"ns2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(
+				testNamespace("ns1", map[string]string{"team": "a"}),
+				testNamespace("ns2", map[string]string{"team": "a"}),
+				testNamespace("ns3", map[string]string{"team": "b"}),
+			)
+
+			namespaces, err := selectorNamespaces(context.TODO(), client, tc.spec)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, namespaces); diff != "" {
+				t.Fatalf("expected namespaces do not match returned\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package disruptors
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// portRange returns the first and last port of a range of ports (e.g. "8080-8082") and reports if the value is a
+// range. Port names cannot be ranges, as they must contain at least one letter.
+func portRange(port intstr.IntOrString) (int32, int32, bool) {
+	first, last, found := strings.Cut(port.Str(), "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	from, err := strconv.ParseInt(first, 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	to, err := strconv.ParseInt(last, 10, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return int32(from), int32(to), true
+}
+
+// findTargetPort returns the port in the Pod that maps to the port of a fault. If the fault does not select a port,
+// it is discovered from the ports exposed by the Pod, preferring the DefaultTargetPort.
+func findTargetPort(port intstr.IntOrString, pod corev1.Pod) (intstr.IntOrString, error) {
+	if port.IsNull() || port.IsZero() {
+		return utils.DiscoverPort(pod, DefaultTargetPort)
+	}
+
+	return utils.FindPort(port, pod)
+}
+
+// findPorts returns the ports in the Pod that map to the given ports by port number, name or range of numbers.
+// A range maps to all the ports exposed by the Pod in the range, and must include at least one.
+func findPorts(ports []intstr.IntOrString, pod corev1.Pod) ([]intstr.IntOrString, error) {
+	found := []intstr.IntOrString{}
+	for _, port := range ports {
+		from, to, isRange := portRange(port)
+		if !isRange {
+			podPort, err := utils.FindPort(port, pod)
+			if err != nil {
+				return nil, err
+			}
+			found = appendPort(found, podPort)
+			continue
+		}
+
+		inRange := false
+		for _, container := range pod.Spec.Containers {
+			for _, p := range container.Ports {
+				if p.ContainerPort >= from && p.ContainerPort <= to {
+					found = appendPort(found, intstr.FromInt32(p.ContainerPort))
+					inRange = true
+				}
+			}
+		}
+
+		if !inRange {
+			return nil, fmt.Errorf("pod %q does not export any port in range %q", pod.Name, port.Str())
+		}
+	}
+
+	return found, nil
+}
+
+// serviceTargetPorts returns the target ports in the pods that back the service for the given service ports, either
+// by port number, name or range of numbers. A range maps to all the ports exposed by the service in the range, and
+// must include at least one.
+func serviceTargetPorts(service corev1.Service, ports []intstr.IntOrString) ([]intstr.IntOrString, error) {
+	targets := []intstr.IntOrString{}
+	for _, port := range ports {
+		from, to, isRange := portRange(port)
+		if !isRange {
+			target, err := utils.GetTargetPort(service, port)
+			if err != nil {
+				return nil, err
+			}
+			targets = appendPort(targets, target)
+			continue
+		}
+
+		inRange := false
+		for _, p := range service.Spec.Ports {
+			if p.Port >= from && p.Port <= to {
+				targets = appendPort(targets, intstr.IntOrString(p.TargetPort.String()))
+				inRange = true
+			}
+		}
+
+		if !inRange {
+			return nil, fmt.Errorf("the service does not expose any port in range %q", port.Str())
+		}
+	}
+
+	return targets, nil
+}
+
+// appendPort appends the port if it is not already in the list, as the same port can be selected by both its number
+// and its name.
+func appendPort(ports []intstr.IntOrString, port intstr.IntOrString) []intstr.IntOrString {
+	if slices.Contains(ports, port) {
+		return ports
+	}
+
+	return append(ports, port)
+}
@@ -0,0 +1,78 @@
+package disruptors
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+
+	k8sintstr "k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_ServiceTargetPorts(t *testing.T) {
+	t.Parallel()
+
+	service := builders.NewServiceBuilder("test-svc").
+		WithPort("http", 80, k8sintstr.FromInt(8080)).
+		WithPort("admin", 8001, k8sintstr.FromString("admin")).
+		WithPort("metrics", 9090, k8sintstr.FromInt(9090)).
+		Build()
+
+	testCases := []struct {
+		title       string
+		ports       []intstr.IntOrString
+		expected    []intstr.IntOrString
+		expectError bool
+	}{
+		{
+			title:    "ports by number and name",
+			ports:    []intstr.IntOrString{"80", "admin"},
+			expected: []intstr.IntOrString{"8080", "admin"},
+		},
+		{
+			title:    "range of ports",
+			ports:    []intstr.IntOrString{"8000-9999"},
+			expected: []intstr.IntOrString{"admin", "9090"},
+		},
+		{
+			title:    "port selected twice",
+			ports:    []intstr.IntOrString{"http", "1-100"},
+			expected: []intstr.IntOrString{"8080"},
+		},
+		{
+			title:       "port not exposed",
+			ports:       []intstr.IntOrString{"8080"},
+			expectError: true,
+		},
+		{
+			title:       "range not exposed",
+			ports:       []intstr.IntOrString{"100-200"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			ports, err := serviceTargetPorts(service, tc.ports)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, ports); diff != "" {
+				t.Fatalf("expected ports do not match returned\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package disruptors
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProtectedNamespacesEnvVar is the environment variable that defines a comma-separated list of namespaces protected
+// in addition to the default protected namespaces
+const ProtectedNamespacesEnvVar = "XK6_DISRUPTOR_PROTECTED_NAMESPACES"
+
+// namespaceGuard prevents targeting the namespaces that host the infrastructure of the cluster
+type namespaceGuard struct {
+	protected []string
+	allow     bool
+}
+
+// newNamespaceGuard returns a guard for the default protected namespaces, the namespaces defined in the
+// ProtectedNamespacesEnvVar and the given additional namespaces. If allow is true, no namespace is protected.
+func newNamespaceGuard(additional []string, allow bool) namespaceGuard {
+	protected := []string{metav1.NamespaceSystem, corev1.NamespaceNodeLease}
+	for _, namespace := range strings.Split(os.Getenv(ProtectedNamespacesEnvVar), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			protected = append(protected, namespace)
+		}
+	}
+
+	return namespaceGuard{
+		protected: append(protected, additional...),
+		allow:     allow,
+	}
+}
+
+// isProtected returns if the namespace is protected
+func (g namespaceGuard) isProtected(namespace string) bool {
+	return !g.allow && slices.Contains(g.protected, namespace)
+}
+
+// check returns an error if any of the namespaces is protected
+func (g namespaceGuard) check(namespaces ...string) error {
+	for _, namespace := range namespaces {
+		if g.isProtected(namespace) {
+			return fmt.Errorf(
+				"namespace %q is protected. Use the allowProtectedNamespaces option for targeting it",
+				namespace,
+			)
+		}
+	}
+
+	return nil
+}
+
+// filter returns the pods that are not in a protected namespace
+func (g namespaceGuard) filter(pods []corev1.Pod) []corev1.Pod {
+	return slices.DeleteFunc(pods, func(pod corev1.Pod) bool {
+		return g.isProtected(pod.Namespace)
+	})
+}
@@ -0,0 +1,82 @@
+package disruptors
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_NamespaceGuard(t *testing.T) {
+	t.Parallel()
+
+	pods := []corev1.Pod{
+		builders.NewPodBuilder("coredns").WithNamespace("kube-system").Build(),
+		builders.NewPodBuilder("lease").WithNamespace("kube-node-lease").Build(),
+		builders.NewPodBuilder("monitor").WithNamespace("monitoring").Build(),
+		builders.NewPodBuilder("app").WithNamespace("test-ns").Build(),
+	}
+
+	testCases := []struct {
+		title       string
+		additional  []string
+		allow       bool
+		namespace   string
+		expectError bool
+		expected    []string
+	}{
+		{
+			title:       "default protected namespace",
+			namespace:   "kube-system",
+			expectError: true,
+			expected:    []string{"monitor", "app"},
+		},
+		{
+			title:       "not protected namespace",
+			namespace:   "test-ns",
+			expectError: false,
+			expected:    []string{"monitor", "app"},
+		},
+		{
+			title:       "additional protected namespace",
+			additional:  []string{"monitoring"},
+			namespace:   "monitoring",
+			expectError: true,
+			expected:    []string{"app"},
+		},
+		{
+			title:       "protected namespaces allowed",
+			allow:       true,
+			namespace:   "kube-system",
+			expectError: false,
+			expected:    []string{"coredns", "lease", "monitor", "app"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			guard := newNamespaceGuard(tc.additional, tc.allow)
+
+			err := guard.check(tc.namespace)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			targets := guard.filter(append([]corev1.Pod{}, pods...))
+			if diff := cmp.Diff(tc.expected, utils.PodNames(targets)); diff != "" {
+				t.Fatalf("expected targets do not match returned\n%s", diff)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package disruptors
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
@@ -15,24 +16,131 @@ type ProtocolFaultInjector interface {
 	// InjectGrpcFault injects faults in the grpc requests sent to the disruptor's targets
 	// for the specified duration
 	InjectGrpcFaults(ctx context.Context, fault GrpcFault, duration time.Duration, options GrpcDisruptionOptions) error
+	// ComposeFaults injects simultaneously multiple faults in the requests sent to the disruptor's targets
+	// for the specified duration. If the injection of any of the faults fails, the others are stopped.
+	ComposeFaults(ctx context.Context, faults ComposedFaults, duration time.Duration) error
+	// InjectTimeline injects in sequence the faults defined in the steps of the timeline in the requests sent to
+	// the disruptor's targets. The sequence is managed by the disruptor agent.
+	InjectTimeline(ctx context.Context, timeline FaultTimeline) error
+	// InjectChaos injects faults selected at random from the spec, at random times and in random subsets of the
+	// disruptor's targets, until the duration elapses
+	InjectChaos(ctx context.Context, spec ChaosSpec, duration time.Duration) error
+	// AccessLog returns the requests handled by the disruptor agent in each target, for the HTTP faults injected
+	// with the AccessLog option
+	AccessLog(ctx context.Context) ([]TargetAccessLog, error)
 }
 
 // HTTPDisruptionOptions defines options for the injection of HTTP faults in a target pod
 type HTTPDisruptionOptions struct {
 	// Port used by the agent for listening
 	ProxyPort uint `js:"proxyPort"`
+	// StartAfter delays the start of the fault
+	StartAfter time.Duration `js:"startAfter"`
+	// StartAt schedules the start of the fault at a given time. Cannot be combined with StartAfter
+	StartAt time.Time `js:"startAt"`
+	// RepeatEvery repeats the fault periodically. Must be at least the duration of the fault
+	RepeatEvery time.Duration `js:"repeatEvery"`
+	// RepeatFor is the total duration of the repetitions. Required by RepeatEvery
+	RepeatFor time.Duration `js:"repeatFor"`
+	// DryRun resolves the targets and prints the commands the agent would run in each of them,
+	// without injecting the fault
+	DryRun bool `js:"dryRun"`
+	// AllowHostNetwork allows the injection of the fault in targets that use the network of the node (hostNetwork).
+	// The redirection of traffic is restricted to the traffic directed to the target's IP and port.
+	AllowHostNetwork bool `js:"allowHostNetwork"`
+	// DisableUpstreamKeepAlive opens a new connection to the target for each request forwarded by the agent, instead
+	// of reusing the connections as clients using keep-alive do
+	DisableUpstreamKeepAlive bool `js:"disableUpstreamKeepAlive"`
+	// UpstreamMaxIdleConns is the maximum number of idle connections to the target kept open by the agent
+	UpstreamMaxIdleConns uint `js:"upstreamMaxIdleConns"`
+	// UpstreamMaxConns limits the number of connections to the target opened by the agent
+	UpstreamMaxConns uint `js:"upstreamMaxConns"`
+	// UpstreamIdleTimeout is the maximum time an idle connection to the target is kept open by the agent
+	UpstreamIdleTimeout time.Duration `js:"upstreamIdleTimeout"`
+	// UpstreamDialTimeout is the maximum time the agent waits for establishing a connection to the target.
+	// Defaults to 30s.
+	UpstreamDialTimeout time.Duration `js:"upstreamDialTimeout"`
+	// UpstreamResponseTimeout is the maximum time the agent waits for the response of the target to a request.
+	// Requests that time out return a 504 status code identified by the X-Xk6-Disruptor-Upstream-Error header, to
+	// distinguish them from the errors injected by the fault. By default, the time is not limited.
+	UpstreamResponseTimeout time.Duration `js:"upstreamResponseTimeout"`
+	// ReadBufferSize is the size in bytes of the buffers used by the agent for reading from the connections
+	ReadBufferSize uint `js:"readBufferSize"`
+	// WriteBufferSize is the size in bytes of the buffers used by the agent for writing to the connections
+	WriteBufferSize uint `js:"writeBufferSize"`
+	// MaxHeaderBytes is the maximum size in bytes of the headers of the requests and responses forwarded by the agent.
+	// Services with large headers may need increasing it over the default limits (1MB for requests).
+	MaxHeaderBytes uint `js:"maxHeaderBytes"`
+	// MaxConnections limits the number of concurrent connections accepted by the agent
+	MaxConnections uint `js:"maxConnections"`
+	// TLS defines how the agent handles the traffic of targets that serve TLS
+	TLS TLSOptions `js:"tls"`
+	// AccessLog reports each request handled by the agent, with the fault applied to it and the status returned by
+	// the target, in the access log of the agent. The requests are not reported if the fault does not affect them or
+	// the TLS traffic is not terminated by the agent.
+	AccessLog bool `js:"accessLog"`
+}
+
+// Modes for handling the traffic of targets that serve TLS
+const (
+	// TLSPassthrough forwards the TLS connections without decrypting them. Only delays can be injected, which are
+	// applied to the establishment of the connections.
+	TLSPassthrough = "passthrough"
+	// TLSTerminate decrypts the TLS connections using the provided certificate and forwards the requests to the
+	// target over TLS
+	TLSTerminate = "terminate"
+	// TLSMutual decrypts the TLS connections, verifying the certificates of the clients with the provided CA, and
+	// forwards the requests to the target over TLS presenting the provided client certificate
+	TLSMutual = "mtls"
+)
+
+// TLSOptions defines how the agent handles the traffic of targets that serve TLS. The files are paths in the
+// container of the agent. The volumes of the targets with the certificates can be mounted in the agent using the
+// AgentVolumes option of the disruptor.
+type TLSOptions struct {
+	// Mode is the TLS mode: passthrough, terminate or mtls. By default, the traffic is handled as plain HTTP.
+	Mode string
+	// Cert is the file with the certificate presented to the clients
+	Cert string
+	// Key is the file with the key of the certificate presented to the clients
+	Key string
+	// CA is the file with the CA for verifying the certificate of the target and, in mtls mode, of the clients
+	CA string `js:"ca"`
+	// ServerName is the name for verifying the certificate of the target
+	ServerName string `js:"serverName"`
+	// ClientCert is the file with the certificate presented to the target in mtls mode. Defaults to Cert.
+	ClientCert string `js:"clientCert"`
+	// ClientKey is the file with the key of the certificate presented to the target in mtls mode. Defaults to Key.
+	ClientKey string `js:"clientKey"`
 }
 
 // GrpcDisruptionOptions defines options for the injection of grpc faults in a target pod
 type GrpcDisruptionOptions struct {
 	// Port used by the agent for listening
 	ProxyPort uint `js:"proxyPort"`
+	// StartAfter delays the start of the fault
+	StartAfter time.Duration `js:"startAfter"`
+	// StartAt schedules the start of the fault at a given time. Cannot be combined with StartAfter
+	StartAt time.Time `js:"startAt"`
+	// RepeatEvery repeats the fault periodically. Must be at least the duration of the fault
+	RepeatEvery time.Duration `js:"repeatEvery"`
+	// RepeatFor is the total duration of the repetitions. Required by RepeatEvery
+	RepeatFor time.Duration `js:"repeatFor"`
+	// DryRun resolves the targets and prints the commands the agent would run in each of them,
+	// without injecting the fault
+	DryRun bool `js:"dryRun"`
+	// AllowHostNetwork allows the injection of the fault in targets that use the network of the node (hostNetwork).
+	// The redirection of traffic is restricted to the traffic directed to the target's IP and port.
+	AllowHostNetwork bool `js:"allowHostNetwork"`
 }
 
 // HTTPFault specifies a fault to be injected in http requests
 type HTTPFault struct {
 	// port the disruptions will be applied to
 	Port intstr.IntOrString
+	// ports the disruptions will be applied to, instead of Port. Each port can be a number, a name or a range of
+	// numbers (e.g. "8080-8082")
+	Ports []intstr.IntOrString
 	// Average delay introduced to requests
 	AverageDelay time.Duration `js:"averageDelay"`
 	// Variation in the delay (with respect of the average delay)
@@ -45,6 +153,8 @@ type HTTPFault struct {
 	ErrorBody string `js:"errorBody"`
 	// Comma-separated list of url paths to be excluded from disruptions
 	Exclude string
+	// Duration of the linear increase of the delay and error rate from zero to their values in the fault
+	RampDuration time.Duration `js:"rampDuration"`
 }
 
 // GrpcFault specifies a fault to be injected in grpc requests
@@ -63,4 +173,171 @@ type GrpcFault struct {
 	StatusMessage string `js:"statusMessage"`
 	// List of grpc services to be excluded from disruptions
 	Exclude string `js:"exclude"`
+	// Duration of the linear increase of the delay and error rate from zero to their values in the fault
+	RampDuration time.Duration `js:"rampDuration"`
+}
+
+// defaultProxyPort is the port used by the agent for listening if no port is specified in the options
+const defaultProxyPort = 8000
+
+// HTTPFaultSpec defines a HTTP fault and the options for injecting it
+type HTTPFaultSpec struct {
+	Fault   HTTPFault
+	Options HTTPDisruptionOptions
+}
+
+// GrpcFaultSpec defines a grpc fault and the options for injecting it
+type GrpcFaultSpec struct {
+	Fault   GrpcFault
+	Options GrpcDisruptionOptions
+}
+
+// ComposedFaults defines the protocol faults that are injected simultaneously in the targets.
+// Each fault must target a different port and use a different proxy port.
+type ComposedFaults struct {
+	HTTP []HTTPFaultSpec `js:"http"`
+	Grpc []GrpcFaultSpec
+}
+
+// composedFault defines the attributes of a fault that must not conflict with other composed faults
+type composedFault struct {
+	port       intstr.IntOrString
+	proxyPort  uint
+	startAfter time.Duration
+	startAt    time.Time
+	repeat     bool
+	dryRun     bool
+}
+
+func (f ComposedFaults) validate() error {
+	faults := []composedFault{}
+	for _, spec := range f.HTTP {
+		if err := spec.Fault.Validate(); err != nil {
+			return err
+		}
+		if len(spec.Fault.Ports) > 0 {
+			return fmt.Errorf("ports option is not supported in composed faults. Use one fault for each port")
+		}
+		faults = append(faults, composedFault{
+			port:       spec.Fault.Port,
+			proxyPort:  spec.Options.ProxyPort,
+			startAfter: spec.Options.StartAfter,
+			startAt:    spec.Options.StartAt,
+			repeat:     spec.Options.RepeatEvery != 0 || spec.Options.RepeatFor != 0,
+			dryRun:     spec.Options.DryRun,
+		})
+	}
+	for _, spec := range f.Grpc {
+		if err := spec.Fault.Validate(); err != nil {
+			return err
+		}
+		faults = append(faults, composedFault{
+			port:       spec.Fault.Port,
+			proxyPort:  spec.Options.ProxyPort,
+			startAfter: spec.Options.StartAfter,
+			startAt:    spec.Options.StartAt,
+			repeat:     spec.Options.RepeatEvery != 0 || spec.Options.RepeatFor != 0,
+			dryRun:     spec.Options.DryRun,
+		})
+	}
+
+	if len(faults) == 0 {
+		return fmt.Errorf("at least one fault must be specified")
+	}
+
+	ports := map[string]bool{}
+	proxyPorts := map[uint]bool{}
+	for _, fault := range faults {
+		if fault.startAfter != 0 || !fault.startAt.IsZero() {
+			return fmt.Errorf("startAfter and startAt options are not supported in composed faults")
+		}
+
+		if fault.repeat {
+			return fmt.Errorf("repeatEvery and repeatFor options are not supported in composed faults")
+		}
+
+		if fault.dryRun {
+			return fmt.Errorf("dryRun option is not supported in composed faults. Use the dryRun option of the disruptor")
+		}
+
+		// faults that do not specify a port target the port discovered in each target
+		port := fault.port
+		if port.IsZero() {
+			port = intstr.NullValue
+		}
+		if ports[port.Str()] {
+			if port.IsNull() {
+				return fmt.Errorf("multiple faults do not specify a port")
+			}
+			return fmt.Errorf("multiple faults target port %s", port.Str())
+		}
+		ports[port.Str()] = true
+
+		proxyPort := fault.proxyPort
+		if proxyPort == 0 {
+			proxyPort = defaultProxyPort
+		}
+		if proxyPorts[proxyPort] {
+			return fmt.Errorf("multiple faults use proxy port %d", proxyPort)
+		}
+		proxyPorts[proxyPort] = true
+	}
+
+	return nil
+}
+
+// FaultTimelineStep defines the faults injected simultaneously during a step of a FaultTimeline
+type FaultTimelineStep struct {
+	Faults   ComposedFaults
+	Duration time.Duration
+}
+
+// FaultTimeline defines a sequence of faults
+type FaultTimeline []FaultTimelineStep
+
+func (t FaultTimeline) validate() error {
+	if len(t) == 0 {
+		return fmt.Errorf("timeline must have at least one step")
+	}
+
+	for i, step := range t {
+		if step.Duration <= 0 {
+			return fmt.Errorf("step %d: duration must be greater than zero", i+1)
+		}
+
+		if err := step.Faults.validate(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// duration returns the total duration of the timeline
+func (t FaultTimeline) duration() time.Duration {
+	var duration time.Duration
+	for _, step := range t {
+		duration += step.Duration
+	}
+
+	return duration
+}
+
+// remaining returns the part of the timeline that remains when there is the given duration left
+func (t FaultTimeline) remaining(duration time.Duration) FaultTimeline {
+	elapsed := t.duration() - duration
+
+	remaining := FaultTimeline{}
+	for _, step := range t {
+		if elapsed >= step.Duration {
+			elapsed -= step.Duration
+			continue
+		}
+
+		step.Duration -= elapsed
+		elapsed = 0
+		remaining = append(remaining, step)
+	}
+
+	return remaining
 }
@@ -0,0 +1,172 @@
+package disruptors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+)
+
+func Test_ComposedFaultsValidation(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		faults      ComposedFaults
+		expectError bool
+	}{
+		{
+			title:       "no faults",
+			faults:      ComposedFaults{},
+			expectError: true,
+		},
+		{
+			title: "faults in different ports",
+			faults: ComposedFaults{
+				HTTP: []HTTPFaultSpec{
+					{Fault: HTTPFault{Port: intstr.FromInt32(80)}},
+				},
+				Grpc: []GrpcFaultSpec{
+					{
+						Fault:   GrpcFault{Port: intstr.FromInt32(9000)},
+						Options: GrpcDisruptionOptions{ProxyPort: 8001},
+					},
+				},
+			},
+			expectError: false,
+		},
+		{
+			title: "faults in the same port",
+			faults: ComposedFaults{
+				HTTP: []HTTPFaultSpec{
+					{Fault: HTTPFault{Port: intstr.FromInt32(80)}},
+					{
+						Fault:   HTTPFault{Port: intstr.FromInt32(80)},
+						Options: HTTPDisruptionOptions{ProxyPort: 8001},
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			title: "faults using the default proxy port",
+			faults: ComposedFaults{
+				HTTP: []HTTPFaultSpec{
+					{Fault: HTTPFault{Port: intstr.FromInt32(80)}},
+				},
+				Grpc: []GrpcFaultSpec{
+					{
+						Fault:   GrpcFault{Port: intstr.FromInt32(9000)},
+						Options: GrpcDisruptionOptions{ProxyPort: 8000},
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			title: "fault with start delay",
+			faults: ComposedFaults{
+				HTTP: []HTTPFaultSpec{
+					{
+						Fault:   HTTPFault{Port: intstr.FromInt32(80)},
+						Options: HTTPDisruptionOptions{StartAfter: time.Second},
+					},
+				},
+			},
+			expectError: true,
+		},
+		{
+			title: "fault with dry run",
+			faults: ComposedFaults{
+				Grpc: []GrpcFaultSpec{
+					{
+						Fault:   GrpcFault{Port: intstr.FromInt32(9000)},
+						Options: GrpcDisruptionOptions{DryRun: true},
+					},
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.faults.validate()
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+		})
+	}
+}
+
+func Test_FaultTimelineRemaining(t *testing.T) {
+	t.Parallel()
+
+	latency := ComposedFaults{
+		HTTP: []HTTPFaultSpec{{Fault: HTTPFault{AverageDelay: 100 * time.Millisecond}}},
+	}
+	failures := ComposedFaults{
+		HTTP: []HTTPFaultSpec{{Fault: HTTPFault{ErrorRate: 0.1, ErrorCode: 500}}},
+	}
+
+	timeline := FaultTimeline{
+		{Faults: latency, Duration: 2 * time.Minute},
+		{Faults: failures, Duration: time.Minute},
+	}
+
+	testCases := []struct {
+		title     string
+		remaining time.Duration
+		expected  FaultTimeline
+	}{
+		{
+			title:     "whole timeline",
+			remaining: 3 * time.Minute,
+			expected:  timeline,
+		},
+		{
+			title:     "part of the first step elapsed",
+			remaining: 150 * time.Second,
+			expected: FaultTimeline{
+				{Faults: latency, Duration: 90 * time.Second},
+				{Faults: failures, Duration: time.Minute},
+			},
+		},
+		{
+			title:     "first step elapsed",
+			remaining: time.Minute,
+			expected: FaultTimeline{
+				{Faults: failures, Duration: time.Minute},
+			},
+		},
+		{
+			title:     "part of the last step elapsed",
+			remaining: 10 * time.Second,
+			expected: FaultTimeline{
+				{Faults: failures, Duration: 10 * time.Second},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			remaining := timeline.remaining(tc.remaining)
+			if diff := cmp.Diff(tc.expected, remaining); diff != "" {
+				t.Fatalf("expected timeline does not match returned:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+package disruptors
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sort"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// RBACSpec defines the disruptors and faults used by a test, for generating the RBAC resources that grant the
+// minimal permissions they require
+type RBACSpec struct {
+	// Name of the roles and bindings
+	Name string
+	// ServiceAccount the roles are bound to
+	ServiceAccount RBACServiceAccount `js:"serviceAccount"`
+	// Pods are the selectors of the PodDisruptors
+	Pods []PodSelectorSpec
+	// Services are the services targeted by the ServiceDisruptors
+	Services []RBACService
+	// Faults are the names of the fault injection methods used (e.g. "injectHTTPFaults", "terminatePods")
+	Faults []string
+}
+
+// RBACServiceAccount identifies the service account the test runs with
+type RBACServiceAccount struct {
+	Name      string
+	Namespace string
+}
+
+// RBACService identifies a service targeted by a ServiceDisruptor
+type RBACService struct {
+	Name      string
+	Namespace string
+}
+
+// faultPermissions returns the permissions the fault injection method requires in the namespace, in addition to
+// the permissions for injecting the agent in the targets
+func faultPermissions(fault string, namespace string) ([]Permission, error) {
+	switch fault {
+	case "injectHTTPFaults", "injectGrpcFaults", "composeFaults", "injectTimeline", "injectChaos":
+		return nil, nil
+	case "terminatePods":
+		return []Permission{{Verb: "delete", Resource: "pods", Namespace: namespace}}, nil
+	case "injectContainerFault":
+		// the fault is applied by agent pods created in the nodes of the targets, in the default agent namespace
+		return []Permission{
+			{Verb: "create", Resource: "pods", Namespace: metav1.NamespaceDefault},
+			{Verb: "get", Resource: "pods", Namespace: metav1.NamespaceDefault},
+			{Verb: "watch", Resource: "pods", Namespace: metav1.NamespaceDefault},
+			{Verb: "create", Resource: "pods", Subresource: "exec", Namespace: metav1.NamespaceDefault},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown fault injection method %q", fault)
+	}
+}
+
+// permissions returns the permissions required by the disruptors and faults of the spec
+func (s RBACSpec) permissions() ([]Permission, error) {
+	permissions := []Permission{}
+	namespaces := []string{}
+	for _, selector := range s.Pods {
+		permissions = append(permissions, selectorPermissions(selector)...)
+		namespaces = append(namespaces, selector.NamespaceOrDefault())
+	}
+
+	for _, service := range s.Services {
+		if service.Name == "" || service.Namespace == "" {
+			return nil, fmt.Errorf("services must specify a name and a namespace")
+		}
+		permissions = append(permissions, servicePermissions(service.Name, service.Namespace)...)
+		namespaces = append(namespaces, service.Namespace)
+	}
+
+	// the events recorded on the targets are best effort, but granting the permission makes them visible
+	for _, namespace := range namespaces {
+		permissions = append(permissions, Permission{Verb: "create", Resource: "events", Namespace: namespace})
+	}
+
+	// the disruptors read the defaults of the agents they inject
+	if len(namespaces) > 0 {
+		namespace, name, err := agentConfigReference()
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(
+			permissions,
+			Permission{Verb: "get", Resource: "configmaps", Name: name, Namespace: namespace},
+		)
+	}
+
+	for _, fault := range s.Faults {
+		for _, namespace := range namespaces {
+			required, err := faultPermissions(fault, namespace)
+			if err != nil {
+				return nil, err
+			}
+			permissions = append(permissions, required...)
+		}
+	}
+
+	return permissions, nil
+}
+
+// policyRules returns the rules that grant the permissions. The verbs on the same resource are grouped in one rule.
+func policyRules(permissions []Permission) []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{}
+	for _, permission := range permissions {
+		resource := permission.Resource
+		if permission.Subresource != "" {
+			resource = resource + "/" + permission.Subresource
+		}
+
+		resourceNames := []string(nil)
+		if permission.Name != "" {
+			resourceNames = []string{permission.Name}
+		}
+
+		i := slices.IndexFunc(rules, func(rule rbacv1.PolicyRule) bool {
+			return rule.APIGroups[0] == permission.Group &&
+				rule.Resources[0] == resource &&
+				slices.Equal(rule.ResourceNames, resourceNames)
+		})
+		if i == -1 {
+			rules = append(rules, rbacv1.PolicyRule{
+				APIGroups:     []string{permission.Group},
+				Resources:     []string{resource},
+				ResourceNames: resourceNames,
+			})
+			i = len(rules) - 1
+		}
+
+		if !slices.Contains(rules[i].Verbs, permission.Verb) {
+			rules[i].Verbs = append(rules[i].Verbs, permission.Verb)
+		}
+	}
+
+	return rules
+}
+
+func rbacTypeMeta(kind string) metav1.TypeMeta {
+	return metav1.TypeMeta{APIVersion: rbacv1.SchemeGroupVersion.String(), Kind: kind}
+}
+
+// RBACManifest returns the YAML manifest of the roles and bindings that grant the service account of the spec the
+// minimal permissions required by its disruptors and faults. Permissions in a namespace are granted by a Role
+// and permissions in all namespaces or on cluster resources by a ClusterRole.
+func RBACManifest(spec RBACSpec) ([]byte, error) {
+	if spec.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	if spec.ServiceAccount.Name == "" || spec.ServiceAccount.Namespace == "" {
+		return nil, fmt.Errorf("serviceAccount must specify a name and a namespace")
+	}
+
+	permissions, err := spec.permissions()
+	if err != nil {
+		return nil, err
+	}
+
+	byNamespace := map[string][]Permission{}
+	for _, permission := range permissions {
+		byNamespace[permission.Namespace] = append(byNamespace[permission.Namespace], permission)
+	}
+
+	namespaces := make([]string, 0, len(byNamespace))
+	for namespace := range byNamespace {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	subjects := []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      spec.ServiceAccount.Name,
+		Namespace: spec.ServiceAccount.Namespace,
+	}}
+
+	objects := []any{}
+	for _, namespace := range namespaces {
+		meta := metav1.ObjectMeta{Name: spec.Name, Namespace: namespace}
+		rules := policyRules(byNamespace[namespace])
+
+		if namespace == "" {
+			objects = append(
+				objects,
+				rbacv1.ClusterRole{TypeMeta: rbacTypeMeta("ClusterRole"), ObjectMeta: meta, Rules: rules},
+				rbacv1.ClusterRoleBinding{
+					TypeMeta:   rbacTypeMeta("ClusterRoleBinding"),
+					ObjectMeta: meta,
+					Subjects:   subjects,
+					RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: spec.Name},
+				},
+			)
+			continue
+		}
+
+		objects = append(
+			objects,
+			rbacv1.Role{TypeMeta: rbacTypeMeta("Role"), ObjectMeta: meta, Rules: rules},
+			rbacv1.RoleBinding{
+				TypeMeta:   rbacTypeMeta("RoleBinding"),
+				ObjectMeta: meta,
+				Subjects:   subjects,
+				RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: spec.Name},
+			},
+		)
+	}
+
+	manifest := bytes.Buffer{}
+	for i, object := range objects {
+		var content []byte
+		content, err = yaml.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 {
+			manifest.WriteString("---\n")
+		}
+		manifest.Write(content)
+	}
+
+	return manifest.Bytes(), nil
+}
@@ -0,0 +1,134 @@
+package disruptors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func Test_RBACManifest(t *testing.T) {
+	t.Parallel()
+
+	serviceAccount := RBACServiceAccount{Name: "k6", Namespace: "k6"}
+
+	testCases := []struct {
+		title       string
+		spec        RBACSpec
+		expectError bool
+		expected    []string
+	}{
+		{
+			title: "pods in a namespace",
+			spec: RBACSpec{
+				Name:           "disruptor",
+				ServiceAccount: serviceAccount,
+				Pods:           []PodSelectorSpec{{Namespace: "app"}},
+			},
+			expectError: false,
+			expected: []string{
+				"Role app", "RoleBinding app", "Role xk6-disruptor", "RoleBinding xk6-disruptor",
+			},
+		},
+		{
+			title: "pods in multiple namespaces and service",
+			spec: RBACSpec{
+				Name:           "disruptor",
+				ServiceAccount: serviceAccount,
+				Pods:           []PodSelectorSpec{{Namespaces: []string{"app", "db"}}},
+				Services:       []RBACService{{Name: "svc", Namespace: "app"}},
+			},
+			expectError: false,
+			expected: []string{
+				"ClusterRole ",
+				"ClusterRoleBinding ",
+				"Role app",
+				"RoleBinding app",
+				"Role xk6-disruptor",
+				"RoleBinding xk6-disruptor",
+			},
+		},
+		{
+			title: "unknown fault",
+			spec: RBACSpec{
+				Name:           "disruptor",
+				ServiceAccount: serviceAccount,
+				Pods:           []PodSelectorSpec{{Namespace: "app"}},
+				Faults:         []string{"injectFaults"},
+			},
+			expectError: true,
+		},
+		{
+			title: "missing service account",
+			spec: RBACSpec{
+				Name: "disruptor",
+				Pods: []PodSelectorSpec{{Namespace: "app"}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			manifest, err := RBACManifest(tc.spec)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			objects := []string{}
+			for _, document := range strings.Split(string(manifest), "---\n") {
+				object := struct {
+					metav1.TypeMeta   `json:",inline"`
+					metav1.ObjectMeta `json:"metadata"`
+				}{}
+				err = yaml.Unmarshal([]byte(document), &object)
+				if err != nil {
+					t.Fatalf("invalid manifest: %v", err)
+				}
+				objects = append(objects, object.Kind+" "+object.Namespace)
+			}
+
+			if diff := cmp.Diff(tc.expected, objects); diff != "" {
+				t.Fatalf("expected objects do not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_PolicyRules(t *testing.T) {
+	t.Parallel()
+
+	permissions := []Permission{
+		{Verb: "get", Resource: "pods", Namespace: "app"},
+		{Verb: "delete", Resource: "pods", Namespace: "app"},
+		{Verb: "get", Resource: "pods", Namespace: "app"},
+		{Verb: "create", Resource: "pods", Subresource: "exec", Namespace: "app"},
+		{Verb: "get", Resource: "services", Name: "svc", Namespace: "app"},
+	}
+
+	expected := []rbacv1.PolicyRule{
+		{APIGroups: []string{""}, Resources: []string{"pods"}, Verbs: []string{"get", "delete"}},
+		{APIGroups: []string{""}, Resources: []string{"pods/exec"}, Verbs: []string{"create"}},
+		{APIGroups: []string{""}, Resources: []string{"services"}, ResourceNames: []string{"svc"}, Verbs: []string{"get"}},
+	}
+
+	if diff := cmp.Diff(expected, policyRules(permissions)); diff != "" {
+		t.Fatalf("expected rules do not match returned\n%s", diff)
+	}
+}
@@ -0,0 +1,38 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/xk6-disruptor/pkg/cloud"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeRebootFault specifies a fault that reboots the instances that back a set of nodes
+// using the API of the cloud provider
+type NodeRebootFault struct {
+	// Terminate indicates if the instance must be terminated instead of rebooted
+	Terminate bool
+}
+
+// NodeRebootVisitor defines a Visitor that reboots or terminates the instance of its target node
+type NodeRebootVisitor struct {
+	provider cloud.Provider
+	fault    NodeRebootFault
+}
+
+// Visit reboots or terminates the instance of the node
+func (c NodeRebootVisitor) Visit(ctx context.Context, node corev1.Node) error {
+	var err error
+	if c.fault.Terminate {
+		err = c.provider.Terminate(ctx, node)
+	} else {
+		err = c.provider.Reboot(ctx, node)
+	}
+
+	if err != nil {
+		return fmt.Errorf("rebooting node %q: %w", node.Name, err)
+	}
+
+	return nil
+}
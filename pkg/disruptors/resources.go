@@ -0,0 +1,63 @@
+package disruptors
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// AgentResources defines the compute resources of the agent (e.g. "cpu": "100m", "memory": "64Mi").
+// Kubernetes does not allow setting the resources of ephemeral containers, therefore they only apply to the
+// agent pods started in the nodes.
+type AgentResources struct {
+	// Requests are the minimum resources required by the agent
+	Requests map[string]string
+	// Limits are the maximum resources the agent can use
+	Limits map[string]string
+}
+
+// resourceList returns the ResourceList defined by the quantities of the resources
+func resourceList(resources map[string]string) (corev1.ResourceList, error) {
+	if len(resources) == 0 {
+		return nil, nil
+	}
+
+	list := corev1.ResourceList{}
+	for name, value := range resources {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity of %s %q: %w", name, value, err)
+		}
+		list[corev1.ResourceName(name)] = quantity
+	}
+
+	return list, nil
+}
+
+// requirements returns the ResourceRequirements defined by the resources
+func (r AgentResources) requirements() (corev1.ResourceRequirements, error) {
+	requests, err := resourceList(r.Requests)
+	if err != nil {
+		return corev1.ResourceRequirements{}, fmt.Errorf("agent requests: %w", err)
+	}
+
+	limits, err := resourceList(r.Limits)
+	if err != nil {
+		return corev1.ResourceRequirements{}, fmt.Errorf("agent limits: %w", err)
+	}
+
+	for name, request := range requests {
+		limit, found := limits[name]
+		if found && request.Cmp(limit) > 0 {
+			return corev1.ResourceRequirements{}, fmt.Errorf(
+				"agent request of %s %s exceeds its limit %s",
+				name,
+				request.String(),
+				limit.String(),
+			)
+		}
+	}
+
+	return corev1.ResourceRequirements{Requests: requests, Limits: limits}, nil
+}
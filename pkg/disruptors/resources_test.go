@@ -0,0 +1,85 @@
+package disruptors
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func Test_AgentResources(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		resources   AgentResources
+		expectError bool
+		expected    corev1.ResourceRequirements
+	}{
+		{
+			title:       "no resources",
+			resources:   AgentResources{},
+			expectError: false,
+			expected:    corev1.ResourceRequirements{},
+		},
+		{
+			title: "requests and limits",
+			resources: AgentResources{
+				Requests: map[string]string{"cpu": "100m", "memory": "64Mi"},
+				Limits:   map[string]string{"memory": "128Mi"},
+			},
+			expectError: false,
+			expected: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("100m"),
+					corev1.ResourceMemory: resource.MustParse("64Mi"),
+				},
+				Limits: corev1.ResourceList{
+					corev1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+			},
+		},
+		{
+			title: "invalid quantity",
+			resources: AgentResources{
+				Requests: map[string]string{"cpu": "one"},
+			},
+			expectError: true,
+		},
+		{
+			title: "request exceeds limit",
+			resources: AgentResources{
+				Requests: map[string]string{"memory": "256Mi"},
+				Limits:   map[string]string{"memory": "128Mi"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			requirements, err := tc.resources.requirements()
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expected, requirements); diff != "" {
+				t.Fatalf("expected requirements do not match returned\n%s", diff)
+			}
+		})
+	}
+}
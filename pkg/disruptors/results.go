@@ -0,0 +1,63 @@
+package disruptors
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// TargetError is the error of applying a fault to a target
+type TargetError struct {
+	// Target is the name of the target
+	Target string
+	// Err is the error of the target
+	Err error
+}
+
+// TargetsError reports the targets a fault was applied to and the targets where it failed, when the fault is
+// applied to all the targets regardless of errors
+type TargetsError struct {
+	// Succeeded are the targets the fault was applied to
+	Succeeded []string
+	// Failed are the targets where the fault failed
+	Failed []TargetError
+	// Accepted is true if the fault was partially applied and partial application is allowed
+	Accepted bool
+}
+
+// Error implements the error interface
+func (e *TargetsError) Error() string {
+	errs := make([]string, 0, len(e.Failed))
+	for _, failed := range e.Failed {
+		errs = append(errs, failed.Err.Error())
+	}
+
+	return fmt.Sprintf(
+		"fault failed in %d of %d targets: %s",
+		len(e.Failed),
+		len(e.Failed)+len(e.Succeeded),
+		strings.Join(errs, "; "),
+	)
+}
+
+// Unwrap returns the errors of the failed targets
+func (e *TargetsError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, failed := range e.Failed {
+		errs = append(errs, failed.Err)
+	}
+
+	return errs
+}
+
+// TimedOut returns the targets where the fault failed because the command in the agent timed out
+func (e *TargetsError) TimedOut() []string {
+	targets := []string{}
+	for _, failed := range e.Failed {
+		if errors.Is(failed.Err, ErrExecTimeout) {
+			targets = append(targets, failed.Target)
+		}
+	}
+
+	return targets
+}
@@ -0,0 +1,144 @@
+package disruptors
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// validatePercentage checks the percentage of targets is in the range 0 to 100
+func validatePercentage(percentage int) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("percentage must be between 0 and 100: %d", percentage)
+	}
+
+	return nil
+}
+
+// targetSampler selects random subsets of targets. When created with a seed, the sequence of
+// subsets selected is reproducible given the same targets.
+type targetSampler struct {
+	mtx sync.Mutex
+	rnd *rand.Rand
+}
+
+// newTargetSampler returns a targetSampler. A zero seed selects a non-reproducible sequence.
+func newTargetSampler(seed int64) *targetSampler {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return &targetSampler{
+		//nolint:gosec // the sample is not used for security purposes
+		rnd: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// shuffle returns a copy of the targets in random order. The targets are sorted by name before shuffling
+// to make the result independent of the order they were listed.
+func (s *targetSampler) shuffle(targets []corev1.Pod) []corev1.Pod {
+	shuffled := make([]corev1.Pod, len(targets))
+	copy(shuffled, targets)
+	sort.Slice(shuffled, func(i, j int) bool {
+		if shuffled[i].Namespace != shuffled[j].Namespace {
+			return shuffled[i].Namespace < shuffled[j].Namespace
+		}
+		return shuffled[i].Name < shuffled[j].Name
+	})
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.rnd.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return shuffled
+}
+
+// sample returns a random subset of count targets. The count can be a number or a percentage.
+func (s *targetSampler) sample(targets []corev1.Pod, count intstr.IntOrString) ([]corev1.Pod, error) {
+	return utils.Sample(s.shuffle(targets), count)
+}
+
+// percentage returns a random subset with the given percentage of the targets.
+// A zero percentage returns all the targets.
+func (s *targetSampler) percentage(targets []corev1.Pod, percentage int) ([]corev1.Pod, error) {
+	if percentage == 0 || percentage == 100 {
+		return targets, nil
+	}
+
+	return s.sample(targets, intstr.FromString(fmt.Sprintf("%d%%", percentage)))
+}
+
+// percentageSize returns the number of targets that make the given percentage of the targets, which is at least one.
+// It matches the size of the subsets returned by percentage.
+func percentageSize(targets int, percentage int) int {
+	return int(math.Max(1, math.Round(float64(targets*percentage)/100)))
+}
+
+// targetSubset is a subset of targets selected at random the first time it is requested and kept afterwards, so the
+// faults of a disruptor are applied to, and reported for, the same targets. Targets of the subset that no longer
+// exist are replaced by other targets selected at random.
+type targetSubset struct {
+	mtx  sync.Mutex
+	keys map[string]bool
+}
+
+// pick returns size targets, preferring the targets already in the subset and adding the others to it
+func (s *targetSubset) pick(sampler *targetSampler, targets []corev1.Pod, size int) []corev1.Pod {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.keys == nil {
+		s.keys = map[string]bool{}
+	}
+
+	picked := []corev1.Pod{}
+	others := []corev1.Pod{}
+	for _, pod := range targets {
+		if s.keys[podKey(pod)] && len(picked) < size {
+			picked = append(picked, pod)
+			continue
+		}
+		others = append(others, pod)
+	}
+
+	for _, pod := range sampler.shuffle(others) {
+		if len(picked) >= size {
+			break
+		}
+		s.keys[podKey(pod)] = true
+		picked = append(picked, pod)
+	}
+
+	return picked
+}
+
+// intn returns a random number in the range [0, n)
+func (s *targetSampler) intn(n int) int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.rnd.Intn(n)
+}
+
+// duration returns a random duration in the range [minDuration, maxDuration]
+func (s *targetSampler) duration(minDuration time.Duration, maxDuration time.Duration) time.Duration {
+	if maxDuration <= minDuration {
+		return minDuration
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return minDuration + time.Duration(s.rnd.Int63n(int64(maxDuration-minDuration)+1))
+}
@@ -0,0 +1,155 @@
+package disruptors
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func buildTargets(count int) []corev1.Pod {
+	targets := []corev1.Pod{}
+	for i := 0; i < count; i++ {
+		targets = append(targets, builders.NewPodBuilder(fmt.Sprintf("pod-%d", i)).Build())
+	}
+
+	return targets
+}
+
+func Test_SampleTargets(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title      string
+		targets    int
+		percentage int
+		expected   int
+	}{
+		{
+			title:      "all targets",
+			targets:    10,
+			percentage: 0,
+			expected:   10,
+		},
+		{
+			title:      "percentage of targets",
+			targets:    10,
+			percentage: 30,
+			expected:   3,
+		},
+		{
+			title:      "at least one target",
+			targets:    2,
+			percentage: 10,
+			expected:   1,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			sample, err := newTargetSampler(0).percentage(buildTargets(tc.targets), tc.percentage)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if len(sample) != tc.expected {
+				t.Fatalf("expected %d targets got %d", tc.expected, len(sample))
+			}
+
+			if tc.percentage > 0 && percentageSize(tc.targets, tc.percentage) != tc.expected {
+				t.Fatalf("expected size %d got %d", tc.expected, percentageSize(tc.targets, tc.percentage))
+			}
+
+			// targets must not be repeated
+			names := map[string]bool{}
+			for _, pod := range sample {
+				if names[pod.Name] {
+					t.Fatalf("target %q is repeated", pod.Name)
+				}
+				names[pod.Name] = true
+			}
+		})
+	}
+}
+
+// sortedNames returns the names of the pods in alphabetical order
+func sortedNames(pods []corev1.Pod) []string {
+	names := utils.PodNames(pods)
+	slices.Sort(names)
+	return names
+}
+
+func Test_TargetSubset(t *testing.T) {
+	t.Parallel()
+
+	targets := buildTargets(10)
+	sampler := newTargetSampler(0)
+	subset := targetSubset{}
+
+	first := subset.pick(sampler, targets, 3)
+	if len(first) != 3 {
+		t.Fatalf("expected %d targets got %d", 3, len(first))
+	}
+
+	// the same targets are picked again
+	second := subset.pick(sampler, targets, 3)
+	if diff := cmp.Diff(sortedNames(first), sortedNames(second)); diff != "" {
+		t.Fatalf("picked targets changed\n%s", diff)
+	}
+
+	// a target that no longer exists is replaced
+	remaining := []corev1.Pod{}
+	for _, pod := range targets {
+		if pod.Name != first[0].Name {
+			remaining = append(remaining, pod)
+		}
+	}
+
+	third := subset.pick(sampler, remaining, 3)
+	if len(third) != 3 {
+		t.Fatalf("expected %d targets got %d", 3, len(third))
+	}
+
+	names := utils.PodNames(third)
+	for _, pod := range first[1:] {
+		if !slices.Contains(names, pod.Name) {
+			t.Fatalf("expected %q to be kept in %v", pod.Name, names)
+		}
+	}
+}
+
+func Test_SeededSampling(t *testing.T) {
+	t.Parallel()
+
+	targets := buildTargets(20)
+
+	// reversed order of the same targets
+	reversed := make([]corev1.Pod, len(targets))
+	for i := range targets {
+		reversed[len(targets)-1-i] = targets[i]
+	}
+
+	first, err := newTargetSampler(42).sample(targets, intstr.FromInt32(5))
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	second, err := newTargetSampler(42).sample(reversed, intstr.FromInt32(5))
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if diff := cmp.Diff(utils.PodNames(first), utils.PodNames(second)); diff != "" {
+		t.Fatalf("samples with the same seed do not match\n%s", diff)
+	}
+}
@@ -0,0 +1,86 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// faultStartDelay returns how long to wait before starting a fault scheduled to start after a delay or at a given
+// time. A start time in the past starts the fault immediately.
+func faultStartDelay(startAfter time.Duration, startAt time.Time) (time.Duration, error) {
+	if startAfter < 0 {
+		return 0, fmt.Errorf("startAfter cannot be negative: %s", startAfter)
+	}
+
+	if startAfter > 0 && !startAt.IsZero() {
+		return 0, fmt.Errorf("startAfter and startAt cannot be combined")
+	}
+
+	if !startAt.IsZero() {
+		return max(time.Until(startAt), 0), nil
+	}
+
+	return startAfter, nil
+}
+
+// waitFaultStart waits until a fault scheduled to start after a delay or at a given time must start
+func waitFaultStart(ctx context.Context, startAfter time.Duration, startAt time.Time) error {
+	delay, err := faultStartDelay(startAfter, startAt)
+	if err != nil {
+		return err
+	}
+
+	return waitFor(ctx, delay)
+}
+
+// waitFor waits for the given duration or until the context is done
+func waitFor(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// repeatFault returns the total duration of a fault that repeats periodically and the function that builds the
+// command for repeating it during the remaining duration. If the fault is not repeated, the duration of the fault
+// and the given build function are returned.
+func repeatFault(
+	build func(time.Duration) PodVisitCommand,
+	duration time.Duration,
+	every time.Duration,
+	total time.Duration,
+) (time.Duration, func(time.Duration) PodVisitCommand, error) {
+	if every == 0 && total == 0 {
+		return duration, build, nil
+	}
+
+	if every <= 0 {
+		return 0, nil, fmt.Errorf("repeatEvery must be greater than zero: %s", every)
+	}
+
+	if every < duration {
+		return 0, nil, fmt.Errorf("repeatEvery (%s) cannot be shorter than the duration of the fault (%s)", every, duration)
+	}
+
+	if total <= 0 {
+		return 0, nil, fmt.Errorf("repeatFor must be greater than zero: %s", total)
+	}
+
+	return total, func(remaining time.Duration) PodVisitCommand {
+		return PodRepeatCommand{
+			command:  build(duration),
+			every:    every,
+			duration: remaining,
+		}
+	}, nil
+}
@@ -0,0 +1,173 @@
+package disruptors
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_FaultStartDelay(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		startAfter  time.Duration
+		startAt     time.Time
+		expectError bool
+		minDelay    time.Duration
+		maxDelay    time.Duration
+	}{
+		{
+			title:       "no delay",
+			expectError: false,
+			minDelay:    0,
+			maxDelay:    0,
+		},
+		{
+			title:       "start after",
+			startAfter:  10 * time.Second,
+			expectError: false,
+			minDelay:    10 * time.Second,
+			maxDelay:    10 * time.Second,
+		},
+		{
+			title:       "start at",
+			startAt:     time.Now().Add(time.Minute),
+			expectError: false,
+			minDelay:    50 * time.Second,
+			maxDelay:    time.Minute,
+		},
+		{
+			title:       "start at time in the past",
+			startAt:     time.Now().Add(-time.Minute),
+			expectError: false,
+			minDelay:    0,
+			maxDelay:    0,
+		},
+		{
+			title:       "negative start after",
+			startAfter:  -time.Second,
+			expectError: true,
+		},
+		{
+			title:       "start after and start at",
+			startAfter:  time.Second,
+			startAt:     time.Now().Add(time.Minute),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			delay, err := faultStartDelay(tc.startAfter, tc.startAt)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if delay < tc.minDelay || delay > tc.maxDelay {
+				t.Fatalf("expected delay between %s and %s got %s", tc.minDelay, tc.maxDelay, delay)
+			}
+		})
+	}
+}
+
+func Test_RepeatFault(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title         string
+		duration      time.Duration
+		every         time.Duration
+		total         time.Duration
+		expectError   bool
+		expectRepeat  bool
+		expectedTotal time.Duration
+	}{
+		{
+			title:         "fault not repeated",
+			duration:      time.Minute,
+			expectError:   false,
+			expectRepeat:  false,
+			expectedTotal: time.Minute,
+		},
+		{
+			title:         "fault repeated",
+			duration:      time.Minute,
+			every:         10 * time.Minute,
+			total:         time.Hour,
+			expectError:   false,
+			expectRepeat:  true,
+			expectedTotal: time.Hour,
+		},
+		{
+			title:       "interval shorter than fault",
+			duration:    time.Minute,
+			every:       30 * time.Second,
+			total:       time.Hour,
+			expectError: true,
+		},
+		{
+			title:       "missing total duration",
+			duration:    time.Minute,
+			every:       10 * time.Minute,
+			expectError: true,
+		},
+		{
+			title:       "missing interval",
+			duration:    time.Minute,
+			total:       time.Hour,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			build := func(duration time.Duration) PodVisitCommand {
+				return PodHTTPFaultCommand{duration: duration}
+			}
+
+			total, repeatBuild, err := repeatFault(build, tc.duration, tc.every, tc.total)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if total != tc.expectedTotal {
+				t.Fatalf("expected total duration %s got %s", tc.expectedTotal, total)
+			}
+
+			// targets injected later repeat the fault for the remaining duration
+			command := repeatBuild(total / 2)
+			repeat, isRepeat := command.(PodRepeatCommand)
+			if isRepeat != tc.expectRepeat {
+				t.Fatalf("expected repeat command: %t got %T", tc.expectRepeat, command)
+			}
+
+			if isRepeat && (repeat.duration != total/2 || repeat.every != tc.every) {
+				t.Fatalf("unexpected repeat command %v", repeat)
+			}
+		})
+	}
+}
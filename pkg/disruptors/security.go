@@ -0,0 +1,137 @@
+package disruptors
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// AgentRestrictedEnvVar is the environment variable that informs the agent it runs without the NET_ADMIN
+	// capability. In this mode, the agent runs its proxies as non-transparent proxies.
+	AgentRestrictedEnvVar = "XK6_DISRUPTOR_AGENT_RESTRICTED"
+	// nobody is the user and group the agent runs as in restricted mode
+	nobody = int64(65534)
+)
+
+// AgentSecurityContext defines the security context of the agent injected in the targets
+type AgentSecurityContext struct {
+	// Restricted runs the agent complying with the Restricted Pod Security Standard: as a non-root user, without
+	// capabilities or privilege escalation and with the RuntimeDefault seccomp profile. Without the NET_ADMIN
+	// capability the agent cannot redirect the traffic of the targets to its proxy, therefore the faults only
+	// affect the requests sent directly to the proxy port.
+	Restricted bool
+	// RunAsUser is the user the agent runs as. Defaults to root or, in restricted mode, 65534.
+	RunAsUser int64 `js:"runAsUser"`
+	// RunAsGroup is the group the agent runs as. Defaults to root or, in restricted mode, 65534.
+	RunAsGroup int64 `js:"runAsGroup"`
+	// Capabilities added to the agent. Defaults to NET_ADMIN or, in restricted mode, none.
+	Capabilities []string
+	// SeccompProfile of the agent: RuntimeDefault, Unconfined or Localhost/<profile>. By default, no profile is
+	// set or, in restricted mode, RuntimeDefault.
+	SeccompProfile string `js:"seccompProfile"`
+}
+
+// defaultAgentSecurityContext returns the security context of the agent when none is configured
+func defaultAgentSecurityContext() *corev1.SecurityContext {
+	agentContext, _ := AgentSecurityContext{}.securityContext()
+	return agentContext
+}
+
+// seccompProfile returns the seccomp profile defined by its name
+func seccompProfile(profile string) (*corev1.SeccompProfile, error) {
+	if profile == "" {
+		return nil, nil //nolint:nilnil // no profile defined
+	}
+
+	profileType, localhostProfile, _ := strings.Cut(profile, "/")
+	switch corev1.SeccompProfileType(profileType) {
+	case corev1.SeccompProfileTypeRuntimeDefault, corev1.SeccompProfileTypeUnconfined:
+		return &corev1.SeccompProfile{Type: corev1.SeccompProfileType(profileType)}, nil
+	case corev1.SeccompProfileTypeLocalhost:
+		if localhostProfile == "" {
+			return nil, fmt.Errorf("localhost seccomp profile must be defined as Localhost/<profile>")
+		}
+		return &corev1.SeccompProfile{
+			Type:             corev1.SeccompProfileTypeLocalhost,
+			LocalhostProfile: &localhostProfile,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid seccomp profile %q", profile)
+	}
+}
+
+// validateRestricted returns an error if the security context does not comply with the restricted mode
+func (s AgentSecurityContext) validateRestricted() error {
+	for _, capability := range s.Capabilities {
+		if capability != "NET_BIND_SERVICE" {
+			return fmt.Errorf("capability %q cannot be added to the agent in restricted mode", capability)
+		}
+	}
+
+	if s.SeccompProfile == string(corev1.SeccompProfileTypeUnconfined) {
+		return fmt.Errorf("seccomp profile cannot be Unconfined in restricted mode")
+	}
+
+	return nil
+}
+
+// securityContext returns the security context of the agent container
+func (s AgentSecurityContext) securityContext() (*corev1.SecurityContext, error) {
+	if s.RunAsUser < 0 || s.RunAsGroup < 0 {
+		return nil, fmt.Errorf("runAsUser and runAsGroup cannot be negative")
+	}
+
+	capabilities := []corev1.Capability{"NET_ADMIN"}
+	if s.Capabilities != nil {
+		capabilities = []corev1.Capability{}
+		for _, capability := range s.Capabilities {
+			capabilities = append(capabilities, corev1.Capability(capability))
+		}
+	}
+
+	profile := s.SeccompProfile
+	user, group := s.RunAsUser, s.RunAsGroup
+	if s.Restricted {
+		err := s.validateRestricted()
+		if err != nil {
+			return nil, err
+		}
+
+		if s.Capabilities == nil {
+			capabilities = nil
+		}
+		if profile == "" {
+			profile = string(corev1.SeccompProfileTypeRuntimeDefault)
+		}
+		if user == 0 {
+			user = nobody
+		}
+		if group == 0 {
+			group = nobody
+		}
+	}
+
+	seccomp, err := seccompProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	runAsNonRoot := user != 0
+	agentContext := &corev1.SecurityContext{
+		Capabilities:   &corev1.Capabilities{Add: capabilities},
+		RunAsUser:      &user,
+		RunAsGroup:     &group,
+		RunAsNonRoot:   &runAsNonRoot,
+		SeccompProfile: seccomp,
+	}
+
+	if s.Restricted {
+		allowPrivilegeEscalation := false
+		agentContext.AllowPrivilegeEscalation = &allowPrivilegeEscalation
+		agentContext.Capabilities.Drop = []corev1.Capability{"ALL"}
+	}
+
+	return agentContext, nil
+}
@@ -0,0 +1,114 @@
+package disruptors
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_AgentSecurityContext(t *testing.T) {
+	t.Parallel()
+
+	root := int64(0)
+	user := int64(1000)
+	restrictedUser := nobody
+	notRoot := true
+	isRoot := false
+	noEscalation := false
+	profile := "profiles/agent.json"
+
+	testCases := []struct {
+		title       string
+		security    AgentSecurityContext
+		expectError bool
+		expected    *corev1.SecurityContext
+	}{
+		{
+			title:       "default",
+			security:    AgentSecurityContext{},
+			expectError: false,
+			expected: &corev1.SecurityContext{
+				Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}},
+				RunAsUser:    &root,
+				RunAsGroup:   &root,
+				RunAsNonRoot: &isRoot,
+			},
+		},
+		{
+			title: "custom user and seccomp profile",
+			security: AgentSecurityContext{
+				RunAsUser:      1000,
+				RunAsGroup:     1000,
+				Capabilities:   []string{"NET_ADMIN", "NET_RAW"},
+				SeccompProfile: "Localhost/profiles/agent.json",
+			},
+			expectError: false,
+			expected: &corev1.SecurityContext{
+				Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN", "NET_RAW"}},
+				RunAsUser:    &user,
+				RunAsGroup:   &user,
+				RunAsNonRoot: &notRoot,
+				SeccompProfile: &corev1.SeccompProfile{
+					Type:             corev1.SeccompProfileTypeLocalhost,
+					LocalhostProfile: &profile,
+				},
+			},
+		},
+		{
+			title:       "restricted",
+			security:    AgentSecurityContext{Restricted: true},
+			expectError: false,
+			expected: &corev1.SecurityContext{
+				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				RunAsUser:                &restrictedUser,
+				RunAsGroup:               &restrictedUser,
+				RunAsNonRoot:             &notRoot,
+				AllowPrivilegeEscalation: &noEscalation,
+				SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+		},
+		{
+			title:       "restricted with capability",
+			security:    AgentSecurityContext{Restricted: true, Capabilities: []string{"NET_ADMIN"}},
+			expectError: true,
+		},
+		{
+			title:       "restricted unconfined",
+			security:    AgentSecurityContext{Restricted: true, SeccompProfile: "Unconfined"},
+			expectError: true,
+		},
+		{
+			title:       "invalid seccomp profile",
+			security:    AgentSecurityContext{SeccompProfile: "Default"},
+			expectError: true,
+		},
+		{
+			title:       "localhost seccomp profile without path",
+			security:    AgentSecurityContext{SeccompProfile: "Localhost"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			securityContext, err := tc.security.securityContext()
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, securityContext); diff != "" {
+				t.Fatalf("expected security context does not match returned\n%s", diff)
+			}
+		})
+	}
+}
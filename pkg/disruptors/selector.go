@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
 
@@ -22,8 +24,12 @@ var ErrServiceNoTargets = errors.New("service does not have any backing pods")
 
 // PodSelector returns the target of a PodSelectorSpec
 type PodSelector struct {
-	helper helpers.PodHelper
-	spec   PodSelectorSpec
+	helper  helpers.PodHelper
+	spec    PodSelectorSpec
+	sampler *targetSampler
+	guard   namespaceGuard
+	// subset are the pods selected when the number of targets is limited by MaxTargets
+	subset targetSubset
 }
 
 // NewPodSelector creates a new PodSelector
@@ -31,28 +37,115 @@ func NewPodSelector(spec PodSelectorSpec, helper helpers.PodHelper) (*PodSelecto
 	// validate selector
 	emptySelect := reflect.DeepEqual(spec.Select, PodAttributes{})
 	emptyExclude := reflect.DeepEqual(spec.Exclude, PodAttributes{})
-	if spec.Namespace == "" && emptySelect && emptyExclude {
-		return nil, fmt.Errorf("namespace, select and exclude attributes in pod selector cannot all be empty")
+	emptyOwner := spec.Owner == PodOwner{}
+	emptyNodes := len(spec.Nodes.Names) == 0 && len(spec.Nodes.Labels) == 0
+	emptyNamespace := spec.Namespace == "" && !spec.multiNamespace()
+	if emptyNamespace && emptySelect && emptyExclude && emptyOwner && emptyNodes {
+		return nil, fmt.Errorf(
+			"namespace, namespaces, select, exclude, owner and nodes attributes in pod selector cannot all be empty",
+		)
+	}
+
+	if spec.Namespace != "" && spec.multiNamespace() {
+		return nil, fmt.Errorf("namespace cannot be combined with namespaces or namespaceLabels in pod selector")
+	}
+
+	for _, phase := range spec.Phases {
+		switch corev1.PodPhase(phase) {
+		case corev1.PodPending, corev1.PodRunning, corev1.PodSucceeded, corev1.PodFailed, corev1.PodUnknown:
+		default:
+			return nil, fmt.Errorf("invalid pod phase %q in pod selector", phase)
+		}
+	}
+
+	if err := spec.Status.validate(); err != nil {
+		return nil, err
+	}
+
+	if spec.MaxTargets < 0 {
+		return nil, fmt.Errorf("maxTargets cannot be negative: %d", spec.MaxTargets)
 	}
 
 	return &PodSelector{
-		spec:   spec,
-		helper: helper,
+		spec:    spec,
+		helper:  helper,
+		sampler: newTargetSampler(0),
 	}, nil
 }
 
-// Targets returns the list of target pods
-func (s *PodSelector) Targets(ctx context.Context) ([]corev1.Pod, error) {
+// filter returns the PodFilter that selects the pods matching the spec
+func (s *PodSelector) filter() helpers.PodFilter {
 	filter := helpers.PodFilter{
-		Select:  s.spec.Select.Labels,
-		Exclude: s.spec.Exclude.Labels,
+		Select:             s.spec.Select.Labels,
+		Exclude:            s.spec.Exclude.Labels,
+		SelectFields:       s.spec.Select.Fields,
+		ExcludeFields:      s.spec.Exclude.Fields,
+		SelectAnnotations:  s.spec.Select.Annotations,
+		ExcludeAnnotations: s.spec.Exclude.Annotations,
+		Owner: helpers.Owner{
+			Kind: s.spec.Owner.Kind,
+			Name: s.spec.Owner.Name,
+		},
+		SelectExpressions:    labelExpressions(s.spec.Select.MatchExpressions),
+		ExcludeExpressions:   labelExpressions(s.spec.Exclude.MatchExpressions),
+		SelectLabelPatterns:  s.spec.Select.LabelPatterns,
+		ExcludeLabelPatterns: s.spec.Exclude.LabelPatterns,
+		NodeNames:            s.spec.Nodes.Names,
+		NodeLabels:           s.spec.Nodes.Labels,
+		Namespaces:           s.spec.Namespaces,
+		NamespaceLabels:      s.spec.NamespaceLabels,
+		ExcludeTerminating:   !s.spec.IncludeTerminating,
+		ExcludeNotReady:      s.spec.ReadyOnly,
+		QOSClass:             corev1.PodQOSClass(s.spec.Status.QOSClass),
+		MinRestarts:          int32(s.spec.Status.MinRestarts), //nolint:gosec // validated to be a small positive value
 	}
 
-	targets, err := s.helper.List(ctx, filter)
+	for condition, status := range s.spec.Status.Conditions {
+		if filter.Conditions == nil {
+			filter.Conditions = map[corev1.PodConditionType]corev1.ConditionStatus{}
+		}
+		filter.Conditions[corev1.PodConditionType(condition)] = corev1.ConditionStatus(status)
+	}
+
+	if s.spec.Status.RestartedWithin > 0 {
+		filter.RestartedSince = time.Now().Add(-s.spec.Status.RestartedWithin)
+	}
+
+	if len(s.spec.Phases) > 0 {
+		for _, phase := range s.spec.Phases {
+			filter.Phases = append(filter.Phases, corev1.PodPhase(phase))
+		}
+	} else {
+		// pods that have completed cannot be disrupted
+		filter.ExcludePhases = []corev1.PodPhase{corev1.PodSucceeded, corev1.PodFailed}
+	}
+
+	return filter
+}
+
+// Targets returns the list of target pods. If the targets are limited by MaxTargets, the pods are selected at random
+// the first time and the same pods are returned afterwards, as long as they match the selector.
+func (s *PodSelector) Targets(ctx context.Context) ([]corev1.Pod, error) {
+	targets, err := s.AllTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.spec.MaxTargets == 0 || len(targets) <= s.spec.MaxTargets {
+		return targets, nil
+	}
+
+	return s.subset.pick(s.sampler, targets, s.spec.MaxTargets), nil
+}
+
+// AllTargets returns all the pods that match the selector, regardless of MaxTargets
+func (s *PodSelector) AllTargets(ctx context.Context) ([]corev1.Pod, error) {
+	targets, err := s.helper.List(ctx, s.filter())
 	if err != nil {
 		return nil, err
 	}
 
+	targets = s.guard.filter(targets)
 	if len(targets) == 0 {
 		return nil, fmt.Errorf("finding pods matching '%s': %w", s.spec, ErrSelectorNoPods)
 	}
@@ -61,37 +154,186 @@ func (s *PodSelector) Targets(ctx context.Context) ([]corev1.Pod, error) {
 }
 
 // NamespaceOrDefault returns the configured namespace for this selector, and the name of the default namespace if it
-// is not configured.
+// is not configured. If the selector spans multiple namespaces, it returns metav1.NamespaceAll.
 func (p PodSelectorSpec) NamespaceOrDefault() string {
 	if p.Namespace != "" {
 		return p.Namespace
 	}
 
+	if p.multiNamespace() {
+		return metav1.NamespaceAll
+	}
+
 	return metav1.NamespaceDefault
 }
 
+// validate checks the status attributes are valid
+func (a PodStatusAttributes) validate() error {
+	switch corev1.PodQOSClass(a.QOSClass) {
+	case "", corev1.PodQOSGuaranteed, corev1.PodQOSBurstable, corev1.PodQOSBestEffort:
+	default:
+		return fmt.Errorf("invalid QoS class %q in pod selector", a.QOSClass)
+	}
+
+	for condition, status := range a.Conditions {
+		switch corev1.ConditionStatus(status) {
+		case corev1.ConditionTrue, corev1.ConditionFalse, corev1.ConditionUnknown:
+		default:
+			return fmt.Errorf("invalid status %q for condition %q in pod selector", status, condition)
+		}
+	}
+
+	if a.MinRestarts < 0 || a.MinRestarts > math.MaxInt32 {
+		return fmt.Errorf("invalid minRestarts in pod selector: %d", a.MinRestarts)
+	}
+
+	if a.RestartedWithin < 0 {
+		return fmt.Errorf("restartedWithin cannot be negative: %s", a.RestartedWithin)
+	}
+
+	return nil
+}
+
+// String returns a human-readable explanation of the status attributes
+func (a PodStatusAttributes) String() string {
+	str := ""
+	if len(a.Conditions) > 0 {
+		str += " " + strings.TrimSuffix(groupLabels("with conditions", a.Conditions), ", ")
+	}
+
+	if a.QOSClass != "" {
+		str += fmt.Sprintf(" of QoS class %s", a.QOSClass)
+	}
+
+	if a.MinRestarts > 0 || a.RestartedWithin > 0 {
+		str += " restarted"
+	}
+
+	if a.MinRestarts > 0 {
+		str += fmt.Sprintf(" at least %d times", a.MinRestarts)
+	}
+
+	if a.RestartedWithin > 0 {
+		str += fmt.Sprintf(" within %s", a.RestartedWithin)
+	}
+
+	return str
+}
+
+// multiNamespace returns if the selector selects pods from multiple namespaces
+func (p PodSelectorSpec) multiNamespace() bool {
+	return len(p.Namespaces) > 0 || len(p.NamespaceLabels) > 0
+}
+
 // String returns a human-readable explanation of the pods matched by a PodSelector.
 func (p PodSelectorSpec) String() string {
 	var str string
 
-	if len(p.Select.Labels) == 0 && len(p.Exclude.Labels) == 0 {
+	switch {
+	case p.Owner != (PodOwner{}):
+		str = fmt.Sprintf("pods of %s %q", p.Owner.Kind, p.Owner.Name)
+	case p.Select.empty() && p.Exclude.empty():
 		str = "all pods"
-	} else {
-		str = "pods "
-		str += p.groupLabels("including", p.Select.Labels)
-		str += p.groupLabels("excluding", p.Exclude.Labels)
+	default:
+		str = "pods"
+	}
+
+	if !p.Select.empty() || !p.Exclude.empty() {
+		str += " "
+		str += groupLabels("including", p.Select.Labels)
+		str += groupLabels("excluding", p.Exclude.Labels)
+		str += groupLabels("with fields", p.Select.Fields)
+		str += groupLabels("without fields", p.Exclude.Fields)
+		str += groupLabels("annotated", p.Select.Annotations)
+		str += groupLabels("not annotated", p.Exclude.Annotations)
+		str += groupExpressions("matching", p.Select.MatchExpressions)
+		str += groupExpressions("not matching", p.Exclude.MatchExpressions)
+		str += groupLabels("including patterns", p.Select.LabelPatterns)
+		str += groupLabels("excluding patterns", p.Exclude.LabelPatterns)
 		str = strings.TrimSuffix(str, ", ")
 	}
 
-	str += fmt.Sprintf(" in ns %q", p.NamespaceOrDefault())
+	switch {
+	case len(p.Namespaces) > 0:
+		str += fmt.Sprintf(" in namespaces %s", strings.Join(p.Namespaces, ", "))
+		if len(p.NamespaceLabels) > 0 {
+			str += " " + strings.TrimSuffix(groupLabels("labeled", p.NamespaceLabels), ", ")
+		}
+	case len(p.NamespaceLabels) > 0:
+		str += " " + strings.TrimSuffix(groupLabels("in namespaces labeled", p.NamespaceLabels), ", ")
+	default:
+		str += fmt.Sprintf(" in ns %q", p.NamespaceOrDefault())
+	}
+
+	if len(p.Nodes.Names) > 0 {
+		str += fmt.Sprintf(" on nodes %s", strings.Join(p.Nodes.Names, ", "))
+	}
+
+	if len(p.Nodes.Labels) > 0 {
+		str += " " + strings.TrimSuffix(groupLabels("on nodes labeled", p.Nodes.Labels), ", ")
+	}
+
+	if len(p.Phases) > 0 {
+		str += fmt.Sprintf(" in phases %s", strings.Join(p.Phases, ", "))
+	}
+
+	if p.ReadyOnly {
+		str += " if ready"
+	}
+
+	str += p.Status.String()
+
+	if p.IncludeTerminating {
+		str += " including terminating"
+	}
+
+	if p.MaxTargets > 0 {
+		str += fmt.Sprintf(" (at most %d)", p.MaxTargets)
+	}
 
 	return str
 }
 
+// empty returns if no attribute is defined
+func (a PodAttributes) empty() bool {
+	return len(a.Labels) == 0 && len(a.Fields) == 0 && len(a.Annotations) == 0 &&
+		len(a.MatchExpressions) == 0 && len(a.LabelPatterns) == 0
+}
+
+// labelExpressions converts LabelExpressions to the expressions used by the PodHelper
+func labelExpressions(expressions []LabelExpression) []helpers.LabelExpression {
+	converted := []helpers.LabelExpression{}
+	for _, e := range expressions {
+		converted = append(converted, helpers.LabelExpression{
+			Key:      e.Key,
+			Operator: e.Operator,
+			Values:   e.Values,
+		})
+	}
+
+	return converted
+}
+
+// groupExpressions returns a group of label expressions as a string in the same form as groupLabels
+func groupExpressions(groupName string, expressions []LabelExpression) string {
+	if len(expressions) == 0 {
+		return ""
+	}
+
+	group := groupName + "("
+	for _, e := range expressions {
+		group += strings.TrimSpace(fmt.Sprintf("%s %s %s", e.Key, e.Operator, strings.Join(e.Values, ","))) + ", "
+	}
+	group = strings.TrimSuffix(group, ", ")
+	group += "), "
+
+	return group
+}
+
 // groupLabels returns a group of labels as a string, giving that group a name. The returned string has the form of:
 // `groupName(foo=bar, boo=baz), `, including the trailing space and comma.
 // An empty group of labels produces an empty string.
-func (PodSelectorSpec) groupLabels(groupName string, labels map[string]string) string {
+func groupLabels(groupName string, labels map[string]string) string {
 	if len(labels) == 0 {
 		return ""
 	}
@@ -139,3 +381,54 @@ func (s *ServicePodSelector) Targets(ctx context.Context) ([]corev1.Pod, error)
 
 	return targets, nil
 }
+
+// ErrSelectorNoNodes is returned by NewNodeDisruptor when the selector passed to it does not match any node in the
+// cluster.
+var ErrSelectorNoNodes = errors.New("no nodes found matching selector")
+
+// NodeSelector returns the targets of a NodeSelectorSpec
+type NodeSelector struct {
+	helper helpers.NodeHelper
+	spec   NodeSelectorSpec
+}
+
+// NewNodeSelector creates a new NodeSelector
+func NewNodeSelector(spec NodeSelectorSpec, helper helpers.NodeHelper) (*NodeSelector, error) {
+	// prevent selecting all nodes in the cluster, including the control-plane nodes, by mistake
+	if len(spec.Select.Labels) == 0 {
+		return nil, fmt.Errorf("select attributes in node selector cannot be empty")
+	}
+
+	return &NodeSelector{
+		spec:   spec,
+		helper: helper,
+	}, nil
+}
+
+// Targets returns the list of target nodes
+func (s *NodeSelector) Targets(ctx context.Context) ([]corev1.Node, error) {
+	filter := helpers.NodeFilter{
+		Select:  s.spec.Select.Labels,
+		Exclude: s.spec.Exclude.Labels,
+	}
+
+	targets, err := s.helper.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("finding nodes matching '%s': %w", s.spec, ErrSelectorNoNodes)
+	}
+
+	return targets, nil
+}
+
+// String returns a human-readable explanation of the nodes matched by a NodeSelector.
+func (n NodeSelectorSpec) String() string {
+	str := "nodes "
+	str += groupLabels("including", n.Select.Labels)
+	str += groupLabels("excluding", n.Exclude.Labels)
+
+	return strings.TrimSuffix(str, ", ")
+}
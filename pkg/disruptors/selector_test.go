@@ -4,6 +4,7 @@ import (
 	"context"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
@@ -11,6 +12,7 @@ import (
 	"github.com/grafana/xk6-disruptor/pkg/utils"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -41,6 +43,53 @@ func Test_NewPodSelector(t *testing.T) {
 			spec:        PodSelectorSpec{},
 			expectError: true,
 		},
+		{
+			title: "multiple namespaces",
+			spec: PodSelectorSpec{
+				Namespaces: []string{"ns-1", "ns-2"},
+			},
+			expectError: false,
+		},
+		{
+			title: "namespace and namespaces",
+			spec: PodSelectorSpec{
+				Namespace:       "test-ns",
+				NamespaceLabels: map[string]string{"team": "checkout"},
+			},
+			expectError: true,
+		},
+		{
+			title: "invalid phase",
+			spec: PodSelectorSpec{
+				Namespace: "test-ns",
+				Phases:    []string{"Started"},
+			},
+			expectError: true,
+		},
+		{
+			title: "invalid QoS class",
+			spec: PodSelectorSpec{
+				Namespace: "test-ns",
+				Status:    PodStatusAttributes{QOSClass: "Best"},
+			},
+			expectError: true,
+		},
+		{
+			title: "invalid condition status",
+			spec: PodSelectorSpec{
+				Namespace: "test-ns",
+				Status:    PodStatusAttributes{Conditions: map[string]string{"Ready": "No"}},
+			},
+			expectError: true,
+		},
+		{
+			title: "negative max targets",
+			spec: PodSelectorSpec{
+				Namespace:  "test-ns",
+				MaxTargets: -1,
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -70,6 +119,57 @@ func Test_NewPodSelector(t *testing.T) {
 	}
 }
 
+func Test_NewNodeSelector(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		spec        NodeSelectorSpec
+		expectError bool
+	}{
+		{
+			title: "valid specs",
+			spec: NodeSelectorSpec{
+				Select: NodeAttributes{Labels: map[string]string{"pool": "workers"}},
+			},
+			expectError: false,
+		},
+		{
+			title:       "empty specs",
+			spec:        NodeSelectorSpec{},
+			expectError: true,
+		},
+		{
+			title: "only exclude",
+			spec: NodeSelectorSpec{
+				Exclude: NodeAttributes{Labels: map[string]string{"pool": "system"}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset()
+			k, _ := kubernetes.NewFakeKubernetes(client)
+
+			_, err := NewNodeSelector(tc.spec, k.NodeHelper())
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+		})
+	}
+}
+
 func Test_PodSelectorString(t *testing.T) {
 	t.Parallel()
 
@@ -86,7 +186,7 @@ func Test_PodSelectorString(t *testing.T) {
 			name: "Only inclusions",
 			selector: PodSelectorSpec{
 				Namespace: "testns",
-				Select:    PodAttributes{map[string]string{"foo": "bar"}},
+				Select:    PodAttributes{Labels: map[string]string{"foo": "bar"}},
 			},
 			expected: `pods including(foo=bar) in ns "testns"`,
 		},
@@ -94,7 +194,7 @@ func Test_PodSelectorString(t *testing.T) {
 			name: "Only exclusions",
 			selector: PodSelectorSpec{
 				Namespace: "testns",
-				Exclude:   PodAttributes{map[string]string{"foo": "bar"}},
+				Exclude:   PodAttributes{Labels: map[string]string{"foo": "bar"}},
 			},
 			expected: `pods excluding(foo=bar) in ns "testns"`,
 		},
@@ -102,11 +202,113 @@ func Test_PodSelectorString(t *testing.T) {
 			name: "Both inclusions and exclusions",
 			selector: PodSelectorSpec{
 				Namespace: "testns",
-				Select:    PodAttributes{map[string]string{"foo": "bar"}},
-				Exclude:   PodAttributes{map[string]string{"boo": "baa"}},
+				Select:    PodAttributes{Labels: map[string]string{"foo": "bar"}},
+				Exclude:   PodAttributes{Labels: map[string]string{"boo": "baa"}},
 			},
 			expected: `pods including(foo=bar), excluding(boo=baa) in ns "testns"`,
 		},
+		{
+			name: "Field selectors",
+			selector: PodSelectorSpec{
+				Namespace: "testns",
+				Select:    PodAttributes{Fields: map[string]string{"spec.nodeName": "node-1"}},
+				Exclude:   PodAttributes{Fields: map[string]string{"status.phase": "Pending"}},
+			},
+			expected: `pods with fields(spec.nodeName=node-1), without fields(status.phase=Pending) in ns "testns"`,
+		},
+		{
+			name: "Annotations",
+			selector: PodSelectorSpec{
+				Namespace: "testns",
+				Select:    PodAttributes{Annotations: map[string]string{"chaos.example.com/allowed": "true"}},
+			},
+			expected: `pods annotated(chaos.example.com/allowed=true) in ns "testns"`,
+		},
+		{
+			name: "Owner",
+			selector: PodSelectorSpec{
+				Namespace: "testns",
+				Owner:     PodOwner{Kind: "Deployment", Name: "checkout"},
+				Exclude:   PodAttributes{Labels: map[string]string{"canary": "true"}},
+			},
+			expected: `pods of Deployment "checkout" excluding(canary=true) in ns "testns"`,
+		},
+		{
+			name: "Label expressions",
+			selector: PodSelectorSpec{
+				Namespace: "testns",
+				Select: PodAttributes{MatchExpressions: []LabelExpression{
+					{Key: "env", Operator: "In", Values: []string{"dev", "staging"}},
+				}},
+				Exclude: PodAttributes{MatchExpressions: []LabelExpression{
+					{Key: "canary", Operator: "Exists"},
+				}},
+			},
+			expected: `pods matching(env In dev,staging), not matching(canary Exists) in ns "testns"`,
+		},
+		{
+			name: "Nodes",
+			selector: PodSelectorSpec{
+				Namespace: "testns",
+				Select:    PodAttributes{Labels: map[string]string{"foo": "bar"}},
+				Nodes:     PodNodes{Labels: map[string]string{"topology.kubernetes.io/zone": "a"}},
+			},
+			expected: `pods including(foo=bar) in ns "testns" on nodes labeled(topology.kubernetes.io/zone=a)`,
+		},
+		{
+			name: "Max targets",
+			selector: PodSelectorSpec{
+				Namespace:  "testns",
+				Select:     PodAttributes{Labels: map[string]string{"foo": "bar"}},
+				MaxTargets: 2,
+			},
+			expected: `pods including(foo=bar) in ns "testns" (at most 2)`,
+		},
+		{
+			name: "Label patterns",
+			selector: PodSelectorSpec{
+				Namespace: "testns",
+				Select:    PodAttributes{LabelPatterns: map[string]string{"app": "checkout-.*"}},
+			},
+			expected: `pods including patterns(app=checkout-.*) in ns "testns"`,
+		},
+		{
+			name: "Phases and readiness",
+			selector: PodSelectorSpec{
+				Namespace: "testns",
+				Select:    PodAttributes{Labels: map[string]string{"foo": "bar"}},
+				Phases:    []string{"Running"},
+				ReadyOnly: true,
+			},
+			expected: `pods including(foo=bar) in ns "testns" in phases Running if ready`,
+		},
+		{
+			name: "Status",
+			selector: PodSelectorSpec{
+				Namespace: "testns",
+				Status: PodStatusAttributes{
+					QOSClass:        "BestEffort",
+					MinRestarts:     3,
+					RestartedWithin: 5 * time.Minute,
+				},
+			},
+			expected: `all pods in ns "testns" of QoS class BestEffort restarted at least 3 times within 5m0s`,
+		},
+		{
+			name: "Multiple namespaces",
+			selector: PodSelectorSpec{
+				Namespaces: []string{"ns-1", "ns-2"},
+				Select:     PodAttributes{Labels: map[string]string{"foo": "bar"}},
+			},
+			expected: `pods including(foo=bar) in namespaces ns-1, ns-2`,
+		},
+		{
+			name: "Namespace labels",
+			selector: PodSelectorSpec{
+				NamespaceLabels: map[string]string{"team": "checkout"},
+			},
+			expected: `all pods in namespaces labeled(team=checkout)`,
+		},
 	} {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
@@ -162,6 +364,85 @@ func Test_PodSelectorTargets(t *testing.T) {
 			expected:    nil,
 			expectError: true,
 		},
+		{
+			title:     "max targets not reached",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					Build(),
+				builders.NewPodBuilder("pod-2").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					Build(),
+			},
+			spec: PodSelectorSpec{
+				Namespace: "test-ns",
+				Select: PodAttributes{Labels: map[string]string{
+					"app": "test",
+				}},
+				MaxTargets: 2,
+			},
+			expectError: false,
+			expected:    []string{"pod-1", "pod-2"},
+		},
+		{
+			title:     "completed and terminating pods are not selected",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithPhase(corev1.PodRunning).
+					Build(),
+				builders.NewPodBuilder("pod-2").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithPhase(corev1.PodFailed).
+					Build(),
+				builders.NewPodBuilder("pod-3").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithPhase(corev1.PodRunning).
+					WithTerminating().
+					Build(),
+			},
+			spec: PodSelectorSpec{
+				Namespace: "test-ns",
+				Select: PodAttributes{Labels: map[string]string{
+					"app": "test",
+				}},
+			},
+			expectError: false,
+			expected:    []string{"pod-1"},
+		},
+		{
+			title:     "multiple namespaces",
+			namespace: metav1.NamespaceAll,
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("ns-1").
+					WithLabel("app", "test").
+					Build(),
+				builders.NewPodBuilder("pod-2").
+					WithNamespace("ns-2").
+					WithLabel("app", "test").
+					Build(),
+				builders.NewPodBuilder("pod-3").
+					WithNamespace("ns-3").
+					WithLabel("app", "test").
+					Build(),
+			},
+			spec: PodSelectorSpec{
+				Namespaces: []string{"ns-1", "ns-2"},
+				Select: PodAttributes{Labels: map[string]string{
+					"app": "test",
+				}},
+			},
+			expectError: false,
+			expected:    []string{"pod-1", "pod-2"},
+		},
 	}
 
 	for _, tc := range testCases {
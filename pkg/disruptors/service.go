@@ -2,7 +2,10 @@ package disruptors
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"time"
 
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes"
@@ -18,6 +21,7 @@ type ServiceDisruptor interface {
 	Disruptor
 	ProtocolFaultInjector
 	PodFaultInjector
+	RecoveryVerifier
 }
 
 // ServiceDisruptorOptions defines options that controls the behavior of the ServiceDisruptor
@@ -25,14 +29,182 @@ type ServiceDisruptorOptions struct {
 	// timeout when waiting agent to be injected (default 30s). A zero value forces default.
 	// A Negative value forces no waiting.
 	InjectTimeout time.Duration `js:"injectTimeout"`
+	// Percentage of the pods backing the service that are disrupted. A zero value disrupts all pods.
+	Percentage int
+	// Seed for the random selection of targets. Using the same seed selects the same targets across runs.
+	// A zero value selects targets differently on each run.
+	Seed int64
+	// DynamicTargets injects the faults in the pods that start backing the service while the fault is active.
+	// Cannot be combined with Percentage.
+	DynamicTargets bool `js:"dynamicTargets"`
+	// ReapplyOnRestart re-applies the faults in the targets whose containers restart while the fault is active.
+	// The agent reports the number of times the fault was re-applied in the status of the target.
+	ReapplyOnRestart bool `js:"reapplyOnRestart"`
+	// ReinjectReplaced injects the faults in the pods that replace the targets deleted while the fault is active.
+	// The status of the replacement reports the target it replaces.
+	ReinjectReplaced bool `js:"reinjectReplaced"`
+	// ProtectedNamespaces are namespaces the disruptor refuses to target, in addition to kube-system,
+	// kube-node-lease and the namespaces defined in the XK6_DISRUPTOR_PROTECTED_NAMESPACES environment variable.
+	ProtectedNamespaces []string `js:"protectedNamespaces"`
+	// AllowProtectedNamespaces allows targeting protected namespaces
+	AllowProtectedNamespaces bool `js:"allowProtectedNamespaces"`
+	// DryRun resolves the targets and prints the commands the agent would run in each of them when injecting
+	// faults, without injecting anything. Pods are not terminated either.
+	DryRun bool `js:"dryRun"`
+	// HealthCheck verifies the targets are Ready and none of their containers is in CrashLoopBackOff before
+	// injecting faults. With "fail", the injection fails if any target is unhealthy. With "skip", faults are
+	// not injected in the unhealthy targets. By default, the health of the targets is not checked.
+	HealthCheck string `js:"healthCheck"`
+	// Limits bound the pods disrupted. They cannot relax the limits defined by the XK6_DISRUPTOR_MAX_PODS,
+	// XK6_DISRUPTOR_MAX_NAMESPACES and XK6_DISRUPTOR_MAX_PERCENTAGE environment variables.
+	Limits SafetyLimits
+	// AgentImage is the image of the agent injected in the targets. Defaults to the image defined in the
+	// XK6_DISRUPTOR_AGENT_IMAGE environment variable or, if not defined, the image of this version of the extension.
+	AgentImage string `js:"agentImage"`
+	// AgentImagePullPolicy is the pull policy of the agent image: Always, IfNotPresent or Never. Defaults to the
+	// policy defined in the XK6_DISRUPTOR_AGENT_IMAGE_PULL_POLICY environment variable or, if not defined,
+	// IfNotPresent.
+	AgentImagePullPolicy string `js:"agentImagePullPolicy"`
+	// AgentImagePullSecrets are the secrets required for pulling the agent image. Defaults to the secrets defined in
+	// the XK6_DISRUPTOR_AGENT_IMAGE_PULL_SECRETS environment variable. The agent uses the imagePullSecrets of the
+	// targets, therefore the targets must reference these secrets.
+	AgentImagePullSecrets []string `js:"agentImagePullSecrets"`
+	// AgentImages are the images of the agent for the architectures of the nodes of the targets
+	// (e.g. {"arm64": "registry.local/xk6-disruptor-agent:arm64"}). They take precedence over AgentImage.
+	AgentImages map[string]string `js:"agentImages"`
+	// AgentSecurityContext is the security context of the agent injected in the targets
+	AgentSecurityContext AgentSecurityContext `js:"agentSecurityContext"`
+	// AgentVolumes are volumes of the targets mounted, read-only, in the agent under AgentVolumesPath, such as the
+	// secrets with the certificates for the tls option of HTTP faults. Volumes cannot be added to an agent that was
+	// already injected in a target.
+	AgentVolumes []string `js:"agentVolumes"`
+	// Concurrency is the maximum number of targets the agent is injected in and the faults are applied to
+	// simultaneously. A zero value does not limit the concurrency.
+	Concurrency int
+	// InjectRate is the maximum number of targets per second the agent is injected in. A zero value does not
+	// limit the rate.
+	InjectRate float64 `js:"injectRate"`
+	// InjectRetries is the number of times the injection of the agent in a target is retried if it fails with a
+	// transient error. If zero, the retries of the requests to the cluster are used (see kubernetes.Config).
+	InjectRetries int `js:"injectRetries"`
+	// InjectRetryBackoff is the initial delay between the retries of the injection of the agent. The delay doubles
+	// after each retry. Defaults to 1s.
+	InjectRetryBackoff time.Duration `js:"injectRetryBackoff"`
+	// ContinueOnError applies the faults to all the targets even if some of them fail. The errors of all the
+	// failed targets are reported once the faults have finished.
+	ContinueOnError bool `js:"continueOnError"`
+	// AllowPartial applies the faults to all the targets even if some of them fail, and does not report an error if
+	// the faults were applied to at least one target. The targets where the faults failed are returned instead.
+	AllowPartial bool `js:"allowPartial"`
+	// ExecTimeout is the time the agent is given, in addition to the duration of the fault, to complete the
+	// fault in a target. Targets exceeding it are reported as timed out. A zero value does not limit the time.
+	ExecTimeout time.Duration `js:"execTimeout"`
+	// AgentLogsOnError adds the last lines of the log of the agent in a target to the error reported when the fault
+	// fails in the target
+	AgentLogsOnError bool `js:"agentLogsOnError"`
+	// InjectionMode defines how the agent is added to the targets: injected as an ephemeral container (ephemeral)
+	// or added as a sidecar when the targets were created (sidecar). Defaults to ephemeral.
+	InjectionMode string `js:"injectionMode"`
+	// AgentControl defines how the faults are applied by the agent: executing the agent commands for the duration
+	// of the faults (exec) or requesting them to the control API of the agent (api). Defaults to exec.
+	AgentControl string `js:"agentControl"`
 }
 
 // serviceDisruptor is an instance of a ServiceDisruptor
 type serviceDisruptor struct {
+	k8s      kubernetes.Kubernetes
 	service  corev1.Service
 	helper   helpers.PodHelper
 	selector *ServicePodSelector
 	options  ServiceDisruptorOptions
+	sampler  *targetSampler
+	limits   SafetyLimits
+	lock     targetLock
+	// subset are the targets selected when only a percentage of them is disrupted
+	subset targetSubset
+	// agentOptions are the options for injecting the agent in the targets
+	agentOptions PodAgentVisitorOptions
+	// out is where the commands are printed in dry run
+	out io.Writer
+	// replacements maps the pods injected as replacement of deleted targets to the target they replace
+	replacements map[string]string
+}
+
+// validate returns an error if the options are not valid
+func (o ServiceDisruptorOptions) validate() error {
+	err := validatePercentage(o.Percentage)
+	if err != nil {
+		return err
+	}
+
+	if o.DynamicTargets && o.Percentage > 0 {
+		return fmt.Errorf("dynamicTargets cannot be combined with percentage")
+	}
+
+	err = validateHealthCheck(o.HealthCheck)
+	if err != nil {
+		return err
+	}
+
+	err = validateInjectionMode(o.InjectionMode)
+	if err != nil {
+		return err
+	}
+
+	err = validateAgentControl(o.AgentControl)
+	if err != nil {
+		return err
+	}
+
+	err = validateAgentImages(o.AgentImages)
+	if err != nil {
+		return err
+	}
+
+	if o.InjectRetries < 0 || o.InjectRetryBackoff < 0 {
+		return fmt.Errorf("injectRetries and injectRetryBackoff cannot be negative")
+	}
+
+	if o.ExecTimeout < 0 {
+		return fmt.Errorf("execTimeout cannot be negative")
+	}
+
+	return o.controllerOptions().validate()
+}
+
+// controllerOptions returns the options for visiting the targets
+func (o ServiceDisruptorOptions) controllerOptions() PodControllerOptions {
+	return PodControllerOptions{
+		Concurrency:     o.Concurrency,
+		Rate:            o.InjectRate,
+		ContinueOnError: o.ContinueOnError,
+		AllowPartial:    o.AllowPartial,
+	}
+}
+
+// agentOptions returns the options for injecting the agent in the targets
+func (o ServiceDisruptorOptions) agentOptions() (PodAgentVisitorOptions, error) {
+	agentOptions, err := newAgentOptions(
+		o.InjectTimeout,
+		o.AgentImage,
+		o.AgentImagePullPolicy,
+		o.AgentImagePullSecrets,
+		o.AgentSecurityContext,
+	)
+	if err != nil {
+		return PodAgentVisitorOptions{}, err
+	}
+
+	agentOptions.Retries = o.InjectRetries
+	agentOptions.RetryBackoff = o.InjectRetryBackoff
+	agentOptions.LogsOnError = o.AgentLogsOnError
+	agentOptions.Sidecar = o.InjectionMode == InjectionSidecar
+	agentOptions.ExecTimeout = o.ExecTimeout
+	agentOptions.ControlAPI = o.AgentControl == AgentControlAPI
+	agentOptions.Images = o.AgentImages
+	agentOptions.Volumes = o.AgentVolumes
+
+	return agentOptions, nil
 }
 
 // NewServiceDisruptor creates a new instance of a ServiceDisruptor that targets the given service
@@ -51,6 +223,23 @@ func NewServiceDisruptor(
 		return nil, fmt.Errorf("must specify a namespace")
 	}
 
+	err := newNamespaceGuard(options.ProtectedNamespaces, options.AllowProtectedNamespaces).check(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkPolicy(ctx, k8s.Client(), func() ([]string, error) {
+		return []string{namespace}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = CheckPermissions(ctx, k8s.Client(), servicePermissions(service, namespace))
+	if err != nil {
+		return nil, err
+	}
+
 	svc, err := k8s.Client().CoreV1().Services(namespace).Get(ctx, service, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
@@ -61,11 +250,39 @@ func NewServiceDisruptor(
 		return nil, err
 	}
 
+	err = options.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	limits, err := newSafetyLimits(options.Limits)
+	if err != nil {
+		return nil, err
+	}
+
+	agentOptions, err := options.agentOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	agentConfig, err := loadAgentConfig(ctx, k8s.Client())
+	if err != nil {
+		return nil, err
+	}
+	agentOptions.AgentArgs = agentConfig.args()
+
 	return &serviceDisruptor{
-		service:  *svc,
-		helper:   k8s.PodHelper(namespace),
-		selector: selector,
-		options:  options,
+		k8s:          k8s,
+		service:      *svc,
+		helper:       k8s.PodHelper(namespace),
+		selector:     selector,
+		options:      options,
+		sampler:      newTargetSampler(options.Seed),
+		limits:       limits,
+		lock:         newTargetLock(k8s.Client()),
+		agentOptions: agentOptions,
+		out:          os.Stdout,
+		replacements: map[string]string{},
 	}, nil
 }
 
@@ -75,34 +292,41 @@ func (d *serviceDisruptor) InjectHTTPFaults(
 	duration time.Duration,
 	options HTTPDisruptionOptions,
 ) error {
-	// Map service port to a target pod port
-	port, err := utils.GetTargetPort(d.service, fault.Port)
+	podFault, err := d.podHTTPFault(fault)
 	if err != nil {
 		return err
 	}
-	podFault := fault
-	podFault.Port = port
 
-	command := PodHTTPFaultCommand{
-		fault:    podFault,
-		duration: duration,
-		options:  options,
+	err = HTTPFaultSpec{Fault: podFault, Options: options}.Validate()
+	if err != nil {
+		return err
 	}
 
-	visitor := NewPodAgentVisitor(
-		d.helper,
-		PodAgentVisitorOptions{Timeout: d.options.InjectTimeout},
-		command,
+	total, build, err := repeatFault(
+		func(duration time.Duration) PodVisitCommand {
+			return PodHTTPFaultCommand{
+				fault:    podFault,
+				duration: duration,
+				options:  options,
+			}
+		},
+		duration,
+		options.RepeatEvery,
+		options.RepeatFor,
 	)
-
-	targets, err := d.selector.Targets(ctx)
 	if err != nil {
 		return err
 	}
 
-	controller := NewPodController(targets)
+	dryRun := d.options.DryRun || options.DryRun
+	if !dryRun {
+		err = waitFaultStart(ctx, options.StartAfter, options.StartAt)
+		if err != nil {
+			return err
+		}
+	}
 
-	return controller.Visit(ctx, visitor)
+	return d.injectFault(ctx, total, build, dryRun)
 }
 
 func (d *serviceDisruptor) InjectGrpcFaults(
@@ -119,53 +343,463 @@ func (d *serviceDisruptor) InjectGrpcFaults(
 	podFault := fault
 	podFault.Port = port
 
-	command := PodGrpcFaultCommand{
-		fault:    fault,
-		duration: duration,
-		options:  options,
+	err = GrpcFaultSpec{Fault: podFault, Options: options}.Validate()
+	if err != nil {
+		return err
+	}
+
+	total, build, err := repeatFault(
+		func(duration time.Duration) PodVisitCommand {
+			return PodGrpcFaultCommand{
+				fault:    fault,
+				duration: duration,
+				options:  options,
+			}
+		},
+		duration,
+		options.RepeatEvery,
+		options.RepeatFor,
+	)
+	if err != nil {
+		return err
+	}
+
+	dryRun := d.options.DryRun || options.DryRun
+	if !dryRun {
+		err = waitFaultStart(ctx, options.StartAfter, options.StartAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.injectFault(ctx, total, build, dryRun)
+}
+
+func (d *serviceDisruptor) ComposeFaults(
+	ctx context.Context,
+	faults ComposedFaults,
+	duration time.Duration,
+) error {
+	podFaults, err := d.podFaults(faults)
+	if err != nil {
+		return err
+	}
+
+	err = podFaults.validate()
+	if err != nil {
+		return err
+	}
+
+	return d.injectFault(ctx, duration, func(duration time.Duration) PodVisitCommand {
+		return PodComposedFaultCommand{
+			faults:   podFaults,
+			duration: duration,
+		}
+	}, d.options.DryRun)
+}
+
+func (d *serviceDisruptor) InjectTimeline(ctx context.Context, timeline FaultTimeline) error {
+	podTimeline := FaultTimeline{}
+	for _, step := range timeline {
+		podFaults, err := d.podFaults(step.Faults)
+		if err != nil {
+			return err
+		}
+		step.Faults = podFaults
+		podTimeline = append(podTimeline, step)
+	}
+
+	err := podTimeline.validate()
+	if err != nil {
+		return err
+	}
+
+	// targets injected after the start of the timeline skip the steps already elapsed
+	return d.injectFault(ctx, podTimeline.duration(), func(duration time.Duration) PodVisitCommand {
+		return PodTimelineCommand{
+			timeline: podTimeline.remaining(duration),
+		}
+	}, d.options.DryRun)
+}
+
+func (d *serviceDisruptor) InjectChaos(ctx context.Context, spec ChaosSpec, duration time.Duration) error {
+	podFaults, err := d.podFaults(spec.Faults)
+	if err != nil {
+		return err
+	}
+	spec.Faults = podFaults
+
+	err = spec.validate()
+	if err != nil {
+		return err
 	}
 
+	if d.options.DryRun {
+		return d.dryRunChaos(ctx, spec)
+	}
+
+	return runChaos(ctx, spec, duration, d.sampler, d.injectChaosFault)
+}
+
+// dryRunChaos prints the commands for each of the faults of the chaos spec in the targets
+func (d *serviceDisruptor) dryRunChaos(ctx context.Context, spec ChaosSpec) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	return dryRunChaos(d.out, targets, spec)
+}
+
+// injectChaosFault injects the faults selected at random in a percentage of the targets
+func (d *serviceDisruptor) injectChaosFault(
+	ctx context.Context,
+	faults ComposedFaults,
+	percentage int,
+	duration time.Duration,
+) error {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	targets, err = checkHealth(d.out, targets, d.options.HealthCheck)
+	if err != nil {
+		return err
+	}
+
+	targets, err = d.sampler.percentage(targets, percentage)
+	if err != nil {
+		return err
+	}
+
+	err = d.checkLimits(ctx, targets)
+	if err != nil {
+		return err
+	}
+
+	release, err := d.lock.acquire(ctx, targets, duration)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	visitor := NewPodAgentVisitor(
 		d.helper,
-		PodAgentVisitorOptions{Timeout: d.options.InjectTimeout},
-		command,
+		d.agentOptions,
+		PodComposedFaultCommand{faults: faults, duration: duration},
 	)
 
-	targets, err := d.selector.Targets(ctx)
+	return NewPodController(targets, d.options.controllerOptions()).Visit(ctx, visitor)
+}
+
+// podFaults maps the service ports of the faults to target pod ports
+// podHTTPFault maps the service ports of the fault to the target ports in the pods
+func (d *serviceDisruptor) podHTTPFault(fault HTTPFault) (HTTPFault, error) {
+	var err error
+
+	if len(fault.Ports) > 0 {
+		fault.Ports, err = serviceTargetPorts(d.service, fault.Ports)
+		return fault, err
+	}
+
+	fault.Port, err = utils.GetTargetPort(d.service, fault.Port)
+	return fault, err
+}
+
+func (d *serviceDisruptor) podFaults(faults ComposedFaults) (ComposedFaults, error) {
+	var err error
+
+	podFaults := ComposedFaults{}
+	for _, spec := range faults.HTTP {
+		spec.Fault, err = d.podHTTPFault(spec.Fault)
+		if err != nil {
+			return ComposedFaults{}, err
+		}
+		podFaults.HTTP = append(podFaults.HTTP, spec)
+	}
+	for _, spec := range faults.Grpc {
+		spec.Fault.Port, err = utils.GetTargetPort(d.service, spec.Fault.Port)
+		if err != nil {
+			return ComposedFaults{}, err
+		}
+		podFaults.Grpc = append(podFaults.Grpc, spec)
+	}
+
+	return podFaults, nil
+}
+
+// injectFault executes the command returned by the build function in the agent of the targets for the duration
+// of the fault. With DynamicTargets, the pods that back the service during the fault are also injected.
+// With ReapplyOnRestart, the targets that restart during the fault are injected again. With ReinjectReplaced,
+// the pods that replace targets deleted during the fault are injected. With dryRun, the commands are printed
+// instead.
+func (d *serviceDisruptor) injectFault(
+	ctx context.Context,
+	duration time.Duration,
+	build func(time.Duration) PodVisitCommand,
+	dryRun bool,
+) error {
+	visitor := func(duration time.Duration) PodVisitor {
+		return NewPodAgentVisitor(
+			d.helper,
+			d.agentOptions,
+			build(duration),
+		)
+	}
+
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	targets, err = checkHealth(d.out, targets, d.options.HealthCheck)
+	if err != nil {
+		return err
+	}
+
+	err = d.checkLimits(ctx, targets)
 	if err != nil {
 		return err
 	}
 
-	controller := NewPodController(targets)
+	if dryRun {
+		return dryRunCommands(d.out, targets, build(duration))
+	}
+
+	release, err := d.lock.acquire(ctx, targets, duration)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if !d.options.DynamicTargets && !d.options.ReapplyOnRestart && !d.options.ReinjectReplaced {
+		return NewPodController(targets, d.options.controllerOptions()).Visit(ctx, visitor(duration))
+	}
+
+	// stop watching the changes of the pods when the visit ends
+	changesCtx, stopChanges := context.WithCancel(ctx)
+	defer stopChanges()
 
-	return controller.Visit(ctx, visitor)
+	controller := NewDynamicPodController(
+		targets,
+		d.selector.Targets,
+		DynamicPodControllerOptions{
+			MaxTargets:       d.limits.MaxPods,
+			IgnoreNewTargets: !d.options.DynamicTargets,
+			Reapply:          d.options.ReapplyOnRestart,
+			Replace:          d.options.ReinjectReplaced,
+			OnReplace:        d.recordReplacement,
+			Changes:          d.helper.Changes(changesCtx),
+		},
+	)
+
+	return controller.Visit(ctx, duration, visitor)
 }
 
-func (d *serviceDisruptor) Targets(ctx context.Context) ([]string, error) {
+// targets returns the pods backing the service that are disrupted. When only a percentage of them is disrupted, the
+// same pods are returned as long as they back the service.
+func (d *serviceDisruptor) targets(ctx context.Context) ([]corev1.Pod, error) {
 	targets, err := d.selector.Targets(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	if d.options.Percentage == 0 || d.options.Percentage == 100 {
+		return targets, nil
+	}
+
+	return d.subset.pick(d.sampler, targets, percentageSize(len(targets), d.options.Percentage)), nil
+}
+
+// checkLimits returns an error if disrupting the targets exceeds the safety limits of the disruptor
+func (d *serviceDisruptor) checkLimits(ctx context.Context, targets []corev1.Pod) error {
+	return d.limits.check(targets, func() ([]corev1.Pod, error) {
+		return d.selector.Targets(ctx)
+	})
+}
+
+// recordReplacement records the target replaced by a pod
+func (d *serviceDisruptor) recordReplacement(replaced corev1.Pod, replacement corev1.Pod) {
+	d.replacements[replacement.Name] = replaced.Name
+}
+
+func (d *serviceDisruptor) Targets(ctx context.Context) ([]string, error) {
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return utils.PodNames(targets), nil
 }
 
+// Stop stops the faults applied by the agents injected in the pods backing the service
+func (d *serviceDisruptor) Stop(ctx context.Context) error {
+	// all the pods backing the service are visited, as the targets of the faults may have been sampled
+	targets, err := d.selector.Targets(ctx)
+	if errors.Is(err, ErrServiceNoTargets) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return NewPodController(targets, d.options.controllerOptions()).Visit(ctx, PodAgentStopVisitor{helper: d.helper})
+}
+
+// Logs returns the logs of the agents injected in the pods backing the service
+func (d *serviceDisruptor) Logs(ctx context.Context) ([]TargetLogs, error) {
+	targets, err := d.selector.Targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]TargetLogs, len(targets))
+	for i, pod := range targets {
+		logs[i], err = podAgentLogs(ctx, d.helper, pod)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return logs, nil
+}
+
+// AccessLog returns the access log of the agents injected in the pods backing the service
+func (d *serviceDisruptor) AccessLog(ctx context.Context) ([]TargetAccessLog, error) {
+	targets, err := d.selector.Targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accessLogs := make([]TargetAccessLog, len(targets))
+	for i, pod := range targets {
+		accessLogs[i], err = podAgentAccessLog(ctx, d.helper, pod)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return accessLogs, nil
+}
+
+// Shutdown terminates the agents injected in the pods backing the service
+func (d *serviceDisruptor) Shutdown(ctx context.Context) error {
+	targets, err := d.selector.Targets(ctx)
+	if errors.Is(err, ErrServiceNoTargets) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	visitor := PodAgentShutdownVisitor{helper: d.helper}
+
+	return NewPodController(targets, d.options.controllerOptions()).Visit(ctx, visitor)
+}
+
+// Status returns the state of the faults applied by the agents injected in the pods backing the service
+func (d *serviceDisruptor) Status(ctx context.Context) ([]TargetStatus, error) {
+	targets, err := d.selector.Targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]TargetStatus, len(targets))
+	for i, pod := range targets {
+		status[i], err = podAgentStatus(ctx, d.helper, pod)
+		if err != nil {
+			return nil, err
+		}
+		status[i].Replaces = d.replacements[pod.Name]
+	}
+
+	return status, nil
+}
+
+// VerifyRecovery verifies the pods backing the service recovered from the faults
+func (d *serviceDisruptor) VerifyRecovery(ctx context.Context, timeout time.Duration) ([]TargetRecovery, error) {
+	return verifyRecovery(ctx, timeout, func(ctx context.Context) ([]TargetRecovery, error) {
+		targets, err := d.selector.Targets(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		recovery := make([]TargetRecovery, len(targets))
+		for i, pod := range targets {
+			recovery[i], err = podRecovery(ctx, d.helper, pod)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return recovery, nil
+	})
+}
+
+// InjectContainerFault applies the container fault to a subset of the target pods of the disruptor
+func (d *serviceDisruptor) InjectContainerFault(
+	ctx context.Context,
+	fault ContainerFault,
+	duration time.Duration,
+) error {
+	err := fault.validate(duration)
+	if err != nil {
+		return err
+	}
+
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	targets, err = d.sampler.sample(targets, fault.Count)
+	if err != nil {
+		return err
+	}
+
+	err = d.checkLimits(ctx, targets)
+	if err != nil {
+		return err
+	}
+
+	if d.options.DryRun {
+		return dryRunContainerFault(d.out, targets, fault, duration)
+	}
+
+	options := NodeAgentVisitorOptions{
+		Timeout:   d.options.InjectTimeout,
+		AgentArgs: d.agentOptions.AgentArgs,
+	}
+
+	return injectContainerFault(ctx, d.k8s.PodHelper(fault.agentNamespace()), options, targets, fault, duration)
+}
+
 // TerminatePods terminates a subset of the target pods of the disruptor
 func (d *serviceDisruptor) TerminatePods(
 	ctx context.Context,
 	fault PodTerminationFault,
 ) ([]string, error) {
-	targets, err := d.selector.Targets(ctx)
+	targets, err := d.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, err = d.sampler.sample(targets, fault.Count)
 	if err != nil {
 		return nil, err
 	}
 
-	targets, err = utils.Sample(targets, fault.Count)
+	err = d.checkLimits(ctx, targets)
 	if err != nil {
 		return nil, err
 	}
 
-	controller := NewPodController(targets)
+	if d.options.DryRun {
+		return utils.PodNames(targets), dryRunTermination(d.out, targets)
+	}
+
+	controller := NewPodController(targets, d.options.controllerOptions())
 
 	visitor := PodTerminationVisitor{helper: d.helper, timeout: fault.Timeout}
 
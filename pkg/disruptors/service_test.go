@@ -60,6 +60,60 @@ func Test_NewServiceDisruptor(t *testing.T) {
 			options:     ServiceDisruptorOptions{},
 			expectError: true,
 		},
+		{
+			title:     "invalid percentage",
+			name:      "test-svc",
+			namespace: "test-ns",
+			service: builders.NewServiceBuilder("test-svc").
+				WithNamespace("test-ns").
+				WithSelectorLabel("app", "test").
+				WithPort("http", 80, intstr.FromInt(80)).
+				BuildAsPtr(),
+			options: ServiceDisruptorOptions{
+				Percentage: 120,
+			},
+			expectError: true,
+		},
+		{
+			title:     "protected namespace",
+			name:      "kube-dns",
+			namespace: "kube-system",
+			service: builders.NewServiceBuilder("kube-dns").
+				WithNamespace("kube-system").
+				WithSelectorLabel("app", "dns").
+				WithPort("dns", 53, intstr.FromInt(53)).
+				BuildAsPtr(),
+			options:     ServiceDisruptorOptions{},
+			expectError: true,
+		},
+		{
+			title:     "protected namespace allowed",
+			name:      "kube-dns",
+			namespace: "kube-system",
+			service: builders.NewServiceBuilder("kube-dns").
+				WithNamespace("kube-system").
+				WithSelectorLabel("app", "dns").
+				WithPort("dns", 53, intstr.FromInt(53)).
+				BuildAsPtr(),
+			options: ServiceDisruptorOptions{
+				AllowProtectedNamespaces: true,
+			},
+			expectError: false,
+		},
+		{
+			title:     "additional protected namespace",
+			name:      "test-svc",
+			namespace: "test-ns",
+			service: builders.NewServiceBuilder("test-svc").
+				WithNamespace("test-ns").
+				WithSelectorLabel("app", "test").
+				WithPort("http", 80, intstr.FromInt(80)).
+				BuildAsPtr(),
+			options: ServiceDisruptorOptions{
+				ProtectedNamespaces: []string{"test-ns"},
+			},
+			expectError: true,
+		},
 		{
 			title:     "empty namespace",
 			name:      "test-svc",
@@ -0,0 +1,83 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// nodeAgentShutdownTimeout is the time to wait for the agent pod to be deleted after shutting down the agent
+const nodeAgentShutdownTimeout = 30 * time.Second
+
+// agentTerminated returns if the agent injected in the pod has terminated
+func agentTerminated(pod corev1.Pod) bool {
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.Name == "xk6-agent" && status.State.Terminated != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PodAgentShutdownVisitor implements PodVisitor, terminating the agent injected in the Pod, if any
+type PodAgentShutdownVisitor struct {
+	helper helpers.PodHelper
+}
+
+// Visit terminates the agent in the pod
+func (c PodAgentShutdownVisitor) Visit(ctx context.Context, pod corev1.Pod) error {
+	if !hasAgent(pod) || agentTerminated(pod) {
+		return nil
+	}
+
+	// sidecars are restarted if terminated, therefore the faults are only stopped and their resources removed
+	command := buildShutdownCmd()
+	if hasSidecarAgent(pod) {
+		command = buildCleanupCmd()
+	}
+
+	_, stderr, err := c.helper.Exec(ctx, pod.Name, "xk6-agent", command, []byte{})
+	if err != nil {
+		return fmt.Errorf("shutting down agent in pod %q: %w \n%s", pod.Name, err, string(stderr))
+	}
+
+	return nil
+}
+
+// NodeAgentShutdownVisitor implements NodeVisitor, terminating the agent running in the Node, if any, and deleting
+// its pod
+type NodeAgentShutdownVisitor struct {
+	helper helpers.PodHelper
+}
+
+// Visit terminates the agent in the node
+func (c NodeAgentShutdownVisitor) Visit(ctx context.Context, node corev1.Node) error {
+	agents, err := c.helper.List(ctx, helpers.PodFilter{Select: map[string]string{NodeAgentLabel: node.Name}})
+	if err != nil {
+		return fmt.Errorf("listing agent in node %q: %w", node.Name, err)
+	}
+
+	if len(agents) == 0 {
+		return nil
+	}
+
+	agentPod := nodeAgentPodName(node)
+	if agents[0].Status.Phase == corev1.PodRunning {
+		_, stderr, execErr := c.helper.Exec(ctx, agentPod, "xk6-agent", buildShutdownCmd(), []byte{})
+		if execErr != nil {
+			return fmt.Errorf("shutting down agent in node %q: %w \n%s", node.Name, execErr, string(stderr))
+		}
+	}
+
+	err = c.helper.Terminate(ctx, agentPod, nodeAgentShutdownTimeout)
+	if err != nil {
+		return fmt.Errorf("deleting agent pod in node %q: %w", node.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_PodAgentShutdownVisitor(t *testing.T) {
+	t.Parallel()
+
+	agent := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name: "xk6-agent",
+		},
+	}
+
+	testCases := []struct {
+		title       string
+		injected    bool
+		sidecar     bool
+		state       corev1.ContainerState
+		err         error
+		expectError bool
+		expected    []helpers.Command
+	}{
+		{
+			title:       "agent not injected",
+			injected:    false,
+			expectError: false,
+			expected:    nil,
+		},
+		{
+			title:       "agent running",
+			injected:    true,
+			state:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			expectError: false,
+			expected: []helpers.Command{
+				{Pod: "pod1", Container: "xk6-agent", Namespace: "test-ns", Command: buildShutdownCmd(), Stdin: []byte{}},
+			},
+		},
+		{
+			title:       "agent terminated",
+			injected:    true,
+			state:       corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+			expectError: false,
+			expected:    nil,
+		},
+		{
+			title:       "sidecar agent",
+			sidecar:     true,
+			expectError: false,
+			expected: []helpers.Command{
+				{Pod: "pod1", Container: "xk6-agent", Namespace: "test-ns", Command: buildCleanupCmd(), Stdin: []byte{}},
+			},
+		},
+		{
+			title:       "shutdown fails",
+			injected:    true,
+			state:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			err:         errFailed,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build()
+			if tc.sidecar {
+				pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "xk6-agent"})
+			}
+			if tc.injected {
+				pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{agent}
+				pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{{Name: "xk6-agent", State: tc.state}}
+			}
+
+			client := fake.NewSimpleClientset(&pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			executor.SetResult(nil, nil, tc.err)
+			visitor := PodAgentShutdownVisitor{helper: helpers.NewPodHelper(client, executor, "test-ns")}
+
+			err := visitor.Visit(context.TODO(), pod)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expected, executor.GetHistory()); diff != "" {
+				t.Fatalf("expected commands do not match executed\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package disruptors
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// InjectionEphemeral injects the agent in the targets as an ephemeral container when the faults are applied
+	InjectionEphemeral = "ephemeral"
+	// InjectionSidecar uses the agent added as a sidecar container to the targets when they were created, for
+	// example, by the webhook command of the agent. The targets are not modified when the faults are applied.
+	InjectionSidecar = "sidecar"
+)
+
+// validateInjectionMode returns an error if the injection mode is not valid. An empty value is the default mode.
+func validateInjectionMode(mode string) error {
+	switch mode {
+	case "", InjectionEphemeral, InjectionSidecar:
+		return nil
+	default:
+		return fmt.Errorf("invalid injectionMode %q: must be %s or %s", mode, InjectionEphemeral, InjectionSidecar)
+	}
+}
+
+// hasSidecarAgent returns if the pod runs the agent as a sidecar container
+func hasSidecarAgent(pod corev1.Pod) bool {
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		if container.Name == "xk6-agent" {
+			return true
+		}
+	}
+
+	return false
+}
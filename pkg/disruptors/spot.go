@@ -0,0 +1,138 @@
+package disruptors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/cloud"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// SpotInterruptionTaintKey is the key of the taint applied to the nodes when the interruption is notified
+const SpotInterruptionTaintKey = "xk6-disruptor/spot-interruption"
+
+// SpotInterruptionFault specifies a fault that emulates the interruption of spot instances.
+// When the interruption is notified, the node is cordoned and tainted and optionally drained. After the notice
+// period, the pods still running in the node are deleted without waiting for their termination, as it happens
+// when the instance is reclaimed. After the duration of the fault, the node is restored.
+type SpotInterruptionFault struct {
+	// Provider whose interruption timeline is emulated: "aws" (default), "gcp" or "azure"
+	Provider string
+	// NoticePeriod overrides the time between the interruption notice and the termination of the instance
+	NoticePeriod time.Duration `js:"noticePeriod"`
+	// Drain indicates if the pods must be evicted during the notice period, as termination handlers do
+	Drain bool
+}
+
+// noticePeriod returns the notice period of the interruption
+func (f SpotInterruptionFault) noticePeriod() (time.Duration, error) {
+	if f.NoticePeriod > 0 {
+		return f.NoticePeriod, nil
+	}
+
+	provider := f.Provider
+	if provider == "" {
+		provider = cloud.AWS
+	}
+
+	// interruption notice period of each cloud provider
+	switch provider {
+	case cloud.AWS:
+		return 2 * time.Minute, nil
+	case cloud.GCP, cloud.Azure:
+		return 30 * time.Second, nil
+	default:
+		return 0, fmt.Errorf("unsupported cloud provider %q", f.Provider)
+	}
+}
+
+// SpotInterruptionVisitor defines a Visitor that emulates the interruption of its target node
+type SpotInterruptionVisitor struct {
+	helper       helpers.NodeHelper
+	noticePeriod time.Duration
+	drain        bool
+	duration     time.Duration
+}
+
+// Visit notifies the interruption of the node, terminates its pods after the notice period, and restores it
+// after the fault duration
+func (c SpotInterruptionVisitor) Visit(ctx context.Context, node corev1.Node) error {
+	terminated := time.After(c.noticePeriod)
+	expired := time.After(c.noticePeriod + c.duration)
+
+	err := c.helper.Cordon(ctx, node.Name)
+	if err != nil {
+		return err
+	}
+
+	// nodes that were unschedulable before the fault are left as they were
+	if !node.Spec.Unschedulable {
+		defer func() {
+			// we use a fresh context because the context of the visit may have been cancelled
+			//nolint:contextcheck
+			_ = c.helper.Uncordon(context.TODO(), node.Name)
+		}()
+	}
+
+	taint := corev1.Taint{Key: SpotInterruptionTaintKey, Effect: corev1.TaintEffectNoSchedule}
+	err = c.helper.AddTaint(ctx, node.Name, taint)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		//nolint:contextcheck
+		_ = c.helper.RemoveTaint(context.TODO(), node.Name, taint)
+	}()
+
+	if c.drain {
+		err = c.drainNode(ctx, node.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	select {
+	case <-terminated:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	err = c.helper.DeletePods(ctx, node.Name)
+	if err != nil {
+		return fmt.Errorf("terminating pods in node %q: %w", node.Name, err)
+	}
+
+	select {
+	case <-expired:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// drainNode evicts the pods of the node during the notice period. The pods that are not evicted before the end of the
+// notice period are not an error, as they are terminated with the instance.
+func (c SpotInterruptionVisitor) drainNode(ctx context.Context, name string) error {
+	drainCtx, cancel := context.WithTimeout(ctx, c.noticePeriod)
+	defer cancel()
+
+	err := c.helper.Drain(drainCtx, name, helpers.DrainOptions{Timeout: c.noticePeriod})
+	if err == nil {
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if errors.Is(err, utils.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		return nil
+	}
+
+	return fmt.Errorf("draining node %q: %w", name, err)
+}
@@ -0,0 +1,97 @@
+package disruptors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// FaultState defines the state of a fault in a target
+type FaultState string
+
+const (
+	// FaultPending indicates the fault has not been applied to the target yet
+	FaultPending FaultState = "pending"
+	// FaultActive indicates the fault is in effect in the target
+	FaultActive FaultState = "active"
+	// FaultFinished indicates the fault completed or was stopped
+	FaultFinished FaultState = "finished"
+	// FaultFailed indicates the fault terminated with an error
+	FaultFailed FaultState = "failed"
+)
+
+// TargetStatus describes the state of the last fault applied to a target by the disruptor agent
+type TargetStatus struct {
+	// Target is the name of the target
+	Target string
+	// State of the fault
+	State FaultState
+	// Remaining is the remaining duration of an active fault
+	Remaining time.Duration
+	// Error reported by a failed fault
+	Error string
+	// Reapplied is the number of times the fault was re-applied after the restart of the target
+	Reapplied int
+	// Replaces is the name of the deleted target replaced by this target
+	Replaces string
+}
+
+// agentStatus is the status reported by the agent's status command
+type agentStatus struct {
+	State     string        `json:"state"`
+	Remaining time.Duration `json:"remaining"`
+	Error     string        `json:"error"`
+	Reapplied int           `json:"reapplied"`
+}
+
+// execStatusCmd returns the status of the fault reported by the agent running in the given pod
+func execStatusCmd(ctx context.Context, helper helpers.PodHelper, target string, pod string) (TargetStatus, error) {
+	stdout, stderr, err := helper.Exec(ctx, pod, "xk6-agent", buildStatusCmd(), []byte{})
+	if err != nil {
+		return TargetStatus{}, fmt.Errorf("getting fault status in %q: %w \n%s", target, err, string(stderr))
+	}
+
+	status := agentStatus{}
+	err = json.Unmarshal(stdout, &status)
+	if err != nil {
+		return TargetStatus{}, fmt.Errorf("decoding fault status in %q: %w", target, err)
+	}
+
+	return TargetStatus{
+		Target:    target,
+		State:     FaultState(status.State),
+		Remaining: status.Remaining,
+		Error:     status.Error,
+		Reapplied: status.Reapplied,
+	}, nil
+}
+
+// podAgentStatus returns the status of the fault applied by the agent injected in the pod.
+// If the agent was not injected, the fault is pending.
+func podAgentStatus(ctx context.Context, helper helpers.PodHelper, pod corev1.Pod) (TargetStatus, error) {
+	if !hasAgent(pod) {
+		return TargetStatus{Target: pod.Name, State: FaultPending}, nil
+	}
+
+	return execStatusCmd(ctx, helper, pod.Name, pod.Name)
+}
+
+// nodeAgentStatus returns the status of the fault applied by the agent running in the node.
+// If the agent is not running, the fault is pending.
+func nodeAgentStatus(ctx context.Context, helper helpers.PodHelper, node corev1.Node) (TargetStatus, error) {
+	agents, err := helper.List(ctx, helpers.PodFilter{Select: map[string]string{NodeAgentLabel: node.Name}})
+	if err != nil {
+		return TargetStatus{}, fmt.Errorf("listing agent in node %q: %w", node.Name, err)
+	}
+
+	if len(agents) == 0 {
+		return TargetStatus{Target: node.Name, State: FaultPending}, nil
+	}
+
+	return execStatusCmd(ctx, helper, node.Name, nodeAgentPodName(node))
+}
@@ -0,0 +1,110 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_PodAgentStatus(t *testing.T) {
+	t.Parallel()
+
+	agent := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name: "xk6-agent",
+		},
+	}
+
+	testCases := []struct {
+		title       string
+		pod         corev1.Pod
+		stdout      []byte
+		expectError bool
+		expected    TargetStatus
+	}{
+		{
+			title:    "agent not injected",
+			pod:      builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build(),
+			expected: TargetStatus{Target: "pod1", State: FaultPending},
+		},
+		{
+			title:  "active fault",
+			pod:    builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build(),
+			stdout: []byte(`{"state":"active","duration":30000000000,"remaining":10000000000}`),
+			expected: TargetStatus{
+				Target:    "pod1",
+				State:     FaultActive,
+				Remaining: 10 * time.Second,
+			},
+		},
+		{
+			title:  "reapplied fault",
+			pod:    builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build(),
+			stdout: []byte(`{"state":"active","duration":30000000000,"remaining":10000000000,"reapplied":1}`),
+			expected: TargetStatus{
+				Target:    "pod1",
+				State:     FaultActive,
+				Remaining: 10 * time.Second,
+				Reapplied: 1,
+			},
+		},
+		{
+			title:  "failed fault",
+			pod:    builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build(),
+			stdout: []byte(`{"state":"failed","error":"received signal \"interrupt\""}`),
+			expected: TargetStatus{
+				Target: "pod1",
+				State:  FaultFailed,
+				Error:  `received signal "interrupt"`,
+			},
+		},
+		{
+			title:       "invalid status",
+			pod:         builders.NewPodBuilder("pod1").WithNamespace("test-ns").Build(),
+			stdout:      []byte("not a status"),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := tc.pod
+			if tc.stdout != nil {
+				pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, agent)
+			}
+
+			client := fake.NewSimpleClientset(&pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			executor.SetResult(tc.stdout, nil, nil)
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+
+			status, err := podAgentStatus(context.TODO(), helper, pod)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed unexpectedly: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if diff := cmp.Diff(tc.expected, status); diff != "" {
+				t.Errorf("expected status does not match returned:\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package disruptors
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultTaintKey is the key of the taint applied by the NodeTaintFault if none is specified
+const DefaultTaintKey = "xk6-disruptor/fault"
+
+// NodeTaintFault specifies a fault that applies a taint to a set of nodes
+type NodeTaintFault struct {
+	// Key of the taint. Defaults to "xk6-disruptor/fault"
+	Key string
+	// Value of the taint
+	Value string
+	// Effect of the taint: "NoSchedule", "PreferNoSchedule" or "NoExecute". Defaults to "NoSchedule"
+	Effect string
+}
+
+// taint returns the taint defined by the fault
+func (f NodeTaintFault) taint() (corev1.Taint, error) {
+	taint := corev1.Taint{
+		Key:    f.Key,
+		Value:  f.Value,
+		Effect: corev1.TaintEffect(f.Effect),
+	}
+
+	if taint.Key == "" {
+		taint.Key = DefaultTaintKey
+	}
+
+	switch taint.Effect {
+	case "":
+		taint.Effect = corev1.TaintEffectNoSchedule
+	case corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		return corev1.Taint{}, fmt.Errorf("invalid taint effect %q", f.Effect)
+	}
+
+	return taint, nil
+}
+
+// hasTaint returns if the node has a taint with the same key and effect
+func hasTaint(node corev1.Node, taint corev1.Taint) bool {
+	for _, t := range node.Spec.Taints {
+		if t.MatchTaint(&taint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NodeTaintVisitor defines a Visitor that taints its target node for the duration of the fault
+type NodeTaintVisitor struct {
+	helper   helpers.NodeHelper
+	taint    corev1.Taint
+	duration time.Duration
+}
+
+// Visit adds the taint to the node and removes it after the fault duration
+func (c NodeTaintVisitor) Visit(ctx context.Context, node corev1.Node) error {
+	expired := time.After(c.duration)
+
+	// taints that existed before the fault are left as they were
+	tainted := hasTaint(node, c.taint)
+
+	err := c.helper.AddTaint(ctx, node.Name, c.taint)
+	if err != nil {
+		return err
+	}
+
+	if !tainted {
+		defer func() {
+			// we use a fresh context because the context of the visit may have been cancelled
+			//nolint:contextcheck
+			_ = c.helper.RemoveTaint(context.TODO(), node.Name, c.taint)
+		}()
+	}
+
+	select {
+	case <-expired:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -28,6 +28,9 @@ type PodFaultInjector interface {
 	// Terminates a set of pods. Returns the list of pods affected. If any of the target pods
 	// is not terminated after the timeout defined in the TerminatePodsFault, an error is returned
 	TerminatePods(context.Context, PodTerminationFault) ([]string, error)
+	// InjectContainerFault kills, restarts or pauses a container of a set of pods using the container runtime of
+	// their nodes. Paused containers are resumed after the given duration.
+	InjectContainerFault(context.Context, ContainerFault, time.Duration) error
 }
 
 // PodTerminationFault specifies a fault that will terminate a set of pods
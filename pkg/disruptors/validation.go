@@ -0,0 +1,223 @@
+package disruptors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+)
+
+// maxPort is the highest valid port number
+const maxPort = 65535
+
+// maxHTTPStatusCode is the highest valid http status code
+const maxHTTPStatusCode = 599
+
+// maxGrpcStatusCode is the highest grpc status code (Unauthenticated)
+const maxGrpcStatusCode = 16
+
+// FaultValidationError reports an invalid value of a field of a fault
+type FaultValidationError struct {
+	// Field is the name of the invalid field as used in the test scripts (e.g. "errorRate")
+	Field string
+	// Value is the invalid value
+	Value any
+	// Reason describes the valid values of the field
+	Reason string
+}
+
+func (e *FaultValidationError) Error() string {
+	return fmt.Sprintf("invalid %s %v: %s", e.Field, e.Value, e.Reason)
+}
+
+// faultErrors accumulates the validation errors of a fault
+type faultErrors []error
+
+func (e *faultErrors) add(field string, value any, reason string) {
+	*e = append(*e, &FaultValidationError{Field: field, Value: value, Reason: reason})
+}
+
+// err returns all the validation errors joined, or nil if there are none
+func (e faultErrors) err() error {
+	return errors.Join(e...)
+}
+
+// validatePort checks a port is either a number in the valid range or a name. A null or zero port uses the
+// default port.
+func (e *faultErrors) validatePort(field string, port intstr.IntOrString) {
+	number, err := strconv.Atoi(port.Str())
+	if err != nil {
+		return
+	}
+
+	if number < 0 || number > maxPort {
+		e.add(field, port.Str(), fmt.Sprintf("must be between 1 and %d", maxPort))
+	}
+}
+
+// validatePorts checks each port is either a number in the valid range, a name or a range of numbers in the valid
+// range. The ports cannot be combined with a port.
+func (e *faultErrors) validatePorts(port intstr.IntOrString, ports []intstr.IntOrString) {
+	if len(ports) == 0 {
+		return
+	}
+
+	if !port.IsNull() && !port.IsZero() {
+		e.add("ports", ports, "cannot be combined with port")
+	}
+
+	for _, p := range ports {
+		from, to, isRange := portRange(p)
+		if !isRange {
+			e.validatePort("ports", p)
+			continue
+		}
+
+		if from < 1 || to > maxPort || from > to {
+			e.add("ports", p.Str(), fmt.Sprintf("must be a range of ports between 1 and %d", maxPort))
+		}
+	}
+}
+
+// validateDelay checks the delay and its variation. The variation cannot exceed the delay.
+func (e *faultErrors) validateDelay(averageDelay time.Duration, delayVariation time.Duration) {
+	if averageDelay < 0 {
+		e.add("averageDelay", averageDelay, "cannot be negative")
+	}
+
+	if delayVariation < 0 {
+		e.add("delayVariation", delayVariation, "cannot be negative")
+	}
+
+	if averageDelay >= 0 && delayVariation > averageDelay {
+		e.add("delayVariation", delayVariation, fmt.Sprintf("cannot exceed averageDelay %s", averageDelay))
+	}
+}
+
+// validateOptions checks the options common to the protocol faults
+func (e *faultErrors) validateOptions(
+	port intstr.IntOrString,
+	proxyPort uint,
+	startAfter time.Duration,
+	startAt time.Time,
+) {
+	if proxyPort > maxPort {
+		e.add("proxyPort", proxyPort, fmt.Sprintf("must be between 1 and %d", maxPort))
+	}
+
+	// the proxy cannot listen in the port it redirects traffic from
+	if proxyPort != 0 && port.Str() == strconv.FormatUint(uint64(proxyPort), 10) {
+		e.add("proxyPort", proxyPort, "cannot be the port the fault is injected in")
+	}
+
+	if startAfter < 0 {
+		e.add("startAfter", startAfter, "cannot be negative")
+	}
+
+	if startAfter > 0 && !startAt.IsZero() {
+		e.add("startAt", startAt.Format(time.RFC3339), "cannot be combined with startAfter")
+	}
+}
+
+// validateTLS checks the TLS mode and the files it requires. Errors cannot be injected in TLS traffic that is not
+// decrypted.
+func (e *faultErrors) validateTLS(options TLSOptions, errorRate float32) {
+	switch options.Mode {
+	case "":
+		return
+	case TLSPassthrough:
+		if errorRate > 0 {
+			e.add("errorRate", errorRate, "cannot be injected with tls mode "+TLSPassthrough)
+		}
+		return
+	case TLSTerminate, TLSMutual:
+	default:
+		e.add(
+			"tls.mode",
+			options.Mode,
+			fmt.Sprintf("must be one of %s, %s or %s", TLSPassthrough, TLSTerminate, TLSMutual),
+		)
+		return
+	}
+
+	if options.Cert == "" || options.Key == "" {
+		e.add("tls.cert", options.Cert, "a certificate and its key are required by tls mode "+options.Mode)
+	}
+
+	if options.Mode == TLSMutual && options.CA == "" {
+		e.add("tls.ca", options.CA, "is required by tls mode "+TLSMutual)
+	}
+}
+
+// Validate checks the values of the fault. Each invalid value is reported as a FaultValidationError.
+func (f HTTPFault) Validate() error {
+	errs := faultErrors{}
+	errs.validatePort("port", f.Port)
+	errs.validatePorts(f.Port, f.Ports)
+	errs.validateDelay(f.AverageDelay, f.DelayVariation)
+
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		errs.add("errorRate", f.ErrorRate, "must be in the range [0.0, 1.0]")
+	}
+
+	// the error code is only used when an error rate is defined
+	if f.ErrorRate > 0 && (f.ErrorCode < http.StatusContinue || f.ErrorCode > maxHTTPStatusCode) {
+		errs.add("errorCode", f.ErrorCode, "must be a valid http status code")
+	}
+
+	if f.RampDuration < 0 {
+		errs.add("rampDuration", f.RampDuration, "cannot be negative")
+	}
+
+	return errs.err()
+}
+
+// Validate checks the values of the fault. Each invalid value is reported as a FaultValidationError.
+func (f GrpcFault) Validate() error {
+	errs := faultErrors{}
+	errs.validatePort("port", f.Port)
+	errs.validateDelay(f.AverageDelay, f.DelayVariation)
+
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		errs.add("errorRate", f.ErrorRate, "must be in the range [0.0, 1.0]")
+	}
+
+	// the status code is only used when an error rate is defined. Code 0 (OK) is not an error.
+	if f.ErrorRate > 0 && (f.StatusCode < 1 || f.StatusCode > maxGrpcStatusCode) {
+		errs.add("statusCode", f.StatusCode, fmt.Sprintf("must be a grpc error code between 1 and %d", maxGrpcStatusCode))
+	}
+
+	if f.RampDuration < 0 {
+		errs.add("rampDuration", f.RampDuration, "cannot be negative")
+	}
+
+	return errs.err()
+}
+
+// Validate checks the values of the fault and their consistency with the options for injecting it
+func (s HTTPFaultSpec) Validate() error {
+	errs := faultErrors{}
+	errs.validateOptions(s.Fault.Port, s.Options.ProxyPort, s.Options.StartAfter, s.Options.StartAt)
+	errs.validateTLS(s.Options.TLS, s.Fault.ErrorRate)
+
+	if s.Options.UpstreamDialTimeout < 0 {
+		errs.add("upstreamDialTimeout", s.Options.UpstreamDialTimeout, "cannot be negative")
+	}
+
+	if s.Options.UpstreamResponseTimeout < 0 {
+		errs.add("upstreamResponseTimeout", s.Options.UpstreamResponseTimeout, "cannot be negative")
+	}
+
+	return errors.Join(s.Fault.Validate(), errs.err())
+}
+
+// Validate checks the values of the fault and their consistency with the options for injecting it
+func (s GrpcFaultSpec) Validate() error {
+	errs := faultErrors{}
+	errs.validateOptions(s.Fault.Port, s.Options.ProxyPort, s.Options.StartAfter, s.Options.StartAt)
+
+	return errors.Join(s.Fault.Validate(), errs.err())
+}
@@ -0,0 +1,221 @@
+package disruptors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
+)
+
+// invalidFields returns the fields reported as invalid in the errors joined in err
+func invalidFields(err error) []string {
+	fields := []string{}
+	switch e := err.(type) { //nolint:errorlint
+	case *FaultValidationError:
+		fields = append(fields, e.Field)
+	case interface{ Unwrap() []error }:
+		for _, joined := range e.Unwrap() {
+			fields = append(fields, invalidFields(joined)...)
+		}
+	}
+
+	return fields
+}
+
+func Test_HTTPFaultSpecValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		spec     HTTPFaultSpec
+		expected []string
+	}{
+		{
+			title: "valid fault",
+			spec: HTTPFaultSpec{
+				Fault: HTTPFault{
+					Port:           intstr.FromInt32(80),
+					AverageDelay:   100 * time.Millisecond,
+					DelayVariation: 10 * time.Millisecond,
+					ErrorRate:      0.1,
+					ErrorCode:      500,
+				},
+				Options: HTTPDisruptionOptions{ProxyPort: 8080},
+			},
+			expected: []string{},
+		},
+		{
+			title: "named port",
+			spec: HTTPFaultSpec{
+				Fault: HTTPFault{Port: intstr.FromString("http")},
+			},
+			expected: []string{},
+		},
+		{
+			title: "multiple ports",
+			spec: HTTPFaultSpec{
+				Fault: HTTPFault{Ports: []intstr.IntOrString{"80", "admin", "9000-9010"}},
+			},
+			expected: []string{},
+		},
+		{
+			title: "invalid ports",
+			spec: HTTPFaultSpec{
+				Fault: HTTPFault{
+					Port:  intstr.FromInt32(80),
+					Ports: []intstr.IntOrString{"70000", "9010-9000", "0-80"},
+				},
+			},
+			expected: []string{"ports", "ports", "ports", "ports"},
+		},
+		{
+			title: "error code ignored without error rate",
+			spec: HTTPFaultSpec{
+				Fault: HTTPFault{ErrorCode: 1000},
+			},
+			expected: []string{},
+		},
+		{
+			title: "invalid ranges",
+			spec: HTTPFaultSpec{
+				Fault: HTTPFault{
+					Port:      intstr.FromInt32(70000),
+					ErrorRate: 1.5,
+					ErrorCode: 1000,
+				},
+			},
+			expected: []string{"port", "errorRate", "errorCode"},
+		},
+		{
+			title: "variation exceeds delay",
+			spec: HTTPFaultSpec{
+				Fault: HTTPFault{
+					AverageDelay:   10 * time.Millisecond,
+					DelayVariation: 100 * time.Millisecond,
+				},
+			},
+			expected: []string{"delayVariation"},
+		},
+		{
+			title: "missing error code",
+			spec: HTTPFaultSpec{
+				Fault: HTTPFault{ErrorRate: 0.1},
+			},
+			expected: []string{"errorCode"},
+		},
+		{
+			title: "proxy port is the target port",
+			spec: HTTPFaultSpec{
+				Fault:   HTTPFault{Port: intstr.FromInt32(8000)},
+				Options: HTTPDisruptionOptions{ProxyPort: 8000},
+			},
+			expected: []string{"proxyPort"},
+		},
+		{
+			title: "start after combined with start at",
+			spec: HTTPFaultSpec{
+				Options: HTTPDisruptionOptions{StartAfter: time.Second, StartAt: time.Now()},
+			},
+			expected: []string{"startAt"},
+		},
+		{
+			title: "tls termination",
+			spec: HTTPFaultSpec{
+				Fault: HTTPFault{ErrorRate: 0.1, ErrorCode: 500},
+				Options: HTTPDisruptionOptions{
+					TLS: TLSOptions{Mode: TLSTerminate, Cert: "/certs/tls.crt", Key: "/certs/tls.key"},
+				},
+			},
+			expected: []string{},
+		},
+		{
+			title: "errors with tls passthrough",
+			spec: HTTPFaultSpec{
+				Fault:   HTTPFault{ErrorRate: 0.1, ErrorCode: 500},
+				Options: HTTPDisruptionOptions{TLS: TLSOptions{Mode: TLSPassthrough}},
+			},
+			expected: []string{"errorRate"},
+		},
+		{
+			title: "mtls without certificates",
+			spec: HTTPFaultSpec{
+				Options: HTTPDisruptionOptions{TLS: TLSOptions{Mode: TLSMutual}},
+			},
+			expected: []string{"tls.cert", "tls.ca"},
+		},
+		{
+			title: "invalid tls mode",
+			spec: HTTPFaultSpec{
+				Options: HTTPDisruptionOptions{TLS: TLSOptions{Mode: "other"}},
+			},
+			expected: []string{"tls.mode"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.spec.Validate()
+			if diff := cmp.Diff(tc.expected, invalidFields(err)); diff != "" {
+				t.Fatalf("expected invalid fields do not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_GrpcFaultSpecValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		spec     GrpcFaultSpec
+		expected []string
+	}{
+		{
+			title: "valid fault",
+			spec: GrpcFaultSpec{
+				Fault: GrpcFault{
+					Port:       intstr.FromInt32(9000),
+					ErrorRate:  0.1,
+					StatusCode: 14,
+				},
+			},
+			expected: []string{},
+		},
+		{
+			title: "ok status code",
+			spec: GrpcFaultSpec{
+				Fault: GrpcFault{ErrorRate: 0.1, StatusCode: 0},
+			},
+			expected: []string{"statusCode"},
+		},
+		{
+			title: "invalid ranges",
+			spec: GrpcFaultSpec{
+				Fault: GrpcFault{
+					AverageDelay: -time.Second,
+					RampDuration: -time.Second,
+				},
+				Options: GrpcDisruptionOptions{ProxyPort: 70000, StartAfter: -time.Second},
+			},
+			expected: []string{"averageDelay", "rampDuration", "proxyPort", "startAfter"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.spec.Validate()
+			if diff := cmp.Diff(tc.expected, invalidFields(err)); diff != "" {
+				t.Fatalf("expected invalid fields do not match returned\n%s", diff)
+			}
+		})
+	}
+}
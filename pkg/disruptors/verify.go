@@ -0,0 +1,122 @@
+package disruptors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultRecoveryTimeout is the default time to wait for the targets to recover from the faults
+const DefaultRecoveryTimeout = 30 * time.Second
+
+// recoveryCheckInterval is the interval between checks of the recovery of the targets
+const recoveryCheckInterval = time.Second
+
+// RecoveryVerifier defines the method for verifying the targets recovered from the faults
+type RecoveryVerifier interface {
+	// VerifyRecovery waits up to the timeout for the faults to be removed from the targets and for the
+	// targets to be Ready, and returns the recovery of each target
+	VerifyRecovery(ctx context.Context, timeout time.Duration) ([]TargetRecovery, error)
+}
+
+// TargetRecovery describes if a target recovered from the faults applied by the disruptor agent
+type TargetRecovery struct {
+	// Target is the name of the target
+	Target string
+	// FaultRemoved is true if the agent is not applying any fault and the iptables rules it created are gone
+	FaultRemoved bool
+	// Ready is true if the target is Ready and none of its containers is in CrashLoopBackOff
+	Ready bool
+	// Error describes why the target did not recover
+	Error string
+}
+
+// Recovered returns if the target recovered from the faults
+func (r TargetRecovery) Recovered() bool {
+	return r.FaultRemoved && r.Ready
+}
+
+// agentVerification is the verification reported by the agent's verify command
+type agentVerification struct {
+	Active bool     `json:"active"`
+	Rules  []string `json:"rules"`
+}
+
+// podRecovery returns the recovery of the pod. If the agent was not injected, the pod had no faults applied.
+func podRecovery(ctx context.Context, helper helpers.PodHelper, pod corev1.Pod) (TargetRecovery, error) {
+	recovery := TargetRecovery{Target: pod.Name, FaultRemoved: true}
+	reasons := []string{}
+
+	if hasAgent(pod) {
+		stdout, stderr, err := helper.Exec(ctx, pod.Name, "xk6-agent", buildVerifyCmd(), []byte{})
+		if err != nil {
+			return TargetRecovery{}, fmt.Errorf("verifying fault removal in %q: %w \n%s", pod.Name, err, string(stderr))
+		}
+
+		verification := agentVerification{}
+		err = json.Unmarshal(stdout, &verification)
+		if err != nil {
+			return TargetRecovery{}, fmt.Errorf("decoding fault verification in %q: %w", pod.Name, err)
+		}
+
+		if verification.Active {
+			recovery.FaultRemoved = false
+			reasons = append(reasons, "fault is still active")
+		}
+
+		if len(verification.Rules) > 0 {
+			recovery.FaultRemoved = false
+			reasons = append(reasons, fmt.Sprintf("%d iptables rules remain", len(verification.Rules)))
+		}
+	}
+
+	if reason := unhealthyReason(pod); reason != "" {
+		reasons = append(reasons, reason)
+	} else {
+		recovery.Ready = true
+	}
+
+	recovery.Error = strings.Join(reasons, ", ")
+
+	return recovery, nil
+}
+
+// verifyRecovery checks the recovery of the targets using the check function until all the targets recovered
+// or the timeout elapses, and returns the last recovery of each target
+func verifyRecovery(
+	ctx context.Context,
+	timeout time.Duration,
+	check func(context.Context) ([]TargetRecovery, error),
+) ([]TargetRecovery, error) {
+	if timeout <= 0 {
+		timeout = DefaultRecoveryTimeout
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		recovery, err := check(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		recovered := true
+		for _, target := range recovery {
+			recovered = recovered && target.Recovered()
+		}
+
+		if recovered || time.Now().After(deadline) {
+			return recovery, nil
+		}
+
+		err = waitFor(ctx, recoveryCheckInterval)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package disruptors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_PodRecovery(t *testing.T) {
+	t.Parallel()
+
+	agent := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name: "xk6-agent",
+		},
+	}
+
+	testCases := []struct {
+		title    string
+		pod      corev1.Pod
+		stdout   []byte
+		expected TargetRecovery
+	}{
+		{
+			title:    "pod without agent",
+			pod:      builders.NewPodBuilder("pod").WithNamespace("test-ns").WithReady(true).Build(),
+			expected: TargetRecovery{Target: "pod", FaultRemoved: true, Ready: true},
+		},
+		{
+			title:    "pod not ready",
+			pod:      builders.NewPodBuilder("pod").WithNamespace("test-ns").WithReady(false).Build(),
+			expected: TargetRecovery{Target: "pod", FaultRemoved: true, Error: "pod is not Ready"},
+		},
+		{
+			title:    "fault removed",
+			pod:      builders.NewPodBuilder("pod").WithNamespace("test-ns").WithReady(true).Build(),
+			stdout:   []byte(`{"active":false}`),
+			expected: TargetRecovery{Target: "pod", FaultRemoved: true, Ready: true},
+		},
+		{
+			title:  "rules remain",
+			pod:    builders.NewPodBuilder("pod").WithNamespace("test-ns").WithReady(true).Build(),
+			stdout: []byte(`{"active":false,"rules":["-t nat -A OUTPUT -m comment --comment xk6-disruptor"]}`),
+			expected: TargetRecovery{
+				Target: "pod",
+				Ready:  true,
+				Error:  "1 iptables rules remain",
+			},
+		},
+		{
+			title:  "fault active",
+			pod:    builders.NewPodBuilder("pod").WithNamespace("test-ns").WithReady(false).Build(),
+			stdout: []byte(`{"active":true}`),
+			expected: TargetRecovery{
+				Target: "pod",
+				Error:  "fault is still active, pod is not Ready",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := tc.pod
+			if tc.stdout != nil {
+				pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, agent)
+			}
+
+			client := fake.NewSimpleClientset(&pod)
+			executor := helpers.NewFakePodCommandExecutor()
+			executor.SetResult(tc.stdout, nil, nil)
+			helper := helpers.NewPodHelper(client, executor, "test-ns")
+
+			recovery, err := podRecovery(context.TODO(), helper, pod)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, recovery); diff != "" {
+				t.Fatalf("expected recovery does not match returned\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_VerifyRecovery(t *testing.T) {
+	t.Parallel()
+
+	checks := 0
+	recovery, err := verifyRecovery(context.TODO(), 5*time.Second, func(context.Context) ([]TargetRecovery, error) {
+		checks++
+		return []TargetRecovery{{Target: "pod", FaultRemoved: true, Ready: checks > 1}}, nil
+	})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if checks != 2 || !recovery[0].Recovered() {
+		t.Fatalf("expected target to recover in the second check but got %v after %d checks", recovery, checks)
+	}
+}
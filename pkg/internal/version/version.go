@@ -2,16 +2,24 @@
 package version
 
 import (
+	"fmt"
 	"runtime/debug"
 )
 
-const xk6DisruptorPath = "github.com/grafana/xk6-disruptor"
+const (
+	xk6DisruptorPath = "github.com/grafana/xk6-disruptor"
+	k6Path           = "go.k6.io/k6"
+)
 
-// DisruptorVersion returns the version of the currently executed disruptor
-func DisruptorVersion() string {
+// moduleVersion returns the version of the module with the given path in the currently executed binary
+func moduleVersion(path string) string {
 	if bi, ok := debug.ReadBuildInfo(); ok {
+		if bi.Main.Path == path {
+			return bi.Main.Version
+		}
+
 		for _, d := range bi.Deps {
-			if d.Path == xk6DisruptorPath {
+			if d.Path == path {
 				if d.Replace != nil {
 					return d.Replace.Version
 				}
@@ -23,6 +31,38 @@ func DisruptorVersion() string {
 	return ""
 }
 
+// DisruptorVersion returns the version of the currently executed disruptor. In the agent, the disruptor is the main
+// module.
+func DisruptorVersion() string {
+	return moduleVersion(xk6DisruptorPath)
+}
+
+// K6Version returns the version of k6 the disruptor is built with
+func K6Version() string {
+	return moduleVersion(k6Path)
+}
+
+// UserAgent returns the User-Agent that identifies the requests made by the disruptor in the given test run. The
+// test run is omitted if runID is empty.
+func UserAgent(runID string) string {
+	disruptorVersion := DisruptorVersion()
+	if disruptorVersion == "" {
+		disruptorVersion = "unknown"
+	}
+
+	k6Version := K6Version()
+	if k6Version == "" {
+		k6Version = "unknown"
+	}
+
+	userAgent := fmt.Sprintf("xk6-disruptor/%s k6/%s", disruptorVersion, k6Version)
+	if runID == "" {
+		return userAgent
+	}
+
+	return fmt.Sprintf("%s run=%s", userAgent, runID)
+}
+
 // AgentImage returns the name of the agent image that corresponds to
 // this version of the extension.
 func AgentImage() string {
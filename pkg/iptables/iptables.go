@@ -2,12 +2,16 @@
 package iptables
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/grafana/xk6-disruptor/pkg/runtime"
 )
 
+// Tag is the comment added to the rules to identify them as created by the disruptor agent
+const Tag = "xk6-disruptor"
+
 // Iptables adds and removes iptables rules by executing the `iptables` binary.
 type Iptables struct {
 	// Executor is the runtime.Executor used to run the iptables binary.
@@ -21,6 +25,13 @@ func New(executor runtime.Executor) Iptables {
 	}
 }
 
+// Available returns if netfilter rules can be managed using the iptables binary. Nodes that only support nftables
+// fail when listing the rules with iptables (legacy).
+func (i Iptables) Available() bool {
+	_, err := i.executor.Exec("iptables", "-t", "nat", "-S")
+	return err == nil
+}
+
 // Add appends a rule into the corresponding table and chain.
 func (i Iptables) Add(r Rule) error {
 	err := i.exec(r.add())
@@ -41,6 +52,67 @@ func (i Iptables) Remove(r Rule) error {
 	return nil
 }
 
+// RemoveTagged removes the rules tagged as created by the disruptor agent from the given tables. This removes the rules
+// left behind by an agent that terminated unexpectedly. RemoveTagged continues removing rules even if removing one
+// fails.
+func (i Iptables) RemoveTagged(tables ...string) error {
+	var errs []error
+
+	for _, table := range tables {
+		rules, err := i.tagged(table)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, rule := range rules {
+			if err = i.exec(fmt.Sprintf("-t %s -D %s", table, rule)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ListTagged returns the rules tagged as created by the disruptor agent in the given tables, using the arguments
+// for appending them (e.g. "-t nat -A OUTPUT ...")
+func (i Iptables) ListTagged(tables ...string) ([]string, error) {
+	tagged := []string{}
+	for _, table := range tables {
+		rules, err := i.tagged(table)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rule := range rules {
+			tagged = append(tagged, fmt.Sprintf("-t %s -A %s", table, rule))
+		}
+	}
+
+	return tagged, nil
+}
+
+// tagged returns the chain and arguments of the rules tagged as created by the disruptor agent in the table
+func (i Iptables) tagged(table string) ([]string, error) {
+	out, err := i.executor.Exec("iptables", "-t", table, "-S")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", err, out)
+	}
+
+	rules := []string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		// rules are listed using the same arguments used for appending them
+		rule, found := strings.CutPrefix(strings.TrimSpace(line), "-A ")
+		if !found || !strings.Contains(rule, "--comment "+Tag) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
 func (i Iptables) exec(args string) error {
 	out, err := i.executor.Exec("iptables", strings.Split(args, " ")...)
 	if err != nil {
@@ -77,25 +149,20 @@ func (i *RuleSet) Add(r Rule) error {
 
 // Remove removes all added rules. If an error occurs, Remove continues to try and remove remaining rules.
 func (i *RuleSet) Remove() error {
-	var errors []error
+	var errs []error
 
 	var remaining []Rule
 	for _, rule := range i.rules {
 		err := i.iptables.Remove(rule)
 		if err != nil {
-			errors = append(errors, err)
+			errs = append(errs, err)
 			remaining = append(remaining, rule)
 		}
 	}
 
 	i.rules = remaining
 
-	// TODO: Return all errors with errors.Join when we move to go 1.21.
-	if len(errors) > 0 {
-		return errors[0]
-	}
-
-	return nil
+	return errors.Join(errs...)
 }
 
 // Rule is a netfilter/iptables rule.
@@ -108,12 +175,21 @@ type Rule struct {
 	// Arguments must be space-separated. Using shell-style quotes or backslashes to group more than one space-separated
 	// word as one argument is not allowed.
 	Args string
+	// Insert inserts the rule at the beginning of the chain instead of appending it. It is used for rules that must
+	// be evaluated before the rules added by other components, such as the sidecar of a service mesh.
+	Insert bool
 }
 
+// rules are tagged for removing them if the agent terminates unexpectedly
 func (r Rule) add() string {
-	return fmt.Sprintf("-t %s -A %s %s", r.Table, r.Chain, r.Args)
+	operation := "-A"
+	if r.Insert {
+		operation = "-I"
+	}
+
+	return fmt.Sprintf("-t %s %s %s -m comment --comment %s %s", r.Table, operation, r.Chain, Tag, r.Args)
 }
 
 func (r Rule) remove() string {
-	return fmt.Sprintf("-t %s -D %s %s", r.Table, r.Chain, r.Args)
+	return fmt.Sprintf("-t %s -D %s -m comment --comment %s %s", r.Table, r.Chain, Tag, r.Args)
 }
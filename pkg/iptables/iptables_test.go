@@ -30,7 +30,21 @@ func Test_Iptables(t *testing.T) {
 				})
 			},
 			expectedCommands: []string{
-				"iptables -t some -A ECHO foo -t bar -w xx",
+				"iptables -t some -A ECHO -m comment --comment xk6-disruptor foo -t bar -w xx",
+			},
+		},
+		{
+			name: "Inserts rule",
+			testFunc: func(i Iptables) error {
+				return i.Add(Rule{
+					Table:  "some",
+					Chain:  "ECHO",
+					Args:   "foo -t bar -w xx",
+					Insert: true,
+				})
+			},
+			expectedCommands: []string{
+				"iptables -t some -I ECHO -m comment --comment xk6-disruptor foo -t bar -w xx",
 			},
 		},
 		{
@@ -43,7 +57,7 @@ func Test_Iptables(t *testing.T) {
 				})
 			},
 			expectedCommands: []string{
-				"iptables -t some -D ECHO foo -t bar -w xx",
+				"iptables -t some -D ECHO -m comment --comment xk6-disruptor foo -t bar -w xx",
 			},
 		},
 		{
@@ -57,7 +71,7 @@ func Test_Iptables(t *testing.T) {
 			},
 			execError: anError,
 			expectedCommands: []string{
-				"iptables -t some -D ECHO foo -t bar -w xx",
+				"iptables -t some -D ECHO -m comment --comment xk6-disruptor foo -t bar -w xx",
 			},
 			expectedError: anError,
 		},
@@ -105,8 +119,8 @@ func Test_RulesetAddsRemovesRules(t *testing.T) {
 
 	// Check we have run the expected commands.
 	expectedAddCmds := []string{
-		"iptables -t table1 -A CHAIN1 --foo foo --bar bar",
-		"iptables -t table2 -A CHAIN2 --boo boo --baz baz",
+		"iptables -t table1 -A CHAIN1 -m comment --comment xk6-disruptor --foo foo --bar bar",
+		"iptables -t table2 -A CHAIN2 -m comment --comment xk6-disruptor --boo boo --baz baz",
 	}
 
 	if diff := cmp.Diff(exec.CmdHistory(), expectedAddCmds); diff != "" {
@@ -123,8 +137,8 @@ func Test_RulesetAddsRemovesRules(t *testing.T) {
 
 	// Check we have run the expected commands.
 	expectedRemoveCmds := []string{
-		"iptables -t table1 -D CHAIN1 --foo foo --bar bar",
-		"iptables -t table2 -D CHAIN2 --boo boo --baz baz",
+		"iptables -t table1 -D CHAIN1 -m comment --comment xk6-disruptor --foo foo --bar bar",
+		"iptables -t table2 -D CHAIN2 -m comment --comment xk6-disruptor --boo boo --baz baz",
 	}
 
 	if diff := cmp.Diff(exec.CmdHistory(), expectedRemoveCmds); diff != "" {
@@ -143,7 +157,7 @@ func Test_RulesetAddsRemovesRules(t *testing.T) {
 
 	// Check we run the expected add command.
 	expectedAddCmds = []string{
-		"iptables -t table3 -A CHAIN3 --zoo zoo --zap zap",
+		"iptables -t table3 -A CHAIN3 -m comment --comment xk6-disruptor --zoo zoo --zap zap",
 	}
 	if diff := cmp.Diff(exec.CmdHistory(), expectedAddCmds); diff != "" {
 		t.Fatalf("Executed commands to add rules do not match expected:\n%s", diff)
@@ -159,10 +173,74 @@ func Test_RulesetAddsRemovesRules(t *testing.T) {
 
 	// Check we have run the expected command.
 	expectedRemoveCmds = []string{
-		"iptables -t table3 -D CHAIN3 --zoo zoo --zap zap",
+		"iptables -t table3 -D CHAIN3 -m comment --comment xk6-disruptor --zoo zoo --zap zap",
 	}
 
 	if diff := cmp.Diff(exec.CmdHistory(), expectedRemoveCmds); diff != "" {
 		t.Fatalf("Executed commands to remove rules do not match expected:\n%s", diff)
 	}
 }
+
+func Test_RemoveTagged(t *testing.T) {
+	t.Parallel()
+
+	rules := map[string]string{
+		"nat": "-P OUTPUT ACCEPT\n" +
+			"-A OUTPUT -m comment --comment xk6-disruptor -p tcp --dport 80 -j REDIRECT --to-port 8080\n" +
+			"-A OUTPUT -p tcp --dport 443 -j ACCEPT\n",
+		"filter": "-P INPUT ACCEPT\n" +
+			"-A INPUT -m comment --comment xk6-disruptor -p tcp --dport 8080 -j REJECT --reject-with tcp-reset\n",
+	}
+
+	exec := runtime.NewCallbackExecutor(func(_ string, args ...string) ([]byte, error) {
+		if args[len(args)-1] == "-S" {
+			return []byte(rules[args[1]]), nil
+		}
+		return nil, nil
+	})
+
+	err := New(exec).RemoveTagged("nat", "filter")
+	if err != nil {
+		t.Fatalf("error removing rules: %v", err)
+	}
+
+	expected := []string{
+		"iptables -t nat -S",
+		"iptables -t nat -D OUTPUT -m comment --comment xk6-disruptor -p tcp --dport 80 -j REDIRECT --to-port 8080",
+		"iptables -t filter -S",
+		"iptables -t filter -D INPUT -m comment --comment xk6-disruptor -p tcp --dport 8080 -j REJECT " +
+			"--reject-with tcp-reset",
+	}
+
+	if diff := cmp.Diff(expected, exec.CmdHistory()); diff != "" {
+		t.Fatalf("Executed commands to remove rules do not match expected:\n%s", diff)
+	}
+}
+
+func Test_ListTagged(t *testing.T) {
+	t.Parallel()
+
+	rules := map[string]string{
+		"nat": "-P OUTPUT ACCEPT\n" +
+			"-A OUTPUT -m comment --comment xk6-disruptor -p tcp --dport 80 -j REDIRECT --to-port 8080\n" +
+			"-A OUTPUT -p tcp --dport 443 -j ACCEPT\n",
+		"filter": "-P INPUT ACCEPT\n",
+	}
+
+	exec := runtime.NewCallbackExecutor(func(_ string, args ...string) ([]byte, error) {
+		return []byte(rules[args[1]]), nil
+	})
+
+	tagged, err := New(exec).ListTagged("nat", "filter")
+	if err != nil {
+		t.Fatalf("error listing rules: %v", err)
+	}
+
+	expected := []string{
+		"-t nat -A OUTPUT -m comment --comment xk6-disruptor -p tcp --dport 80 -j REDIRECT --to-port 8080",
+	}
+
+	if diff := cmp.Diff(expected, tagged); diff != "" {
+		t.Fatalf("Listed rules do not match expected:\n%s", diff)
+	}
+}
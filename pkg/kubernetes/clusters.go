@@ -0,0 +1,55 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Clusters keeps a Kubernetes instance for each cluster used in a test. The instance of a cluster is created the
+// first time the cluster is used and it is reused afterwards, including by other VUs, so the pods of a cluster are
+// watched and cached once per process.
+type Clusters struct {
+	mutex    sync.Mutex
+	factory  func(Config) (Kubernetes, error)
+	clusters map[string]Kubernetes
+}
+
+// NewClusters returns a Clusters that creates the Kubernetes instances with NewWithConfig
+func NewClusters() *Clusters {
+	return &Clusters{
+		factory:  NewWithConfig,
+		clusters: map[string]Kubernetes{},
+	}
+}
+
+// Get returns the Kubernetes instance for the cluster defined by the config
+func (c *Clusters) Get(config Config) (Kubernetes, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := fmt.Sprintf("%+v", config)
+	if k8s, found := c.clusters[key]; found {
+		return k8s, nil
+	}
+
+	k8s, err := c.factory(config)
+	if err != nil {
+		return nil, err
+	}
+
+	c.clusters[key] = k8s
+
+	return k8s, nil
+}
+
+// Stop stops the Kubernetes instances of all the clusters. The instances are created again if the clusters are used
+// afterwards.
+func (c *Clusters) Stop() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for key, k8s := range c.clusters {
+		k8s.Stop()
+		delete(c.clusters, key)
+	}
+}
@@ -0,0 +1,61 @@
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_Clusters(t *testing.T) {
+	t.Parallel()
+
+	created := map[string]int{}
+	clusters := NewClusters()
+	clusters.factory = func(config Config) (Kubernetes, error) {
+		if config.Kubeconfig == "invalid" {
+			return nil, errors.New("invalid kubeconfig")
+		}
+		created[config.Kubeconfig]++
+		return NewFakeKubernetes(fake.NewSimpleClientset())
+	}
+
+	clusterA, err := clusters.Get(Config{Kubeconfig: "cluster-a"})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	clusterB, err := clusters.Get(Config{Kubeconfig: "cluster-b"})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if clusterA == clusterB {
+		t.Fatalf("expected different instances for different clusters")
+	}
+
+	again, err := clusters.Get(Config{Kubeconfig: "cluster-a"})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if again != clusterA || created["cluster-a"] != 1 {
+		t.Fatalf("expected instance of cluster to be reused")
+	}
+
+	_, err = clusters.Get(Config{Kubeconfig: "invalid"})
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+
+	clusters.Stop()
+
+	_, err = clusters.Get(Config{Kubeconfig: "cluster-a"})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if created["cluster-a"] != 2 {
+		t.Fatalf("expected instance of cluster to be created again after stopping")
+	}
+}
@@ -2,10 +2,83 @@ package kubernetes
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// QPSEnvVar is the environment variable that defines the maximum queries per second to the API server when
+	// they are not defined in the Config
+	QPSEnvVar = "XK6_DISRUPTOR_K8S_QPS"
+	// BurstEnvVar is the environment variable that defines the maximum burst of requests to the API server when it
+	// is not defined in the Config
+	BurstEnvVar = "XK6_DISRUPTOR_K8S_BURST"
+	// TimeoutEnvVar is the environment variable that defines the timeout of the requests to the API server (e.g.
+	// "30s") when it is not defined in the Config
+	TimeoutEnvVar = "XK6_DISRUPTOR_K8S_TIMEOUT"
+	// RetriesEnvVar is the environment variable that defines the number of times the requests to the API server that
+	// fail with a transient error are retried when it is not defined in the Config
+	RetriesEnvVar = "XK6_DISRUPTOR_K8S_RETRIES"
+	// RetryBackoffEnvVar is the environment variable that defines the delay before the first retry of a request to
+	// the API server (e.g. "500ms") when it is not defined in the Config
+	RetryBackoffEnvVar = "XK6_DISRUPTOR_K8S_RETRY_BACKOFF"
+	// DefaultQPS is the default maximum queries per second to the API server
+	DefaultQPS = 100
+	// DefaultBurst is the default maximum burst of requests to the API server
+	DefaultBurst = 150
+	// DefaultRetries is the default number of times the requests that fail with a transient error are retried
+	DefaultRetries = 3
+	// DefaultRetryBackoff is the default delay before the first retry of a request. It doubles on each retry.
+	DefaultRetryBackoff = 500 * time.Millisecond
 )
 
+// setClientLimits sets the limits of the requests to the API server that are not defined in the config from the
+// environment or the defaults
+func setClientLimits(config *rest.Config) {
+	// As per the discussion in [1] client side rate limiting is no longer required.
+	// Setting a large limit by default
+	// [1] https://github.com/kubernetes/kubernetes/issues/111880
+	if config.QPS == 0 {
+		config.QPS = utils.GetFloat32EnvVar(QPSEnvVar, DefaultQPS)
+	}
+
+	if config.Burst == 0 {
+		config.Burst = int(utils.GetInt32EnvVar(BurstEnvVar, DefaultBurst))
+	}
+
+	if config.Timeout == 0 {
+		config.Timeout = utils.GetDurationEnvVar(TimeoutEnvVar, 0)
+	}
+}
+
+// retryOptions returns the options for retrying the requests to the API server that fail with a transient error. The
+// options not defined in the config are taken from the environment or the defaults.
+func (c Config) retryOptions() helpers.RetryOptions {
+	retries := c.Retries
+	if retries == 0 {
+		retries = int(utils.GetInt32EnvVar(RetriesEnvVar, DefaultRetries))
+	}
+	if retries < 0 {
+		retries = 0
+	}
+
+	backoff := c.RetryBackoff
+	if backoff == 0 {
+		backoff = utils.GetDurationEnvVar(RetryBackoffEnvVar, DefaultRetryBackoff)
+	}
+
+	return helpers.RetryOptions{Retries: retries, Backoff: backoff}
+}
+
 // getConfigPath Copied from ahmetb/kubectx source code:
 // https://github.com/ahmetb/kubectx/blob/29850e1a75cb5cad8d93f74a4114311eb9feba9f/internal/kubeconfig/kubeconfigloader.go#L59
 func getConfigPath() (string, error) {
@@ -29,3 +102,147 @@ func getConfigPath() (string, error) {
 	}
 	return filepath.Join(home, ".kube", "config"), nil
 }
+
+// Config defines the cluster a Kubernetes instance connects to
+type Config struct {
+	// Kubeconfig is the path to the kubeconfig file. If empty, the config is loaded as described in New
+	Kubeconfig string `js:"kubeconfig"`
+	// Context is the name of the kubeconfig context to use. If empty, the current context is used.
+	Context string `js:"context"`
+	// Impersonate defines the identity the requests to the cluster are made as
+	Impersonate Impersonation `js:"impersonate"`
+	// UserAgent is the User-Agent of the requests to the cluster. If empty, the versions of the disruptor and k6 are
+	// used.
+	UserAgent string `js:"userAgent"`
+	// QPS is the maximum queries per second to the API server. If zero, the QPSEnvVar or the DefaultQPS is used.
+	QPS float32 `js:"qps"`
+	// Burst is the maximum burst of requests to the API server. If zero, the BurstEnvVar or the DefaultBurst is used.
+	Burst int `js:"burst"`
+	// Timeout is the timeout of the requests to the API server. If zero, the TimeoutEnvVar is used. By default,
+	// requests have no timeout.
+	Timeout time.Duration `js:"timeout"`
+	// Retries is the number of times the idempotent requests to the API server that fail with a transient error
+	// (e.g. too many requests) are retried. If zero, the RetriesEnvVar or the DefaultRetries is used. A negative
+	// value disables the retries.
+	Retries int `js:"retries"`
+	// RetryBackoff is the delay before the first retry. It doubles on each retry. If zero, the RetryBackoffEnvVar or
+	// the DefaultRetryBackoff is used.
+	RetryBackoff time.Duration `js:"retryBackoff"`
+	// kubeconfigData is the content of the kubeconfig, for kubeconfigs that are not stored in a file. Set by
+	// NewFromKubeconfigBytes.
+	kubeconfigData []byte
+}
+
+// Impersonation defines the identity to impersonate in the requests to the cluster. The identity of the kubeconfig
+// must be allowed to impersonate it.
+type Impersonation struct {
+	// User is the name of the user to impersonate
+	User string `js:"user"`
+	// Groups are the groups to impersonate. Requires impersonating a user or a service account.
+	Groups []string `js:"groups"`
+	// ServiceAccount is the service account to impersonate in the form namespace/name
+	ServiceAccount string `js:"serviceAccount"`
+}
+
+// userName returns the name of the user to impersonate
+func (i Impersonation) userName() (string, error) {
+	if i.ServiceAccount == "" {
+		if i.User == "" && len(i.Groups) > 0 {
+			return "", errors.New("impersonating groups requires a user or a service account")
+		}
+		return i.User, nil
+	}
+
+	if i.User != "" {
+		return "", errors.New("user and service account cannot be impersonated at the same time")
+	}
+
+	namespace, name, found := strings.Cut(i.ServiceAccount, "/")
+	if !found || namespace == "" || name == "" {
+		return "", fmt.Errorf("invalid service account %q. Expected namespace/name", i.ServiceAccount)
+	}
+
+	return fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name), nil
+}
+
+// restConfig returns the rest.Config for connecting to the cluster
+func (c Config) restConfig() (*rest.Config, error) {
+	config, err := c.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := c.Impersonate.userName()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.UserAgent != "" {
+		config.UserAgent = c.UserAgent
+	}
+
+	config.QPS = c.QPS
+	config.Burst = c.Burst
+	config.Timeout = c.Timeout
+
+	if user != "" {
+		config.Impersonate = rest.ImpersonationConfig{
+			UserName: user,
+			Groups:   c.Impersonate.Groups,
+		}
+	}
+
+	return config, nil
+}
+
+// loadConfig loads the config from the kubeconfig. The in-cluster config is only used if neither the kubeconfig nor
+// the context are specified.
+func (c Config) loadConfig() (*rest.Config, error) {
+	if c.kubeconfigData != nil {
+		return c.loadConfigFromBytes()
+	}
+
+	if c.Kubeconfig == "" && c.Context == "" {
+		config, err := rest.InClusterConfig()
+		if err == nil {
+			return config, nil
+		}
+
+		if !errors.Is(err, rest.ErrNotInCluster) {
+			return nil, err
+		}
+	}
+
+	kubeconfig := c.Kubeconfig
+	if kubeconfig == "" {
+		path, err := getConfigPath()
+		if err != nil {
+			return nil, fmt.Errorf("error getting kubernetes config path: %w", err)
+		}
+		kubeconfig = path
+	}
+
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+		&clientcmd.ConfigOverrides{CurrentContext: c.Context},
+	).ClientConfig()
+}
+
+// loadConfigFromBytes loads the config from the content of the kubeconfig
+func (c Config) loadConfigFromBytes() (*rest.Config, error) {
+	if c.Kubeconfig != "" {
+		return nil, errors.New("the path and the content of the kubeconfig cannot be specified at the same time")
+	}
+
+	kubeconfig, err := clientcmd.Load(c.kubeconfigData)
+	if err != nil {
+		return nil, fmt.Errorf("loading kubeconfig: %w", err)
+	}
+
+	return clientcmd.NewNonInteractiveClientConfig(
+		*kubeconfig,
+		c.Context,
+		&clientcmd.ConfigOverrides{},
+		nil,
+	).ClientConfig()
+}
@@ -0,0 +1,282 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+	"k8s.io/client-go/rest"
+)
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: cluster-a
+  cluster:
+    server: https://cluster-a:6443
+- name: cluster-b
+  cluster:
+    server: https://cluster-b:6443
+users:
+- name: user
+  user:
+    token: token
+contexts:
+- name: context-a
+  context:
+    cluster: cluster-a
+    user: user
+- name: context-b
+  context:
+    cluster: cluster-b
+    user: user
+current-context: context-a
+`
+
+func Test_ConfigContext(t *testing.T) {
+	t.Parallel()
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	err := os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	testCases := []struct {
+		title       string
+		context     string
+		expectError bool
+		expected    string
+	}{
+		{
+			title:    "current context",
+			context:  "",
+			expected: "https://cluster-a:6443",
+		},
+		{
+			title:    "named context",
+			context:  "context-b",
+			expected: "https://cluster-b:6443",
+		},
+		{
+			title:       "unknown context",
+			context:     "context-c",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := Config{Kubeconfig: kubeconfig, Context: tc.context}.restConfig()
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if config.Host != tc.expected {
+				t.Fatalf("expected host %q got %q", tc.expected, config.Host)
+			}
+		})
+	}
+}
+
+func Test_ConfigImpersonation(t *testing.T) {
+	t.Parallel()
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	err := os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	testCases := []struct {
+		title       string
+		impersonate Impersonation
+		expectError bool
+		expected    rest.ImpersonationConfig
+	}{
+		{
+			title:       "no impersonation",
+			impersonate: Impersonation{},
+			expected:    rest.ImpersonationConfig{},
+		},
+		{
+			title:       "user and groups",
+			impersonate: Impersonation{User: "chaos", Groups: []string{"chaos-testers"}},
+			expected:    rest.ImpersonationConfig{UserName: "chaos", Groups: []string{"chaos-testers"}},
+		},
+		{
+			title:       "service account",
+			impersonate: Impersonation{ServiceAccount: "chaos/disruptor"},
+			expected:    rest.ImpersonationConfig{UserName: "system:serviceaccount:chaos:disruptor"},
+		},
+		{
+			title:       "invalid service account",
+			impersonate: Impersonation{ServiceAccount: "disruptor"},
+			expectError: true,
+		},
+		{
+			title:       "user and service account",
+			impersonate: Impersonation{User: "chaos", ServiceAccount: "chaos/disruptor"},
+			expectError: true,
+		},
+		{
+			title:       "groups without user",
+			impersonate: Impersonation{Groups: []string{"chaos-testers"}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			config, err := Config{Kubeconfig: kubeconfig, Impersonate: tc.impersonate}.restConfig()
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, config.Impersonate); diff != "" {
+				t.Fatalf("expected impersonation does not match returned:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ConfigUserAgent(t *testing.T) {
+	t.Parallel()
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	err := os.WriteFile(kubeconfig, []byte(testKubeconfig), 0o600)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	userAgent := "xk6-disruptor/v0.3.0 k6/v0.50.0 run=abc"
+	config, err := Config{Kubeconfig: kubeconfig, UserAgent: userAgent}.restConfig()
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if config.UserAgent != userAgent {
+		t.Fatalf("expected user agent %q got %q", userAgent, config.UserAgent)
+	}
+}
+
+//nolint:paralleltest // uses t.Setenv
+func Test_ClientLimits(t *testing.T) {
+	testCases := []struct {
+		title    string
+		config   rest.Config
+		env      map[string]string
+		expected rest.Config
+	}{
+		{
+			title:    "defaults",
+			config:   rest.Config{},
+			expected: rest.Config{QPS: DefaultQPS, Burst: DefaultBurst},
+		},
+		{
+			title:    "from config",
+			config:   rest.Config{QPS: 10, Burst: 20, Timeout: time.Minute},
+			env:      map[string]string{QPSEnvVar: "50", BurstEnvVar: "60", TimeoutEnvVar: "30s"},
+			expected: rest.Config{QPS: 10, Burst: 20, Timeout: time.Minute},
+		},
+		{
+			title:    "from environment",
+			config:   rest.Config{},
+			env:      map[string]string{QPSEnvVar: "50", BurstEnvVar: "60", TimeoutEnvVar: "30s"},
+			expected: rest.Config{QPS: 50, Burst: 60, Timeout: 30 * time.Second},
+		},
+		{
+			title:    "invalid environment",
+			config:   rest.Config{},
+			env:      map[string]string{QPSEnvVar: "many", BurstEnvVar: "", TimeoutEnvVar: "30"},
+			expected: rest.Config{QPS: DefaultQPS, Burst: DefaultBurst},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			config := tc.config
+			setClientLimits(&config)
+
+			if config.QPS != tc.expected.QPS || config.Burst != tc.expected.Burst ||
+				config.Timeout != tc.expected.Timeout {
+				t.Fatalf(
+					"expected QPS %v burst %d timeout %s got QPS %v burst %d timeout %s",
+					tc.expected.QPS, tc.expected.Burst, tc.expected.Timeout,
+					config.QPS, config.Burst, config.Timeout,
+				)
+			}
+		})
+	}
+}
+
+//nolint:paralleltest // uses t.Setenv
+func Test_RetryOptions(t *testing.T) {
+	testCases := []struct {
+		title    string
+		config   Config
+		env      map[string]string
+		expected helpers.RetryOptions
+	}{
+		{
+			title:    "defaults",
+			config:   Config{},
+			expected: helpers.RetryOptions{Retries: DefaultRetries, Backoff: DefaultRetryBackoff},
+		},
+		{
+			title:    "from config",
+			config:   Config{Retries: 5, RetryBackoff: time.Second},
+			env:      map[string]string{RetriesEnvVar: "2", RetryBackoffEnvVar: "100ms"},
+			expected: helpers.RetryOptions{Retries: 5, Backoff: time.Second},
+		},
+		{
+			title:    "from environment",
+			config:   Config{},
+			env:      map[string]string{RetriesEnvVar: "2", RetryBackoffEnvVar: "100ms"},
+			expected: helpers.RetryOptions{Retries: 2, Backoff: 100 * time.Millisecond},
+		},
+		{
+			title:    "retries disabled",
+			config:   Config{Retries: -1},
+			expected: helpers.RetryOptions{Retries: 0, Backoff: DefaultRetryBackoff},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+
+			if diff := cmp.Diff(tc.expected, tc.config.retryOptions()); diff != "" {
+				t.Fatalf("expected options do not match returned:\n%s", diff)
+			}
+		})
+	}
+}
@@ -5,26 +5,63 @@ import (
 
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
 
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 // FakeKubernetes is a fake implementation of the Kubernetes interface
 type FakeKubernetes struct {
 	client   *fake.Clientset
+	dynamic  dynamic.Interface
 	ctx      context.Context
 	executor *helpers.FakePodCommandExecutor
 }
 
 // NewFakeKubernetes returns a new fake implementation of Kubernetes from fake Clientset
 func NewFakeKubernetes(clientset *fake.Clientset) (*FakeKubernetes, error) {
+	// the fake clientset does not authorize requests, so all the access reviews are allowed
+	clientset.PrependReactor(
+		"create",
+		"selfsubjectaccessreviews",
+		func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review, _ := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+			review.Status.Allowed = true
+			return true, review, nil
+		},
+	)
+
 	return &FakeKubernetes{
 		client:   clientset,
+		dynamic:  dynamicfake.NewSimpleDynamicClient(scheme.Scheme),
 		ctx:      context.TODO(),
 		executor: helpers.NewFakePodCommandExecutor(),
 	}, nil
 }
 
+// NewFakeKubernetesWithDynamic returns a new fake implementation of Kubernetes from fake Clientset that uses the
+// given dynamic client (e.g. a fake dynamic client that knows custom resources)
+func NewFakeKubernetesWithDynamic(
+	clientset *fake.Clientset,
+	dynamicClient dynamic.Interface,
+) (*FakeKubernetes, error) {
+	k8s, err := NewFakeKubernetes(clientset)
+	if err != nil {
+		return nil, err
+	}
+
+	k8s.dynamic = dynamicClient
+
+	return k8s, nil
+}
+
 // PodHelper returns a PodHelper for the given namespace
 func (f *FakeKubernetes) PodHelper(namespace string) helpers.PodHelper {
 	return helpers.NewPodHelper(
@@ -38,15 +75,49 @@ func (f *FakeKubernetes) PodHelper(namespace string) helpers.PodHelper {
 func (f *FakeKubernetes) ServiceHelper(namespace string) helpers.ServiceHelper {
 	return helpers.NewServiceHelper(
 		f.client,
+		f.executor,
 		namespace,
 	)
 }
 
+// NodeHelper returns a NodeHelper
+func (f *FakeKubernetes) NodeHelper() helpers.NodeHelper {
+	return helpers.NewNodeHelper(f.client)
+}
+
+// ManifestHelper returns a ManifestHelper that maps the kinds known by the client-go scheme
+func (f *FakeKubernetes) ManifestHelper() helpers.ManifestHelper {
+	return helpers.NewManifestHelper(f.client, f.dynamic, testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme))
+}
+
+// NamespaceHelper returns a NamespaceHelper
+func (f *FakeKubernetes) NamespaceHelper() helpers.NamespaceHelper {
+	return helpers.NewNamespaceHelper(f.client)
+}
+
+// WorkloadHelper returns a WorkloadHelper for the given namespace
+func (f *FakeKubernetes) WorkloadHelper(namespace string) helpers.WorkloadHelper {
+	return helpers.NewWorkloadHelper(f.client, namespace)
+}
+
 // Client return a kubernetes client
 func (f *FakeKubernetes) Client() kubernetes.Interface {
 	return f.client
 }
 
+// Dynamic returns a dynamic client
+func (f *FakeKubernetes) Dynamic() dynamic.Interface {
+	return f.dynamic
+}
+
+// ResourceHelper returns a ResourceHelper for the resource in the given namespace
+func (f *FakeKubernetes) ResourceHelper(resource schema.GroupVersionResource, namespace string) helpers.ResourceHelper {
+	return helpers.NewResourceHelper(f.dynamic, resource, namespace)
+}
+
+// Stop does nothing, as the fake helpers do not watch resources
+func (f *FakeKubernetes) Stop() {}
+
 // GetFakeProcessExecutor returns the FakeProcessExecutor used by the helpers to mock
 // the execution of commands in a Pod
 func (f *FakeKubernetes) GetFakeProcessExecutor() *helpers.FakePodCommandExecutor {
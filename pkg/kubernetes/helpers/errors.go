@@ -0,0 +1,85 @@
+package helpers
+
+import (
+	"errors"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	// ErrTargetNotFound is returned when the object an operation acts on (e.g. a pod or a service) does not exist
+	ErrTargetNotFound = errors.New("target not found")
+	// ErrPermissionDenied is returned when the Kubernetes API denies a request because the caller is not
+	// authenticated or lacks the permissions required
+	ErrPermissionDenied = errors.New("permission denied by the Kubernetes API")
+	// ErrEphemeralContainersDisabled is returned when the cluster does not support ephemeral containers, which are
+	// required for injecting the agent
+	ErrEphemeralContainersDisabled = errors.New("ephemeral containers are not enabled in the cluster")
+	// ErrVersionUnsupported is returned when the version of the cluster is not supported
+	ErrVersionUnsupported = errors.New("unsupported Kubernetes version")
+)
+
+// APIError is an error returned by the Kubernetes API. Its reason, if known, can be checked with errors.Is against
+// ErrTargetNotFound, ErrPermissionDenied and ErrEphemeralContainersDisabled. The original error can be checked with
+// the functions of the k8s.io/apimachinery/pkg/api/errors package.
+type APIError struct {
+	// Reason of the failure
+	Reason error
+	// Err is the error returned by the Kubernetes API
+	Err error
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the reason of the failure and the error returned by the Kubernetes API
+func (e *APIError) Unwrap() []error {
+	return []error{e.Reason, e.Err}
+}
+
+// WrapAPIError returns an APIError with the reason of the failure of a request to the Kubernetes API. Returns the
+// error unchanged if it is nil or its reason is not known.
+func WrapAPIError(err error) error {
+	reason := apiErrorReason(err)
+	if reason == nil {
+		return err
+	}
+
+	return &APIError{Reason: reason, Err: err}
+}
+
+// wrapEphemeralContainersError returns the error of adding an ephemeral container to a pod. Clusters that do not
+// support ephemeral containers reject the request because the ephemeralcontainers subresource does not exist or the
+// EphemeralContainers feature gate is disabled.
+func wrapEphemeralContainersError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var status k8serrors.APIStatus
+	subresourceMissing := errors.As(err, &status) && k8serrors.IsNotFound(err) &&
+		(status.Status().Details == nil || status.Status().Details.Name == "")
+	if subresourceMissing || k8serrors.IsMethodNotSupported(err) ||
+		strings.Contains(err.Error(), "EphemeralContainers feature-gate") {
+		return &APIError{Reason: ErrEphemeralContainersDisabled, Err: err}
+	}
+
+	return WrapAPIError(err)
+}
+
+// apiErrorReason returns the reason of an error returned by the Kubernetes API, or nil if it is not known
+func apiErrorReason(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case k8serrors.IsNotFound(err):
+		return ErrTargetNotFound
+	case k8serrors.IsForbidden(err), k8serrors.IsUnauthorized(err):
+		return ErrPermissionDenied
+	default:
+		return nil
+	}
+}
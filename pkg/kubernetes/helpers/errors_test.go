@@ -0,0 +1,127 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_WrapAPIError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		err      error
+		expected error
+	}{
+		{
+			title:    "not found",
+			err:      k8serrors.NewNotFound(corev1.Resource("pods"), "pod"),
+			expected: ErrTargetNotFound,
+		},
+		{
+			title:    "forbidden",
+			err:      k8serrors.NewForbidden(corev1.Resource("pods"), "pod", errors.New("denied")),
+			expected: ErrPermissionDenied,
+		},
+		{
+			title:    "unauthorized",
+			err:      k8serrors.NewUnauthorized("invalid token"),
+			expected: ErrPermissionDenied,
+		},
+		{
+			title:    "wrapped error",
+			err:      fmt.Errorf("getting pod: %w", k8serrors.NewNotFound(corev1.Resource("pods"), "pod")),
+			expected: ErrTargetNotFound,
+		},
+		{
+			title:    "unknown reason",
+			err:      k8serrors.NewConflict(corev1.Resource("pods"), "pod", errors.New("conflict")),
+			expected: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := WrapAPIError(tc.err)
+			if tc.expected == nil {
+				if !errors.Is(err, tc.err) || errors.Is(err, ErrTargetNotFound) || errors.Is(err, ErrPermissionDenied) {
+					t.Fatalf("expected error unchanged got %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("expected %v got %v", tc.expected, err)
+			}
+
+			// the original error is still accessible
+			if k8serrors.ReasonForError(err) != k8serrors.ReasonForError(tc.err) {
+				t.Fatalf("expected reason %q got %q", k8serrors.ReasonForError(tc.err), k8serrors.ReasonForError(err))
+			}
+		})
+	}
+}
+
+func Test_WrapEphemeralContainersError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		err      error
+		expected error
+	}{
+		{
+			title:    "subresource not found",
+			err:      k8serrors.NewGenericServerResponse(404, "patch", corev1.Resource("pods"), "", "", 0, false),
+			expected: ErrEphemeralContainersDisabled,
+		},
+		{
+			title:    "invalid request",
+			err:      k8serrors.NewInvalid(corev1.SchemeGroupVersion.WithKind("Pod").GroupKind(), "pod", nil),
+			expected: nil,
+		},
+		{
+			title: "feature gate disabled message",
+			err: &k8serrors.StatusError{ErrStatus: metav1.Status{
+				Status:  metav1.StatusFailure,
+				Reason:  metav1.StatusReasonInvalid,
+				Message: "spec.ephemeralContainers: Forbidden: disabled by EphemeralContainers feature-gate",
+			}},
+			expected: ErrEphemeralContainersDisabled,
+		},
+		{
+			title:    "pod not found",
+			err:      k8serrors.NewNotFound(corev1.Resource("pods"), "pod"),
+			expected: ErrTargetNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			err := wrapEphemeralContainersError(tc.err)
+			if tc.expected == nil {
+				if errors.Is(err, ErrEphemeralContainersDisabled) {
+					t.Fatalf("unexpected reason %v", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("expected %v got %v", tc.expected, err)
+			}
+		})
+	}
+}
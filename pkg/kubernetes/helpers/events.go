@@ -0,0 +1,69 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EventSource is the component reported as the source of the events recorded by the helpers
+const EventSource = "xk6-disruptor"
+
+// Event describes an event recorded about an object
+type Event struct {
+	// Type of the event: corev1.EventTypeNormal or corev1.EventTypeWarning
+	Type string
+	// Reason is a short, CamelCase description of the event (e.g. FaultStarted)
+	Reason string
+	// Message is a human readable description of the event
+	Message string
+	// Labels added to the event
+	Labels map[string]string
+}
+
+// newEvent returns the event about the object. Events are named after the object and the time they are recorded,
+// like the events recorded by the client-go event recorder.
+func newEvent(object corev1.ObjectReference, event Event) *corev1.Event {
+	now := metav1.Now()
+	eventType := event.Type
+	if eventType == "" {
+		eventType = corev1.EventTypeNormal
+	}
+
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", object.Name, now.UnixNano()),
+			Namespace: object.Namespace,
+			Labels:    event.Labels,
+		},
+		InvolvedObject:      object,
+		Type:                eventType,
+		Reason:              event.Reason,
+		Message:             event.Message,
+		Source:              corev1.EventSource{Component: EventSource},
+		ReportingController: EventSource,
+		FirstTimestamp:      now,
+		LastTimestamp:       now,
+		Count:               1,
+	}
+}
+
+// RecordEvent records an event about the pod
+func (h *podHelper) RecordEvent(ctx context.Context, pod corev1.Pod, event Event) error {
+	object := corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Namespace:  h.namespace,
+		Name:       pod.Name,
+		UID:        pod.UID,
+	}
+
+	_, err := h.client.CoreV1().Events(h.namespace).Create(ctx, newEvent(object, event), metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("recording event %q for pod %q: %w", event.Reason, pod.Name, err)
+	}
+
+	return nil
+}
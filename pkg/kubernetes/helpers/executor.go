@@ -3,12 +3,17 @@ package helpers
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"net/http"
 
 	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
 	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
 )
 
 // PodCommandExecutor defines a method for executing commands in a target Pod
@@ -24,13 +29,20 @@ type PodCommandExecutor interface {
 	) ([]byte, []byte, error)
 }
 
+// PodPortForwarder defines a method for forwarding a local port to a port of a target Pod
+type PodPortForwarder interface {
+	// PortForward forwards a local port in the loopback interface to the port of the pod, returning the local
+	// port. The forwarding is stopped by calling the returned function.
+	PortForward(ctx context.Context, pod string, namespace string, port uint) (uint, func(), error)
+}
+
 type restExecutor struct {
 	client rest.Interface
 	config *rest.Config
 }
 
 // NewRestExecutor returns a PodCommandExecutor that executes command using rest client with the
-// given rest configuration
+// given rest configuration. The returned executor also implements PodPortForwarder.
 func NewRestExecutor(client rest.Interface, config *rest.Config) PodCommandExecutor {
 	return &restExecutor{
 		client: client,
@@ -79,3 +91,61 @@ func (h *restExecutor) Exec(
 
 	return stdout.Bytes(), stderr.Bytes(), err
 }
+
+func (h *restExecutor) PortForward(
+	ctx context.Context,
+	pod string,
+	namespace string,
+	port uint,
+) (uint, func(), error) {
+	req := h.client.
+		Post().
+		Namespace(namespace).
+		Resource("pods").
+		Name(pod).
+		SubResource("portforward")
+
+	transport, upgrader, err := spdy.RoundTripperFor(h.config)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopChan := make(chan struct{})
+	readyChan := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(
+		dialer,
+		[]string{"127.0.0.1"},
+		[]string{fmt.Sprintf("0:%d", port)},
+		stopChan,
+		readyChan,
+		io.Discard,
+		io.Discard,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case <-readyChan:
+	case err = <-errChan:
+		return 0, nil, fmt.Errorf("forwarding port %d: %w", port, err)
+	case <-ctx.Done():
+		close(stopChan)
+		return 0, nil, ctx.Err()
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		close(stopChan)
+		return 0, nil, err
+	}
+
+	return uint(ports[0].Local), func() { close(stopChan) }, nil
+}
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"context"
+	"fmt"
 	"sync"
 )
 
@@ -14,6 +15,13 @@ type Command struct {
 	Stdin     []byte
 }
 
+// PortForward records the forwarding of a port of a Pod
+type PortForward struct {
+	Pod       string
+	Namespace string
+	Port      uint
+}
+
 // FakePodCommandExecutor mocks the execution of a command in a pod
 // recording the command history and returning a predefined stdout, stderr, and error
 type FakePodCommandExecutor struct {
@@ -22,6 +30,9 @@ type FakePodCommandExecutor struct {
 	stdout  []byte
 	stderr  []byte
 	err     error
+	// localPort is the port returned when forwarding a port
+	localPort uint
+	forwards  []PortForward
 }
 
 // Exec records the execution of a command and returns the pre-defined
@@ -53,6 +64,37 @@ func (f *FakePodCommandExecutor) SetResult(stdout []byte, stderr []byte, err err
 	f.err = err
 }
 
+// PortForward returns the local port set with SetLocalPort, as if the port of the pod were forwarded to it
+func (f *FakePodCommandExecutor) PortForward(
+	_ context.Context,
+	pod string,
+	namespace string,
+	port uint,
+) (uint, func(), error) {
+	if f.localPort == 0 {
+		return 0, nil, fmt.Errorf("connection refused")
+	}
+
+	f.mutex.Lock()
+	f.forwards = append(f.forwards, PortForward{Pod: pod, Namespace: namespace, Port: port})
+	f.mutex.Unlock()
+
+	return f.localPort, func() {}, nil
+}
+
+// SetLocalPort sets the local port returned when forwarding a port with the FakePodCommandExecutor
+func (f *FakePodCommandExecutor) SetLocalPort(port uint) {
+	f.localPort = port
+}
+
+// GetForwards returns the ports forwarded with the FakePodCommandExecutor
+func (f *FakePodCommandExecutor) GetForwards() []PortForward {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.forwards
+}
+
 // GetHistory returns the history of commands executed by the FakePodCommandExecutor
 func (f *FakePodCommandExecutor) GetHistory() []Command {
 	return f.history
@@ -0,0 +1,208 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultFieldManager is the field manager of the objects applied by the ManifestHelper if none is given
+const DefaultFieldManager = "xk6-disruptor"
+
+// ManifestHelper defines helper methods for applying manifests
+type ManifestHelper interface {
+	// Apply applies the objects of a manifest with one or more YAML (or JSON) documents using server-side apply and
+	// returns the applied objects. If the options define a timeout, waits for the applied deployments,
+	// statefulsets and pods to be ready.
+	Apply(ctx context.Context, manifest []byte, options ApplyOptions) ([]unstructured.Unstructured, error)
+}
+
+// ApplyOptions defines the options for applying a manifest
+type ApplyOptions struct {
+	// Namespace of the namespaced objects that do not define one. If empty, the default namespace is used.
+	Namespace string
+	// FieldManager is the field manager of the applied fields. If empty, the DefaultFieldManager is used.
+	FieldManager string
+	// Timeout for the applied objects to be ready. If zero, the helper does not wait.
+	Timeout time.Duration
+}
+
+// manifestHelper holds the data required by the helpers
+type manifestHelper struct {
+	client  kubernetes.Interface
+	dynamic dynamic.Interface
+	mapper  meta.RESTMapper
+}
+
+// NewManifestHelper returns a ManifestHelper that finds the resource of the objects using the mapper
+func NewManifestHelper(
+	client kubernetes.Interface,
+	dynamicClient dynamic.Interface,
+	mapper meta.RESTMapper,
+) ManifestHelper {
+	return &manifestHelper{
+		client:  client,
+		dynamic: dynamicClient,
+		mapper:  mapper,
+	}
+}
+
+// decodeManifest returns the objects in the documents of the manifest. Empty documents are ignored.
+func decodeManifest(manifest []byte) ([]unstructured.Unstructured, error) {
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096)
+	objs := []unstructured.Unstructured{}
+	for {
+		obj := unstructured.Unstructured{}
+		err := decoder.Decode(&obj.Object)
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding manifest: %w", err)
+		}
+
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		objs = append(objs, obj)
+	}
+}
+
+// mapping returns the mapping of the kind of the object to its resource. If the kind is not found, the mapper is
+// reset (if supported) in case the kind was defined after the mapper was last refreshed (e.g. by a CRD applied in
+// the same manifest).
+func (h *manifestHelper) mapping(obj unstructured.Unstructured) (*meta.RESTMapping, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := h.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		if resettable, ok := h.mapper.(meta.ResettableRESTMapper); ok {
+			resettable.Reset()
+			mapping, err = h.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("finding resource of kind %s: %w", gvk, err)
+	}
+
+	return mapping, nil
+}
+
+// applyObject applies the object. Namespaced objects without namespace are applied in the given namespace.
+func (h *manifestHelper) applyObject(
+	ctx context.Context,
+	obj unstructured.Unstructured,
+	namespace string,
+	fieldManager string,
+) (*unstructured.Unstructured, error) {
+	mapping, err := h.mapping(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources dynamic.ResourceInterface = h.dynamic.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		if obj.GetNamespace() == "" {
+			obj.SetNamespace(namespace)
+		}
+		resources = h.dynamic.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+	}
+
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s %q: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	force := true
+	result, err := resources.Patch(
+		ctx,
+		obj.GetName(),
+		types.ApplyPatchType,
+		data,
+		metav1.PatchOptions{FieldManager: fieldManager, Force: &force},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("applying %s %q: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	return result, nil
+}
+
+func (h *manifestHelper) Apply(
+	ctx context.Context,
+	manifest []byte,
+	options ApplyOptions,
+) ([]unstructured.Unstructured, error) {
+	objs, err := decodeManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := options.Namespace
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	fieldManager := options.FieldManager
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+
+	applied := []unstructured.Unstructured{}
+	for _, obj := range objs {
+		result, applyErr := h.applyObject(ctx, obj, namespace, fieldManager)
+		if applyErr != nil {
+			return applied, applyErr
+		}
+
+		applied = append(applied, *result)
+	}
+
+	if options.Timeout == 0 {
+		return applied, nil
+	}
+
+	return applied, h.waitReady(ctx, applied, options.Timeout)
+}
+
+// waitReady waits for the deployments, statefulsets and pods in the objects to be ready
+func (h *manifestHelper) waitReady(ctx context.Context, objs []unstructured.Unstructured, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for _, obj := range objs {
+		var err error
+		switch obj.GroupVersionKind().GroupKind().String() {
+		case "Deployment.apps":
+			err = NewWorkloadHelper(h.client, obj.GetNamespace()).
+				WaitDeploymentReady(ctx, obj.GetName(), time.Until(deadline))
+		case "StatefulSet.apps":
+			err = NewWorkloadHelper(h.client, obj.GetNamespace()).
+				WaitStatefulSetReady(ctx, obj.GetName(), time.Until(deadline))
+		case "Pod":
+			var running bool
+			running, err = NewPodHelper(h.client, nil, obj.GetNamespace()).
+				WaitPodRunning(ctx, obj.GetName(), time.Until(deadline))
+			if err == nil && !running {
+				err = fmt.Errorf("pod is not running")
+			}
+		default:
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("waiting for %s %q to be ready: %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
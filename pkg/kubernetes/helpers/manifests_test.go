@@ -0,0 +1,152 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/json"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const testManifest = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: demo
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+# empty document
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+  namespace: demo
+spec:
+  ports:
+  - port: 80
+`
+
+// newApplyDynamicClient returns a fake dynamic client that emulates server-side apply by creating or replacing the
+// objects. The apply of the fake client does not support unstructured objects.
+func newApplyDynamicClient() *dynamicfake.FakeDynamicClient {
+	client := dynamicfake.NewSimpleDynamicClient(scheme.Scheme)
+	client.PrependReactor("patch", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch, _ := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(patch.GetPatch(), &obj.Object); err != nil {
+			return true, nil, err
+		}
+
+		_, err := client.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if k8serrors.IsNotFound(err) {
+			return true, obj, client.Tracker().Create(patch.GetResource(), obj, patch.GetNamespace())
+		}
+
+		return true, obj, client.Tracker().Update(patch.GetResource(), obj, patch.GetNamespace())
+	})
+
+	return client
+}
+
+func Test_ApplyManifest(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		manifest    string
+		namespace   string
+		expectError bool
+		expected    []string
+	}{
+		{
+			title:     "multiple documents",
+			manifest:  testManifest,
+			namespace: "test",
+			expected:  []string{"Namespace//demo", "ConfigMap/test/config", "Service/demo/app"},
+		},
+		{
+			title:    "default namespace",
+			manifest: testManifest,
+			expected: []string{"Namespace//demo", "ConfigMap/default/config", "Service/demo/app"},
+		},
+		{
+			title:       "unknown kind",
+			manifest:    "apiVersion: example.com/v1\nkind: Unknown\nmetadata:\n  name: unknown\n",
+			expectError: true,
+		},
+		{
+			title:       "invalid manifest",
+			manifest:    "kind: [",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			dynamicClient := newApplyDynamicClient()
+			h := NewManifestHelper(
+				fake.NewSimpleClientset(),
+				dynamicClient,
+				testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme),
+			)
+
+			applied, err := h.Apply(context.TODO(), []byte(tc.manifest), ApplyOptions{Namespace: tc.namespace})
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			objs := []string{}
+			for _, obj := range applied {
+				objs = append(objs, obj.GetKind()+"/"+obj.GetNamespace()+"/"+obj.GetName())
+			}
+
+			if diff := cmp.Diff(tc.expected, objs); diff != "" {
+				t.Fatalf("expected objects do not match applied:\n%s", diff)
+			}
+
+			// applying again updates the objects
+			_, err = h.Apply(context.TODO(), []byte(tc.manifest), ApplyOptions{Namespace: tc.namespace})
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			_, err = dynamicClient.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).
+				Namespace(applied[1].GetNamespace()).
+				Get(context.TODO(), "config", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
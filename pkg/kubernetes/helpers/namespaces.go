@@ -0,0 +1,101 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultNamespacePrefix is the prefix of the namespaces created without specifying one
+const DefaultNamespacePrefix = "xk6-disruptor"
+
+// namespaceSuffixLength is the length of the random suffix added to the prefix of the namespaces
+const namespaceSuffixLength = 5
+
+// namespaceCreateAttempts is the number of names tried for creating a namespace
+const namespaceCreateAttempts = 5
+
+// NamespaceHelper defines helper methods for handling Namespaces
+type NamespaceHelper interface {
+	// Create creates a namespace with a unique name, formed by the prefix followed by a random suffix, and the given
+	// labels. Returns the name of the namespace.
+	Create(ctx context.Context, prefix string, labels map[string]string) (string, error)
+	// Delete deletes the namespace and waits for up to the given timeout for it to be removed, once the resources
+	// it contains are deleted and its finalizers complete. Deleting a namespace that does not exist is not an error.
+	Delete(ctx context.Context, name string, timeout time.Duration) error
+}
+
+// namespaceHelper holds the data required by the helpers
+type namespaceHelper struct {
+	client kubernetes.Interface
+}
+
+// NewNamespaceHelper returns a NamespaceHelper
+func NewNamespaceHelper(client kubernetes.Interface) NamespaceHelper {
+	return &namespaceHelper{
+		client: client,
+	}
+}
+
+func (h *namespaceHelper) Create(ctx context.Context, prefix string, labels map[string]string) (string, error) {
+	if prefix == "" {
+		prefix = DefaultNamespacePrefix
+	}
+
+	// the name is generated instead of using the GenerateName of the namespace, to retry if it is already used
+	var err error
+	for range namespaceCreateAttempts {
+		namespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("%s-%s", prefix, rand.String(namespaceSuffixLength)),
+				Labels: labels,
+			},
+		}
+
+		namespace, err = h.client.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{})
+		if err == nil {
+			return namespace.Name, nil
+		}
+
+		if !errors.IsAlreadyExists(err) {
+			break
+		}
+	}
+
+	return "", fmt.Errorf("creating namespace with prefix %q: %w", prefix, err)
+}
+
+func (h *namespaceHelper) Delete(ctx context.Context, name string, timeout time.Duration) error {
+	err := h.client.CoreV1().Namespaces().Delete(ctx, name, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("deleting namespace %q: %w", name, err)
+	}
+
+	err = utils.Retry(ctx, timeout, time.Second, func() (bool, error) {
+		_, err = h.client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to access namespace: %w", err)
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for namespace %q to be deleted: %w", name, err)
+	}
+
+	return nil
+}
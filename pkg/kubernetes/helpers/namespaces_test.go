@@ -0,0 +1,160 @@
+package helpers
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_CreateNamespace(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title          string
+		prefix         string
+		labels         map[string]string
+		conflicts      int
+		expectError    bool
+		expectedPrefix string
+	}{
+		{
+			title:          "namespace with prefix and labels",
+			prefix:         "test",
+			labels:         map[string]string{"app": "test"},
+			expectError:    false,
+			expectedPrefix: "test-",
+		},
+		{
+			title:          "default prefix",
+			prefix:         "",
+			expectError:    false,
+			expectedPrefix: DefaultNamespacePrefix + "-",
+		},
+		{
+			title:          "name already used",
+			prefix:         "test",
+			conflicts:      1,
+			expectError:    false,
+			expectedPrefix: "test-",
+		},
+		{
+			title:       "all names already used",
+			prefix:      "test",
+			conflicts:   namespaceCreateAttempts,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset()
+			conflicts := 0
+			client.PrependReactor("create", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				if conflicts == tc.conflicts {
+					return false, nil, nil
+				}
+				conflicts++
+				name := action.(k8stesting.CreateAction).GetObject().(*corev1.Namespace).Name
+				return true, nil, k8serrors.NewAlreadyExists(corev1.Resource("namespaces"), name)
+			})
+
+			helper := NewNamespaceHelper(client)
+			name, err := helper.Create(context.TODO(), tc.prefix, tc.labels)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if !strings.HasPrefix(name, tc.expectedPrefix) {
+				t.Fatalf("expected name with prefix %q got %q", tc.expectedPrefix, name)
+			}
+
+			namespace, err := client.CoreV1().Namespaces().Get(context.TODO(), name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.labels, namespace.Labels); diff != "" {
+				t.Fatalf("expected labels do not match returned:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_DeleteNamespace(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		namespaces  []runtime.Object
+		finalizing  bool
+		expectError bool
+	}{
+		{
+			title: "delete namespace",
+			namespaces: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+			},
+			expectError: false,
+		},
+		{
+			title:       "namespace does not exist",
+			namespaces:  []runtime.Object{},
+			expectError: false,
+		},
+		{
+			title: "finalizers do not complete",
+			namespaces: []runtime.Object{
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+			},
+			finalizing:  true,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(tc.namespaces...)
+			if tc.finalizing {
+				// the namespace is kept while its finalizers run
+				client.PrependReactor("delete", "namespaces", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, nil
+				})
+			}
+
+			helper := NewNamespaceHelper(client)
+			err := helper.Delete(context.TODO(), "test", 100*time.Millisecond)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// mirrorPodAnnotation is the annotation set by the kubelet on the API representation of static pods
+const mirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// controlPlaneLabel is the label that identifies the control-plane nodes
+const controlPlaneLabel = "node-role.kubernetes.io/control-plane"
+
+// NodeHelper defines helper methods for handling Nodes
+type NodeHelper interface {
+	// List returns a list of nodes that match the given NodeFilter
+	List(ctx context.Context, filter NodeFilter) ([]corev1.Node, error)
+	// Cordon marks the node as unschedulable
+	Cordon(ctx context.Context, name string) error
+	// Uncordon marks the node as schedulable
+	Uncordon(ctx context.Context, name string) error
+	// Drain evicts the pods running in the node and waits until they are terminated.
+	// Pods managed by a DaemonSet and mirror pods are not evicted.
+	Drain(ctx context.Context, name string, options DrainOptions) error
+	// WaitNodeReady waits for the Node to report the Ready condition for up to the given timeout
+	WaitNodeReady(ctx context.Context, name string, timeout time.Duration) error
+	// WaitNodeNotReady waits for the Node to stop reporting the Ready condition for up to the given timeout
+	WaitNodeNotReady(ctx context.Context, name string, timeout time.Duration) error
+	// ControlPlaneAddresses returns the internal addresses of the control-plane nodes and the addresses of the
+	// API server endpoints
+	ControlPlaneAddresses(ctx context.Context) ([]string, error)
+	// AddTaint adds a taint to the node. If the node already has a taint with the same key and effect,
+	// it is replaced.
+	AddTaint(ctx context.Context, name string, taint corev1.Taint) error
+	// RemoveTaint removes the taint with the same key and effect from the node, if it exists
+	RemoveTaint(ctx context.Context, name string, taint corev1.Taint) error
+	// DeletePods deletes the pods running in the node without respecting their termination grace period
+	// nor PodDisruptionBudgets. Pods managed by a DaemonSet and mirror pods are not deleted.
+	DeletePods(ctx context.Context, name string) error
+}
+
+// NodeFilter defines the criteria for selecting a node
+type NodeFilter struct {
+	// Select Nodes that match these labels
+	Select map[string]string
+	// Exclude Nodes that match these labels
+	Exclude map[string]string
+}
+
+// DrainOptions defines options for draining a node
+type DrainOptions struct {
+	// Timeout for waiting the evicted pods to terminate
+	Timeout time.Duration
+	// GracePeriod overrides the termination grace period of the evicted pods. A zero value uses
+	// the pod's default grace period.
+	GracePeriod time.Duration
+}
+
+// nodeHelper struct holds the data required by the helpers
+type nodeHelper struct {
+	client kubernetes.Interface
+}
+
+// NewNodeHelper returns a NodeHelper
+func NewNodeHelper(client kubernetes.Interface) NodeHelper {
+	return &nodeHelper{
+		client: client,
+	}
+}
+
+func (h *nodeHelper) List(ctx context.Context, filter NodeFilter) ([]corev1.Node, error) {
+	labelSelector, err := buildLabelSelector(PodFilter{Select: filter.Select, Exclude: filter.Exclude})
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := h.client.CoreV1().Nodes().List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: labelSelector.String(),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes.Items, nil
+}
+
+// setUnschedulable updates the unschedulable attribute of a node
+func (h *nodeHelper) setUnschedulable(ctx context.Context, name string, unschedulable bool) error {
+	patch := fmt.Sprintf(`{"spec":{"unschedulable":%t}}`, unschedulable)
+	_, err := h.client.CoreV1().Nodes().Patch(
+		ctx,
+		name,
+		types.MergePatchType,
+		[]byte(patch),
+		metav1.PatchOptions{},
+	)
+	if err != nil {
+		return fmt.Errorf("patching node %q: %w", name, err)
+	}
+
+	return nil
+}
+
+func (h *nodeHelper) Cordon(ctx context.Context, name string) error {
+	return h.setUnschedulable(ctx, name, true)
+}
+
+func (h *nodeHelper) Uncordon(ctx context.Context, name string) error {
+	return h.setUnschedulable(ctx, name, false)
+}
+
+// isEvictable returns if a pod running in a node should be evicted when the node is drained
+func isEvictable(pod corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return false
+	}
+
+	if _, isMirror := pod.Annotations[mirrorPodAnnotation]; isMirror {
+		return false
+	}
+
+	for _, owner := range pod.OwnerReferences {
+		if owner.Controller != nil && *owner.Controller && owner.Kind == "DaemonSet" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// evictablePods returns the pods running in the node that can be evicted
+func (h *nodeHelper) evictablePods(ctx context.Context, name string) ([]corev1.Pod, error) {
+	pods, err := h.client.CoreV1().Pods(metav1.NamespaceAll).List(
+		ctx,
+		metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing pods in node %q: %w", name, err)
+	}
+
+	evictable := []corev1.Pod{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == name && isEvictable(pod) {
+			evictable = append(evictable, pod)
+		}
+	}
+
+	return evictable, nil
+}
+
+func (h *nodeHelper) Drain(ctx context.Context, name string, options DrainOptions) error {
+	pods, err := h.evictablePods(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	var deleteOptions *metav1.DeleteOptions
+	if options.GracePeriod > 0 {
+		gracePeriod := int64(options.GracePeriod.Seconds())
+		deleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod}
+	}
+
+	pending := pods
+	err = utils.Retry(ctx, options.Timeout, time.Second, func() (bool, error) {
+		remaining := []corev1.Pod{}
+		for _, pod := range pending {
+			eviction := &policyv1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      pod.Name,
+					Namespace: pod.Namespace,
+				},
+				DeleteOptions: deleteOptions,
+			}
+
+			err := h.client.CoreV1().Pods(pod.Namespace).EvictV1(ctx, eviction)
+			switch {
+			case err == nil || k8serrors.IsNotFound(err):
+				continue
+			case k8serrors.IsTooManyRequests(err):
+				// the eviction is not allowed at this moment (e.g. by a PodDisruptionBudget). Try again later.
+				remaining = append(remaining, pod)
+			default:
+				return false, fmt.Errorf("evicting pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+		}
+		pending = remaining
+
+		return len(pending) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("evicting pods from node %q: %w", name, err)
+	}
+
+	return h.waitPodsEvicted(ctx, name, pods, options.Timeout)
+}
+
+// waitPodsEvicted waits until the evicted pods are no longer running in the node
+func (h *nodeHelper) waitPodsEvicted(
+	ctx context.Context,
+	name string,
+	evicted []corev1.Pod,
+	timeout time.Duration,
+) error {
+	return utils.Retry(ctx, timeout, time.Second, func() (bool, error) {
+		pods, err := h.evictablePods(ctx, name)
+		if err != nil {
+			return false, err
+		}
+
+		for _, pod := range pods {
+			for _, e := range evicted {
+				if pod.UID == e.UID {
+					return false, nil
+				}
+			}
+		}
+
+		return true, nil
+	})
+}
+
+// isReady returns if the node reports the Ready condition
+func isReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+func (h *nodeHelper) WaitNodeReady(ctx context.Context, name string, timeout time.Duration) error {
+	err := h.waitNodeReadiness(ctx, name, timeout, true)
+	if err != nil {
+		return fmt.Errorf("waiting for node %q to be ready: %w", name, err)
+	}
+
+	return nil
+}
+
+func (h *nodeHelper) WaitNodeNotReady(ctx context.Context, name string, timeout time.Duration) error {
+	err := h.waitNodeReadiness(ctx, name, timeout, false)
+	if err != nil {
+		return fmt.Errorf("waiting for node %q to be not ready: %w", name, err)
+	}
+
+	return nil
+}
+
+// waitNodeReadiness waits until the Ready condition of the node matches the expected readiness
+func (h *nodeHelper) waitNodeReadiness(ctx context.Context, name string, timeout time.Duration, ready bool) error {
+	return utils.Retry(ctx, timeout, time.Second, func() (bool, error) {
+		node, err := h.client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("getting node %q: %w", name, err)
+		}
+
+		return isReady(node) == ready, nil
+	})
+}
+
+// NodeAddresses returns the internal addresses of a node
+func NodeAddresses(node corev1.Node) []string {
+	addresses := []string{}
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			addresses = append(addresses, address.Address)
+		}
+	}
+
+	return addresses
+}
+
+func (h *nodeHelper) ControlPlaneAddresses(ctx context.Context) ([]string, error) {
+	nodes, err := h.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: controlPlaneLabel})
+	if err != nil {
+		return nil, fmt.Errorf("listing control-plane nodes: %w", err)
+	}
+
+	addresses := []string{}
+	for _, node := range nodes.Items {
+		addresses = append(addresses, NodeAddresses(node)...)
+	}
+
+	// the API server may run outside the cluster's nodes (e.g. managed control-planes)
+	endpoints, err := h.client.CoreV1().Endpoints(metav1.NamespaceDefault).Get(ctx, "kubernetes", metav1.GetOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return nil, fmt.Errorf("getting API server endpoints: %w", err)
+	}
+
+	if err == nil {
+		for _, subset := range endpoints.Subsets {
+			for _, address := range subset.Addresses {
+				addresses = append(addresses, address.IP)
+			}
+		}
+	}
+
+	return addresses, nil
+}
+
+// updateTaints updates the taints of a node using the given function, retrying in case of conflicts
+func (h *nodeHelper) updateTaints(
+	ctx context.Context,
+	name string,
+	update func([]corev1.Taint) []corev1.Taint,
+) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := h.client.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		node.Spec.Taints = update(node.Spec.Taints)
+
+		_, err = h.client.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("updating taints of node %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// withoutTaint returns the taints that do not match the key and effect of the given taint
+func withoutTaint(taints []corev1.Taint, taint corev1.Taint) []corev1.Taint {
+	filtered := []corev1.Taint{}
+	for _, t := range taints {
+		if !t.MatchTaint(&taint) {
+			filtered = append(filtered, t)
+		}
+	}
+
+	return filtered
+}
+
+func (h *nodeHelper) AddTaint(ctx context.Context, name string, taint corev1.Taint) error {
+	return h.updateTaints(ctx, name, func(taints []corev1.Taint) []corev1.Taint {
+		return append(withoutTaint(taints, taint), taint)
+	})
+}
+
+func (h *nodeHelper) RemoveTaint(ctx context.Context, name string, taint corev1.Taint) error {
+	return h.updateTaints(ctx, name, func(taints []corev1.Taint) []corev1.Taint {
+		return withoutTaint(taints, taint)
+	})
+}
+
+func (h *nodeHelper) DeletePods(ctx context.Context, name string) error {
+	pods, err := h.evictablePods(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	gracePeriod := int64(0)
+	for _, pod := range pods {
+		err = h.client.CoreV1().Pods(pod.Namespace).Delete(
+			ctx,
+			pod.Name,
+			metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod},
+		)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("deleting pod %s/%s: %w", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	return nil
+}
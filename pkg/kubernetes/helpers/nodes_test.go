@@ -0,0 +1,300 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/testutils/assertions"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+)
+
+func Test_ListNodes(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title         string
+		nodes         []corev1.Node
+		filter        NodeFilter
+		expectedNodes []string
+	}{
+		{
+			title: "select nodes by label",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").WithLabel("zone", "a").Build(),
+				builders.NewNodeBuilder("node-2").WithLabel("zone", "b").Build(),
+			},
+			filter: NodeFilter{
+				Select: map[string]string{"zone": "a"},
+			},
+			expectedNodes: []string{"node-1"},
+		},
+		{
+			title: "exclude nodes by label",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").WithLabel("zone", "a").Build(),
+				builders.NewNodeBuilder("node-2").WithLabel("zone", "b").Build(),
+				builders.NewNodeBuilder("node-3").WithLabel("zone", "c").Build(),
+			},
+			filter: NodeFilter{
+				Exclude: map[string]string{"zone": "a"},
+			},
+			expectedNodes: []string{"node-2", "node-3"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			objs := []runtime.Object{}
+			for n := range tc.nodes {
+				objs = append(objs, &tc.nodes[n])
+			}
+			client := fake.NewSimpleClientset(objs...)
+
+			helper := NewNodeHelper(client)
+			nodes, err := helper.List(context.TODO(), tc.filter)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			names := []string{}
+			for _, n := range nodes {
+				names = append(names, n.Name)
+			}
+			if !assertions.CompareStringArrays(names, tc.expectedNodes) {
+				t.Errorf("result does not match expected value. Expected: %s\nActual: %s\n", tc.expectedNodes, names)
+			}
+		})
+	}
+}
+
+func Test_CordonNode(t *testing.T) {
+	t.Parallel()
+
+	node := builders.NewNodeBuilder("node-1").Build()
+	client := fake.NewSimpleClientset(&node)
+	helper := NewNodeHelper(client)
+
+	err := helper.Cordon(context.TODO(), node.Name)
+	if err != nil {
+		t.Fatalf("failed cordoning node: %v", err)
+	}
+
+	updated, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed getting node: %v", err)
+	}
+
+	if !updated.Spec.Unschedulable {
+		t.Fatalf("node should be unschedulable")
+	}
+
+	err = helper.Uncordon(context.TODO(), node.Name)
+	if err != nil {
+		t.Fatalf("failed uncordoning node: %v", err)
+	}
+
+	updated, err = client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed getting node: %v", err)
+	}
+
+	if updated.Spec.Unschedulable {
+		t.Fatalf("node should be schedulable")
+	}
+}
+
+func Test_DrainNode(t *testing.T) {
+	t.Parallel()
+
+	isController := true
+	daemonSetPod := builders.NewPodBuilder("daemonset-pod").
+		WithNamespace("test-ns").
+		WithNodeName("node-1").
+		Build()
+	daemonSetPod.OwnerReferences = []metav1.OwnerReference{
+		{Kind: "DaemonSet", Name: "daemonset", Controller: &isController},
+	}
+
+	pods := []corev1.Pod{
+		builders.NewPodBuilder("pod-1").WithNamespace("test-ns").WithNodeName("node-1").Build(),
+		builders.NewPodBuilder("pod-2").WithNamespace("other-ns").WithNodeName("node-1").Build(),
+		builders.NewPodBuilder("pod-3").WithNamespace("test-ns").WithNodeName("node-2").Build(),
+		daemonSetPod,
+	}
+
+	objs := []runtime.Object{}
+	for p := range pods {
+		objs = append(objs, &pods[p])
+	}
+	client := fake.NewSimpleClientset(objs...)
+
+	// the fake client does not delete evicted pods
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+
+		eviction, ok := action.(k8stesting.CreateAction).GetObject().(*policyv1.Eviction)
+		if !ok {
+			return false, nil, nil
+		}
+
+		err := client.Tracker().Delete(
+			corev1.SchemeGroupVersion.WithResource("pods"),
+			eviction.Namespace,
+			eviction.Name,
+		)
+
+		return true, nil, err
+	})
+
+	helper := NewNodeHelper(client)
+	err := helper.Drain(context.TODO(), "node-1", DrainOptions{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("failed draining node: %v", err)
+	}
+
+	remaining, err := client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed listing pods: %v", err)
+	}
+
+	names := []string{}
+	for _, p := range remaining.Items {
+		names = append(names, p.Name)
+	}
+
+	expected := []string{"pod-3", "daemonset-pod"}
+	if !assertions.CompareStringArrays(names, expected) {
+		t.Errorf("result does not match expected value. Expected: %s\nActual: %s\n", expected, names)
+	}
+}
+
+func Test_ControlPlaneAddresses(t *testing.T) {
+	t.Parallel()
+
+	controlPlane := builders.NewNodeBuilder("control-plane").
+		WithLabel("node-role.kubernetes.io/control-plane", "").
+		WithIP("10.0.0.1").
+		Build()
+	worker := builders.NewNodeBuilder("worker").
+		WithIP("10.0.0.2").
+		Build()
+	endpoints := corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kubernetes",
+			Namespace: metav1.NamespaceDefault,
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Addresses: []corev1.EndpointAddress{{IP: "172.16.0.1"}},
+			},
+		},
+	}
+
+	client := fake.NewSimpleClientset(&controlPlane, &worker, &endpoints)
+	helper := NewNodeHelper(client)
+
+	addresses, err := helper.ControlPlaneAddresses(context.TODO())
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	expected := []string{"10.0.0.1", "172.16.0.1"}
+	if !assertions.CompareStringArrays(addresses, expected) {
+		t.Errorf("result does not match expected value. Expected: %s\nActual: %s\n", expected, addresses)
+	}
+}
+
+func Test_TaintNode(t *testing.T) {
+	t.Parallel()
+
+	existing := corev1.Taint{Key: "existing", Effect: corev1.TaintEffectNoSchedule}
+	node := builders.NewNodeBuilder("node-1").Build()
+	node.Spec.Taints = []corev1.Taint{existing}
+
+	client := fake.NewSimpleClientset(&node)
+	helper := NewNodeHelper(client)
+
+	taint := corev1.Taint{Key: "fault", Value: "true", Effect: corev1.TaintEffectNoExecute}
+	err := helper.AddTaint(context.TODO(), node.Name, taint)
+	if err != nil {
+		t.Fatalf("failed adding taint: %v", err)
+	}
+
+	updated, err := client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed getting node: %v", err)
+	}
+
+	if len(updated.Spec.Taints) != 2 || !updated.Spec.Taints[1].MatchTaint(&taint) {
+		t.Fatalf("expected taints %v got %v", []corev1.Taint{existing, taint}, updated.Spec.Taints)
+	}
+
+	err = helper.RemoveTaint(context.TODO(), node.Name, taint)
+	if err != nil {
+		t.Fatalf("failed removing taint: %v", err)
+	}
+
+	updated, err = client.CoreV1().Nodes().Get(context.TODO(), node.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed getting node: %v", err)
+	}
+
+	if len(updated.Spec.Taints) != 1 || !updated.Spec.Taints[0].MatchTaint(&existing) {
+		t.Fatalf("expected taints %v got %v", []corev1.Taint{existing}, updated.Spec.Taints)
+	}
+}
+
+func Test_DeleteNodePods(t *testing.T) {
+	t.Parallel()
+
+	pods := []corev1.Pod{
+		builders.NewPodBuilder("pod-1").WithNamespace("test-ns").WithNodeName("node-1").Build(),
+		builders.NewPodBuilder("pod-2").WithNamespace("test-ns").WithNodeName("node-2").Build(),
+		builders.NewPodBuilder("static-pod").
+			WithNamespace("test-ns").
+			WithNodeName("node-1").
+			WithAnnotation(mirrorPodAnnotation, "hash").
+			Build(),
+	}
+
+	objs := []runtime.Object{}
+	for p := range pods {
+		objs = append(objs, &pods[p])
+	}
+	client := fake.NewSimpleClientset(objs...)
+
+	helper := NewNodeHelper(client)
+	err := helper.DeletePods(context.TODO(), "node-1")
+	if err != nil {
+		t.Fatalf("failed deleting pods: %v", err)
+	}
+
+	remaining, err := client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed listing pods: %v", err)
+	}
+
+	names := []string{}
+	for _, p := range remaining.Items {
+		names = append(names, p.Name)
+	}
+
+	expected := []string{"pod-2", "static-pod"}
+	if !assertions.CompareStringArrays(names, expected) {
+		t.Errorf("result does not match expected value. Expected: %s\nActual: %s\n", expected, names)
+	}
+}
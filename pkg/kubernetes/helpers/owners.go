@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Owner identifies a workload that owns pods
+type Owner struct {
+	// Kind of the workload: Deployment, ReplicaSet, StatefulSet, DaemonSet or Job
+	Kind string
+	// Name of the workload
+	Name string
+}
+
+// IsEmpty returns if no owner is defined
+func (o Owner) IsEmpty() bool {
+	return o.Kind == "" && o.Name == ""
+}
+
+// controllerUID returns the UID of the controller of an object, if any
+func controllerUID(meta metav1.ObjectMeta) (types.UID, bool) {
+	ref := metav1.GetControllerOfNoCopy(&meta)
+	if ref == nil {
+		return "", false
+	}
+
+	return ref.UID, true
+}
+
+// ownerUIDs returns the UIDs of the objects that directly control the pods of the owner
+func (h *podHelper) ownerUIDs(ctx context.Context, owner Owner) (map[types.UID]bool, error) {
+	if owner.Name == "" {
+		return nil, fmt.Errorf("owner name is required")
+	}
+
+	apps := h.client.AppsV1()
+
+	var uid types.UID
+	switch owner.Kind {
+	case "Deployment":
+		deployment, err := apps.Deployments(h.namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		// pods of a deployment are controlled by its replicasets
+		replicaSets, err := apps.ReplicaSets(h.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+
+		uids := map[types.UID]bool{}
+		for _, rs := range replicaSets.Items {
+			if controller, found := controllerUID(rs.ObjectMeta); found && controller == deployment.UID {
+				uids[rs.UID] = true
+			}
+		}
+
+		return uids, nil
+	case "ReplicaSet":
+		rs, err := apps.ReplicaSets(h.namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		uid = rs.UID
+	case "StatefulSet":
+		sts, err := apps.StatefulSets(h.namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		uid = sts.UID
+	case "DaemonSet":
+		ds, err := apps.DaemonSets(h.namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		uid = ds.UID
+	case "Job":
+		job, err := h.client.BatchV1().Jobs(h.namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		uid = job.UID
+	default:
+		return nil, fmt.Errorf("unsupported owner kind %q", owner.Kind)
+	}
+
+	return map[types.UID]bool{uid: true}, nil
+}
+
+// filterByOwner returns the pods that are controlled by the owner
+func (h *podHelper) filterByOwner(ctx context.Context, pods []corev1.Pod, owner Owner) ([]corev1.Pod, error) {
+	uids, err := h.ownerUIDs(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("finding %s %q: %w", owner.Kind, owner.Name, err)
+	}
+
+	owned := []corev1.Pod{}
+	for _, pod := range pods {
+		if controller, found := controllerUID(pod.ObjectMeta); found && uids[controller] {
+			owned = append(owned, pod)
+		}
+	}
+
+	return owned, nil
+}
@@ -0,0 +1,131 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/grafana/xk6-disruptor/pkg/testutils/assertions"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+)
+
+// controlledBy returns the owner references of an object controlled by the given owner
+func controlledBy(kind string, name string, uid types.UID) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{
+		{Kind: kind, Name: name, UID: uid, Controller: &isController},
+	}
+}
+
+// buildOwnedPod returns a pod controlled by the given owner
+func buildOwnedPod(name string, kind string, owner string, uid types.UID) *corev1.Pod {
+	pod := builders.NewPodBuilder(name).WithNamespace("test-ns").Build()
+	pod.OwnerReferences = controlledBy(kind, owner, uid)
+	return &pod
+}
+
+func Test_ListPodsByOwner(t *testing.T) {
+	t.Parallel()
+
+	objs := []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "checkout", Namespace: "test-ns", UID: "deployment-checkout"},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "checkout-1",
+				Namespace:       "test-ns",
+				UID:             "rs-checkout-1",
+				OwnerReferences: controlledBy("Deployment", "checkout", "deployment-checkout"),
+			},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "checkout-2",
+				Namespace:       "test-ns",
+				UID:             "rs-checkout-2",
+				OwnerReferences: controlledBy("Deployment", "checkout", "deployment-checkout"),
+			},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "cart", Namespace: "test-ns", UID: "rs-cart"},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test-ns", UID: "sts-db"},
+		},
+		buildOwnedPod("checkout-1-a", "ReplicaSet", "checkout-1", "rs-checkout-1"),
+		buildOwnedPod("checkout-2-a", "ReplicaSet", "checkout-2", "rs-checkout-2"),
+		buildOwnedPod("cart-a", "ReplicaSet", "cart", "rs-cart"),
+		buildOwnedPod("db-0", "StatefulSet", "db", "sts-db"),
+	}
+
+	testCases := []struct {
+		title        string
+		owner        Owner
+		expectError  bool
+		expectedPods []string
+	}{
+		{
+			title:        "pods of a deployment",
+			owner:        Owner{Kind: "Deployment", Name: "checkout"},
+			expectedPods: []string{"checkout-1-a", "checkout-2-a"},
+		},
+		{
+			title:        "pods of a replicaset",
+			owner:        Owner{Kind: "ReplicaSet", Name: "cart"},
+			expectedPods: []string{"cart-a"},
+		},
+		{
+			title:        "pods of a statefulset",
+			owner:        Owner{Kind: "StatefulSet", Name: "db"},
+			expectedPods: []string{"db-0"},
+		},
+		{
+			title:       "owner does not exist",
+			owner:       Owner{Kind: "Deployment", Name: "other"},
+			expectError: true,
+		},
+		{
+			title:       "unsupported kind",
+			owner:       Owner{Kind: "CronJob", Name: "checkout"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset(objs...)
+			helper := NewPodHelper(client, nil, "test-ns")
+
+			pods, err := helper.List(context.TODO(), PodFilter{Owner: tc.owner})
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			names := []string{}
+			for _, p := range pods {
+				names = append(names, p.Name)
+			}
+			if !assertions.CompareStringArrays(names, tc.expectedPods) {
+				t.Errorf("result does not match expected value. Expected: %s\nActual: %s\n", tc.expectedPods, names)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package helpers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DefaultPodCacheSyncTimeout is the default time to wait for the initial list of the pods of a namespace
+const DefaultPodCacheSyncTimeout = 30 * time.Second
+
+// PodCache keeps a local copy of the pods of the namespaces, updated by watching the API server. Listing the pods
+// from the cache does not make requests to the API server. The pods of a namespace are watched from the first time
+// they are listed. If the initial list cannot be completed (e.g. the watch is not allowed), the pods of the namespace
+// are listed from the API server.
+type PodCache struct {
+	client      kubernetes.Interface
+	resync      time.Duration
+	syncTimeout time.Duration
+	mutex       sync.Mutex
+	namespaces  map[string]*namespacePods
+}
+
+// namespacePods keeps the informer of the pods of a namespace and the subscribers to their changes
+type namespacePods struct {
+	lister    corelisters.PodLister
+	hasSynced cache.InformerSynced
+	factory   informers.SharedInformerFactory
+	stopCh    chan struct{}
+	stopOnce  sync.Once
+	synced    bool
+	// ready is closed once the initial list of the pods completes or fails
+	ready       chan struct{}
+	mutex       sync.Mutex
+	subscribers map[chan struct{}]bool
+}
+
+// NewPodCache returns a PodCache that lists and watches pods using the client. The cache is refreshed with a full
+// list of the pods at every resync period. A zero resync period disables refreshing.
+func NewPodCache(client kubernetes.Interface, resync time.Duration) *PodCache {
+	return &PodCache{
+		client:      client,
+		resync:      resync,
+		syncTimeout: DefaultPodCacheSyncTimeout,
+		namespaces:  map[string]*namespacePods{},
+	}
+}
+
+// namespace returns the pods of the namespace, starting to watch them if needed. Returns false if the pods are not
+// cached. An empty namespace watches the pods of all namespaces. The initial list of the pods is waited for without
+// holding the lock of the cache, so the pods of other namespaces can be listed meanwhile.
+func (c *PodCache) namespace(ctx context.Context, namespace string) (*namespacePods, bool) {
+	c.mutex.Lock()
+	pods, found := c.namespaces[namespace]
+	if !found {
+		var err error
+		pods, err = c.watch(namespace)
+		if err != nil {
+			c.mutex.Unlock()
+			return nil, false
+		}
+		c.namespaces[namespace] = pods
+	}
+	c.mutex.Unlock()
+
+	if !found {
+		c.sync(ctx, namespace, pods)
+	}
+
+	select {
+	case <-pods.ready:
+		return pods, pods.synced
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+// watch starts watching the pods of the namespace
+func (c *PodCache) watch(namespace string) (*namespacePods, error) {
+	factory := informers.NewSharedInformerFactoryWithOptions(c.client, c.resync, informers.WithNamespace(namespace))
+	informer := factory.Core().V1().Pods()
+	pods := &namespacePods{
+		lister:      informer.Lister(),
+		hasSynced:   informer.Informer().HasSynced,
+		factory:     factory,
+		stopCh:      make(chan struct{}),
+		ready:       make(chan struct{}),
+		subscribers: map[chan struct{}]bool{},
+	}
+
+	_, err := informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { pods.notify() },
+		UpdateFunc: func(any, any) { pods.notify() },
+		DeleteFunc: func(any) { pods.notify() },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	factory.Start(pods.stopCh)
+
+	return pods, nil
+}
+
+// sync waits for the initial list of the pods of the namespace. If it cannot be completed, the pods are listed from
+// the API server from now on, unless the caller gave up waiting, in which case the list is tried again next time.
+func (c *PodCache) sync(ctx context.Context, namespace string, pods *namespacePods) {
+	defer close(pods.ready)
+
+	syncCtx, cancel := context.WithTimeout(ctx, c.syncTimeout)
+	defer cancel()
+
+	pods.synced = cache.WaitForCacheSync(syncCtx.Done(), pods.hasSynced)
+	if pods.synced {
+		return
+	}
+
+	pods.stop()
+	if ctx.Err() != nil {
+		c.mutex.Lock()
+		delete(c.namespaces, namespace)
+		c.mutex.Unlock()
+	}
+}
+
+// List returns the pods of the namespace that match the label selector. Returns false if the pods of the namespace
+// are not cached.
+func (c *PodCache) List(ctx context.Context, namespace string, selector labels.Selector) ([]corev1.Pod, bool) {
+	pods, cached := c.namespace(ctx, namespace)
+	if !cached {
+		return nil, false
+	}
+
+	list, err := pods.lister.List(selector)
+	if err != nil {
+		return nil, false
+	}
+
+	// the pods are copied because the objects in the cache must not be modified
+	result := make([]corev1.Pod, 0, len(list))
+	for _, pod := range list {
+		result = append(result, *pod.DeepCopy())
+	}
+
+	return result, true
+}
+
+// Changes returns a channel that receives a notification when pods of the namespace are added, updated or deleted.
+// Notifications that are not received are merged into one. The subscription ends when the context is done.
+// Returns nil if the pods of the namespace are not cached.
+func (c *PodCache) Changes(ctx context.Context, namespace string) <-chan struct{} {
+	pods, cached := c.namespace(ctx, namespace)
+	if !cached {
+		return nil
+	}
+
+	changes := make(chan struct{}, 1)
+	pods.mutex.Lock()
+	pods.subscribers[changes] = true
+	pods.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		pods.mutex.Lock()
+		delete(pods.subscribers, changes)
+		pods.mutex.Unlock()
+	}()
+
+	return changes
+}
+
+// Stop stops watching the pods of all namespaces
+func (c *PodCache) Stop() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for namespace, pods := range c.namespaces {
+		pods.stop()
+		delete(c.namespaces, namespace)
+	}
+}
+
+// stop stops watching the pods
+func (p *namespacePods) stop() {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+		p.factory.Shutdown()
+	})
+}
+
+// notify notifies a change to the subscribers without blocking
+func (p *namespacePods) notify() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for subscriber := range p.subscribers {
+		select {
+		case subscriber <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package helpers
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+)
+
+func Test_CachedList(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		pods     []corev1.Pod
+		filter   PodFilter
+		expected []string
+	}{
+		{
+			title: "select labels",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").WithNamespace(testNamespace).WithLabel("app", "test").Build(),
+				builders.NewPodBuilder("pod-2").WithNamespace(testNamespace).WithLabel("app", "other").Build(),
+				builders.NewPodBuilder("pod-3").WithNamespace("other").WithLabel("app", "test").Build(),
+			},
+			filter:   PodFilter{Select: map[string]string{"app": "test"}},
+			expected: []string{"pod-1"},
+		},
+		{
+			title: "select fields",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").WithNamespace(testNamespace).WithNodeName("node-1").Build(),
+				builders.NewPodBuilder("pod-2").WithNamespace(testNamespace).WithNodeName("node-2").Build(),
+			},
+			filter:   PodFilter{SelectFields: map[string]string{"spec.nodeName": "node-2"}},
+			expected: []string{"pod-2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			objs := []runtime.Object{}
+			for i := range tc.pods {
+				objs = append(objs, &tc.pods[i])
+			}
+			client := fake.NewSimpleClientset(objs...)
+
+			cache := NewPodCache(client, 0)
+			t.Cleanup(cache.Stop)
+
+			helper := NewCachedPodHelper(client, nil, cache, RetryOptions{}, testNamespace)
+			pods, err := helper.List(context.TODO(), tc.filter)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			// once the cache is synced, the pods must be listed without requests to the API server
+			client.ClearActions()
+			pods, err = helper.List(context.TODO(), tc.filter)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "list" {
+					t.Fatalf("pods listed from the API server")
+				}
+			}
+
+			names := []string{}
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			sort.Strings(names)
+
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Fatalf("expected pods do not match returned:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_CacheNotSynced(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("pod-1").WithNamespace(testNamespace).WithNodeName("node-1").Build()
+	client := fake.NewSimpleClientset(&pod)
+
+	// listing all the pods is not allowed, so the cache never syncs. Listing the pods with a field selector (as the
+	// helper does) is allowed.
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.ListAction).GetListRestrictions().Fields.Empty() {
+			return true, nil, errors.NewForbidden(corev1.Resource("pods"), "", nil)
+		}
+		return false, nil, nil
+	})
+
+	cache := NewPodCache(client, 0)
+	cache.syncTimeout = 100 * time.Millisecond
+	t.Cleanup(cache.Stop)
+
+	helper := NewCachedPodHelper(client, nil, cache, RetryOptions{}, testNamespace)
+	pods, err := helper.List(context.TODO(), PodFilter{SelectFields: map[string]string{"spec.nodeName": "node-1"}})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if len(pods) != 1 {
+		t.Fatalf("expected 1 pod got %d", len(pods))
+	}
+
+	if changes := helper.Changes(context.TODO()); changes != nil {
+		t.Fatalf("expected no changes for pods that are not cached")
+	}
+}
+
+func Test_CacheSyncDoesNotBlockOtherNamespaces(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("pod-1").WithNamespace(testNamespace).Build()
+	client := fake.NewSimpleClientset(&pod)
+
+	// the initial list of the pods of the blocked namespace does not complete until the sync timeout expires
+	client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "blocked" {
+			return true, nil, errors.NewForbidden(corev1.Resource("pods"), "", nil)
+		}
+		return false, nil, nil
+	})
+
+	cache := NewPodCache(client, 0)
+	t.Cleanup(cache.Stop)
+
+	blockedCtx, stopBlocked := context.WithCancel(context.TODO())
+	t.Cleanup(stopBlocked)
+	go cache.List(blockedCtx, "blocked", labels.Everything())
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	pods, cached := cache.List(ctx, testNamespace, labels.Everything())
+	if !cached {
+		t.Fatalf("expected pods to be cached")
+	}
+
+	if len(pods) != 1 {
+		t.Fatalf("expected 1 pod got %d", len(pods))
+	}
+}
+
+func Test_CacheChanges(t *testing.T) {
+	t.Parallel()
+
+	client := fake.NewSimpleClientset()
+
+	cache := NewPodCache(client, 0)
+	t.Cleanup(cache.Stop)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	helper := NewCachedPodHelper(client, nil, cache, RetryOptions{}, testNamespace)
+	changes := helper.Changes(ctx)
+	if changes == nil {
+		t.Fatalf("expected changes to be watched")
+	}
+
+	pod := builders.NewPodBuilder("pod-1").WithNamespace(testNamespace).Build()
+	_, err := client.CoreV1().Pods(testNamespace).Create(context.TODO(), &pod, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("change not notified")
+	}
+}
@@ -5,6 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -13,11 +18,14 @@ import (
 
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
 )
 
 // PodHelper defines helper methods for handling Pods
@@ -29,7 +37,8 @@ type PodHelper interface {
 	WaitPodDeleted(ctx context.Context, name string, timeout time.Duration) error
 	// Exec executes a non-interactive command described in options and returns the stdout and stderr outputs
 	Exec(ctx context.Context, pod string, container string, command []string, stdin []byte) ([]byte, []byte, error)
-	// AttachEphemeralContainer adds an ephemeral container to a running pod
+	// AttachEphemeralContainer adds an ephemeral container to a running pod. If IgnoreIfExists is set, an existing
+	// container with the same name is reused once it is running.
 	AttachEphemeralContainer(
 		ctx context.Context,
 		podName string,
@@ -38,8 +47,38 @@ type PodHelper interface {
 	) error
 	// List returns a list of pods that match the given PodFilter
 	List(ctx context.Context, filter PodFilter) ([]corev1.Pod, error)
+	// Changes returns a channel that is notified when pods of the namespace change until the context is done.
+	// Returns nil if the helper does not watch the pods.
+	Changes(ctx context.Context) <-chan struct{}
 	// Terminate terminates the execution of a running Pod
 	Terminate(ctx context.Context, name string, timeout time.Duration) error
+	// CreatePod creates a pod and optionally waits for it to be running
+	CreatePod(ctx context.Context, pod corev1.Pod, options CreatePodOptions) error
+	// Logs returns the last lines of the logs of a container of the Pod. A zero value returns all the lines.
+	Logs(ctx context.Context, pod string, container string, lines int64) ([]byte, error)
+	// StreamLogs returns a stream with the logs of a container of the Pod selected by the options. The stream must
+	// be closed by the caller.
+	StreamLogs(ctx context.Context, pod string, options LogOptions) (io.ReadCloser, error)
+	// PortForward forwards a local port to the port of the pod, returning the local port. The forwarding is
+	// stopped by calling the returned function or when the context is done. Requires an executor that implements
+	// PodPortForwarder.
+	PortForward(ctx context.Context, pod string, port uint) (uint, func(), error)
+	// NodeArchitecture returns the architecture (e.g. amd64) of a node
+	NodeArchitecture(ctx context.Context, node string) (string, error)
+	// RecordEvent records an event about the Pod
+	RecordEvent(ctx context.Context, pod corev1.Pod, event Event) error
+}
+
+// LogOptions defines the logs of a container returned by the PodHelper
+type LogOptions struct {
+	// Container whose logs are returned. Can be empty if the pod has only one container.
+	Container string
+	// Since returns only the logs newer than this duration. Zero returns all the logs.
+	Since time.Duration
+	// Tail returns only this number of lines from the end of the logs. Zero returns all the lines.
+	Tail int64
+	// Follow keeps streaming the logs until the stream is closed, the context is done or the container terminates
+	Follow bool
 }
 
 // helpers struct holds the data required by the helpers
@@ -47,6 +86,10 @@ type podHelper struct {
 	client    kubernetes.Interface
 	executor  PodCommandExecutor
 	namespace string
+	// cache of the pods. If nil, pods are listed from the API server.
+	cache *PodCache
+	// retry defines how the requests that fail with transient errors are retried
+	retry RetryOptions
 }
 
 // NewPodHelper returns a PodHelper
@@ -58,27 +101,147 @@ func NewPodHelper(client kubernetes.Interface, executor PodCommandExecutor, name
 	}
 }
 
+// NewCachedPodHelper returns a PodHelper that lists the pods from the cache and retries the idempotent requests
+// that fail with transient errors
+func NewCachedPodHelper(
+	client kubernetes.Interface,
+	executor PodCommandExecutor,
+	cache *PodCache,
+	retry RetryOptions,
+	namespace string,
+) PodHelper {
+	return &podHelper{
+		client:    client,
+		namespace: namespace,
+		executor:  executor,
+		cache:     cache,
+		retry:     retry,
+	}
+}
+
 // PodFilter defines the criteria for selecting a pod for disruption
 type PodFilter struct {
 	// Select Pods that match these labels
 	Select map[string]string
 	// Select Pods that match these labels
 	Exclude map[string]string
+	// SelectFields selects Pods whose fields match these values (e.g. "spec.nodeName")
+	SelectFields map[string]string
+	// ExcludeFields excludes Pods whose fields match these values
+	ExcludeFields map[string]string
+	// SelectAnnotations selects Pods that have these annotations
+	SelectAnnotations map[string]string
+	// ExcludeAnnotations excludes Pods that have these annotations
+	ExcludeAnnotations map[string]string
+	// Owner selects Pods controlled by this workload
+	Owner Owner
+	// SelectExpressions selects Pods whose labels match all these expressions
+	SelectExpressions []LabelExpression
+	// ExcludeExpressions excludes Pods whose labels match any of these expressions
+	ExcludeExpressions []LabelExpression
+	// SelectLabelPatterns selects Pods whose labels match these regular expressions (e.g. "checkout-.*")
+	SelectLabelPatterns map[string]string
+	// ExcludeLabelPatterns excludes Pods whose labels match these regular expressions
+	ExcludeLabelPatterns map[string]string
+	// Phases selects Pods in any of these phases. If empty, Pods are selected regardless of their phase
+	Phases []corev1.PodPhase
+	// ExcludePhases excludes Pods in any of these phases
+	ExcludePhases []corev1.PodPhase
+	// ExcludeTerminating excludes Pods that are being deleted
+	ExcludeTerminating bool
+	// ExcludeNotReady excludes Pods that are not Ready
+	ExcludeNotReady bool
+	// Conditions selects Pods whose conditions have these statuses (e.g. "Ready": "False")
+	Conditions map[corev1.PodConditionType]corev1.ConditionStatus
+	// QOSClass selects Pods of this QoS class
+	QOSClass corev1.PodQOSClass
+	// MinRestarts selects Pods with a container restarted at least this number of times
+	MinRestarts int32
+	// RestartedSince selects Pods with a container restarted after this time
+	RestartedSince time.Time
+	// NodeNames selects Pods running in any of these nodes
+	NodeNames []string
+	// NodeLabels selects Pods running in nodes that match these labels
+	NodeLabels map[string]string
+	// Namespaces selects Pods in any of these namespaces
+	Namespaces []string
+	// NamespaceLabels selects Pods in namespaces that match these labels
+	NamespaceLabels map[string]string
+}
+
+// LabelExpression is a set-based requirement on the value of a label
+type LabelExpression struct {
+	// Key of the label
+	Key string
+	// Operator is one of In, NotIn, Exists and DoesNotExist
+	Operator string
+	// Values of the label for the In and NotIn operators
+	Values []string
+}
+
+// requirement returns the selector requirement of the expression. If negate is true, the requirement
+// matches the labels that do not match the expression.
+func (e LabelExpression) requirement(negate bool) (*labels.Requirement, error) {
+	var operator, negated selection.Operator
+	switch e.Operator {
+	case "In":
+		operator, negated = selection.In, selection.NotIn
+	case "NotIn":
+		operator, negated = selection.NotIn, selection.In
+	case "Exists":
+		operator, negated = selection.Exists, selection.DoesNotExist
+	case "DoesNotExist":
+		operator, negated = selection.DoesNotExist, selection.Exists
+	default:
+		return nil, fmt.Errorf("unsupported operator %q in expression for label %q", e.Operator, e.Key)
+	}
+
+	if negate {
+		operator = negated
+	}
+
+	return labels.NewRequirement(e.Key, operator, e.Values)
 }
 
 // AttachOptions defines options for attaching a container
 type AttachOptions struct {
 	// timeout for waiting until container is ready.
 	Timeout time.Duration
-	// IgnoreIfExists causes AttachEphemeralContainer to return successfully if the ephemeral container already exists
+	// IgnoreIfExists causes AttachEphemeralContainer to reuse the ephemeral container if it already exists
 	// when set to true. If set to false, it will exit with an error if the container already exists.
 	IgnoreIfExists bool
+	// Retries is the number of times attaching the container is retried if it fails with a transient error
+	// (e.g. a conflict or a timeout calling an admission webhook). If zero, the retries of the helper are used.
+	Retries int
+	// RetryBackoff is the delay before the first retry. The delay doubles on each retry. Defaults to 1s.
+	RetryBackoff time.Duration
+}
+
+// retryOptions returns the options for retrying attaching the container, or the given defaults if the attach
+// options do not define the retries
+func (o AttachOptions) retryOptions(defaults RetryOptions) RetryOptions {
+	if o.Retries == 0 {
+		return defaults
+	}
+
+	return RetryOptions{Retries: o.Retries, Backoff: o.RetryBackoff}
+}
+
+// CreatePodOptions defines options for creating a pod
+type CreatePodOptions struct {
+	// timeout for waiting until pod is running. A zero value does not wait.
+	Timeout time.Duration
+	// IgnoreIfExists causes CreatePod to reuse an existing pod with the same name when set to true.
+	// If set to false, it will exit with an error if the pod already exists.
+	IgnoreIfExists bool
 }
 
 // podConditionChecker defines a function that checks if a pod satisfies a condition
 type podConditionChecker func(*corev1.Pod) (bool, error)
 
-// waitForCondition watches a Pod in a namespace until a podConditionChecker is satisfied or a timeout expires
+// waitForCondition watches a Pod in a namespace until a podConditionChecker is satisfied or a timeout expires.
+// The pod is listed and then watched from the version listed, so no change is missed, and the watch is resumed if it
+// is closed by the API server before the condition is satisfied.
 func (h *podHelper) waitForCondition(
 	ctx context.Context,
 	namespace string,
@@ -86,57 +249,75 @@ func (h *podHelper) waitForCondition(
 	timeout time.Duration,
 	checker podConditionChecker,
 ) (bool, error) {
-	selector := fields.Set{
-		"metadata.name": name,
-	}.AsSelector()
-
-	watcher, err := h.client.CoreV1().Pods(namespace).Watch(
-		ctx,
-		metav1.ListOptions{
-			FieldSelector: selector.String(),
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return h.client.CoreV1().Pods(namespace).List(ctx, options)
+		},
+		WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return h.client.CoreV1().Pods(namespace).Watch(ctx, options)
 		},
-	)
-	if err != nil {
-		return false, fmt.Errorf("starting pod watcher: %w", err)
 	}
-	defer watcher.Stop()
 
-	// we check if the pod already satisfies the condition to prevent race conditions
-	// on which we miss the update that makes the condition true
-	pod, err := h.client.CoreV1().Pods(namespace).Get(
-		ctx,
-		name,
-		metav1.GetOptions{},
-	)
-	if err != nil {
-		return false, fmt.Errorf("getting pod: %w", err)
-	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	condition, err := checker(pod)
-	if condition || err != nil {
+	// records if the condition was satisfied, as the wait may also end by the timeout expiring
+	satisfied := false
+	check := func(pod *corev1.Pod) (bool, error) {
+		condition, err := checker(pod)
+		satisfied = condition
 		return condition, err
 	}
 
-	expired := time.After(timeout)
-	for {
-		select {
-		case <-expired:
+	// we check if the pod already satisfies the condition once it is listed
+	precondition := func(store cache.Store) (bool, error) {
+		obj, exists, err := store.GetByKey(namespace + "/" + name)
+		if err != nil {
+			return false, err
+		}
+		if !exists {
+			notFound := k8serrors.NewNotFound(corev1.Resource("pods"), name)
+			return false, fmt.Errorf("getting pod: %w", WrapAPIError(notFound))
+		}
+
+		pod, isPod := obj.(*corev1.Pod)
+		if !isPod {
+			return false, errors.New("received unknown object while watching for pods")
+		}
+
+		return check(pod)
+	}
+
+	_, err := watchtools.UntilWithSync(waitCtx, lw, &corev1.Pod{}, precondition, func(event watch.Event) (bool, error) {
+		pod, isPod := event.Object.(*corev1.Pod)
+		// pods with other names are ignored because not all clients (e.g. fake clients) support field selectors
+		if !isPod || pod.Name != name {
+			return false, nil
+		}
+
+		switch event.Type {
+		case watch.Deleted:
+			return false, fmt.Errorf("pod %q was deleted", name)
+		case watch.Added, watch.Modified:
+			return check(pod)
+		default:
 			return false, nil
-		case event := <-watcher.ResultChan():
-			if event.Type == watch.Error {
-				return false, fmt.Errorf("error watching for pod: %v", event.Object)
-			}
-			if event.Type == watch.Modified {
-				pod, isPod := event.Object.(*corev1.Pod)
-				if !isPod {
-					return false, errors.New("received unknown object while watching for pods")
-				}
-				condition, err = checker(pod)
-				if condition || err != nil {
-					return condition, err
-				}
-			}
 		}
+	})
+
+	switch {
+	case satisfied:
+		return true, nil
+	case ctx.Err() != nil:
+		return false, ctx.Err()
+	case waitCtx.Err() != nil:
+		// timeout expired
+		return false, nil
+	default:
+		return false, err
 	}
 }
 
@@ -181,40 +362,78 @@ func (h *podHelper) AttachEphemeralContainer(
 	container corev1.EphemeralContainer,
 	options AttachOptions,
 ) error {
+	// transient errors are retried until the retries are exhausted or the context is done
+	var exists bool
+	err := retryTransient(ctx, options.retryOptions(h.retry), func(ctx context.Context) error {
+		var patchErr error
+		exists, patchErr = h.patchEphemeralContainer(ctx, podName, container)
+		return patchErr
+	})
+	if err != nil {
+		return err
+	}
+
+	if exists && !options.IgnoreIfExists {
+		return fmt.Errorf("ephemeral container %s already exists", container.Name)
+	}
+
+	// an existing container is reused, but it may have not started yet or may have terminated
+	if options.Timeout == 0 {
+		return nil
+	}
+	running, err := h.waitForCondition(
+		ctx,
+		h.namespace,
+		podName,
+		options.Timeout,
+		ephemeralContainerIsRunning(container.Name),
+	)
+	if err != nil {
+		return fmt.Errorf("waiting for ephemeral container of %q to start: %w", podName, err)
+	}
+	if !running {
+		return fmt.Errorf("ephemeral container for pod %q has not started after %fs", podName, options.Timeout.Seconds())
+	}
+	return nil
+}
+
+// patchEphemeralContainer adds the ephemeral container to the pod. Returns true if the pod already has the container.
+func (h *podHelper) patchEphemeralContainer(
+	ctx context.Context,
+	podName string,
+	container corev1.EphemeralContainer,
+) (bool, error) {
 	pod, err := h.client.CoreV1().Pods(h.namespace).Get(
 		ctx,
 		podName,
 		metav1.GetOptions{},
 	)
 	if err != nil {
-		return fmt.Errorf("retrieving pod %q in %q: %w", podName, h.namespace, err)
+		return false, fmt.Errorf("retrieving pod %q in %q: %w", podName, h.namespace, WrapAPIError(err))
 	}
 
 	// check if container already exists
 	for _, c := range pod.Spec.EphemeralContainers {
 		if c.Name == container.Name {
-			if options.IgnoreIfExists {
-				return nil
-			}
-			return fmt.Errorf("ephemeral container %s already exists", container.Name)
+			return true, nil
 		}
 	}
 
 	podJSON, err := json.Marshal(pod)
 	if err != nil {
-		return fmt.Errorf("json marshalling pod %q: %w", pod.Name, err)
+		return false, fmt.Errorf("json marshalling pod %q: %w", pod.Name, err)
 	}
 
 	updatedPod := pod.DeepCopy()
 	updatedPod.Spec.EphemeralContainers = append(updatedPod.Spec.EphemeralContainers, container)
 	updateJSON, err := json.Marshal(updatedPod)
 	if err != nil {
-		return fmt.Errorf("json marshalling patched pod %q: %w", pod.Name, err)
+		return false, fmt.Errorf("json marshalling patched pod %q: %w", pod.Name, err)
 	}
 
 	patch, err := strategicpatch.CreateTwoWayMergePatch(podJSON, updateJSON, pod)
 	if err != nil {
-		return fmt.Errorf("creating ephemeral container patch for %q: %w", pod.Name, err)
+		return false, fmt.Errorf("creating ephemeral container patch for %q: %w", pod.Name, err)
 	}
 
 	_, err = h.client.CoreV1().Pods(h.namespace).Patch(
@@ -226,38 +445,61 @@ func (h *podHelper) AttachEphemeralContainer(
 		"ephemeralcontainers",
 	)
 	if err != nil {
-		return fmt.Errorf("patching ephemeral container into pod %q: %w", pod.Name, err)
+		return false, fmt.Errorf("patching ephemeral container into pod %q: %w", pod.Name, wrapEphemeralContainersError(err))
 	}
 
-	if options.Timeout == 0 {
-		return nil
-	}
-	running, err := h.waitForCondition(
-		ctx,
-		h.namespace,
-		podName,
-		options.Timeout,
-		checkEphemeralContainerIsRunning,
-	)
-	if err != nil {
-		return fmt.Errorf("waiting for ephemeral container of %q to start: %w", pod.Name, err)
-	}
-	if !running {
-		return fmt.Errorf("ephemeral container for pod %q has not started after %fs", pod.Name, options.Timeout.Seconds())
+	return false, nil
+}
+
+// isImagePullError returns if the reason of a waiting container reports that it cannot pull its image
+func isImagePullError(reason string) bool {
+	switch reason {
+	case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+		return true
+	default:
+		return false
 	}
-	return nil
 }
 
-func checkEphemeralContainerIsRunning(pod *corev1.Pod) (bool, error) {
-	if pod.Status.EphemeralContainerStatuses != nil {
+// ephemeralContainerIsRunning returns a podConditionChecker that checks if the ephemeral container is running
+func ephemeralContainerIsRunning(name string) podConditionChecker {
+	return func(pod *corev1.Pod) (bool, error) {
 		for _, cs := range pod.Status.EphemeralContainerStatuses {
+			if cs.Name != name {
+				continue
+			}
+
 			if cs.State.Running != nil {
 				return true, nil
 			}
+
+			// ephemeral containers are not restarted, therefore a terminated container cannot be reused
+			if cs.State.Terminated != nil {
+				return false, fmt.Errorf(
+					"ephemeral container %q has terminated with exit code %d: %s %s. "+
+						"Ephemeral containers cannot be restarted or removed, the pod must be restarted",
+					cs.Name,
+					cs.State.Terminated.ExitCode,
+					cs.State.Terminated.Reason,
+					cs.State.Terminated.Message,
+				)
+			}
+
+			// fail fast instead of waiting for the timeout if the image cannot be pulled
+			if cs.State.Waiting != nil && isImagePullError(cs.State.Waiting.Reason) {
+				return false, fmt.Errorf(
+					"pulling image %q of ephemeral container %q: %s %s. "+
+						"Ephemeral containers use the imagePullSecrets of the pod",
+					cs.Image,
+					cs.Name,
+					cs.State.Waiting.Reason,
+					cs.State.Waiting.Message,
+				)
+			}
 		}
-	}
 
-	return false, nil
+		return false, nil
+	}
 }
 
 // buildLabelSelector builds a label selector to be used in the k8s api, from a PodSelector
@@ -279,27 +521,405 @@ func buildLabelSelector(f PodFilter) (labels.Selector, error) {
 		labelsSelector = labelsSelector.Add(*req)
 	}
 
+	for _, expression := range f.SelectExpressions {
+		req, err := expression.requirement(false)
+		if err != nil {
+			return nil, err
+		}
+		labelsSelector = labelsSelector.Add(*req)
+	}
+
+	// label patterns are matched in the client, but the pods must have the label
+	for label := range f.SelectLabelPatterns {
+		req, err := labels.NewRequirement(label, selection.Exists, nil)
+		if err != nil {
+			return nil, err
+		}
+		labelsSelector = labelsSelector.Add(*req)
+	}
+
+	// a selector only supports the conjunction of requirements, therefore excluding pods that match any
+	// expression is expressed as requiring pods to not match each one of them
+	for _, expression := range f.ExcludeExpressions {
+		req, err := expression.requirement(true)
+		if err != nil {
+			return nil, err
+		}
+		labelsSelector = labelsSelector.Add(*req)
+	}
+
 	return labelsSelector, nil
 }
 
+// buildFieldSelector returns a field selector from the fields in the PodFilter
+func buildFieldSelector(f PodFilter) fields.Selector {
+	selectors := []fields.Selector{}
+	for field, value := range f.SelectFields {
+		selectors = append(selectors, fields.OneTermEqualSelector(field, value))
+	}
+
+	for field, value := range f.ExcludeFields {
+		selectors = append(selectors, fields.OneTermNotEqualSelector(field, value))
+	}
+
+	return fields.AndSelectors(selectors...)
+}
+
+// podFields returns the fields of a pod that can be used in a field selector.
+// These are the same fields supported by the API server.
+func podFields(pod corev1.Pod) fields.Set {
+	return fields.Set{
+		"metadata.name":            pod.Name,
+		"metadata.namespace":       pod.Namespace,
+		"spec.nodeName":            pod.Spec.NodeName,
+		"spec.restartPolicy":       string(pod.Spec.RestartPolicy),
+		"spec.schedulerName":       pod.Spec.SchedulerName,
+		"spec.serviceAccountName":  pod.Spec.ServiceAccountName,
+		"spec.hostNetwork":         fmt.Sprint(pod.Spec.HostNetwork),
+		"status.phase":             string(pod.Status.Phase),
+		"status.podIP":             pod.Status.PodIP,
+		"status.nominatedNodeName": pod.Status.NominatedNodeName,
+	}
+}
+
 func (h *podHelper) List(ctx context.Context, filter PodFilter) ([]corev1.Pod, error) {
 	labelSelector, err := buildLabelSelector(filter)
 	if err != nil {
 		return nil, err
 	}
 
-	listOptions := metav1.ListOptions{
-		LabelSelector: labelSelector.String(),
+	fieldSelector := buildFieldSelector(filter)
+
+	selectPatterns, err := compileLabelPatterns(filter.SelectLabelPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	excludePatterns, err := compileLabelPatterns(filter.ExcludeLabelPatterns)
+	if err != nil {
+		return nil, err
+	}
+
+	pods, err := h.listPods(ctx, labelSelector, fieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	// the field selector is also applied to the result because not all clients (e.g. fake clients) nor the cache
+	// support it. Annotations and label patterns are not supported by selectors so they are always matched in the
+	// client.
+	filtered := []corev1.Pod{}
+	for _, pod := range pods {
+		if !fieldSelector.Matches(podFields(pod)) || !matchAnnotations(pod, filter) || !matchStatus(pod, filter) {
+			continue
+		}
+
+		if matchLabelPatterns(pod, selectPatterns, excludePatterns) {
+			filtered = append(filtered, pod)
+		}
+	}
+
+	if len(filter.Namespaces) > 0 || len(filter.NamespaceLabels) > 0 {
+		filtered, err = h.filterByNamespace(ctx, filtered, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(filter.NodeNames) > 0 || len(filter.NodeLabels) > 0 {
+		filtered, err = h.filterByNode(ctx, filtered, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !filter.Owner.IsEmpty() {
+		return h.filterByOwner(ctx, filtered, filter.Owner)
 	}
-	pods, err := h.client.CoreV1().Pods(h.namespace).List(
+
+	return filtered, nil
+}
+
+// listPods returns the pods of the namespace that match the selectors, from the cache if available
+func (h *podHelper) listPods(
+	ctx context.Context,
+	labelSelector labels.Selector,
+	fieldSelector fields.Selector,
+) ([]corev1.Pod, error) {
+	if h.cache != nil {
+		if pods, cached := h.cache.List(ctx, h.namespace, labelSelector); cached {
+			return pods, nil
+		}
+	}
+
+	pods, err := listPodPages(
 		ctx,
-		listOptions,
+		h.client,
+		h.namespace,
+		metav1.ListOptions{
+			LabelSelector: labelSelector.String(),
+			FieldSelector: fieldSelector.String(),
+		},
+		h.retry,
 	)
 	if err != nil {
-		return nil, err
+		return nil, WrapAPIError(err)
+	}
+
+	return pods, nil
+}
+
+// listPageSize is the maximum number of pods requested in each page when listing the pods from the API server
+const listPageSize = 500
+
+// listPodPages lists the pods in pages of up to listPageSize pods, so listing the pods of large clusters does not
+// exceed the timeout of the requests. The transient errors of each page are retried. If the list expires before all
+// the pages are listed, because the pods changed too much since the first page, the pods are listed in one request.
+func listPodPages(
+	ctx context.Context,
+	client kubernetes.Interface,
+	namespace string,
+	options metav1.ListOptions,
+	retry RetryOptions,
+) ([]corev1.Pod, error) {
+	options.Limit = listPageSize
+	pods := []corev1.Pod{}
+	for {
+		var page *corev1.PodList
+		err := retryTransient(ctx, retry, func(ctx context.Context) error {
+			var listErr error
+			page, listErr = client.CoreV1().Pods(namespace).List(ctx, options)
+			return listErr
+		})
+		if k8serrors.IsResourceExpired(err) && options.Continue != "" {
+			options.Limit = 0
+			options.Continue = ""
+			pods = pods[:0]
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		pods = append(pods, page.Items...)
+		if page.Continue == "" {
+			return pods, nil
+		}
+		options.Continue = page.Continue
+	}
+}
+
+func (h *podHelper) Changes(ctx context.Context) <-chan struct{} {
+	if h.cache == nil {
+		return nil
+	}
+
+	return h.cache.Changes(ctx, h.namespace)
+}
+
+// filterByNode returns the pods running in the nodes that match the node names and labels of the filter
+func (h *podHelper) filterByNode(ctx context.Context, pods []corev1.Pod, filter PodFilter) ([]corev1.Pod, error) {
+	nodes := map[string]bool{}
+	for _, name := range filter.NodeNames {
+		nodes[name] = true
+	}
+
+	if len(filter.NodeLabels) > 0 {
+		var nodeList *corev1.NodeList
+		err := retryTransient(ctx, h.retry, func(ctx context.Context) error {
+			var listErr error
+			nodeList, listErr = h.client.CoreV1().Nodes().List(
+				ctx,
+				metav1.ListOptions{
+					LabelSelector: labels.SelectorFromSet(filter.NodeLabels).String(),
+				},
+			)
+			return listErr
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing nodes: %w", err)
+		}
+
+		labeled := map[string]bool{}
+		for _, node := range nodeList.Items {
+			// if node names are also specified, the node must match both
+			if len(filter.NodeNames) == 0 || nodes[node.Name] {
+				labeled[node.Name] = true
+			}
+		}
+		nodes = labeled
+	}
+
+	selected := []corev1.Pod{}
+	for _, pod := range pods {
+		if nodes[pod.Spec.NodeName] {
+			selected = append(selected, pod)
+		}
+	}
+
+	return selected, nil
+}
+
+// filterByNamespace returns the pods in the namespaces that match the namespace names and labels of the filter
+func (h *podHelper) filterByNamespace(ctx context.Context, pods []corev1.Pod, filter PodFilter) ([]corev1.Pod, error) {
+	namespaces := map[string]bool{}
+	for _, name := range filter.Namespaces {
+		namespaces[name] = true
+	}
+
+	if len(filter.NamespaceLabels) > 0 {
+		var namespaceList *corev1.NamespaceList
+		err := retryTransient(ctx, h.retry, func(ctx context.Context) error {
+			var listErr error
+			namespaceList, listErr = h.client.CoreV1().Namespaces().List(
+				ctx,
+				metav1.ListOptions{
+					LabelSelector: labels.SelectorFromSet(filter.NamespaceLabels).String(),
+				},
+			)
+			return listErr
+		})
+		if err != nil {
+			return nil, fmt.Errorf("listing namespaces: %w", err)
+		}
+
+		labeled := map[string]bool{}
+		for _, ns := range namespaceList.Items {
+			// if namespace names are also specified, the namespace must match both
+			if len(filter.Namespaces) == 0 || namespaces[ns.Name] {
+				labeled[ns.Name] = true
+			}
+		}
+		namespaces = labeled
+	}
+
+	selected := []corev1.Pod{}
+	for _, pod := range pods {
+		if namespaces[pod.Namespace] {
+			selected = append(selected, pod)
+		}
+	}
+
+	return selected, nil
+}
+
+// matchAnnotations returns if the pod has all the selected annotations and none of the excluded annotations
+func matchAnnotations(pod corev1.Pod, f PodFilter) bool {
+	for annotation, value := range f.SelectAnnotations {
+		if v, found := pod.Annotations[annotation]; !found || v != value {
+			return false
+		}
+	}
+
+	for annotation, value := range f.ExcludeAnnotations {
+		if v, found := pod.Annotations[annotation]; found && v == value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchStatus returns if the phase, readiness and deletion status of the pod match the filter
+func matchStatus(pod corev1.Pod, f PodFilter) bool {
+	if len(f.Phases) > 0 && !slices.Contains(f.Phases, pod.Status.Phase) {
+		return false
+	}
+
+	if slices.Contains(f.ExcludePhases, pod.Status.Phase) {
+		return false
+	}
+
+	if f.ExcludeTerminating && pod.DeletionTimestamp != nil {
+		return false
+	}
+
+	if f.ExcludeNotReady && podConditionStatus(pod, corev1.PodReady) != corev1.ConditionTrue {
+		return false
+	}
+
+	for condition, status := range f.Conditions {
+		if podConditionStatus(pod, condition) != status {
+			return false
+		}
+	}
+
+	if f.QOSClass != "" && pod.Status.QOSClass != f.QOSClass {
+		return false
+	}
+
+	return matchRestarts(pod, f)
+}
+
+// podConditionStatus returns the status of a condition of the pod. If the pod does not have the condition,
+// its status is unknown
+func podConditionStatus(pod corev1.Pod, conditionType corev1.PodConditionType) corev1.ConditionStatus {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Status
+		}
+	}
+
+	return corev1.ConditionUnknown
+}
+
+// matchRestarts returns if any container of the pod has restarted as many times and as recently as required by
+// the filter
+func matchRestarts(pod corev1.Pod, f PodFilter) bool {
+	if f.MinRestarts == 0 && f.RestartedSince.IsZero() {
+		return true
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.RestartCount == 0 || status.RestartCount < f.MinRestarts {
+			continue
+		}
+
+		if f.RestartedSince.IsZero() {
+			return true
+		}
+
+		// the container restarted when its previous instance terminated
+		terminated := status.LastTerminationState.Terminated
+		if terminated != nil && terminated.FinishedAt.After(f.RestartedSince) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compileLabelPatterns compiles the regular expressions of the label patterns.
+// Patterns must match the whole value of the label.
+func compileLabelPatterns(patterns map[string]string) (map[string]*regexp.Regexp, error) {
+	compiled := map[string]*regexp.Regexp{}
+	for label, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for label %q: %w", label, err)
+		}
+		compiled[label] = re
 	}
 
-	return pods.Items, nil
+	return compiled, nil
+}
+
+// matchLabelPatterns returns if the labels of the pod match all the selected patterns and none of the
+// excluded patterns
+func matchLabelPatterns(pod corev1.Pod, selectPatterns, excludePatterns map[string]*regexp.Regexp) bool {
+	for label, re := range selectPatterns {
+		if v, found := pod.Labels[label]; !found || !re.MatchString(v) {
+			return false
+		}
+	}
+
+	for label, re := range excludePatterns {
+		if v, found := pod.Labels[label]; found && re.MatchString(v) {
+			return false
+		}
+	}
+
+	return true
 }
 
 // WaitPodDeleted waits until a pod is deleted or a timeout expires
@@ -334,7 +954,12 @@ func (h *podHelper) WaitPodDeleted(ctx context.Context, pod string, timeout time
 		select {
 		case <-expired:
 			return fmt.Errorf("pod '%s/%s' not terminated after %fs", h.namespace, pod, timeout.Seconds())
-		case event := <-watcher.ResultChan():
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return errors.New("pod watcher closed unexpectedly")
+			}
 			if event.Type == watch.Error {
 				return fmt.Errorf("error watching for pod: %v", event.Object)
 			}
@@ -345,6 +970,104 @@ func (h *podHelper) WaitPodDeleted(ctx context.Context, pod string, timeout time
 	}
 }
 
+func (h *podHelper) Logs(ctx context.Context, pod string, container string, lines int64) ([]byte, error) {
+	options := &corev1.PodLogOptions{Container: container}
+	if lines > 0 {
+		options.TailLines = &lines
+	}
+
+	var logs []byte
+	err := retryTransient(ctx, h.retry, func(ctx context.Context) error {
+		var logsErr error
+		logs, logsErr = h.client.CoreV1().Pods(h.namespace).GetLogs(pod, options).DoRaw(ctx)
+		return logsErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting logs of container %q in pod %q: %w", container, pod, err)
+	}
+
+	return logs, nil
+}
+
+// podLogOptions returns the options for requesting the logs from the API server
+func podLogOptions(options LogOptions) *corev1.PodLogOptions {
+	logOptions := &corev1.PodLogOptions{
+		Container: options.Container,
+		Follow:    options.Follow,
+	}
+
+	if options.Tail > 0 {
+		logOptions.TailLines = &options.Tail
+	}
+
+	if options.Since > 0 {
+		seconds := int64(math.Ceil(options.Since.Seconds()))
+		logOptions.SinceSeconds = &seconds
+	}
+
+	return logOptions
+}
+
+func (h *podHelper) StreamLogs(ctx context.Context, pod string, options LogOptions) (io.ReadCloser, error) {
+	stream, err := h.client.CoreV1().Pods(h.namespace).GetLogs(pod, podLogOptions(options)).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("streaming logs of container %q in pod %q: %w", options.Container, pod, err)
+	}
+
+	return stream, nil
+}
+
+// NodeArchitecture returns the architecture of the node from its label or, if not labeled, from its node info
+func (h *podHelper) NodeArchitecture(ctx context.Context, node string) (string, error) {
+	var n *corev1.Node
+	err := retryTransient(ctx, h.retry, func(ctx context.Context) error {
+		var getErr error
+		n, getErr = h.client.CoreV1().Nodes().Get(ctx, node, metav1.GetOptions{})
+		return getErr
+	})
+	if err != nil {
+		return "", fmt.Errorf("getting node %q: %w", node, WrapAPIError(err))
+	}
+
+	if arch := n.Labels[corev1.LabelArchStable]; arch != "" {
+		return arch, nil
+	}
+
+	return n.Status.NodeInfo.Architecture, nil
+}
+
+// PortForward forwards a local port to the port of the pod
+func (h *podHelper) PortForward(ctx context.Context, pod string, port uint) (uint, func(), error) {
+	forwarder, ok := h.executor.(PodPortForwarder)
+	if !ok {
+		return 0, nil, fmt.Errorf("port forwarding is not supported by the executor")
+	}
+
+	localPort, stop, err := forwarder.PortForward(ctx, pod, h.namespace, port)
+	if err != nil {
+		return 0, nil, fmt.Errorf("forwarding port %d of pod %q: %w", port, pod, err)
+	}
+
+	once := sync.Once{}
+	stopped := make(chan struct{})
+	stopOnce := func() {
+		once.Do(func() {
+			close(stopped)
+			stop()
+		})
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			stopOnce()
+		case <-stopped:
+		}
+	}()
+
+	return localPort, stopOnce, nil
+}
+
 // Terminate terminates a running Pod
 func (h *podHelper) Terminate(ctx context.Context, pod string, timeout time.Duration) error {
 	err := h.client.CoreV1().Pods(h.namespace).Delete(ctx, pod, metav1.DeleteOptions{})
@@ -354,3 +1077,25 @@ func (h *podHelper) Terminate(ctx context.Context, pod string, timeout time.Dura
 
 	return h.WaitPodDeleted(ctx, pod, timeout)
 }
+
+// CreatePod creates a Pod and waits for it to be running
+func (h *podHelper) CreatePod(ctx context.Context, pod corev1.Pod, options CreatePodOptions) error {
+	_, err := h.client.CoreV1().Pods(h.namespace).Create(ctx, &pod, metav1.CreateOptions{})
+	if err != nil && !(options.IgnoreIfExists && k8serrors.IsAlreadyExists(err)) {
+		return fmt.Errorf("creating pod %q in %q: %w", pod.Name, h.namespace, err)
+	}
+
+	if options.Timeout == 0 {
+		return nil
+	}
+
+	running, err := h.WaitPodRunning(ctx, pod.Name, options.Timeout)
+	if err != nil {
+		return fmt.Errorf("waiting for pod %q to start: %w", pod.Name, err)
+	}
+	if !running {
+		return fmt.Errorf("pod %q has not started after %fs", pod.Name, options.Timeout.Seconds())
+	}
+
+	return nil
+}
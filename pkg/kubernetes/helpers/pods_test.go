@@ -2,14 +2,19 @@ package helpers
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/grafana/xk6-disruptor/pkg/testutils/assertions"
 	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
@@ -30,6 +35,7 @@ func TestPods_Wait(t *testing.T) {
 		expectError    bool
 		expectedResult bool
 		timeout        time.Duration
+		cancelAfter    time.Duration
 	}
 
 	testCases := []TestCase{
@@ -60,6 +66,16 @@ func TestPods_Wait(t *testing.T) {
 			expectedResult: false,
 			timeout:        5 * time.Second,
 		},
+		{
+			test:           "context cancelled waiting pod running",
+			name:           "pod-running",
+			phase:          corev1.PodRunning,
+			delay:          10 * time.Second,
+			expectError:    true,
+			expectedResult: false,
+			timeout:        5 * time.Second,
+			cancelAfter:    1 * time.Second,
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -94,9 +110,16 @@ func TestPods_Wait(t *testing.T) {
 				return
 			}
 
+			waitCtx := context.TODO()
+			if tc.cancelAfter > 0 {
+				var cancelWait context.CancelFunc
+				waitCtx, cancelWait = context.WithTimeout(waitCtx, tc.cancelAfter)
+				defer cancelWait()
+			}
+
 			h := NewPodHelper(client, nil, testNamespace)
 			result, err := h.WaitPodRunning(
-				context.TODO(),
+				waitCtx,
 				tc.name,
 				tc.timeout,
 			)
@@ -124,11 +147,11 @@ func TestPods_AddEphemeralContainer(t *testing.T) {
 		test        string
 		podName     string
 		expectError bool
+		existing    bool
 		status      corev1.ContainerStatus
 		options     AttachOptions
 	}
 
-	// TODO: check injecting agent when it is already present in the pod
 	testCases := []TestCase{
 		{
 			test:        "Create ephemeral container not waiting",
@@ -175,6 +198,71 @@ func TestPods_AddEphemeralContainer(t *testing.T) {
 				IgnoreIfExists: true,
 			},
 		},
+		{
+			test:        "Fail pulling image",
+			podName:     "test-pod",
+			expectError: true,
+			status: corev1.ContainerStatus{
+				Name: "ephemeral",
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{
+						Reason: "ImagePullBackOff",
+					},
+				},
+			},
+			options: AttachOptions{
+				Timeout:        1 * time.Second,
+				IgnoreIfExists: true,
+			},
+		},
+		{
+			test:        "Reuse running ephemeral container",
+			podName:     "test-pod",
+			expectError: false,
+			existing:    true,
+			status: corev1.ContainerStatus{
+				Name: "ephemeral",
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{},
+				},
+			},
+			options: AttachOptions{
+				Timeout:        1 * time.Second,
+				IgnoreIfExists: true,
+			},
+		},
+		{
+			test:        "Fail reusing terminated ephemeral container",
+			podName:     "test-pod",
+			expectError: true,
+			existing:    true,
+			status: corev1.ContainerStatus{
+				Name: "ephemeral",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Reason: "Error"},
+				},
+			},
+			options: AttachOptions{
+				Timeout:        1 * time.Second,
+				IgnoreIfExists: true,
+			},
+		},
+		{
+			test:        "Fail if ephemeral container exists",
+			podName:     "test-pod",
+			expectError: true,
+			existing:    true,
+			status: corev1.ContainerStatus{
+				Name: "ephemeral",
+				State: corev1.ContainerState{
+					Running: &corev1.ContainerStateRunning{},
+				},
+			},
+			options: AttachOptions{
+				Timeout:        1 * time.Second,
+				IgnoreIfExists: false,
+			},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -182,9 +270,17 @@ func TestPods_AddEphemeralContainer(t *testing.T) {
 		t.Run(tc.test, func(t *testing.T) {
 			t.Parallel()
 
+			container := corev1.EphemeralContainer{
+				EphemeralContainerCommon: corev1.EphemeralContainerCommon{Name: "ephemeral"},
+			}
+
 			pod := builders.NewPodBuilder(tc.podName).
 				WithNamespace(testNamespace).
 				Build()
+			if tc.existing {
+				pod.Spec.EphemeralContainers = []corev1.EphemeralContainer{container}
+				pod.Status.EphemeralContainerStatuses = []corev1.ContainerStatus{tc.status}
+			}
 
 			// wait for pod to updated with ephemeral container and update status
 			observer := func(event builders.ObjectEvent, pod *corev1.Pod) (*corev1.Pod, bool, error) {
@@ -216,13 +312,237 @@ func TestPods_AddEphemeralContainer(t *testing.T) {
 			err = h.AttachEphemeralContainer(
 				context.TODO(),
 				tc.podName,
-				corev1.EphemeralContainer{},
+				container,
 				tc.options,
 			)
 			if !tc.expectError && err != nil {
 				t.Errorf("failed: %v", err)
 				return
 			}
+			if tc.expectError && err == nil {
+				t.Errorf("should had failed")
+				return
+			}
+		})
+	}
+}
+
+func TestPods_AttachEphemeralContainerRetries(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		test        string
+		failures    int
+		err         error
+		retries     int
+		expectError bool
+	}{
+		{
+			test:        "no failures",
+			failures:    0,
+			retries:     0,
+			expectError: false,
+		},
+		{
+			test:        "transient failures retried",
+			failures:    2,
+			err:         errors.NewConflict(corev1.Resource("pods"), "test-pod", nil),
+			retries:     2,
+			expectError: false,
+		},
+		{
+			test:        "retries exhausted",
+			failures:    3,
+			err:         errors.NewInternalError(fmt.Errorf("failed calling webhook")),
+			retries:     2,
+			expectError: true,
+		},
+		{
+			test:        "permanent failure not retried",
+			failures:    1,
+			err:         errors.NewForbidden(corev1.Resource("pods"), "test-pod", nil),
+			retries:     2,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.test, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("test-pod").WithNamespace(testNamespace).Build()
+			client := fake.NewSimpleClientset(&pod)
+
+			failures := 0
+			client.PrependReactor("patch", "pods", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+				if failures < tc.failures {
+					failures++
+					return true, nil, tc.err
+				}
+				return false, nil, nil
+			})
+
+			h := NewPodHelper(client, nil, testNamespace)
+			err := h.AttachEphemeralContainer(
+				context.TODO(),
+				"test-pod",
+				corev1.EphemeralContainer{},
+				AttachOptions{Retries: tc.retries, RetryBackoff: time.Millisecond},
+			)
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+		})
+	}
+}
+
+func TestPods_ListRetries(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		test        string
+		failures    int
+		err         error
+		retry       RetryOptions
+		expectError bool
+	}{
+		{
+			test:        "transient failures retried",
+			failures:    2,
+			err:         errors.NewTooManyRequests("too many requests", 0),
+			retry:       RetryOptions{Retries: 2, Backoff: time.Millisecond},
+			expectError: false,
+		},
+		{
+			test:        "retries disabled",
+			failures:    1,
+			err:         errors.NewServiceUnavailable("unavailable"),
+			retry:       RetryOptions{},
+			expectError: true,
+		},
+		{
+			test:        "permanent failure not retried",
+			failures:    1,
+			err:         errors.NewForbidden(corev1.Resource("pods"), "", nil),
+			retry:       RetryOptions{Retries: 2, Backoff: time.Millisecond},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.test, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("test-pod").WithNamespace(testNamespace).Build()
+			client := fake.NewSimpleClientset(&pod)
+
+			failures := 0
+			client.PrependReactor("list", "pods", func(_ k8stesting.Action) (bool, runtime.Object, error) {
+				if failures < tc.failures {
+					failures++
+					return true, nil, tc.err
+				}
+				return false, nil, nil
+			})
+
+			h := NewCachedPodHelper(client, nil, nil, tc.retry, testNamespace)
+			pods, err := h.List(context.TODO(), PodFilter{})
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+			if !tc.expectError && len(pods) != 1 {
+				t.Fatalf("expected 1 pod got %d", len(pods))
+			}
+		})
+	}
+}
+
+func Test_ListPodPages(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		test             string
+		pods             int
+		expire           bool
+		expectedRequests int
+	}{
+		{
+			test:             "single page",
+			pods:             listPageSize - 1,
+			expectedRequests: 1,
+		},
+		{
+			test:             "multiple pages",
+			pods:             2*listPageSize + 1,
+			expectedRequests: 3,
+		},
+		{
+			test:             "list expired",
+			pods:             2*listPageSize + 1,
+			expire:           true,
+			expectedRequests: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.test, func(t *testing.T) {
+			t.Parallel()
+
+			all := []corev1.Pod{}
+			for i := range tc.pods {
+				all = append(all, builders.NewPodBuilder(fmt.Sprintf("pod-%d", i)).WithNamespace(testNamespace).Build())
+			}
+
+			// the reactor returns the pages from the index of the first pod of the page, passed as continue token
+			client := fake.NewSimpleClientset()
+			requests := 0
+			client.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				requests++
+				options := action.(k8stesting.ListActionImpl).GetListOptions()
+				if options.Limit == 0 {
+					return true, &corev1.PodList{Items: all}, nil
+				}
+
+				start := 0
+				if options.Continue != "" {
+					if tc.expire {
+						return true, nil, errors.NewResourceExpired("continue token expired")
+					}
+					start, _ = strconv.Atoi(options.Continue)
+				}
+
+				end := min(start+int(options.Limit), len(all))
+				page := &corev1.PodList{Items: all[start:end]}
+				if end < len(all) {
+					page.Continue = strconv.Itoa(end)
+				}
+				return true, page, nil
+			})
+
+			pods, err := listPodPages(context.TODO(), client, testNamespace, metav1.ListOptions{}, RetryOptions{})
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if len(pods) != tc.pods {
+				t.Fatalf("expected %d pods got %d", tc.pods, len(pods))
+			}
+
+			if requests != tc.expectedRequests {
+				t.Fatalf("expected %d requests got %d", tc.expectedRequests, requests)
+			}
 		})
 	}
 }
@@ -233,6 +553,8 @@ func Test_ListPods(t *testing.T) {
 	testCases := []struct {
 		title        string
 		pods         []corev1.Pod
+		nodes        []corev1.Node
+		namespaces   []corev1.Namespace
 		namespace    string
 		filter       PodFilter
 		expectError  bool
@@ -393,6 +715,419 @@ func Test_ListPods(t *testing.T) {
 				"another-pod-in-test-ns",
 			},
 		},
+		{
+			title:     "select fields",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-in-node-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithNodeName("node-1").
+					Build(),
+				builders.NewPodBuilder("pod-in-node-2").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithNodeName("node-2").
+					Build(),
+			},
+			filter: PodFilter{
+				Select: map[string]string{
+					"app": "test",
+				},
+				SelectFields: map[string]string{
+					"spec.nodeName": "node-1",
+				},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"pod-in-node-1",
+			},
+		},
+		{
+			title:     "exclude fields",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("running-pod").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodRunning).
+					Build(),
+				builders.NewPodBuilder("pending-pod").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodPending).
+					Build(),
+			},
+			filter: PodFilter{
+				ExcludeFields: map[string]string{
+					"status.phase": "Pending",
+				},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"running-pod",
+			},
+		}, {
+			title:     "select annotations",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("allowed-pod").
+					WithNamespace("test-ns").
+					WithAnnotation("chaos.example.com/allowed", "true").
+					Build(),
+				builders.NewPodBuilder("not-allowed-pod").
+					WithNamespace("test-ns").
+					WithAnnotation("chaos.example.com/allowed", "false").
+					Build(),
+				builders.NewPodBuilder("pod-without-annotation").
+					WithNamespace("test-ns").
+					Build(),
+			},
+			filter: PodFilter{
+				SelectAnnotations: map[string]string{
+					"chaos.example.com/allowed": "true",
+				},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"allowed-pod",
+			},
+		},
+		{
+			title:     "exclude annotations",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("allowed-pod").
+					WithNamespace("test-ns").
+					WithAnnotation("chaos.example.com/allowed", "true").
+					Build(),
+				builders.NewPodBuilder("not-allowed-pod").
+					WithNamespace("test-ns").
+					WithAnnotation("chaos.example.com/allowed", "false").
+					Build(),
+				builders.NewPodBuilder("pod-without-annotation").
+					WithNamespace("test-ns").
+					Build(),
+			},
+			filter: PodFilter{
+				ExcludeAnnotations: map[string]string{
+					"chaos.example.com/allowed": "false",
+				},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"allowed-pod",
+				"pod-without-annotation",
+			},
+		}, {
+			title:     "select label expressions",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("dev-pod").
+					WithNamespace("test-ns").
+					WithLabel("env", "dev").
+					Build(),
+				builders.NewPodBuilder("staging-pod").
+					WithNamespace("test-ns").
+					WithLabel("env", "staging").
+					Build(),
+				builders.NewPodBuilder("prod-pod").
+					WithNamespace("test-ns").
+					WithLabel("env", "prod").
+					Build(),
+			},
+			filter: PodFilter{
+				SelectExpressions: []LabelExpression{
+					{Key: "env", Operator: "In", Values: []string{"dev", "staging"}},
+				},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"dev-pod",
+				"staging-pod",
+			},
+		},
+		{
+			title:     "exclude label expressions",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("canary-pod").
+					WithNamespace("test-ns").
+					WithLabel("canary", "true").
+					Build(),
+				builders.NewPodBuilder("stable-pod").
+					WithNamespace("test-ns").
+					Build(),
+			},
+			filter: PodFilter{
+				ExcludeExpressions: []LabelExpression{
+					{Key: "canary", Operator: "Exists"},
+				},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"stable-pod",
+			},
+		},
+		{
+			title:     "select node names",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-in-node-1").
+					WithNamespace("test-ns").
+					WithNodeName("node-1").
+					Build(),
+				builders.NewPodBuilder("pod-in-node-2").
+					WithNamespace("test-ns").
+					WithNodeName("node-2").
+					Build(),
+				builders.NewPodBuilder("pod-in-node-3").
+					WithNamespace("test-ns").
+					WithNodeName("node-3").
+					Build(),
+			},
+			filter: PodFilter{
+				NodeNames: []string{"node-1", "node-3"},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"pod-in-node-1",
+				"pod-in-node-3",
+			},
+		},
+		{
+			title:     "select node labels",
+			namespace: "test-ns",
+			nodes: []corev1.Node{
+				builders.NewNodeBuilder("node-1").WithLabel("topology.kubernetes.io/zone", "a").Build(),
+				builders.NewNodeBuilder("node-2").WithLabel("topology.kubernetes.io/zone", "b").Build(),
+			},
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-in-zone-a").
+					WithNamespace("test-ns").
+					WithNodeName("node-1").
+					Build(),
+				builders.NewPodBuilder("pod-in-zone-b").
+					WithNamespace("test-ns").
+					WithNodeName("node-2").
+					Build(),
+			},
+			filter: PodFilter{
+				NodeLabels: map[string]string{"topology.kubernetes.io/zone": "a"},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"pod-in-zone-a",
+			},
+		},
+		{
+			title:     "select namespaces",
+			namespace: metav1.NamespaceAll,
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-in-ns-1").
+					WithNamespace("ns-1").
+					Build(),
+				builders.NewPodBuilder("pod-in-ns-2").
+					WithNamespace("ns-2").
+					Build(),
+				builders.NewPodBuilder("pod-in-ns-3").
+					WithNamespace("ns-3").
+					Build(),
+			},
+			filter: PodFilter{
+				Namespaces: []string{"ns-1", "ns-3"},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"pod-in-ns-1",
+				"pod-in-ns-3",
+			},
+		},
+		{
+			title:     "select namespace labels",
+			namespace: metav1.NamespaceAll,
+			namespaces: []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "ns-1", Labels: map[string]string{"team": "checkout"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "ns-2", Labels: map[string]string{"team": "payments"}}},
+			},
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-in-ns-1").
+					WithNamespace("ns-1").
+					Build(),
+				builders.NewPodBuilder("pod-in-ns-2").
+					WithNamespace("ns-2").
+					Build(),
+			},
+			filter: PodFilter{
+				NamespaceLabels: map[string]string{"team": "checkout"},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"pod-in-ns-1",
+			},
+		},
+		{
+			title:     "select label patterns",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("checkout-blue").
+					WithNamespace("test-ns").
+					WithLabel("app", "checkout-blue").
+					Build(),
+				builders.NewPodBuilder("checkout-pr-123").
+					WithNamespace("test-ns").
+					WithLabel("app", "checkout-pr-123").
+					Build(),
+				builders.NewPodBuilder("payments").
+					WithNamespace("test-ns").
+					WithLabel("app", "payments-checkout-1").
+					Build(),
+				builders.NewPodBuilder("unlabeled").
+					WithNamespace("test-ns").
+					Build(),
+			},
+			filter: PodFilter{
+				SelectLabelPatterns:  map[string]string{"app": "checkout-.*"},
+				ExcludeLabelPatterns: map[string]string{"app": "checkout-pr-[0-9]+"},
+			},
+			expectError: false,
+			expectedPods: []string{
+				"checkout-blue",
+			},
+		},
+		{
+			title:     "invalid label pattern",
+			namespace: "test-ns",
+			pods:      []corev1.Pod{},
+			filter: PodFilter{
+				SelectLabelPatterns: map[string]string{"app": "checkout-(.*"},
+			},
+			expectError: true,
+		},
+		{
+			title:     "exclude phases and terminating pods",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("running").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodRunning).
+					Build(),
+				builders.NewPodBuilder("succeeded").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodSucceeded).
+					Build(),
+				builders.NewPodBuilder("terminating").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodRunning).
+					WithTerminating().
+					Build(),
+			},
+			filter: PodFilter{
+				ExcludePhases:      []corev1.PodPhase{corev1.PodSucceeded, corev1.PodFailed},
+				ExcludeTerminating: true,
+			},
+			expectError: false,
+			expectedPods: []string{
+				"running",
+			},
+		},
+		{
+			title:     "select phases and ready pods",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("ready").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodRunning).
+					WithReady(true).
+					Build(),
+				builders.NewPodBuilder("not-ready").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodRunning).
+					WithReady(false).
+					Build(),
+				builders.NewPodBuilder("pending").
+					WithNamespace("test-ns").
+					WithPhase(corev1.PodPending).
+					Build(),
+			},
+			filter: PodFilter{
+				Phases:          []corev1.PodPhase{corev1.PodRunning},
+				ExcludeNotReady: true,
+			},
+			expectError: false,
+			expectedPods: []string{
+				"ready",
+			},
+		},
+		{
+			title:     "select conditions and QoS class",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("unschedulable").
+					WithNamespace("test-ns").
+					WithCondition(corev1.PodScheduled, corev1.ConditionFalse).
+					WithQOSClass(corev1.PodQOSBestEffort).
+					Build(),
+				builders.NewPodBuilder("guaranteed").
+					WithNamespace("test-ns").
+					WithCondition(corev1.PodScheduled, corev1.ConditionFalse).
+					WithQOSClass(corev1.PodQOSGuaranteed).
+					Build(),
+				builders.NewPodBuilder("scheduled").
+					WithNamespace("test-ns").
+					WithCondition(corev1.PodScheduled, corev1.ConditionTrue).
+					WithQOSClass(corev1.PodQOSBestEffort).
+					Build(),
+			},
+			filter: PodFilter{
+				Conditions: map[corev1.PodConditionType]corev1.ConditionStatus{
+					corev1.PodScheduled: corev1.ConditionFalse,
+				},
+				QOSClass: corev1.PodQOSBestEffort,
+			},
+			expectError: false,
+			expectedPods: []string{
+				"unschedulable",
+			},
+		},
+		{
+			title:     "select restarted pods",
+			namespace: "test-ns",
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("restarted-recently").
+					WithNamespace("test-ns").
+					WithRestarts(3, time.Now().Add(-time.Minute)).
+					Build(),
+				builders.NewPodBuilder("restarted-long-ago").
+					WithNamespace("test-ns").
+					WithRestarts(3, time.Now().Add(-time.Hour)).
+					Build(),
+				builders.NewPodBuilder("restarted-once").
+					WithNamespace("test-ns").
+					WithRestarts(1, time.Now().Add(-time.Minute)).
+					Build(),
+				builders.NewPodBuilder("not-restarted").
+					WithNamespace("test-ns").
+					Build(),
+			},
+			filter: PodFilter{
+				MinRestarts:    2,
+				RestartedSince: time.Now().Add(-10 * time.Minute),
+			},
+			expectError: false,
+			expectedPods: []string{
+				"restarted-recently",
+			},
+		},
+		{
+			title:     "invalid label expression operator",
+			namespace: "test-ns",
+			pods:      []corev1.Pod{},
+			filter: PodFilter{
+				SelectExpressions: []LabelExpression{
+					{Key: "env", Operator: "Equals", Values: []string{"dev"}},
+				},
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -401,11 +1136,17 @@ func Test_ListPods(t *testing.T) {
 		t.Run(tc.title, func(t *testing.T) {
 			t.Parallel()
 
-			pods := []runtime.Object{}
+			objs := []runtime.Object{}
 			for p := range tc.pods {
-				pods = append(pods, &tc.pods[p])
+				objs = append(objs, &tc.pods[p])
 			}
-			client := fake.NewSimpleClientset(pods...)
+			for n := range tc.nodes {
+				objs = append(objs, &tc.nodes[n])
+			}
+			for n := range tc.namespaces {
+				objs = append(objs, &tc.namespaces[n])
+			}
+			client := fake.NewSimpleClientset(objs...)
 
 			helper := NewPodHelper(client, nil, tc.namespace)
 			podList, err := helper.List(context.TODO(), tc.filter)
@@ -551,3 +1292,111 @@ func Test_WaitPodDeleted(t *testing.T) {
 		})
 	}
 }
+
+func TestPods_Logs(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("pod-1").WithNamespace(testNamespace).Build()
+	client := fake.NewSimpleClientset(&pod)
+	h := NewPodHelper(client, nil, testNamespace)
+
+	logs, err := h.Logs(context.TODO(), "pod-1", "xk6-agent", 10)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	// the fake client returns the same logs for every container
+	if string(logs) != "fake logs" {
+		t.Fatalf("expected fake logs got %q", string(logs))
+	}
+}
+
+func TestPods_StreamLogs(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("pod-1").WithNamespace(testNamespace).Build()
+	client := fake.NewSimpleClientset(&pod)
+	h := NewPodHelper(client, nil, testNamespace)
+
+	stream, err := h.StreamLogs(context.TODO(), "pod-1", LogOptions{Container: "main", Tail: 10})
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+	defer stream.Close() //nolint:errcheck
+
+	logs, err := io.ReadAll(stream)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if string(logs) != "fake logs" {
+		t.Fatalf("expected fake logs got %q", string(logs))
+	}
+}
+
+func Test_PodLogOptions(t *testing.T) {
+	t.Parallel()
+
+	tail := int64(10)
+	since := int64(2)
+
+	testCases := []struct {
+		title    string
+		options  LogOptions
+		expected *corev1.PodLogOptions
+	}{
+		{
+			title:    "all logs",
+			options:  LogOptions{Container: "main"},
+			expected: &corev1.PodLogOptions{Container: "main"},
+		},
+		{
+			title:    "tail and follow",
+			options:  LogOptions{Container: "main", Tail: 10, Follow: true},
+			expected: &corev1.PodLogOptions{Container: "main", TailLines: &tail, Follow: true},
+		},
+		{
+			title:    "since rounded up to seconds",
+			options:  LogOptions{Since: 1500 * time.Millisecond},
+			expected: &corev1.PodLogOptions{SinceSeconds: &since},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			if diff := cmp.Diff(tc.expected, podLogOptions(tc.options)); diff != "" {
+				t.Fatalf("expected log options do not match returned:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPods_NodeArchitecture(t *testing.T) {
+	t.Parallel()
+
+	labeled := builders.NewNodeBuilder("node-1").WithLabel("kubernetes.io/arch", "arm64").Build()
+	unlabeled := builders.NewNodeBuilder("node-2").Build()
+	unlabeled.Status.NodeInfo.Architecture = "amd64"
+
+	client := fake.NewSimpleClientset(&labeled, &unlabeled)
+	h := NewPodHelper(client, nil, testNamespace)
+
+	for node, expected := range map[string]string{"node-1": "arm64", "node-2": "amd64"} {
+		arch, err := h.NodeArchitecture(context.TODO(), node)
+		if err != nil {
+			t.Fatalf("failed: %v", err)
+		}
+
+		if arch != expected {
+			t.Fatalf("expected architecture %q of node %q got %q", expected, node, arch)
+		}
+	}
+
+	_, err := h.NodeArchitecture(context.TODO(), "node-3")
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+}
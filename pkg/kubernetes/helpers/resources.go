@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// ResourceHelper defines helper methods for handling resources of a kind as unstructured objects. It allows
+// handling custom resources (e.g. Istio VirtualServices) without their typed clients.
+type ResourceHelper interface {
+	// Get returns the resource with the given name
+	Get(ctx context.Context, name string) (*unstructured.Unstructured, error)
+	// List returns the resources that match the labels. If no labels are given, all the resources are returned.
+	List(ctx context.Context, selector map[string]string) ([]unstructured.Unstructured, error)
+	// Patch applies the patch of the given type (e.g. types.MergePatchType) to the resource and returns the patched
+	// resource
+	Patch(ctx context.Context, name string, patchType types.PatchType, patch []byte) (*unstructured.Unstructured, error)
+}
+
+// resourceHelper holds the data required by the helpers
+type resourceHelper struct {
+	client    dynamic.Interface
+	resource  schema.GroupVersionResource
+	namespace string
+}
+
+// NewResourceHelper returns a ResourceHelper for the resources of the given namespace. For cluster scoped resources,
+// the namespace must be empty. For namespaced resources, an empty namespace lists the resources of all namespaces.
+func NewResourceHelper(
+	client dynamic.Interface,
+	resource schema.GroupVersionResource,
+	namespace string,
+) ResourceHelper {
+	return &resourceHelper{
+		client:    client,
+		resource:  resource,
+		namespace: namespace,
+	}
+}
+
+// resources returns the client for the resources of the namespace
+func (h *resourceHelper) resources() dynamic.ResourceInterface {
+	if h.namespace == "" {
+		return h.client.Resource(h.resource)
+	}
+
+	return h.client.Resource(h.resource).Namespace(h.namespace)
+}
+
+func (h *resourceHelper) Get(ctx context.Context, name string) (*unstructured.Unstructured, error) {
+	obj, err := h.resources().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting %s %q: %w", h.resource.Resource, name, err)
+	}
+
+	return obj, nil
+}
+
+func (h *resourceHelper) List(ctx context.Context, selector map[string]string) ([]unstructured.Unstructured, error) {
+	list, err := h.resources().List(
+		ctx,
+		metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(selector).String(),
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", h.resource.Resource, err)
+	}
+
+	return list.Items, nil
+}
+
+func (h *resourceHelper) Patch(
+	ctx context.Context,
+	name string,
+	patchType types.PatchType,
+	patch []byte,
+) (*unstructured.Unstructured, error) {
+	obj, err := h.resources().Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("patching %s %q: %w", h.resource.Resource, name, err)
+	}
+
+	return obj, nil
+}
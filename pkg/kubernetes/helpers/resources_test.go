@@ -0,0 +1,135 @@
+package helpers
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func virtualServices() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "networking.istio.io",
+		Version:  "v1beta1",
+		Resource: "virtualservices",
+	}
+}
+
+func buildVirtualService(name string, namespace string, labels map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("networking.istio.io/v1beta1")
+	obj.SetKind("VirtualService")
+	obj.SetName(name)
+	obj.SetNamespace(namespace)
+	obj.SetLabels(labels)
+
+	return obj
+}
+
+func newFakeDynamicClient(objs ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{virtualServices(): "VirtualServiceList"},
+		objs...,
+	)
+}
+
+func Test_ResourceList(t *testing.T) {
+	t.Parallel()
+
+	objs := []runtime.Object{
+		buildVirtualService("vs-1", testNamespace, map[string]string{"app": "test"}),
+		buildVirtualService("vs-2", testNamespace, map[string]string{"app": "other"}),
+		buildVirtualService("vs-3", "other", map[string]string{"app": "test"}),
+	}
+
+	testCases := []struct {
+		title     string
+		namespace string
+		selector  map[string]string
+		expected  []string
+	}{
+		{
+			title:     "all in namespace",
+			namespace: testNamespace,
+			expected:  []string{"vs-1", "vs-2"},
+		},
+		{
+			title:     "select labels",
+			namespace: testNamespace,
+			selector:  map[string]string{"app": "test"},
+			expected:  []string{"vs-1"},
+		},
+		{
+			title:     "all namespaces",
+			namespace: "",
+			selector:  map[string]string{"app": "test"},
+			expected:  []string{"vs-1", "vs-3"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			h := NewResourceHelper(newFakeDynamicClient(objs...), virtualServices(), tc.namespace)
+			list, err := h.List(context.TODO(), tc.selector)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			names := []string{}
+			for _, obj := range list {
+				names = append(names, obj.GetName())
+			}
+			sort.Strings(names)
+
+			if diff := cmp.Diff(tc.expected, names); diff != "" {
+				t.Fatalf("expected resources do not match returned:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ResourcePatch(t *testing.T) {
+	t.Parallel()
+
+	client := newFakeDynamicClient(buildVirtualService("vs-1", testNamespace, nil))
+	h := NewResourceHelper(client, virtualServices(), testNamespace)
+
+	_, err := h.Patch(
+		context.TODO(),
+		"vs-1",
+		types.MergePatchType,
+		[]byte(`{"spec":{"hosts":["reviews"]}}`),
+	)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	obj, err := h.Get(context.TODO(), "vs-1")
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	hosts, _, err := unstructured.NestedStringSlice(obj.Object, "spec", "hosts")
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"reviews"}, hosts); diff != "" {
+		t.Fatalf("expected hosts do not match patched:\n%s", diff)
+	}
+
+	_, err = h.Get(context.TODO(), "vs-2")
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+}
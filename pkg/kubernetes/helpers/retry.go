@@ -0,0 +1,65 @@
+package helpers
+
+import (
+	"context"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// defaultRetryBackoff is the delay before the first retry of a request
+const defaultRetryBackoff = time.Second
+
+// RetryOptions defines how the requests to the API server that fail with a transient error (e.g. too many requests,
+// a conflict or the API server being unavailable) are retried
+type RetryOptions struct {
+	// Retries is the number of times a request is retried. Zero disables the retries.
+	Retries int
+	// Backoff is the delay before the first retry. The delay doubles on each retry. Defaults to 1s.
+	Backoff time.Duration
+}
+
+// backoff returns the backoff for retrying the requests
+func (o RetryOptions) backoff() wait.Backoff {
+	duration := o.Backoff
+	if duration == 0 {
+		duration = defaultRetryBackoff
+	}
+
+	return wait.Backoff{
+		Duration: duration,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    o.Retries + 1,
+	}
+}
+
+// isTransientError returns if the error of a request to the API server may not happen if the request is retried
+func isTransientError(err error) bool {
+	return k8serrors.IsConflict(err) ||
+		k8serrors.IsServerTimeout(err) ||
+		k8serrors.IsTimeout(err) ||
+		k8serrors.IsTooManyRequests(err) ||
+		k8serrors.IsInternalError(err) ||
+		k8serrors.IsServiceUnavailable(err)
+}
+
+// retryTransient calls the request until it succeeds, fails with an error that is not transient, the retries are
+// exhausted or the context is done. Returns the error of the last call, if any. The request must be idempotent.
+func retryTransient(ctx context.Context, options RetryOptions, request func(context.Context) error) error {
+	var requestErr error
+	err := wait.ExponentialBackoffWithContext(ctx, options.backoff(), func(ctx context.Context) (bool, error) {
+		requestErr = request(ctx)
+		if requestErr != nil && !isTransientError(requestErr) {
+			return false, requestErr
+		}
+
+		return requestErr == nil, nil
+	})
+	if wait.Interrupted(err) && requestErr != nil {
+		return requestErr
+	}
+
+	return err
+}
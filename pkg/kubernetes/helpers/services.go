@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
 	"github.com/grafana/xk6-disruptor/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -21,24 +22,53 @@ type ServiceHelper interface {
 	WaitIngressReady(ctx context.Context, ingress string, timeout time.Duration) error
 	// GetTargets returns the list of pods that match the service selector criteria
 	GetTargets(ctx context.Context, service string) ([]corev1.Pod, error)
+	// PortForward forwards a local port to the target port of a running pod of the service that corresponds to the
+	// given service port, returning the local port. If the port is zero, the service must expose only one port. The
+	// forwarding is stopped by calling the returned function or when the context is done. Requires an executor that
+	// implements PodPortForwarder.
+	PortForward(ctx context.Context, service string, port uint) (uint, func(), error)
 }
 
 // helpers struct holds the data required by the helpers
 type serviceHelper struct {
 	client    kubernetes.Interface
+	executor  PodCommandExecutor
 	namespace string
+	// cache of the pods. If nil, pods are listed from the API server.
+	cache *PodCache
+	// retry defines how the requests that fail with transient errors are retried
+	retry RetryOptions
 }
 
 // NewServiceHelper returns a ServiceHelper
-func NewServiceHelper(client kubernetes.Interface, namespace string) ServiceHelper {
+func NewServiceHelper(client kubernetes.Interface, executor PodCommandExecutor, namespace string) ServiceHelper {
 	return &serviceHelper{
 		client:    client,
+		executor:  executor,
 		namespace: namespace,
 	}
 }
 
+// NewCachedServiceHelper returns a ServiceHelper that lists the pods of the services from the cache and retries the
+// idempotent requests that fail with transient errors
+func NewCachedServiceHelper(
+	client kubernetes.Interface,
+	executor PodCommandExecutor,
+	cache *PodCache,
+	retry RetryOptions,
+	namespace string,
+) ServiceHelper {
+	return &serviceHelper{
+		client:    client,
+		executor:  executor,
+		namespace: namespace,
+		cache:     cache,
+		retry:     retry,
+	}
+}
+
 func (h *serviceHelper) WaitServiceReady(ctx context.Context, service string, timeout time.Duration) error {
-	return utils.Retry(timeout, time.Second, func() (bool, error) {
+	return utils.Retry(ctx, timeout, time.Second, func() (bool, error) {
 		ep, err := h.client.CoreV1().Endpoints(h.namespace).Get(ctx, service, metav1.GetOptions{})
 		if err != nil {
 			if errors.IsNotFound(err) {
@@ -58,7 +88,7 @@ func (h *serviceHelper) WaitServiceReady(ctx context.Context, service string, ti
 }
 
 func (h *serviceHelper) WaitIngressReady(ctx context.Context, name string, timeout time.Duration) error {
-	return utils.Retry(timeout, time.Second, func() (bool, error) {
+	return utils.Retry(ctx, timeout, time.Second, func() (bool, error) {
 		ingress, err := h.client.NetworkingV1().Ingresses(h.namespace).Get(ctx, name, metav1.GetOptions{})
 		if err != nil {
 			if errors.IsNotFound(err) {
@@ -73,19 +103,75 @@ func (h *serviceHelper) WaitIngressReady(ctx context.Context, name string, timeo
 	})
 }
 
+// getService returns the service, retrying the transient errors
+func (h *serviceHelper) getService(ctx context.Context, name string) (*corev1.Service, error) {
+	var service *corev1.Service
+	err := retryTransient(ctx, h.retry, func(ctx context.Context) error {
+		var getErr error
+		service, getErr = h.client.CoreV1().Services(h.namespace).Get(ctx, name, metav1.GetOptions{})
+		return getErr
+	})
+
+	return service, err
+}
+
 func (h *serviceHelper) GetTargets(ctx context.Context, name string) ([]corev1.Pod, error) {
-	service, err := h.client.CoreV1().Services(h.namespace).Get(ctx, name, metav1.GetOptions{})
+	service, err := h.getService(ctx, name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve target service %s: %w", service, err)
+		return nil, fmt.Errorf("failed to retrieve target service %s: %w", name, WrapAPIError(err))
+	}
+
+	selector := labels.SelectorFromSet(service.Spec.Selector)
+	if h.cache != nil {
+		if pods, cached := h.cache.List(ctx, h.namespace, selector); cached {
+			return pods, nil
+		}
 	}
 
 	listOptions := metav1.ListOptions{
-		LabelSelector: labels.SelectorFromSet(service.Spec.Selector).String(),
+		LabelSelector: selector.String(),
+	}
+	pods, err := listPodPages(ctx, h.client, h.namespace, listOptions, h.retry)
+	if err != nil {
+		return nil, WrapAPIError(err)
+	}
+
+	return pods, nil
+}
+
+func (h *serviceHelper) PortForward(ctx context.Context, name string, port uint) (uint, func(), error) {
+	service, err := h.getService(ctx, name)
+	if err != nil {
+		return 0, nil, fmt.Errorf("getting service %q: %w", name, WrapAPIError(err))
+	}
+
+	servicePort := intstr.NullValue
+	if port > 0 {
+		servicePort = intstr.FromInt32(int32(port))
+	}
+
+	targetPort, err := utils.GetTargetPort(*service, servicePort)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	targets, err := h.GetTargets(ctx, name)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for _, pod := range targets {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		podPort, portErr := utils.FindPort(targetPort, pod)
+		if portErr != nil {
+			return 0, nil, portErr
+		}
+
+		return NewPodHelper(h.client, h.executor, h.namespace).PortForward(ctx, pod.Name, uint(podPort.Int32()))
 	}
-	pods, err := h.client.CoreV1().Pods(h.namespace).List(
-		ctx,
-		listOptions,
-	)
 
-	return pods.Items, err
+	return 0, nil, fmt.Errorf("service %q has no running pods", name)
 }
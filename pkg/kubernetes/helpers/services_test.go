@@ -5,11 +5,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/grafana/xk6-disruptor/pkg/testutils/assertions"
 	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
 	corev1 "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes/fake"
 )
@@ -132,7 +134,7 @@ func Test_WaitServiceReady(t *testing.T) {
 				}
 			}(tc)
 
-			h := NewServiceHelper(client, "default")
+			h := NewServiceHelper(client, nil, "default")
 
 			err := h.WaitServiceReady(context.TODO(), "service", tc.timeout)
 			if !tc.expectError && err != nil {
@@ -230,7 +232,7 @@ func Test_WaitIngressReady(t *testing.T) {
 				}
 			}(tc)
 
-			h := NewServiceHelper(client, "default")
+			h := NewServiceHelper(client, nil, "default")
 
 			err := h.WaitIngressReady(context.TODO(), "ingress", tc.timeout)
 			if !tc.expectError && err != nil {
@@ -325,7 +327,7 @@ func Test_Targets(t *testing.T) {
 				}
 			}
 
-			helper := NewServiceHelper(client, tc.namespace)
+			helper := NewServiceHelper(client, nil, tc.namespace)
 			targets, err := helper.GetTargets(context.TODO(), tc.serviceName)
 			if !tc.expectError && err != nil {
 				t.Errorf("failed: %v", err)
@@ -357,3 +359,138 @@ func Test_Targets(t *testing.T) {
 		})
 	}
 }
+
+func Test_ServicePortForward(t *testing.T) {
+	t.Parallel()
+
+	container := builders.NewContainerBuilder("main").WithPort("http", 8080).WithPort("metrics", 9090).Build()
+
+	testCases := []struct {
+		title       string
+		service     corev1.Service
+		pods        []corev1.Pod
+		port        uint
+		expectError bool
+		expected    []PortForward
+	}{
+		{
+			title: "forward service port",
+			service: builders.NewServiceBuilder("test-svc").
+				WithNamespace("test-ns").
+				WithSelectorLabel("app", "test").
+				WithPort("http", 80, intstr.FromString("http")).
+				WithPort("metrics", 9000, intstr.FromInt(9090)).
+				Build(),
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithPhase(corev1.PodPending).
+					WithContainer(container).
+					Build(),
+				builders.NewPodBuilder("pod-2").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithPhase(corev1.PodRunning).
+					WithContainer(container).
+					Build(),
+			},
+			port:     9000,
+			expected: []PortForward{{Pod: "pod-2", Namespace: "test-ns", Port: 9090}},
+		},
+		{
+			title: "default service port",
+			service: builders.NewServiceBuilder("test-svc").
+				WithNamespace("test-ns").
+				WithSelectorLabel("app", "test").
+				WithPort("http", 80, intstr.FromString("http")).
+				Build(),
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithPhase(corev1.PodRunning).
+					WithContainer(container).
+					Build(),
+			},
+			port:     0,
+			expected: []PortForward{{Pod: "pod-1", Namespace: "test-ns", Port: 8080}},
+		},
+		{
+			title: "port not exposed",
+			service: builders.NewServiceBuilder("test-svc").
+				WithNamespace("test-ns").
+				WithSelectorLabel("app", "test").
+				WithPort("http", 80, intstr.FromString("http")).
+				Build(),
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithPhase(corev1.PodRunning).
+					WithContainer(container).
+					Build(),
+			},
+			port:        8080,
+			expectError: true,
+		},
+		{
+			title: "no running pods",
+			service: builders.NewServiceBuilder("test-svc").
+				WithNamespace("test-ns").
+				WithSelectorLabel("app", "test").
+				WithPort("http", 80, intstr.FromString("http")).
+				Build(),
+			pods: []corev1.Pod{
+				builders.NewPodBuilder("pod-1").
+					WithNamespace("test-ns").
+					WithLabel("app", "test").
+					WithPhase(corev1.PodPending).
+					WithContainer(container).
+					Build(),
+			},
+			port:        80,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			objs := []runtime.Object{&tc.service}
+			for i := range tc.pods {
+				objs = append(objs, &tc.pods[i])
+			}
+			client := fake.NewSimpleClientset(objs...)
+
+			executor := NewFakePodCommandExecutor()
+			executor.SetLocalPort(30000)
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+
+			helper := NewServiceHelper(client, executor, "test-ns")
+			localPort, stop, err := helper.PortForward(ctx, "test-svc", tc.port)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+			defer stop()
+
+			if localPort != 30000 {
+				t.Fatalf("expected local port 30000 got %d", localPort)
+			}
+
+			if diff := cmp.Diff(tc.expected, executor.GetForwards()); diff != "" {
+				t.Fatalf("expected forwarded ports do not match:\n%s", diff)
+			}
+		})
+	}
+}
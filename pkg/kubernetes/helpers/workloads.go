@@ -0,0 +1,231 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/xk6-disruptor/pkg/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+// WorkloadHelper implements functions for dealing with workloads
+type WorkloadHelper interface {
+	// WaitDeploymentReady waits for the rollout of the deployment to complete and all its replicas to be available
+	WaitDeploymentReady(ctx context.Context, name string, timeout time.Duration) error
+	// WaitStatefulSetReady waits for the rollout of the statefulset to complete and all its replicas to be ready
+	WaitStatefulSetReady(ctx context.Context, name string, timeout time.Duration) error
+	// RunJob creates the job, waits up to the timeout for it to complete or fail and returns the result of its
+	// last pod
+	RunJob(ctx context.Context, job batchv1.Job, timeout time.Duration) (JobResult, error)
+}
+
+// JobResult describes the result of a job
+type JobResult struct {
+	// Succeeded indicates if the job completed successfully
+	Succeeded bool
+	// Pod is the name of the last pod of the job. Empty if the job did not create pods.
+	Pod string
+	// ExitCode of the first container of the last pod
+	ExitCode int32
+	// Logs of the first container of the last pod
+	Logs string
+}
+
+// workloadHelper holds the data required by the helpers
+type workloadHelper struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+// NewWorkloadHelper returns a WorkloadHelper
+func NewWorkloadHelper(client kubernetes.Interface, namespace string) WorkloadHelper {
+	return &workloadHelper{
+		client:    client,
+		namespace: namespace,
+	}
+}
+
+func (h *workloadHelper) WaitDeploymentReady(ctx context.Context, name string, timeout time.Duration) error {
+	return utils.Retry(ctx, timeout, time.Second, func() (bool, error) {
+		deployment, err := h.client.AppsV1().Deployments(h.namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to access deployment: %w", err)
+		}
+
+		return deploymentReady(deployment)
+	})
+}
+
+func (h *workloadHelper) WaitStatefulSetReady(ctx context.Context, name string, timeout time.Duration) error {
+	return utils.Retry(ctx, timeout, time.Second, func() (bool, error) {
+		sts, err := h.client.AppsV1().StatefulSets(h.namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to access statefulset: %w", err)
+		}
+
+		return statefulSetReady(sts), nil
+	})
+}
+
+// deploymentReady returns if the rollout of the deployment is complete. Returns an error if the rollout exceeded its
+// progress deadline.
+func deploymentReady(deployment *appsv1.Deployment) (bool, error) {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false, nil
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == "ProgressDeadlineExceeded" {
+			return false, fmt.Errorf("deployment %q exceeded its progress deadline", deployment.Name)
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	status := deployment.Status
+	// all the replicas must be updated, the old replicas terminated and the updated replicas available
+	return status.UpdatedReplicas >= replicas &&
+		status.Replicas <= status.UpdatedReplicas &&
+		status.AvailableReplicas >= status.UpdatedReplicas, nil
+}
+
+// statefulSetReady returns if the rollout of the statefulset is complete. For partitioned rolling updates, only the
+// replicas from the partition on must be updated.
+func statefulSetReady(sts *appsv1.StatefulSet) bool {
+	if sts.Status.ObservedGeneration < sts.Generation {
+		return false
+	}
+
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+
+	if sts.Status.ReadyReplicas < replicas {
+		return false
+	}
+
+	if sts.Spec.UpdateStrategy.Type == appsv1.RollingUpdateStatefulSetStrategyType &&
+		sts.Spec.UpdateStrategy.RollingUpdate != nil &&
+		sts.Spec.UpdateStrategy.RollingUpdate.Partition != nil &&
+		*sts.Spec.UpdateStrategy.RollingUpdate.Partition > 0 {
+		return sts.Status.UpdatedReplicas >= replicas-*sts.Spec.UpdateStrategy.RollingUpdate.Partition
+	}
+
+	return sts.Status.UpdateRevision == sts.Status.CurrentRevision
+}
+
+// jobFinished returns if the job has completed or failed
+func jobFinished(job *batchv1.Job) (finished bool, succeeded bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+
+		//nolint:exhaustive
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, true
+		case batchv1.JobFailed:
+			return true, false
+		}
+	}
+
+	return false, false
+}
+
+// lastJobPod returns the last pod created by the job
+func (h *workloadHelper) lastJobPod(ctx context.Context, job *batchv1.Job) (*corev1.Pod, error) {
+	selector := labels.SelectorFromSet(labels.Set{"job-name": job.Name})
+	if job.Spec.Selector != nil {
+		jobSelector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+		if err != nil {
+			return nil, err
+		}
+		selector = jobSelector
+	}
+
+	pods, err := h.client.CoreV1().Pods(h.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("listing pods of job %q: %w", job.Name, err)
+	}
+
+	var last *corev1.Pod
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if last == nil || last.CreationTimestamp.Before(&pod.CreationTimestamp) {
+			last = pod
+		}
+	}
+
+	return last, nil
+}
+
+func (h *workloadHelper) RunJob(ctx context.Context, job batchv1.Job, timeout time.Duration) (JobResult, error) {
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	created, err := h.client.BatchV1().Jobs(h.namespace).Create(ctx, &job, metav1.CreateOptions{})
+	if err != nil {
+		return JobResult{}, fmt.Errorf("creating job %q: %w", job.Name, err)
+	}
+
+	result := JobResult{}
+	err = utils.Retry(ctx, timeout, time.Second, func() (bool, error) {
+		current, getErr := h.client.BatchV1().Jobs(h.namespace).Get(ctx, created.Name, metav1.GetOptions{})
+		if getErr != nil {
+			return false, fmt.Errorf("failed to access job: %w", getErr)
+		}
+
+		var finished bool
+		finished, result.Succeeded = jobFinished(current)
+		created = current
+
+		return finished, nil
+	})
+	if err != nil {
+		return JobResult{}, fmt.Errorf("waiting for job %q to complete: %w", created.Name, err)
+	}
+
+	pod, err := h.lastJobPod(ctx, created)
+	if err != nil || pod == nil {
+		return result, err
+	}
+
+	result.Pod = pod.Name
+	if len(pod.Status.ContainerStatuses) > 0 && pod.Status.ContainerStatuses[0].State.Terminated != nil {
+		result.ExitCode = pod.Status.ContainerStatuses[0].State.Terminated.ExitCode
+	}
+
+	container := ""
+	if len(pod.Spec.Containers) > 0 {
+		container = pod.Spec.Containers[0].Name
+	}
+
+	logs, err := NewPodHelper(h.client, nil, h.namespace).Logs(ctx, pod.Name, container, 0)
+	if err != nil {
+		return result, err
+	}
+	result.Logs = string(logs)
+
+	return result, nil
+}
@@ -0,0 +1,312 @@
+package helpers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+)
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func Test_DeploymentReady(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		deployment  appsv1.Deployment
+		expected    bool
+		expectError bool
+	}{
+		{
+			title: "all replicas available",
+			deployment: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 2,
+					Replicas:           2,
+					UpdatedReplicas:    2,
+					AvailableReplicas:  2,
+				},
+			},
+			expected: true,
+		},
+		{
+			title: "generation not observed",
+			deployment: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					ObservedGeneration: 1,
+					Replicas:           2,
+					UpdatedReplicas:    2,
+					AvailableReplicas:  2,
+				},
+			},
+			expected: false,
+		},
+		{
+			title: "replicas not updated",
+			deployment: appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					Replicas:          2,
+					UpdatedReplicas:   1,
+					AvailableReplicas: 2,
+				},
+			},
+			expected: false,
+		},
+		{
+			title: "old replicas pending termination",
+			deployment: appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					Replicas:          3,
+					UpdatedReplicas:   2,
+					AvailableReplicas: 3,
+				},
+			},
+			expected: false,
+		},
+		{
+			title: "updated replicas not available",
+			deployment: appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					Replicas:          2,
+					UpdatedReplicas:   2,
+					AvailableReplicas: 1,
+				},
+			},
+			expected: false,
+		},
+		{
+			title: "progress deadline exceeded",
+			deployment: appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+				Status: appsv1.DeploymentStatus{
+					Replicas:          2,
+					UpdatedReplicas:   1,
+					AvailableReplicas: 1,
+					Conditions: []appsv1.DeploymentCondition{
+						{
+							Type:   appsv1.DeploymentProgressing,
+							Status: corev1.ConditionFalse,
+							Reason: "ProgressDeadlineExceeded",
+						},
+					},
+				},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			ready, err := deploymentReady(&tc.deployment)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if ready != tc.expected {
+				t.Fatalf("expected ready to be %t", tc.expected)
+			}
+		})
+	}
+}
+
+func Test_StatefulSetReady(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title    string
+		sts      appsv1.StatefulSet
+		expected bool
+	}{
+		{
+			title: "all replicas ready and updated",
+			sts: appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+				Status: appsv1.StatefulSetStatus{
+					ReadyReplicas:   3,
+					UpdatedReplicas: 3,
+					CurrentRevision: "rev-2",
+					UpdateRevision:  "rev-2",
+				},
+			},
+			expected: true,
+		},
+		{
+			title: "replicas not ready",
+			sts: appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+				Status: appsv1.StatefulSetStatus{
+					ReadyReplicas:   2,
+					CurrentRevision: "rev-2",
+					UpdateRevision:  "rev-2",
+				},
+			},
+			expected: false,
+		},
+		{
+			title: "update in progress",
+			sts: appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+				Status: appsv1.StatefulSetStatus{
+					ReadyReplicas:   3,
+					UpdatedReplicas: 1,
+					CurrentRevision: "rev-1",
+					UpdateRevision:  "rev-2",
+				},
+			},
+			expected: false,
+		},
+		{
+			title: "partition updated",
+			sts: appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas: int32Ptr(3),
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+						Type: appsv1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &appsv1.RollingUpdateStatefulSetStrategy{
+							Partition: int32Ptr(2),
+						},
+					},
+				},
+				Status: appsv1.StatefulSetStatus{
+					ReadyReplicas:   3,
+					UpdatedReplicas: 1,
+					CurrentRevision: "rev-1",
+					UpdateRevision:  "rev-2",
+				},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			if ready := statefulSetReady(&tc.sts); ready != tc.expected {
+				t.Fatalf("expected ready to be %t", tc.expected)
+			}
+		})
+	}
+}
+
+func Test_WaitDeploymentReady(t *testing.T) {
+	t.Parallel()
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: testNamespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(1)},
+		Status: appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 1,
+		},
+	}
+	client := fake.NewSimpleClientset(deployment)
+
+	h := NewWorkloadHelper(client, testNamespace)
+	err := h.WaitDeploymentReady(context.TODO(), "app", 5*time.Second)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	err = h.WaitStatefulSetReady(ctx, "app", 5*time.Second)
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+}
+
+func Test_RunJob(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title     string
+		condition batchv1.JobConditionType
+		exitCode  int32
+		expected  JobResult
+	}{
+		{
+			title:     "job completed",
+			condition: batchv1.JobComplete,
+			exitCode:  0,
+			expected:  JobResult{Succeeded: true, Pod: "job-pod", ExitCode: 0, Logs: "fake logs"},
+		},
+		{
+			title:     "job failed",
+			condition: batchv1.JobFailed,
+			exitCode:  1,
+			expected:  JobResult{Succeeded: false, Pod: "job-pod", ExitCode: 1, Logs: "fake logs"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			pod := builders.NewPodBuilder("job-pod").
+				WithNamespace(testNamespace).
+				WithLabel("job-name", "job").
+				WithContainer(builders.NewContainerBuilder("main").Build()).
+				Build()
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+				{
+					Name:  "main",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: tc.exitCode}},
+				},
+			}
+
+			client := fake.NewSimpleClientset(&pod)
+			// the job finishes as soon as it is created
+			client.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				job, _ := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+				job.Status.Conditions = []batchv1.JobCondition{{Type: tc.condition, Status: corev1.ConditionTrue}}
+				return false, nil, nil
+			})
+
+			job := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: testNamespace}}
+
+			h := NewWorkloadHelper(client, testNamespace)
+			result, err := h.RunJob(context.TODO(), job, 5*time.Second)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, result); diff != "" {
+				t.Fatalf("expected result does not match returned:\n%s", diff)
+			}
+		})
+	}
+}
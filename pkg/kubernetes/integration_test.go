@@ -58,12 +58,7 @@ func Test_Kubernetes(t *testing.T) {
 		t.Fatalf("failed to get kube-config : %s", err)
 	}
 
-	restcfg, err := clientcmd.RESTConfigFromKubeConfig(kubeConfigYaml)
-	if err != nil {
-		t.Fatalf("failed to create rest client for kubernetes : %s", err)
-	}
-
-	k8s, err := NewFromConfig(restcfg)
+	k8s, err := NewFromKubeconfigBytes(kubeConfigYaml, Config{})
 	if err != nil {
 		t.Fatalf("error creating kubernetes client: %v", err)
 	}
@@ -279,7 +274,7 @@ func Test_UnsupportedKubernetesVersion(t *testing.T) {
 		t.Fatalf("failed to create rest client for kubernetes : %s", err)
 	}
 
-	_, err = NewFromConfig(restcfg)
+	_, err = NewFromRESTConfig(restcfg)
 	if err == nil {
 		t.Errorf("should had failed creating kubernetes client")
 		return
@@ -3,14 +3,16 @@
 package kubernetes
 
 import (
-	"errors"
-	"fmt"
-
+	"github.com/grafana/xk6-disruptor/pkg/internal/version"
 	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
 
-	"k8s.io/client-go/discovery"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -23,28 +25,71 @@ type Kubernetes interface {
 	ServiceHelper(namespace string) helpers.ServiceHelper
 	// PodHelper returns a helpers.PodHelper scoped for the given namespace
 	PodHelper(namespace string) helpers.PodHelper
+	// NodeHelper returns a helpers.NodeHelper
+	NodeHelper() helpers.NodeHelper
+	// NamespaceHelper returns a helpers.NamespaceHelper
+	NamespaceHelper() helpers.NamespaceHelper
+	// WorkloadHelper returns a helpers.WorkloadHelper scoped for the given namespace
+	WorkloadHelper(namespace string) helpers.WorkloadHelper
+	// ManifestHelper returns a helpers.ManifestHelper
+	ManifestHelper() helpers.ManifestHelper
+	// Dynamic returns a client for handling resources of any kind, including custom resources, as unstructured objects
+	Dynamic() dynamic.Interface
+	// ResourceHelper returns a helpers.ResourceHelper for the resource scoped for the given namespace
+	ResourceHelper(resource schema.GroupVersionResource, namespace string) helpers.ResourceHelper
+	// Stop stops watching the resources of the cluster. The instance must not be used afterwards.
+	Stop()
 }
 
 // k8s Holds the reference to the helpers for interacting with kubernetes
 type k8s struct {
 	config *rest.Config
 	kubernetes.Interface
+	dynamic dynamic.Interface
+	// pods caches the pods listed by the helpers, shared by all the helpers of the instance
+	pods *helpers.PodCache
+	// mapper maps kinds to resources, using the cached discovery information of the cluster
+	mapper meta.RESTMapper
+	// retry defines how the helpers retry the requests that fail with transient errors
+	retry helpers.RetryOptions
+}
+
+// NewFromRESTConfig returns a Kubernetes instance configured with the provided rest config. The limits of the
+// requests to the API server that are not set in the config are taken from the environment (see QPSEnvVar,
+// BurstEnvVar and TimeoutEnvVar) or the defaults, as well as the retries of the requests (see RetriesEnvVar and
+// RetryBackoffEnvVar).
+func NewFromRESTConfig(config *rest.Config) (Kubernetes, error) {
+	return newFromConfig(config, Config{}.retryOptions())
 }
 
-// NewFromConfig returns a Kubernetes instance configured with the provided kubeconfig.
+// NewFromConfig returns a Kubernetes instance configured with the provided rest config.
+//
+// Deprecated: use NewFromRESTConfig.
 func NewFromConfig(config *rest.Config) (Kubernetes, error) {
-	// As per the discussion in [1] client side rate limiting is no longer required.
-	// Setting a large limit
-	// [1] https://github.com/kubernetes/kubernetes/issues/111880
-	config.QPS = 100
-	config.Burst = 150
+	return NewFromRESTConfig(config)
+}
+
+// newFromConfig returns a Kubernetes instance configured with the provided kubeconfig and retry options. The
+// provided config is not modified. If the config does not define the User-Agent, the requests identify the versions
+// of the disruptor and k6.
+func newFromConfig(config *rest.Config, retry helpers.RetryOptions) (Kubernetes, error) {
+	config = rest.CopyConfig(config)
+	setClientLimits(config)
+	if config.UserAgent == "" {
+		config.UserAgent = version.UserAgent("")
+	}
 
 	client, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, err
 	}
 
-	err = checkK8sVersion(config)
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkK8sVersion(client.Discovery())
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +97,10 @@ func NewFromConfig(config *rest.Config) (Kubernetes, error) {
 	return &k8s{
 		config:    config,
 		Interface: client,
+		dynamic:   dynamicClient,
+		pods:      helpers.NewPodCache(client, 0),
+		mapper:    restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(client.Discovery())),
+		retry:     retry,
 	}, nil
 }
 
@@ -62,7 +111,19 @@ func NewFromKubeconfig(kubeconfig string) (Kubernetes, error) {
 		return nil, err
 	}
 
-	return NewFromConfig(config)
+	return NewFromRESTConfig(config)
+}
+
+// NewFromKubeconfigBytes returns a Kubernetes instance for the cluster defined by the given kubeconfig and the
+// config, which must not specify the path of a kubeconfig. It allows using a kubeconfig that is not stored in a file,
+// for example one read from a secret.
+func NewFromKubeconfigBytes(kubeconfig []byte, config Config) (Kubernetes, error) {
+	if kubeconfig == nil {
+		kubeconfig = []byte{}
+	}
+	config.kubeconfigData = kubeconfig
+
+	return NewWithConfig(config)
 }
 
 // New returns a Kubernetes instance or an error when no config is eligible to be used.
@@ -71,46 +132,28 @@ func NewFromKubeconfig(kubeconfig string) (Kubernetes, error) {
 // 2. KUBECONFIG environment variable.
 // 3. $HOME/.kube/config file.
 func New() (Kubernetes, error) {
-	k8sConfig, err := rest.InClusterConfig()
-	if err == nil {
-		return NewFromConfig(k8sConfig)
-	}
-
-	if !errors.Is(err, rest.ErrNotInCluster) {
-		return nil, err
-	}
-
-	kubeConfigPath, getConfigErr := getConfigPath()
-	if getConfigErr != nil {
-		return nil, fmt.Errorf("error getting kubernetes config path: %w", getConfigErr)
-	}
-
-	return NewFromKubeconfig(kubeConfigPath)
+	return NewWithConfig(Config{})
 }
 
-func checkK8sVersion(config *rest.Config) error {
-	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+// NewWithConfig returns a Kubernetes instance for the cluster defined by the config. If the config does not
+// specify a kubeconfig, the kubeconfig is loaded as described in New.
+func NewWithConfig(config Config) (Kubernetes, error) {
+	restConfig, err := config.restConfig()
 	if err != nil {
-		return err
-	}
-
-	version, err := discoveryClient.ServerVersion()
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	semver := fmt.Sprintf("v%s.%s", version.Major, version.Minor)
-	// TODO: implement proper semver check
-	if semver < "v1.23" {
-		return fmt.Errorf("unsupported Kubernetes version. Expected >= v1.23 but actual is %s", semver)
-	}
-	return nil
+	return newFromConfig(restConfig, config.retryOptions())
 }
 
 // ServiceHelper returns a ServiceHelper for the given namespace
 func (k *k8s) ServiceHelper(namespace string) helpers.ServiceHelper {
-	return helpers.NewServiceHelper(
+	executor := helpers.NewRestExecutor(k.CoreV1().RESTClient(), k.config)
+	return helpers.NewCachedServiceHelper(
 		k.Interface,
+		executor,
+		k.pods,
+		k.retry,
 		namespace,
 	)
 }
@@ -118,13 +161,49 @@ func (k *k8s) ServiceHelper(namespace string) helpers.ServiceHelper {
 // PodHelper returns a PodHelper for the given namespace
 func (k *k8s) PodHelper(namespace string) helpers.PodHelper {
 	executor := helpers.NewRestExecutor(k.CoreV1().RESTClient(), k.config)
-	return helpers.NewPodHelper(
+	return helpers.NewCachedPodHelper(
 		k,
 		executor,
+		k.pods,
+		k.retry,
 		namespace,
 	)
 }
 
+// NodeHelper returns a NodeHelper
+func (k *k8s) NodeHelper() helpers.NodeHelper {
+	return helpers.NewNodeHelper(k.Interface)
+}
+
+// NamespaceHelper returns a NamespaceHelper
+func (k *k8s) NamespaceHelper() helpers.NamespaceHelper {
+	return helpers.NewNamespaceHelper(k.Interface)
+}
+
+// WorkloadHelper returns a WorkloadHelper for the given namespace
+func (k *k8s) WorkloadHelper(namespace string) helpers.WorkloadHelper {
+	return helpers.NewWorkloadHelper(k.Interface, namespace)
+}
+
+// ManifestHelper returns a ManifestHelper
+func (k *k8s) ManifestHelper() helpers.ManifestHelper {
+	return helpers.NewManifestHelper(k.Interface, k.dynamic, k.mapper)
+}
+
 func (k *k8s) Client() kubernetes.Interface {
 	return k.Interface
 }
+
+func (k *k8s) Dynamic() dynamic.Interface {
+	return k.dynamic
+}
+
+// ResourceHelper returns a ResourceHelper for the resource in the given namespace
+func (k *k8s) ResourceHelper(resource schema.GroupVersionResource, namespace string) helpers.ResourceHelper {
+	return helpers.NewResourceHelper(k.dynamic, resource, namespace)
+}
+
+// Stop stops watching the pods cached by the helpers
+func (k *k8s) Stop() {
+	k.pods.Stop()
+}
@@ -0,0 +1,207 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/rest"
+)
+
+// newTestAPIServer returns a server that reports the version and the resources required by the disruptors
+func newTestAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	responses := map[string]any{
+		"/version": version.Info{GitVersion: "v1.31.0", Major: "1", Minor: "31"},
+	}
+	for _, list := range buildResourceLists() {
+		path := "/apis/" + list.GroupVersion
+		if list.GroupVersion == "v1" {
+			path = "/api/v1"
+		}
+		responses[path] = list
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// the requests identify the disruptor
+		if !strings.HasPrefix(r.Header.Get("User-Agent"), "xk6-disruptor/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		response, found := responses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func Test_NewFromKubeconfigBytes(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t)
+
+	testCases := []struct {
+		title       string
+		kubeconfig  string
+		config      Config
+		expectError bool
+	}{
+		{
+			title: "valid kubeconfig",
+			kubeconfig: fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+users:
+- name: user
+  user:
+    token: token
+contexts:
+- name: context
+  context:
+    cluster: cluster
+    user: user
+current-context: context
+`, server.URL),
+			expectError: false,
+		},
+		{
+			title: "invalid credentials",
+			kubeconfig: fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+users:
+- name: user
+  user:
+    token: other
+contexts:
+- name: context
+  context:
+    cluster: cluster
+    user: user
+current-context: context
+`, server.URL),
+			expectError: true,
+		},
+		{
+			title: "context with invalid credentials",
+			kubeconfig: fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+users:
+- name: user
+  user:
+    token: token
+- name: other
+  user:
+    token: other
+contexts:
+- name: context
+  context:
+    cluster: cluster
+    user: user
+- name: other
+  context:
+    cluster: cluster
+    user: other
+current-context: context
+`, server.URL),
+			config:      Config{Context: "other"},
+			expectError: true,
+		},
+		{
+			title:       "kubeconfig path specified",
+			kubeconfig:  "apiVersion: v1\nkind: Config\n",
+			config:      Config{Kubeconfig: "/path/to/kubeconfig"},
+			expectError: true,
+		},
+		{
+			title:       "no current context",
+			kubeconfig:  "apiVersion: v1\nkind: Config\n",
+			expectError: true,
+		},
+		{
+			title:       "invalid kubeconfig",
+			kubeconfig:  "not a kubeconfig",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			k8s, err := NewFromKubeconfigBytes([]byte(tc.kubeconfig), tc.config)
+			if tc.expectError && err == nil {
+				t.Fatalf("should had failed")
+			}
+
+			if !tc.expectError && err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			if tc.expectError {
+				return
+			}
+
+			if k8s.Client() == nil {
+				t.Fatalf("expected a client")
+			}
+		})
+	}
+}
+
+func Test_NewFromRESTConfigDoesNotModifyConfig(t *testing.T) {
+	t.Parallel()
+
+	server := newTestAPIServer(t)
+
+	config := &rest.Config{
+		Host:            server.URL,
+		BearerToken:     "token",
+		TLSClientConfig: rest.TLSClientConfig{Insecure: true},
+	}
+
+	_, err := NewFromRESTConfig(config)
+	if err != nil {
+		t.Fatalf("failed: %v", err)
+	}
+
+	if config.QPS != 0 || config.Burst != 0 {
+		t.Fatalf("expected config not modified, got QPS %f and burst %d", config.QPS, config.Burst)
+	}
+}
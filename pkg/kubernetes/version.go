@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+)
+
+// minK8sVersion is the minimum version of Kubernetes supported
+const minK8sVersion = "v1.23.0"
+
+// requiredResource is an API resource required by the disruptors
+type requiredResource struct {
+	groupVersion string
+	resource     string
+	// feature describes what the resource is required for
+	feature string
+}
+
+// requiredResources returns the API resources required by the disruptors
+func requiredResources() []requiredResource {
+	return []requiredResource{
+		{groupVersion: "v1", resource: "pods", feature: "listing target pods"},
+		{groupVersion: "v1", resource: "pods/ephemeralcontainers", feature: "injecting the agent (ephemeral containers)"},
+		{groupVersion: "v1", resource: "pods/exec", feature: "executing commands in the agent"},
+		{groupVersion: "v1", resource: "services", feature: "disrupting services"},
+		{groupVersion: "v1", resource: "nodes", feature: "disrupting nodes"},
+		{
+			groupVersion: "authorization.k8s.io/v1",
+			resource:     "selfsubjectaccessreviews",
+			feature:      "checking permissions",
+		},
+	}
+}
+
+// checkK8sVersion checks the version of the cluster is supported and the cluster has the capabilities required by
+// the disruptors
+func checkK8sVersion(client discovery.DiscoveryInterface) error {
+	info, err := client.ServerVersion()
+	if err != nil {
+		return err
+	}
+
+	serverVersion, err := parseServerVersion(info)
+	if err != nil {
+		return err
+	}
+
+	if serverVersion.LessThan(utilversion.MustParseGeneric(minK8sVersion)) {
+		return fmt.Errorf(
+			"%w. Expected >= %s but actual is %s",
+			helpers.ErrVersionUnsupported,
+			minK8sVersion,
+			serverVersion,
+		)
+	}
+
+	return checkResources(client)
+}
+
+// parseServerVersion returns the version of the server. Uses the git version, if it is valid, or the major and
+// minor versions otherwise. Suffixes added by some providers (e.g. "27+" in GKE and EKS) are ignored.
+func parseServerVersion(info *version.Info) (*utilversion.Version, error) {
+	serverVersion, err := utilversion.ParseGeneric(info.GitVersion)
+	if err == nil {
+		return serverVersion, nil
+	}
+
+	major := strings.TrimRightFunc(info.Major, func(r rune) bool { return !unicode.IsDigit(r) })
+	minor := strings.TrimRightFunc(info.Minor, func(r rune) bool { return !unicode.IsDigit(r) })
+	serverVersion, err = utilversion.ParseGeneric(fmt.Sprintf("%s.%s", major, minor))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Kubernetes version %q (major %q minor %q)", info.GitVersion, info.Major, info.Minor)
+	}
+
+	return serverVersion, nil
+}
+
+// checkResources checks the resources required by the disruptors are available in the cluster
+func checkResources(client discovery.DiscoveryInterface) error {
+	available := map[string]map[string]bool{}
+	missing := []string{}
+	for _, required := range requiredResources() {
+		resources, found := available[required.groupVersion]
+		if !found {
+			resources = map[string]bool{}
+			list, err := client.ServerResourcesForGroupVersion(required.groupVersion)
+			if err != nil && !errors.IsNotFound(err) {
+				return fmt.Errorf("discovering resources of %s: %w", required.groupVersion, err)
+			}
+			if list != nil {
+				for _, resource := range list.APIResources {
+					resources[resource.Name] = true
+				}
+			}
+			available[required.groupVersion] = resources
+		}
+
+		if !resources[required.resource] {
+			missing = append(
+				missing,
+				fmt.Sprintf("%s in %s (required for %s)", required.resource, required.groupVersion, required.feature),
+			)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	// the lack of ephemeral containers is reported as such, as some clusters may have them disabled
+	reason := helpers.ErrVersionUnsupported
+	if !available["v1"]["pods/ephemeralcontainers"] {
+		reason = helpers.ErrEphemeralContainersDisabled
+	}
+
+	return fmt.Errorf("%w. Resources not available: %s", reason, strings.Join(missing, ", "))
+}
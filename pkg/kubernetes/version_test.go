@@ -0,0 +1,125 @@
+package kubernetes
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grafana/xk6-disruptor/pkg/kubernetes/helpers"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// buildResourceLists returns the resource lists with all the required resources except the ones to skip
+func buildResourceLists(skip ...string) []*metav1.APIResourceList {
+	lists := map[string]*metav1.APIResourceList{}
+	result := []*metav1.APIResourceList{}
+	for _, required := range requiredResources() {
+		list, found := lists[required.groupVersion]
+		if !found {
+			list = &metav1.APIResourceList{GroupVersion: required.groupVersion}
+			lists[required.groupVersion] = list
+			result = append(result, list)
+		}
+
+		skipped := false
+		for _, s := range skip {
+			if s == required.resource {
+				skipped = true
+			}
+		}
+		if !skipped {
+			list.APIResources = append(list.APIResources, metav1.APIResource{Name: required.resource})
+		}
+	}
+
+	return result
+}
+
+func Test_CheckK8sVersion(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		version     version.Info
+		resources   []*metav1.APIResourceList
+		expectError bool
+		expectedErr error
+	}{
+		{
+			title:     "supported version",
+			version:   version.Info{Major: "1", Minor: "27", GitVersion: "v1.27.3"},
+			resources: buildResourceLists(),
+		},
+		{
+			title:     "provider suffix in git version",
+			version:   version.Info{Major: "1", Minor: "27+", GitVersion: "v1.27.4-eks-2d98532"},
+			resources: buildResourceLists(),
+		},
+		{
+			title:     "provider suffix in minor version",
+			version:   version.Info{Major: "1", Minor: "27+", GitVersion: "unknown"},
+			resources: buildResourceLists(),
+		},
+		{
+			title:     "minor version with two digits compared as number",
+			version:   version.Info{Major: "1", Minor: "100", GitVersion: "v1.100.0"},
+			resources: buildResourceLists(),
+		},
+		{
+			title:       "unsupported version",
+			version:     version.Info{Major: "1", Minor: "22", GitVersion: "v1.22.17"},
+			resources:   buildResourceLists(),
+			expectError: true,
+			expectedErr: helpers.ErrVersionUnsupported,
+		},
+		{
+			title:       "invalid version",
+			version:     version.Info{Major: "", Minor: "", GitVersion: ""},
+			resources:   buildResourceLists(),
+			expectError: true,
+		},
+		{
+			title:       "ephemeral containers not available",
+			version:     version.Info{Major: "1", Minor: "27", GitVersion: "v1.27.3"},
+			resources:   buildResourceLists("pods/ephemeralcontainers"),
+			expectError: true,
+			expectedErr: helpers.ErrEphemeralContainersDisabled,
+		},
+		{
+			title:       "API group not available",
+			version:     version.Info{Major: "1", Minor: "27", GitVersion: "v1.27.3"},
+			resources:   buildResourceLists("selfsubjectaccessreviews")[:1],
+			expectError: true,
+			expectedErr: helpers.ErrVersionUnsupported,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			client := fake.NewSimpleClientset()
+			discovery, _ := client.Discovery().(*fakediscovery.FakeDiscovery)
+			discovery.FakedServerVersion = &tc.version
+			discovery.Resources = tc.resources
+
+			err := checkK8sVersion(discovery)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("should had failed")
+				}
+				if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+					t.Fatalf("expected error %v got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Package nftables implements objects that manipulate netfilter rules by calling the nft binary.
+package nftables
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+// Table is the name of the table, in the ip family, that holds the chains created by the disruptor agent. Keeping the
+// chains in a separate table allows removing them if the agent terminates unexpectedly.
+const Table = "xk6-disruptor"
+
+// Nftables adds and removes nftables chains and rules by executing the `nft` binary.
+type Nftables struct {
+	// Executor is the runtime.Executor used to run the nft binary.
+	executor runtime.Executor
+}
+
+// New returns a new Nftables ready to use.
+func New(executor runtime.Executor) Nftables {
+	return Nftables{
+		executor: executor,
+	}
+}
+
+// Chain is a base chain of the agent's table, attached to a netfilter hook.
+type Chain struct {
+	// Name of the chain
+	Name string
+	// Type of the chain: "filter" or "nat"
+	Type string
+	// Hook the chain is attached to, such as "prerouting", "input" or "output"
+	Hook string
+	// Priority of the chain in the hook, which can be expressed relative to a standard priority (e.g. "dstnat - 1").
+	// Chains with a lower priority are evaluated first.
+	Priority string
+}
+
+// Rule is a rule in a chain of the agent's table.
+type Rule struct {
+	// Chain is the name of the chain to which this rule belongs.
+	Chain string
+	// Args are the statements of the rule.
+	// Arguments must be space-separated. Using shell-style quotes or backslashes to group more than one space-separated
+	// word as one argument is not allowed.
+	Args string
+}
+
+// Available returns if netfilter rules can be managed using the nft binary
+func (n Nftables) Available() bool {
+	_, err := n.executor.Exec("nft", "list", "tables")
+	return err == nil
+}
+
+// AddChain adds a chain to the agent's table, creating the table if it does not exist.
+func (n Nftables) AddChain(c Chain) error {
+	err := n.exec("add table ip " + Table)
+	if err != nil {
+		return err
+	}
+
+	return n.exec(
+		fmt.Sprintf("add chain ip %s %s { type %s hook %s priority %s ; }", Table, c.Name, c.Type, c.Hook, c.Priority),
+	)
+}
+
+// RemoveChain removes a chain and its rules from the agent's table. If the chain does not exist, an error is returned.
+func (n Nftables) RemoveChain(name string) error {
+	err := n.exec(fmt.Sprintf("flush chain ip %s %s", Table, name))
+	if err != nil {
+		return err
+	}
+
+	return n.exec(fmt.Sprintf("delete chain ip %s %s", Table, name))
+}
+
+// Add appends a rule into the corresponding chain.
+func (n Nftables) Add(r Rule) error {
+	return n.exec(fmt.Sprintf("add rule ip %s %s %s", Table, r.Chain, r.Args))
+}
+
+// RemoveTable removes the agent's table with all its chains and rules. This removes the rules left behind by an agent
+// that terminated unexpectedly. If the table does not exist, nothing is done.
+func (n Nftables) RemoveTable() error {
+	exists, err := n.tableExists()
+	if err != nil || !exists {
+		return err
+	}
+
+	return n.exec("delete table ip " + Table)
+}
+
+// ListRules returns the rules in the chains of the agent's table, as listed by nft
+func (n Nftables) ListRules() ([]string, error) {
+	exists, err := n.tableExists()
+	if err != nil || !exists {
+		return []string{}, err
+	}
+
+	out, err := n.executor.Exec("nft", "list", "table", "ip", Table)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", err, out)
+	}
+
+	rules := []string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line == "}" || hasAnyPrefix(line, "table ", "chain ", "type ") {
+			continue
+		}
+		rules = append(rules, line)
+	}
+
+	return rules, nil
+}
+
+// tableExists returns if the agent's table exists
+func (n Nftables) tableExists() (bool, error) {
+	out, err := n.executor.Exec("nft", "list", "tables")
+	if err != nil {
+		return false, fmt.Errorf("%w: %q", err, out)
+	}
+
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) == "table ip "+Table {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n Nftables) exec(args string) error {
+	out, err := n.executor.Exec("nft", strings.Split(args, " ")...)
+	if err != nil {
+		return fmt.Errorf("%w: %q", err, out)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package nftables
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/runtime"
+)
+
+func Test_Nftables(t *testing.T) {
+	t.Parallel()
+
+	anError := errors.New("an error occurred")
+
+	for _, tc := range []struct {
+		name             string
+		testFunc         func(Nftables) error
+		execError        error
+		expectedCommands []string
+		expectedError    error
+	}{
+		{
+			name: "Adds chain",
+			testFunc: func(n Nftables) error {
+				return n.AddChain(Chain{
+					Name:     "output-80",
+					Type:     "nat",
+					Hook:     "output",
+					Priority: "dstnat - 1",
+				})
+			},
+			expectedCommands: []string{
+				"nft add table ip xk6-disruptor",
+				"nft add chain ip xk6-disruptor output-80 { type nat hook output priority dstnat - 1 ; }",
+			},
+		},
+		{
+			name: "Removes chain",
+			testFunc: func(n Nftables) error {
+				return n.RemoveChain("output-80")
+			},
+			expectedCommands: []string{
+				"nft flush chain ip xk6-disruptor output-80",
+				"nft delete chain ip xk6-disruptor output-80",
+			},
+		},
+		{
+			name: "Adds rule",
+			testFunc: func(n Nftables) error {
+				return n.Add(Rule{
+					Chain: "output-80",
+					Args:  "tcp dport 80 redirect to :8080",
+				})
+			},
+			expectedCommands: []string{
+				"nft add rule ip xk6-disruptor output-80 tcp dport 80 redirect to :8080",
+			},
+		},
+		{
+			name: "Propagates error",
+			testFunc: func(n Nftables) error {
+				return n.RemoveChain("output-80")
+			},
+			execError: anError,
+			expectedCommands: []string{
+				"nft flush chain ip xk6-disruptor output-80",
+			},
+			expectedError: anError,
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fakeExec := runtime.NewFakeExecutor(nil, tc.execError)
+			err := tc.testFunc(New(fakeExec))
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("Expected error to be %v, got %v", tc.expectedError, err)
+			}
+
+			if diff := cmp.Diff(tc.expectedCommands, fakeExec.CmdHistory()); diff != "" {
+				t.Fatalf("Ran commands do not match expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_RemoveTable(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name             string
+		tables           string
+		expectedCommands []string
+	}{
+		{
+			name:   "Table exists",
+			tables: "table ip filter\ntable ip xk6-disruptor\n",
+			expectedCommands: []string{
+				"nft list tables",
+				"nft delete table ip xk6-disruptor",
+			},
+		},
+		{
+			name:   "Table does not exist",
+			tables: "table ip filter\n",
+			expectedCommands: []string{
+				"nft list tables",
+			},
+		},
+	} {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			exec := runtime.NewFakeExecutor([]byte(tc.tables), nil)
+			err := New(exec).RemoveTable()
+			if err != nil {
+				t.Fatalf("error removing table: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expectedCommands, exec.CmdHistory()); diff != "" {
+				t.Fatalf("Ran commands do not match expected:\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ListRules(t *testing.T) {
+	t.Parallel()
+
+	table := "table ip xk6-disruptor {\n" +
+		"\tchain output-80 {\n" +
+		"\t\ttype nat hook output priority dstnat - 1; policy accept;\n" +
+		"\t\tip saddr 127.0.0.0/8 ip daddr 127.0.0.1 tcp dport 80 redirect to :8080\n" +
+		"\t}\n" +
+		"}\n"
+
+	exec := runtime.NewCallbackExecutor(func(_ string, args ...string) ([]byte, error) {
+		if args[1] == "tables" {
+			return []byte("table ip xk6-disruptor\n"), nil
+		}
+		return []byte(table), nil
+	})
+
+	rules, err := New(exec).ListRules()
+	if err != nil {
+		t.Fatalf("error listing rules: %v", err)
+	}
+
+	expected := []string{
+		"ip saddr 127.0.0.0/8 ip daddr 127.0.0.1 tcp dport 80 redirect to :8080",
+	}
+
+	if diff := cmp.Diff(expected, rules); diff != "" {
+		t.Fatalf("Listed rules do not match expected:\n%s", diff)
+	}
+}
@@ -1,6 +1,7 @@
 package runtime
 
 import (
+	"context"
 	"os/exec"
 )
 
@@ -11,6 +12,27 @@ type Executor interface {
 	Exec(cmd string, args ...string) ([]byte, error)
 }
 
+// ContextExecutor is implemented by the executors that can terminate the processes when a context is done
+type ContextExecutor interface {
+	// ExecContext executes a process and waits for its completion, returning the combined stdout and stderr.
+	// The process is killed if the context is done before it completes.
+	ExecContext(ctx context.Context, cmd string, args ...string) ([]byte, error)
+}
+
+// ExecContext executes a process with the executor. If the executor implements ContextExecutor, the process is
+// killed when the context is done. Otherwise, the process is only executed if the context is not done.
+func ExecContext(ctx context.Context, executor Executor, cmd string, args ...string) ([]byte, error) {
+	if contextExecutor, ok := executor.(ContextExecutor); ok {
+		return contextExecutor.ExecContext(ctx, cmd, args...)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return executor.Exec(cmd, args...)
+}
+
 // An instance of an executor that uses the os/exec package for
 // executing processes
 type executor struct{}
@@ -24,3 +46,9 @@ func DefaultExecutor() Executor {
 func (e *executor) Exec(cmd string, args ...string) ([]byte, error) {
 	return exec.Command(cmd, args...).CombinedOutput()
 }
+
+// ExecContext executes a process and returns the combined stdout and stderr. The process is killed if the context
+// is done before it completes.
+func (e *executor) ExecContext(ctx context.Context, cmd string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, cmd, args...).CombinedOutput()
+}
@@ -1,7 +1,9 @@
 package runtime
 
 import (
+	"context"
 	"testing"
+	"time"
 )
 
 func Test_Exec(t *testing.T) {
@@ -77,3 +79,31 @@ func Test_Exec(t *testing.T) {
 		})
 	}
 }
+
+func Test_ExecContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := ExecContext(ctx, DefaultExecutor(), "sleep", "10")
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("process was not killed when the context was done")
+	}
+
+	// executors that do not support contexts do not execute the process if the context is done
+	executor := NewFakeExecutor(nil, nil)
+	_, err = ExecContext(ctx, executor, "true")
+	if err == nil {
+		t.Fatalf("should had failed")
+	}
+
+	if executor.Invoked() {
+		t.Fatalf("process should not be executed")
+	}
+}
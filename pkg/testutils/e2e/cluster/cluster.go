@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"time"
 
@@ -106,6 +107,24 @@ func InstallContourIngress(ctx context.Context, cluster E2eCluster) error {
 	return nil
 }
 
+// InstallIstio installs the minimal profile of Istio using the istioctl binary, which must be available in the PATH
+func InstallIstio(ctx context.Context, cluster E2eCluster) error {
+	cmd := exec.CommandContext(
+		ctx,
+		"istioctl", "install",
+		"--kubeconfig", cluster.Kubeconfig(),
+		"--set", "profile=minimal",
+		"--skip-confirmation",
+	)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("installing istio: %w: %s", err, out)
+	}
+
+	return nil
+}
+
 // DefaultE2eClusterConfig builds the default configuration for an e2e test cluster
 // TODO: allow override of default port using an environment variable (E2E_INGRESS_PORT)
 func DefaultE2eClusterConfig() E2eClusterConfig {
@@ -210,6 +229,14 @@ func WithImages(images ...string) E2eClusterOption {
 	}
 }
 
+// WithPostInstall adds functions to be executed after the cluster is created
+func WithPostInstall(postInstall ...PostInstall) E2eClusterOption {
+	return func(c E2eClusterConfig) (E2eClusterConfig, error) {
+		c.PostInstall = append(c.PostInstall, postInstall...)
+		return c, nil
+	}
+}
+
 // e2eCluster maintains the status of a cluster
 type e2eCluster struct {
 	cluster     *cluster.Cluster
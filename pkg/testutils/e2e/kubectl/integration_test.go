@@ -48,7 +48,7 @@ func Test_Kubectl(t *testing.T) {
 		t.Fatalf("failed to create rest client for kubernetes : %s", err)
 	}
 
-	k8s, err := kubernetes.NewFromConfig(restcfg)
+	k8s, err := kubernetes.NewFromRESTConfig(restcfg)
 	if err != nil {
 		t.Fatalf("error creating kubernetes client: %v", err)
 	}
@@ -20,6 +20,7 @@ type TestNamespaceConfig struct {
 	keepOnFail bool
 	random     bool
 	name       string
+	labels     map[string]string
 }
 
 // DefaultNamespaceConfig defines the default options for creating a test namespace
@@ -57,6 +58,18 @@ func WithName(name string) TestNamespaceOption {
 	}
 }
 
+// WithLabel adds a label to the namespace
+func WithLabel(name string, value string) TestNamespaceOption {
+	return func(c TestNamespaceConfig) (TestNamespaceConfig, error) {
+		if c.labels == nil {
+			c.labels = map[string]string{}
+		}
+
+		c.labels[name] = value
+		return c, nil
+	}
+}
+
 // WithKeepOnFail indicates if the namespace must be kept in case the test fails
 func WithKeepOnFail(keepOnFail bool) TestNamespaceOption {
 	return func(c TestNamespaceConfig) (TestNamespaceConfig, error) {
@@ -95,6 +108,7 @@ func CreateTestNamespace(
 	} else {
 		ns.ObjectMeta = metav1.ObjectMeta{Name: config.name}
 	}
+	ns.Labels = config.labels
 
 	ns, err = k8s.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
 	if err != nil {
@@ -60,6 +60,23 @@ func Test_CreateNamespace(t *testing.T) {
 					return fmt.Errorf("expected 'testns' got %q", ns)
 				}
 
+				return nil
+			},
+		},
+		{
+			title:       "with label",
+			options:     []TestNamespaceOption{WithName("testns"), WithLabel("istio-injection", "enabled")},
+			expectError: false,
+			check: func(k8s kubernetes.Interface, ns string) error {
+				namespace, err := k8s.CoreV1().Namespaces().Get(context.TODO(), ns, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+
+				if namespace.Labels["istio-injection"] != "enabled" {
+					return fmt.Errorf("expected label 'istio-injection=enabled' got %v", namespace.Labels)
+				}
+
 				return nil
 			},
 		},
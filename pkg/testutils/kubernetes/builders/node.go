@@ -0,0 +1,75 @@
+package builders
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeBuilder defines the methods for building a Node
+type NodeBuilder interface {
+	// Build returns a Node with the attributes defined in the NodeBuilder
+	Build() corev1.Node
+	// WithLabels sets the labels to the node (overrides any previously set labels)
+	WithLabels(labels map[string]string) NodeBuilder
+	// WithLabel adds a label to the Node
+	WithLabel(name string, value string) NodeBuilder
+	// WithUnschedulable sets the unschedulable attribute of the node
+	WithUnschedulable(unschedulable bool) NodeBuilder
+	// WithIP adds an internal IP address to the node
+	WithIP(ip string) NodeBuilder
+}
+
+// nodeBuilder defines the attributes for building a node
+type nodeBuilder struct {
+	name          string
+	labels        map[string]string
+	unschedulable bool
+	addresses     []corev1.NodeAddress
+}
+
+// NewNodeBuilder creates a new instance of NodeBuilder with the given node name
+func NewNodeBuilder(name string) NodeBuilder {
+	return &nodeBuilder{
+		name:   name,
+		labels: map[string]string{},
+	}
+}
+
+func (b *nodeBuilder) WithLabels(labels map[string]string) NodeBuilder {
+	b.labels = labels
+	return b
+}
+
+func (b *nodeBuilder) WithLabel(name string, value string) NodeBuilder {
+	b.labels[name] = value
+	return b
+}
+
+func (b *nodeBuilder) WithUnschedulable(unschedulable bool) NodeBuilder {
+	b.unschedulable = unschedulable
+	return b
+}
+
+func (b *nodeBuilder) WithIP(ip string) NodeBuilder {
+	b.addresses = append(b.addresses, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ip})
+	return b
+}
+
+func (b *nodeBuilder) Build() corev1.Node {
+	return corev1.Node{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Node",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   b.name,
+			Labels: b.labels,
+		},
+		Spec: corev1.NodeSpec{
+			Unschedulable: b.unschedulable,
+		},
+		Status: corev1.NodeStatus{
+			Addresses: b.addresses,
+		},
+	}
+}
@@ -1,6 +1,9 @@
 package builders
 
 import (
+	"fmt"
+	"time"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -28,6 +31,24 @@ type PodBuilder interface {
 	WithHostNetwork(hostNetwork bool) PodBuilder
 	// WithContainer add a container to the pod
 	WithContainer(c corev1.Container) PodBuilder
+	// WithNodeName sets the name of the node the pod to be built is scheduled to
+	WithNodeName(node string) PodBuilder
+	// WithReady sets the Ready condition of the pod to be built
+	WithReady(ready bool) PodBuilder
+	// WithTerminating marks the pod to be built as being deleted
+	WithTerminating() PodBuilder
+	// WithCondition sets the status of a condition of the pod to be built
+	WithCondition(condition corev1.PodConditionType, status corev1.ConditionStatus) PodBuilder
+	// WithQOSClass sets the QoS class of the pod to be built
+	WithQOSClass(class corev1.PodQOSClass) PodBuilder
+	// WithRestarts adds the status of a container restarted a number of times, the last one at the given time
+	WithRestarts(count int32, lastRestart time.Time) PodBuilder
+	// WithWaiting adds the status of a container waiting for the given reason (e.g. "CrashLoopBackOff")
+	WithWaiting(reason string) PodBuilder
+	// WithImagePullSecret adds a reference to a secret for pulling the images of the pod
+	WithImagePullSecret(name string) PodBuilder
+	// WithSecretVolume adds a volume with the given secret to the pod
+	WithSecretVolume(name string, secret string) PodBuilder
 }
 
 // podBuilder defines the attributes for building a pod
@@ -40,6 +61,13 @@ type podBuilder struct {
 	ip          string
 	hostNetwork bool
 	containers  []corev1.Container
+	nodeName    string
+	conditions  []corev1.PodCondition
+	terminating bool
+	qosClass    corev1.PodQOSClass
+	statuses    []corev1.ContainerStatus
+	pullSecrets []corev1.LocalObjectReference
+	volumes     []corev1.Volume
 }
 
 // NewPodBuilder creates a new instance of PodBuilder with the given pod name
@@ -97,6 +125,70 @@ func (b *podBuilder) WithContainer(c corev1.Container) PodBuilder {
 	return b
 }
 
+func (b *podBuilder) WithNodeName(node string) PodBuilder {
+	b.nodeName = node
+	return b
+}
+
+func (b *podBuilder) WithReady(ready bool) PodBuilder {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return b.WithCondition(corev1.PodReady, status)
+}
+
+func (b *podBuilder) WithCondition(condition corev1.PodConditionType, status corev1.ConditionStatus) PodBuilder {
+	b.conditions = append(b.conditions, corev1.PodCondition{Type: condition, Status: status})
+	return b
+}
+
+func (b *podBuilder) WithQOSClass(class corev1.PodQOSClass) PodBuilder {
+	b.qosClass = class
+	return b
+}
+
+func (b *podBuilder) WithRestarts(count int32, lastRestart time.Time) PodBuilder {
+	b.statuses = append(b.statuses, corev1.ContainerStatus{
+		Name:         fmt.Sprintf("container-%d", len(b.statuses)),
+		RestartCount: count,
+		LastTerminationState: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{
+				FinishedAt: metav1.NewTime(lastRestart),
+			},
+		},
+	})
+	return b
+}
+
+func (b *podBuilder) WithWaiting(reason string) PodBuilder {
+	b.statuses = append(b.statuses, corev1.ContainerStatus{
+		Name: fmt.Sprintf("container-%d", len(b.statuses)),
+		State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: reason},
+		},
+	})
+	return b
+}
+
+func (b *podBuilder) WithImagePullSecret(name string) PodBuilder {
+	b.pullSecrets = append(b.pullSecrets, corev1.LocalObjectReference{Name: name})
+	return b
+}
+
+func (b *podBuilder) WithSecretVolume(name string, secret string) PodBuilder {
+	b.volumes = append(b.volumes, corev1.Volume{
+		Name:         name,
+		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secret}},
+	})
+	return b
+}
+
+func (b *podBuilder) WithTerminating() PodBuilder {
+	b.terminating = true
+	return b
+}
+
 func (b *podBuilder) Build() corev1.Pod {
 	pod := corev1.Pod{
 		TypeMeta: metav1.TypeMeta{
@@ -112,13 +204,26 @@ func (b *podBuilder) Build() corev1.Pod {
 		Spec: corev1.PodSpec{
 			Containers:          b.containers,
 			HostNetwork:         b.hostNetwork,
+			NodeName:            b.nodeName,
+			ImagePullSecrets:    b.pullSecrets,
+			Volumes:             b.volumes,
 			EphemeralContainers: nil,
 		},
 		Status: corev1.PodStatus{
-			Phase: b.phase,
+			Phase:             b.phase,
+			Conditions:        b.conditions,
+			QOSClass:          b.qosClass,
+			ContainerStatuses: b.statuses,
 		},
 	}
 
+	if b.terminating {
+		now := metav1.Now()
+		pod.DeletionTimestamp = &now
+		// the API server does not accept objects being deleted without finalizers
+		pod.Finalizers = []string{"xk6-disruptor/test"}
+	}
+
 	// PodIPs is a patchMergeKey field, so it should be nil if no IPs are present. Otherwise, creation of
 	// StrategicMerge patches will fail with:
 	// map: map[] does not contain declared merge key: ip
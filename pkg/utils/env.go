@@ -3,6 +3,7 @@ package utils
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // GetBooleanEnvVar returns a boolean environment variable.
@@ -34,3 +35,23 @@ func GetInt32EnvVar(envVar string, defaultValue int32) int32 {
 	}
 	return int32(value)
 }
+
+// GetFloat32EnvVar returns a float environment variable.
+// If variable is not set or invalid value, returns the default value
+func GetFloat32EnvVar(envVar string, defaultValue float32) float32 {
+	value, err := strconv.ParseFloat(os.Getenv(envVar), 32)
+	if err != nil {
+		return defaultValue
+	}
+	return float32(value)
+}
+
+// GetDurationEnvVar returns a duration environment variable (e.g. "30s").
+// If variable is not set or invalid value, returns the default value
+func GetDurationEnvVar(envVar string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(envVar))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
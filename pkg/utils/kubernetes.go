@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"math"
+	"strings"
 
 	"github.com/grafana/xk6-disruptor/pkg/types/intstr"
 	corev1 "k8s.io/api/core/v1"
@@ -13,10 +14,21 @@ func GetTargetPort(service corev1.Service, svcPort intstr.IntOrString) (intstr.I
 	// Handle default port mapping
 	// TODO: make port required
 	if svcPort.IsNull() || svcPort.IsZero() {
-		if len(service.Spec.Ports) > 1 {
-			return intstr.NullValue, fmt.Errorf("no port selected and service exposes more than one service")
+		switch len(service.Spec.Ports) {
+		case 0:
+			return intstr.NullValue, fmt.Errorf("no port selected and service does not expose any port")
+		case 1:
+			return intstr.IntOrString(service.Spec.Ports[0].TargetPort.String()), nil
 		}
-		return intstr.IntOrString(service.Spec.Ports[0].TargetPort.String()), nil
+
+		exposed := []string{}
+		for _, p := range service.Spec.Ports {
+			exposed = append(exposed, describePort(p.Name, p.Port))
+		}
+		return intstr.NullValue, fmt.Errorf(
+			"no port selected and service exposes more than one port: %s",
+			strings.Join(exposed, ", "),
+		)
 	}
 
 	for _, p := range service.Spec.Ports {
@@ -53,11 +65,88 @@ func FindPort(port intstr.IntOrString, pod corev1.Pod) (intstr.IntOrString, erro
 	return intstr.NullValue, fmt.Errorf("pod %q does exports port %q", pod.Name, port.Str())
 }
 
+// DiscoverPort returns the port in the Pod for a fault that does not select one. If the Pod exposes only one port, it
+// is returned. If it exposes more than one, the preferred port is returned if exposed. Otherwise, the error lists the
+// exposed ports. The ports of the Istio sidecar are ignored.
+func DiscoverPort(pod corev1.Pod, preferred intstr.IntOrString) (intstr.IntOrString, error) {
+	ports := []corev1.ContainerPort{}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == istioSidecarName {
+			continue
+		}
+		ports = append(ports, container.Ports...)
+	}
+
+	switch len(ports) {
+	case 0:
+		return intstr.NullValue, fmt.Errorf("no port selected and pod %q does not export any port", pod.Name)
+	case 1:
+		return intstr.FromInt32(ports[0].ContainerPort), nil
+	}
+
+	exposed := []string{}
+	for _, p := range ports {
+		if preferred.IsInt() && p.ContainerPort == preferred.Int32() {
+			return intstr.FromInt32(p.ContainerPort), nil
+		}
+		exposed = append(exposed, describePort(p.Name, p.ContainerPort))
+	}
+
+	return intstr.NullValue, fmt.Errorf(
+		"no port selected and pod %q exports more than one port: %s",
+		pod.Name,
+		strings.Join(exposed, ", "),
+	)
+}
+
+// describePort returns the number of a port, followed by its name if it has one
+func describePort(name string, port int32) string {
+	if name == "" {
+		return fmt.Sprint(port)
+	}
+
+	return fmt.Sprintf("%d (%s)", port, name)
+}
+
 // HasHostNetwork returns whether a pod has HostNetwork enabled, i.e. it shares the host's network namespace.
 func HasHostNetwork(pod corev1.Pod) bool {
 	return pod.Spec.HostNetwork
 }
 
+// istioSidecarName is the name of the container of the Istio sidecar
+const istioSidecarName = "istio-proxy"
+
+// HasIstioSidecar returns whether a pod has an Istio sidecar, either as a regular container or as a native sidecar
+// (an init container that keeps running)
+func HasIstioSidecar(pod corev1.Pod) bool {
+	for _, containers := range [][]corev1.Container{pod.Spec.Containers, pod.Spec.InitContainers} {
+		for _, container := range containers {
+			if container.Name == istioSidecarName {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsIstioPort returns whether the port is used by the Istio sidecar
+func IsIstioPort(port uint) bool {
+	switch port {
+	case 15000, // admin
+		15001, // outbound traffic
+		15004, // debug
+		15006, // inbound traffic
+		15008, // HBONE tunnel
+		15020, // merged metrics
+		15021, // health checks
+		15090: // envoy metrics
+		return true
+	default:
+		return false
+	}
+}
+
 // PodIP returns the pod IP for the supplied pod, or an error if it has no IP (yet).
 func PodIP(pod corev1.Pod) (string, error) {
 	// PodIP must be set if len(PodIPs > 0).
@@ -78,6 +167,16 @@ func PodNames(pods []corev1.Pod) []string {
 	return names
 }
 
+// NodeNames return the name of the nodes in a list
+func NodeNames(nodes []corev1.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		names = append(names, node.Name)
+	}
+
+	return names
+}
+
 // Sample a subset of the given list of Pods. The count is defined as a int or a string representing a percentage.
 // If the count is a percentage and there are no enough elements in the pod list, the number is rounded up.
 // If the list is not empty, at least one element is returned
@@ -141,6 +141,15 @@ func Test_GetTargetPort(t *testing.T) {
 			expectError: false,
 			expected:    intstr.FromInt32(80),
 		},
+		{
+			title: "No port selected and multiple ports",
+			service: builders.NewServiceBuilder("test-svc").
+				WithPort("http", 80, k8sintstr.FromInt(8080)).
+				WithPort("admin", 8001, k8sintstr.FromInt(8001)).
+				Build(),
+			port:        intstr.NullValue,
+			expectError: true,
+		},
 		{
 			title:       "Numeric port not exposed",
 			service:     buildServicWithPort("test-svc", "http", 80, k8sintstr.FromInt(80)),
@@ -184,6 +193,121 @@ func Test_GetTargetPort(t *testing.T) {
 	}
 }
 
+func Test_DiscoverPort(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		title       string
+		pod         corev1.Pod
+		expectError bool
+		expected    intstr.IntOrString
+	}{
+		{
+			title:    "Single port",
+			pod:      buildPodWithPort("pod-1", "http", 8080),
+			expected: intstr.FromInt32(8080),
+		},
+		{
+			title: "Multiple ports including preferred port",
+			pod: builders.NewPodBuilder("pod-1").
+				WithContainer(builders.NewContainerBuilder("app").WithPort("admin", 8001).WithPort("http", 80).Build()).
+				Build(),
+			expected: intstr.FromInt32(80),
+		},
+		{
+			title: "Multiple ports",
+			pod: builders.NewPodBuilder("pod-1").
+				WithContainer(builders.NewContainerBuilder("app").WithPort("admin", 8001).WithPort("http", 8080).Build()).
+				Build(),
+			expectError: true,
+		},
+		{
+			title: "Istio sidecar ports are ignored",
+			pod: builders.NewPodBuilder("pod-1").
+				WithContainer(builders.NewContainerBuilder("app").WithPort("http", 8080).Build()).
+				WithContainer(builders.NewContainerBuilder("istio-proxy").WithPort("http-envoy-prom", 15090).Build()).
+				Build(),
+			expected: intstr.FromInt32(8080),
+		},
+		{
+			title:       "No ports",
+			pod:         builders.NewPodBuilder("pod-1").WithContainer(builders.NewContainerBuilder("app").Build()).Build(),
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			port, err := DiscoverPort(tc.pod, intstr.FromInt32(80))
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("should had failed")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf(" failed: %v", err)
+				return
+			}
+
+			if tc.expected != port {
+				t.Errorf("expected %q got %q", tc.expected.Str(), port.Str())
+			}
+		})
+	}
+}
+
+func Test_HasIstioSidecar(t *testing.T) {
+	t.Parallel()
+
+	sidecar := builders.NewContainerBuilder("istio-proxy").Build()
+
+	nativeSidecar := buildPodWithPort("pod-1", "http", 80)
+	nativeSidecar.Spec.InitContainers = []corev1.Container{sidecar}
+
+	testCases := []struct {
+		title    string
+		pod      corev1.Pod
+		expected bool
+	}{
+		{
+			title:    "no sidecar",
+			pod:      buildPodWithPort("pod-1", "http", 80),
+			expected: false,
+		},
+		{
+			title: "sidecar container",
+			pod: builders.NewPodBuilder("pod-1").
+				WithContainer(builders.NewContainerBuilder("app").WithPort("http", 80).Build()).
+				WithContainer(sidecar).
+				Build(),
+			expected: true,
+		},
+		{
+			title:    "native sidecar",
+			pod:      nativeSidecar,
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			if HasIstioSidecar(tc.pod) != tc.expected {
+				t.Errorf("expected %t got %t", tc.expected, !tc.expected)
+			}
+		})
+	}
+}
+
 func Test_Sample(t *testing.T) {
 	t.Parallel()
 
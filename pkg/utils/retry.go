@@ -1,18 +1,24 @@
 package utils
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"time"
 )
 
-// Retry retries a function until it returns true, error, or the timeout expires.
+// ErrTimeout is returned by Retry when the timeout expires before the function succeeds
+var ErrTimeout = errors.New("timeout expired")
+
+// Retry retries a function until it returns true, error, the timeout expires or the context is done.
 // If the function returns false, a new attempt is tried after the backoff period
-func Retry(timeout time.Duration, backoff time.Duration, f func() (bool, error)) error {
+func Retry(ctx context.Context, timeout time.Duration, backoff time.Duration, f func() (bool, error)) error {
 	expired := time.After(timeout)
 	for {
 		select {
+		case <-ctx.Done():
+			return ctx.Err()
 		case <-expired:
-			return fmt.Errorf("timeout expired")
+			return ErrTimeout
 		default:
 			done, err := f()
 			if err != nil {
@@ -21,7 +27,12 @@ func Retry(timeout time.Duration, backoff time.Duration, f func() (bool, error))
 			if done {
 				return nil
 			}
-			time.Sleep(backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -15,6 +16,7 @@ func Test_Retry(t *testing.T) {
 		backoff       time.Duration
 		failedRetries int
 		errorValue    error
+		cancelled     bool
 		expectError   bool
 	}{
 		{
@@ -57,6 +59,15 @@ func Test_Retry(t *testing.T) {
 			errorValue:    nil,
 			expectError:   true,
 		},
+		{
+			title:         "context cancelled",
+			timeout:       time.Second * 5,
+			backoff:       time.Second,
+			failedRetries: 100,
+			errorValue:    nil,
+			cancelled:     true,
+			expectError:   true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -65,8 +76,14 @@ func Test_Retry(t *testing.T) {
 		t.Run(tc.title, func(t *testing.T) {
 			t.Parallel()
 
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			if tc.cancelled {
+				cancel()
+			}
+
 			retries := 0
-			err := Retry(tc.timeout, tc.backoff, func() (bool, error) {
+			err := Retry(ctx, tc.timeout, tc.backoff, func() (bool, error) {
 				retries++
 				if retries < tc.failedRetries {
 					return false, nil
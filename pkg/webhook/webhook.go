@@ -0,0 +1,130 @@
+// Package webhook implements a mutating admission webhook that adds the disruptor agent as a sidecar container to
+// the pods that request it, so the disruptor can apply faults to them without injecting the agent at test time.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SidecarAnnotation is the annotation that requests adding the agent as a sidecar to a pod, when set to "true"
+const SidecarAnnotation = "xk6-disruptor/agent-sidecar"
+
+// AgentContainerName is the name of the container that runs the agent
+const AgentContainerName = "xk6-agent"
+
+// maxRequestSize is the maximum size of an admission review request
+const maxRequestSize = 1 << 20
+
+// Config defines the sidecar added to the pods
+type Config struct {
+	// Image of the agent
+	Image string
+	// ImagePullPolicy of the agent image
+	ImagePullPolicy corev1.PullPolicy
+	// ControlPort is the port of the control API of the agent. A zero value disables the control API.
+	ControlPort uint
+}
+
+// patchOperation is a JSON patch operation
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// Sidecar returns the container that runs the agent as a sidecar
+func Sidecar(config Config) corev1.Container {
+	command := []string{"xk6-disruptor-agent", "janitor"}
+	if config.ControlPort != 0 {
+		command = append(command, "--control-port", fmt.Sprint(config.ControlPort))
+	}
+
+	return corev1.Container{
+		Name:            AgentContainerName,
+		Image:           config.Image,
+		ImagePullPolicy: config.ImagePullPolicy,
+		Command:         command,
+		SecurityContext: &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN"}},
+		},
+		TTY:   true,
+		Stdin: true,
+	}
+}
+
+// requestsSidecar returns if the pod requests the agent sidecar and does not have it already
+func requestsSidecar(pod corev1.Pod) bool {
+	if pod.Annotations[SidecarAnnotation] != "true" {
+		return false
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name == AgentContainerName {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Mutate returns the admission response to a pod admission request. The response patches the pods that request the
+// agent sidecar and allows all the pods regardless of whether they are patched.
+func Mutate(config Config, request *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	response := &admissionv1.AdmissionResponse{UID: request.UID, Allowed: true}
+
+	pod := corev1.Pod{}
+	err := json.Unmarshal(request.Object.Raw, &pod)
+	if err != nil {
+		response.Result = &metav1.Status{Message: fmt.Sprintf("decoding pod: %v", err)}
+		return response
+	}
+
+	if !requestsSidecar(pod) {
+		return response
+	}
+
+	patch, err := json.Marshal([]patchOperation{
+		{Op: "add", Path: "/spec/containers/-", Value: Sidecar(config)},
+	})
+	if err != nil {
+		response.Result = &metav1.Status{Message: fmt.Sprintf("encoding patch: %v", err)}
+		return response
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	response.Patch = patch
+	response.PatchType = &patchType
+
+	return response
+}
+
+// Handler returns the http handler of the webhook
+func Handler(config Config) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("reading request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		review := admissionv1.AdmissionReview{}
+		err = json.Unmarshal(body, &review)
+		if err != nil || review.Request == nil {
+			http.Error(w, "invalid admission review", http.StatusBadRequest)
+			return
+		}
+
+		review.Response = Mutate(config, review.Request)
+		review.Request = nil
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(review)
+	})
+}
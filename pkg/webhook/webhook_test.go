@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/xk6-disruptor/pkg/testutils/kubernetes/builders"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func Test_Mutate(t *testing.T) {
+	t.Parallel()
+
+	config := Config{Image: "ghcr.io/grafana/xk6-disruptor-agent:latest", ImagePullPolicy: corev1.PullIfNotPresent}
+	sidecar := Sidecar(config)
+
+	withSidecar := builders.NewPodBuilder("pod").WithAnnotation(SidecarAnnotation, "true").Build()
+	withSidecar.Spec.Containers = append(withSidecar.Spec.Containers, sidecar)
+
+	testCases := []struct {
+		title         string
+		pod           corev1.Pod
+		expectPatched bool
+	}{
+		{
+			title:         "pod requests sidecar",
+			pod:           builders.NewPodBuilder("pod").WithAnnotation(SidecarAnnotation, "true").Build(),
+			expectPatched: true,
+		},
+		{
+			title:         "pod does not request sidecar",
+			pod:           builders.NewPodBuilder("pod").Build(),
+			expectPatched: false,
+		},
+		{
+			title:         "pod disables sidecar",
+			pod:           builders.NewPodBuilder("pod").WithAnnotation(SidecarAnnotation, "false").Build(),
+			expectPatched: false,
+		},
+		{
+			title:         "pod already has sidecar",
+			pod:           withSidecar,
+			expectPatched: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.title, func(t *testing.T) {
+			t.Parallel()
+
+			raw, err := json.Marshal(tc.pod)
+			if err != nil {
+				t.Fatalf("failed: %v", err)
+			}
+
+			response := Mutate(config, &admissionv1.AdmissionRequest{UID: "uid", Object: runtime.RawExtension{Raw: raw}})
+			if !response.Allowed {
+				t.Fatalf("pod should be allowed")
+			}
+
+			if !tc.expectPatched {
+				if response.Patch != nil {
+					t.Fatalf("pod should not be patched")
+				}
+				return
+			}
+
+			patch := []patchOperation{}
+			err = json.Unmarshal(response.Patch, &patch)
+			if err != nil {
+				t.Fatalf("decoding patch: %v", err)
+			}
+
+			if len(patch) != 1 || patch[0].Path != "/spec/containers/-" {
+				t.Fatalf("unexpected patch %s", string(response.Patch))
+			}
+
+			container := corev1.Container{}
+			value, _ := json.Marshal(patch[0].Value)
+			_ = json.Unmarshal(value, &container)
+			if diff := cmp.Diff(sidecar, container); diff != "" {
+				t.Fatalf("expected sidecar does not match patched\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Handler(t *testing.T) {
+	t.Parallel()
+
+	pod := builders.NewPodBuilder("pod").WithAnnotation(SidecarAnnotation, "true").Build()
+	raw, _ := json.Marshal(pod)
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  &admissionv1.AdmissionRequest{UID: "uid", Object: runtime.RawExtension{Raw: raw}},
+	}
+	body, _ := json.Marshal(review)
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/mutate", bytes.NewReader(body))
+	Handler(Config{Image: "agent"}).ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200 got %d", recorder.Code)
+	}
+
+	returned := admissionv1.AdmissionReview{}
+	err := json.Unmarshal(recorder.Body.Bytes(), &returned)
+	if err != nil {
+		t.Fatalf("decoding review: %v", err)
+	}
+
+	if returned.Response == nil || returned.Response.UID != "uid" || returned.Response.Patch == nil {
+		t.Fatalf("unexpected response %s", recorder.Body.String())
+	}
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/mutate", bytes.NewReader([]byte("{}")))
+	Handler(Config{Image: "agent"}).ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 got %d", recorder.Code)
+	}
+}